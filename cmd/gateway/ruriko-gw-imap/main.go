@@ -9,8 +9,10 @@
 // # PLACEHOLDER STATUS
 //
 // The IMAP polling loop is intentionally stubbed out. The binary compiles,
-// starts, validates its configuration, and then waits without connecting to any
-// mail server. This is sufficient to:
+// starts, validates its configuration, logs in to the mail server once to
+// check the credentials, and then waits without polling. A rejected login
+// exits with the server's reason; connection failures are retried with
+// backoff. This is sufficient to:
 //
 //   - Establish the binary artefact that proves the Docker image build layer works.
 //   - Validate the ACP integration path (POST /events/{source}).
@@ -24,11 +26,19 @@
 //
 //	ACP_URL          Base URL of the agent's ACP server, e.g. http://localhost:8765 (required)
 //	ACP_TOKEN        Bearer token for ACP authentication (optional)
+//	ACP_CA_FILE      PEM CA bundle trusted in addition to the system roots when ACP_URL
+//	                 is https, e.g. the CA that issued the agent's ACP certificate (optional)
+//	ACP_TLS_SERVER_NAME Name verified in the agent's ACP certificate instead of the ACP_URL
+//	                 host (optional)
 //	GW_SOURCE        Gateway source name matching the Gosuto config entry, e.g. "imap" (required)
 //	GW_IMAP_HOST     IMAP server hostname, e.g. "imap.example.com" (required)
 //	GW_IMAP_PORT     IMAP server port (default: "993" for TLS)
 //	GW_IMAP_USER     IMAP account username (required)
-//	GW_IMAP_PASSWORD IMAP account password (required)
+//	GW_IMAP_AUTH     Authentication mode: "plain" (LOGIN with a password) or
+//	                 "xoauth2" (SASL XOAUTH2 with an OAuth2 access token, as
+//	                 Gmail and Office 365 require) (default: "plain")
+//	GW_IMAP_PASSWORD IMAP account password (required when GW_IMAP_AUTH=plain)
+//	GW_IMAP_OAUTH_TOKEN OAuth2 access token (required when GW_IMAP_AUTH=xoauth2)
 //	GW_IMAP_MAILBOX  Mailbox/folder to watch (default: "INBOX")
 //	GW_POLL_INTERVAL Poll interval for servers that don't support IMAP IDLE (default: "60s")
 //	LOG_FORMAT       "text" or "json" (default: "text")
@@ -37,51 +47,87 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/textproto"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
 
 // ─── Config ──────────────────────────────────────────────────────────────────
 
+// IMAP authentication modes (GW_IMAP_AUTH).
+const (
+	authPlain   = "plain"
+	authXOAuth2 = "xoauth2"
+)
+
 type config struct {
-	ACPURL       string
-	ACPToken     string
-	Source       string
-	IMAPHost     string
-	IMAPPort     string
-	IMAPUser     string
-	IMAPPassword string
-	IMAPMailbox  string
-	PollInterval time.Duration
+	ACPURL         string
+	ACPToken       string
+	Source         string
+	IMAPHost       string
+	IMAPPort       string
+	IMAPUser       string
+	IMAPAuth       string
+	IMAPPassword   string
+	IMAPOAuthToken string
+	IMAPMailbox    string
+	PollInterval   time.Duration
+
+	// acpClient posts events to ACP; loadConfig builds it from ACP_CA_FILE
+	// and ACP_TLS_SERVER_NAME.
+	acpClient *http.Client
+
+	// imapTLS configures the IMAP connection; nil verifies the server
+	// against the system roots.
+	imapTLS *tls.Config
 }
 
 func loadConfig() (*config, error) {
 	cfg := &config{
-		ACPURL:       os.Getenv("ACP_URL"),
-		ACPToken:     os.Getenv("ACP_TOKEN"),
-		Source:       os.Getenv("GW_SOURCE"),
-		IMAPHost:     os.Getenv("GW_IMAP_HOST"),
-		IMAPPort:     os.Getenv("GW_IMAP_PORT"),
-		IMAPUser:     os.Getenv("GW_IMAP_USER"),
-		IMAPPassword: os.Getenv("GW_IMAP_PASSWORD"),
-		IMAPMailbox:  os.Getenv("GW_IMAP_MAILBOX"),
+		ACPURL:         os.Getenv("ACP_URL"),
+		ACPToken:       os.Getenv("ACP_TOKEN"),
+		Source:         os.Getenv("GW_SOURCE"),
+		IMAPHost:       os.Getenv("GW_IMAP_HOST"),
+		IMAPPort:       os.Getenv("GW_IMAP_PORT"),
+		IMAPUser:       os.Getenv("GW_IMAP_USER"),
+		IMAPAuth:       strings.ToLower(strings.TrimSpace(os.Getenv("GW_IMAP_AUTH"))),
+		IMAPPassword:   os.Getenv("GW_IMAP_PASSWORD"),
+		IMAPOAuthToken: os.Getenv("GW_IMAP_OAUTH_TOKEN"),
+		IMAPMailbox:    os.Getenv("GW_IMAP_MAILBOX"),
+	}
+	if cfg.IMAPAuth == "" {
+		cfg.IMAPAuth = authPlain
 	}
 
-	for _, req := range []struct{ name, val string }{
+	required := []struct{ name, val string }{
 		{"ACP_URL", cfg.ACPURL},
 		{"GW_SOURCE", cfg.Source},
 		{"GW_IMAP_HOST", cfg.IMAPHost},
 		{"GW_IMAP_USER", cfg.IMAPUser},
-		{"GW_IMAP_PASSWORD", cfg.IMAPPassword},
-	} {
+	}
+	switch cfg.IMAPAuth {
+	case authPlain:
+		required = append(required, struct{ name, val string }{"GW_IMAP_PASSWORD", cfg.IMAPPassword})
+	case authXOAuth2:
+		required = append(required, struct{ name, val string }{"GW_IMAP_OAUTH_TOKEN", cfg.IMAPOAuthToken})
+	default:
+		return nil, fmt.Errorf("invalid GW_IMAP_AUTH %q: must be %q or %q", cfg.IMAPAuth, authPlain, authXOAuth2)
+	}
+	for _, req := range required {
 		if req.val == "" {
-			return nil, fmt.Errorf("required environment variable %s is not set", req.name)
+			return nil, fmt.Errorf("required environment variable %s is not set (GW_IMAP_AUTH=%s)", req.name, cfg.IMAPAuth)
 		}
 	}
 
@@ -102,9 +148,116 @@ func loadConfig() (*config, error) {
 	}
 	cfg.PollInterval = d
 
+	client, err := newACPClient(os.Getenv("ACP_CA_FILE"), os.Getenv("ACP_TLS_SERVER_NAME"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.acpClient = client
+
 	return cfg, nil
 }
 
+// newACPClient builds the HTTP client used for ACP requests. caFile, when
+// set, adds its PEM certificates to the system roots so an agent serving ACP
+// over TLS with a private CA is trusted; serverName, when set, is verified
+// in the agent certificate instead of the ACP_URL host, which is usually
+// 127.0.0.1.
+func newACPClient(caFile, serverName string) (*http.Client, error) {
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ACP_CA_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ACP_CA_FILE %s: no PEM certificates found", caFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tlsCfg
+	return &http.Client{Transport: t}, nil
+}
+
+// acpHTTPClient returns the client for ACP requests: the one loadConfig
+// built, or http.DefaultClient for configs assembled by hand.
+func (c *config) acpHTTPClient() *http.Client {
+	if c.acpClient != nil {
+		return c.acpClient
+	}
+	return http.DefaultClient
+}
+
+// ─── IMAP authentication ─────────────────────────────────────────────────────
+
+// errAuthRejected reports that the IMAP server refused the credentials or the
+// authentication mechanism. Retrying cannot help, so the gateway must exit
+// with the error instead of reconnecting in a loop.
+var errAuthRejected = errors.New("IMAP authentication rejected")
+
+// xoauth2InitialResponse returns the base64 SASL XOAUTH2 initial client
+// response for user and an OAuth2 access token, as specified by Google and
+// Microsoft: "user=<user>^Aauth=Bearer <token>^A^A".
+func xoauth2InitialResponse(user, token string) string {
+	return base64.StdEncoding.EncodeToString([]byte("user=" + user + "\x01auth=Bearer " + token + "\x01\x01"))
+}
+
+// authenticate logs in on an IMAP connection that has already read the
+// server greeting, using LOGIN in plain mode and AUTHENTICATE XOAUTH2 (with
+// an initial response, RFC 4959) in xoauth2 mode. A NO or BAD reply, including
+// one saying the server does not support XOAUTH2, is returned wrapping
+// errAuthRejected with the server's explanation.
+func authenticate(conn *textproto.Conn, cfg *config) error {
+	const tag = "a1"
+	mechanism := "LOGIN"
+	cmd := tag + " LOGIN " + imapQuote(cfg.IMAPUser) + " " + imapQuote(cfg.IMAPPassword)
+	if cfg.IMAPAuth == authXOAuth2 {
+		mechanism = "XOAUTH2"
+		cmd = tag + " AUTHENTICATE XOAUTH2 " + xoauth2InitialResponse(cfg.IMAPUser, cfg.IMAPOAuthToken)
+	}
+	if err := conn.PrintfLine("%s", cmd); err != nil {
+		return fmt.Errorf("send %s: %w", mechanism, err)
+	}
+
+	var detail string
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return fmt.Errorf("read %s response: %w", mechanism, err)
+		}
+		switch {
+		case strings.HasPrefix(line, "+"):
+			// XOAUTH2 failure: the server sends a base64 JSON error as a
+			// continuation and expects an empty line before its tagged NO.
+			if raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(line, "+"))); err == nil {
+				detail = string(raw)
+			}
+			if err := conn.PrintfLine(""); err != nil {
+				return fmt.Errorf("send %s continuation: %w", mechanism, err)
+			}
+		case strings.HasPrefix(line, tag+" OK"):
+			return nil
+		case strings.HasPrefix(line, tag+" NO"), strings.HasPrefix(line, tag+" BAD"):
+			msg := strings.TrimSpace(strings.TrimPrefix(line, tag))
+			if detail != "" {
+				msg += " " + detail
+			}
+			return fmt.Errorf("%w: server refused %s for %s (GW_IMAP_AUTH=%s): %s",
+				errAuthRejected, mechanism, cfg.IMAPUser, cfg.IMAPAuth, msg)
+		}
+		// Untagged responses (e.g. "* CAPABILITY ...") are ignored.
+	}
+}
+
+// imapQuote returns s as an IMAP quoted string (RFC 3501 §4.3).
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
 // ─── ACP event types ──────────────────────────────────────────────────────────
 
 // acpEvent is the normalised envelope posted to ACP POST /events/{source}.
@@ -118,8 +271,37 @@ type acpEvent struct {
 }
 
 type acpEventPayload struct {
-	Message string                 `json:"message"`
-	Data    map[string]interface{} `json:"data,omitempty"`
+	Message     string                 `json:"message"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+	Attachments []acpAttachment        `json:"attachments,omitempty"`
+}
+
+// acpAttachment mirrors common/spec/envelope.Attachment. Exactly one of URL
+// or Data is set; inline Data is base64 and capped at
+// maxInlineAttachmentBytes decoded (the agent rejects larger ones).
+type acpAttachment struct {
+	Name string `json:"name"`
+	MIME string `json:"mime,omitempty"`
+	Size int64  `json:"size,omitempty"`
+	URL  string `json:"url,omitempty"`
+	Data string `json:"data,omitempty"`
+}
+
+// maxInlineAttachmentBytes mirrors envelope.MaxInlineAttachmentBytes.
+const maxInlineAttachmentBytes = 256 * 1024
+
+// inlineAttachment builds an acpAttachment for a MIME part small enough to
+// inline, and reports false for parts that must be passed by reference.
+func inlineAttachment(name, mime string, content []byte) (acpAttachment, bool) {
+	if len(content) > maxInlineAttachmentBytes {
+		return acpAttachment{}, false
+	}
+	return acpAttachment{
+		Name: name,
+		MIME: mime,
+		Size: int64(len(content)),
+		Data: base64.StdEncoding.EncodeToString(content),
+	}, true
 }
 
 // postEvent sends a single event envelope to the agent's ACP endpoint.
@@ -139,7 +321,7 @@ func postEvent(ctx context.Context, cfg *config, evt acpEvent) error {
 		req.Header.Set("Authorization", "Bearer "+cfg.ACPToken)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := cfg.acpHTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("POST %s: %w", url, err)
 	}
@@ -154,23 +336,99 @@ func postEvent(ctx context.Context, cfg *config, evt acpEvent) error {
 
 // ─── Gateway loop (placeholder) ───────────────────────────────────────────────
 
-// runGateway is the main polling loop. In a production implementation this
-// would authenticate to the IMAP server, watch for new messages using IMAP
-// IDLE (RFC 2177), and call postEvent for each new message. The placeholder
-// loop simply sleeps and logs to demonstrate the lifecycle without requiring
-// a live mail server.
+// Login retry backoff bounds. The delay doubles after each failed attempt.
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 5 * time.Minute
+)
+
+// loginTimeout bounds connecting, reading the greeting and logging in.
+const loginTimeout = 30 * time.Second
+
+// login connects to the IMAP server over TLS, reads its greeting,
+// authenticates and logs out again. A rejected login is returned wrapping
+// errAuthRejected; any other error is a connection or protocol failure.
+func login(ctx context.Context, cfg *config) error {
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(cfg.IMAPHost, cfg.IMAPPort)
+	d := tls.Dialer{Config: cfg.imapTLS}
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", addr, err)
+	}
+	defer nc.Close()
+	stop := context.AfterFunc(ctx, func() { nc.Close() })
+	defer stop()
+
+	conn := textproto.NewConn(nc)
+	greeting, err := conn.ReadLine()
+	if err != nil {
+		return fmt.Errorf("read greeting from %s: %w", addr, err)
+	}
+	switch {
+	case strings.HasPrefix(greeting, "* OK"):
+		if err := authenticate(conn, cfg); err != nil {
+			return err
+		}
+	case strings.HasPrefix(greeting, "* PREAUTH"):
+		// Already authenticated by the transport.
+	default:
+		return fmt.Errorf("unexpected greeting from %s: %q", addr, greeting)
+	}
+	_ = conn.PrintfLine("a2 LOGOUT")
+	return nil
+}
+
+// checkLogin logs in once, retrying connection failures with backoff. It
+// returns the errAuthRejected error when the server refuses the credentials,
+// and nil once a login succeeds or ctx is cancelled.
+func checkLogin(ctx context.Context, cfg *config) error {
+	delay := minReconnectDelay
+	for {
+		err := login(ctx, cfg)
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, errAuthRejected) {
+			return err
+		}
+		slog.Warn("IMAP login failed; retrying", "err", err, "in", delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// runGateway is the main polling loop. It first checks that the IMAP server
+// accepts the configured credentials. In a production implementation it
+// would then watch for new messages using IMAP IDLE (RFC 2177) and call
+// postEvent for each new message; the placeholder loop simply sleeps and
+// logs to demonstrate the lifecycle.
 //
-// The function returns when ctx is cancelled (e.g. on SIGTERM).
-func runGateway(ctx context.Context, cfg *config) {
+// The function returns when ctx is cancelled (e.g. on SIGTERM). It returns an
+// error only when the server rejects the login.
+func runGateway(ctx context.Context, cfg *config) error {
 	slog.Info("ruriko-gw-imap started",
 		"source", cfg.Source,
 		"imap_host", cfg.IMAPHost,
 		"imap_port", cfg.IMAPPort,
 		"imap_user", cfg.IMAPUser,
+		"imap_auth", cfg.IMAPAuth,
 		"mailbox", cfg.IMAPMailbox,
 		"poll_interval", cfg.PollInterval,
 		"placeholder", true,
 	)
+	if err := checkLogin(ctx, cfg); err != nil {
+		return err
+	}
+	if ctx.Err() == nil {
+		slog.Info("IMAP login succeeded", "imap_host", cfg.IMAPHost, "imap_user", cfg.IMAPUser)
+	}
 	slog.Warn("PLACEHOLDER: IMAP polling is not implemented; binary exists to validate Docker image build layer")
 
 	ticker := time.NewTicker(cfg.PollInterval)
@@ -180,25 +438,26 @@ func runGateway(ctx context.Context, cfg *config) {
 		select {
 		case <-ctx.Done():
 			slog.Info("ruriko-gw-imap shutting down")
-			return
+			return nil
 		case t := <-ticker.C:
-			slog.Debug("poll tick (placeholder — no IMAP connection)",
+			slog.Debug("poll tick (placeholder — no IMAP polling)",
 				"source", cfg.Source,
 				"tick", t.UTC().Format(time.RFC3339),
 			)
 			// Production implementation would:
-			//   1. Connect to cfg.IMAPHost:cfg.IMAPPort using TLS
-			//   2. Authenticate with cfg.IMAPUser / cfg.IMAPPassword
-			//   3. SELECT cfg.IMAPMailbox
-			//   4. SEARCH UNSEEN (or use IMAP IDLE for push notification)
-			//   5. For each unseen message, build and send:
+			//   1. Log in as login does and SELECT cfg.IMAPMailbox
+			//   2. SEARCH UNSEEN (or use IMAP IDLE for push notification)
+			//   3. For each unseen message, collect its attachment parts with
+			//      inlineAttachment (parts over the inline cap would be
+			//      uploaded and passed by URL), then build and send:
 			//      postEvent(ctx, cfg, acpEvent{
 			//          Source: cfg.Source,
 			//          Type:   "imap.email",
 			//          TS:     time.Now().UTC(),
 			//          Payload: acpEventPayload{
-			//              Message: "New email from <from>: <subject>",
-			//              Data:    map[string]interface{}{"from": from, "subject": subject},
+			//              Message:     "New email from <from>: <subject>",
+			//              Data:        map[string]interface{}{"from": from, "subject": subject},
+			//              Attachments: attachments,
 			//          },
 			//      })
 		}
@@ -227,5 +486,8 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
 
-	runGateway(ctx, cfg)
+	if err := runGateway(ctx, cfg); err != nil {
+		slog.Error("ruriko-gw-imap exited", "err", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeIMAP is a TLS IMAP server that greets each connection and hands the
+// client's lines to a per-test script.
+type fakeIMAP struct {
+	addr  string
+	tls   *tls.Config
+	lines chan string
+}
+
+// newFakeIMAP starts a server; each connection gets greeting and is then
+// served by script, which reads client lines with next and writes replies
+// with send.
+func newFakeIMAP(t *testing.T, greeting string, script func(next func() string, send func(string))) *fakeIMAP {
+	t.Helper()
+	// Borrow httptest's self-signed certificate for 127.0.0.1.
+	hs := httptest.NewUnstartedServer(nil)
+	hs.StartTLS()
+	hs.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(hs.Certificate())
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: hs.TLS.Certificates})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeIMAP{addr: ln.Addr().String(), tls: &tls.Config{RootCAs: pool}, lines: make(chan string, 16)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				r := bufio.NewReader(c)
+				send := func(line string) { c.Write([]byte(line + "\r\n")) }
+				next := func() string {
+					line, _ := r.ReadString('\n')
+					line = strings.TrimRight(line, "\r\n")
+					f.lines <- line
+					return line
+				}
+				send(greeting)
+				script(next, send)
+			}()
+		}
+	}()
+	return f
+}
+
+func (f *fakeIMAP) config(auth string) *config {
+	host, port, _ := net.SplitHostPort(f.addr)
+	return &config{
+		Source:         "imap",
+		IMAPHost:       host,
+		IMAPPort:       port,
+		IMAPUser:       "agent@example.com",
+		IMAPAuth:       auth,
+		IMAPPassword:   `pa"ss`,
+		IMAPOAuthToken: "ya29.token",
+		PollInterval:   time.Hour,
+		imapTLS:        f.tls,
+	}
+}
+
+func TestLogin_XOAuth2(t *testing.T) {
+	srv := newFakeIMAP(t, "* OK IMAP4rev1 ready", func(next func() string, send func(string)) {
+		next()
+		send("a1 OK AUTHENTICATE completed")
+		next()
+	})
+
+	if err := login(context.Background(), srv.config(authXOAuth2)); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	cmd := <-srv.lines
+	want := "a1 AUTHENTICATE XOAUTH2 " + xoauth2InitialResponse("agent@example.com", "ya29.token")
+	if cmd != want {
+		t.Errorf("command = %q, want %q", cmd, want)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(cmd, "a1 AUTHENTICATE XOAUTH2 "))
+	if string(raw) != "user=agent@example.com\x01auth=Bearer ya29.token\x01\x01" {
+		t.Errorf("initial response = %q", raw)
+	}
+	if got := <-srv.lines; got != "a2 LOGOUT" {
+		t.Errorf("after login = %q, want a2 LOGOUT", got)
+	}
+}
+
+func TestLogin_PlainQuotesPassword(t *testing.T) {
+	srv := newFakeIMAP(t, "* OK ready", func(next func() string, send func(string)) {
+		next()
+		send("a1 OK LOGIN completed")
+		next()
+	})
+
+	if err := login(context.Background(), srv.config(authPlain)); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if got, want := <-srv.lines, `a1 LOGIN "agent@example.com" "pa\"ss"`; got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+}
+
+func TestRunGateway_XOAuth2RejectedIsFatal(t *testing.T) {
+	detail := `{"status":"400","schemes":"Bearer","scope":"https://mail.google.com/"}`
+	srv := newFakeIMAP(t, "* OK ready", func(next func() string, send func(string)) {
+		next()
+		send("+ " + base64.StdEncoding.EncodeToString([]byte(detail)))
+		next() // empty line acknowledging the error
+		send("a1 NO [AUTHENTICATIONFAILED] Invalid credentials")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := runGateway(ctx, srv.config(authXOAuth2))
+	if !errors.Is(err, errAuthRejected) {
+		t.Fatalf("err = %v, want errAuthRejected", err)
+	}
+	if !strings.Contains(err.Error(), "AUTHENTICATIONFAILED") || !strings.Contains(err.Error(), `"status":"400"`) {
+		t.Errorf("err = %v, want the server's reason and XOAUTH2 detail", err)
+	}
+	<-srv.lines
+	if got := <-srv.lines; got != "" {
+		t.Errorf("continuation reply = %q, want empty line", got)
+	}
+}
+
+func TestRunGateway_UnsupportedMechanismIsFatal(t *testing.T) {
+	srv := newFakeIMAP(t, "* OK ready", func(next func() string, send func(string)) {
+		next()
+		send("a1 BAD Unsupported authentication mechanism")
+	})
+
+	err := runGateway(context.Background(), srv.config(authXOAuth2))
+	if !errors.Is(err, errAuthRejected) || !strings.Contains(err.Error(), "Unsupported authentication mechanism") {
+		t.Fatalf("err = %v, want errAuthRejected with the server's reason", err)
+	}
+}
+
+func TestCheckLogin_RetriesConnectionFailures(t *testing.T) {
+	attempts := make(chan struct{}, 4)
+	srv := newFakeIMAP(t, "* OK ready", func(next func() string, send func(string)) {
+		attempts <- struct{}{}
+		if len(attempts) == 1 {
+			return // drop the first connection before answering
+		}
+		next()
+		send("a1 OK LOGIN completed")
+		next()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := checkLogin(ctx, srv.config(authPlain)); err != nil {
+		t.Fatalf("checkLogin: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("checkLogin gave up instead of retrying")
+	}
+	if n := len(attempts); n != 2 {
+		t.Errorf("attempts = %d, want 2", n)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// feedItem is an RSS item or Atom entry, reduced to the fields the gateway
+// forwards.
+type feedItem struct {
+	GUID      string
+	Title     string
+	Link      string
+	Published time.Time // zero when the feed gives no usable date
+}
+
+// feed is a parsed RSS 2.0 or Atom document.
+type feed struct {
+	Title string
+	Items []feedItem // in document order, usually newest first
+}
+
+// feedDoc decodes both formats: an RSS root fills Channel, an Atom root
+// fills Title and Entries. Atom elements are namespaced; encoding/xml matches
+// tags without a namespace against any namespace.
+type feedDoc struct {
+	XMLName xml.Name
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	// Dublin Core date, used by feeds that omit pubDate.
+	Date string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// parseFeed parses an RSS 2.0 or Atom document in UTF-8 or one of the
+// single-byte encodings charsetReader knows.
+func parseFeed(r io.Reader) (*feed, error) {
+	var doc feedDoc
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charsetReader
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+
+	switch doc.XMLName.Local {
+	case "rss":
+		f := &feed{Title: strings.TrimSpace(doc.Channel.Title)}
+		for _, it := range doc.Channel.Items {
+			item := feedItem{
+				GUID:      strings.TrimSpace(it.GUID),
+				Title:     strings.TrimSpace(it.Title),
+				Link:      strings.TrimSpace(it.Link),
+				Published: parseFeedTime(firstNonEmpty(it.PubDate, it.Date)),
+			}
+			f.Items = append(f.Items, withGUID(item))
+		}
+		return f, nil
+	case "feed":
+		f := &feed{Title: strings.TrimSpace(doc.Title)}
+		for _, e := range doc.Entries {
+			item := feedItem{
+				GUID:      strings.TrimSpace(e.ID),
+				Title:     strings.TrimSpace(e.Title),
+				Link:      atomAlternate(e.Links),
+				Published: parseFeedTime(firstNonEmpty(e.Published, e.Updated)),
+			}
+			f.Items = append(f.Items, withGUID(item))
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("parse feed: unsupported root element <%s> (want RSS 2.0 or Atom)", doc.XMLName.Local)
+	}
+}
+
+// withGUID fills in a GUID for items whose feed gives none, from the link
+// or, failing that, the title and date.
+func withGUID(it feedItem) feedItem {
+	if it.GUID == "" {
+		it.GUID = it.Link
+	}
+	if it.GUID == "" {
+		it.GUID = it.Title + "|" + it.Published.Format(time.RFC3339)
+	}
+	return it
+}
+
+// atomAlternate returns the entry's alternate link (rel="alternate" or no
+// rel), falling back to the first link.
+func atomAlternate(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	if len(links) > 0 {
+		return strings.TrimSpace(links[0].Href)
+	}
+	return ""
+}
+
+// feedTimeLayouts are the date formats seen in the wild: RFC 822 variants in
+// RSS, RFC 3339 in Atom and Dublin Core.
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseFeedTime parses a feed date, returning the zero time when it matches
+// no known layout.
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// charsetReader converts a feed declared as <?xml encoding="..."?> in a
+// non-UTF-8 charset to UTF-8. encoding/xml only reads UTF-8 itself, and older
+// feeds are often Latin-1 or Windows-1252. Labels follow the WHATWG Encoding
+// Standard, which decodes ISO-8859-1 and US-ASCII as Windows-1252.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "utf-8", "utf8", "unicode-1-1-utf-8":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1", "cp819", "ibm819",
+		"us-ascii", "ascii", "windows-1252", "cp1252", "x-cp1252":
+		return &cp1252Reader{r: bufio.NewReader(input)}, nil
+	}
+	return nil, fmt.Errorf("unsupported feed encoding %q", label)
+}
+
+// cp1252High maps Windows-1252 bytes 0x80–0x9F to Unicode; Latin-1 maps them
+// to C1 controls, which no feed means. Undefined bytes keep their Latin-1
+// value. Every other byte is its own code point.
+var cp1252High = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// cp1252Reader decodes a Windows-1252 byte stream to UTF-8.
+type cp1252Reader struct {
+	r   *bufio.Reader
+	buf []byte // encoded bytes not yet returned
+}
+
+func (c *cp1252Reader) Read(p []byte) (int, error) {
+	for len(c.buf) < len(p) {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			if len(c.buf) > 0 {
+				break
+			}
+			return 0, err
+		}
+		r := rune(b)
+		if b >= 0x80 && b < 0xA0 {
+			r = cp1252High[b-0x80]
+		}
+		c.buf = utf8.AppendRune(c.buf, r)
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,517 @@
+// ruriko-gw-rss is an RSS/Atom feed gateway for Gitai agents.
+//
+// # Overview
+//
+// This binary implements the external gateway binary contract: it polls one
+// or more RSS 2.0 or Atom feeds and forwards each new item as a normalised
+// event envelope to the agent's local ACP endpoint (POST /events/{source}).
+//
+// Items are identified by their GUID (RSS <guid>, Atom <id>, falling back to
+// the link). Seen GUIDs are kept per feed and, when GW_RSS_STATE is set,
+// saved to that file after every poll so a restart does not replay the feed.
+// The first poll of a feed with no saved state only records the items already
+// in it; set GW_RSS_BACKFILL=true to forward them instead. An item is marked
+// seen only once ACP accepted it, so a failed POST is retried next poll.
+//
+// # Configuration (environment variables)
+//
+//	ACP_URL          Base URL of the agent's ACP server, e.g. http://localhost:8765 (required)
+//	ACP_TOKEN        Bearer token for ACP authentication (optional)
+//	ACP_CA_FILE      PEM CA bundle trusted in addition to the system roots when ACP_URL
+//	                 is https, e.g. the CA that issued the agent's ACP certificate (optional)
+//	ACP_TLS_SERVER_NAME Name verified in the agent's ACP certificate instead of the ACP_URL
+//	                 host (optional)
+//	GW_SOURCE        Gateway source name matching the Gosuto config entry, e.g. "rss" (required)
+//	GW_RSS_FEEDS     Comma-separated feed URLs (required)
+//	GW_RSS_STATE     File that persists seen item GUIDs, e.g. /data/gw-rss-state.json
+//	                 (default: "" = kept in memory only)
+//	GW_RSS_BACKFILL  Forward the items already in a feed on its first poll (default: "false")
+//	GW_POLL_INTERVAL Time between polls (default: "5m")
+//	LOG_FORMAT       "text" or "json" (default: "text")
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ─── Config ──────────────────────────────────────────────────────────────────
+
+type config struct {
+	ACPURL       string
+	ACPToken     string
+	Source       string
+	Feeds        []string
+	StateFile    string
+	Backfill     bool
+	PollInterval time.Duration
+
+	// acpClient posts events to ACP; loadConfig builds it from ACP_CA_FILE
+	// and ACP_TLS_SERVER_NAME.
+	acpClient *http.Client
+}
+
+func loadConfig() (*config, error) {
+	cfg := &config{
+		ACPURL:    os.Getenv("ACP_URL"),
+		ACPToken:  os.Getenv("ACP_TOKEN"),
+		Source:    os.Getenv("GW_SOURCE"),
+		StateFile: os.Getenv("GW_RSS_STATE"),
+	}
+	for _, f := range strings.Split(os.Getenv("GW_RSS_FEEDS"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			cfg.Feeds = append(cfg.Feeds, f)
+		}
+	}
+
+	for _, req := range []struct {
+		name string
+		set  bool
+	}{
+		{"ACP_URL", cfg.ACPURL != ""},
+		{"GW_SOURCE", cfg.Source != ""},
+		{"GW_RSS_FEEDS", len(cfg.Feeds) > 0},
+	} {
+		if !req.set {
+			return nil, fmt.Errorf("required environment variable %s is not set", req.name)
+		}
+	}
+	for _, f := range cfg.Feeds {
+		u, err := url.Parse(f)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid feed URL %q in GW_RSS_FEEDS: must be an http or https URL", f)
+		}
+	}
+
+	if s := os.Getenv("GW_RSS_BACKFILL"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GW_RSS_BACKFILL %q: %w", s, err)
+		}
+		cfg.Backfill = b
+	}
+
+	pollStr := os.Getenv("GW_POLL_INTERVAL")
+	if pollStr == "" {
+		pollStr = "5m"
+	}
+	d, err := time.ParseDuration(pollStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GW_POLL_INTERVAL %q: %w", pollStr, err)
+	}
+	if d < time.Second {
+		return nil, fmt.Errorf("invalid GW_POLL_INTERVAL %q: must be at least 1s", pollStr)
+	}
+	cfg.PollInterval = d
+
+	client, err := newACPClient(os.Getenv("ACP_CA_FILE"), os.Getenv("ACP_TLS_SERVER_NAME"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.acpClient = client
+
+	return cfg, nil
+}
+
+// newACPClient builds the HTTP client used for ACP requests. caFile, when
+// set, adds its PEM certificates to the system roots so an agent serving ACP
+// over TLS with a private CA is trusted; serverName, when set, is verified
+// in the agent certificate instead of the ACP_URL host, which is usually
+// 127.0.0.1.
+func newACPClient(caFile, serverName string) (*http.Client, error) {
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ACP_CA_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ACP_CA_FILE %s: no PEM certificates found", caFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tlsCfg
+	return &http.Client{Transport: t}, nil
+}
+
+// acpHTTPClient returns the client for ACP requests: the one loadConfig
+// built, or http.DefaultClient for configs assembled by hand.
+func (c *config) acpHTTPClient() *http.Client {
+	if c.acpClient != nil {
+		return c.acpClient
+	}
+	return http.DefaultClient
+}
+
+// ─── Seen-item state ─────────────────────────────────────────────────────────
+
+// maxSeenPerFeed caps the GUIDs remembered per feed; the oldest are dropped
+// first. Feeds list far fewer items than this, so a dropped GUID has long
+// since left the feed.
+const maxSeenPerFeed = 1000
+
+// seenState records the item GUIDs already handled, per feed URL. A feed
+// with an entry (even an empty one) has been polled before.
+type seenState struct {
+	path  string
+	feeds map[string][]string // oldest first
+	index map[string]map[string]bool
+	dirty bool
+}
+
+// stateFile is the on-disk form of seenState.
+type stateFile struct {
+	Feeds map[string][]string `json:"feeds"`
+}
+
+// loadState reads path, or returns an empty state when path is empty or the
+// file does not exist yet.
+func loadState(path string) (*seenState, error) {
+	s := &seenState{path: path, feeds: map[string][]string{}, index: map[string]map[string]bool{}}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+	var sf stateFile
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("parse state %s: %w", path, err)
+	}
+	for feedURL, guids := range sf.Feeds {
+		s.known(feedURL)
+		for _, g := range guids {
+			s.add(feedURL, g)
+		}
+	}
+	s.dirty = false
+	return s, nil
+}
+
+// known reports whether feedURL has been polled before, and records that it
+// now has.
+func (s *seenState) known(feedURL string) bool {
+	if _, ok := s.index[feedURL]; ok {
+		return true
+	}
+	s.index[feedURL] = map[string]bool{}
+	s.feeds[feedURL] = nil
+	s.dirty = true
+	return false
+}
+
+func (s *seenState) seen(feedURL, guid string) bool { return s.index[feedURL][guid] }
+
+// add marks guid as seen for feedURL.
+func (s *seenState) add(feedURL, guid string) {
+	idx := s.index[feedURL]
+	if idx[guid] {
+		return
+	}
+	idx[guid] = true
+	guids := append(s.feeds[feedURL], guid)
+	if over := len(guids) - maxSeenPerFeed; over > 0 {
+		for _, g := range guids[:over] {
+			delete(idx, g)
+		}
+		guids = append([]string(nil), guids[over:]...)
+	}
+	s.feeds[feedURL] = guids
+	s.dirty = true
+}
+
+// save writes the state to its file when it changed, replacing the file
+// atomically so a crash mid-write cannot corrupt it.
+func (s *seenState) save() error {
+	if s.path == "" || !s.dirty {
+		return nil
+	}
+	data, err := json.Marshal(stateFile{Feeds: s.feeds})
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write state: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
+
+// ─── Polling ─────────────────────────────────────────────────────────────────
+
+// maxFeedBytes bounds a fetched feed document.
+const maxFeedBytes = 10 << 20
+
+// fetchTimeout bounds fetching one feed; postTimeout bounds one POST /events.
+const (
+	fetchTimeout = 30 * time.Second
+	postTimeout  = 10 * time.Second
+)
+
+type poller struct {
+	cfg    *config
+	state  *seenState
+	client *http.Client
+	post   func(ctx context.Context, evt acpEvent) error
+}
+
+func newPoller(cfg *config, state *seenState) *poller {
+	return &poller{
+		cfg:    cfg,
+		state:  state,
+		client: &http.Client{Timeout: fetchTimeout},
+		post:   func(ctx context.Context, evt acpEvent) error { return postEvent(ctx, cfg, evt) },
+	}
+}
+
+// pollOnce polls every feed, forwards new items and saves the state. It
+// returns the number of items forwarded; failures are logged per feed.
+func (p *poller) pollOnce(ctx context.Context) int {
+	forwarded := 0
+	for _, feedURL := range p.cfg.Feeds {
+		n, err := p.pollFeed(ctx, feedURL)
+		forwarded += n
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("feed poll failed", "feed", feedURL, "forwarded", n, "err", err)
+		}
+	}
+	if err := p.state.save(); err != nil {
+		slog.Error("could not save seen items; a restart may replay them", "file", p.state.path, "err", err)
+	}
+	return forwarded
+}
+
+func (p *poller) pollFeed(ctx context.Context, feedURL string) (int, error) {
+	f, err := p.fetch(ctx, feedURL)
+	if err != nil {
+		return 0, err
+	}
+	if !p.state.known(feedURL) && !p.cfg.Backfill {
+		for _, it := range f.Items {
+			p.state.add(feedURL, it.GUID)
+		}
+		slog.Info("feed primed; forwarding items published from now on", "feed", feedURL, "existing_items", len(f.Items))
+		return 0, nil
+	}
+
+	forwarded := 0
+	// Feeds list newest first; forward oldest first.
+	for i := len(f.Items) - 1; i >= 0; i-- {
+		it := f.Items[i]
+		if p.state.seen(feedURL, it.GUID) {
+			continue
+		}
+		postCtx, cancel := context.WithTimeout(ctx, postTimeout)
+		err := p.post(postCtx, itemEvent(p.cfg.Source, feedURL, f.Title, it))
+		cancel()
+		if err != nil {
+			// Leave this and newer items unseen so the next poll retries them.
+			return forwarded, fmt.Errorf("forward %q: %w", it.GUID, err)
+		}
+		p.state.add(feedURL, it.GUID)
+		forwarded++
+	}
+	return forwarded, nil
+}
+
+func (p *poller) fetch(ctx context.Context, feedURL string) (*feed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8")
+	req.Header.Set("User-Agent", "ruriko-gw-rss")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", feedURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %d", feedURL, resp.StatusCode)
+	}
+	return parseFeed(io.LimitReader(resp.Body, maxFeedBytes))
+}
+
+// itemEvent builds the rss.item event for it.
+func itemEvent(source, feedURL, feedTitle string, it feedItem) acpEvent {
+	data := map[string]interface{}{
+		"title": it.Title,
+		"link":  it.Link,
+		"guid":  it.GUID,
+		"feed":  feedURL,
+	}
+	ts := time.Now().UTC()
+	if !it.Published.IsZero() {
+		data["published"] = it.Published.Format(time.RFC3339)
+		ts = it.Published
+	}
+	if feedTitle != "" {
+		data["feed_title"] = feedTitle
+	}
+
+	title := it.Title
+	if title == "" {
+		title = it.Link
+	}
+	msg := "New feed item: " + title
+	if feedTitle != "" {
+		msg = "New item in " + feedTitle + ": " + title
+	}
+	return acpEvent{
+		Source: source,
+		Type:   "rss.item",
+		TS:     ts,
+		Payload: acpEventPayload{
+			Message: msg,
+			Data:    data,
+		},
+	}
+}
+
+// ─── ACP event types ──────────────────────────────────────────────────────────
+
+// acpEvent is the normalised envelope posted to ACP POST /events/{source}.
+// This mirrors common/spec/envelope.Event — reproduced here so the binary has
+// zero in-tree dependencies and can be built as a standalone artefact.
+type acpEvent struct {
+	Source  string          `json:"source"`
+	Type    string          `json:"type"`
+	TS      time.Time       `json:"ts"`
+	Payload acpEventPayload `json:"payload"`
+}
+
+type acpEventPayload struct {
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// postEvent sends a single event envelope to the agent's ACP endpoint.
+func postEvent(ctx context.Context, cfg *config, evt acpEvent) error {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	url := cfg.ACPURL + "/events/" + cfg.Source
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.ACPToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.ACPToken)
+	}
+
+	resp, err := cfg.acpHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("ACP returned HTTP %d for %s", resp.StatusCode, url)
+	}
+
+	return nil
+}
+
+// ─── Gateway loop ────────────────────────────────────────────────────────────
+
+// runGateway polls immediately and then every cfg.PollInterval until ctx is
+// cancelled (e.g. on SIGTERM).
+func runGateway(ctx context.Context, cfg *config, state *seenState) {
+	slog.Info("ruriko-gw-rss started",
+		"source", cfg.Source,
+		"feeds", len(cfg.Feeds),
+		"poll_interval", cfg.PollInterval,
+		"state_file", cfg.StateFile,
+	)
+	if cfg.StateFile == "" {
+		slog.Warn("GW_RSS_STATE is not set; seen items are not persisted across restarts")
+	}
+
+	p := newPoller(cfg, state)
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if n := p.pollOnce(ctx); n > 0 {
+			slog.Info("forwarded feed items", "count", n)
+		}
+		select {
+		case <-ctx.Done():
+			slog.Info("ruriko-gw-rss shutting down")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ─── Main ─────────────────────────────────────────────────────────────────────
+
+func main() {
+	// Configure structured logging.
+	logLevel := slog.LevelInfo
+	var logHandler slog.Handler
+	if os.Getenv("LOG_FORMAT") == "json" {
+		logHandler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})
+	} else {
+		logHandler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})
+	}
+	slog.SetDefault(slog.New(logHandler))
+
+	cfg, err := loadConfig()
+	if err != nil {
+		slog.Error("configuration error", "err", err)
+		os.Exit(1)
+	}
+	state, err := loadState(cfg.StateFile)
+	if err != nil {
+		slog.Error("configuration error", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	runGateway(ctx, cfg, state)
+}
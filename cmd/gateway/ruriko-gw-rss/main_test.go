@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Engineering Blog</title>
+  <entry>
+    <title>Second post</title>
+    <id>urn:uuid:2</id>
+    <link rel="self" href="https://blog.example.com/api/2"/>
+    <link href="https://blog.example.com/2"/>
+    <updated>2026-10-02T08:00:00Z</updated>
+  </entry>
+  <entry>
+    <title>First post</title>
+    <id>urn:uuid:1</id>
+    <link rel="alternate" href="https://blog.example.com/1"/>
+    <published>2026-10-01T09:30:00+02:00</published>
+    <updated>2026-10-05T00:00:00Z</updated>
+  </entry>
+</feed>`
+
+// rssFeed renders an RSS 2.0 feed listing items newest first.
+func rssFeed(items ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><rss version="2.0"><channel><title>News</title>`)
+	for i := len(items) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, `<item><title>%s</title><link>https://news.example.com/%s</link><guid>%s</guid><pubDate>Mon, 05 Oct 2026 1%d:00:00 +0000</pubDate></item>`,
+			items[i], items[i], items[i], i)
+	}
+	b.WriteString(`</channel></rss>`)
+	return b.String()
+}
+
+func TestParseFeed_RSS(t *testing.T) {
+	f, err := parseFeed(strings.NewReader(rssFeed("a", "b")))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if f.Title != "News" || len(f.Items) != 2 {
+		t.Fatalf("feed = %+v", f)
+	}
+	want := feedItem{GUID: "b", Title: "b", Link: "https://news.example.com/b", Published: time.Date(2026, 10, 5, 11, 0, 0, 0, time.UTC)}
+	if f.Items[0] != want {
+		t.Errorf("item = %+v, want %+v", f.Items[0], want)
+	}
+}
+
+func TestParseFeed_Atom(t *testing.T) {
+	f, err := parseFeed(strings.NewReader(atomFeed))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if f.Title != "Engineering Blog" || len(f.Items) != 2 {
+		t.Fatalf("feed = %+v", f)
+	}
+	if it := f.Items[0]; it.GUID != "urn:uuid:2" || it.Link != "https://blog.example.com/2" || !it.Published.Equal(time.Date(2026, 10, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("entry with only <updated> = %+v", it)
+	}
+	if it := f.Items[1]; it.Link != "https://blog.example.com/1" || !it.Published.Equal(time.Date(2026, 10, 1, 7, 30, 0, 0, time.UTC)) {
+		t.Errorf("entry with <published> = %+v, want the published time", it)
+	}
+}
+
+func TestParseFeed_SingleByteEncodings(t *testing.T) {
+	// "Café – news" in ISO-8859-1 and Windows-1252 (0x96 is an en dash).
+	for _, label := range []string{"ISO-8859-1", "windows-1252"} {
+		doc := `<?xml version="1.0" encoding="` + label + `"?><rss version="2.0"><channel><title>Caf` + "\xe9 \x96" + ` news</title></channel></rss>`
+		f, err := parseFeed(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("%s: parseFeed: %v", label, err)
+		}
+		if f.Title != "Café – news" {
+			t.Errorf("%s: title = %q, want %q", label, f.Title, "Café – news")
+		}
+	}
+
+	doc := `<?xml version="1.0" encoding="Shift_JIS"?><rss version="2.0"><channel></channel></rss>`
+	if _, err := parseFeed(strings.NewReader(doc)); err == nil || !strings.Contains(err.Error(), "Shift_JIS") {
+		t.Errorf("unknown encoding: err = %v", err)
+	}
+}
+
+func TestParseFeed_RejectsOtherDocuments(t *testing.T) {
+	if _, err := parseFeed(strings.NewReader(`<html><body>not a feed</body></html>`)); err == nil {
+		t.Error("expected an error for an HTML page")
+	}
+}
+
+// feedServer serves a feed whose body can be replaced between polls.
+type feedServer struct {
+	mu   sync.Mutex
+	body string
+}
+
+func (s *feedServer) set(body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+}
+
+func (s *feedServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/rss+xml")
+	_, _ = w.Write([]byte(s.body))
+}
+
+// recordingPoller returns a poller for feedURL whose posts are recorded by
+// GUID. A non-nil fail makes posts of that GUID fail.
+func recordingPoller(t *testing.T, cfg *config, fail *string) (*poller, *[]string) {
+	t.Helper()
+	state, err := loadState(cfg.StateFile)
+	if err != nil {
+		t.Fatalf("loadState: %v", err)
+	}
+	p := newPoller(cfg, state)
+	var posted []string
+	p.post = func(_ context.Context, evt acpEvent) error {
+		guid, _ := evt.Payload.Data["guid"].(string)
+		if fail != nil && guid == *fail {
+			return errors.New("ACP returned HTTP 503")
+		}
+		posted = append(posted, guid)
+		return nil
+	}
+	return p, &posted
+}
+
+func TestPoller_DedupsAcrossPolls(t *testing.T) {
+	feed := &feedServer{}
+	srv := httptest.NewServer(feed)
+	t.Cleanup(srv.Close)
+	cfg := &config{Source: "rss", Feeds: []string{srv.URL}, StateFile: filepath.Join(t.TempDir(), "state.json")}
+
+	p, posted := recordingPoller(t, cfg, nil)
+	feed.set(rssFeed("a", "b"))
+	if n := p.pollOnce(context.Background()); n != 0 {
+		t.Errorf("first poll forwarded %d items, want the existing items recorded only", n)
+	}
+
+	feed.set(rssFeed("a", "b", "c", "d"))
+	if n := p.pollOnce(context.Background()); n != 2 {
+		t.Errorf("second poll forwarded %d items, want 2", n)
+	}
+	if n := p.pollOnce(context.Background()); n != 0 {
+		t.Errorf("unchanged feed forwarded %d items, want 0", n)
+	}
+	if got := strings.Join(*posted, ","); got != "c,d" {
+		t.Errorf("posted %s, want c,d oldest first", got)
+	}
+
+	// A restart loads the saved GUIDs and forwards only what is new.
+	p, posted = recordingPoller(t, cfg, nil)
+	feed.set(rssFeed("a", "b", "c", "d", "e"))
+	if n := p.pollOnce(context.Background()); n != 1 || strings.Join(*posted, ",") != "e" {
+		t.Errorf("after restart forwarded %d items %v, want only e", n, *posted)
+	}
+}
+
+func TestPoller_BackfillAndRetry(t *testing.T) {
+	feed := &feedServer{}
+	srv := httptest.NewServer(feed)
+	t.Cleanup(srv.Close)
+	cfg := &config{Source: "rss", Feeds: []string{srv.URL}, Backfill: true}
+
+	failing := "b"
+	p, posted := recordingPoller(t, cfg, &failing)
+	feed.set(rssFeed("a", "b", "c"))
+	if n := p.pollOnce(context.Background()); n != 1 {
+		t.Errorf("forwarded %d items before the failure, want 1", n)
+	}
+
+	failing = ""
+	if n := p.pollOnce(context.Background()); n != 2 {
+		t.Errorf("retry poll forwarded %d items, want 2", n)
+	}
+	if got := strings.Join(*posted, ","); got != "a,b,c" {
+		t.Errorf("posted %s, want a,b,c", got)
+	}
+}
+
+func TestItemEvent_Shape(t *testing.T) {
+	published := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	evt := itemEvent("rss", "https://news.example.com/feed", "News", feedItem{
+		GUID: "g-1", Title: "Launch day", Link: "https://news.example.com/launch", Published: published,
+	})
+
+	raw, err := json.Marshal(evt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Source  string    `json:"source"`
+		Type    string    `json:"type"`
+		TS      time.Time `json:"ts"`
+		Payload struct {
+			Message string            `json:"message"`
+			Data    map[string]string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Source != "rss" || got.Type != "rss.item" || !got.TS.Equal(published) {
+		t.Errorf("envelope = %+v", got)
+	}
+	if got.Payload.Message != "New item in News: Launch day" {
+		t.Errorf("message = %q", got.Payload.Message)
+	}
+	for k, v := range map[string]string{
+		"title": "Launch day", "link": "https://news.example.com/launch", "published": "2026-10-05T10:00:00Z",
+		"guid": "g-1", "feed": "https://news.example.com/feed", "feed_title": "News",
+	} {
+		if got.Payload.Data[k] != v {
+			t.Errorf("data[%s] = %q, want %q", k, got.Payload.Data[k], v)
+		}
+	}
+}
+
+func TestLoadConfig_Validation(t *testing.T) {
+	t.Setenv("ACP_URL", "http://localhost:8765")
+	t.Setenv("GW_SOURCE", "rss")
+	t.Setenv("GW_RSS_FEEDS", " , ")
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "GW_RSS_FEEDS") {
+		t.Errorf("empty feeds: err = %v", err)
+	}
+
+	t.Setenv("GW_RSS_FEEDS", "https://a.example.com/feed.xml,ftp://b.example.com/feed")
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "ftp://") {
+		t.Errorf("ftp feed: err = %v", err)
+	}
+
+	t.Setenv("GW_RSS_FEEDS", "https://a.example.com/feed.xml, https://b.example.com/atom")
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if len(cfg.Feeds) != 2 || cfg.Feeds[1] != "https://b.example.com/atom" || cfg.PollInterval != 5*time.Minute || cfg.Backfill {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestPostEvent_TrustsACPCAFile(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, pemBytes, 0o600); err != nil {
+		t.Fatalf("write ca: %v", err)
+	}
+	evt := acpEvent{Source: "rss", Type: "rss.item"}
+
+	cfg := &config{ACPURL: ts.URL, Source: "rss"}
+	if err := postEvent(context.Background(), cfg, evt); err == nil {
+		t.Fatal("expected certificate verification error without ACP_CA_FILE")
+	}
+
+	t.Setenv("ACP_URL", ts.URL)
+	t.Setenv("GW_SOURCE", "rss")
+	t.Setenv("GW_RSS_FEEDS", "https://a.example.com/feed.xml")
+	t.Setenv("ACP_CA_FILE", caFile)
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if err := postEvent(context.Background(), cfg, evt); err != nil {
+		t.Fatalf("postEvent with ACP_CA_FILE: %v", err)
+	}
+}
@@ -0,0 +1,451 @@
+// ruriko-gw-slack is a Slack event gateway for Gitai agents.
+//
+// # Overview
+//
+// This binary implements the external gateway binary contract: it connects to
+// Slack over Socket Mode and forwards each channel message as a normalised
+// event envelope to the agent's local ACP endpoint (POST /events/{source}).
+//
+// Socket Mode needs no public HTTP endpoint: the gateway opens an outbound
+// WebSocket with an app-level token, so it works from inside the agent
+// container. Because Slack does not sign Socket Mode deliveries, no signing
+// secret is needed; the app-level token is the only credential.
+//
+// A Socket Mode envelope carrying a message is acknowledged only once ACP has
+// accepted the event; when the POST fails the envelope is left unacknowledged
+// and Slack redelivers it. Message edits, deletions, joins and other subtyped
+// messages, and messages posted by bots (including the agent itself), are
+// acknowledged and dropped. The gateway pings Slack every 30 seconds and
+// reconnects when nothing arrives for 75 seconds.
+//
+// # Configuration (environment variables)
+//
+//	ACP_URL            Base URL of the agent's ACP server, e.g. http://localhost:8765 (required)
+//	ACP_TOKEN          Bearer token for ACP authentication (optional)
+//	ACP_CA_FILE        PEM CA bundle trusted in addition to the system roots when ACP_URL
+//	                   is https, e.g. the CA that issued the agent's ACP certificate (optional)
+//	ACP_TLS_SERVER_NAME Name verified in the agent's ACP certificate instead of the ACP_URL
+//	                   host (optional)
+//	GW_SOURCE          Gateway source name matching the Gosuto config entry, e.g. "slack" (required)
+//	GW_SLACK_APP_TOKEN Slack app-level token with the connections:write scope, "xapp-..." (required)
+//	GW_SLACK_API_URL   Slack Web API base URL (default: "https://slack.com/api")
+//	LOG_FORMAT         "text" or "json" (default: "text")
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ─── Config ──────────────────────────────────────────────────────────────────
+
+const defaultSlackAPIURL = "https://slack.com/api"
+
+type config struct {
+	ACPURL      string
+	ACPToken    string
+	Source      string
+	AppToken    string
+	SlackAPIURL string
+
+	// acpClient posts events to ACP; loadConfig builds it from ACP_CA_FILE
+	// and ACP_TLS_SERVER_NAME.
+	acpClient *http.Client
+}
+
+func loadConfig() (*config, error) {
+	cfg := &config{
+		ACPURL:      os.Getenv("ACP_URL"),
+		ACPToken:    os.Getenv("ACP_TOKEN"),
+		Source:      os.Getenv("GW_SOURCE"),
+		AppToken:    strings.TrimSpace(os.Getenv("GW_SLACK_APP_TOKEN")),
+		SlackAPIURL: strings.TrimRight(os.Getenv("GW_SLACK_API_URL"), "/"),
+	}
+
+	for _, req := range []struct{ name, val string }{
+		{"ACP_URL", cfg.ACPURL},
+		{"GW_SOURCE", cfg.Source},
+		{"GW_SLACK_APP_TOKEN", cfg.AppToken},
+	} {
+		if req.val == "" {
+			return nil, fmt.Errorf("required environment variable %s is not set", req.name)
+		}
+	}
+	// A bot token (xoxb-) is the usual mistake: it cannot open Socket Mode
+	// connections, and Slack's error for it is not obvious.
+	if !strings.HasPrefix(cfg.AppToken, "xapp-") {
+		return nil, fmt.Errorf("GW_SLACK_APP_TOKEN must be an app-level token (xapp-...), not a bot or user token")
+	}
+	if cfg.SlackAPIURL == "" {
+		cfg.SlackAPIURL = defaultSlackAPIURL
+	}
+
+	client, err := newACPClient(os.Getenv("ACP_CA_FILE"), os.Getenv("ACP_TLS_SERVER_NAME"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.acpClient = client
+
+	return cfg, nil
+}
+
+// newACPClient builds the HTTP client used for ACP requests. caFile, when
+// set, adds its PEM certificates to the system roots so an agent serving ACP
+// over TLS with a private CA is trusted; serverName, when set, is verified
+// in the agent certificate instead of the ACP_URL host, which is usually
+// 127.0.0.1.
+func newACPClient(caFile, serverName string) (*http.Client, error) {
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ACP_CA_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ACP_CA_FILE %s: no PEM certificates found", caFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tlsCfg
+	return &http.Client{Transport: t}, nil
+}
+
+// acpHTTPClient returns the client for ACP requests: the one loadConfig
+// built, or http.DefaultClient for configs assembled by hand.
+func (c *config) acpHTTPClient() *http.Client {
+	if c.acpClient != nil {
+		return c.acpClient
+	}
+	return http.DefaultClient
+}
+
+// ─── Slack Socket Mode ───────────────────────────────────────────────────────
+
+// errAuthRejected reports that Slack refused the app token. Retrying cannot
+// help, so the gateway must exit with the error instead of reconnecting in a
+// loop.
+var errAuthRejected = errors.New("Slack authentication rejected")
+
+// authErrors are the apps.connections.open error codes that mean the token
+// itself is unusable.
+var authErrors = map[string]bool{
+	"invalid_auth":           true,
+	"not_authed":             true,
+	"token_revoked":          true,
+	"account_inactive":       true,
+	"not_allowed_token_type": true,
+	"missing_scope":          true,
+}
+
+// openConnection calls apps.connections.open and returns the WebSocket URL of
+// a new Socket Mode connection.
+func openConnection(ctx context.Context, cfg *config) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.SlackAPIURL+"/apps.connections.open", nil)
+	if err != nil {
+		return "", fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.AppToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("apps.connections.open: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		OK    bool   `json:"ok"`
+		URL   string `json:"url"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("apps.connections.open: HTTP %d: decode response: %w", resp.StatusCode, err)
+	}
+	switch {
+	case !out.OK && authErrors[out.Error]:
+		return "", fmt.Errorf("%w: apps.connections.open: %s", errAuthRejected, out.Error)
+	case !out.OK:
+		return "", fmt.Errorf("apps.connections.open: %s", out.Error)
+	case out.URL == "":
+		return "", fmt.Errorf("apps.connections.open: response has no url")
+	}
+	return out.URL, nil
+}
+
+// socketEnvelope is a Socket Mode message. Envelopes with an EnvelopeID must
+// be acknowledged within a few seconds or Slack redelivers them.
+type socketEnvelope struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Type       string          `json:"type"`
+	Reason     string          `json:"reason"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// eventsAPIPayload is the payload of an "events_api" envelope.
+type eventsAPIPayload struct {
+	TeamID string       `json:"team_id"`
+	Event  messageEvent `json:"event"`
+}
+
+// messageEvent holds the fields of a Slack message event the gateway uses.
+type messageEvent struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type"`
+	User        string `json:"user"`
+	BotID       string `json:"bot_id"`
+	Text        string `json:"text"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts"`
+}
+
+// translateEnvelope turns a Socket Mode envelope into an ACP event. It
+// reports false for envelopes that are not plain human channel messages.
+func translateEnvelope(source string, env socketEnvelope) (acpEvent, bool) {
+	if env.Type != "events_api" {
+		return acpEvent{}, false
+	}
+	var p eventsAPIPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil {
+		slog.Warn("ignoring malformed events_api payload", "envelope_id", env.EnvelopeID, "err", err)
+		return acpEvent{}, false
+	}
+	ev := p.Event
+	if ev.Type != "message" || ev.Subtype != "" || ev.BotID != "" || ev.User == "" {
+		return acpEvent{}, false
+	}
+
+	data := map[string]interface{}{
+		"channel": ev.Channel,
+		"user":    ev.User,
+		"ts":      ev.TS,
+	}
+	if ev.ChannelType != "" {
+		data["channel_type"] = ev.ChannelType
+	}
+	if ev.ThreadTS != "" {
+		data["thread_ts"] = ev.ThreadTS
+	}
+	if p.TeamID != "" {
+		data["team"] = p.TeamID
+	}
+	return acpEvent{
+		Source: source,
+		Type:   "slack.message",
+		TS:     slackTime(ev.TS),
+		Payload: acpEventPayload{
+			Message: ev.Text,
+			Data:    data,
+		},
+	}, true
+}
+
+// slackTime converts a Slack message timestamp ("1700000000.123456") to a
+// time, falling back to now when it cannot be parsed.
+func slackTime(ts string) time.Time {
+	secs, frac, _ := strings.Cut(ts, ".")
+	s, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Now().UTC()
+	}
+	var us int64
+	if frac != "" {
+		us, _ = strconv.ParseInt((frac + "000000")[:6], 10, 64)
+	}
+	return time.Unix(s, us*int64(time.Microsecond)).UTC()
+}
+
+// ─── ACP event types ──────────────────────────────────────────────────────────
+
+// acpEvent is the normalised envelope posted to ACP POST /events/{source}.
+// This mirrors common/spec/envelope.Event — reproduced here so the binary has
+// zero in-tree dependencies and can be built as a standalone artefact.
+type acpEvent struct {
+	Source  string          `json:"source"`
+	Type    string          `json:"type"`
+	TS      time.Time       `json:"ts"`
+	Payload acpEventPayload `json:"payload"`
+}
+
+type acpEventPayload struct {
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// postEvent sends a single event envelope to the agent's ACP endpoint.
+func postEvent(ctx context.Context, cfg *config, evt acpEvent) error {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	url := cfg.ACPURL + "/events/" + cfg.Source
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.ACPToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.ACPToken)
+	}
+
+	resp, err := cfg.acpHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("ACP returned HTTP %d for %s", resp.StatusCode, url)
+	}
+
+	return nil
+}
+
+// ─── Gateway loop ────────────────────────────────────────────────────────────
+
+// Reconnect backoff bounds. The delay doubles after each failed connection
+// and resets once Slack greets a connection with "hello".
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// postTimeout bounds one POST /events call. ACP queues events and answers at
+// once, well within Slack's few-second acknowledgement window.
+const postTimeout = 10 * time.Second
+
+// runGateway keeps a Socket Mode connection open, reconnecting when Slack
+// drops or recycles it, until ctx is cancelled (e.g. on SIGTERM). It returns
+// an error only when Slack rejects the app token.
+func runGateway(ctx context.Context, cfg *config) error {
+	slog.Info("ruriko-gw-slack started", "source", cfg.Source, "slack_api", cfg.SlackAPIURL)
+
+	delay := minReconnectDelay
+	for {
+		greeted, err := runSession(ctx, cfg)
+		if ctx.Err() != nil {
+			slog.Info("ruriko-gw-slack shutting down")
+			return nil
+		}
+		if errors.Is(err, errAuthRejected) {
+			return err
+		}
+		if greeted {
+			delay = minReconnectDelay
+		}
+		slog.Warn("Slack connection lost; reconnecting", "err", err, "in", delay)
+		select {
+		case <-ctx.Done():
+			slog.Info("ruriko-gw-slack shutting down")
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// runSession runs one Socket Mode connection until it fails, Slack asks the
+// gateway to reconnect, or ctx is cancelled. greeted reports whether Slack
+// sent its "hello", i.e. the connection was established.
+func runSession(ctx context.Context, cfg *config) (greeted bool, err error) {
+	wsURL, err := openConnection(ctx, cfg)
+	if err != nil {
+		return false, err
+	}
+	ws, err := dialWebSocket(ctx, wsURL)
+	if err != nil {
+		return false, err
+	}
+	defer ws.Close()
+	stop := context.AfterFunc(ctx, func() { ws.Close() })
+	defer stop()
+	pingCtx, cancelPing := context.WithCancel(ctx)
+	defer cancelPing()
+	go ws.keepAlive(pingCtx)
+
+	for {
+		raw, err := ws.readMessage()
+		if err != nil {
+			return greeted, err
+		}
+		var env socketEnvelope
+		if err := json.Unmarshal(raw, &env); err != nil {
+			slog.Warn("ignoring malformed Socket Mode message", "err", err)
+			continue
+		}
+
+		switch env.Type {
+		case "hello":
+			greeted = true
+			slog.Info("connected to Slack Socket Mode", "source", cfg.Source)
+		case "disconnect":
+			return greeted, fmt.Errorf("Slack requested reconnect: %s", env.Reason)
+		default:
+			if evt, ok := translateEnvelope(cfg.Source, env); ok {
+				postCtx, cancel := context.WithTimeout(ctx, postTimeout)
+				err := postEvent(postCtx, cfg, evt)
+				cancel()
+				if err != nil {
+					// No ack: Slack redelivers the envelope.
+					slog.Error("failed to forward Slack message; leaving it for redelivery", "envelope_id", env.EnvelopeID, "err", err)
+					continue
+				}
+			}
+		}
+
+		if env.EnvelopeID != "" {
+			ack, _ := json.Marshal(map[string]string{"envelope_id": env.EnvelopeID})
+			if err := ws.writeText(ack); err != nil {
+				return greeted, fmt.Errorf("acknowledge envelope: %w", err)
+			}
+		}
+	}
+}
+
+// ─── Main ─────────────────────────────────────────────────────────────────────
+
+func main() {
+	// Configure structured logging.
+	logLevel := slog.LevelInfo
+	var logHandler slog.Handler
+	if os.Getenv("LOG_FORMAT") == "json" {
+		logHandler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})
+	} else {
+		logHandler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})
+	}
+	slog.SetDefault(slog.New(logHandler))
+
+	cfg, err := loadConfig()
+	if err != nil {
+		slog.Error("configuration error", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	if err := runGateway(ctx, cfg); err != nil {
+		slog.Error("ruriko-gw-slack exited", "err", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// messageEnvelope is a synthetic Socket Mode delivery of a channel message.
+const messageEnvelope = `{
+  "envelope_id": "env-1",
+  "type": "events_api",
+  "accepts_response_payload": false,
+  "payload": {
+    "team_id": "T01",
+    "event": {
+      "type": "message",
+      "channel": "C123",
+      "channel_type": "channel",
+      "user": "U456",
+      "text": "deploy is stuck, can you look?",
+      "ts": "1700000000.250000",
+      "thread_ts": "1699999999.000100"
+    }
+  }
+}`
+
+func TestTranslateEnvelope_MessageEvent(t *testing.T) {
+	var env socketEnvelope
+	if err := json.Unmarshal([]byte(messageEnvelope), &env); err != nil {
+		t.Fatal(err)
+	}
+
+	evt, ok := translateEnvelope("slack", env)
+	if !ok {
+		t.Fatal("expected the message to be forwarded")
+	}
+	if evt.Source != "slack" || evt.Type != "slack.message" {
+		t.Errorf("source/type = %q/%q, want slack/slack.message", evt.Source, evt.Type)
+	}
+	if want := time.Unix(1700000000, 250000000).UTC(); !evt.TS.Equal(want) {
+		t.Errorf("ts = %v, want %v", evt.TS, want)
+	}
+	if evt.Payload.Message != "deploy is stuck, can you look?" {
+		t.Errorf("message = %q", evt.Payload.Message)
+	}
+	want := map[string]interface{}{
+		"channel": "C123", "user": "U456", "ts": "1700000000.250000",
+		"channel_type": "channel", "thread_ts": "1699999999.000100", "team": "T01",
+	}
+	for k, v := range want {
+		if evt.Payload.Data[k] != v {
+			t.Errorf("data[%s] = %v, want %v", k, evt.Payload.Data[k], v)
+		}
+	}
+}
+
+func TestTranslateEnvelope_SkipsNonMessages(t *testing.T) {
+	cases := map[string]string{
+		"hello":      `{"type":"hello"}`,
+		"reaction":   `{"envelope_id":"e","type":"events_api","payload":{"event":{"type":"reaction_added","user":"U1"}}}`,
+		"edit":       `{"envelope_id":"e","type":"events_api","payload":{"event":{"type":"message","subtype":"message_changed","channel":"C1"}}}`,
+		"bot":        `{"envelope_id":"e","type":"events_api","payload":{"event":{"type":"message","bot_id":"B1","user":"U1","text":"hi"}}}`,
+		"slash":      `{"envelope_id":"e","type":"slash_commands","payload":{"command":"/deploy"}}`,
+		"no payload": `{"envelope_id":"e","type":"events_api","payload":"oops"}`,
+	}
+	for name, raw := range cases {
+		var env socketEnvelope
+		if err := json.Unmarshal([]byte(raw), &env); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if evt, ok := translateEnvelope("slack", env); ok {
+			t.Errorf("%s: forwarded %+v, want it dropped", name, evt)
+		}
+	}
+}
+
+func TestLoadConfig_Validation(t *testing.T) {
+	t.Setenv("ACP_URL", "http://localhost:8765")
+	t.Setenv("GW_SOURCE", "slack")
+	t.Setenv("GW_SLACK_APP_TOKEN", "")
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "GW_SLACK_APP_TOKEN") {
+		t.Errorf("missing token: err = %v", err)
+	}
+
+	t.Setenv("GW_SLACK_APP_TOKEN", "xoxb-bot-token")
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "app-level token") {
+		t.Errorf("bot token: err = %v", err)
+	}
+
+	t.Setenv("GW_SLACK_APP_TOKEN", "xapp-1-abc")
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.SlackAPIURL != defaultSlackAPIURL {
+		t.Errorf("SlackAPIURL = %q, want the default", cfg.SlackAPIURL)
+	}
+}
+
+// TestRunSession_ForwardsMessages drives a session against a fake Slack that
+// serves apps.connections.open and a Socket Mode WebSocket, and checks the
+// envelope is acknowledged and posted to ACP.
+func TestRunSession_ForwardsMessages(t *testing.T) {
+	posted := make(chan acpEvent, 1)
+	acp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events/slack" || r.Header.Get("Authorization") != "Bearer acp-token" {
+			t.Errorf("ACP request %s with auth %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var evt acpEvent
+		if err := json.NewDecoder(r.Body).Decode(&evt); err != nil {
+			t.Errorf("decode event: %v", err)
+		}
+		posted <- evt
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(acp.Close)
+
+	acks := make(chan string, 1)
+	slackAPI := fakeSlack(t, func(conn net.Conn, rw *bufio.ReadWriter) {
+		serverWrite(t, conn, `{"type":"hello","num_connections":1}`)
+		serverWrite(t, conn, messageEnvelope)
+		var ack struct {
+			EnvelopeID string `json:"envelope_id"`
+		}
+		c := &wsConn{br: rw.Reader}
+		if _, op, payload, err := c.readFrame(); err != nil || op != opText {
+			t.Errorf("read ack: op %#x, %v", op, err)
+		} else if err := json.Unmarshal(payload, &ack); err != nil {
+			t.Errorf("decode ack: %v", err)
+		}
+		if len(posted) == 0 {
+			t.Error("envelope acknowledged before the event reached ACP")
+		}
+		acks <- ack.EnvelopeID
+		serverWrite(t, conn, `{"type":"disconnect","reason":"refresh_requested"}`)
+		_, _ = io.Copy(io.Discard, rw) // until the client hangs up
+	})
+
+	cfg := &config{ACPURL: acp.URL, ACPToken: "acp-token", Source: "slack", AppToken: "xapp-1-test", SlackAPIURL: slackAPI}
+	greeted, err := runSession(context.Background(), cfg)
+	if !greeted || err == nil || !strings.Contains(err.Error(), "refresh_requested") {
+		t.Errorf("runSession = %v, %v; want the disconnect after hello", greeted, err)
+	}
+	if id := <-acks; id != "env-1" {
+		t.Errorf("ack = %q, want env-1", id)
+	}
+	select {
+	case evt := <-posted:
+		if evt.Type != "slack.message" || evt.Payload.Data["channel"] != "C123" || evt.Payload.Data["user"] != "U456" {
+			t.Errorf("posted %+v", evt)
+		}
+	default:
+		t.Fatal("no event was posted to ACP")
+	}
+
+	cfg.AppToken = "xapp-1-revoked"
+	if _, err := runSession(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "invalid_auth") {
+		t.Errorf("runSession with a bad token = %v, want invalid_auth", err)
+	}
+}
+
+// TestRunSession_LeavesFailedPostsUnacknowledged checks that an envelope ACP
+// did not accept gets no ack, so Slack redelivers it.
+func TestRunSession_LeavesFailedPostsUnacknowledged(t *testing.T) {
+	acp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "queue full", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(acp.Close)
+
+	slackAPI := fakeSlack(t, func(conn net.Conn, rw *bufio.ReadWriter) {
+		serverWrite(t, conn, `{"type":"hello","num_connections":1}`)
+		serverWrite(t, conn, messageEnvelope)
+		_ = conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		c := &wsConn{br: rw.Reader}
+		if _, op, payload, err := c.readFrame(); err == nil {
+			t.Errorf("got frame op %#x %q, want no ack for the failed post", op, payload)
+		}
+		serverWrite(t, conn, `{"type":"disconnect","reason":"refresh_requested"}`)
+		_ = conn.SetReadDeadline(time.Time{})
+		_, _ = io.Copy(io.Discard, rw)
+	})
+
+	cfg := &config{ACPURL: acp.URL, Source: "slack", AppToken: "xapp-1-test", SlackAPIURL: slackAPI}
+	if _, err := runSession(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "refresh_requested") {
+		t.Errorf("runSession = %v, want the disconnect", err)
+	}
+}
+
+// TestRunSession_DetectsDeadConnection checks the keepalive: the gateway
+// pings a quiet connection, and gives up on one that stops answering.
+func TestRunSession_DetectsDeadConnection(t *testing.T) {
+	interval, timeout := wsPingInterval, wsReadTimeout
+	wsPingInterval, wsReadTimeout = 20*time.Millisecond, 200*time.Millisecond
+	t.Cleanup(func() { wsPingInterval, wsReadTimeout = interval, timeout })
+
+	pinged := make(chan bool, 1)
+	slackAPI := fakeSlack(t, func(conn net.Conn, rw *bufio.ReadWriter) {
+		serverWrite(t, conn, `{"type":"hello","num_connections":1}`)
+		c := &wsConn{br: rw.Reader}
+		_, op, _, err := c.readFrame()
+		pinged <- err == nil && op == opPing
+		_, _ = io.Copy(io.Discard, rw) // read pings, never answer
+	})
+
+	cfg := &config{ACPURL: "http://127.0.0.1:0", Source: "slack", AppToken: "xapp-1-test", SlackAPIURL: slackAPI}
+	done := make(chan error, 1)
+	go func() {
+		_, err := runSession(context.Background(), cfg)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "timeout") {
+			t.Errorf("runSession = %v, want a read timeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runSession did not notice the silent connection")
+	}
+	if !<-pinged {
+		t.Error("the gateway did not ping the quiet connection")
+	}
+}
+
+// fakeSlack serves apps.connections.open for the app token xapp-1-test and
+// hands the upgraded Socket Mode connection to link. It returns the Web API
+// base URL.
+func fakeSlack(t *testing.T, link func(net.Conn, *bufio.ReadWriter)) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	slack := httptest.NewServer(mux)
+	t.Cleanup(slack.Close)
+	mux.HandleFunc("/api/apps.connections.open", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xapp-1-test" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "invalid_auth"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "url": "ws" + strings.TrimPrefix(slack.URL, "http") + "/link"})
+	})
+	mux.HandleFunc("/link", func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := upgrade(w, r)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		link(conn, rw)
+	})
+	return slack.URL + "/api"
+}
+
+// upgrade completes a server-side WebSocket handshake.
+func upgrade(w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " +
+		wsAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, rw, nil
+}
+
+// serverWrite sends msg as an unmasked text frame.
+func serverWrite(t *testing.T, conn net.Conn, msg string) {
+	t.Helper()
+	frame := []byte{0x80 | opText}
+	if len(msg) < 126 {
+		frame = append(frame, byte(len(msg)))
+	} else {
+		frame = binary.BigEndian.AppendUint16(append(frame, 126), uint16(len(msg)))
+	}
+	if _, err := conn.Write(append(frame, msg...)); err != nil {
+		t.Errorf("write frame: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// A minimal RFC 6455 WebSocket client, enough for Slack Socket Mode: text
+// messages in, text acknowledgements out, ping/pong and close. It is kept in
+// the binary so the gateway has no dependencies outside the standard library.
+
+// WebSocket opcodes (RFC 6455 §5.2).
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// maxWSMessageBytes bounds a single (reassembled) message from the server.
+const maxWSMessageBytes = 4 << 20
+
+// wsAcceptGUID is the key suffix hashed into Sec-WebSocket-Accept.
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Keepalive timing. The client pings every wsPingInterval, and a read that
+// sees no frame at all (message, ping or pong) for wsReadTimeout fails, so a
+// connection that died without a FIN is noticed and replaced. Variables so
+// tests can shorten them.
+var (
+	wsPingInterval = 30 * time.Second
+	wsReadTimeout  = 75 * time.Second
+)
+
+// errWSClosed is returned by readMessage once the server sent a close frame.
+var errWSClosed = errors.New("websocket closed by server")
+
+type wsConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	writeMu sync.Mutex
+}
+
+// dialWebSocket opens a ws:// or wss:// connection and performs the opening
+// handshake.
+func dialWebSocket(ctx context.Context, rawURL string) (*wsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse websocket URL: %w", err)
+	}
+	addr := u.Host
+	var dialer net.Dialer
+	var conn net.Conn
+	switch u.Scheme {
+	case "wss":
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), "443")
+		}
+		td := &tls.Dialer{NetDialer: &dialer, Config: &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}}
+		conn, err = td.DialContext(ctx, "tcp", addr)
+	case "ws":
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), "80")
+		}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	default:
+		return nil, fmt.Errorf("unsupported websocket scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", u.Host, err)
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("generate websocket key: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	if _, err := fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n",
+		u.RequestURI(), u.Host, key); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("send websocket handshake: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("read websocket handshake: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("websocket handshake: HTTP %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != wsAccept(key) {
+		conn.Close()
+		return nil, fmt.Errorf("websocket handshake: bad Sec-WebSocket-Accept %q", got)
+	}
+	return &wsConn{conn: conn, br: br}, nil
+}
+
+// wsAccept returns the Sec-WebSocket-Accept value expected for key.
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// readMessage returns the next text or binary message, answering pings and
+// reassembling fragments on the way. Each frame must arrive within
+// wsReadTimeout.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(wsReadTimeout)); err != nil {
+			return nil, err
+		}
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			_ = c.writeFrame(opClose, payload)
+			return nil, errWSClosed
+		case opText, opBinary, opContinuation:
+		default:
+			return nil, fmt.Errorf("websocket: unexpected opcode %#x", op)
+		}
+		if len(msg)+len(payload) > maxWSMessageBytes {
+			return nil, fmt.Errorf("websocket: message exceeds %d bytes", maxWSMessageBytes)
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = hdr[0]&0x80 != 0, hdr[0]&0x0F
+	masked := hdr[1]&0x80 != 0
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxWSMessageBytes {
+		return false, 0, nil, fmt.Errorf("websocket: frame of %d bytes exceeds %d", n, maxWSMessageBytes)
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// writeText sends p as a single text message.
+func (c *wsConn) writeText(p []byte) error { return c.writeFrame(opText, p) }
+
+// writeFrame sends one final, client-masked frame.
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	frame := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return fmt.Errorf("generate frame mask: %w", err)
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// keepAlive pings the server every wsPingInterval until ctx is done. The
+// pongs keep readMessage's deadline from expiring on a quiet connection; a
+// failed ping closes the connection so readMessage returns.
+func (c *wsConn) keepAlive(ctx context.Context) {
+	t := time.NewTicker(wsPingInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := c.writeFrame(opPing, nil); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+// Close closes the underlying connection without a closing handshake.
+func (c *wsConn) Close() error { return c.conn.Close() }
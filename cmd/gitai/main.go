@@ -16,14 +16,45 @@
 //
 //	GITAI_GOSUTO_FILE     - path to initial gosuto.yaml (if not using ACP push)
 //	GITAI_ACP_ADDR        - ACP HTTP server listen address (default ":8765")
-//	GITAI_ACP_TOKEN       - bearer token required on all ACP requests; empty = auth disabled (dev)//	FEATURE_DIRECT_SECRET_PUSH - re-enable legacy POST /secrets/apply (default: false; OFF in production)//	LLM_PROVIDER          - LLM backend: "openai" (default)
+//	GITAI_ACP_TLS_CERT_FILE - PEM certificate for serving ACP over HTTPS (with GITAI_ACP_TLS_KEY_FILE)
+//	GITAI_ACP_TLS_KEY_FILE  - PEM private key for the ACP certificate
+//	GITAI_ACP_TOKEN       - bearer token required on all ACP requests; empty = auth disabled (dev)//	FEATURE_DIRECT_SECRET_PUSH - re-enable legacy POST /secrets/apply (default: false; OFF in production)//	LLM_PROVIDER          - LLM backend: "openai" (default), "anthropic" or "gemini"
 //	LLM_API_KEY           - API key for the LLM provider
 //	LLM_BASE_URL          - override LLM API base URL (e.g. for Ollama)
-//	LLM_MODEL             - model name (e.g. "gpt-4o")
+//	LLM_MODEL             - model name (default "gpt-4o"; set it when using "anthropic" or "gemini")
 //	LLM_MAX_TOKENS        - max tokens per response (default: provider default)
 //	GITAI_LLM_CALL_HARD_LIMIT - hard cap on total LLM calls before exit (default: 0=disabled)
+//	GITAI_EVENT_DLQ_ENABLE - record failed event turns in a dead-letter queue (default: false)
+//	GITAI_EVENT_DLQ_MAX_RETRIES - manual retries allowed per dead-lettered event (default: 3)
+//	GITAI_EVENT_DLQ_MAX_ENTRIES - dead-lettered events kept, newest first (default: 1000)
+//	FEATURE_TURN_TRANSCRIPTS - store secret-redacted LLM turn transcripts (default: false)
+//	GITAI_TURN_TRANSCRIPT_RETENTION - turn transcripts kept, newest first (default: 100)
+//	GITAI_TOOL_CALL_RETENTION - tool-call audit entries kept, newest first (default: 10000)
+//	GITAI_ROOM_HISTORY_TURNS - recent exchanges per room replayed into chat turns (default: 0=disabled)
+//	GITAI_ROOM_HISTORY_MAX_TOKENS - estimated token cap on a room's replayed exchanges (default: 2000)
+//	GITAI_STREAM_REPLIES  - stream chat replies as Matrix edits when the LLM provider supports it (default: true)
+//	GITAI_EVENT_QUEUE_SIZE - events buffered before ingress answers 503 (default: 64)
+//	GITAI_EVENT_WORKERS   - event turns run concurrently (default: 4)
+//	GITAI_MCP_SHUTDOWN_GRACE - wait after SIGTERM before killing a stopping MCP server (default: 5s)
+//	FEATURE_STRICT_EVENT_AUTH - require the ACP token on /events even from localhost (default: false)
+//	FEATURE_SEALED_SECRETS - keep cached secrets encrypted in memory until read (default: false)
+//	GITAI_DEFAULT_MAX_EVENTS_PER_MINUTE - event rate limit when Gosuto sets none (default: 60; 0=unlimited)
+//	GITAI_EVENT_RATE_ALGORITHM - event rate limiting: "sliding" (default) or "fixed"
+//	GITAI_ACP_IDEMPOTENCY_MAX_ENTRIES - responses kept for ACP idempotent replays (default: 10000)
+//	GITAI_ACP_MAX_SECRET_LEASES - leases accepted per POST /secrets/token (default: 100)
+//	GOSUTO_APPROVAL_TTL_MIN_SECONDS - shortest approvals.ttlSeconds accepted (default: 60)
+//	GOSUTO_APPROVAL_TTL_MAX_SECONDS - longest approvals.ttlSeconds accepted (default: 86400)
 //	LOG_LEVEL             - "debug", "info", "warn", "error" (default: "info")
 //	LOG_FORMAT            - "text" or "json" (default: "text")
+//	LOG_REDACT            - scrub API keys and tokens from log output (default: true)
+//	LOG_SAMPLE_EVERY      - keep 1 in N sampled INFO lines (default: 1=log all)
+//	LOG_SAMPLE_MESSAGES   - comma-separated INFO messages to sample (default: "event received,event processed")
+//	LOG_FILE              - also write logs to this file (default: "" = stdout only)
+//	LOG_FILE_MAX_SIZE_MB  - rotate LOG_FILE once it reaches this size (default: 100; 0=never)
+//	LOG_FILE_MAX_BACKUPS  - rotated log files kept (default: 5)
+//	LOG_FILE_STDOUT       - keep logging to stdout when LOG_FILE is set (default: true)
+//	DEBUG_PPROF           - serve net/http/pprof on DEBUG_PPROF_ADDR (default: false)
+//	DEBUG_PPROF_ADDR      - loopback address for the pprof server (default: "127.0.0.1:6060")
 package main
 
 import (
@@ -31,12 +62,21 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/bdobrica/Ruriko/common/debugserver"
 	"github.com/bdobrica/Ruriko/common/environment"
+	"github.com/bdobrica/Ruriko/common/spec/gosuto"
 	"github.com/bdobrica/Ruriko/internal/gitai/app"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
 	"github.com/bdobrica/Ruriko/internal/gitai/matrix"
+	"github.com/bdobrica/Ruriko/internal/gitai/mcp"
+	"github.com/bdobrica/Ruriko/internal/gitai/observability"
 )
 
 func main() {
+	// A gitai process re-executed to start an MCP server under resource
+	// limits execs the server here and never returns.
+	mcp.RunLimitsWrapper()
+
 	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
@@ -76,17 +116,58 @@ func loadConfig() (*app.Config, error) {
 		return nil, err
 	}
 
+	if err := gosuto.SetApprovalTTLBounds(
+		environment.IntOr("GOSUTO_APPROVAL_TTL_MIN_SECONDS", gosuto.MinApprovalTTLSeconds),
+		environment.IntOr("GOSUTO_APPROVAL_TTL_MAX_SECONDS", gosuto.MaxApprovalTTLSeconds),
+	); err != nil {
+		return nil, fmt.Errorf("invalid GOSUTO_APPROVAL_TTL_*_SECONDS: %w", err)
+	}
+
 	return &app.Config{
-		AgentID:                 agentID,
-		DatabasePath:            environment.StringOr("GITAI_DB_PATH", "/data/gitai.db"),
-		GosutoFile:              environment.StringOr("GITAI_GOSUTO_FILE", ""),
-		ACPAddr:                 environment.StringOr("GITAI_ACP_ADDR", ":8765"),
-		ACPToken:                environment.StringOr("GITAI_ACP_TOKEN", ""),
-		DirectSecretPushEnabled: environment.BoolOr("FEATURE_DIRECT_SECRET_PUSH", false),
-		LogLevel:                environment.StringOr("LOG_LEVEL", "info"),
-		LogFormat:               environment.StringOr("LOG_FORMAT", "text"),
-		LLMCallHardLimit:        environment.IntOr("GITAI_LLM_CALL_HARD_LIMIT", 0),
-		MemoryContextEnabled:    environment.BoolOr("GITAI_MEMORY_CONTEXT_ENABLE", false),
+		AgentID:                   agentID,
+		DatabasePath:              environment.StringOr("GITAI_DB_PATH", "/data/gitai.db"),
+		GosutoFile:                environment.StringOr("GITAI_GOSUTO_FILE", ""),
+		ACPAddr:                   environment.StringOr("GITAI_ACP_ADDR", ":8765"),
+		ACPToken:                  environment.StringOr("GITAI_ACP_TOKEN", ""),
+		ACPTLSCertFile:            environment.StringOr("GITAI_ACP_TLS_CERT_FILE", ""),
+		ACPTLSKeyFile:             environment.StringOr("GITAI_ACP_TLS_KEY_FILE", ""),
+		RegisterURL:               environment.StringOr("RURIKO_REGISTER_URL", ""),
+		RegisterToken:             environment.StringOr("RURIKO_REGISTER_TOKEN", ""),
+		SecretRefreshURL:          environment.StringOr("RURIKO_SECRET_REFRESH_URL", ""),
+		AdvertiseURL:              environment.StringOr("GITAI_ADVERTISE_URL", ""),
+		TunnelURL:                 environment.StringOr("RURIKO_TUNNEL_URL", ""),
+		DirectSecretPushEnabled:   environment.BoolOr("FEATURE_DIRECT_SECRET_PUSH", false),
+		StrictEventAuth:           environment.BoolOr("FEATURE_STRICT_EVENT_AUTH", false),
+		SealedSecrets:             environment.BoolOr("FEATURE_SEALED_SECRETS", false),
+		DefaultMaxEventsPerMinute: environment.IntOr("GITAI_DEFAULT_MAX_EVENTS_PER_MINUTE", 60),
+		EventRateAlgorithm:        environment.StringOr("GITAI_EVENT_RATE_ALGORITHM", "sliding"),
+		ACPIdempotencyMaxEntries:  environment.IntOr("GITAI_ACP_IDEMPOTENCY_MAX_ENTRIES", control.DefaultIdempotencyMaxEntries),
+		ACPMaxSecretLeases:        environment.IntOr("GITAI_ACP_MAX_SECRET_LEASES", control.DefaultMaxSecretLeases),
+		LogLevel:                  environment.StringOr("LOG_LEVEL", "info"),
+		LogFormat:                 environment.StringOr("LOG_FORMAT", "text"),
+		LogRedact:                 environment.BoolOr("LOG_REDACT", true),
+		LogSampleEvery:            environment.IntOr("LOG_SAMPLE_EVERY", 1),
+		LogSampleMessages:         environment.StringSliceOr("LOG_SAMPLE_MESSAGES", observability.DefaultSampleMessages),
+		LogFile:                   environment.StringOr("LOG_FILE", ""),
+		LogFileMaxSizeMB:          environment.IntOr("LOG_FILE_MAX_SIZE_MB", 100),
+		LogFileMaxBackups:         environment.IntOr("LOG_FILE_MAX_BACKUPS", 5),
+		LogFileStdout:             environment.BoolOr("LOG_FILE_STDOUT", true),
+		DebugPprof:                environment.BoolOr("DEBUG_PPROF", false),
+		DebugPprofAddr:            environment.StringOr("DEBUG_PPROF_ADDR", debugserver.DefaultAddr),
+		LLMCallHardLimit:          environment.IntOr("GITAI_LLM_CALL_HARD_LIMIT", 0),
+		MemoryContextEnabled:      environment.BoolOr("GITAI_MEMORY_CONTEXT_ENABLE", false),
+		EventDLQEnabled:           environment.BoolOr("GITAI_EVENT_DLQ_ENABLE", false),
+		EventDLQMaxRetries:        environment.IntOr("GITAI_EVENT_DLQ_MAX_RETRIES", 3),
+		EventDLQMaxEntries:        environment.IntOr("GITAI_EVENT_DLQ_MAX_ENTRIES", 1000),
+		TurnTranscriptsEnabled:    environment.BoolOr("FEATURE_TURN_TRANSCRIPTS", false),
+		TurnTranscriptRetention:   environment.IntOr("GITAI_TURN_TRANSCRIPT_RETENTION", 100),
+		ToolCallRetention:         environment.IntOr("GITAI_TOOL_CALL_RETENTION", 10000),
+		RoomHistoryTurns:          environment.IntOr("GITAI_ROOM_HISTORY_TURNS", 0),
+		RoomHistoryMaxTokens:      environment.IntOr("GITAI_ROOM_HISTORY_MAX_TOKENS", 2000),
+		StreamReplies:             environment.BoolOr("GITAI_STREAM_REPLIES", true),
+		EventQueueSize:            environment.IntOr("GITAI_EVENT_QUEUE_SIZE", 64),
+		EventWorkers:              environment.IntOr("GITAI_EVENT_WORKERS", 4),
+		MCPShutdownGrace:          environment.DurationOr("GITAI_MCP_SHUTDOWN_GRACE", mcp.DefaultShutdownGrace),
 		Matrix: matrix.Config{
 			Homeserver:  homeserver,
 			UserID:      userID,
@@ -5,9 +5,12 @@ import (
 	"io/fs"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/bdobrica/Ruriko/common/crypto"
+	"github.com/bdobrica/Ruriko/common/debugserver"
 	"github.com/bdobrica/Ruriko/common/environment"
+	"github.com/bdobrica/Ruriko/common/spec/gosuto"
 	"github.com/bdobrica/Ruriko/common/version"
 	"github.com/bdobrica/Ruriko/internal/ruriko/app"
 	"github.com/bdobrica/Ruriko/internal/ruriko/matrix"
@@ -79,6 +82,13 @@ func loadConfig() (*app.Config, error) {
 		return nil, fmt.Errorf("invalid RURIKO_MASTER_KEY: %w", err)
 	}
 
+	if err := gosuto.SetApprovalTTLBounds(
+		environment.IntOr("GOSUTO_APPROVAL_TTL_MIN_SECONDS", gosuto.MinApprovalTTLSeconds),
+		environment.IntOr("GOSUTO_APPROVAL_TTL_MAX_SECONDS", gosuto.MaxApprovalTTLSeconds),
+	); err != nil {
+		return nil, fmt.Errorf("invalid GOSUTO_APPROVAL_TTL_*_SECONDS: %w", err)
+	}
+
 	adminSenders := environment.StringSliceOr("MATRIX_ADMIN_SENDERS", nil)
 	dbPath := environment.StringOr("DATABASE_PATH", "./ruriko.db")
 	enableDocker := environment.BoolOr("DOCKER_ENABLE", false)
@@ -108,14 +118,37 @@ func loadConfig() (*app.Config, error) {
 		EnableDocker:      enableDocker,
 		DockerNetwork:     dockerNetwork,
 		ReconcileInterval: reconcileInterval,
-		AdminSenders:      adminSenders,
-		Provisioning:      provisioningCfg,
-		HTTPAddr:          environment.StringOr("HTTP_ADDR", ""),
-		KuzeBaseURL:       environment.StringOr("KUZE_BASE_URL", ""),
-		KuzeTTL:           environment.DurationOr("KUZE_TTL", 0),
-		DefaultAgentImage: environment.StringOr("DEFAULT_AGENT_IMAGE", ""),
-		AuditRoomID:       environment.StringOr("MATRIX_AUDIT_ROOM", ""),
-		TemplatesFS:       loadTemplatesFS(),
+		// Agent heartbeat / liveness thresholds.
+		HeartbeatInterval:    environment.DurationOr("HEARTBEAT_INTERVAL", time.Minute),
+		LivenessStaleAfter:   environment.DurationOr("HEARTBEAT_STALE_AFTER", 0),
+		LivenessOfflineAfter: environment.DurationOr("HEARTBEAT_OFFLINE_AFTER", 0),
+		AdminSenders:         adminSenders,
+		Provisioning:         provisioningCfg,
+		HTTPAddr:             environment.StringOr("HTTP_ADDR", ""),
+		HTTPAccessLog:        environment.BoolOr("HTTP_ACCESS_LOG", false),
+		DebugPprof:           environment.BoolOr("DEBUG_PPROF", false),
+		DebugPprofAddr:       environment.StringOr("DEBUG_PPROF_ADDR", debugserver.DefaultAddr),
+		KuzeBaseURL:          environment.StringOr("KUZE_BASE_URL", ""),
+		KuzeTTL:              environment.DurationOr("KUZE_TTL", 0),
+		// TLS for agent ACP servers that serve HTTPS (optional).
+		ACPCAFile:                environment.StringOr("ACP_TLS_CA_FILE", ""),
+		ACPTLSInsecureSkipVerify: environment.BoolOr("ACP_TLS_INSECURE_SKIP_VERIFY", false),
+		ACPGzipMinBytes:          environment.IntOr("ACP_GZIP_MIN_BYTES", 0),
+		// Agent self-registration (optional; requires HTTP_ADDR).
+		RegisterBootstrapToken: environment.StringOr("RURIKO_REGISTER_TOKEN", ""),
+		DashboardToken:         environment.StringOr("RURIKO_DASHBOARD_TOKEN", ""),
+		DefaultAgentImage:      environment.StringOr("DEFAULT_AGENT_IMAGE", ""),
+		GosutoStrict:           environment.BoolOr("RURIKO_GOSUTO_STRICT", false),
+		AuditRoomID:            environment.StringOr("MATRIX_AUDIT_ROOM", ""),
+		AuditQuietHours:        environment.StringOr("MATRIX_AUDIT_QUIET_HOURS", ""),
+		AuditMinSeverity:       environment.StringOr("MATRIX_AUDIT_MIN_SEVERITY", ""),
+		AuditExtraRoomIDs:      environment.StringSliceOr("MATRIX_AUDIT_EXTRA_ROOMS", nil),
+		// Additional audit sinks (optional).
+		AuditSlackWebhookURL:    environment.StringOr("AUDIT_SLACK_WEBHOOK_URL", ""),
+		AuditSlackMinSeverity:   environment.StringOr("AUDIT_SLACK_MIN_SEVERITY", ""),
+		AuditWebhookURL:         environment.StringOr("AUDIT_WEBHOOK_URL", ""),
+		AuditWebhookMinSeverity: environment.StringOr("AUDIT_WEBHOOK_MIN_SEVERITY", ""),
+		TemplatesFS:             loadTemplatesFS(),
 		Matrix: matrix.Config{
 			Homeserver:  homeserver,
 			UserID:      userID,
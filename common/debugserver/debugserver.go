@@ -0,0 +1,105 @@
+// Package debugserver serves the net/http/pprof profiling handlers on a
+// separate, loopback-only listener.
+//
+// Profiles expose heap contents and command lines, so the server refuses to
+// bind anything but a loopback address; reach it from outside the host or
+// container with a port-forward (e.g. "docker exec" or "kubectl
+// port-forward"). The handlers are mounted on the server's own mux: nothing
+// in Ruriko or Gitai serves http.DefaultServeMux, where importing
+// net/http/pprof also registers them.
+package debugserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// DefaultAddr is the listen address used when none is configured.
+const DefaultAddr = "127.0.0.1:6060"
+
+// Server is a pprof HTTP server bound to a loopback address.
+type Server struct {
+	addr   string
+	server *http.Server
+	ln     net.Listener
+}
+
+// New returns a server for addr, which must name a loopback host
+// ("localhost", 127.0.0.0/8 or ::1) so profiles are never exposed publicly.
+func New(addr string) (*Server, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("pprof address %q: %w", addr, err)
+	}
+	if host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return nil, fmt.Errorf("pprof address %q is not a loopback address", addr)
+		}
+	}
+	return &Server{addr: addr}, nil
+}
+
+// Handler returns a mux serving the pprof endpoints under /debug/pprof/.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// Start begins listening in the background and returns once the listener is
+// open. The server shuts down when ctx is cancelled or Stop is called.
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("pprof server: listen %s: %w", s.addr, err)
+	}
+	s.ln = ln
+	// No WriteTimeout: CPU profiles and traces stream for ?seconds=N.
+	s.server = &http.Server{
+		Handler:           Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	go func() {
+		slog.Info("pprof server listening", "addr", ln.Addr().String())
+		if err := s.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			slog.Error("pprof server stopped", "err", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+	return nil
+}
+
+// Addr returns the address the server listens on, or the configured address
+// before Start.
+func (s *Server) Addr() string {
+	if s.ln != nil {
+		return s.ln.Addr().String()
+	}
+	return s.addr
+}
+
+// Stop shuts down the server.
+func (s *Server) Stop() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		slog.Warn("pprof server shutdown error", "err", err)
+	}
+}
@@ -0,0 +1,60 @@
+package debugserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/common/debugserver"
+)
+
+func TestNew_RequiresLoopback(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:6060", "localhost:6060", "[::1]:6060", "127.0.0.2:0"} {
+		if _, err := debugserver.New(addr); err != nil {
+			t.Errorf("New(%q): %v", addr, err)
+		}
+	}
+	for _, addr := range []string{":6060", "0.0.0.0:6060", "[::]:6060", "10.0.0.5:6060", "example.com:6060", "6060"} {
+		if _, err := debugserver.New(addr); err == nil {
+			t.Errorf("New(%q) succeeded, want a non-loopback error", addr)
+		}
+	}
+}
+
+func TestServer_ServesPprofOnLoopback(t *testing.T) {
+	srv, err := debugserver.New("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := srv.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if !strings.HasPrefix(srv.Addr(), "127.0.0.1:") || strings.HasSuffix(srv.Addr(), ":0") {
+		t.Fatalf("Addr = %q, want the bound loopback port", srv.Addr())
+	}
+
+	for path, want := range map[string]string{
+		"/debug/pprof/":                  "goroutine",
+		"/debug/pprof/goroutine?debug=1": "goroutine profile:",
+		"/debug/pprof/cmdline":           "",
+	} {
+		resp, err := http.Get("http://" + srv.Addr() + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), want) {
+			t.Errorf("GET %s = %d %.80q, want 200 containing %q", path, resp.StatusCode, body, want)
+		}
+	}
+
+	srv.Stop()
+	if _, err := http.Get("http://" + srv.Addr() + "/debug/pprof/"); err == nil {
+		t.Error("server still answering after Stop")
+	}
+}
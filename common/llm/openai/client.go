@@ -3,12 +3,14 @@
 package openai
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -58,6 +60,14 @@ type ChatCompletionRequest struct {
 	Tools          []Tool          `json:"tools,omitempty"`
 	MaxTokens      int             `json:"max_tokens,omitempty"`
 	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+// StreamOptions configures streamed responses.
+type StreamOptions struct {
+	// IncludeUsage asks for a final chunk carrying token usage.
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // ResponseFormat configures OpenAI response formatting options.
@@ -173,3 +183,100 @@ func (c *Client) CreateChatCompletion(ctx context.Context, req ChatCompletionReq
 		Response:   parsed,
 	}, nil
 }
+
+// ChatCompletionChunk is one server-sent event of a streamed completion.
+type ChatCompletionChunk struct {
+	Choices []ChunkChoice `json:"choices"`
+	Usage   *Usage        `json:"usage,omitempty"`
+	Error   *APIError     `json:"error,omitempty"`
+}
+
+// ChunkChoice is the per-candidate part of a streamed chunk.
+type ChunkChoice struct {
+	Delta        ChunkDelta `json:"delta"`
+	FinishReason string     `json:"finish_reason"`
+}
+
+// ChunkDelta is the message increment carried by a streamed chunk.
+type ChunkDelta struct {
+	Role      string          `json:"role,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	ToolCalls []ToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// ToolCallDelta is a fragment of a streamed tool call. Fragments with the
+// same Index belong to one call; ID, Type and Name arrive in the first
+// fragment and Arguments is split across all of them.
+type ToolCallDelta struct {
+	Index    int          `json:"index"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// CreateChatCompletionStream calls POST /chat/completions with streaming
+// enabled and invokes onChunk for every chunk until the stream ends, onChunk
+// returns an error, or ctx is cancelled.
+func (c *Client) CreateChatCompletionStream(ctx context.Context, req ChatCompletionRequest, onChunk func(ChatCompletionChunk) error) error {
+	req.Stream = true
+	if req.StreamOptions == nil {
+		req.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create http request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("http request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(httpResp.Body, 64<<10))
+		var parsed ChatCompletionResponse
+		if json.Unmarshal(respBody, &parsed) == nil && parsed.Error != nil {
+			return fmt.Errorf("openai error %s: %s", parsed.Error.Type, parsed.Error.Message)
+		}
+		return fmt.Errorf("stream request failed with status %d", httpResp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue // blank separators, comments and other SSE fields
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("decode stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("openai error %s: %s", chunk.Error.Type, chunk.Error.Message)
+		}
+		if err := onChunk(chunk); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -62,3 +63,62 @@ func TestCreateChatCompletion_DecodeError(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestCreateChatCompletionStream_DeliversChunks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("request stream = %v, options = %+v; want streaming with usage", req.Stream, req.StreamOptions)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(": keep-alive\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"Hel\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n" +
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2,\"total_tokens\":5}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL})
+	var text strings.Builder
+	var finish string
+	var usage *Usage
+	err := c.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{Model: "gpt-test"}, func(chunk ChatCompletionChunk) error {
+		for _, ch := range chunk.Choices {
+			text.WriteString(ch.Delta.Content)
+			if ch.FinishReason != "" {
+				finish = ch.FinishReason
+			}
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream: %v", err)
+	}
+	if text.String() != "Hello" || finish != "stop" || usage == nil || usage.TotalTokens != 5 {
+		t.Errorf("text = %q, finish = %q, usage = %+v", text.String(), finish, usage)
+	}
+}
+
+func TestCreateChatCompletionStream_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"type":"rate_limit","message":"slow down"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL})
+	err := c.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{Model: "gpt-test"}, func(ChatCompletionChunk) error {
+		t.Error("onChunk called for an error response")
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "slow down") {
+		t.Fatalf("err = %v, want the API error", err)
+	}
+}
@@ -106,6 +106,25 @@ func (c *Client) SendMessageEvent(ctx context.Context, roomID id.RoomID, evtType
 	return err
 }
 
+// SendMessageEventID sends a generic Matrix event and returns its event ID.
+func (c *Client) SendMessageEventID(ctx context.Context, roomID id.RoomID, evtType event.Type, content interface{}) (id.EventID, error) {
+	resp, err := c.client.SendMessageEvent(ctx, roomID, evtType, content)
+	if err != nil {
+		return "", err
+	}
+	return resp.EventID, nil
+}
+
+// UploadBytes stores data in the homeserver's media repository and returns
+// its content URI.
+func (c *Client) UploadBytes(ctx context.Context, data []byte, contentType, fileName string) (id.ContentURI, error) {
+	resp, err := c.client.UploadBytesWithName(ctx, data, contentType, fileName)
+	if err != nil {
+		return id.ContentURI{}, err
+	}
+	return resp.ContentURI, nil
+}
+
 // UserTyping updates typing status.
 func (c *Client) UserTyping(ctx context.Context, roomID id.RoomID, typing bool, timeout time.Duration) error {
 	_, err := c.client.UserTyping(ctx, roomID, typing, timeout)
@@ -2,11 +2,13 @@
 //
 // The engine evaluates whether a proposed tool invocation is permitted,
 // requires approval, or is denied, based on the active Gosuto configuration.
-// Evaluation is purely deterministic -- no LLM involvement.
+// Evaluation is purely deterministic -- no LLM involvement. Gitai enforces
+// its decisions; Ruriko uses the same engine to report policy coverage.
 package policy
 
 import (
 	"fmt"
+	"strings"
 
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
 )
@@ -55,6 +57,9 @@ type Result struct {
 	Decision    Decision
 	MatchedRule string
 	Violation   *Violation
+	// Output is the matched rule's output transform, if any. It is set only
+	// when the call is allowed (with or without approval).
+	Output *gosutospec.OutputTransform
 }
 
 // Engine evaluates policy against the currently loaded Gosuto config.
@@ -72,6 +77,22 @@ func New(provider ConfigProvider) *Engine {
 	return &Engine{loader: provider}
 }
 
+// staticConfig is a ConfigProvider that always returns the same config.
+type staticConfig struct{ cfg *gosutospec.Config }
+
+func (s staticConfig) Config() *gosutospec.Config { return s.cfg }
+
+// ForConfig returns an Engine that evaluates against cfg alone. It lets code
+// outside a running agent (e.g. Ruriko's policy coverage report) apply the
+// exact rules the agent would.
+func ForConfig(cfg *gosutospec.Config) *Engine {
+	return New(staticConfig{cfg: cfg})
+}
+
+// DefaultRule is the Result.MatchedRule reported when no capability rule
+// matches and the call falls through to the default deny.
+const DefaultRule = "<default>"
+
 // Evaluate checks whether calling tool on mcpServer with the given args is
 // permitted by the current Gosuto policy.
 //
@@ -121,34 +142,60 @@ func (e *Engine) Evaluate(mcpServer, tool string, args map[string]interface{}) R
 			return Result{
 				Decision:    DecisionRequireApproval,
 				MatchedRule: cap.Name,
+				Output:      cap.Output,
 			}
 		}
 
 		return Result{
 			Decision:    DecisionAllow,
 			MatchedRule: cap.Name,
+			Output:      cap.Output,
 		}
 	}
 
 	// No rule matched -- default deny.
 	return Result{
 		Decision:    DecisionDeny,
-		MatchedRule: "<default>",
+		MatchedRule: DefaultRule,
 		Violation: &Violation{
-			Rule:    "<default>",
+			Rule:    DefaultRule,
 			Message: fmt.Sprintf("no capability rule matches mcp=%q tool=%q; default deny", mcpServer, tool),
 		},
 	}
 }
 
 // IsSenderAllowed returns true if the given Matrix user ID is allowed to
-// interact with this agent according to Gosuto trust settings.
+// interact with this agent according to Gosuto trust settings. When
+// trust.allowedHomeservers is set, the sender's server name must also match
+// one of its entries.
 func (e *Engine) IsSenderAllowed(senderMXID string) bool {
 	cfg := e.loader.Config()
 	if cfg == nil {
 		return false
 	}
-	return matchesAny(cfg.Trust.AllowedSenders, senderMXID)
+	if !matchesAny(cfg.Trust.AllowedSenders, senderMXID) {
+		return false
+	}
+	if len(cfg.Trust.AllowedHomeservers) == 0 {
+		return true
+	}
+	return homeserverAllowed(cfg.Trust.AllowedHomeservers, senderMXID)
+}
+
+// IsSenderIgnored reports whether senderMXID is listed in
+// trust.ignoreSenders. Ignored senders are dropped before IsSenderAllowed is
+// consulted.
+func (e *Engine) IsSenderIgnored(senderMXID string) bool {
+	cfg := e.loader.Config()
+	if cfg == nil {
+		return false
+	}
+	for _, ignored := range cfg.Trust.IgnoreSenders {
+		if ignored == senderMXID {
+			return true
+		}
+	}
+	return false
 }
 
 // IsRoomAllowed returns true if the given Matrix room ID is in the allowed list.
@@ -161,7 +208,8 @@ func (e *Engine) IsRoomAllowed(roomID string) bool {
 }
 
 // IsMessagingConfigured returns true when the active Gosuto config declares at
-// least one allowed messaging target.  When this returns false the built-in
+// least one messaging target, either in messaging.allowedTargets or as a peer
+// with a roomId.  When this returns false the built-in
 // matrix.send_message tool must be treated as unavailable: it is excluded from
 // the tool list sent to the LLM and any stray call attempts are denied by the
 // default-deny policy.
@@ -170,7 +218,7 @@ func (e *Engine) IsMessagingConfigured() bool {
 	if cfg == nil {
 		return false
 	}
-	return len(cfg.Messaging.AllowedTargets) > 0
+	return len(cfg.MessagingTargets()) > 0
 }
 
 // checkConstraints validates args against the capability's constraint map.
@@ -208,6 +256,31 @@ func matchesGlob(pattern, value string) bool {
 	return pattern == "*" || pattern == value
 }
 
+// homeserverAllowed returns true when the server name of mxid matches any of
+// the patterns. Matching is case-insensitive; "*.example.com" matches any
+// subdomain of example.com but not example.com itself.
+func homeserverAllowed(patterns []string, mxid string) bool {
+	_, server, ok := strings.Cut(mxid, ":")
+	if !ok || server == "" {
+		return false
+	}
+	server = strings.ToLower(server)
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		switch {
+		case p == "*":
+			return true
+		case strings.HasPrefix(p, "*."):
+			if strings.HasSuffix(server, p[1:]) {
+				return true
+			}
+		case p == server:
+			return true
+		}
+	}
+	return false
+}
+
 // matchesAny returns true when "*" is in the list or value appears in the list.
 func matchesAny(list []string, value string) bool {
 	for _, s := range list {
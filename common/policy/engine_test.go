@@ -3,8 +3,8 @@ package policy_test
 import (
 	"testing"
 
+	"github.com/bdobrica/Ruriko/common/policy"
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
-	"github.com/bdobrica/Ruriko/internal/gitai/policy"
 )
 
 // staticProvider is a test helper that always returns the same config.
@@ -85,6 +85,19 @@ func TestEvaluate_DefaultDeny(t *testing.T) {
 	}
 }
 
+func TestForConfig_EvaluatesStaticConfig(t *testing.T) {
+	e := policy.ForConfig(cfg([]gosutospec.Capability{
+		{Name: "allow-fetch", MCP: "browser", Tool: "fetch", Allow: true},
+	}, nil, nil))
+
+	if r := e.Evaluate("browser", "fetch", nil); r.Decision != policy.DecisionAllow {
+		t.Errorf("expected Allow, got %s", r.Decision)
+	}
+	if r := e.Evaluate("browser", "click", nil); r.MatchedRule != policy.DefaultRule {
+		t.Errorf("expected %s rule, got %q", policy.DefaultRule, r.MatchedRule)
+	}
+}
+
 func TestEvaluate_NilConfig(t *testing.T) {
 	e := policy.New(&staticProvider{cfg: nil})
 	r := e.Evaluate("any", "tool", nil)
@@ -128,6 +141,60 @@ func TestIsSenderAllowed(t *testing.T) {
 	}
 }
 
+func TestIsSenderAllowed_AllowedHomeservers(t *testing.T) {
+	c := cfg(nil, []string{"*"}, []string{"*"})
+	c.Trust.AllowedHomeservers = []string{"example.com", "*.corp.example.org"}
+	e := policy.New(&staticProvider{cfg: c})
+
+	cases := []struct {
+		sender string
+		want   bool
+	}{
+		{"@alice:example.com", true},
+		{"@alice:Example.COM", true},
+		{"@bob:matrix.example.corp.example.org", true},
+		{"@eve:corp.example.org", false}, // wildcard does not match the apex
+		{"@eve:matrix.org", false},
+		{"@eve:example.com.evil.net", false},
+		{"not-an-mxid", false},
+	}
+	for _, tc := range cases {
+		if got := e.IsSenderAllowed(tc.sender); got != tc.want {
+			t.Errorf("IsSenderAllowed(%q) = %v, want %v", tc.sender, got, tc.want)
+		}
+	}
+}
+
+func TestIsSenderAllowed_AllowedHomeserversAlongsideSenders(t *testing.T) {
+	// Explicit sender list and homeserver restriction must both pass.
+	c := cfg(nil, []string{"@alice:example.com", "@bob:matrix.org"}, []string{"*"})
+	c.Trust.AllowedHomeservers = []string{"example.com"}
+	e := policy.New(&staticProvider{cfg: c})
+
+	if !e.IsSenderAllowed("@alice:example.com") {
+		t.Error("expected alice on allowed homeserver to be allowed")
+	}
+	if e.IsSenderAllowed("@bob:matrix.org") {
+		t.Error("expected bob on disallowed homeserver to be denied")
+	}
+	if e.IsSenderAllowed("@carol:example.com") {
+		t.Error("expected carol (not in allowedSenders) to be denied")
+	}
+}
+
+func TestIsSenderIgnored(t *testing.T) {
+	c := cfg(nil, []string{"*"}, []string{"*"})
+	c.Trust.IgnoreSenders = []string{"@otherbot:example.com"}
+	e := policy.New(&staticProvider{cfg: c})
+
+	if !e.IsSenderIgnored("@otherbot:example.com") {
+		t.Error("expected otherbot to be ignored")
+	}
+	if e.IsSenderIgnored("@alice:example.com") {
+		t.Error("expected alice not to be ignored")
+	}
+}
+
 func TestIsRoomAllowed_Wildcard(t *testing.T) {
 	e := policy.New(&staticProvider{cfg: cfg(nil, nil, []string{"*"})})
 
@@ -14,22 +14,35 @@ type bucket struct {
 // KeyedFixedWindow enforces fixed-window limits per key.
 //
 // It is safe for concurrent use.
+//
+// Because each window resets wholesale, a caller can spend a full limit at
+// the end of one window and another at the start of the next, so up to
+// twice the limit may pass in any span of one window. KeyedSlidingWindow
+// avoids that boundary burst.
 type KeyedFixedWindow struct {
 	mu      sync.Mutex
 	window  time.Duration
 	buckets map[string]*bucket
+	now     func() time.Time
 }
 
 // NewKeyedFixedWindow returns a keyed fixed-window limiter.
 //
 // If window <= 0, a default one-minute window is used.
 func NewKeyedFixedWindow(window time.Duration) *KeyedFixedWindow {
+	return NewKeyedFixedWindowWithClock(window, time.Now)
+}
+
+// NewKeyedFixedWindowWithClock is like NewKeyedFixedWindow but injects a
+// custom clock. Intended for tests.
+func NewKeyedFixedWindowWithClock(window time.Duration, now func() time.Time) *KeyedFixedWindow {
 	if window <= 0 {
 		window = time.Minute
 	}
 	return &KeyedFixedWindow{
 		window:  window,
 		buckets: make(map[string]*bucket),
+		now:     now,
 	}
 }
 
@@ -46,33 +59,51 @@ func (l *KeyedFixedWindow) AllowAll(limit int, keys ...string) bool {
 	if limit <= 0 {
 		return true
 	}
-	if len(keys) == 0 {
-		return true
+	checks := make([]KeyLimit, len(keys))
+	for i, key := range keys {
+		checks[i] = KeyLimit{Key: key, Limit: limit}
 	}
+	_, ok := l.AllowLimits(checks...)
+	return ok
+}
 
+// KeyLimit pairs a key with its own limit for AllowLimits.
+type KeyLimit struct {
+	Key   string
+	Limit int
+}
+
+// AllowLimits is like AllowAll but gives each key its own limit. Checks
+// with a limit <= 0 are unlimited and consume nothing. On failure it
+// returns the first check that had no remaining capacity and no key is
+// incremented.
+func (l *KeyedFixedWindow) AllowLimits(checks ...KeyLimit) (KeyLimit, bool) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	now := time.Now()
+	now := l.now()
 
-	seen := make(map[string]struct{}, len(keys))
-	for _, key := range keys {
-		if _, ok := seen[key]; ok {
+	seen := make(map[string]struct{}, len(checks))
+	for _, c := range checks {
+		if c.Limit <= 0 {
+			continue
+		}
+		if _, ok := seen[c.Key]; ok {
 			continue
 		}
-		seen[key] = struct{}{}
+		seen[c.Key] = struct{}{}
 
-		b, ok := l.buckets[key]
+		b, ok := l.buckets[c.Key]
 		if !ok {
 			b = &bucket{}
-			l.buckets[key] = b
+			l.buckets[c.Key] = b
 		}
 		if b.resetAt.IsZero() || now.After(b.resetAt) {
 			b.count = 0
 			b.resetAt = now.Add(l.window)
 		}
-		if b.count >= limit {
-			return false
+		if b.count >= c.Limit {
+			return c, false
 		}
 	}
 
@@ -80,5 +111,5 @@ func (l *KeyedFixedWindow) AllowAll(limit int, keys ...string) bool {
 		l.buckets[key].count++
 	}
 
-	return true
+	return KeyLimit{}, true
 }
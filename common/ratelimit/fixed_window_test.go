@@ -61,3 +61,30 @@ func TestKeyedFixedWindow_AllowAllAtomic(t *testing.T) {
 		t.Fatal("source:b should still allow first request after failed atomic call")
 	}
 }
+
+func TestKeyedFixedWindow_AllowLimitsPerKey(t *testing.T) {
+	rl := NewKeyedFixedWindow(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, ok := rl.AllowLimits(KeyLimit{"global", 5}, KeyLimit{"source:a", 2}); !ok {
+			t.Fatalf("call %d should be allowed", i)
+		}
+	}
+	denied, ok := rl.AllowLimits(KeyLimit{"global", 5}, KeyLimit{"source:a", 2})
+	if ok {
+		t.Fatal("third call should be denied by source:a")
+	}
+	if denied.Key != "source:a" || denied.Limit != 2 {
+		t.Fatalf("denied = %+v, want source:a/2", denied)
+	}
+
+	// The failed call must not have consumed the global key.
+	for i := 0; i < 3; i++ {
+		if _, ok := rl.AllowLimits(KeyLimit{"global", 5}, KeyLimit{"source:b", 0}); !ok {
+			t.Fatalf("global call %d should be allowed", i)
+		}
+	}
+	if denied, ok := rl.AllowLimits(KeyLimit{"global", 5}); ok || denied.Key != "global" {
+		t.Fatalf("expected global to be exhausted, got ok=%v denied=%+v", ok, denied)
+	}
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// KeyedSlidingWindow enforces sliding-window limits per key: a call is
+// allowed only while fewer than limit calls for the key were allowed in the
+// preceding window. Unlike KeyedFixedWindow there is no reset boundary, so
+// at most limit calls pass in any span of one window.
+//
+// Each key keeps the timestamps of its recent calls, so memory grows with
+// the limit; it suits per-minute limits in the tens or hundreds. Timestamps
+// from time.Now carry a monotonic reading, so wall-clock steps do not
+// shorten or stretch the window.
+//
+// It is safe for concurrent use.
+type KeyedSlidingWindow struct {
+	mu     sync.Mutex
+	window time.Duration
+	hits   map[string][]time.Time
+	now    func() time.Time
+}
+
+// NewKeyedSlidingWindow returns a keyed sliding-window limiter.
+//
+// If window <= 0, a default one-minute window is used.
+func NewKeyedSlidingWindow(window time.Duration) *KeyedSlidingWindow {
+	return NewKeyedSlidingWindowWithClock(window, time.Now)
+}
+
+// NewKeyedSlidingWindowWithClock is like NewKeyedSlidingWindow but injects
+// a custom clock. Intended for tests.
+func NewKeyedSlidingWindowWithClock(window time.Duration, now func() time.Time) *KeyedSlidingWindow {
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &KeyedSlidingWindow{
+		window: window,
+		hits:   make(map[string][]time.Time),
+		now:    now,
+	}
+}
+
+// Allow checks and consumes one slot for key within limit.
+func (l *KeyedSlidingWindow) Allow(limit int, key string) bool {
+	_, ok := l.AllowLimits(KeyLimit{Key: key, Limit: limit})
+	return ok
+}
+
+// AllowLimits checks and consumes one slot for every key atomically, each
+// against its own limit. Checks with a limit <= 0 are unlimited and consume
+// nothing. On failure it returns the first check that had no remaining
+// capacity and no key is consumed.
+func (l *KeyedSlidingWindow) AllowLimits(checks ...KeyLimit) (KeyLimit, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cutoff := now.Add(-l.window)
+
+	seen := make(map[string]struct{}, len(checks))
+	for _, c := range checks {
+		if c.Limit <= 0 {
+			continue
+		}
+		if _, ok := seen[c.Key]; ok {
+			continue
+		}
+		seen[c.Key] = struct{}{}
+
+		hits := pruneBefore(l.hits[c.Key], cutoff)
+		l.hits[c.Key] = hits
+		if len(hits) >= c.Limit {
+			return c, false
+		}
+	}
+
+	for key := range seen {
+		l.hits[key] = append(l.hits[key], now)
+	}
+
+	return KeyLimit{}, true
+}
+
+// pruneBefore drops the leading timestamps in hits that are not after
+// cutoff. hits is in ascending order.
+func pruneBefore(hits []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	if i == 0 {
+		return hits
+	}
+	return append(hits[:0], hits[i:]...)
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for window tests.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// burstAcrossBoundary spends the limit just before a fixed window would
+// reset and again just after, returning how many calls were allowed in
+// total.
+func burstAcrossBoundary(clock *fakeClock, allow func() bool, limit int) int {
+	allowed := 0
+	// Open the window, then move to just before its end.
+	if allow() {
+		allowed++
+	}
+	clock.advance(59 * time.Second)
+	for i := 0; i < limit; i++ {
+		if allow() {
+			allowed++
+		}
+	}
+	clock.advance(2 * time.Second)
+	for i := 0; i < limit; i++ {
+		if allow() {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestKeyedFixedWindow_BurstAtBoundary(t *testing.T) {
+	const limit = 5
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	rl := NewKeyedFixedWindowWithClock(time.Minute, clock.now)
+
+	got := burstAcrossBoundary(clock, func() bool { return rl.Allow(limit, "k") }, limit)
+	// 1 opener + 4 late in window one + 5 early in window two: twice the
+	// limit within about a minute, nine of them within two seconds.
+	if got != 2*limit {
+		t.Fatalf("fixed window allowed %d calls across the boundary, want %d", got, 2*limit)
+	}
+}
+
+func TestKeyedSlidingWindow_NoBurstAtBoundary(t *testing.T) {
+	const limit = 5
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	rl := NewKeyedSlidingWindowWithClock(time.Minute, clock.now)
+
+	got := burstAcrossBoundary(clock, func() bool { return rl.Allow(limit, "k") }, limit)
+	// The opener expires at +60s, freeing exactly one slot after the
+	// fixed-window boundary; nothing else has left the window yet.
+	if got != limit+1 {
+		t.Fatalf("sliding window allowed %d calls across the boundary, want %d", got, limit+1)
+	}
+}
+
+func TestKeyedSlidingWindow_SlotsFreeAsHitsExpire(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	rl := NewKeyedSlidingWindowWithClock(time.Minute, clock.now)
+
+	if !rl.Allow(2, "k") {
+		t.Fatal("first call should be allowed")
+	}
+	clock.advance(30 * time.Second)
+	if !rl.Allow(2, "k") {
+		t.Fatal("second call should be allowed")
+	}
+	if rl.Allow(2, "k") {
+		t.Fatal("third call within the window should be denied")
+	}
+	clock.advance(31 * time.Second)
+	if !rl.Allow(2, "k") {
+		t.Fatal("call after the first hit expired should be allowed")
+	}
+	if rl.Allow(2, "k") {
+		t.Fatal("window should be full again")
+	}
+}
+
+func TestKeyedSlidingWindow_AllowLimitsAtomic(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	rl := NewKeyedSlidingWindowWithClock(time.Minute, clock.now)
+
+	if _, ok := rl.AllowLimits(KeyLimit{"global", 1}, KeyLimit{"source:a", 5}); !ok {
+		t.Fatal("first call should be allowed")
+	}
+	denied, ok := rl.AllowLimits(KeyLimit{"global", 1}, KeyLimit{"source:b", 5})
+	if ok || denied.Key != "global" {
+		t.Fatalf("second call should be denied by global, got ok=%v denied=%+v", ok, denied)
+	}
+	// source:b must not have been consumed by the failed call.
+	if !rl.Allow(1, "source:b") {
+		t.Fatal("source:b should still allow its first call")
+	}
+}
@@ -0,0 +1,66 @@
+package redact
+
+import "regexp"
+
+// namedSecretPatterns matches well-known credential formats that should never
+// appear in a Matrix message or a log line regardless of context.  Each
+// pattern is intentionally specific (vendor prefix + sufficient length) to
+// keep the false-positive rate low.
+var namedSecretPatterns = []*regexp.Regexp{
+	// OpenAI API key — classic and project variants
+	regexp.MustCompile(`\bsk-[A-Za-z0-9]{20,}\b`),
+	regexp.MustCompile(`\bsk-proj-[A-Za-z0-9_\-]{20,}\b`),
+	// Anthropic
+	regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_\-]{20,}\b`),
+	// AWS access key ID
+	regexp.MustCompile(`\bAKIA[A-Z0-9]{16}\b`),
+	// GitHub tokens (personal, OAuth, fine-grained)
+	regexp.MustCompile(`\bghp_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(`\bgho_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{20,}\b`),
+	// Slack tokens
+	regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9\-]{10,}\b`),
+	// Stripe secret / restricted / public keys
+	regexp.MustCompile(`\b(?:sk|rk|pk)_(?:live|test)_[A-Za-z0-9]{20,}\b`),
+}
+
+// genericSecretPatterns catches high-entropy strings that are unlikely to
+// appear in normal prose.  They also match content hashes and base64
+// payloads, so callers opt in to them explicitly.
+var genericSecretPatterns = []*regexp.Regexp{
+	// Long base64 segment (≥48 contiguous chars from the base64 alphabet).
+	// Using 48 instead of 40 avoids false positives from SHA-1 hashes (40 chars)
+	// while still catching SHA-256 hashes (64 chars) and longer API tokens.
+	regexp.MustCompile(`[A-Za-z0-9+/]{48,}={0,2}`),
+	// Long lowercase hex (≥48 chars).  Avoids SHA-1 (40 chars) while catching
+	// SHA-256 (64 chars) and other long hex tokens.
+	regexp.MustCompile(`[0-9a-f]{48,}`),
+}
+
+// ContainsSecret reports whether s contains a well-known credential format.
+// When generic is true, long base64 and hex runs count as secrets too.
+func ContainsSecret(s string, generic bool) bool {
+	for _, re := range namedSecretPatterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	if generic {
+		for _, re := range genericSecretPatterns {
+			if re.MatchString(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Secrets replaces every well-known credential format in s with [REDACTED].
+// Only the vendor-specific patterns are applied, so hashes and other long
+// identifiers survive.
+func Secrets(s string) string {
+	for _, re := range namedSecretPatterns {
+		s = re.ReplaceAllString(s, placeholder)
+	}
+	return s
+}
@@ -0,0 +1,30 @@
+package redact
+
+import "strings"
+
+// tokenPathPrefixes are URL path prefixes whose next segment is a one-time
+// Kuze token: the agent redemption endpoint and the human entry form.
+var tokenPathPrefixes = []string{"/kuze/redeem/", "/s/"}
+
+// URL returns u, a full URL or just a path, with the token segment of any
+// Kuze one-time path replaced by [REDACTED] and the query string and
+// fragment dropped, so the result is safe to log or put in an error.
+//
+// Example:
+//
+//	redact.URL("https://ruriko.example/kuze/redeem/abc123") // "https://ruriko.example/kuze/redeem/[REDACTED]"
+func URL(u string) string {
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	for _, prefix := range tokenPathPrefixes {
+		i := strings.Index(u, prefix)
+		if i < 0 {
+			continue
+		}
+		if end := i + len(prefix); end < len(u) {
+			return u[:end] + placeholder
+		}
+	}
+	return u
+}
@@ -24,19 +24,124 @@ type StatusResponse struct {
 	Uptime     float64   `json:"uptime_seconds"`
 	StartedAt  time.Time `json:"started_at"`
 	MCPs       []string  `json:"mcps"`
+	// MCPsInitializing lists running MCP servers still waiting for their
+	// readiness probe; their tools are not offered until it passes.
+	MCPsInitializing []string `json:"mcps_initializing,omitempty"`
+	// MCPStatus reports the health of every configured MCP server,
+	// including those that are down; nil when the runtime does not track it.
+	MCPStatus []MCPServerStatus `json:"mcp_status,omitempty"`
+	// Restarts is the number of times the agent has started against its
+	// data directory since FirstStartedAt, not counting the first start.
+	// A count that keeps growing points at a crash loop.
+	Restarts int `json:"restarts,omitempty"`
+	// FirstStartedAt is when the agent first started against its data
+	// directory; nil when the runtime does not track starts.
+	FirstStartedAt *time.Time `json:"first_started_at,omitempty"`
 	// Gateways lists supervised gateway names (optional).
 	Gateways []string `json:"gateways,omitempty"`
 	// MessagesOutbound is the number of successful matrix.send_message calls
 	// since process start.
 	MessagesOutbound int64 `json:"messages_outbound,omitempty"`
+	// EventQueueDepth is the number of gateway events waiting for a turn.
+	EventQueueDepth int `json:"event_queue_depth,omitempty"`
+	// EventQueueCapacity is the size of the bounded event queue.
+	EventQueueCapacity int `json:"event_queue_capacity,omitempty"`
+	// StartupFailures lists the MCP servers, cron jobs and gateways that
+	// failed to start when the agent booted, as "<kind>:<name>: <error>";
+	// empty when everything started. The next config apply clears it and
+	// reports its own failures in LastConfigApply.
+	StartupFailures []string `json:"startup_failures,omitempty"`
+	// LastConfigApply describes the most recent POST /config/apply; nil
+	// until the first push since process start.
+	LastConfigApply *ConfigApplyStatus `json:"last_config_apply,omitempty"`
+	// Canary describes the config under test in a single room; nil when
+	// no canary is active.
+	Canary *CanaryStatus `json:"canary,omitempty"`
+	// Spend is the estimated LLM spend for the current month; nil when the
+	// runtime does not track it.
+	Spend *MonthlySpend `json:"spend,omitempty"`
 }
 
-// ConfigApplyRequest is the body for POST /config/apply.
+// MCPServerStatus is the health of one configured MCP server.
+type MCPServerStatus struct {
+	Name string `json:"name"`
+	// State is "running", "initializing" (readiness probe pending),
+	// "restarting" (down, auto-restart pending) or "crashed" (down and not
+	// restarted automatically).
+	State string `json:"state"`
+	// Restarts counts automatic restarts since the server was configured.
+	Restarts int `json:"restarts,omitempty"`
+	// LastExit describes the most recent unexpected exit or failed start,
+	// e.g. "exit status 1".
+	LastExit   string     `json:"last_exit,omitempty"`
+	LastExitAt *time.Time `json:"last_exit_at,omitempty"`
+}
+
+// MonthlySpend reports the estimated LLM cost of a calendar month (UTC)
+// against the Gosuto limits.maxMonthlyCostUSD budget.
+type MonthlySpend struct {
+	// Month is the calendar month, "YYYY-MM".
+	Month   string  `json:"month"`
+	CostUSD float64 `json:"cost_usd"`
+	// BudgetUSD is the configured monthly budget; 0 means unlimited.
+	BudgetUSD float64 `json:"budget_usd,omitempty"`
+}
+
+// CanaryStatus reports the active canary config.
+type CanaryStatus struct {
+	Room      string    `json:"room"`
+	Hash      string    `json:"hash"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Values of ConfigApplyStatus.Result.
+const (
+	ConfigApplyOK      = "ok"
+	ConfigApplyWarning = "warning"
+	ConfigApplyError   = "error"
+)
+
+// ConfigApplyStatus reports the outcome of a config apply. An apply that was
+// accepted but left something degraded (a config warning, an MCP server that
+// failed to start) has Result "warning" and the details in Message.
+//
+// Started, Stopped, Restarted and Failed list what the apply's reconcile
+// changed, as "<kind>:<name>" with kind one of "mcp", "cron" or "gateway".
+// Failed entries carry the error after the name.
+type ConfigApplyStatus struct {
+	Hash      string    `json:"hash"`
+	AppliedAt time.Time `json:"applied_at"`
+	Result    string    `json:"result"`
+	Message   string    `json:"message,omitempty"`
+	Started   []string  `json:"started,omitempty"`
+	Stopped   []string  `json:"stopped,omitempty"`
+	Restarted []string  `json:"restarted,omitempty"`
+	Failed    []string  `json:"failed,omitempty"`
+}
+
+// ConfigApplyRequest is the body for POST /config/apply. Hash must be the
+// hex SHA-256 of YAML (as sent, or of its canonical Gosuto form); the agent
+// rejects a mismatch with 422 Unprocessable Entity.
 type ConfigApplyRequest struct {
 	YAML string `json:"yaml"`
 	Hash string `json:"hash"`
 }
 
+// ConfigCanaryRequest is the body for POST /config/canary. The agent uses
+// the config only for turns in Room and keeps its live config everywhere
+// else.
+type ConfigCanaryRequest struct {
+	YAML string `json:"yaml"`
+	Hash string `json:"hash"`
+	Room string `json:"room"`
+}
+
+// ConfigResponse is returned by GET /config.
+type ConfigResponse struct {
+	YAML string `json:"yaml"`
+	Hash string `json:"hash"`
+}
+
 // SecretsApplyRequest is the body for POST /secrets/apply.
 type SecretsApplyRequest struct {
 	Secrets map[string]string `json:"secrets"`
@@ -47,6 +152,9 @@ type SecretLease struct {
 	SecretRef       string `json:"secret_ref"`
 	RedemptionToken string `json:"redemption_token"`
 	KuzeURL         string `json:"kuze_url"`
+	// TTLSeconds overrides the agent's cache TTL for this secret.
+	// 0 uses the agent's default.
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
 }
 
 // SecretsTokenRequest is the body for POST /secrets/token.
@@ -54,6 +162,21 @@ type SecretsTokenRequest struct {
 	Leases []SecretLease `json:"leases"`
 }
 
+// SecretsTokenResponse is the body returned by POST /secrets/token when at
+// least one lease was redeemed and applied. It is sent with 200 OK when every
+// lease succeeded and 207 Multi-Status when some failed, so the caller can
+// re-issue only the failed refs.
+type SecretsTokenResponse struct {
+	Applied []string             `json:"applied"`
+	Failed  []SecretLeaseFailure `json:"failed,omitempty"`
+}
+
+// SecretLeaseFailure names a lease that could not be redeemed and why.
+type SecretLeaseFailure struct {
+	SecretRef string `json:"secret_ref"`
+	Error     string `json:"error"`
+}
+
 // ApprovalDecisionRequest is the body for POST /approvals/decision.
 type ApprovalDecisionRequest struct {
 	ApprovalID string `json:"approval_id"`
@@ -74,7 +197,250 @@ type ToolCallResponse struct {
 	Result string `json:"result"`
 }
 
-// ErrorResponse is returned by ACP endpoints on errors.
+// RegisterRequest is the body an agent POSTs to Ruriko's /agents/register
+// endpoint on startup (self-registration). It is authenticated with the
+// shared bootstrap token, not the agent's ACP token.
+type RegisterRequest struct {
+	AgentID    string `json:"agent_id"`
+	ControlURL string `json:"control_url"`
+	// ACPToken is the bearer token Ruriko must present when calling the
+	// agent's ACP endpoints.
+	ACPToken   string `json:"acp_token,omitempty"`
+	Version    string `json:"version,omitempty"`
+	GosutoHash string `json:"gosuto_hash,omitempty"`
+}
+
+// SecretRefreshRequest is the body an agent POSTs to Ruriko's
+// /agents/secrets/refresh endpoint when a secret it needs is missing or its
+// lease has expired. It is authenticated with the agent's own ACP token.
+// Ruriko answers by issuing fresh Kuze tokens over POST /secrets/token
+// before it responds.
+type SecretRefreshRequest struct {
+	AgentID string `json:"agent_id"`
+	// Secrets names the refs the agent failed to resolve. Informational:
+	// Ruriko re-pushes every secret bound to the agent.
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// SecretRefreshResponse reports how many secrets Ruriko pushed.
+type SecretRefreshResponse struct {
+	Pushed int `json:"pushed"`
+}
+
+// TunnelFrame is one message on the optional agent-initiated WebSocket
+// control channel (GET /agents/tunnel on Ruriko). Ruriko sends request
+// frames (Method, Path, Header, Body) and the agent answers each with a
+// response frame carrying the same ID (Status, Header, Body). Bodies are the
+// regular ACP request/response payloads, so every ACP endpoint works
+// unchanged over the tunnel.
+type TunnelFrame struct {
+	ID     string            `json:"id"`
+	Method string            `json:"method,omitempty"`
+	Path   string            `json:"path,omitempty"`
+	Status int               `json:"status,omitempty"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   []byte            `json:"body,omitempty"`
+}
+
+// DeadLetter is a gateway event whose turn failed, held in the agent's
+// dead-letter queue for inspection and manual retry.
+type DeadLetter struct {
+	ID        int64     `json:"id"`
+	Source    string    `json:"source"`
+	EventType string    `json:"event_type"`
+	Error     string    `json:"error"`
+	Retries   int       `json:"retries"`
+	CreatedAt time.Time `json:"created_at"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// DeadLetterListResponse is returned by GET /dlq.
+type DeadLetterListResponse struct {
+	// Enabled reports whether the agent records failed events at all.
+	Enabled bool `json:"enabled"`
+	// MaxRetries is the number of manual retries allowed per entry.
+	MaxRetries int          `json:"max_retries"`
+	Entries    []DeadLetter `json:"entries"`
+}
+
+// DeadLetterRetryRequest is the body for POST /dlq/retry.
+type DeadLetterRetryRequest struct {
+	ID int64 `json:"id"`
+}
+
+// ToolInfo describes one tool the agent currently offers to its LLM.
+type ToolInfo struct {
+	// Name is the name the LLM calls the tool by: "<mcp>__<tool>" for MCP
+	// tools, the canonical name (e.g. "matrix.send_message") for built-ins.
+	Name string `json:"name"`
+	// MCP is the MCP server the tool comes from, or "builtin".
+	MCP string `json:"mcp"`
+	// Tool is the tool's name as capability rules match it.
+	Tool        string `json:"tool"`
+	Description string `json:"description,omitempty"`
+	// InputSchema is the tool's JSON Schema; only set when requested with
+	// GET /tools?schemas=true.
+	InputSchema interface{} `json:"input_schema,omitempty"`
+}
+
+// ToolListResponse is returned by GET /tools.
+type ToolListResponse struct {
+	Tools []ToolInfo `json:"tools"`
+}
+
+// ToolCallRecord is one entry in the agent's tool-call audit trail. Only the
+// names of the call's arguments are recorded, never their values.
+type ToolCallRecord struct {
+	TraceID string `json:"trace_id,omitempty"`
+	// Caller is the execution path that made the call: llm, workflow or control.
+	Caller string `json:"caller"`
+	// MCP is the MCP server or "builtin"; "-" when the call was rejected
+	// before its tool could be resolved.
+	MCP  string `json:"mcp"`
+	Tool string `json:"tool"`
+	// Decision is the policy outcome (allow, deny, requires_approval), or
+	// "rejected" for calls that failed before policy evaluation.
+	Decision   string    `json:"decision"`
+	Rule       string    `json:"rule,omitempty"`
+	ArgKeys    []string  `json:"arg_keys"`
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// ToolCallListResponse is returned by GET /calls, newest call first.
+type ToolCallListResponse struct {
+	Calls []ToolCallRecord `json:"calls"`
+}
+
+// TurnSummary is one entry in an agent's turn history. The message text and
+// the reply are not included.
+type TurnSummary struct {
+	TraceID string `json:"trace_id"`
+	// Trigger is "matrix" for chat messages or "gateway" for events.
+	Trigger string `json:"trigger"`
+	Sender  string `json:"sender,omitempty"`
+	// Gateway and EventType identify the event behind a gateway turn.
+	Gateway   string `json:"gateway,omitempty"`
+	EventType string `json:"event_type,omitempty"`
+	// Status is the turn result (success, error), or "running" while the
+	// turn is in progress.
+	Status     string    `json:"status"`
+	ToolCalls  int       `json:"tool_calls"`
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// TurnListResponse is returned by GET /turns, newest turn first.
+type TurnListResponse struct {
+	Turns []TurnSummary `json:"turns"`
+}
+
+// TranscriptToolCall is a tool invocation the model requested in a turn
+// transcript. Arguments is the raw JSON the model produced, secret-redacted.
+type TranscriptToolCall struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// TranscriptMessage is one message of the history a turn sent the LLM.
+type TranscriptMessage struct {
+	// Role is system, user, assistant or tool.
+	Role       string               `json:"role"`
+	Content    string               `json:"content,omitempty"`
+	ToolCalls  []TranscriptToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string               `json:"tool_call_id,omitempty"`
+	// Name is the tool whose result a tool message carries.
+	Name string `json:"name,omitempty"`
+}
+
+// TurnTranscriptResponse is returned by GET /turns/{trace}/transcript. Known
+// secret values and credential formats are redacted from every message.
+type TurnTranscriptResponse struct {
+	TraceID  string              `json:"trace_id"`
+	Messages []TranscriptMessage `json:"messages"`
+	At       time.Time           `json:"at"`
+}
+
+// TokenRotateRequest is the body for POST /token/rotate.
+type TokenRotateRequest struct {
+	// Token is the new ACP bearer token.
+	Token string `json:"token"`
+	// OverlapSeconds is how long the old token keeps authenticating;
+	// 0 means the agent's default (5 minutes).
+	OverlapSeconds int `json:"overlap_seconds,omitempty"`
+}
+
+// TokenRotateResponse is returned by POST /token/rotate.
+type TokenRotateResponse struct {
+	// RetiresAt is when the old token stops being accepted.
+	RetiresAt time.Time `json:"retires_at"`
+}
+
+// BroadcastRequest is the body for POST /broadcast.
+type BroadcastRequest struct {
+	// Message is the notice text posted to every room the agent is in.
+	Message string `json:"message"`
+}
+
+// BroadcastResponse is returned by POST /broadcast.
+type BroadcastResponse struct {
+	// Rooms is the number of rooms the notice was posted to.
+	Rooms int `json:"rooms"`
+	// Failed lists the rooms the notice could not be posted to.
+	Failed []string `json:"failed,omitempty"`
+}
+
+// EventBatchResult is the outcome of one envelope in a batch posted to
+// POST /events/{source}/batch.
+type EventBatchResult struct {
+	// Index is the envelope's position in the posted array.
+	Index int `json:"index"`
+	// Status is "queued" or "rejected".
+	Status string `json:"status"`
+	// Code is the HTTP status the envelope would have received from
+	// POST /events/{source} (202, 400, 429, 503, ...).
+	Code int `json:"code"`
+	// Error explains a rejection.
+	Error string `json:"error,omitempty"`
+}
+
+// EventBatchResponse is returned by POST /events/{source}/batch.
+type EventBatchResponse struct {
+	Accepted int                `json:"accepted"`
+	Rejected int                `json:"rejected"`
+	Results  []EventBatchResult `json:"results"`
+}
+
+// ErrorResponse is returned by ACP endpoints on errors, always with
+// Content-Type application/json. Code is stable and meant for clients to
+// branch on; Error is a human-readable message that may change.
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code"`
 }
+
+// Values of ErrorResponse.Code. Most follow the HTTP status; the rest
+// narrow down a status that several failures share.
+const (
+	ErrorCodeBadRequest           = "bad_request"
+	ErrorCodeUnauthorized         = "unauthorized"
+	ErrorCodeNotFound             = "not_found"
+	ErrorCodeMethodNotAllowed     = "method_not_allowed"
+	ErrorCodeConflict             = "conflict"
+	ErrorCodeGone                 = "gone"
+	ErrorCodePayloadTooLarge      = "payload_too_large"
+	ErrorCodeUnsupportedMediaType = "unsupported_media_type"
+	ErrorCodeUnprocessable        = "unprocessable"
+	ErrorCodeRateLimited          = "rate_limited"
+	ErrorCodeInternal             = "internal"
+	ErrorCodeBadGateway           = "bad_gateway"
+	ErrorCodeUnavailable          = "unavailable"
+
+	// ErrorCodeHashMismatch: a pushed config's hash does not match its YAML.
+	ErrorCodeHashMismatch = "hash_mismatch"
+	// ErrorCodeTimeout: the route's handler did not finish in time (503).
+	ErrorCodeTimeout = "timeout"
+)
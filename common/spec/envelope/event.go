@@ -4,11 +4,25 @@
 package envelope
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"time"
 )
 
+// Attachment limits. Inline data travels inside the event body, which the
+// agent caps at 1 MiB, so larger files must be passed by reference (URL).
+const (
+	// MaxAttachments is the most attachments a single event may carry.
+	MaxAttachments = 16
+	// MaxInlineAttachmentBytes caps the decoded size of one inline attachment.
+	MaxInlineAttachmentBytes = 256 * 1024
+	// MaxInlineAttachmentTotalBytes caps the decoded size of all inline
+	// attachments in one event.
+	MaxInlineAttachmentTotalBytes = 512 * 1024
+)
+
 // Event is the normalised envelope that gateways POST to an agent's ACP
 // endpoint. It carries a machine-readable source/type classification plus a
 // payload that is forwarded to the agent's LLM turn engine.
@@ -39,6 +53,45 @@ type EventPayload struct {
 	// to the LLM directly but may be referenced in prompt templates or logged
 	// for debugging purposes.
 	Data map[string]interface{} `json:"data,omitempty"`
+
+	// Attachments lists files that arrived with the event (e.g. email
+	// attachments). The agent lists their metadata in the prompt; the LLM
+	// reads their content on demand with the event.attachment tool.
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment describes one file carried by an event. Exactly one of URL or
+// Data must be set: small files may be inlined as base64, larger ones are
+// passed by reference.
+type Attachment struct {
+	// Name is the file name as reported by the source (e.g. "report.pdf").
+	Name string `json:"name"`
+
+	// MIME is the media type, e.g. "application/pdf". Optional.
+	MIME string `json:"mime,omitempty"`
+
+	// Size is the file size in bytes. For inline attachments it must match
+	// the decoded length of Data when set.
+	Size int64 `json:"size,omitempty"`
+
+	// URL is an http(s) reference the agent can fetch the content from.
+	URL string `json:"url,omitempty"`
+
+	// Data is the base64-encoded (standard encoding) content of a small file.
+	Data string `json:"data,omitempty"`
+}
+
+// Decode returns the inline content of the attachment. It fails when the
+// attachment is passed by URL or its data is not valid base64.
+func (a *Attachment) Decode() ([]byte, error) {
+	if a.Data == "" {
+		return nil, fmt.Errorf("attachment %q has no inline data", a.Name)
+	}
+	b, err := base64.StdEncoding.DecodeString(a.Data)
+	if err != nil {
+		return nil, fmt.Errorf("attachment %q: invalid base64 data: %w", a.Name, err)
+	}
+	return b, nil
 }
 
 // Validate checks that an Event is structurally valid.
@@ -57,6 +110,50 @@ func (e *Event) Validate() error {
 	if e.TS.IsZero() {
 		return fmt.Errorf("ts must not be zero")
 	}
+	return validateAttachments(e.Payload.Attachments)
+}
+
+// validateAttachments checks the attachment list against the structural
+// rules and size caps documented on Attachment and MaxAttachments.
+func validateAttachments(atts []Attachment) error {
+	if len(atts) > MaxAttachments {
+		return fmt.Errorf("payload.attachments: %d attachments exceeds the maximum of %d", len(atts), MaxAttachments)
+	}
+	var inline int
+	for i := range atts {
+		a := &atts[i]
+		if a.Name == "" {
+			return fmt.Errorf("payload.attachments[%d]: name must not be empty", i)
+		}
+		if a.Size < 0 {
+			return fmt.Errorf("payload.attachments[%d]: size must not be negative", i)
+		}
+		if (a.URL == "") == (a.Data == "") {
+			return fmt.Errorf("payload.attachments[%d]: exactly one of url or data must be set", i)
+		}
+		if a.URL != "" {
+			u, err := url.Parse(a.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("payload.attachments[%d]: url must be an absolute http(s) URL", i)
+			}
+			continue
+		}
+		b, err := a.Decode()
+		if err != nil {
+			return fmt.Errorf("payload.attachments[%d]: %w", i, err)
+		}
+		if len(b) > MaxInlineAttachmentBytes {
+			return fmt.Errorf("payload.attachments[%d]: inline data is %d bytes, exceeds the %d-byte cap; pass a url instead",
+				i, len(b), MaxInlineAttachmentBytes)
+		}
+		if a.Size != 0 && a.Size != int64(len(b)) {
+			return fmt.Errorf("payload.attachments[%d]: size %d does not match the %d decoded bytes", i, a.Size, len(b))
+		}
+		inline += len(b)
+	}
+	if inline > MaxInlineAttachmentTotalBytes {
+		return fmt.Errorf("payload.attachments: inline data totals %d bytes, exceeds the %d-byte cap", inline, MaxInlineAttachmentTotalBytes)
+	}
 	return nil
 }
 
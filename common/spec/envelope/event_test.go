@@ -1,7 +1,9 @@
 package envelope_test
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -178,3 +180,92 @@ func TestParseEvent_MissingTS(t *testing.T) {
 		t.Error("ParseEvent: expected error for missing/zero ts, got nil")
 	}
 }
+
+// ── attachments ───────────────────────────────────────────────────────────────
+
+func TestEvent_Validate_Attachments_Valid(t *testing.T) {
+	e := validEvent()
+	e.Payload.Attachments = []envelope.Attachment{
+		{Name: "note.txt", MIME: "text/plain", Size: 5, Data: base64.StdEncoding.EncodeToString([]byte("hello"))},
+		{Name: "report.pdf", MIME: "application/pdf", Size: 2 << 20, URL: "https://files.example.com/report.pdf"},
+	}
+	if err := e.Validate(); err != nil {
+		t.Fatalf("Validate: unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	got, err := envelope.ParseEvent(data)
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if len(got.Payload.Attachments) != 2 {
+		t.Fatalf("Attachments: got %d, want 2", len(got.Payload.Attachments))
+	}
+	content, err := got.Payload.Attachments[0].Decode()
+	if err != nil || string(content) != "hello" {
+		t.Errorf("Decode: got %q, %v; want \"hello\"", content, err)
+	}
+}
+
+func TestEvent_Validate_Attachments_Invalid(t *testing.T) {
+	inline := base64.StdEncoding.EncodeToString([]byte("hello"))
+	cases := []struct {
+		name string
+		att  envelope.Attachment
+		want string
+	}{
+		{"missing name", envelope.Attachment{Data: inline}, "name must not be empty"},
+		{"neither url nor data", envelope.Attachment{Name: "a"}, "exactly one of url or data"},
+		{"both url and data", envelope.Attachment{Name: "a", URL: "https://x.example.com/a", Data: inline}, "exactly one of url or data"},
+		{"relative url", envelope.Attachment{Name: "a", URL: "/files/a"}, "absolute http(s) URL"},
+		{"file url", envelope.Attachment{Name: "a", URL: "file:///etc/passwd"}, "absolute http(s) URL"},
+		{"bad base64", envelope.Attachment{Name: "a", Data: "not base64!"}, "invalid base64"},
+		{"size mismatch", envelope.Attachment{Name: "a", Size: 99, Data: inline}, "does not match"},
+		{"negative size", envelope.Attachment{Name: "a", Size: -1, Data: inline}, "must not be negative"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := validEvent()
+			e.Payload.Attachments = []envelope.Attachment{tc.att}
+			err := e.Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Validate: got %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvent_Validate_Attachments_InlineSizeCap(t *testing.T) {
+	big := make([]byte, envelope.MaxInlineAttachmentBytes+1)
+	e := validEvent()
+	e.Payload.Attachments = []envelope.Attachment{
+		{Name: "big.bin", Data: base64.StdEncoding.EncodeToString(big)},
+	}
+	if err := e.Validate(); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Validate: got %v, want inline size-cap error", err)
+	}
+}
+
+func TestEvent_Validate_Attachments_TotalInlineCap(t *testing.T) {
+	chunk := base64.StdEncoding.EncodeToString(make([]byte, envelope.MaxInlineAttachmentBytes))
+	e := validEvent()
+	for i := 0; i < 3; i++ {
+		e.Payload.Attachments = append(e.Payload.Attachments, envelope.Attachment{Name: "part", Data: chunk})
+	}
+	if err := e.Validate(); err == nil || !strings.Contains(err.Error(), "totals") {
+		t.Errorf("Validate: got %v, want total inline size-cap error", err)
+	}
+}
+
+func TestEvent_Validate_Attachments_TooMany(t *testing.T) {
+	e := validEvent()
+	for i := 0; i <= envelope.MaxAttachments; i++ {
+		e.Payload.Attachments = append(e.Payload.Attachments, envelope.Attachment{Name: "a", URL: "https://x.example.com/a"})
+	}
+	if err := e.Validate(); err == nil || !strings.Contains(err.Error(), "maximum") {
+		t.Errorf("Validate: got %v, want too-many-attachments error", err)
+	}
+}
@@ -0,0 +1,28 @@
+package gosuto
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Canonicalize decodes a Gosuto YAML document and re-emits it in canonical
+// form: fields in Config struct order, mapping keys sorted, scalars in a
+// single style and comments dropped. Documents that differ only cosmetically
+// (key order, quoting, indentation) canonicalize to identical bytes, so a
+// hash of the result identifies a config by content rather than formatting.
+//
+// Canonicalize does not validate; call Parse first. The output matches what
+// re-serialising a decoded Config with yaml.Marshal produces, so configs
+// patched section by section hash the same way.
+func Canonicalize(data []byte) ([]byte, error) {
+	var cfg Config
+	if err := DecodeStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("gosuto canonicalize: %w", err)
+	}
+	out, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("gosuto canonicalize: %w", err)
+	}
+	return out, nil
+}
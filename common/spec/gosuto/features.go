@@ -0,0 +1,126 @@
+package gosuto
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// FeatureKind is the value type of a feature flag.
+type FeatureKind string
+
+const (
+	// FeatureBool flags accept any value strconv.ParseBool understands.
+	FeatureBool FeatureKind = "bool"
+	// FeatureString flags accept a free-form or enumerated string.
+	FeatureString FeatureKind = "string"
+)
+
+// FeatureSpec describes a feature flag understood by Gitai.
+type FeatureSpec struct {
+	Kind FeatureKind
+	// Default is the value used when the flag is not set in the config.
+	Default string
+	// Allowed, when non-empty, enumerates the accepted string values.
+	Allowed []string
+	// Description explains what the flag toggles.
+	Description string
+}
+
+// KnownFeatures lists the feature flags Gitai understands. Flags are applied
+// live when a new config is pushed; no restart is required. Unknown flag
+// names are accepted but reported by Warnings.
+var KnownFeatures = map[string]FeatureSpec{
+	"toolSchemaValidation": {
+		Kind:        FeatureBool,
+		Default:     "true",
+		Description: "Validate MCP tool arguments against the tool's input schema before dispatch.",
+	},
+	"resourceTools": {
+		Kind:        FeatureBool,
+		Default:     "true",
+		Description: "Offer the resource.list and resource.read built-ins to the LLM.",
+	},
+	"configApplyNotices": {
+		Kind:        FeatureBool,
+		Default:     "false",
+		Description: "Post a notice to the admin room whenever a pushed config is applied.",
+	},
+	"logLevel": {
+		Kind:        FeatureString,
+		Allowed:     []string{"debug", "info", "warn", "error"},
+		Description: "Override the process log level (LOG_LEVEL) for this agent.",
+	},
+}
+
+// FeatureBool returns the boolean value of the named flag, falling back to
+// the flag's default when it is unset. Unknown flags default to false.
+func (c *Config) FeatureBool(name string) bool {
+	b, _ := strconv.ParseBool(c.featureValue(name))
+	return b
+}
+
+// FeatureString returns the string value of the named flag, falling back to
+// the flag's default when it is unset.
+func (c *Config) FeatureString(name string) string {
+	return c.featureValue(name)
+}
+
+func (c *Config) featureValue(name string) string {
+	if c != nil {
+		if v, ok := c.Features[name]; ok {
+			return v
+		}
+	}
+	return KnownFeatures[name].Default
+}
+
+// UnknownFeatures returns the sorted names of configured flags that are not
+// in KnownFeatures.
+func (c *Config) UnknownFeatures() []string {
+	var out []string
+	for name := range c.Features {
+		if _, ok := KnownFeatures[name]; !ok {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// validateFeatures checks the values of known flags. Unknown flags are left
+// to Warnings so that a config written for a newer Gitai still applies.
+func validateFeatures(features map[string]string) error {
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec, ok := KnownFeatures[name]
+		if !ok {
+			continue
+		}
+		v := features[name]
+		switch spec.Kind {
+		case FeatureBool:
+			if _, err := strconv.ParseBool(v); err != nil {
+				return fmt.Errorf("%s: %q is not a boolean", name, v)
+			}
+		case FeatureString:
+			if len(spec.Allowed) > 0 && !containsString(spec.Allowed, v) {
+				return fmt.Errorf("%s: %q is not one of %v", name, v, spec.Allowed)
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package gosuto
+
+import (
+	"fmt"
+	"net/textproto"
+	"strings"
+)
+
+// WebhookTypeFrom says where a webhook gateway reads the event type of a
+// delivery from. It is parsed from the gateway's config.typeFrom, which is
+// either a JSON path of object keys ("$.action", "$.check_run.status") or a
+// request header ("header:X-GitHub-Event"). Exactly one of Path and Header
+// is set.
+type WebhookTypeFrom struct {
+	// Path is the sequence of object keys to follow from the payload root.
+	Path []string
+	// Header is the canonical name of the request header to read.
+	Header string
+}
+
+// ParseWebhookTypeFrom parses a webhook gateway's config.typeFrom value.
+func ParseWebhookTypeFrom(s string) (WebhookTypeFrom, error) {
+	s = strings.TrimSpace(s)
+	if name, ok := strings.CutPrefix(s, "header:"); ok {
+		name = strings.TrimSpace(name)
+		if name == "" || !isTypeFromToken(name) {
+			return WebhookTypeFrom{}, fmt.Errorf("typeFrom %q: header name is empty or invalid", s)
+		}
+		return WebhookTypeFrom{Header: textproto.CanonicalMIMEHeaderKey(name)}, nil
+	}
+	rest, ok := strings.CutPrefix(s, "$.")
+	if !ok {
+		return WebhookTypeFrom{}, fmt.Errorf("typeFrom %q must be a JSON path like \"$.action\" or \"header:<name>\"", s)
+	}
+	path := strings.Split(rest, ".")
+	for _, key := range path {
+		if key == "" || !isTypeFromToken(key) {
+			return WebhookTypeFrom{}, fmt.Errorf("typeFrom %q: path segment %q is empty or invalid", s, key)
+		}
+	}
+	return WebhookTypeFrom{Path: path}, nil
+}
+
+// isTypeFromToken reports whether s contains only letters, digits, '_' and '-'.
+func isTypeFromToken(s string) bool {
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -6,6 +6,9 @@ package gosuto
 
 import (
 	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -36,6 +39,11 @@ type Config struct {
 	// MCPs defines the MCP server processes to wire to this agent.
 	MCPs []MCPServer `yaml:"mcps,omitempty" json:"mcps,omitempty"`
 
+	// StartupProbe, when enabled, holds Matrix turns and gateway events at
+	// agent start until the MCP servers above are ready and the required
+	// secrets below have arrived.
+	StartupProbe StartupProbe `yaml:"startupProbe,omitempty" json:"startupProbe,omitempty"`
+
 	// Gateways defines the inbound event gateway processes for this agent.
 	Gateways []Gateway `yaml:"gateways,omitempty" json:"gateways,omitempty"`
 
@@ -55,10 +63,19 @@ type Config struct {
 	// section is absent the agent cannot send messages to anyone.
 	Messaging Messaging `yaml:"messaging,omitempty" json:"messaging,omitempty"`
 
+	// Notifications configures operator alerts the agent raises with the
+	// built-in notify.operator tool, independently of messaging targets.
+	// When the section is absent the tool is not offered.
+	Notifications Notifications `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+
 	// Workflow defines deterministic protocol-triggered workflows used by the
 	// runtime workflow engine. This is independent from instructions.workflow,
 	// which is prompt-only guidance for the LLM.
 	Workflow Workflow `yaml:"workflow,omitempty" json:"workflow,omitempty"`
+
+	// Features holds named agent feature flags (booleans or strings), applied
+	// live on every config push. See KnownFeatures for the recognised names.
+	Features map[string]string `yaml:"features,omitempty" json:"features,omitempty"`
 }
 
 // Metadata holds descriptive information about a Gosuto config.
@@ -77,6 +94,21 @@ type Metadata struct {
 
 	// Description is a human-readable description of the agent's purpose.
 	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// Image is the container image Ruriko uses when creating an agent from
+	// this template without an explicit --image. Gitai ignores it.
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+
+	// Enabled is a config-level kill switch. When explicitly false the agent
+	// still applies the config (and keeps serving ACP /health and /status)
+	// but refuses all Matrix turns and gateway events. Nil means enabled.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+}
+
+// IsEnabled reports whether the agent should process turns. A missing
+// enabled field defaults to true.
+func (m Metadata) IsEnabled() bool {
+	return m.Enabled == nil || *m.Enabled
 }
 
 // Trust defines who the agent communicates with and under what conditions.
@@ -89,6 +121,31 @@ type Trust struct {
 	// Use "*" to allow all senders, or list specific MXIDs starting with "@".
 	AllowedSenders []string `yaml:"allowedSenders" json:"allowedSenders"`
 
+	// AllowedHomeservers optionally restricts senders to specific Matrix
+	// server names (the part of the MXID after the first ':'). Entries may be
+	// an exact server name ("example.com"), a subdomain wildcard
+	// ("*.example.com", which does not match the apex domain), or "*".
+	// When empty, no homeserver restriction is applied. The check is
+	// enforced in addition to AllowedSenders, not instead of it.
+	AllowedHomeservers []string `yaml:"allowedHomeservers,omitempty" json:"allowedHomeservers,omitempty"`
+
+	// IgnoreSenders lists exact Matrix user IDs (typically other bots) whose
+	// messages the agent drops before any other check, even when they match
+	// AllowedSenders. The agent always ignores its own messages as well.
+	IgnoreSenders []string `yaml:"ignoreSenders,omitempty" json:"ignoreSenders,omitempty"`
+
+	// RequireMention controls free-form messages, i.e. those that are neither
+	// a "Hey <agent>, ..." directive nor a workflow protocol message. Unset,
+	// they are ignored (the default). True accepts them only when they
+	// mention the agent: its MXID in m.mentions, a matrix.to pill in the
+	// formatted body, the MXID in the plain body, or one of MentionKeywords.
+	// False accepts every free-form message from an allowed sender.
+	RequireMention *bool `yaml:"requireMention,omitempty" json:"requireMention,omitempty"`
+
+	// MentionKeywords are extra words that count as a mention when
+	// RequireMention is true. Matching is case-insensitive on whole words.
+	MentionKeywords []string `yaml:"mentionKeywords,omitempty" json:"mentionKeywords,omitempty"`
+
 	// RequireE2EE specifies whether the agent will only operate in
 	// end-to-end encrypted rooms.
 	RequireE2EE bool `yaml:"requireE2EE,omitempty" json:"requireE2EE,omitempty"`
@@ -261,8 +318,9 @@ type WorkflowProtocolStep struct {
 
 // Limits defines resource constraints on agent operations.
 type Limits struct {
-	// MaxRequestsPerMinute is the maximum number of LLM calls per minute.
-	// 0 means unlimited.
+	// MaxRequestsPerMinute is the maximum number of Matrix messages a single
+	// sender may have the agent act on per minute (fixed window). Further
+	// messages get a rate-limit reply. 0 means unlimited.
 	MaxRequestsPerMinute int `yaml:"maxRequestsPerMinute,omitempty" json:"maxRequestsPerMinute,omitempty"`
 
 	// MaxTokensPerRequest is the maximum number of tokens per LLM call.
@@ -277,8 +335,20 @@ type Limits struct {
 	MaxMonthlyCostUSD float64 `yaml:"maxMonthlyCostUSD,omitempty" json:"maxMonthlyCostUSD,omitempty"`
 
 	// MaxEventsPerMinute is the maximum number of inbound gateway events
-	// processed per minute across all gateways. 0 means unlimited.
+	// processed per minute across all gateways. 0 falls back to the
+	// deployment default (GITAI_DEFAULT_MAX_EVENTS_PER_MINUTE) unless
+	// UnlimitedEvents is set.
 	MaxEventsPerMinute int `yaml:"maxEventsPerMinute,omitempty" json:"maxEventsPerMinute,omitempty"`
+
+	// UnlimitedEvents opts out of the deployment default event rate limit,
+	// so an unset MaxEventsPerMinute means no limit at all. It cannot be
+	// combined with a positive MaxEventsPerMinute.
+	UnlimitedEvents bool `yaml:"unlimitedEvents,omitempty" json:"unlimitedEvents,omitempty"`
+
+	// MaxToolCallsPerTurn caps the total number of tool calls a single turn
+	// may make across all LLM rounds. The turn is aborted when the model
+	// requests more. 0 means unlimited.
+	MaxToolCallsPerTurn int `yaml:"maxToolCallsPerTurn,omitempty" json:"maxToolCallsPerTurn,omitempty"`
 }
 
 // Capability defines a single allow/deny rule for tool invocation.
@@ -307,6 +377,36 @@ type Capability struct {
 	// Constraints is an optional set of key-value restrictions on the tool
 	// arguments (e.g. {"url_prefix": "https://example.com"}).
 	Constraints map[string]string `yaml:"constraints,omitempty" json:"constraints,omitempty"`
+
+	// Output optionally post-processes the results of tool calls this rule
+	// allows before they reach the LLM.
+	Output *OutputTransform `yaml:"output,omitempty" json:"output,omitempty"`
+}
+
+// OutputTransform strips fields from and redacts text in a tool result.
+// Field filters apply to results that are JSON; redactions apply to every
+// result, after the field filters.
+type OutputTransform struct {
+	// AllowFields keeps only these top-level object fields (applied to each
+	// element when the result is an array). Mutually exclusive with
+	// DenyFields.
+	AllowFields []string `yaml:"allowFields,omitempty" json:"allowFields,omitempty"`
+
+	// DenyFields removes object fields with these names at any depth.
+	DenyFields []string `yaml:"denyFields,omitempty" json:"denyFields,omitempty"`
+
+	// Redact replaces every match of each pattern in the result text.
+	Redact []OutputRedaction `yaml:"redact,omitempty" json:"redact,omitempty"`
+}
+
+// OutputRedaction is a regular-expression replacement applied to tool output.
+type OutputRedaction struct {
+	// Pattern is a Go (RE2) regular expression.
+	Pattern string `yaml:"pattern" json:"pattern"`
+
+	// Replacement substitutes each match; $1-style group references are
+	// expanded. Defaults to "[REDACTED]".
+	Replacement string `yaml:"replacement,omitempty" json:"replacement,omitempty"`
 }
 
 // Approvals configures the approval workflow for this agent.
@@ -321,10 +421,51 @@ type Approvals struct {
 	Approvers []string `yaml:"approvers,omitempty" json:"approvers,omitempty"`
 
 	// TTLSeconds is how long an approval request waits before expiring.
-	// 0 defaults to 3600 (1 hour).
+	// 0 defaults to DefaultApprovalTTLSeconds; otherwise it must lie within
+	// ApprovalTTLBounds.
 	TTLSeconds int `yaml:"ttlSeconds,omitempty" json:"ttlSeconds,omitempty"`
 }
 
+// Default approval TTL bounds for Approvals.TTLSeconds. Below the floor an
+// approver has no realistic chance to respond; above the ceiling a turn
+// waiting on the approval stays blocked for days. Deployments can move them
+// with SetApprovalTTLBounds.
+const (
+	DefaultApprovalTTLSeconds = 3600
+	MinApprovalTTLSeconds     = 60
+	MaxApprovalTTLSeconds     = 86400
+)
+
+type ttlBounds struct{ min, max int }
+
+// approvalTTLBounds holds the bounds validation enforces; nil means the
+// Min/MaxApprovalTTLSeconds defaults.
+var approvalTTLBounds atomic.Pointer[ttlBounds]
+
+// ApprovalTTLBounds returns the inclusive range a non-zero
+// Approvals.TTLSeconds must lie in.
+func ApprovalTTLBounds() (minSeconds, maxSeconds int) {
+	if b := approvalTTLBounds.Load(); b != nil {
+		return b.min, b.max
+	}
+	return MinApprovalTTLSeconds, MaxApprovalTTLSeconds
+}
+
+// SetApprovalTTLBounds overrides the approval TTL range for every config
+// parsed afterwards. The range must include DefaultApprovalTTLSeconds, since
+// that is what a ttlSeconds of 0 resolves to.
+func SetApprovalTTLBounds(minSeconds, maxSeconds int) error {
+	if minSeconds <= 0 || maxSeconds < minSeconds {
+		return fmt.Errorf("approval TTL bounds must satisfy 0 < min <= max, got %d and %d", minSeconds, maxSeconds)
+	}
+	if DefaultApprovalTTLSeconds < minSeconds || DefaultApprovalTTLSeconds > maxSeconds {
+		return fmt.Errorf("approval TTL bounds %d..%d must include the default of %d seconds",
+			minSeconds, maxSeconds, DefaultApprovalTTLSeconds)
+	}
+	approvalTTLBounds.Store(&ttlBounds{min: minSeconds, max: maxSeconds})
+	return nil
+}
+
 // MCPServer describes a Model Context Protocol server process to be supervised
 // by the Gitai runtime.
 type MCPServer struct {
@@ -343,6 +484,131 @@ type MCPServer struct {
 	// AutoRestart specifies whether Gitai should restart this MCP if it exits
 	// unexpectedly.
 	AutoRestart bool `yaml:"autoRestart,omitempty" json:"autoRestart,omitempty"`
+
+	// Tools, when non-empty, is the allowlist of this MCP's tools presented
+	// to the LLM, optionally with rewritten descriptions. Tools not listed
+	// are hidden from the LLM. This only shapes what the model sees; use
+	// capabilities to enforce which tools may actually be called.
+	Tools []MCPTool `yaml:"tools,omitempty" json:"tools,omitempty"`
+
+	// Resources holds optional OS resource limits for the MCP process, so a
+	// runaway server cannot take the whole host. Unset means unlimited.
+	Resources MCPResources `yaml:"resources,omitempty" json:"resources,omitempty"`
+
+	// Readiness, when set, holds the server back after the MCP handshake
+	// until a probe tool call succeeds, so a server that is still loading
+	// is not offered to the LLM or sent tool calls.
+	Readiness *MCPReadiness `yaml:"readiness,omitempty" json:"readiness,omitempty"`
+
+	// MaxConcurrentCalls caps the tool calls in flight to this server at
+	// once, for servers that cannot handle overlapping requests (e.g. a
+	// single SQLite connection). Excess calls queue until a call finishes
+	// or their context ends. 0 means unlimited.
+	MaxConcurrentCalls int `yaml:"maxConcurrentCalls,omitempty" json:"maxConcurrentCalls,omitempty"`
+}
+
+// Readiness probe bounds for MCPReadiness.TimeoutSeconds.
+const (
+	DefaultMCPReadinessTimeoutSeconds = 60
+	MaxMCPReadinessTimeoutSeconds     = 600
+)
+
+// MCPReadiness configures an MCP server's readiness probe. Gitai calls Tool
+// repeatedly after the server starts; the server is ready once a call
+// returns without an error. A server that is not ready within the timeout
+// is stopped (and restarted later when autoRestart is set).
+type MCPReadiness struct {
+	// Tool is the name of the MCP tool used as the probe. It is called by
+	// the supervisor directly, bypassing capabilities and approvals, so it
+	// should be side-effect free.
+	Tool string `yaml:"tool" json:"tool"`
+
+	// Arguments are passed to the probe tool.
+	Arguments map[string]interface{} `yaml:"arguments,omitempty" json:"arguments,omitempty"`
+
+	// TimeoutSeconds caps how long the server may take to become ready.
+	// 0 means DefaultMCPReadinessTimeoutSeconds.
+	TimeoutSeconds int `yaml:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"`
+}
+
+// Timeout returns the effective readiness deadline.
+func (r MCPReadiness) Timeout() time.Duration {
+	if r.TimeoutSeconds <= 0 {
+		return DefaultMCPReadinessTimeoutSeconds * time.Second
+	}
+	return time.Duration(r.TimeoutSeconds) * time.Second
+}
+
+// MaxMCPNiceness is the largest accepted MCPResources.Niceness.
+const MaxMCPNiceness = 19
+
+// MCPResources are resource limits Gitai applies to an MCP server process
+// right after starting it, where the platform supports them (Linux).
+// Processes the server spawns afterwards inherit them.
+type MCPResources struct {
+	// MaxMemoryMB caps the process's virtual address space (RLIMIT_AS) in
+	// MiB; allocations beyond it fail. Runtimes that reserve large address
+	// ranges up front (Node.js, the JVM) need generous headroom. 0 means
+	// unlimited.
+	MaxMemoryMB int `yaml:"maxMemoryMB,omitempty" json:"maxMemoryMB,omitempty"`
+
+	// Niceness lowers the process's CPU scheduling priority, from 0 (the
+	// default, unchanged) to MaxMCPNiceness (lowest priority).
+	Niceness int `yaml:"niceness,omitempty" json:"niceness,omitempty"`
+}
+
+// Startup probe bounds for StartupProbe.TimeoutSeconds.
+const (
+	DefaultStartupProbeTimeoutSeconds = 60
+	MaxStartupProbeTimeoutSeconds     = 600
+)
+
+// StartupProbe configures the startup gate. While it is closed, Matrix
+// messages and gateway events wait instead of running turns whose tool
+// calls would fail. It opens once every configured MCP server answers
+// tools/list and every required secret has arrived from Ruriko, or when the
+// timeout elapses, whichever comes first.
+type StartupProbe struct {
+	// Enabled turns the gate on. It only applies when mcps or required
+	// secrets are configured.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// TimeoutSeconds caps the wait so a broken MCP cannot block the agent
+	// forever. 0 means DefaultStartupProbeTimeoutSeconds.
+	TimeoutSeconds int `yaml:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"`
+}
+
+// Timeout returns the effective maximum wait.
+func (p StartupProbe) Timeout() time.Duration {
+	if p.TimeoutSeconds <= 0 {
+		return DefaultStartupProbeTimeoutSeconds * time.Second
+	}
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// MCPTool is an entry in an MCP server's tool allowlist.
+type MCPTool struct {
+	// Name is the tool name as advertised by the MCP server.
+	Name string `yaml:"name" json:"name"`
+
+	// Description, when non-empty, replaces the MCP's own tool description
+	// in the definition presented to the LLM.
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// ExposedTool reports whether the named tool is presented to the LLM and
+// returns the description override for it ("" keeps the MCP's own). When no
+// allowlist is configured every tool is exposed.
+func (m MCPServer) ExposedTool(name string) (description string, ok bool) {
+	if len(m.Tools) == 0 {
+		return "", true
+	}
+	for _, t := range m.Tools {
+		if t.Name == name {
+			return t.Description, true
+		}
+	}
+	return "", false
 }
 
 // Gateway describes an inbound event gateway process to be supervised by the
@@ -371,12 +637,20 @@ type Gateway struct {
 	// Config holds gateway-specific configuration key-value pairs.
 	// For cron gateways: "expression" (cron schedule) and "payload" (trigger message).
 	// For webhook gateways: "authType" ("bearer" or "hmac-sha256"),
-	// "hmacSecretRef" (Ruriko secret ref for HMAC key), "path" (custom route).
+	// "hmacSecretRef" (Ruriko secret ref for HMAC key), "path" (custom route),
+	// "typeFrom" (JSON path or "header:<name>" giving the event type; see
+	// ParseWebhookTypeFrom), "contentType" (media type deliveries must carry,
+	// e.g. "application/json").
 	Config map[string]string `yaml:"config,omitempty" json:"config,omitempty"`
 
 	// AutoRestart specifies whether Gitai should restart this gateway process
 	// if it exits unexpectedly. Applies to external gateway processes only.
 	AutoRestart bool `yaml:"autoRestart,omitempty" json:"autoRestart,omitempty"`
+
+	// MaxEventsPerMinute caps inbound events from this gateway alone. The
+	// global limits.maxEventsPerMinute still applies, so the effective
+	// per-source limit is the smaller of the two. 0 means no per-gateway cap.
+	MaxEventsPerMinute int `yaml:"maxEventsPerMinute,omitempty" json:"maxEventsPerMinute,omitempty"`
 }
 
 // SecretRef is a reference to a Ruriko secret that should be injected into the
@@ -393,6 +667,11 @@ type SecretRef struct {
 	// Required indicates whether the agent should refuse to start if this
 	// secret is unavailable.
 	Required bool `yaml:"required,omitempty" json:"required,omitempty"`
+
+	// CacheTTLSeconds overrides how long the agent keeps the redeemed value
+	// before it must be refreshed. Ruriko sends it with each lease. 0 uses
+	// the agent's default cache TTL.
+	CacheTTLSeconds int `yaml:"cacheTTLSeconds,omitempty" json:"cacheTTLSeconds,omitempty"`
 }
 
 // Instructions defines the agent's operational workflow. Unlike Persona,
@@ -411,6 +690,13 @@ type Instructions struct {
 	// Context provides additional contextual information injected into the
 	// LLM prompt (user role, known peer agents).
 	Context InstructionsContext `yaml:"context,omitempty" json:"context,omitempty"`
+
+	// OnStartup, when set, is run as a turn once the config is in effect
+	// (e.g. "Announce that you are online and check your MCP tools."). It
+	// runs on the first load after the agent starts and again only when a
+	// config with a different hash is applied. The answer is posted to
+	// trust.adminRoom.
+	OnStartup string `yaml:"onStartup,omitempty" json:"onStartup,omitempty"`
 }
 
 // WorkflowStep is a single trigger → action pair in an agent's workflow.
@@ -442,6 +728,15 @@ type PeerRef struct {
 	// Role describes the peer's functional role from this agent's perspective
 	// (e.g. "News/search agent — ask it for news on specific tickers.").
 	Role string `yaml:"role" json:"role"`
+
+	// RoomID, when set, is the Matrix room the peer listens in. The peer then
+	// becomes a messaging target under its Name, so declaring it is enough to
+	// message it (see Config.MessagingTargets).
+	RoomID string `yaml:"roomId,omitempty" json:"roomId,omitempty"`
+
+	// MXID is the peer's Matrix user ID, shown in the prompt so the agent can
+	// recognise the peer's messages and address it.
+	MXID string `yaml:"mxid,omitempty" json:"mxid,omitempty"`
 }
 
 // Messaging defines the outbound Matrix messaging policy for an agent.
@@ -468,6 +763,80 @@ type MessagingTarget struct {
 	Alias string `yaml:"alias" json:"alias"`
 }
 
+// MessagingTargets returns every target the agent may message: the entries
+// of messaging.allowedTargets followed by each instructions.context.peers
+// entry with a roomId, aliased by the peer's name. A peer whose name is
+// already an allowedTargets alias is skipped.
+func (c *Config) MessagingTargets() []MessagingTarget {
+	if c == nil {
+		return nil
+	}
+	targets := append([]MessagingTarget(nil), c.Messaging.AllowedTargets...)
+	for _, peer := range c.Instructions.Context.Peers {
+		if peer.RoomID == "" {
+			continue
+		}
+		name := strings.TrimSpace(peer.Name)
+		dup := false
+		for _, t := range c.Messaging.AllowedTargets {
+			if t.Alias == name {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			targets = append(targets, MessagingTarget{RoomID: peer.RoomID, Alias: name})
+		}
+	}
+	return targets
+}
+
+// Notification channels for Notifications.Channel.
+const (
+	// NotificationChannelAdminRoom delivers alerts to trust.adminRoom.
+	NotificationChannelAdminRoom = "admin-room"
+)
+
+// Notification severities, in increasing order.
+const (
+	NotificationSeverityInfo     = "info"
+	NotificationSeverityWarning  = "warning"
+	NotificationSeverityCritical = "critical"
+)
+
+// NotificationSeverityRank returns the position of severity in the ordering
+// info < warning < critical, or -1 for an unknown severity.
+func NotificationSeverityRank(severity string) int {
+	switch severity {
+	case NotificationSeverityInfo:
+		return 0
+	case NotificationSeverityWarning:
+		return 1
+	case NotificationSeverityCritical:
+		return 2
+	}
+	return -1
+}
+
+// Notifications configures agent-initiated operator alerts.
+type Notifications struct {
+	// Channel is where alerts are delivered. The only supported value is
+	// "admin-room" (trust.adminRoom). Empty disables notify.operator.
+	Channel string `yaml:"channel,omitempty" json:"channel,omitempty"`
+
+	// MinSeverity drops alerts below this severity: "info" (default),
+	// "warning" or "critical".
+	MinSeverity string `yaml:"minSeverity,omitempty" json:"minSeverity,omitempty"`
+
+	// MaxPerMinute caps alerts delivered per minute. 0 means unlimited.
+	MaxPerMinute int `yaml:"maxPerMinute,omitempty" json:"maxPerMinute,omitempty"`
+}
+
+// Enabled reports whether operator alerts are configured.
+func (n Notifications) Enabled() bool {
+	return n.Channel != ""
+}
+
 // Persona defines the agent's LLM persona. This is purely cosmetic —
 // all access control is enforced via Capability rules, not the persona.
 type Persona struct {
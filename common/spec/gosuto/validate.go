@@ -3,6 +3,11 @@ package gosuto
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -13,9 +18,7 @@ import (
 // It is the canonical entry point for loading Gosuto configurations.
 func Parse(data []byte) (*Config, error) {
 	var cfg Config
-	dec := yaml.NewDecoder(bytes.NewReader(data))
-	dec.KnownFields(true)
-	if err := dec.Decode(&cfg); err != nil {
+	if err := DecodeStrict(data, &cfg); err != nil {
 		return nil, fmt.Errorf("gosuto parse: %w", err)
 	}
 	if err := Validate(&cfg); err != nil {
@@ -24,6 +27,38 @@ func Parse(data []byte) (*Config, error) {
 	return &cfg, nil
 }
 
+// DecodeStrict decodes YAML data into out, rejecting keys that have no
+// matching field (at any depth) with an error naming the key and its line.
+// Parse always decodes this way; callers decoding a single section, such as
+// an instructions or persona payload, use it when typos must not be dropped.
+func DecodeStrict(data []byte, out interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// ParseStrict is Parse for strict deployments: any advisory issue reported by
+// Warnings is treated as a validation error, so typos and uncovered MCP
+// servers block the config instead of being ignored at runtime.
+func ParseStrict(data []byte) (*Config, error) {
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	ws := Warnings(cfg)
+	if len(ws) == 0 {
+		return cfg, nil
+	}
+	msgs := make([]string, len(ws))
+	for i, w := range ws {
+		msgs[i] = w.Field + ": " + w.Message
+	}
+	return nil, fmt.Errorf("gosuto strict validation: %d warning(s): %s", len(ws), strings.Join(msgs, "; "))
+}
+
 // Validate checks a Config for structural correctness without executing it.
 // It returns the first validation error encountered, or nil if the config is valid.
 func Validate(cfg *Config) error {
@@ -51,6 +86,11 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("workflow: %w", err)
 	}
 
+	// ── Approvals ────────────────────────────────────────────────────────────
+	if err := validateApprovals(cfg.Approvals); err != nil {
+		return fmt.Errorf("approvals: %w", err)
+	}
+
 	// ── Limits ───────────────────────────────────────────────────────────────
 	if err := validateLimits(cfg.Limits); err != nil {
 		return fmt.Errorf("limits: %w", err)
@@ -85,6 +125,10 @@ func Validate(cfg *Config) error {
 		if _, dup := supervisorNames[gw.Name]; dup {
 			return fmt.Errorf("gateways[%d]: name %q already used by an MCP server or another gateway", i, gw.Name)
 		}
+		if global := cfg.Limits.MaxEventsPerMinute; global > 0 && gw.MaxEventsPerMinute > global {
+			return fmt.Errorf("gateways[%d] (%q): maxEventsPerMinute %d exceeds limits.maxEventsPerMinute %d",
+				i, gw.Name, gw.MaxEventsPerMinute, global)
+		}
 		supervisorNames[gw.Name] = struct{}{}
 	}
 
@@ -93,6 +137,9 @@ func Validate(cfg *Config) error {
 		if strings.TrimSpace(ref.Name) == "" {
 			return fmt.Errorf("secrets[%d]: name must not be empty", i)
 		}
+		if ref.CacheTTLSeconds < 0 {
+			return fmt.Errorf("secrets[%d] (%q): cacheTTLSeconds must be >= 0", i, ref.Name)
+		}
 	}
 
 	// ── Persona ──────────────────────────────────────────────────────────────
@@ -109,6 +156,24 @@ func Validate(cfg *Config) error {
 	if err := validateMessaging(cfg.Messaging); err != nil {
 		return fmt.Errorf("messaging: %w", err)
 	}
+	if err := validatePeerTargets(cfg); err != nil {
+		return err
+	}
+
+	// ── Notifications ─────────────────────────────────────────────────────────
+	if err := validateNotifications(cfg.Notifications, cfg.Trust); err != nil {
+		return fmt.Errorf("notifications: %w", err)
+	}
+
+	// ── Startup probe ────────────────────────────────────────────────────────
+	if err := validateStartupProbe(cfg.StartupProbe); err != nil {
+		return fmt.Errorf("startupProbe: %w", err)
+	}
+
+	// ── Features ─────────────────────────────────────────────────────────────
+	if err := validateFeatures(cfg.Features); err != nil {
+		return fmt.Errorf("features: %w", err)
+	}
 
 	return nil
 }
@@ -142,12 +207,58 @@ type Warning struct {
 //     name that has no allow:true capability rule. Per Invariant §2
 //     (Policy > Instructions > Persona), instructions cannot grant access to
 //     tools outside the capability rules — requests will be denied at runtime.
+//   - Feature flags not listed in KnownFeatures (likely a typo, or a flag for
+//     a newer Gitai); they are ignored.
+//   - Config keys of built-in gateways that the gateway type does not read
+//     (likely a typo, such as "expresion" for "expression"); they are
+//     ignored.
+//   - Capability rules with requireApproval while approvals is not enabled
+//     with a room; every matching call fails at runtime because there is
+//     nowhere to post the approval request.
+//   - instructions.onStartup without trust.adminRoom; the startup turn has
+//     nowhere to post and is dropped.
 func Warnings(cfg *Config) []Warning {
 	if cfg == nil {
 		return nil
 	}
 
 	var ws []Warning
+	for _, name := range cfg.UnknownFeatures() {
+		ws = append(ws, Warning{
+			Field:   "features." + name,
+			Message: fmt.Sprintf("unknown feature flag %q is ignored", name),
+		})
+	}
+
+	for i, gw := range cfg.Gateways {
+		for _, key := range gw.UnknownConfigKeys() {
+			msg := fmt.Sprintf("%s gateways do not read config key %q; it is ignored", gw.Type, key)
+			if near := nearestKey(key, gatewayConfigKeys[gw.Type]); near != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", near)
+			}
+			ws = append(ws, Warning{Field: fmt.Sprintf("gateways[%d].config.%s", i, key), Message: msg})
+		}
+	}
+
+	if !cfg.Approvals.Enabled || strings.TrimSpace(cfg.Approvals.Room) == "" {
+		for i, cap := range cfg.Capabilities {
+			if cap.Allow && cap.RequireApproval {
+				ws = append(ws, Warning{
+					Field: fmt.Sprintf("capabilities[%d].requireApproval", i),
+					Message: fmt.Sprintf(
+						"rule %q requires approval but approvals is not enabled with a room; "+
+							"matching calls will fail at runtime", cap.Name),
+				})
+			}
+		}
+	}
+
+	if strings.TrimSpace(cfg.Instructions.OnStartup) != "" && cfg.Trust.AdminRoom == "" {
+		ws = append(ws, Warning{
+			Field:   "instructions.onStartup",
+			Message: "trust.adminRoom is not set; the startup action will be dropped",
+		})
+	}
 
 	// Build the set of MCP server names covered by at least one allow:true rule.
 	allowed := make(map[string]bool, len(cfg.MCPs))
@@ -214,6 +325,27 @@ func validateTrust(t Trust) error {
 		}
 	}
 
+	for _, hs := range t.AllowedHomeservers {
+		if err := validateHomeserverPattern(hs); err != nil {
+			return fmt.Errorf("allowedHomeservers entry %q: %w", hs, err)
+		}
+	}
+
+	for _, sender := range t.IgnoreSenders {
+		if !isMXID(sender) {
+			return fmt.Errorf("ignoreSenders entry %q must be a full Matrix user ID (@user:server)", sender)
+		}
+	}
+
+	if len(t.MentionKeywords) > 0 && (t.RequireMention == nil || !*t.RequireMention) {
+		return fmt.Errorf("mentionKeywords requires requireMention: true")
+	}
+	for i, kw := range t.MentionKeywords {
+		if strings.TrimSpace(kw) == "" {
+			return fmt.Errorf("mentionKeywords[%d] must not be empty", i)
+		}
+	}
+
 	seen := make(map[string]struct{})
 	for i, peer := range t.TrustedPeers {
 		if !strings.HasPrefix(peer.MXID, "@") {
@@ -240,6 +372,29 @@ func validateTrust(t Trust) error {
 	return nil
 }
 
+// isMXID reports whether s has the shape of a Matrix user ID: "@", a
+// non-empty localpart, ":" and a non-empty server name, with no whitespace.
+func isMXID(s string) bool {
+	local, server, ok := strings.Cut(strings.TrimPrefix(s, "@"), ":")
+	return strings.HasPrefix(s, "@") && ok && local != "" && server != "" && !strings.ContainsAny(s, " \t*")
+}
+
+// validateHomeserverPattern checks a trust.allowedHomeservers entry. Valid
+// forms are "*", "*.<domain>" and "<domain>[:port]".
+func validateHomeserverPattern(p string) error {
+	if p == "*" {
+		return nil
+	}
+	domain := strings.TrimPrefix(p, "*.")
+	if domain == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	if strings.ContainsAny(domain, "*@!/ \t") || strings.HasPrefix(domain, ".") || strings.HasPrefix(domain, ":") {
+		return fmt.Errorf("must be a server name, \"*.<domain>\" or \"*\"")
+	}
+	return nil
+}
+
 func validateWorkflow(w Workflow) error {
 	for _, key := range w.Schemas.Duplicates {
 		return fmt.Errorf("workflow schemas contains duplicate key %s", key)
@@ -477,6 +632,18 @@ func isExternalSchemaRef(ref string) bool {
 	return false
 }
 
+func validateApprovals(a Approvals) error {
+	if a.TTLSeconds == 0 {
+		return nil
+	}
+	minTTL, maxTTL := ApprovalTTLBounds()
+	if a.TTLSeconds < minTTL || a.TTLSeconds > maxTTL {
+		return fmt.Errorf("ttlSeconds must be 0 (default %d) or between %d and %d, got %d",
+			DefaultApprovalTTLSeconds, minTTL, maxTTL, a.TTLSeconds)
+	}
+	return nil
+}
+
 func validateLimits(l Limits) error {
 	if l.MaxRequestsPerMinute < 0 {
 		return fmt.Errorf("maxRequestsPerMinute must be >= 0")
@@ -493,6 +660,12 @@ func validateLimits(l Limits) error {
 	if l.MaxEventsPerMinute < 0 {
 		return fmt.Errorf("maxEventsPerMinute must be >= 0")
 	}
+	if l.UnlimitedEvents && l.MaxEventsPerMinute > 0 {
+		return fmt.Errorf("unlimitedEvents cannot be combined with maxEventsPerMinute > 0")
+	}
+	if l.MaxToolCallsPerTurn < 0 {
+		return fmt.Errorf("maxToolCallsPerTurn must be >= 0")
+	}
 	return nil
 }
 
@@ -500,6 +673,36 @@ func validateCapability(c Capability) error {
 	if strings.TrimSpace(c.Name) == "" {
 		return fmt.Errorf("name must not be empty")
 	}
+	if c.Output != nil {
+		if err := validateOutputTransform(*c.Output); err != nil {
+			return fmt.Errorf("output: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateOutputTransform(o OutputTransform) error {
+	if len(o.AllowFields) > 0 && len(o.DenyFields) > 0 {
+		return fmt.Errorf("allowFields and denyFields are mutually exclusive")
+	}
+	for i, f := range o.AllowFields {
+		if strings.TrimSpace(f) == "" {
+			return fmt.Errorf("allowFields[%d] must not be empty", i)
+		}
+	}
+	for i, f := range o.DenyFields {
+		if strings.TrimSpace(f) == "" {
+			return fmt.Errorf("denyFields[%d] must not be empty", i)
+		}
+	}
+	for i, r := range o.Redact {
+		if r.Pattern == "" {
+			return fmt.Errorf("redact[%d]: pattern must not be empty", i)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("redact[%d]: invalid pattern: %w", i, err)
+		}
+	}
 	return nil
 }
 
@@ -510,6 +713,33 @@ func validateMCPServer(m MCPServer) error {
 	if strings.TrimSpace(m.Command) == "" {
 		return fmt.Errorf("command must not be empty")
 	}
+	seen := make(map[string]struct{}, len(m.Tools))
+	for i, t := range m.Tools {
+		if strings.TrimSpace(t.Name) == "" {
+			return fmt.Errorf("tools[%d]: name must not be empty", i)
+		}
+		if _, dup := seen[t.Name]; dup {
+			return fmt.Errorf("tools[%d]: duplicate tool %q", i, t.Name)
+		}
+		seen[t.Name] = struct{}{}
+	}
+	if m.Resources.MaxMemoryMB < 0 {
+		return fmt.Errorf("resources.maxMemoryMB must be >= 0")
+	}
+	if m.Resources.Niceness < 0 || m.Resources.Niceness > MaxMCPNiceness {
+		return fmt.Errorf("resources.niceness must be between 0 and %d", MaxMCPNiceness)
+	}
+	if m.MaxConcurrentCalls < 0 {
+		return fmt.Errorf("maxConcurrentCalls must be >= 0")
+	}
+	if r := m.Readiness; r != nil {
+		if strings.TrimSpace(r.Tool) == "" {
+			return fmt.Errorf("readiness.tool must not be empty")
+		}
+		if r.TimeoutSeconds < 0 || r.TimeoutSeconds > MaxMCPReadinessTimeoutSeconds {
+			return fmt.Errorf("readiness.timeoutSeconds must be between 0 and %d", MaxMCPReadinessTimeoutSeconds)
+		}
+	}
 	return nil
 }
 
@@ -518,6 +748,10 @@ func validateGateway(g Gateway) error {
 		return fmt.Errorf("name must not be empty")
 	}
 
+	if g.MaxEventsPerMinute < 0 {
+		return fmt.Errorf("maxEventsPerMinute must be >= 0")
+	}
+
 	hasType := strings.TrimSpace(g.Type) != ""
 	hasCommand := strings.TrimSpace(g.Command) != ""
 
@@ -558,6 +792,16 @@ func validateGateway(g Gateway) error {
 					return fmt.Errorf("type %q with authType hmac-sha256 requires config.hmacSecretRef to be set", g.Type)
 				}
 			}
+			if ct, ok := g.Config["contentType"]; ok {
+				if mt, _, err := mime.ParseMediaType(ct); err != nil || !strings.Contains(mt, "/") {
+					return fmt.Errorf("type %q: config.contentType %q is not a valid media type", g.Type, ct)
+				}
+			}
+			if tf, ok := g.Config["typeFrom"]; ok {
+				if _, err := ParseWebhookTypeFrom(tf); err != nil {
+					return fmt.Errorf("type %q: config.%w", g.Type, err)
+				}
+			}
 		default:
 			return fmt.Errorf("unknown built-in type %q; valid values are \"cron\" and \"webhook\"", g.Type)
 		}
@@ -566,6 +810,63 @@ func validateGateway(g Gateway) error {
 	return nil
 }
 
+// gatewayConfigKeys lists the config keys each built-in gateway type reads.
+var gatewayConfigKeys = map[string][]string{
+	"cron":    {"source", "expression", "payload", "target", "poll_interval"},
+	"webhook": {"path", "authType", "hmacSecretRef", "typeFrom", "contentType"},
+}
+
+// UnknownConfigKeys returns the sorted config keys of a built-in gateway that
+// its type does not read. External gateways (command set) define their own
+// keys, so it always returns nil for them.
+func (g Gateway) UnknownConfigKeys() []string {
+	known, ok := gatewayConfigKeys[g.Type]
+	if !ok {
+		return nil
+	}
+	var out []string
+	for key := range g.Config {
+		if !slices.Contains(known, key) {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// nearestKey returns the candidate within edit distance 2 of key, or "" when
+// none is that close.
+func nearestKey(key string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(c)); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
 func validateInstructions(ins Instructions) error {
 	for i, step := range ins.Workflow {
 		if strings.TrimSpace(step.Trigger) == "" {
@@ -575,13 +876,50 @@ func validateInstructions(ins Instructions) error {
 			return fmt.Errorf("workflow[%d]: action must not be empty", i)
 		}
 	}
+	names := make(map[string]struct{}, len(ins.Context.Peers))
 	for i, peer := range ins.Context.Peers {
-		if strings.TrimSpace(peer.Name) == "" {
+		name := strings.TrimSpace(peer.Name)
+		if name == "" {
 			return fmt.Errorf("context.peers[%d]: name must not be empty", i)
 		}
 		if strings.TrimSpace(peer.Role) == "" {
 			return fmt.Errorf("context.peers[%d]: role must not be empty", i)
 		}
+		if peer.MXID != "" && !isMXID(peer.MXID) {
+			return fmt.Errorf("context.peers[%d]: mxid %q must be a Matrix user ID (@user:server)", i, peer.MXID)
+		}
+		if peer.RoomID == "" {
+			continue
+		}
+		// A peer with a room is a messaging target aliased by its name.
+		if !strings.HasPrefix(peer.RoomID, "!") {
+			return fmt.Errorf("context.peers[%d]: roomId %q must start with '!'", i, peer.RoomID)
+		}
+		if strings.ContainsAny(name, " \t\n\r") {
+			return fmt.Errorf("context.peers[%d]: name %q must not contain whitespace when roomId is set", i, name)
+		}
+		if _, dup := names[name]; dup {
+			return fmt.Errorf("context.peers[%d]: duplicate peer %q with roomId", i, name)
+		}
+		names[name] = struct{}{}
+	}
+	return nil
+}
+
+// validatePeerTargets rejects a peer whose name is a messaging.allowedTargets
+// alias for a different room, since the peer would silently resolve to the
+// other room.
+func validatePeerTargets(cfg *Config) error {
+	for i, peer := range cfg.Instructions.Context.Peers {
+		if peer.RoomID == "" {
+			continue
+		}
+		for _, t := range cfg.Messaging.AllowedTargets {
+			if t.Alias == strings.TrimSpace(peer.Name) && t.RoomID != peer.RoomID {
+				return fmt.Errorf("instructions.context.peers[%d]: peer %q has roomId %q but messaging.allowedTargets maps it to %q",
+					i, t.Alias, peer.RoomID, t.RoomID)
+			}
+		}
 	}
 	return nil
 }
@@ -612,6 +950,38 @@ func validateMessaging(m Messaging) error {
 	return nil
 }
 
+func validateNotifications(n Notifications, trust Trust) error {
+	if n.MaxPerMinute < 0 {
+		return fmt.Errorf("maxPerMinute must be >= 0")
+	}
+	if n.MinSeverity != "" && NotificationSeverityRank(n.MinSeverity) < 0 {
+		return fmt.Errorf("minSeverity %q must be one of info, warning, critical", n.MinSeverity)
+	}
+	switch n.Channel {
+	case "":
+		if n.MinSeverity != "" || n.MaxPerMinute != 0 {
+			return fmt.Errorf("channel must be set")
+		}
+	case NotificationChannelAdminRoom:
+		if strings.TrimSpace(trust.AdminRoom) == "" {
+			return fmt.Errorf("channel %q requires trust.adminRoom", n.Channel)
+		}
+	default:
+		return fmt.Errorf("unsupported channel %q (supported: %s)", n.Channel, NotificationChannelAdminRoom)
+	}
+	return nil
+}
+
+func validateStartupProbe(p StartupProbe) error {
+	if p.TimeoutSeconds < 0 || p.TimeoutSeconds > MaxStartupProbeTimeoutSeconds {
+		return fmt.Errorf("timeoutSeconds must be between 0 and %d", MaxStartupProbeTimeoutSeconds)
+	}
+	if !p.Enabled && p.TimeoutSeconds != 0 {
+		return fmt.Errorf("timeoutSeconds requires enabled: true")
+	}
+	return nil
+}
+
 func validatePersona(p Persona) error {
 	if p.Temperature != nil {
 		if *p.Temperature < 0 || *p.Temperature > 2.0 {
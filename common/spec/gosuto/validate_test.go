@@ -1,8 +1,11 @@
 package gosuto_test
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bdobrica/Ruriko/common/spec/gosuto"
 )
@@ -181,6 +184,113 @@ trust:
 	}
 }
 
+func TestValidate_AllowedHomeservers(t *testing.T) {
+	base := `
+apiVersion: gosuto/v1
+metadata:
+  name: x
+trust:
+  allowedRooms: ["*"]
+  allowedSenders: ["*"]
+  allowedHomeservers: [%s]
+`
+	valid := []string{`"example.com"`, `"*.example.com"`, `"*"`, `"matrix.example.com:8448"`}
+	for _, v := range valid {
+		if _, err := gosuto.Parse([]byte(fmt.Sprintf(base, v))); err != nil {
+			t.Errorf("allowedHomeservers %s should be valid: %v", v, err)
+		}
+	}
+
+	invalid := []string{`""`, `"*."`, `"@alice:example.com"`, `"ex*ample.com"`, `".example.com"`, `"example.com/path"`}
+	for _, v := range invalid {
+		if _, err := gosuto.Parse([]byte(fmt.Sprintf(base, v))); err == nil {
+			t.Errorf("allowedHomeservers %s should be rejected", v)
+		}
+	}
+}
+
+func TestValidate_IgnoreSenders(t *testing.T) {
+	base := `
+apiVersion: gosuto/v1
+metadata:
+  name: x
+trust:
+  allowedRooms: ["*"]
+  allowedSenders: ["*"]
+  ignoreSenders: [%s]
+`
+	if _, err := gosuto.Parse([]byte(fmt.Sprintf(base, `"@otherbot:example.com"`))); err != nil {
+		t.Errorf("ignoreSenders with a full MXID should be valid: %v", err)
+	}
+
+	invalid := []string{`""`, `"*"`, `"otherbot:example.com"`, `"@otherbot"`, `"@:example.com"`, `"@other bot:example.com"`}
+	for _, v := range invalid {
+		if _, err := gosuto.Parse([]byte(fmt.Sprintf(base, v))); err == nil {
+			t.Errorf("ignoreSenders %s should be rejected", v)
+		}
+	}
+}
+
+func TestValidate_MentionKeywords(t *testing.T) {
+	base := `
+apiVersion: gosuto/v1
+metadata:
+  name: x
+trust:
+  allowedRooms: ["*"]
+  allowedSenders: ["*"]
+%s
+`
+	if _, err := gosuto.Parse([]byte(fmt.Sprintf(base, "  requireMention: true\n  mentionKeywords: [\"helper\"]"))); err != nil {
+		t.Errorf("mentionKeywords with requireMention: true should be valid: %v", err)
+	}
+
+	invalid := []string{
+		"  mentionKeywords: [\"helper\"]",
+		"  requireMention: false\n  mentionKeywords: [\"helper\"]",
+		"  requireMention: true\n  mentionKeywords: [\" \"]",
+	}
+	for _, v := range invalid {
+		if _, err := gosuto.Parse([]byte(fmt.Sprintf(base, v))); err == nil {
+			t.Errorf("trust with %q should be rejected", v)
+		}
+	}
+}
+
+func TestValidate_CapabilityOutputTransform(t *testing.T) {
+	base := `
+apiVersion: gosuto/v1
+metadata:
+  name: x
+trust:
+  allowedRooms: ["*"]
+  allowedSenders: ["*"]
+capabilities:
+  - name: search
+    mcp: search
+    tool: "*"
+    allow: true
+    output:
+%s
+`
+	valid := "      denyFields: [internal_id]\n      redact:\n        - pattern: '\\d{3}-\\d{4}'"
+	if _, err := gosuto.Parse([]byte(fmt.Sprintf(base, valid))); err != nil {
+		t.Errorf("output transform should be valid: %v", err)
+	}
+
+	invalid := []string{
+		"      allowFields: [title]\n      denyFields: [internal_id]",
+		"      denyFields: [\"\"]",
+		"      redact:\n        - pattern: '(unclosed'",
+		"      redact:\n        - replacement: x",
+	}
+	for _, v := range invalid {
+		if _, err := gosuto.Parse([]byte(fmt.Sprintf(base, v))); err == nil {
+			t.Errorf("output transform %q should be rejected", v)
+		}
+	}
+}
+
 func TestValidate_DuplicateMCPName(t *testing.T) {
 	_, err := gosuto.Parse([]byte(`
 apiVersion: gosuto/v1
@@ -200,6 +310,136 @@ mcps:
 	}
 }
 
+func TestValidate_MCPToolAllowlist(t *testing.T) {
+	base := `
+apiVersion: gosuto/v1
+metadata:
+  name: x
+trust:
+  allowedRooms: ["*"]
+  allowedSenders: ["*"]
+mcps:
+  - name: foo
+    command: foo
+    tools:
+`
+	cases := []struct {
+		name    string
+		tools   string
+		wantErr bool
+	}{
+		{"valid", "      - name: search\n        description: Search things.\n      - name: fetch\n", false},
+		{"empty name", "      - description: no name\n", true},
+		{"duplicate", "      - name: search\n      - name: search\n", true},
+	}
+	for _, tc := range cases {
+		_, err := gosuto.Parse([]byte(base + tc.tools))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidate_MCPResources(t *testing.T) {
+	base := `
+apiVersion: gosuto/v1
+metadata:
+  name: x
+trust:
+  allowedRooms: ["*"]
+  allowedSenders: ["*"]
+mcps:
+  - name: foo
+    command: foo
+    resources:
+`
+	cases := []struct {
+		name      string
+		resources string
+		wantErr   bool
+	}{
+		{"valid", "      maxMemoryMB: 512\n      niceness: 10\n", false},
+		{"negative memory", "      maxMemoryMB: -1\n", true},
+		{"negative niceness", "      niceness: -5\n", true},
+		{"niceness too high", "      niceness: 20\n", true},
+	}
+	for _, tc := range cases {
+		_, err := gosuto.Parse([]byte(base + tc.resources))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidate_MCPReadiness(t *testing.T) {
+	base := `
+apiVersion: gosuto/v1
+metadata:
+  name: x
+trust:
+  allowedRooms: ["*"]
+  allowedSenders: ["*"]
+mcps:
+  - name: foo
+    command: foo
+    readiness:
+`
+	cases := []struct {
+		name      string
+		readiness string
+		wantErr   bool
+	}{
+		{"valid", "      tool: ping\n      arguments: {deep: false}\n      timeoutSeconds: 120\n", false},
+		{"missing tool", "      timeoutSeconds: 10\n", true},
+		{"negative timeout", "      tool: ping\n      timeoutSeconds: -1\n", true},
+		{"timeout too long", "      tool: ping\n      timeoutSeconds: 601\n", true},
+	}
+	for _, tc := range cases {
+		_, err := gosuto.Parse([]byte(base + tc.readiness))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidate_MCPMaxConcurrentCalls(t *testing.T) {
+	base := `
+apiVersion: gosuto/v1
+metadata:
+  name: x
+trust:
+  allowedRooms: ["*"]
+  allowedSenders: ["*"]
+mcps:
+  - name: foo
+    command: foo
+    maxConcurrentCalls: `
+	if _, err := gosuto.Parse([]byte(base + "1\n")); err != nil {
+		t.Errorf("maxConcurrentCalls: 1: unexpected error: %v", err)
+	}
+	if _, err := gosuto.Parse([]byte(base + "-1\n")); err == nil {
+		t.Error("maxConcurrentCalls: -1: expected an error")
+	}
+}
+
+func TestMCPServer_ExposedTool(t *testing.T) {
+	open := gosuto.MCPServer{Name: "foo"}
+	if desc, ok := open.ExposedTool("anything"); !ok || desc != "" {
+		t.Errorf("no allowlist: got (%q, %v), want (\"\", true)", desc, ok)
+	}
+
+	listed := gosuto.MCPServer{Name: "foo", Tools: []gosuto.MCPTool{{Name: "search", Description: "Custom."}, {Name: "fetch"}}}
+	if desc, ok := listed.ExposedTool("search"); !ok || desc != "Custom." {
+		t.Errorf("search: got (%q, %v), want (\"Custom.\", true)", desc, ok)
+	}
+	if desc, ok := listed.ExposedTool("fetch"); !ok || desc != "" {
+		t.Errorf("fetch: got (%q, %v), want (\"\", true)", desc, ok)
+	}
+	if _, ok := listed.ExposedTool("delete_all"); ok {
+		t.Error("delete_all should be hidden by the allowlist")
+	}
+}
+
 func TestValidate_NegativeTemperature(t *testing.T) {
 	_, err := gosuto.Parse([]byte(`
 apiVersion: gosuto/v1
@@ -1013,6 +1253,56 @@ gateways:
 	}
 }
 
+func TestValidate_Gateway_WebhookTypeFrom(t *testing.T) {
+	for _, tc := range []struct {
+		typeFrom string
+		wantErr  bool
+	}{
+		{`"$.action"`, false},
+		{`"$.check_run.status"`, false},
+		{`"header:X-GitHub-Event"`, false},
+		{`"action"`, true},
+		{`"$."`, true},
+		{`"$.a..b"`, true},
+		{`"$.items[0]"`, true},
+		{`"header:"`, true},
+	} {
+		_, err := gosuto.Parse([]byte(gatewayBase() + `
+gateways:
+  - name: typed-hook
+    type: webhook
+    config:
+      typeFrom: ` + tc.typeFrom + `
+`))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("typeFrom %s: err = %v, wantErr %v", tc.typeFrom, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidate_Gateway_WebhookContentType(t *testing.T) {
+	for _, tc := range []struct {
+		contentType string
+		wantErr     bool
+	}{
+		{`"application/json"`, false},
+		{`"application/x-www-form-urlencoded"`, false},
+		{`"json"`, true},
+		{`"application/json; charset"`, true},
+	} {
+		_, err := gosuto.Parse([]byte(gatewayBase() + `
+gateways:
+  - name: typed-hook
+    type: webhook
+    config:
+      contentType: ` + tc.contentType + `
+`))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("contentType %s: err = %v, wantErr %v", tc.contentType, err, tc.wantErr)
+		}
+	}
+}
+
 func TestValidate_Gateway_ExternalCommandValid(t *testing.T) {
 	_, err := gosuto.Parse([]byte(gatewayBase() + `
 gateways:
@@ -1069,6 +1359,100 @@ limits:
 	}
 }
 
+func TestValidate_Limits_UnlimitedEventsWithMaxEventsPerMinute(t *testing.T) {
+	_, err := gosuto.Parse([]byte(gatewayBase() + `
+limits:
+  maxEventsPerMinute: 10
+  unlimitedEvents: true
+`))
+	if err == nil {
+		t.Fatal("expected error for unlimitedEvents combined with maxEventsPerMinute, got nil")
+	}
+}
+
+func TestValidate_Limits_UnlimitedEventsAlone(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(gatewayBase() + `
+limits:
+  unlimitedEvents: true
+`))
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	if !cfg.Limits.UnlimitedEvents {
+		t.Error("expected unlimitedEvents to be true")
+	}
+}
+
+func TestValidate_Gateway_NegativeMaxEventsPerMinute(t *testing.T) {
+	_, err := gosuto.Parse([]byte(gatewayBase() + `
+gateways:
+  - name: feed
+    type: cron
+    maxEventsPerMinute: -1
+    config:
+      expression: "*/5 * * * *"
+`))
+	if err == nil {
+		t.Fatal("expected error for negative gateway maxEventsPerMinute, got nil")
+	}
+}
+
+func TestValidate_Gateway_MaxEventsPerMinuteAboveGlobal(t *testing.T) {
+	_, err := gosuto.Parse([]byte(gatewayBase() + `
+limits:
+  maxEventsPerMinute: 10
+gateways:
+  - name: feed
+    type: cron
+    maxEventsPerMinute: 20
+    config:
+      expression: "*/5 * * * *"
+`))
+	if err == nil {
+		t.Fatal("expected error for gateway maxEventsPerMinute above the global limit, got nil")
+	}
+}
+
+func TestValidate_Gateway_MaxEventsPerMinuteWithinGlobal(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(gatewayBase() + `
+limits:
+  maxEventsPerMinute: 10
+gateways:
+  - name: feed
+    type: cron
+    maxEventsPerMinute: 2
+    config:
+      expression: "*/5 * * * *"
+`))
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	if cfg.Gateways[0].MaxEventsPerMinute != 2 {
+		t.Errorf("gateway maxEventsPerMinute: got %d, want 2", cfg.Gateways[0].MaxEventsPerMinute)
+	}
+}
+
+func TestValidate_Secrets_NegativeCacheTTL(t *testing.T) {
+	_, err := gosuto.Parse([]byte(gatewayBase() + `
+secrets:
+  - name: api_key
+    cacheTTLSeconds: -1
+`))
+	if err == nil {
+		t.Fatal("expected error for negative cacheTTLSeconds, got nil")
+	}
+}
+
+func TestValidate_Limits_NegativeMaxToolCallsPerTurn(t *testing.T) {
+	_, err := gosuto.Parse([]byte(gatewayBase() + `
+limits:
+  maxToolCallsPerTurn: -1
+`))
+	if err == nil {
+		t.Fatal("expected error for negative maxToolCallsPerTurn, got nil")
+	}
+}
+
 // ── Instructions section tests ────────────────────────────────────────────────
 
 const instructionsBase = `
@@ -1418,6 +1802,106 @@ func TestWarnings_NilConfig(t *testing.T) {
 	}
 }
 
+// ── Feature flag tests ───────────────────────────────────────────────────────
+
+func TestValidate_Features(t *testing.T) {
+	cases := []struct {
+		name     string
+		features string
+		wantErr  bool
+	}{
+		{"bool and string", "  toolSchemaValidation: false\n  logLevel: debug\n", false},
+		{"quoted bool", "  resourceTools: \"true\"\n", false},
+		{"unknown flag accepted", "  futureThing: on\n", false},
+		{"non-boolean", "  toolSchemaValidation: maybe\n", true},
+		{"bad enum", "  logLevel: verbose\n", true},
+	}
+	for _, tc := range cases {
+		_, err := gosuto.Parse([]byte(warningsBase + "features:\n" + tc.features))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestConfig_FeatureDefaults(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(warningsBase + "features:\n  toolSchemaValidation: false\n"))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if cfg.FeatureBool("toolSchemaValidation") {
+		t.Error("toolSchemaValidation should be false when set to false")
+	}
+	if !cfg.FeatureBool("resourceTools") {
+		t.Error("resourceTools should default to true when unset")
+	}
+	if cfg.FeatureBool("futureThing") {
+		t.Error("unknown flags should read as false")
+	}
+	var nilCfg *gosuto.Config
+	if !nilCfg.FeatureBool("toolSchemaValidation") {
+		t.Error("nil config should yield flag defaults")
+	}
+}
+
+func TestWarnings_UnknownFeature(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(warningsBase + "features:\n  toolSchemaValidaton: false\n"))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	ws := gosuto.Warnings(cfg)
+	if len(ws) != 1 || ws[0].Field != "features.toolSchemaValidaton" {
+		t.Errorf("expected one warning for the misspelt flag, got %v", ws)
+	}
+}
+
+const approvalRuleYAML = `capabilities:
+  - name: approve-write
+    mcp: docs
+    tool: write
+    allow: true
+    requireApproval: true
+`
+
+func TestWarnings_ApprovalRuleWithoutApprovalsRoom(t *testing.T) {
+	for name, extra := range map[string]string{
+		"no approvals section": "",
+		"disabled":             "approvals:\n  room: \"!approvals:example.com\"\n",
+		"no room":              "approvals:\n  enabled: true\n",
+	} {
+		cfg, err := gosuto.Parse([]byte(warningsBase + approvalRuleYAML + extra))
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error: %v", name, err)
+		}
+		ws := gosuto.Warnings(cfg)
+		if len(ws) != 1 || ws[0].Field != "capabilities[0].requireApproval" {
+			t.Errorf("%s: expected one requireApproval warning, got %v", name, ws)
+		}
+	}
+}
+
+func TestWarnings_ApprovalRuleWithApprovalsRoom(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(warningsBase + approvalRuleYAML +
+		"approvals:\n  enabled: true\n  room: \"!approvals:example.com\"\n"))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if ws := gosuto.Warnings(cfg); len(ws) != 0 {
+		t.Errorf("expected no warnings, got %v", ws)
+	}
+}
+
+func TestWarnings_OnStartupWithoutAdminRoom(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(warningsBase + "instructions:\n  onStartup: \"Say hello.\"\n"))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	ws := gosuto.Warnings(cfg)
+	if len(ws) != 1 || ws[0].Field != "instructions.onStartup" {
+		t.Errorf("expected one onStartup warning, got %v", ws)
+	}
+}
+
 // ── Messaging validation tests ───────────────────────────────────────────────
 
 const messagingBase = `
@@ -1617,6 +2101,111 @@ messaging:
 	}
 }
 
+// TestMessaging_PeerWithRoomIsTarget verifies that a peer declared with a
+// roomId becomes a messaging target aliased by its name, after the explicit
+// allowedTargets.
+func TestMessaging_PeerWithRoomIsTarget(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(messagingBase + `
+messaging:
+  allowedTargets:
+    - roomId: "!user-dm:localhost"
+      alias: "user"
+instructions:
+  context:
+    peers:
+      - name: kumo
+        role: "News agent."
+        roomId: "!kumo-admin:localhost"
+        mxid: "@kumo:localhost"
+      - name: saito
+        role: "Scheduler; descriptive only."
+`))
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	got := cfg.MessagingTargets()
+	want := []gosuto.MessagingTarget{
+		{RoomID: "!user-dm:localhost", Alias: "user"},
+		{RoomID: "!kumo-admin:localhost", Alias: "kumo"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("MessagingTargets = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MessagingTargets[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestMessaging_InvalidPeerTargets verifies the checks on peers that declare
+// a room.
+func TestMessaging_InvalidPeerTargets(t *testing.T) {
+	cases := map[string]struct {
+		yaml    string
+		wantErr string
+	}{
+		"room without bang": {`
+instructions:
+  context:
+    peers:
+      - name: kumo
+        role: "News agent."
+        roomId: "kumo-admin:localhost"
+`, "roomId"},
+		"bad mxid": {`
+instructions:
+  context:
+    peers:
+      - name: kumo
+        role: "News agent."
+        mxid: "kumo"
+`, "mxid"},
+		"name with whitespace": {`
+instructions:
+  context:
+    peers:
+      - name: "news agent"
+        role: "News agent."
+        roomId: "!kumo-admin:localhost"
+`, "whitespace"},
+		"duplicate peer": {`
+instructions:
+  context:
+    peers:
+      - name: kumo
+        role: "News agent."
+        roomId: "!kumo-admin:localhost"
+      - name: kumo
+        role: "News agent again."
+        roomId: "!kumo-other:localhost"
+`, "duplicate"},
+		"conflicts with allowedTargets": {`
+messaging:
+  allowedTargets:
+    - roomId: "!elsewhere:localhost"
+      alias: "kumo"
+instructions:
+  context:
+    peers:
+      - name: kumo
+        role: "News agent."
+        roomId: "!kumo-admin:localhost"
+`, "allowedTargets"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := gosuto.Parse([]byte(messagingBase + tc.yaml))
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error should mention %q, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
 const r61Base = `
 apiVersion: gosuto/v1
 metadata:
@@ -2181,3 +2770,304 @@ workflow:
 		t.Fatalf("maxOutputItems=0 should be valid: %v", err)
 	}
 }
+
+// ── Notifications validation tests ───────────────────────────────────────────
+
+func TestNotifications_AdminRoomChannelIsValid(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(messagingBase + `  adminRoom: "!admin:example.com"
+notifications:
+  channel: admin-room
+  minSeverity: warning
+  maxPerMinute: 3
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Notifications.Enabled() || cfg.Notifications.MaxPerMinute != 3 {
+		t.Errorf("notifications = %+v", cfg.Notifications)
+	}
+}
+
+func TestNotifications_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"no admin room": `notifications:
+  channel: admin-room
+`,
+		"unknown channel": `  adminRoom: "!admin:example.com"
+notifications:
+  channel: email
+`,
+		"bad severity": `  adminRoom: "!admin:example.com"
+notifications:
+  channel: admin-room
+  minSeverity: loud
+`,
+		"negative rate": `  adminRoom: "!admin:example.com"
+notifications:
+  channel: admin-room
+  maxPerMinute: -1
+`,
+		"settings without channel": `notifications:
+  minSeverity: warning
+`,
+	}
+	for name, extra := range cases {
+		if _, err := gosuto.Parse([]byte(messagingBase + extra)); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+// ── Startup probe validation tests ───────────────────────────────────────────
+
+func TestStartupProbe_Valid(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(messagingBase + `startupProbe:
+  enabled: true
+  timeoutSeconds: 30
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.StartupProbe.Enabled || cfg.StartupProbe.Timeout() != 30*time.Second {
+		t.Errorf("startupProbe = %+v", cfg.StartupProbe)
+	}
+	if got := (gosuto.StartupProbe{Enabled: true}).Timeout(); got != gosuto.DefaultStartupProbeTimeoutSeconds*time.Second {
+		t.Errorf("default Timeout() = %v", got)
+	}
+}
+
+func TestStartupProbe_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"negative timeout": `startupProbe:
+  enabled: true
+  timeoutSeconds: -1
+`,
+		"timeout too long": `startupProbe:
+  enabled: true
+  timeoutSeconds: 601
+`,
+		"timeout without enabled": `startupProbe:
+  timeoutSeconds: 30
+`,
+	}
+	for name, extra := range cases {
+		if _, err := gosuto.Parse([]byte(messagingBase + extra)); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+// ── Approvals validation tests ───────────────────────────────────────────────
+
+func TestApprovals_TTLInRange(t *testing.T) {
+	for _, ttl := range []int{0, gosuto.MinApprovalTTLSeconds, 300, gosuto.MaxApprovalTTLSeconds} {
+		extra := fmt.Sprintf("approvals:\n  enabled: true\n  room: \"!approvals:example.com\"\n  ttlSeconds: %d\n", ttl)
+		if _, err := gosuto.Parse([]byte(messagingBase + extra)); err != nil {
+			t.Errorf("ttlSeconds %d: unexpected error: %v", ttl, err)
+		}
+	}
+}
+
+func TestApprovals_TTLOutOfRange(t *testing.T) {
+	cases := map[string]int{
+		"negative":   -1,
+		"below min":  gosuto.MinApprovalTTLSeconds - 1,
+		"one second": 1,
+		"above max":  gosuto.MaxApprovalTTLSeconds + 1,
+	}
+	for name, ttl := range cases {
+		extra := fmt.Sprintf("approvals:\n  enabled: true\n  room: \"!approvals:example.com\"\n  ttlSeconds: %d\n", ttl)
+		_, err := gosuto.Parse([]byte(messagingBase + extra))
+		if err == nil || !strings.Contains(err.Error(), "approvals: ttlSeconds") {
+			t.Errorf("%s: err = %v, want an approvals.ttlSeconds error", name, err)
+		}
+	}
+}
+
+func TestApprovals_TTLConfiguredBounds(t *testing.T) {
+	if err := gosuto.SetApprovalTTLBounds(300, 7200); err != nil {
+		t.Fatalf("SetApprovalTTLBounds: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = gosuto.SetApprovalTTLBounds(gosuto.MinApprovalTTLSeconds, gosuto.MaxApprovalTTLSeconds)
+	})
+
+	cases := map[int]bool{0: true, 300: true, 7200: true, 60: false, 299: false, 7201: false, 86400: false}
+	for ttl, ok := range cases {
+		extra := fmt.Sprintf("approvals:\n  enabled: true\n  room: \"!approvals:example.com\"\n  ttlSeconds: %d\n", ttl)
+		_, err := gosuto.Parse([]byte(messagingBase + extra))
+		if ok && err != nil {
+			t.Errorf("ttlSeconds %d: unexpected error: %v", ttl, err)
+		}
+		if !ok && (err == nil || !strings.Contains(err.Error(), "between 300 and 7200")) {
+			t.Errorf("ttlSeconds %d: err = %v, want a 300..7200 range error", ttl, err)
+		}
+	}
+}
+
+func TestSetApprovalTTLBounds_RejectsInvalidRange(t *testing.T) {
+	cases := map[string][2]int{
+		"zero min":         {0, 7200},
+		"max below min":    {600, 300},
+		"excludes default": {60, 1800},
+	}
+	for name, b := range cases {
+		if err := gosuto.SetApprovalTTLBounds(b[0], b[1]); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if lo, hi := gosuto.ApprovalTTLBounds(); lo != gosuto.MinApprovalTTLSeconds || hi != gosuto.MaxApprovalTTLSeconds {
+		t.Errorf("bounds = %d..%d after rejected updates, want the defaults", lo, hi)
+	}
+}
+
+// ── Gateway config key warnings ──────────────────────────────────────────────
+
+func TestWarnings_GatewayConfigKeyTypo(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(gatewayBase() + `
+gateways:
+  - name: scheduler
+    type: cron
+    config:
+      expression: "*/15 * * * *"
+      expresion: "0 * * * *"
+`))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	ws := gosuto.Warnings(cfg)
+	if len(ws) != 1 || ws[0].Field != "gateways[0].config.expresion" {
+		t.Fatalf("expected one warning for the misspelt key, got %v", ws)
+	}
+	if !strings.Contains(ws[0].Message, `did you mean "expression"`) {
+		t.Errorf("warning message = %q, want a suggestion", ws[0].Message)
+	}
+}
+
+func TestDecodeStrict_RejectsUnknownKeys(t *testing.T) {
+	for _, data := range []string{
+		"role: \"r\"\nworkflw: []\n",
+		"role: \"r\"\nworkflow:\n  - trigger: \"t\"\n    acton: \"a\"\n",
+	} {
+		var strict gosuto.Instructions
+		err := gosuto.DecodeStrict([]byte(data), &strict)
+		if err == nil || !strings.Contains(err.Error(), "not found in type") {
+			t.Errorf("DecodeStrict(%q) err = %v, want an unknown-field error", data, err)
+		}
+	}
+}
+
+func TestParseStrict_RejectsWarnings(t *testing.T) {
+	data := []byte(gatewayBase() + `
+gateways:
+  - name: scheduler
+    type: cron
+    config:
+      expression: "*/15 * * * *"
+      expresion: "0 * * * *"
+`)
+	if _, err := gosuto.Parse(data); err != nil {
+		t.Fatalf("lenient Parse should accept a config with warnings: %v", err)
+	}
+	_, err := gosuto.ParseStrict(data)
+	if err == nil {
+		t.Fatal("ParseStrict should reject a config with warnings")
+	}
+	if !strings.Contains(err.Error(), "gateways[0].config.expresion") {
+		t.Errorf("error = %q, want the warning field", err)
+	}
+}
+
+func TestParseStrict_AcceptsCleanConfig(t *testing.T) {
+	cfg, err := gosuto.ParseStrict([]byte(gatewayBase() + `
+gateways:
+  - name: scheduler
+    type: cron
+    config:
+      expression: "*/15 * * * *"
+`))
+	if err != nil {
+		t.Fatalf("ParseStrict: %v", err)
+	}
+	if len(cfg.Gateways) != 1 {
+		t.Errorf("gateways = %d, want 1", len(cfg.Gateways))
+	}
+}
+
+func TestWarnings_GatewayConfigKeysClean(t *testing.T) {
+	cfg, err := gosuto.Parse([]byte(gatewayBase() + `
+gateways:
+  - name: scheduler
+    type: cron
+    config:
+      expression: "*/15 * * * *"
+      payload: "tick"
+  - name: github
+    type: webhook
+    config:
+      authType: bearer
+      typeFrom: "$.action"
+      contentType: application/json
+  - name: custom
+    command: /usr/local/bin/my-gateway
+    config:
+      anything: goes
+`))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if ws := gosuto.Warnings(cfg); len(ws) != 0 {
+		t.Errorf("expected no warnings, got %v", ws)
+	}
+}
+
+func TestCanonicalize_CosmeticDifferencesHashEqual(t *testing.T) {
+	a := []byte(`apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms:
+    - "!room:example.com"
+  allowedSenders:
+    - "@alice:example.com"
+features:
+  beta: true
+  alpha: false
+`)
+	b := []byte(`# same config, reordered and restyled
+trust:
+    allowedSenders: ['@alice:example.com']
+    allowedRooms: ['!room:example.com']
+features: {alpha: false, beta: true}
+metadata: {name: "test-agent"}
+apiVersion: "gosuto/v1"
+`)
+	ca, err := gosuto.Canonicalize(a)
+	if err != nil {
+		t.Fatalf("Canonicalize(a): %v", err)
+	}
+	cb, err := gosuto.Canonicalize(b)
+	if err != nil {
+		t.Fatalf("Canonicalize(b): %v", err)
+	}
+	if sha256.Sum256(ca) != sha256.Sum256(cb) {
+		t.Errorf("canonical forms differ:\n%s\n---\n%s", ca, cb)
+	}
+	if sha256.Sum256(a) == sha256.Sum256(b) {
+		t.Fatal("test inputs must differ before canonicalization")
+	}
+}
+
+func TestCanonicalize_SemanticDifferenceHashDiffers(t *testing.T) {
+	ca, err := gosuto.Canonicalize([]byte(minimalValid))
+	if err != nil {
+		t.Fatalf("Canonicalize: %v", err)
+	}
+	cb, err := gosuto.Canonicalize([]byte(strings.Replace(minimalValid, "@alice:example.com", "@bob:example.com", 1)))
+	if err != nil {
+		t.Fatalf("Canonicalize: %v", err)
+	}
+	if sha256.Sum256(ca) == sha256.Sum256(cb) {
+		t.Error("configs with different senders must not canonicalize to the same bytes")
+	}
+}
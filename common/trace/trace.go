@@ -10,6 +10,13 @@ import (
 	"time"
 )
 
+// Header is the HTTP header that carries a trace ID from Ruriko through the
+// webhook proxy to an agent's ACP server, so one trace spans all three.
+const Header = "X-Trace-ID"
+
+// maxIDLen bounds trace IDs accepted from the wire.
+const maxIDLen = 128
+
 // traceKey is the unexported context key used to store the trace ID.
 type traceKey struct{}
 
@@ -35,3 +42,21 @@ func FromContext(ctx context.Context) string {
 	}
 	return ""
 }
+
+// ValidID reports whether id is acceptable as a trace ID received from
+// another process: non-empty, at most 128 characters, and limited to
+// letters, digits, '-', '_' and '.', so it cannot inject into log lines.
+func ValidID(id string) bool {
+	if id == "" || len(id) > maxIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"log/slog"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/store"
+)
+
+// effectiveACPToken returns the ACP bearer token to serve with. A token
+// installed by POST /token/rotate survives restarts, but only while
+// envToken is still the GITAI_ACP_TOKEN it replaced: a changed environment
+// means the operator (or Ruriko recreating the container) chose a token
+// deliberately, and that choice wins.
+func effectiveACPToken(db *store.Store, envToken string) string {
+	base, rotated, err := db.LoadRotatedACPToken()
+	if err != nil {
+		slog.Warn("failed to load rotated ACP token; using GITAI_ACP_TOKEN", "err", err)
+		return envToken
+	}
+	if rotated == "" || base != envToken {
+		return envToken
+	}
+	slog.Info("using rotated ACP token")
+	return rotated
+}
+
+// persistACPToken implements control.Handlers.PersistToken.
+func (a *App) persistACPToken(token string) error {
+	return a.db.SaveRotatedACPToken(a.cfg.ACPToken, token)
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/store"
+)
+
+func TestEffectiveACPToken_RotatedTokenOnlyWhileEnvUnchanged(t *testing.T) {
+	db, err := store.New(filepath.Join(t.TempDir(), "gitai.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if got := effectiveACPToken(db, "env-token"); got != "env-token" {
+		t.Errorf("no rotation: got %q, want env-token", got)
+	}
+
+	a := &App{cfg: &Config{ACPToken: "env-token"}, db: db}
+	if err := a.persistACPToken("rotated-token"); err != nil {
+		t.Fatalf("persistACPToken: %v", err)
+	}
+	if got := effectiveACPToken(db, "env-token"); got != "rotated-token" {
+		t.Errorf("after rotation: got %q, want rotated-token", got)
+	}
+	if got := effectiveACPToken(db, "redeployed-token"); got != "redeployed-token" {
+		t.Errorf("changed env: got %q, want redeployed-token", got)
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
+)
+
+const agentRequestToken = "0123456789abcdef"
+
+func TestTagAgentReply(t *testing.T) {
+	body := "Hey test-agent, any news?\n\n" + builtin.RequestMarker(agentRequestToken)
+	if got, want := tagAgentReply(body, "Markets flat."), "Markets flat.\n\n"+builtin.ReplyMarker(agentRequestToken); got != want {
+		t.Errorf("tagAgentReply = %q, want %q", got, want)
+	}
+	if got := tagAgentReply("Hey test-agent, any news?", "Markets flat."); got != "Markets flat." {
+		t.Errorf("untagged request: reply = %q, want it unchanged", got)
+	}
+}
+
+func TestHandleMessage_StripsAgentRequestMarker(t *testing.T) {
+	prov := newCapturingLLM("Markets flat.")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+
+	body := "Hey test-agent, any news?\n\n" + builtin.RequestMarker(agentRequestToken)
+	a.handleMessage(context.Background(), makeMessageEvent("!chat-room:example.com", "@user:example.com", "$evt-req", body))
+
+	req, ok := prov.waitForCall(3 * time.Second)
+	if !ok {
+		t.Fatal("expected a turn for the peer's request")
+	}
+	last := req.Messages[len(req.Messages)-1].Content
+	if strings.Contains(last, "agent-request") {
+		t.Errorf("user message %q still carries the request marker", last)
+	}
+	if !strings.Contains(last, "any news?") {
+		t.Errorf("user message = %q, want the request text", last)
+	}
+}
+
+const agentRequestGosutoYAML = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms:
+    - "!chat-room:example.com"
+    - "!kumo-room:example.com"
+  allowedSenders:
+    - "@user:example.com"
+    - "@kumo:example.com"
+    - "@mallory:example.com"
+messaging:
+  allowedTargets:
+    - roomId: "!kumo-room:example.com"
+      alias: kumo
+  maxMessagesPerMinute: 10
+instructions:
+  context:
+    peers:
+      - name: kumo
+        role: "News agent."
+        mxid: "@kumo:example.com"
+persona:
+  llmProvider: openai
+  model: gpt-4o-mini
+  systemPrompt: "You are a helpful test agent."
+`
+
+// requestCapture is a MatrixSender that hands each sent message to sent.
+type requestCapture struct{ sent chan string }
+
+func (r *requestCapture) SendText(_, text string) error {
+	r.sent <- text
+	return nil
+}
+
+// TestOnMatrixMessage_DeliversReplyWhileWorkerBusy checks that a peer's reply
+// reaches a waiting agent.request call straight from the sync handler, with
+// no message worker running, and only when it comes from the addressed peer.
+func TestOnMatrixMessage_DeliversReplyWhileWorkerBusy(t *testing.T) {
+	a := newEventApp(t, agentRequestGosutoYAML, newCapturingLLM(""))
+	a.agentReplies = builtin.NewReplyRouter()
+	a.inbox = make(chan *event.Event, matrixInboxSize)
+
+	capture := &requestCapture{sent: make(chan string, 1)}
+	tool := builtin.NewAgentRequestTool(a.gosutoLdr, capture, a.agentReplies)
+	type result struct {
+		text string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		text, err := tool.Execute(context.Background(), map[string]interface{}{
+			"target": "kumo", "message": "Hey kumo, any news?", "timeout_seconds": float64(5),
+		})
+		done <- result{text, err}
+	}()
+
+	var token string
+	select {
+	case sent := <-capture.sent:
+		var ok bool
+		if token, ok = builtin.RequestToken(sent); !ok {
+			t.Fatalf("request %q carries no correlation token", sent)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("agent.request did not send its request")
+	}
+
+	reply := "Markets flat.\n\n" + builtin.ReplyMarker(token)
+	a.onMatrixMessage(context.Background(), makeMessageEvent("!kumo-room:example.com", "@mallory:example.com", "$spoof", reply))
+	if len(a.inbox) != 1 {
+		t.Fatalf("a reply from another sender must be queued as a normal message, inbox has %d", len(a.inbox))
+	}
+	a.onMatrixMessage(context.Background(), makeMessageEvent("!kumo-room:example.com", "@kumo:example.com", "$reply", reply))
+	if len(a.inbox) != 1 {
+		t.Errorf("the peer's reply must not be queued, inbox has %d", len(a.inbox))
+	}
+
+	select {
+	case res := <-done:
+		if res.err != nil || res.text != `Reply from "kumo": Markets flat.` {
+			t.Errorf("Execute = %q, %v", res.text, res.err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("agent.request did not receive the reply")
+	}
+}
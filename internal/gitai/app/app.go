@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -20,7 +21,10 @@ import (
 
 	"maunium.net/go/mautrix/event"
 
+	"github.com/bdobrica/Ruriko/common/debugserver"
 	commonmemory "github.com/bdobrica/Ruriko/common/memory"
+	"github.com/bdobrica/Ruriko/common/policy"
+	"github.com/bdobrica/Ruriko/common/ratelimit"
 	"github.com/bdobrica/Ruriko/common/spec/envelope"
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
 	"github.com/bdobrica/Ruriko/common/trace"
@@ -34,7 +38,6 @@ import (
 	"github.com/bdobrica/Ruriko/internal/gitai/matrix"
 	"github.com/bdobrica/Ruriko/internal/gitai/mcp"
 	"github.com/bdobrica/Ruriko/internal/gitai/observability"
-	"github.com/bdobrica/Ruriko/internal/gitai/policy"
 	"github.com/bdobrica/Ruriko/internal/gitai/secrets"
 	"github.com/bdobrica/Ruriko/internal/gitai/store"
 	"github.com/bdobrica/Ruriko/internal/gitai/supervisor"
@@ -101,6 +104,51 @@ type Config struct {
 	// disabled (dev/test mode).
 	ACPToken string
 
+	// ACPTLSCertFile and ACPTLSKeyFile, when both set, make the ACP server
+	// serve HTTPS with the given PEM certificate and key. Both empty keeps
+	// plaintext HTTP (the default for localhost sidecar setups).
+	//
+	// Environment variables: GITAI_ACP_TLS_CERT_FILE, GITAI_ACP_TLS_KEY_FILE
+	ACPTLSCertFile string
+	ACPTLSKeyFile  string
+
+	// RegisterURL, when non-empty, is Ruriko's self-registration endpoint
+	// (e.g. "http://ruriko:8080/agents/register"). On startup the agent POSTs
+	// its control URL, ACP token, version and Gosuto hash there.
+	//
+	// Environment variable: RURIKO_REGISTER_URL
+	RegisterURL string
+
+	// RegisterToken is the shared bootstrap token sent as a bearer token with
+	// the registration request.
+	//
+	// Environment variable: RURIKO_REGISTER_TOKEN
+	RegisterToken string
+
+	// SecretRefreshURL, when non-empty, is Ruriko's secret refresh endpoint
+	// (e.g. "https://ruriko.example.com/agents/secrets/refresh"). When a tool
+	// call references a missing or expired secret, the agent asks Ruriko
+	// there for fresh Kuze tokens and retries the call once.
+	//
+	// Environment variable: RURIKO_SECRET_REFRESH_URL
+	SecretRefreshURL string
+
+	// AdvertiseURL is the control URL reported during self-registration.
+	// When empty it is derived from the host name and the ACPAddr port.
+	//
+	// Environment variable: GITAI_ADVERTISE_URL
+	AdvertiseURL string
+
+	// TunnelURL, when non-empty, is Ruriko's control tunnel endpoint
+	// (e.g. "ws://ruriko:8080/agents/tunnel"). The agent keeps an outbound
+	// WebSocket open there and serves ACP requests over it, for deployments
+	// where Ruriko cannot reach the ACP port. The agent's ACP token
+	// authenticates the connection, falling back to RegisterToken when it has
+	// none. When empty, ACP is served over HTTP only.
+	//
+	// Environment variable: RURIKO_TUNNEL_URL
+	TunnelURL string
+
 	// DirectSecretPushEnabled re-enables the legacy POST /secrets/apply endpoint
 	// that sends raw (base64-encoded) secret values directly in the ACP request
 	// body.  This is DISABLED by default (production safe) — secrets must flow
@@ -110,10 +158,76 @@ type Config struct {
 	// Environment variable: FEATURE_DIRECT_SECRET_PUSH (default: false)
 	DirectSecretPushEnabled bool
 
+	// StrictEventAuth disables the localhost bypass on the ACP event ingress,
+	// so every event — including those from built-in gateways — must carry
+	// the ACP bearer token.
+	//
+	// Environment variable: FEATURE_STRICT_EVENT_AUTH (default: false)
+	StrictEventAuth bool
+
+	// SealedSecrets keeps cached secret values AES-GCM encrypted in memory
+	// under a process-ephemeral key, decrypting them only when read. It
+	// shrinks the plaintext exposure window in a memory dump at the cost of
+	// a decryption on every secret read.
+	//
+	// Environment variable: FEATURE_SEALED_SECRETS (default: false)
+	SealedSecrets bool
+
+	// DefaultMaxEventsPerMinute caps inbound gateway events when the Gosuto
+	// config leaves limits.maxEventsPerMinute unset. Configs opt out with
+	// limits.unlimitedEvents. 0 disables the default.
+	//
+	// Environment variable: GITAI_DEFAULT_MAX_EVENTS_PER_MINUTE (default: 60)
+	DefaultMaxEventsPerMinute int
+
+	// EventRateAlgorithm selects how gateway event rate limits are counted:
+	// "sliding" smooths the limit over the trailing minute; "fixed" resets
+	// per minute and can admit bursts of up to twice the limit at a reset.
+	//
+	// Environment variable: GITAI_EVENT_RATE_ALGORITHM (default: "sliding")
+	EventRateAlgorithm string
+
+	// ACPIdempotencyMaxEntries caps the responses the ACP server keeps for
+	// X-Idempotency-Key replays; the oldest is evicted when it is full.
+	//
+	// Environment variable: GITAI_ACP_IDEMPOTENCY_MAX_ENTRIES (default: 10000)
+	ACPIdempotencyMaxEntries int
+
+	// ACPMaxSecretLeases caps the leases one POST /secrets/token may carry.
+	//
+	// Environment variable: GITAI_ACP_MAX_SECRET_LEASES (default: 100)
+	ACPMaxSecretLeases int
+
 	// LogLevel is "debug", "info", "warn", or "error". Defaults to "info".
 	LogLevel string
 	// LogFormat is "text" or "json". Defaults to "text".
 	LogFormat string
+	// LogRedact scrubs well-known credential formats (API keys, tokens)
+	// from log messages and attributes. Defaults to true.
+	LogRedact bool
+	// LogSampleEvery keeps 1 in LogSampleEvery INFO lines for each message
+	// in LogSampleMessages; warnings and errors are never sampled. 0 or 1
+	// logs everything.
+	LogSampleEvery int
+	// LogSampleMessages lists the INFO messages subject to sampling.
+	// Defaults to observability.DefaultSampleMessages.
+	LogSampleMessages []string
+	// LogFile, when set, also writes logs to this file. It is rotated once
+	// it reaches LogFileMaxSizeMB (0 = never) and LogFileMaxBackups rotated
+	// files are kept.
+	LogFile           string
+	LogFileMaxSizeMB  int
+	LogFileMaxBackups int
+	// LogFileStdout keeps writing logs to stdout when LogFile is set.
+	LogFileStdout bool
+
+	// DebugPprof serves the net/http/pprof handlers on DebugPprofAddr, which
+	// must be a loopback address.
+	//
+	// Environment variables: DEBUG_PPROF (default: false),
+	// DEBUG_PPROF_ADDR (default: "127.0.0.1:6060")
+	DebugPprof     bool
+	DebugPprofAddr string
 
 	// LLMCallHardLimit is a strict upper bound on total LLM completion calls
 	// made by this agent process. 0 disables the limiter.
@@ -125,11 +239,93 @@ type Config struct {
 	// MemoryContextEnabled enables shared memory-context assembly for prompt
 	// injection (R18 prep). Disabled by default.
 	MemoryContextEnabled bool
+
+	// EventDLQEnabled records gateway events whose turn fails in a SQLite
+	// dead-letter queue, from which operators can list and retry them via
+	// ACP (/ruriko agents dlq). Disabled by default.
+	//
+	// Environment variable: GITAI_EVENT_DLQ_ENABLE (default: false)
+	EventDLQEnabled bool
+
+	// EventDLQMaxRetries caps manual retries per dead-lettered event.
+	// Values <= 0 use the default of 3.
+	//
+	// Environment variable: GITAI_EVENT_DLQ_MAX_RETRIES (default: 3)
+	EventDLQMaxRetries int
+
+	// EventDLQMaxEntries caps the dead-letter queue; the oldest entries are
+	// dropped when a new one is added. Values <= 0 use the default of 1000.
+	//
+	// Environment variable: GITAI_EVENT_DLQ_MAX_ENTRIES (default: 1000)
+	EventDLQMaxEntries int
+
+	// TurnTranscriptsEnabled stores the secret-redacted message history of
+	// every LLM turn so operators can inspect it via ACP
+	// (/ruriko agents transcript). Transcripts hold conversation content, so
+	// they are off by default.
+	//
+	// Environment variable: FEATURE_TURN_TRANSCRIPTS (default: false)
+	TurnTranscriptsEnabled bool
+
+	// TurnTranscriptRetention is the number of most recent transcripts kept.
+	// Values <= 0 use the default of 100.
+	//
+	// Environment variable: GITAI_TURN_TRANSCRIPT_RETENTION (default: 100)
+	TurnTranscriptRetention int
+
+	// ToolCallRetention is the number of most recent tool-call audit entries
+	// kept. Values <= 0 use the default of 10000.
+	//
+	// Environment variable: GITAI_TOOL_CALL_RETENTION (default: 10000)
+	ToolCallRetention int
+
+	// RoomHistoryTurns is the number of recent user/assistant exchanges per
+	// Matrix room replayed into each chat turn, giving the agent
+	// conversational continuity. The window lives in memory only and is
+	// separate from the memory subsystem. 0 disables it.
+	//
+	// Environment variable: GITAI_ROOM_HISTORY_TURNS (default: 0)
+	RoomHistoryTurns int
+
+	// RoomHistoryMaxTokens caps the estimated tokens of a room's window;
+	// the oldest exchanges are dropped first. Values <= 0 use the default
+	// of 2000.
+	//
+	// Environment variable: GITAI_ROOM_HISTORY_MAX_TOKENS (default: 2000)
+	RoomHistoryMaxTokens int
+
+	// StreamReplies streams chat answers into Matrix as they are generated:
+	// the reply is posted with the first text and edited (m.replace) about
+	// once a second. It only takes effect when the LLM provider supports
+	// streaming; otherwise the full answer is sent at the end of the turn.
+	//
+	// Environment variable: GITAI_STREAM_REPLIES (default: true)
+	StreamReplies bool
+
+	// EventQueueSize bounds the number of gateway events waiting for a turn.
+	// When the queue is full, POST /events/{source} answers 503 so the
+	// gateway backs off. Values <= 0 use the default of 64.
+	//
+	// Environment variable: GITAI_EVENT_QUEUE_SIZE (default: 64)
+	EventQueueSize int
+
+	// EventWorkers is the number of event turns run concurrently.
+	// Values <= 0 use the default of 4.
+	//
+	// Environment variable: GITAI_EVENT_WORKERS (default: 4)
+	EventWorkers int
+
+	// MCPShutdownGrace is how long a stopping MCP server may take to exit
+	// after SIGTERM before it is killed, on reconcile and on agent shutdown.
+	// Values <= 0 use the default of 5s.
+	//
+	// Environment variable: GITAI_MCP_SHUTDOWN_GRACE (default: 5s)
+	MCPShutdownGrace time.Duration
 }
 
 // LLMConfig configures the language model backend.
 type LLMConfig struct {
-	// Provider is the LLM backend to use. Currently only "openai" is supported.
+	// Provider is the LLM backend to use: "openai", "anthropic" or "gemini".
 	Provider string
 	// APIKey is the API key (may come from a secret pushed by Ruriko).
 	APIKey string
@@ -155,43 +351,120 @@ type App struct {
 	// eventSender is used by runEventTurn to post gateway-event responses to
 	// Matrix.  It defaults to matrixCli in New() and can be overridden in tests.
 	eventSender eventMatrixSender
+	// replyStream posts and edits streamed chat replies. It is matrixCli
+	// when Config.StreamReplies is set and nil otherwise.
+	replyStream replyStreamer
 	llmProvMu   sync.RWMutex // guards llmProv
 	llmProv     llm.Provider
 	matrixCli   *matrix.Client
 	approvalGt  approvalGate
 	acpServer   *control.Server
-	startedAt   time.Time
-	restartCh   chan struct{}
+	acpTokens   *control.TokenSet
+	// pprofServer is the loopback profiling server; nil unless DebugPprof.
+	pprofServer *debugserver.Server
+	// secretRefresh throttles requests to Ruriko for fresh secret leases.
+	secretRefresh secretRefresher
+	// startup holds turns until the gosuto startupProbe passes; nil when
+	// the probe is not configured. Set in Run before any traffic arrives
+	// and extended by config applies that add MCP servers or required
+	// secrets; see waitStartup.
+	startup   atomic.Pointer[startupGate]
+	startedAt time.Time
+	// restarts and firstStartedAt are the start history recorded in the
+	// store, reported on /status.
+	restarts       int
+	firstStartedAt time.Time
+	restartCh      chan struct{}
 	// cancelCh is signalled when Ruriko sends a POST /tasks/cancel request.
 	// The currently running turn should watch this channel and abort early.
 	cancelCh chan struct{}
 	// builtinReg holds the registry of non-MCP built-in tools exposed to the
 	// LLM. Currently contains matrix.send_message (R15.2).
 	builtinReg *builtin.Registry
+	// agentReplies routes peer replies to pending agent.request calls.
+	agentReplies *builtin.ReplyRouter
+	// inbox queues Matrix messages for the message worker (see inbox.go).
+	inbox chan *event.Event
+	// toolSchemas caches the compiled input schemas of MCP tools so that
+	// arguments are validated before dispatch.
+	toolSchemas toolSchemaCache
 	// msgOutbound counts the total number of successful matrix.send_message
 	// calls made by this agent (R15.5 audit/observability).
 	msgOutbound atomic.Int64
 	// llmCalls counts the total number of LLM completion calls made by this
 	// process. Used by the hard limit kill-switch.
 	llmCalls atomic.Int64
+	// metrics holds the counters exposed by ACP GET /metrics.
+	metrics appMetrics
 	// terminateProcess exits the current process; defaults to os.Exit.
 	terminateProcess func(code int)
 	memorySTM        *gitaiMemorySTM
 	memoryAssembler  *commonmemory.ContextAssembler
 	workflowEngine   *workflow.Engine
 	workflowEngineMu sync.Once
+	// roomHist replays recent exchanges into Matrix chat turns; nil when
+	// Config.RoomHistoryTurns is 0.
+	roomHist *roomHistory
+	// eventQ buffers inbound gateway events between ACP ingress and the
+	// turn workers; created on first use by events().
+	eventQ     *eventQueue
+	eventQOnce sync.Once
+	// lastApply records the outcome of the most recent ACP config apply.
+	lastApply atomic.Pointer[control.ConfigApplyStatus]
+	// startupFailures lists what failed to start at boot; see
+	// reconcileAtStartup.
+	startupFailures atomic.Pointer[[]string]
+	// canary is the config under test in a single room, if any.
+	canary atomic.Pointer[canaryConfig]
+	// turns caps concurrent Matrix and event turns at the Gosuto
+	// limits.maxConcurrentRequests.
+	turns turnLimiter
+	// clock, when set, replaces time.Now for LLM spend accounting.
+	clock func() time.Time
+	// senderRL counts Matrix messages per sender against the Gosuto
+	// limits.maxRequestsPerMinute; the limit is passed on every check, so a
+	// config apply takes effect on the next message. senderRLNotice allows
+	// one rate-limit reply per sender per minute.
+	senderRL       *ratelimit.KeyedFixedWindow
+	senderRLNotice *ratelimit.KeyedFixedWindow
+	// onStartupHash is the hash of the config instructions.onStartup last
+	// completed for, and onStartupPending the one whose turn is queued or
+	// running; see runOnStartup.
+	onStartupMu      sync.Mutex
+	onStartupHash    string
+	onStartupPending string
 }
 
 // New creates and initialises all Gitai subsystems. It does NOT start any
 // goroutines; call Run() for that.
 func New(cfg *Config) (*App, error) {
-	observability.Setup(cfg.LogLevel, cfg.LogFormat)
+	if err := observability.Setup(cfg.LogLevel, cfg.LogFormat, observability.Options{
+		RedactSecrets:  cfg.LogRedact,
+		SampleEvery:    cfg.LogSampleEvery,
+		SampleMessages: cfg.LogSampleMessages,
+		File:           cfg.LogFile,
+		FileMaxBytes:   int64(cfg.LogFileMaxSizeMB) << 20,
+		FileMaxBackups: cfg.LogFileMaxBackups,
+		FileOnly:       !cfg.LogFileStdout,
+	}); err != nil {
+		return nil, fmt.Errorf("set up logging: %w", err)
+	}
+
+	var pprofServer *debugserver.Server
+	if cfg.DebugPprof {
+		var err error
+		if pprofServer, err = debugserver.New(cfg.DebugPprofAddr); err != nil {
+			return nil, fmt.Errorf("DEBUG_PPROF: %w", err)
+		}
+	}
 
 	db, err := store.New(cfg.DatabasePath)
 	if err != nil {
 		return nil, fmt.Errorf("open store: %w", err)
 	}
 
+	restarts, firstStartedAt := recordStartup(db, time.Now())
+
 	gosutoLdr := gosuto.New()
 
 	// Load initial config from file (or previously applied config in DB).
@@ -212,9 +485,18 @@ func New(cfg *Config) (*App, error) {
 	}
 
 	secStore := secrets.New()
+	if cfg.SealedSecrets {
+		secStore, err = secrets.NewSealed()
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("init sealed secrets: %w", err)
+		}
+		slog.Info("secrets: sealing cached values in memory")
+	}
 	secMgr := secrets.NewManager(secStore, 0) // uses DefaultCacheTTL (4 h)
 	policyEng := policy.New(gosutoLdr)
 	supv := supervisor.New()
+	supv.SetShutdownGrace(cfg.MCPShutdownGrace)
 
 	// Build LLM provider.
 	llmProv := buildLLMProvider(cfg.LLM)
@@ -231,10 +513,17 @@ func New(cfg *Config) (*App, error) {
 
 	// Built-in tool registry — populate before any turn can run.
 	builtinReg := builtin.New()
+	agentReplies := builtin.NewReplyRouter()
 	builtinReg.Register(builtin.NewMatrixSendTool(gosutoLdr, matrixCli))
+	builtinReg.Register(builtin.NewAgentRequestTool(gosutoLdr, matrixCli, agentReplies))
+	builtinReg.Register(builtin.NewMatrixSendFileTool(gosutoLdr, matrixCli, nil))
+	builtinReg.Register(builtin.NewNotifyOperatorTool(gosutoLdr, matrixCli))
 	builtinReg.Register(builtin.NewScheduleUpsertTool(db))
 	builtinReg.Register(builtin.NewScheduleDisableTool(db))
 	builtinReg.Register(builtin.NewScheduleListTool(db))
+	builtinReg.Register(builtin.NewResourceListTool(supervisorResources(supv)))
+	builtinReg.Register(builtin.NewResourceReadTool(supervisorResources(supv)))
+	builtinReg.Register(builtin.NewEventAttachmentTool(nil))
 
 	app := &App{
 		cfg:              cfg,
@@ -248,12 +537,26 @@ func New(cfg *Config) (*App, error) {
 		matrixCli:        matrixCli,
 		eventSender:      matrixCli,
 		startedAt:        time.Now(),
+		restarts:         restarts,
+		firstStartedAt:   firstStartedAt,
 		restartCh:        restartCh,
 		cancelCh:         cancelCh,
+		pprofServer:      pprofServer,
 		builtinReg:       builtinReg,
+		agentReplies:     agentReplies,
+		inbox:            make(chan *event.Event, matrixInboxSize),
 		terminateProcess: os.Exit,
+		roomHist:         newRoomHistory(cfg.RoomHistoryTurns, cfg.RoomHistoryMaxTokens),
+		senderRL:         ratelimit.NewKeyedFixedWindow(time.Minute),
+		senderRLNotice:   ratelimit.NewKeyedFixedWindow(time.Minute),
 	}
 
+	if cfg.StreamReplies {
+		app.replyStream = matrixCli
+	}
+
+	app.applyFeatures()
+
 	if cfg.MemoryContextEnabled {
 		app.memorySTM = newGitaiMemorySTM(50)
 		app.memoryAssembler = &commonmemory.ContextAssembler{
@@ -274,8 +577,20 @@ func New(cfg *Config) (*App, error) {
 	if acpAddr == "" {
 		acpAddr = ":8765"
 	}
+	// Built-in and external gateways reach the ACP event ingress on
+	// localhost, over TLS when the ACP server serves it.
+	if (cfg.ACPTLSCertFile == "") != (cfg.ACPTLSKeyFile == "") {
+		db.Close()
+		return nil, fmt.Errorf("acp tls: GITAI_ACP_TLS_CERT_FILE and GITAI_ACP_TLS_KEY_FILE must be set together")
+	}
+	acpLocalURL := gateway.ACPBaseURL(acpAddr)
+	if cfg.ACPTLSCertFile != "" {
+		acpLocalURL = gateway.ACPBaseURLTLS(acpAddr)
+	}
+	app.acpTokens = control.NewTokenSet(effectiveACPToken(db, cfg.ACPToken))
 	// Cron gateway manager: connects to the ACP event ingress on localhost.
-	cronMgr := gateway.NewManager(gateway.ACPBaseURL(acpAddr))
+	cronMgr := gateway.NewManager(acpLocalURL)
+	cronMgr.SetACPToken(app.acpTokens.Current)
 	app.cronMgr = cronMgr
 	cronMgr.EnableDBSchedules(db, func(ctx context.Context, gatewayName, tool string, args map[string]interface{}) error {
 		traceCtx := trace.WithTraceID(ctx, trace.GenerateID())
@@ -288,49 +603,51 @@ func New(cfg *Config) (*App, error) {
 		return err
 	})
 	// External gateway supervisor: manages external gateway binaries (Command set in Gosuto).
-	extGWSupv := supervisor.NewExternalGatewaySupervisor(gateway.ACPBaseURL(acpAddr))
+	extGWSupv := supervisor.NewExternalGatewaySupervisor(acpLocalURL)
 	app.extGWSupv = extGWSupv
 	app.acpServer = control.New(acpAddr, control.Handlers{
-		AgentID:                 cfg.AgentID,
-		Version:                 version.Version,
-		StartedAt:               app.startedAt,
-		Token:                   cfg.ACPToken,
-		DirectSecretPushEnabled: cfg.DirectSecretPushEnabled,
-		GosutoHash:              gosutoLdr.Hash,
-		MCPNames:                supv.Names,
-		ActiveConfig:            gosutoLdr.Config,
+		AgentID:                   cfg.AgentID,
+		Version:                   version.Version,
+		StartedAt:                 app.startedAt,
+		Restarts:                  app.restarts,
+		FirstStartedAt:            app.firstStartedAt,
+		Tokens:                    app.acpTokens,
+		PersistToken:              app.persistACPToken,
+		SaveIdempotency:           app.saveIdempotency,
+		LoadIdempotency:           app.loadIdempotency,
+		TLSCertFile:               cfg.ACPTLSCertFile,
+		TLSKeyFile:                cfg.ACPTLSKeyFile,
+		DirectSecretPushEnabled:   cfg.DirectSecretPushEnabled,
+		DisableLocalhostBypass:    cfg.StrictEventAuth,
+		DefaultMaxEventsPerMinute: cfg.DefaultMaxEventsPerMinute,
+		EventRateAlgorithm:        cfg.EventRateAlgorithm,
+		IdempotencyMaxEntries:     cfg.ACPIdempotencyMaxEntries,
+		MaxSecretLeases:           cfg.ACPMaxSecretLeases,
+		GosutoHash:                gosutoLdr.Hash,
+		MCPNames:                  supv.Names,
+		MCPsInitializing:          supv.Initializing,
+		MCPStatus:                 supervisorStatus(supv),
+		ActiveConfig:              gosutoLdr.Config,
+		ConfigYAML:                gosutoLdr.YAML,
 		// R15.5: expose outbound message count in the ACP /status response.
 		MessagesOutbound: func() int64 { return app.msgOutbound.Load() },
+		Metrics:          app.metrics.snapshot,
 		// GetSecret looks up an agent secret by ref name. Used by the
 		// built-in webhook gateway to validate HMAC-SHA256 signatures.
 		GetSecret: func(ref string) ([]byte, error) {
 			return secStore.Get(ref)
 		},
-		ApplyConfig: func(yaml, hash string) error {
-			if err := gosutoLdr.Apply([]byte(yaml)); err != nil {
-				return err
-			}
-			// Persist to DB for restart recovery.
-			_ = db.SaveAppliedConfig(hash, yaml)
-			// Reconcile MCP servers, cron gateways, and external gateway processes.
-			if c := gosutoLdr.Config(); c != nil {
-				supv.Reconcile(c.MCPs)
-				cronMgr.Reconcile(c.Gateways)
-				extGWSupv.Reconcile(c.Gateways)
-				if app.matrixCli != nil {
-					app.matrixCli.EnsureJoinedRooms(roomsFromConfig(c))
-				}
-			}
-			// Rebuild the LLM provider in case the new Gosuto specifies a
-			// different APIKeySecretRef or model, and the matching secret is
-			// already cached in the secret manager.
-			app.rebuildLLMProvider()
-			return nil
-		},
-		ApplySecrets: func(sec map[string]string) error {
+		ApplyConfig:     app.applyConfig,
+		LastConfigApply: app.lastConfigApply,
+		StartupFailures: app.startupFailureList,
+		ApplyCanary:     app.applyCanary,
+		AbortCanary:     app.abortCanary,
+		Canary:          app.canaryStatus,
+		MonthlySpend:    app.monthlySpend,
+		ApplySecrets: func(sec map[string]string, ttls map[string]time.Duration) error {
 			// Route through the Manager so TTL entries are recorded.
-			// Manager.Apply calls secStore.Apply internally.
-			if err := secMgr.Apply(sec, 0); err != nil {
+			// Manager.ApplyWithTTLs calls secStore.Apply internally.
+			if err := secMgr.ApplyWithTTLs(sec, ttls); err != nil {
 				return err
 			}
 			// Re-inject secret env into MCP supervisor and external gateway supervisor
@@ -372,9 +689,19 @@ func New(cfg *Config) (*App, error) {
 		},
 		// HandleEvent dispatches inbound gateway events to the turn engine.
 		// The method must be non-blocking; the actual work runs in a goroutine.
-		HandleEvent: func(ctx context.Context, evt *envelope.Event) {
-			app.handleEvent(ctx, evt)
+		HandleEvent: func(ctx context.Context, evt *envelope.Event) error {
+			return app.handleEvent(ctx, evt)
+		},
+		EventQueueStats: func() (int, int) {
+			return app.events().stats()
 		},
+		ListTools:       app.listTools,
+		ListToolCalls:   app.listToolCalls,
+		ListTurns:       app.listTurns,
+		Broadcast:       app.broadcast,
+		ListDeadLetters: app.listDeadLetters,
+		RetryDeadLetter: app.retryDeadLetter,
+		TurnTranscript:  app.turnTranscript,
 	})
 
 	return app, nil
@@ -385,16 +712,28 @@ func (a *App) Run() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Close the startup gate before the ACP server or Matrix can deliver
+	// traffic; the probe and secrets preflight below open it once the MCP
+	// servers are ready and the required secrets have arrived.
+	gate := newStartupGate(a.gosutoLdr.Config())
+	a.startup.Store(gate)
+
 	// Start ACP server.
 	if err := a.acpServer.Start(ctx); err != nil {
 		return fmt.Errorf("start acp server: %w", err)
 	}
+	if a.pprofServer != nil {
+		if err := a.pprofServer.Start(ctx); err != nil {
+			slog.Warn("pprof server failed to start; continuing without it", "err", err)
+		}
+	}
 
 	// Start MCP supervisor, cron gateways, and external gateway processes.
 	if c := a.gosutoLdr.Config(); c != nil {
-		a.supv.Reconcile(c.MCPs)
-		a.cronMgr.Reconcile(c.Gateways)
-		a.extGWSupv.Reconcile(c.Gateways)
+		a.reconcileAtStartup(c)
+		a.turns.setLimit(c.Limits.MaxConcurrentRequests)
+		a.runOnStartup(a.gosutoLdr.Hash())
+		a.startStartupChecks(ctx, c, gate)
 	}
 
 	// Start Matrix sync.
@@ -402,7 +741,8 @@ func (a *App) Run() error {
 	if c := a.gosutoLdr.Config(); c != nil {
 		rooms = roomsFromConfig(c)
 	}
-	if err := a.matrixCli.Start(ctx, rooms, a.handleMessage); err != nil {
+	go a.runInbox(ctx)
+	if err := a.matrixCli.Start(ctx, rooms, a.onMatrixMessage); err != nil {
 		return fmt.Errorf("start matrix: %w", err)
 	}
 
@@ -411,6 +751,19 @@ func (a *App) Run() error {
 		"version", version.Version,
 	)
 
+	// Self-register with Ruriko (opt-in) now that the ACP server is reachable.
+	go a.registerWithRuriko(ctx)
+
+	// Optional outbound control tunnel for agents behind NAT.
+	if a.cfg.TunnelURL != "" {
+		go a.acpServer.RunTunnel(ctx, control.TunnelConfig{
+			URL:      a.cfg.TunnelURL,
+			Token:    a.cfg.RegisterToken,
+			ACPToken: a.acpTokens.Current,
+			AgentID:  a.cfg.AgentID,
+		})
+	}
+
 	// Start secret eviction goroutine — sweeps expired cached secrets every
 	// minute to reduce the in-memory credential exposure window.
 	go func() {
@@ -451,6 +804,9 @@ func (a *App) Stop() {
 	a.cronMgr.Stop()
 	a.extGWSupv.Stop()
 	a.acpServer.Stop()
+	if a.pprofServer != nil {
+		a.pprofServer.Stop()
+	}
 	a.db.Close()
 }
 
@@ -489,12 +845,30 @@ func (a *App) setProvider(p llm.Provider) {
 	a.llmProvMu.Unlock()
 }
 
-// handleMessage is called by the Matrix client for every incoming text message.
+// disabledByConfig reports whether the applied Gosuto config sets
+// metadata.enabled: false. Without a config the agent is not disabled.
+func (a *App) disabledByConfig() bool {
+	cfg := a.gosutoLdr.Config()
+	return cfg != nil && !cfg.Metadata.IsEnabled()
+}
+
+// isSelf reports whether sender is this agent's own Matrix user ID.
+func (a *App) isSelf(sender string) bool {
+	return a.cfg != nil && a.cfg.Matrix.UserID != "" && sender == a.cfg.Matrix.UserID
+}
+
+// handleMessage handles every incoming text message, in arrival order, on
+// the message worker started by Run (see runInbox).
 func (a *App) handleMessage(ctx context.Context, evt *event.Event) {
 	msgContent := evt.Content.AsMessage()
 	if msgContent == nil {
 		return
 	}
+	// Edits are not new messages; this also keeps other agents from
+	// reacting to every step of a streamed reply.
+	if msgContent.RelatesTo.GetReplaceID() != "" {
+		return
+	}
 
 	// Skip stale messages that were sent before this agent started.
 	// During initial sync the homeserver replays recent timeline events;
@@ -514,17 +888,48 @@ func (a *App) handleMessage(ctx context.Context, evt *event.Event) {
 	roomID := evt.RoomID.String()
 	sender := evt.Sender.String()
 
+	if a.disabledByConfig() {
+		slog.Info("agent disabled by config; ignoring message", "room", roomID, "sender", sender)
+		return
+	}
+
+	// Turns in the canary room run under the canary config.
+	if c := a.canaryFor(roomID); c != nil {
+		ctx = withCanary(ctx, c)
+	}
+
+	// Never react to our own messages or to configured bot accounts, so
+	// agents sharing a room cannot trigger each other in a loop.
+	if a.isSelf(sender) {
+		slog.Debug("message from self; ignoring", "room", roomID)
+		return
+	}
+	if a.policyFor(ctx).IsSenderIgnored(sender) {
+		slog.Debug("message from ignored sender; ignoring", "room", roomID, "sender", sender)
+		return
+	}
+
+	// The request marker is only for tagging our reply; keep it out of
+	// protocol matching and the prompt.
+	text = builtin.StripRequestMarker(text)
+
 	// --- Policy: check room and sender ---
-	if !a.policyEng.IsRoomAllowed(roomID) {
+	if !a.policyFor(ctx).IsRoomAllowed(roomID) {
 		slog.Debug("message from disallowed room; ignoring", "room", roomID)
 		return
 	}
-	if !a.policyEng.IsSenderAllowed(sender) {
+	if !a.policyFor(ctx).IsSenderAllowed(sender) {
 		slog.Debug("message from disallowed sender; ignoring", "sender", sender)
 		return
 	}
 
-	cfg := a.gosutoLdr.Config()
+	// Hold the turn until the startup probe passes so early tool calls do
+	// not fail against MCP servers that are still starting.
+	if !a.waitStartup(ctx) {
+		return
+	}
+
+	cfg := a.configFor(ctx)
 	directedToSelf := false
 	var protocolMatch *workflow.InboundProtocolMatch
 	if cfg != nil {
@@ -573,7 +978,7 @@ func (a *App) handleMessage(ctx context.Context, evt *event.Event) {
 		protocolMatch = match
 	}
 
-	if protocolMatch == nil && !directedToSelf {
+	if protocolMatch == nil && !directedToSelf && !a.acceptsFreeForm(cfg, msgContent) {
 		slog.Debug("ignoring undirected non-protocol message",
 			"room", roomID,
 			"sender", sender,
@@ -581,11 +986,38 @@ func (a *App) handleMessage(ctx context.Context, evt *event.Event) {
 		return
 	}
 
+	if !a.senderRL.Allow(cfg.Limits.MaxRequestsPerMinute, sender) {
+		slog.Warn("message dropped: sender rate limit reached",
+			"room", roomID,
+			"sender", sender,
+			"max_per_minute", cfg.Limits.MaxRequestsPerMinute,
+		)
+		a.metrics.senderRateLimited.Add(1)
+		if a.senderRLNotice.Allow(1, sender) && a.matrixCli != nil {
+			_ = a.matrixCli.SendReply(roomID, evt.ID.String(), tagAgentReply(msgContent.Body, rateLimitReply))
+		}
+		return
+	}
+	if !a.turns.tryAcquire() {
+		slog.Warn("message dropped: concurrent turn limit reached",
+			"room", roomID,
+			"sender", sender,
+			"max_concurrent", cfg.Limits.MaxConcurrentRequests,
+		)
+		if a.matrixCli != nil {
+			_ = a.matrixCli.SendReply(roomID, evt.ID.String(), tagAgentReply(msgContent.Body, busyReply))
+		}
+		return
+	}
+	defer a.turns.release()
+
 	// Generate trace ID for this turn.
 	traceID := trace.GenerateID()
 	ctx = trace.WithTraceID(ctx, traceID)
 	log := observability.WithTrace(ctx)
 
+	a.metrics.turns.Add(1)
+	turnStart := time.Now()
 	turnID, err := a.db.LogTurn(traceID, roomID, sender, text)
 	if err != nil {
 		log.Warn("could not log turn", "err", err)
@@ -594,30 +1026,63 @@ func (a *App) handleMessage(ctx context.Context, evt *event.Event) {
 	var (
 		result    string
 		toolCalls int
+		reply     *streamedReply
 	)
 	if protocolMatch != nil && len(protocolMatch.Protocol.Steps) > 0 {
 		result, toolCalls, err = a.runWorkflowTurn(ctx, roomID, sender, protocolMatch)
 	} else {
-		result, toolCalls, err = a.runTurn(ctx, roomID, sender, text, evt.ID.String())
+		turnCtx := ctx
+		// Peer agents, and a peer's agent.request in particular, get one
+		// complete reply, not a stream of edits they would not read.
+		_, peerRequest := builtin.RequestToken(msgContent.Body)
+		if a.replyStream != nil && !peerRequest && !isKnownAgent(cfg, sender) {
+			reply = newStreamedReply(a.replyStream, roomID, evt.ID.String())
+			turnCtx = withReplyStream(ctx, reply)
+		}
+		result, toolCalls, err = a.runTurn(turnCtx, roomID, sender, text, evt.ID.String())
 	}
 	if err != nil {
 		log.Error("turn failed", "err", err)
-		if a.matrixCli != nil && shouldSendTurnErrorReply(cfg, sender, err) {
-			_ = a.matrixCli.SendReply(roomID, evt.ID.String(), fmt.Sprintf("❌ %s", err))
+		if shouldSendTurnErrorReply(cfg, sender, err) {
+			switch {
+			case reply.posted():
+				_ = reply.finish(fmt.Sprintf("%s\n\n❌ %s", reply.shown, err))
+			case a.matrixCli != nil:
+				_ = a.matrixCli.SendReply(roomID, evt.ID.String(), tagAgentReply(msgContent.Body, fmt.Sprintf("❌ %s", err)))
+			}
 		}
 		if turnID > 0 {
-			_ = a.db.FinishTurn(turnID, toolCalls, "error", err.Error())
+			_ = a.db.FinishTurnWithDuration(turnID, toolCalls, time.Since(turnStart).Milliseconds(), "error", err.Error())
 		}
 		return
 	}
-	if result != "" && a.matrixCli != nil {
-		if err := a.matrixCli.SendReply(roomID, evt.ID.String(), result); err != nil {
-			log.Error("could not send reply", "err", err)
+	if result == "" && reply.posted() {
+		result = emptyStreamedReply
+	}
+	if result != "" {
+		switch {
+		case reply.posted():
+			if err := reply.finish(tagAgentReply(msgContent.Body, result)); err != nil {
+				log.Error("could not finish streamed reply", "err", err)
+			}
+		case a.matrixCli != nil:
+			if err := a.matrixCli.SendReply(roomID, evt.ID.String(), tagAgentReply(msgContent.Body, result)); err != nil {
+				log.Error("could not send reply", "err", err)
+			}
 		}
 	}
 	if turnID > 0 {
-		_ = a.db.FinishTurn(turnID, toolCalls, "success", "")
+		_ = a.db.FinishTurnWithDuration(turnID, toolCalls, time.Since(turnStart).Milliseconds(), "success", "")
+	}
+}
+
+// tagAgentReply appends the agent.request reply marker to reply when the
+// inbound body was a peer's agent.request, so the peer can correlate it.
+func tagAgentReply(body, reply string) string {
+	if token, ok := builtin.RequestToken(body); ok {
+		return reply + "\n\n" + builtin.ReplyMarker(token)
 	}
+	return reply
 }
 
 func parseDirectedAgentMessage(text string) (target, body string, ok bool) {
@@ -713,7 +1178,7 @@ func shouldSendTurnErrorReply(cfg *gosutospec.Config, sender string, err error)
 
 // runTurn executes the full turn loop: prompt → LLM → tool calls → response.
 func (a *App) runTurn(ctx context.Context, roomID, sender, userText, replyToEventID string) (string, int, error) {
-	cfg := a.gosutoLdr.Config()
+	cfg := a.configFor(ctx)
 	if cfg == nil {
 		return "", 0, fmt.Errorf("no Gosuto config loaded; cannot process messages")
 	}
@@ -722,8 +1187,9 @@ func (a *App) runTurn(ctx context.Context, roomID, sender, userText, replyToEven
 		return "", 0, fmt.Errorf("LLM provider not configured")
 	}
 
-	// Build messaging targets summary for the system prompt (R15.2).
-	messagingTargets := buildMessagingTargets(cfg)
+	// Build messaging targets summary for the system prompt (R15.2). The
+	// send allowlist is the live config's, even for canary turns.
+	messagingTargets := buildMessagingTargets(a.gosutoLdr.Config())
 	memoryContext := ""
 	if a.memorySTM != nil {
 		a.memorySTM.RecordMessage(roomID, sender, "user", userText)
@@ -748,10 +1214,18 @@ func (a *App) runTurn(ctx context.Context, roomID, sender, userText, replyToEven
 	}
 	toolDefsForLLM, llmToolNameMap := normalizeToolDefinitionsForProvider(providerName, toolDefs)
 
-	// Initial message history.
-	messages := []llm.Message{
-		{Role: llm.RoleSystem, Content: systemPrompt},
-		{Role: llm.RoleUser, Content: userText},
+	// Initial message history. Matrix chat turns (those replying to an
+	// event) first replay the room's recent exchanges; gateway event turns
+	// stand alone.
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: systemPrompt}}
+	chatTurn := replyToEventID != ""
+	if chatTurn {
+		messages = append(messages, a.roomHist.messages(roomID)...)
+	}
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: userText})
+	if a.transcriptsEnabled() {
+		// Record whatever history the turn reached, including on failure.
+		defer func() { a.recordTranscript(trace.FromContext(ctx), messages) }()
 	}
 
 	totalToolCalls := 0
@@ -760,19 +1234,44 @@ func (a *App) runTurn(ctx context.Context, roomID, sender, userText, replyToEven
 		maxTokens = cfg.Limits.MaxTokensPerRequest
 	}
 
+	// Chat turns with a reply stream use the streaming API when the
+	// provider has one; a streamed turn can be aborted by POST /tasks/cancel.
+	streamProv, canStream := prov.(llm.StreamingProvider)
+	reply := replyStreamFromContext(ctx)
+	streaming := canStream && reply != nil
+	if streaming {
+		var stop func()
+		ctx, stop = a.watchCancel(ctx)
+		defer stop()
+	}
+
 	for round := 0; round < maxToolCallRounds; round++ {
 		if err := a.enforceLLMCallHardLimit(); err != nil {
 			return "", totalToolCalls, err
 		}
-		resp, err := prov.Complete(ctx, llm.CompletionRequest{
+		if err := a.checkBudget(cfg); err != nil {
+			return "", totalToolCalls, err
+		}
+		req := llm.CompletionRequest{
 			Model:     "",
 			Messages:  messages,
 			Tools:     toolDefsForLLM,
 			MaxTokens: maxTokens,
-		})
+		}
+		var resp *llm.CompletionResponse
+		var err error
+		if streaming {
+			resp, err = completeStreamed(ctx, streamProv, req, reply)
+		} else {
+			resp, err = prov.Complete(ctx, req)
+		}
 		if err != nil {
+			if errors.Is(context.Cause(ctx), errTurnCancelled) {
+				return "", totalToolCalls, errTurnCancelled
+			}
 			return "", totalToolCalls, fmt.Errorf("LLM call failed: %w", err)
 		}
+		a.recordSpend(a.llmModel(cfg), resp.Usage)
 
 		// Append assistant message to history.
 		messages = append(messages, resp.Message)
@@ -782,9 +1281,19 @@ func (a *App) runTurn(ctx context.Context, roomID, sender, userText, replyToEven
 			if a.memorySTM != nil && resp.Message.Content != "" {
 				a.memorySTM.RecordMessage(roomID, sender, "assistant", resp.Message.Content)
 			}
+			if chatTurn && resp.Message.Content != "" {
+				a.roomHist.record(roomID, userText, resp.Message.Content)
+			}
 			return resp.Message.Content, totalToolCalls, nil
 		}
 
+		// Enforce the per-turn tool-call budget before running any call of
+		// this round, so a runaway model cannot fan out past it.
+		if limit := cfg.Limits.MaxToolCallsPerTurn; limit > 0 && totalToolCalls+len(resp.Message.ToolCalls) > limit {
+			return "", totalToolCalls, fmt.Errorf("exceeded maximum tool calls per turn (%d): model requested %d more after %d",
+				limit, len(resp.Message.ToolCalls), totalToolCalls)
+		}
+
 		// Process tool calls.
 		for _, tc := range resp.Message.ToolCalls {
 			if canonical, ok := llmToolNameMap[tc.Function.Name]; ok {
@@ -834,7 +1343,9 @@ func (a *App) enforceLLMCallHardLimit() error {
 var nonOpenAIToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
 
 func normalizeToolDefinitionsForProvider(provider string, defs []llm.ToolDefinition) ([]llm.ToolDefinition, map[string]string) {
-	if strings.ToLower(strings.TrimSpace(provider)) != "openai" {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "openai", "anthropic", "gemini":
+	default:
 		return defs, map[string]string{}
 	}
 
@@ -890,9 +1401,21 @@ func (a *App) executeToolCall(ctx context.Context, roomID, sender string, tc llm
 
 // DispatchToolCall is the single deterministic tool execution boundary used by
 // both LLM and non-LLM execution paths (workflow, gateway, deterministic flows).
-func (a *App) DispatchToolCall(ctx context.Context, req ToolDispatchRequest) (string, error) {
+// Every call, whatever its outcome, is appended to the tool-call audit trail.
+func (a *App) DispatchToolCall(ctx context.Context, req ToolDispatchRequest) (_ string, err error) {
 	log := observability.WithTrace(ctx)
 
+	rec := store.ToolCall{
+		TraceID:  trace.FromContext(ctx),
+		Caller:   req.Caller,
+		Tool:     req.Name,
+		Decision: toolCallRejected,
+		ArgKeys:  argKeys(req.Args),
+	}
+	start := time.Now()
+	defer func() { a.recordToolCall(rec, start, err) }()
+	a.metrics.toolCalls.Add(1)
+
 	isBuiltin := a.builtinReg != nil && a.builtinReg.IsBuiltin(req.Name)
 	namespace := ""
 	mcpName := ""
@@ -902,6 +1425,17 @@ func (a *App) DispatchToolCall(ctx context.Context, req ToolDispatchRequest) (st
 	if isBuiltin {
 		namespace = builtin.BuiltinMCPNamespace
 		approvalAction = "builtin.call"
+		// Tools acting on an MCP's behalf (resource.read, …) are governed by
+		// that MCP's capability rules rather than the builtin namespace.
+		if isResourceTool(req.Name) && !a.features().Bool(featureResourceTools) {
+			return "", fmt.Errorf("%s is disabled by the resourceTools feature flag", req.Name)
+		}
+		if scoped, ok := a.builtinReg.Get(req.Name).(builtin.PolicyScoped); ok {
+			namespace, toolName = scoped.PolicyTarget(req.Args)
+			if strings.TrimSpace(namespace) == "" {
+				return "", fmt.Errorf("%s: missing required argument 'mcp'", req.Name)
+			}
+		}
 	} else {
 		mcpName, toolName = splitToolName(req.Name)
 		namespace = mcpName
@@ -910,7 +1444,20 @@ func (a *App) DispatchToolCall(ctx context.Context, req ToolDispatchRequest) (st
 		}
 	}
 
-	result := a.policyEng.Evaluate(namespace, toolName, req.Args)
+	// Reject arguments that violate the tool's advertised input schema before
+	// policy and approvals, so a malformed call never reaches an approver or
+	// the MCP. Secrets are not yet resolved, so the error echoed back to the
+	// LLM cannot contain plaintext values.
+	if !isBuiltin && a.features().Bool(featureToolSchemaValidation) {
+		if err := a.toolSchemas.validate(req.Name, req.Args); err != nil {
+			log.Info("tool arguments rejected by input schema", "caller", req.Caller, "tool", req.Name, "err", err)
+			return "", err
+		}
+	}
+
+	result := a.policyFor(ctx).Evaluate(namespace, toolName, req.Args)
+	rec.MCP, rec.Tool = namespace, toolName
+	rec.Decision, rec.Rule = result.Decision.String(), result.MatchedRule
 	log.Info("policy evaluation",
 		"caller", req.Caller,
 		"mcp", namespace,
@@ -921,10 +1468,17 @@ func (a *App) DispatchToolCall(ctx context.Context, req ToolDispatchRequest) (st
 
 	switch result.Decision {
 	case policy.DecisionDeny:
+		a.metrics.policyDenials.Add(1)
+		// The violation message can quote argument values; the audit trail
+		// records only which rule and constraint denied the call.
+		rec.Error = "policy denied"
+		if c := result.Violation.Constraint; c != "" {
+			rec.Error += " (constraint " + c + ")"
+		}
 		return "", fmt.Errorf("policy denied: %s", result.Violation.Message)
 
 	case policy.DecisionRequireApproval:
-		cfg := a.gosutoLdr.Config()
+		cfg := a.configFor(ctx)
 		if cfg == nil || !cfg.Approvals.Enabled || cfg.Approvals.Room == "" {
 			return "", fmt.Errorf("approval required but approvals not configured")
 		}
@@ -971,19 +1525,28 @@ func (a *App) DispatchToolCall(ctx context.Context, req ToolDispatchRequest) (st
 	}
 
 	args, err := a.resolveSecretArgs(req.Args)
+	if err != nil && a.refreshSecretArgs(ctx, req.Args) {
+		args, err = a.resolveSecretArgs(req.Args)
+	}
 	if err != nil {
+		rec.Error = "secret resolution failed"
 		return "", fmt.Errorf("resolving secret args for %s.%s: %w", mcpName, toolName, err)
 	}
 
 	callResult, err := client.CallTool(ctx, toolName, args)
 	if err != nil {
+		rec.Error = mcpCallErrorClass(err)
 		return "", fmt.Errorf("tool call %s.%s: %w", mcpName, toolName, err)
 	}
 	if callResult.IsError {
-		return "", fmt.Errorf("tool %s.%s returned error: %v", mcpName, toolName, callResult.Content)
+		// Error text is shown to the LLM too, so it gets the same output
+		// transform as a successful result.
+		rec.Error = "tool returned error"
+		return "", fmt.Errorf("tool %s.%s returned error: %s", mcpName, toolName,
+			strings.TrimSpace(formatToolResult(callResult, result.Output)))
 	}
 
-	return formatToolResult(callResult), nil
+	return formatToolResult(callResult, result.Output), nil
 }
 
 // resolveSecretArgs returns a copy of args where any string value matching
@@ -996,7 +1559,8 @@ func (a *App) DispatchToolCall(ctx context.Context, req ToolDispatchRequest) (st
 //   - The caller (executeToolCall) must also ensure values are not logged after
 //     substitution.
 //   - If a placeholder references an unknown or expired secret the entire call
-//     fails — the agent should request a fresh Kuze token before retrying.
+//     fails — executeToolCall then asks Ruriko for fresh Kuze tokens (see
+//     refreshSecretArgs) and retries once.
 //
 // Non-string argument values and strings that do not follow the placeholder
 // syntax are returned unchanged.
@@ -1100,6 +1664,14 @@ func (a *App) gatherTools(ctx context.Context) ([]llm.ToolDefinition, map[string
 	toolMap := make(map[string][2]string)
 	var defs []llm.ToolDefinition
 
+	// Per-MCP tool allowlists and description overrides from Gosuto.
+	specs := make(map[string]gosutospec.MCPServer)
+	if cfg := a.gosutoLdr.Config(); cfg != nil {
+		for _, sp := range cfg.MCPs {
+			specs[sp.Name] = sp
+		}
+	}
+
 	for _, mcpName := range a.supv.Names() {
 		client := a.supv.Get(mcpName)
 		if client == nil {
@@ -1110,14 +1682,24 @@ func (a *App) gatherTools(ctx context.Context) ([]llm.ToolDefinition, map[string
 			slog.Warn("could not list tools", "mcp", mcpName, "err", err)
 			continue
 		}
+		spec := specs[mcpName]
 		for _, t := range tools {
+			override, exposed := spec.ExposedTool(t.Name)
+			if !exposed {
+				continue
+			}
+			description := t.Description
+			if override != "" {
+				description = override
+			}
 			composed := mcpName + "__" + t.Name
 			toolMap[composed] = [2]string{mcpName, t.Name}
+			a.toolSchemas.record(composed, t.InputSchema)
 			defs = append(defs, llm.ToolDefinition{
 				Type: "function",
 				Function: llm.FunctionDef{
 					Name:        composed,
-					Description: t.Description,
+					Description: description,
 					Parameters:  t.InputSchema,
 				},
 			})
@@ -1128,14 +1710,33 @@ func (a *App) gatherTools(ctx context.Context) ([]llm.ToolDefinition, map[string
 	// canonical name (e.g. "matrix.send_message") and dispatched via
 	// executeBuiltinTool, not through the MCP client.
 	//
-	// R15.3: matrix.send_message is excluded from the tool list when no
-	// messaging targets are configured in Gosuto (default-deny: the tool is
-	// unavailable rather than visible-but-always-denied, which would cause the
-	// LLM to attempt calls that always fail).
+	// R15.3: matrix.send_message and matrix.send_file are excluded from the
+	// tool list when no messaging targets are configured in Gosuto
+	// (default-deny: the tools are unavailable rather than
+	// visible-but-always-denied, which would cause the LLM to attempt calls
+	// that always fail). Likewise the MCP resource tools
+	// are only offered while at least one MCP server is running and the
+	// resourceTools feature flag is on, notify.operator only while the
+	// notifications section is configured, and event.attachment only on
+	// turns for an event that carries attachments.
 	if a.builtinReg != nil {
 		messagingConfigured := a.policyEng.IsMessagingConfigured()
+		notificationsConfigured := false
+		if cfg := a.configFor(ctx); cfg != nil {
+			notificationsConfigured = cfg.Notifications.Enabled()
+		}
+		resourceTools := a.features().Bool(featureResourceTools) && len(a.supv.Names()) > 0
 		for _, def := range a.builtinReg.Definitions() {
-			if def.Function.Name == builtin.MatrixSendToolName && !messagingConfigured {
+			if isMessagingTool(def.Function.Name) && !messagingConfigured {
+				continue
+			}
+			if isResourceTool(def.Function.Name) && !resourceTools {
+				continue
+			}
+			if def.Function.Name == builtin.NotifyOperatorToolName && !notificationsConfigured {
+				continue
+			}
+			if def.Function.Name == builtin.EventAttachmentToolName && !builtin.HasEventAttachments(ctx) {
 				continue
 			}
 			defs = append(defs, def)
@@ -1145,6 +1746,51 @@ func (a *App) gatherTools(ctx context.Context) ([]llm.ToolDefinition, map[string
 	return defs, toolMap
 }
 
+// isMessagingTool reports whether name is one of the built-ins that send to
+// Gosuto messaging targets.
+func isMessagingTool(name string) bool {
+	return name == builtin.MatrixSendToolName || name == builtin.MatrixSendFileToolName ||
+		name == builtin.AgentRequestToolName
+}
+
+// isResourceTool reports whether name is one of the MCP resource built-ins.
+func isResourceTool(name string) bool {
+	return name == builtin.ResourceListToolName || name == builtin.ResourceReadToolName
+}
+
+// supervisorResources adapts the MCP supervisor to the resource built-ins.
+func supervisorResources(supv *supervisor.Supervisor) builtin.ResourceSource {
+	return func(name string) builtin.ResourceReader {
+		if c := supv.Get(name); c != nil {
+			return c
+		}
+		return nil
+	}
+}
+
+// supervisorStatus adapts the MCP supervisor's health report to the ACP
+// /status wire type.
+func supervisorStatus(supv *supervisor.Supervisor) func() []control.MCPServerStatus {
+	return func() []control.MCPServerStatus {
+		statuses := supv.Status()
+		out := make([]control.MCPServerStatus, 0, len(statuses))
+		for _, st := range statuses {
+			ms := control.MCPServerStatus{
+				Name:     st.Name,
+				State:    st.State,
+				Restarts: st.Restarts,
+				LastExit: st.LastExit,
+			}
+			if !st.LastExitAt.IsZero() {
+				t := st.LastExitAt
+				ms.LastExitAt = &t
+			}
+			out = append(out, ms)
+		}
+		return out
+	}
+}
+
 // executeBuiltinTool evaluates policy and dispatches a tool call to the
 // appropriate built-in handler.
 //
@@ -1181,7 +1827,7 @@ func (a *App) auditMessagingSend(ctx context.Context, args map[string]interface{
 	// This is a read-only lookup — it does not re-validate or re-check policy.
 	roomID := ""
 	if cfg := a.gosutoLdr.Config(); cfg != nil {
-		for _, t := range cfg.Messaging.AllowedTargets {
+		for _, t := range cfg.MessagingTargets() {
 			if t.Alias == targetAlias {
 				roomID = t.RoomID
 				break
@@ -1230,14 +1876,15 @@ func (a *App) auditMessagingSend(ctx context.Context, args map[string]interface{
 }
 
 // buildMessagingTargets returns a slice of human-readable target strings
-// ("alias (roomID)") derived from cfg.Messaging.AllowedTargets, suitable for
+// ("alias (roomID)") derived from cfg.MessagingTargets(), suitable for
 // injection into the LLM system prompt via buildSystemPrompt.
 func buildMessagingTargets(cfg *gosutospec.Config) []string {
-	if cfg == nil || len(cfg.Messaging.AllowedTargets) == 0 {
+	all := cfg.MessagingTargets()
+	if len(all) == 0 {
 		return nil
 	}
-	targets := make([]string, 0, len(cfg.Messaging.AllowedTargets))
-	for _, t := range cfg.Messaging.AllowedTargets {
+	targets := make([]string, 0, len(all))
+	for _, t := range all {
 		targets = append(targets, fmt.Sprintf("%s (%s)", t.Alias, t.RoomID))
 	}
 	return targets
@@ -1255,12 +1902,14 @@ func splitToolName(composed string) (mcpName, toolName string) {
 	return "", composed
 }
 
-// formatToolResult converts an MCP CallToolResult into a string for the LLM.
-func formatToolResult(result *mcp.CallToolResult) string {
+// formatToolResult converts an MCP CallToolResult into a string for the LLM,
+// applying the matched capability's output transform (if any) to each text
+// item.
+func formatToolResult(result *mcp.CallToolResult, transform *gosutospec.OutputTransform) string {
 	var parts []string
 	for _, item := range result.Content {
 		if item.Text != "" {
-			parts = append(parts, item.Text)
+			parts = append(parts, applyOutputTransform(item.Text, transform))
 		}
 	}
 	if len(parts) == 0 {
@@ -1301,6 +1950,26 @@ func buildLLMProvider(cfg LLMConfig) llm.Provider {
 			BaseURL: cfg.BaseURL,
 			Model:   cfg.Model,
 		})
+	case "anthropic":
+		if apiKey == "" {
+			slog.Warn("LLM provider disabled: missing Anthropic API key")
+			return nil
+		}
+		return llm.NewAnthropic(llm.AnthropicConfig{
+			APIKey:  apiKey,
+			BaseURL: cfg.BaseURL,
+			Model:   cfg.Model,
+		})
+	case "gemini":
+		if apiKey == "" {
+			slog.Warn("LLM provider disabled: missing Gemini API key")
+			return nil
+		}
+		return llm.NewGemini(llm.GeminiConfig{
+			APIKey:  apiKey,
+			BaseURL: cfg.BaseURL,
+			Model:   cfg.Model,
+		})
 	default:
 		slog.Warn("unknown LLM provider; defaulting to OpenAI", "provider", cfg.Provider)
 		if apiKey == "" {
@@ -1316,43 +1985,104 @@ func buildLLMProvider(cfg LLMConfig) llm.Provider {
 }
 
 // handleEvent is the HandleEvent callback wired into the ACP server (R12.2).
-// It MUST return quickly — the full turn runs in a background goroutine so that
-// the HTTP 202 is returned to the gateway before the LLM call completes.
-func (a *App) handleEvent(ctx context.Context, evt *envelope.Event) {
-	go a.runEventTurn(context.Background(), evt)
+// It MUST return quickly — the event is placed on the bounded event queue and
+// the turn runs on a queue worker, so the HTTP 202 is returned to the gateway
+// before the LLM call completes. It returns control.ErrEventQueueFull when the
+// queue has no room.
+func (a *App) handleEvent(ctx context.Context, evt *envelope.Event) error {
+	return a.queueEvent(ctx, evt, nil)
+}
+
+// queueEvent places evt on the event queue; done, if not nil, receives the
+// turn error once a worker has run it.
+func (a *App) queueEvent(ctx context.Context, evt *envelope.Event, done func(error)) error {
+	if err := a.events().enqueue(ctx, evt, done); err != nil {
+		return err
+	}
+	a.metrics.eventIngested(evt.Source)
+	return nil
+}
+
+// processEvent runs the turn for a dequeued event and dead-letters it on
+// failure. Events refused because the agent is at its concurrency limit are
+// dropped, not dead-lettered. It returns the turn error.
+func (a *App) processEvent(ctx context.Context, evt *envelope.Event) error {
+	err := a.runEventTurn(ctx, evt)
+	if err != nil && !errors.Is(err, errAgentBusy) {
+		a.deadLetterEvent(evt, err)
+	}
+	return err
 }
 
 // runEventTurn executes the full turn pipeline for an inbound gateway event.
 // It mirrors handleMessage but uses the admin room as the output destination
-// and a "gateway:<source>" label as the sender identifier.
-func (a *App) runEventTurn(ctx context.Context, evt *envelope.Event) {
+// and a "gateway:<source>" label as the sender identifier. It returns the
+// turn error, if any; events dropped before a turn starts return nil, except
+// for errAgentBusy when the concurrency limit is reached.
+func (a *App) runEventTurn(ctx context.Context, evt *envelope.Event) error {
 	cfg := a.gosutoLdr.Config()
 	if cfg == nil {
 		slog.Warn("event dropped: no Gosuto config loaded",
 			"source", evt.Source, "type", evt.Type, "reason", "no_config")
-		return
+		return nil
+	}
+	if !cfg.Metadata.IsEnabled() {
+		slog.Info("agent disabled by config; event dropped",
+			"source", evt.Source, "type", evt.Type, "reason", "disabled")
+		return nil
 	}
 
 	adminRoom := cfg.Trust.AdminRoom
 	if adminRoom == "" {
 		slog.Warn("event dropped: no adminRoom configured in Gosuto trust block",
 			"source", evt.Source, "type", evt.Type, "reason", "no_admin_room")
-		return
+		return nil
+	}
+	// Event turns answer in the admin room, so they run under the canary
+	// config when the admin room is the canary room.
+	if c := a.canaryFor(adminRoom); c != nil {
+		ctx = withCanary(ctx, c)
 	}
 
-	// Build the user-facing text for this event turn.
+	// Hold the event (its queue worker) until the startup probe passes.
+	if !a.waitStartup(ctx) {
+		return ctx.Err()
+	}
+
+	// The onStartup turn is queued once per config version, so it waits for
+	// a slot; any other event is dropped while the agent is at its limit.
+	if evt.Source == onStartupSource {
+		if err := a.turns.acquire(ctx); err != nil {
+			return err
+		}
+	} else if !a.turns.tryAcquire() {
+		slog.Warn("event dropped: concurrent turn limit reached",
+			"source", evt.Source, "type", evt.Type, "reason", "busy",
+			"max_concurrent", cfg.Limits.MaxConcurrentRequests)
+		return errAgentBusy
+	}
+	defer a.turns.release()
+
+	// Build the user-facing text for this event turn. Attachment contents
+	// stay out of the prompt; event.attachment reads them from ctx.
 	userText := buildEventMessage(evt)
+	ctx = builtin.WithEventAttachments(ctx, evt.Payload.Attachments)
 
 	// Assign a stable trace ID for the turn so every log line and DB record
-	// can be correlated back to this specific event.
-	traceID := trace.GenerateID()
-	ctx = trace.WithTraceID(ctx, traceID)
+	// can be correlated back to this specific event. Events delivered over
+	// ACP keep the trace ID of the request that carried them.
+	traceID := trace.FromContext(ctx)
+	if traceID == "" {
+		traceID = trace.GenerateID()
+		ctx = trace.WithTraceID(ctx, traceID)
+	}
 	log := observability.WithTrace(ctx)
 
 	// Log the turn in the DB. LogGatewayTurn stores trigger="gateway",
 	// gateway_name, and event_type so that gateway turns are distinguishable
 	// from Matrix-message turns without parsing the sender_mxid string.
 	senderLabel := "gateway:" + evt.Source
+	a.metrics.turns.Add(1)
 	turnID, err := a.db.LogGatewayTurn(traceID, adminRoom, senderLabel, userText, evt.Source, evt.Type)
 	if err != nil {
 		log.Warn("could not log event turn", "err", err)
@@ -1397,7 +2127,7 @@ func (a *App) runEventTurn(ctx context.Context, evt *envelope.Event) {
 		if turnID > 0 {
 			_ = a.db.FinishTurnWithDuration(turnID, toolCalls, durationMS, "error", err.Error())
 		}
-		return
+		return err
 	}
 
 	// Post the formatted response to the admin room.  The raw event payload
@@ -1421,6 +2151,7 @@ func (a *App) runEventTurn(ctx context.Context, evt *envelope.Event) {
 		"duration_ms", durationMS,
 		"tool_calls", toolCalls,
 	)
+	return nil
 }
 
 // buildEventMessage returns the user-facing text for an event turn.
@@ -1428,7 +2159,12 @@ func (a *App) runEventTurn(ctx context.Context, evt *envelope.Event) {
 // When it is empty a descriptive prompt is auto-generated from the event
 // metadata and any structured data in the payload — matching the pattern
 // described in R12.2: "Event received from {source} (type: {type}). Data: {json}"
+// Attachment metadata, when present, is appended in both cases.
 func buildEventMessage(evt *envelope.Event) string {
+	return eventMessageText(evt) + formatAttachmentList(evt.Payload.Attachments)
+}
+
+func eventMessageText(evt *envelope.Event) string {
 	if evt.Payload.Message != "" {
 		return evt.Payload.Message
 	}
@@ -1442,6 +2178,28 @@ func buildEventMessage(evt *envelope.Event) string {
 	return fmt.Sprintf("Event received from %s (type: %s). Data: %s", evt.Source, evt.Type, dataJSON)
 }
 
+// formatAttachmentList renders attachment metadata (never content) for the
+// event prompt, indexed for the event.attachment tool.
+func formatAttachmentList(atts []envelope.Attachment) string {
+	if len(atts) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n\nAttachments (read with the %s tool):", builtin.EventAttachmentToolName)
+	for i, att := range atts {
+		mime := att.MIME
+		if mime == "" {
+			mime = "unknown type"
+		}
+		fmt.Fprintf(&sb, "\n[%d] %s (%s", i, att.Name, mime)
+		if att.Size > 0 {
+			fmt.Fprintf(&sb, ", %d bytes", att.Size)
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
 // buildSecretEnvMapping creates an envVar → secretName mapping from the Gosuto
 // SecretRef list so the supervisor can inject secrets into MCP environments.
 // Only refs with a non-empty EnvVar are included.
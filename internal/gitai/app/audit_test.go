@@ -22,11 +22,11 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/bdobrica/Ruriko/common/policy"
 	"github.com/bdobrica/Ruriko/common/trace"
 	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
 	"github.com/bdobrica/Ruriko/internal/gitai/gosuto"
 	"github.com/bdobrica/Ruriko/internal/gitai/llm"
-	"github.com/bdobrica/Ruriko/internal/gitai/policy"
 )
 
 // --- stubs ---
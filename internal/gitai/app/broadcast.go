@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+// broadcastPrefix marks operator notices so room members can tell them from
+// the agent's own replies.
+const broadcastPrefix = "📢 **Operator notice:** "
+
+// broadcast implements control.Handlers.Broadcast. It posts message to every
+// room in the agent's trust block (allowed rooms plus the admin room);
+// wildcard entries name no room and are skipped. Rooms that fail are
+// reported rather than aborting the rest.
+func (a *App) broadcast(_ context.Context, message string) (control.BroadcastResponse, error) {
+	cfg := a.gosutoLdr.Config()
+	if cfg == nil {
+		return control.BroadcastResponse{}, fmt.Errorf("no Gosuto config loaded")
+	}
+	if a.eventSender == nil {
+		return control.BroadcastResponse{}, fmt.Errorf("matrix client not available")
+	}
+	var resp control.BroadcastResponse
+	for _, room := range roomsFromConfig(cfg) {
+		if room == "*" {
+			continue
+		}
+		if err := a.eventSender.SendText(room, broadcastPrefix+message); err != nil {
+			slog.Warn("broadcast: send failed", "room", room, "err", err)
+			resp.Failed = append(resp.Failed, room)
+			continue
+		}
+		resp.Rooms++
+	}
+	slog.Info("broadcast posted", "rooms", resp.Rooms, "failed", len(resp.Failed))
+	return resp, nil
+}
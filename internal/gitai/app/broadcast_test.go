@@ -0,0 +1,34 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBroadcast_PostsToConfiguredRooms(t *testing.T) {
+	a := newEventApp(t, eventTestGosutoYAML, newCapturingLLM("unused"))
+	sender := &recordingMatrixSender{}
+	a.eventSender = sender
+
+	resp, err := a.broadcast(context.Background(), "Maintenance at 22:00 UTC")
+	if err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+	if resp.Rooms != 2 || len(resp.Failed) != 0 {
+		t.Fatalf("resp = %+v, want 2 rooms and no failures", resp)
+	}
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	rooms := map[string]bool{}
+	for _, s := range sender.sends {
+		rooms[s.roomID] = true
+		if !strings.Contains(s.text, "Maintenance at 22:00 UTC") {
+			t.Errorf("send to %s = %q, want the notice text", s.roomID, s.text)
+		}
+	}
+	if !rooms["!chat-room:example.com"] || !rooms["!admin-room:example.com"] {
+		t.Errorf("rooms = %v, want the allowed room and the admin room", rooms)
+	}
+}
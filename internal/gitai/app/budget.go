@@ -0,0 +1,96 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// errBudgetExceeded is returned by a turn refused because the month's
+// estimated LLM spend reached limits.maxMonthlyCostUSD.
+var errBudgetExceeded = errors.New("monthly LLM budget exceeded")
+
+// spendMonth returns the calendar month (UTC) t is accounted to, "YYYY-MM".
+func spendMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// now returns the current time from the app's clock, which tests replace to
+// cross month boundaries.
+func (a *App) now() time.Time {
+	if a.clock != nil {
+		return a.clock()
+	}
+	return time.Now()
+}
+
+// llmModel returns the model name turns are billed under: the Gosuto
+// persona model, or the deployment default.
+func (a *App) llmModel(cfg *gosutospec.Config) string {
+	if cfg != nil && cfg.Persona.Model != "" {
+		return cfg.Persona.Model
+	}
+	if a.cfg != nil {
+		return a.cfg.LLM.Model
+	}
+	return ""
+}
+
+// checkBudget refuses an LLM call once this month's estimated spend has
+// reached the Gosuto limits.maxMonthlyCostUSD. The error is the reply the
+// requester sees.
+func (a *App) checkBudget(cfg *gosutospec.Config) error {
+	budget := cfg.Limits.MaxMonthlyCostUSD
+	if budget <= 0 || a.db == nil {
+		return nil
+	}
+	month := spendMonth(a.now())
+	spent, err := a.db.LLMSpend(month)
+	if err != nil {
+		// A broken store must not silently lift the budget.
+		return fmt.Errorf("monthly LLM budget could not be checked: %w", err)
+	}
+	if spent >= budget {
+		slog.Warn("monthly LLM budget exceeded; refusing LLM call",
+			"month", month, "spent_usd", spent, "budget_usd", budget)
+		return fmt.Errorf("%w: $%.2f of $%.2f spent in %s", errBudgetExceeded, spent, budget, month)
+	}
+	return nil
+}
+
+// recordSpend adds the estimated cost of one LLM call to this month's total.
+func (a *App) recordSpend(model string, usage llm.TokenUsage) {
+	if a.db == nil || (usage.PromptTokens == 0 && usage.CompletionTokens == 0) {
+		return
+	}
+	if _, known := llm.PriceFor(model); !known {
+		slog.Debug("no price for model; using the default price", "model", model)
+	}
+	cost := llm.EstimateCostUSD(model, usage)
+	if _, err := a.db.AddLLMSpend(spendMonth(a.now()), usage.PromptTokens, usage.CompletionTokens, cost); err != nil {
+		slog.Warn("could not record LLM spend", "model", model, "err", err)
+	}
+}
+
+// monthlySpend implements control.Handlers.MonthlySpend.
+func (a *App) monthlySpend() *control.MonthlySpend {
+	if a.db == nil {
+		return nil
+	}
+	month := spendMonth(a.now())
+	spent, err := a.db.LLMSpend(month)
+	if err != nil {
+		slog.Warn("could not read LLM spend", "month", month, "err", err)
+		return nil
+	}
+	st := &control.MonthlySpend{Month: month, CostUSD: spent}
+	if c := a.gosutoLdr.Config(); c != nil {
+		st.BudgetUSD = c.Limits.MaxMonthlyCostUSD
+	}
+	return st
+}
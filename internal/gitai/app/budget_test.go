@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// meteredLLM answers every call with a fixed text and reports one million
+// prompt tokens, $2.50 at the gpt-4o price.
+type meteredLLM struct {
+	calls atomic.Int32
+}
+
+func (m *meteredLLM) Complete(context.Context, llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	m.calls.Add(1)
+	return &llm.CompletionResponse{
+		Message:      llm.Message{Role: llm.RoleAssistant, Content: "ok"},
+		FinishReason: "stop",
+		Usage:        llm.TokenUsage{PromptTokens: 1_000_000, TotalTokens: 1_000_000},
+	}, nil
+}
+
+// budgetGosutoYAML bills turns at the gpt-4o price with a $5 budget.
+const budgetGosutoYAML = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms:
+    - "!chat-room:example.com"
+  allowedSenders:
+    - "@user:example.com"
+  adminRoom: "!admin-room:example.com"
+limits:
+  maxMonthlyCostUSD: 5
+persona:
+  llmProvider: openai
+  model: gpt-4o
+`
+
+func TestRunTurn_MonthlyBudget(t *testing.T) {
+	prov := &meteredLLM{}
+	a := newEventApp(t, budgetGosutoYAML, prov)
+	clock := time.Date(2026, time.January, 31, 23, 0, 0, 0, time.UTC)
+	a.clock = func() time.Time { return clock }
+
+	turn := func() error {
+		_, _, err := a.runTurn(context.Background(), "!chat-room:example.com", "@user:example.com", "hi", "")
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := turn(); err != nil {
+			t.Fatalf("turn %d: %v", i+1, err)
+		}
+	}
+
+	st := a.monthlySpend()
+	if st == nil || st.Month != "2026-01" || math.Abs(st.CostUSD-5) > 1e-9 || st.BudgetUSD != 5 {
+		t.Fatalf("spend = %+v, want $5 of $5 in 2026-01", st)
+	}
+
+	if err := turn(); !errors.Is(err, errBudgetExceeded) {
+		t.Fatalf("turn over budget: err = %v, want errBudgetExceeded", err)
+	}
+	if n := prov.calls.Load(); n != 2 {
+		t.Errorf("LLM calls = %d, want 2; the over-budget turn must not call the LLM", n)
+	}
+
+	// The budget resets at the month boundary (UTC).
+	clock = time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+	if st := a.monthlySpend(); st.Month != "2026-02" || st.CostUSD != 0 {
+		t.Errorf("spend after rollover = %+v, want nothing spent in 2026-02", st)
+	}
+	if err := turn(); err != nil {
+		t.Fatalf("turn in a new month: %v", err)
+	}
+
+	// January's total is kept.
+	if jan, err := a.db.LLMSpend("2026-01"); err != nil || math.Abs(jan-5) > 1e-9 {
+		t.Errorf("January spend = %v, %v; want 5", jan, err)
+	}
+}
+
+func TestRunTurn_NoBudgetRecordsSpend(t *testing.T) {
+	prov := &meteredLLM{}
+	a := newEventApp(t, eventTestGosutoYAML, prov) // gpt-4o-mini, no budget
+	for i := 0; i < 3; i++ {
+		if _, _, err := a.runTurn(context.Background(), "!chat-room:example.com", "@user:example.com", "hi", ""); err != nil {
+			t.Fatalf("turn %d: %v", i+1, err)
+		}
+	}
+	st := a.monthlySpend()
+	if st == nil || math.Abs(st.CostUSD-0.45) > 1e-9 || st.BudgetUSD != 0 {
+		t.Errorf("spend = %+v, want $0.45 and no budget", st)
+	}
+}
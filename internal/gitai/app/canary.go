@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bdobrica/Ruriko/common/policy"
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+// canaryConfig is a Gosuto config applied to a single room for testing
+// before it is rolled out. Turns in that room read their trust, persona,
+// instructions, limits, capabilities and approvals from it; MCP servers,
+// gateways, secrets and messaging targets stay those of the live config
+// until the canary is promoted by a regular config apply. Canary state is
+// held in memory only, so a restart ends it.
+type canaryConfig struct {
+	room      string
+	hash      string
+	cfg       *gosutospec.Config
+	policy    *policy.Engine
+	startedAt time.Time
+}
+
+// Config implements policy.ConfigProvider for the canary's policy engine.
+func (c *canaryConfig) Config() *gosutospec.Config { return c.cfg }
+
+type canaryCtxKey struct{}
+
+// withCanary marks ctx as a turn that runs under the canary config c.
+func withCanary(ctx context.Context, c *canaryConfig) context.Context {
+	return context.WithValue(ctx, canaryCtxKey{}, c)
+}
+
+func canaryFromContext(ctx context.Context) *canaryConfig {
+	c, _ := ctx.Value(canaryCtxKey{}).(*canaryConfig)
+	return c
+}
+
+// canaryFor returns the active canary when roomID is its room, and nil
+// otherwise.
+func (a *App) canaryFor(roomID string) *canaryConfig {
+	if c := a.canary.Load(); c != nil && c.room == roomID {
+		return c
+	}
+	return nil
+}
+
+// configFor returns the Gosuto config the turn in ctx runs under: the
+// canary config for canary-room turns, the live config otherwise.
+func (a *App) configFor(ctx context.Context) *gosutospec.Config {
+	if c := canaryFromContext(ctx); c != nil {
+		return c.cfg
+	}
+	return a.gosutoLdr.Config()
+}
+
+// policyFor returns the policy engine matching configFor(ctx).
+func (a *App) policyFor(ctx context.Context) *policy.Engine {
+	if c := canaryFromContext(ctx); c != nil {
+		return c.policy
+	}
+	return a.policyEng
+}
+
+// applyCanary implements control.Handlers.ApplyCanary: it validates yaml and
+// starts using it for turns in room, replacing any earlier canary. The live
+// config is untouched.
+func (a *App) applyCanary(yaml, hash, room string) error {
+	room = strings.TrimSpace(room)
+	if !strings.HasPrefix(room, "!") {
+		return fmt.Errorf("canary room %q must be a room ID starting with '!'", room)
+	}
+	cfg, err := gosutospec.Parse([]byte(yaml))
+	if err != nil {
+		return fmt.Errorf("canary config: %w", err)
+	}
+	c := &canaryConfig{room: room, hash: hash, cfg: cfg, startedAt: time.Now().UTC()}
+	c.policy = policy.New(c)
+	a.canary.Store(c)
+	slog.Info("canary config applied", "room", room, "hash", hash[:min(12, len(hash))])
+	return nil
+}
+
+// abortCanary implements control.Handlers.AbortCanary. It reports whether a
+// canary was active.
+func (a *App) abortCanary() bool {
+	c := a.canary.Swap(nil)
+	if c != nil {
+		slog.Info("canary config aborted", "room", c.room, "hash", c.hash[:min(12, len(c.hash))])
+	}
+	return c != nil
+}
+
+// canaryStatus implements control.Handlers.Canary for GET /status.
+func (a *App) canaryStatus() *control.CanaryStatus {
+	c := a.canary.Load()
+	if c == nil {
+		return nil
+	}
+	return &control.CanaryStatus{Room: c.room, Hash: c.hash, StartedAt: c.startedAt}
+}
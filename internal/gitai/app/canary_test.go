@@ -0,0 +1,116 @@
+package app
+
+// Tests for single-room canary configs: turns in the canary room run under
+// the canary config, every other room keeps the live config.
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const canaryLiveGosutoYAML = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms:
+    - "*"
+  allowedSenders:
+    - "*"
+persona:
+  llmProvider: openai
+  model: gpt-4o-mini
+  systemPrompt: "You are the live agent."
+`
+
+var canaryNextGosutoYAML = strings.Replace(canaryLiveGosutoYAML, "You are the live agent.", "You are the canary agent.", 1)
+
+const (
+	canaryRoom = "!canary:example.com"
+	otherRoom  = "!other:example.com"
+)
+
+// turnSystemPrompt sends a directed message to roomID and returns the system
+// prompt of the LLM request it produced.
+func turnSystemPrompt(t *testing.T, a *App, prov *capturingLLM, roomID string) string {
+	t.Helper()
+	a.handleMessage(context.Background(), makeMessageEvent(roomID, "@user:example.com", "$evt-"+roomID, "Hey test-agent, hello"))
+	req, ok := prov.waitForCall(3 * time.Second)
+	if !ok {
+		t.Fatalf("expected a turn in %s", roomID)
+	}
+	if len(req.Messages) == 0 {
+		t.Fatalf("turn in %s sent no messages", roomID)
+	}
+	return req.Messages[0].Content
+}
+
+func TestCanary_AppliesOnlyToCanaryRoom(t *testing.T) {
+	prov := newCapturingLLM("ok")
+	a := newEventApp(t, canaryLiveGosutoYAML, prov)
+
+	if err := a.applyCanary(canaryNextGosutoYAML, "canaryhash", canaryRoom); err != nil {
+		t.Fatalf("applyCanary: %v", err)
+	}
+
+	if got := turnSystemPrompt(t, a, prov, canaryRoom); !strings.Contains(got, "You are the canary agent.") {
+		t.Errorf("canary room system prompt = %q, want the canary persona", got)
+	}
+	if got := turnSystemPrompt(t, a, prov, otherRoom); !strings.Contains(got, "You are the live agent.") {
+		t.Errorf("other room system prompt = %q, want the live persona", got)
+	}
+
+	st := a.canaryStatus()
+	if st == nil || st.Room != canaryRoom || st.Hash != "canaryhash" || st.StartedAt.IsZero() {
+		t.Errorf("canaryStatus = %+v, want room %s and hash canaryhash", st, canaryRoom)
+	}
+}
+
+func TestCanary_AbortRestoresLiveConfig(t *testing.T) {
+	prov := newCapturingLLM("ok")
+	a := newEventApp(t, canaryLiveGosutoYAML, prov)
+
+	if a.abortCanary() {
+		t.Error("abortCanary reported a canary before one was applied")
+	}
+	if err := a.applyCanary(canaryNextGosutoYAML, "canaryhash", canaryRoom); err != nil {
+		t.Fatalf("applyCanary: %v", err)
+	}
+	if !a.abortCanary() {
+		t.Fatal("abortCanary reported no active canary")
+	}
+	if a.canaryStatus() != nil {
+		t.Error("canaryStatus should be nil after abort")
+	}
+	if got := turnSystemPrompt(t, a, prov, canaryRoom); !strings.Contains(got, "You are the live agent.") {
+		t.Errorf("system prompt after abort = %q, want the live persona", got)
+	}
+}
+
+func TestCanary_FullApplyEndsCanary(t *testing.T) {
+	a := newConfigApplyApp(t)
+	if err := a.applyCanary(canaryNextGosutoYAML, "canaryhash", canaryRoom); err != nil {
+		t.Fatalf("applyCanary: %v", err)
+	}
+	if err := a.applyConfig(eventTestGosutoYAML, "h"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if a.canaryStatus() != nil {
+		t.Error("a full config apply should end the canary")
+	}
+}
+
+func TestCanary_RejectsBadInput(t *testing.T) {
+	a := newEventApp(t, canaryLiveGosutoYAML, newCapturingLLM("ok"))
+
+	if err := a.applyCanary(canaryNextGosutoYAML, "h", "#alias:example.com"); err == nil {
+		t.Error("expected an error for a room alias")
+	}
+	if err := a.applyCanary("apiVersion: gosuto/v1\n", "h", canaryRoom); err == nil {
+		t.Error("expected an error for an invalid config")
+	}
+	if a.canaryStatus() != nil {
+		t.Error("a rejected canary must not become active")
+	}
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/reconcile"
+)
+
+// applyConfig implements control.Handlers.ApplyConfig: it applies a pushed
+// Gosuto config, persists it for restart recovery, reconciles MCP servers
+// and gateways, and records the outcome (including what the reconcile
+// started, stopped or failed to start) for GET /status.
+func (a *App) applyConfig(yaml, hash string) error {
+	prev, prevHash := a.gosutoLdr.Config(), a.gosutoLdr.Hash()
+	if err := a.gosutoLdr.Apply([]byte(yaml)); err != nil {
+		a.recordConfigApply(hash, control.ConfigApplyStatus{
+			Result:  control.ConfigApplyError,
+			Message: err.Error(),
+		})
+		return err
+	}
+	// Persist to DB for restart recovery.
+	_ = a.db.SaveAppliedConfig(hash, yaml)
+	// A full apply (including the promotion of a canary) ends any canary.
+	if c := a.canary.Swap(nil); c != nil {
+		slog.Info("config applied; canary ended", "room", c.room)
+	}
+
+	st := control.ConfigApplyStatus{Result: control.ConfigApplyOK}
+	var warnings []string
+	// Reconcile MCP servers, cron gateways, and external gateway processes.
+	if c := a.gosutoLdr.Config(); c != nil {
+		a.turns.setLimit(c.Limits.MaxConcurrentRequests)
+		warnings = append(warnings, a.reconcileAll(c, &st)...)
+		// Hold traffic for the MCP servers and required secrets this apply
+		// added; the checks are bounded by the probe timeout, not the request.
+		if a.gosutoLdr.Hash() != prevHash {
+			a.extendStartupGate(context.Background(), startupAdditions(prev, c))
+		}
+		if a.matrixCli != nil {
+			a.matrixCli.EnsureJoinedRooms(roomsFromConfig(c))
+		}
+		for _, w := range gosutospec.Warnings(c) {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", w.Field, w.Message))
+		}
+	}
+	a.applyFeatures()
+	// Rebuild the LLM provider in case the new Gosuto specifies a
+	// different APIKeySecretRef or model, and the matching secret is
+	// already cached in the secret manager.
+	a.rebuildLLMProvider()
+
+	if len(warnings) > 0 {
+		st.Result = control.ConfigApplyWarning
+		st.Message = strings.Join(warnings, "; ")
+		slog.Warn("config applied with warnings", "warnings", st.Message)
+	}
+	// This reconcile supersedes the boot one: whatever still fails to start
+	// is now reported in st.Failed.
+	a.startupFailures.Store(nil)
+	a.recordConfigApply(a.gosutoLdr.Hash(), st)
+	a.postConfigApplyNotice(st)
+	a.runOnStartup(a.gosutoLdr.Hash())
+	return nil
+}
+
+// postConfigApplyNotice posts a one-line breadcrumb for a successful apply
+// to the admin room when the configApplyNotices flag is on. It sends in the
+// background so a slow homeserver does not hold up the ACP response.
+func (a *App) postConfigApplyNotice(st control.ConfigApplyStatus) {
+	if !a.features().Bool(featureConfigApplyNotices) || a.eventSender == nil {
+		return
+	}
+	c := a.gosutoLdr.Config()
+	if c == nil || c.Trust.AdminRoom == "" {
+		return
+	}
+	text := configApplyNotice(a.gosutoLdr.Hash(), c, st)
+	room, sender := c.Trust.AdminRoom, a.eventSender
+	go func() {
+		if err := sender.SendText(room, text); err != nil {
+			slog.Warn("config apply notice not sent", "room", room, "err", err)
+		}
+	}()
+}
+
+// configApplyNotice formats the admin-room notice for an applied config,
+// e.g. "📥 Applied gosuto 1a2b3c4d5e6f — 2 MCPs, 1 gateways", followed by
+// what the reconcile changed.
+func configApplyNotice(hash string, c *gosutospec.Config, st control.ConfigApplyStatus) string {
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📥 Applied gosuto %s — %d MCPs, %d gateways", hash, len(c.MCPs), len(c.Gateways))
+	for _, part := range []struct {
+		label string
+		names []string
+	}{
+		{"started", st.Started},
+		{"stopped", st.Stopped},
+		{"restarted", st.Restarted},
+		{"failed", st.Failed},
+	} {
+		if len(part.names) > 0 {
+			fmt.Fprintf(&sb, "\n%s: %s", part.label, strings.Join(part.names, ", "))
+		}
+	}
+	if st.Result == control.ConfigApplyWarning {
+		sb.WriteString("\n⚠️ applied with warnings; see /status")
+	}
+	return sb.String()
+}
+
+// reconcileAtStartup reconciles c when the agent boots. Anything that fails
+// to start is kept for GET /status and reported to the admin room, so an
+// agent that came up without some of its tools shows as degraded instead of
+// only logging it.
+func (a *App) reconcileAtStartup(c *gosutospec.Config) {
+	var st control.ConfigApplyStatus
+	for _, w := range a.reconcileAll(c, &st) {
+		slog.Warn("startup reconcile", "warning", w)
+	}
+	if len(st.Failed) == 0 {
+		return
+	}
+	failed := st.Failed
+	a.startupFailures.Store(&failed)
+	slog.Error("agent started degraded", "failed", len(failed))
+
+	if a.eventSender == nil || c.Trust.AdminRoom == "" {
+		return
+	}
+	text := "⚠️ Agent started degraded; failed to start:\n- " + strings.Join(failed, "\n- ")
+	room, sender := c.Trust.AdminRoom, a.eventSender
+	go func() {
+		if err := sender.SendText(room, text); err != nil {
+			slog.Warn("startup failure warning not sent", "room", room, "err", err)
+		}
+	}()
+}
+
+// startupFailureList implements control.Handlers.StartupFailures.
+func (a *App) startupFailureList() []string {
+	if p := a.startupFailures.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// reconcileAll brings MCP servers, cron jobs and external gateways in line
+// with c, logs what each supervisor changed, and records the changes in st.
+// It returns one warning per entry that failed to start. st may be nil.
+func (a *App) reconcileAll(c *gosutospec.Config, st *control.ConfigApplyStatus) []string {
+	var warnings []string
+	for _, r := range []struct {
+		kind string
+		res  reconcile.Result
+	}{
+		{"mcp", a.supv.Reconcile(c.MCPs)},
+		{"cron", a.cronMgr.Reconcile(c.Gateways)},
+		{"gateway", a.extGWSupv.Reconcile(c.Gateways)},
+	} {
+		if !r.res.Empty() {
+			slog.Info("reconcile", "kind", r.kind, "result", r.res.String())
+		}
+		for _, f := range r.res.Failed {
+			warnings = append(warnings, fmt.Sprintf("%s %q failed to start: %s", r.kind, f.Name, f.Err))
+		}
+		if st == nil {
+			continue
+		}
+		st.Started = append(st.Started, prefixNames(r.kind, r.res.Started)...)
+		st.Stopped = append(st.Stopped, prefixNames(r.kind, r.res.Stopped)...)
+		st.Restarted = append(st.Restarted, prefixNames(r.kind, r.res.Restarted)...)
+		for _, f := range r.res.Failed {
+			st.Failed = append(st.Failed, fmt.Sprintf("%s:%s: %s", r.kind, f.Name, f.Err))
+		}
+	}
+	return warnings
+}
+
+// prefixNames returns names as "<kind>:<name>".
+func prefixNames(kind string, names []string) []string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = kind + ":" + n
+	}
+	return out
+}
+
+// recordConfigApply stores st, stamped with hash and the current time, as
+// the latest config-apply outcome.
+func (a *App) recordConfigApply(hash string, st control.ConfigApplyStatus) {
+	st.Hash = hash
+	st.AppliedAt = time.Now().UTC()
+	a.lastApply.Store(&st)
+}
+
+// lastConfigApply implements control.Handlers.LastConfigApply.
+func (a *App) lastConfigApply() *control.ConfigApplyStatus {
+	return a.lastApply.Load()
+}
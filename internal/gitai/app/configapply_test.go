@@ -0,0 +1,201 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/gateway"
+	"github.com/bdobrica/Ruriko/internal/gitai/supervisor"
+)
+
+// newConfigApplyApp returns an event-test app with the gateway managers that
+// applyConfig reconciles.
+func newConfigApplyApp(t *testing.T) *App {
+	t.Helper()
+	a := newEventApp(t, eventTestGosutoYAML, newCapturingLLM("ok"))
+	a.cronMgr = gateway.NewManager(gateway.ACPBaseURL(":0"))
+	t.Cleanup(a.cronMgr.Stop)
+	a.extGWSupv = supervisor.NewExternalGatewaySupervisor(gateway.ACPBaseURL(":0"))
+	t.Cleanup(a.extGWSupv.Stop)
+	return a
+}
+
+const configApplyBrokenMCPYAML = eventTestGosutoYAML + `mcps:
+  - name: ghost
+    command: /nonexistent/ghost-mcp
+`
+
+func TestApplyConfig_RecordsSuccess(t *testing.T) {
+	a := newConfigApplyApp(t)
+	if a.lastConfigApply() != nil {
+		t.Fatal("expected no apply status before the first push")
+	}
+
+	if err := a.applyConfig(eventTestGosutoYAML, "ignored"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	st := a.lastConfigApply()
+	if st == nil {
+		t.Fatal("expected apply status")
+	}
+	if st.Result != control.ConfigApplyOK || st.Message != "" {
+		t.Errorf("status = %+v, want ok without message", st)
+	}
+	if st.Hash != a.gosutoLdr.Hash() {
+		t.Errorf("hash = %q, want loader hash %q", st.Hash, a.gosutoLdr.Hash())
+	}
+	if st.AppliedAt.IsZero() {
+		t.Error("expected AppliedAt to be set")
+	}
+}
+
+func TestApplyConfig_RecordsReconcileWarning(t *testing.T) {
+	a := newConfigApplyApp(t)
+
+	if err := a.applyConfig(configApplyBrokenMCPYAML, "h"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	st := a.lastConfigApply()
+	if st == nil || st.Result != control.ConfigApplyWarning {
+		t.Fatalf("status = %+v, want warning", st)
+	}
+	if !strings.Contains(st.Message, `mcp "ghost" failed to start`) {
+		t.Errorf("message = %q, want the failed MCP to be named", st.Message)
+	}
+	if len(st.Failed) != 1 || !strings.HasPrefix(st.Failed[0], "mcp:ghost: ") {
+		t.Errorf("failed = %v, want [mcp:ghost: ...]", st.Failed)
+	}
+}
+
+func TestApplyConfig_RecordsError(t *testing.T) {
+	a := newConfigApplyApp(t)
+
+	if err := a.applyConfig("apiVersion: gosuto/v1\n", "bad-hash"); err == nil {
+		t.Fatal("expected invalid config to be rejected")
+	}
+	st := a.lastConfigApply()
+	if st == nil || st.Result != control.ConfigApplyError || st.Hash != "bad-hash" || st.Message == "" {
+		t.Errorf("status = %+v, want error for bad-hash", st)
+	}
+}
+
+const configApplyNoticesYAML = eventTestGosutoYAML + `features:
+  configApplyNotices: true
+`
+
+func TestApplyConfig_PostsNoticeWhenEnabled(t *testing.T) {
+	a := newConfigApplyApp(t)
+	sender := &recordingMatrixSender{}
+	a.eventSender = sender
+
+	if err := a.applyConfig(configApplyNoticesYAML+`mcps:
+  - name: ghost
+    command: /nonexistent/ghost-mcp
+`, "h"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+
+	sends, ok := sender.waitForSend(3 * time.Second)
+	if !ok {
+		t.Fatal("expected a config apply notice")
+	}
+	got := sends[0]
+	if got.roomID != "!admin-room:example.com" {
+		t.Errorf("notice room = %q, want the admin room", got.roomID)
+	}
+	hash := a.gosutoLdr.Hash()[:12]
+	for _, want := range []string{"📥 Applied gosuto " + hash, "1 MCPs, 0 gateways", "failed: mcp:ghost", "warnings"} {
+		if !strings.Contains(got.text, want) {
+			t.Errorf("notice = %q, want it to contain %q", got.text, want)
+		}
+	}
+}
+
+func TestReconcileAtStartup_ReportsFailures(t *testing.T) {
+	a := newConfigApplyApp(t)
+	sender := &recordingMatrixSender{}
+	a.eventSender = sender
+	if err := a.gosutoLdr.Apply([]byte(configApplyBrokenMCPYAML)); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	a.reconcileAtStartup(a.gosutoLdr.Config())
+
+	failures := a.startupFailureList()
+	if len(failures) != 1 || !strings.HasPrefix(failures[0], "mcp:ghost: ") {
+		t.Fatalf("startup failures = %q, want the ghost MCP", failures)
+	}
+	sends, ok := sender.waitForSend(3 * time.Second)
+	if !ok {
+		t.Fatal("expected a startup warning in the admin room")
+	}
+	if got := sends[0]; got.roomID != "!admin-room:example.com" ||
+		!strings.Contains(got.text, "started degraded") || !strings.Contains(got.text, "- mcp:ghost: ") {
+		t.Errorf("warning = %+v", got)
+	}
+}
+
+func TestApplyConfig_ClearsStartupFailures(t *testing.T) {
+	a := newConfigApplyApp(t)
+	if err := a.gosutoLdr.Apply([]byte(configApplyBrokenMCPYAML)); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	a.reconcileAtStartup(a.gosutoLdr.Config())
+	if len(a.startupFailureList()) != 1 {
+		t.Fatalf("startup failures = %q, want the ghost MCP", a.startupFailureList())
+	}
+
+	if err := a.applyConfig(eventTestGosutoYAML, "fixed"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if failures := a.startupFailureList(); failures != nil {
+		t.Errorf("startup failures = %q after a clean apply, want none", failures)
+	}
+}
+
+func TestReconcileAtStartup_CleanBootIsQuiet(t *testing.T) {
+	a := newConfigApplyApp(t)
+	sender := &recordingMatrixSender{}
+	a.eventSender = sender
+
+	a.reconcileAtStartup(a.gosutoLdr.Config())
+
+	if failures := a.startupFailureList(); failures != nil {
+		t.Errorf("startup failures = %q, want none", failures)
+	}
+	if sends, ok := sender.waitForSend(200 * time.Millisecond); ok {
+		t.Errorf("unexpected warning after a clean boot: %+v", sends)
+	}
+}
+
+func TestApplyConfig_NoNoticeByDefault(t *testing.T) {
+	a := newConfigApplyApp(t)
+	sender := &recordingMatrixSender{}
+	a.eventSender = sender
+
+	if err := a.applyConfig(eventTestGosutoYAML, "h"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if sends, ok := sender.waitForSend(200 * time.Millisecond); ok {
+		t.Errorf("unexpected notice with the flag off: %+v", sends)
+	}
+}
+
+func TestConfigApplyNotice_ReconcileSummary(t *testing.T) {
+	c := &gosutospec.Config{
+		MCPs:     []gosutospec.MCPServer{{Name: "docs"}, {Name: "web"}},
+		Gateways: []gosutospec.Gateway{{Name: "cron"}},
+	}
+	got := configApplyNotice("0123456789abcdef", c, control.ConfigApplyStatus{
+		Result:  control.ConfigApplyOK,
+		Started: []string{"mcp:web"},
+		Stopped: []string{"gateway:old"},
+	})
+	want := "📥 Applied gosuto 0123456789ab — 2 MCPs, 1 gateways\nstarted: mcp:web\nstopped: gateway:old"
+	if got != want {
+		t.Errorf("notice = %q, want %q", got, want)
+	}
+}
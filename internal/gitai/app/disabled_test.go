@@ -0,0 +1,119 @@
+package app
+
+// Tests for the metadata.enabled kill switch: a config with enabled: false is
+// applied, but Matrix messages and gateway events are refused while ACP
+// /health and /status keep answering.
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+const disabledGosutoYAML = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+  enabled: false
+trust:
+  allowedRooms:
+    - "!chat-room:example.com"
+  allowedSenders:
+    - "@user:example.com"
+  adminRoom: "!admin-room:example.com"
+persona:
+  llmProvider: openai
+  model: gpt-4o-mini
+  systemPrompt: "You are a helpful test agent."
+`
+
+func TestDisabledConfig_IsApplied(t *testing.T) {
+	a := newEventApp(t, disabledGosutoYAML, newCapturingLLM("ok"))
+
+	cfg := a.gosutoLdr.Config()
+	if cfg == nil {
+		t.Fatal("expected disabled config to be applied")
+	}
+	if cfg.Metadata.IsEnabled() {
+		t.Error("expected metadata.enabled: false to be honoured")
+	}
+	if !a.disabledByConfig() {
+		t.Error("expected disabledByConfig to report true")
+	}
+}
+
+func TestDisabledConfig_RejectsMatrixMessages(t *testing.T) {
+	prov := newCapturingLLM("should not be called")
+	a := newEventApp(t, disabledGosutoYAML, prov)
+
+	evt := makeMessageEvent("!chat-room:example.com", "@user:example.com", "$evt-disabled", "Hey test-agent, hello")
+	a.handleMessage(context.Background(), evt)
+
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("disabled agent must not run an LLM turn for Matrix messages")
+	}
+}
+
+func TestDisabledConfig_RejectsEvents(t *testing.T) {
+	prov := newCapturingLLM("should not be called")
+	a := newEventApp(t, disabledGosutoYAML, prov)
+
+	a.runEventTurn(context.Background(), makeTestEvent("scheduler", "cron.tick", "run"))
+
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("disabled agent must not run an LLM turn for gateway events")
+	}
+}
+
+func TestEnabledDefault_ProcessesEvents(t *testing.T) {
+	prov := newCapturingLLM("done")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+
+	a.runEventTurn(context.Background(), makeTestEvent("scheduler", "cron.tick", "run"))
+
+	if _, ok := prov.waitForCall(3 * time.Second); !ok {
+		t.Fatal("config without metadata.enabled should default to enabled")
+	}
+}
+
+func TestDisabledConfig_ServesHealthAndStatus(t *testing.T) {
+	a := newEventApp(t, disabledGosutoYAML, newCapturingLLM("ok"))
+
+	srv := control.New(":0", control.Handlers{
+		AgentID:    "test-agent",
+		StartedAt:  time.Now(),
+		GosutoHash: a.gosutoLdr.Hash,
+		MCPNames:   func() []string { return nil },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/health: expected 200, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/status: expected 200, got %d", resp.StatusCode)
+	}
+	var status control.StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status.GosutoHash != a.gosutoLdr.Hash() {
+		t.Errorf("status hash = %q, want applied hash %q", status.GosutoHash, a.gosutoLdr.Hash())
+	}
+}
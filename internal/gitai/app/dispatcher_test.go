@@ -7,11 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bdobrica/Ruriko/common/policy"
 	"github.com/bdobrica/Ruriko/common/trace"
 	"github.com/bdobrica/Ruriko/internal/gitai/approvals"
 	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
 	"github.com/bdobrica/Ruriko/internal/gitai/gosuto"
-	"github.com/bdobrica/Ruriko/internal/gitai/policy"
 	"github.com/bdobrica/Ruriko/internal/gitai/store"
 	"github.com/bdobrica/Ruriko/internal/gitai/supervisor"
 )
@@ -106,7 +106,7 @@ trust:
 approvals:
   enabled: true
   room: "!approvals-room:example.com"
-  ttlSeconds: 30
+  ttlSeconds: 60
 capabilities:
   - name: approve-matrix-send
     mcp: builtin
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/bdobrica/Ruriko/common/spec/envelope"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+// defaultEventDLQMaxRetries is used when Config.EventDLQMaxRetries is unset.
+const defaultEventDLQMaxRetries = 3
+
+// defaultEventDLQMaxEntries is used when Config.EventDLQMaxEntries is unset.
+const defaultEventDLQMaxEntries = 1000
+
+// dlqEnabled reports whether failed event turns are dead-lettered.
+func (a *App) dlqEnabled() bool {
+	return a.cfg != nil && a.cfg.EventDLQEnabled
+}
+
+// dlqMaxRetries returns the effective per-entry manual retry cap.
+func (a *App) dlqMaxRetries() int {
+	if a.cfg == nil || a.cfg.EventDLQMaxRetries <= 0 {
+		return defaultEventDLQMaxRetries
+	}
+	return a.cfg.EventDLQMaxRetries
+}
+
+// dlqMaxEntries returns the number of dead-lettered events kept.
+func (a *App) dlqMaxEntries() int {
+	if a.cfg == nil || a.cfg.EventDLQMaxEntries <= 0 {
+		return defaultEventDLQMaxEntries
+	}
+	return a.cfg.EventDLQMaxEntries
+}
+
+// deadLetterEvent records evt in the dead-letter queue after its turn failed
+// with turnErr, dropping the oldest entries beyond the configured cap. It is
+// a no-op when the DLQ is disabled.
+func (a *App) deadLetterEvent(evt *envelope.Event, turnErr error) {
+	if !a.dlqEnabled() {
+		return
+	}
+	raw, err := json.Marshal(evt)
+	if err != nil {
+		slog.Error("dlq: marshal event", "source", evt.Source, "type", evt.Type, "err", err)
+		return
+	}
+	id, err := a.db.AddDeadLetter(evt.Source, evt.Type, string(raw), turnErr.Error(), a.dlqMaxEntries())
+	if err != nil {
+		slog.Error("dlq: record event", "source", evt.Source, "type", evt.Type, "err", err)
+		return
+	}
+	slog.Warn("event turn failed; dead-lettered",
+		"source", evt.Source, "type", evt.Type, "dlq_id", id, "err", turnErr)
+}
+
+// listDeadLetters implements control.Handlers.ListDeadLetters.
+func (a *App) listDeadLetters() (control.DeadLetterListResponse, error) {
+	resp := control.DeadLetterListResponse{
+		Enabled:    a.dlqEnabled(),
+		MaxRetries: a.dlqMaxRetries(),
+	}
+	entries, err := a.db.ListDeadLetters()
+	if err != nil {
+		return resp, err
+	}
+	for _, d := range entries {
+		resp.Entries = append(resp.Entries, control.DeadLetter{
+			ID:        d.ID,
+			Source:    d.Source,
+			EventType: d.EventType,
+			Error:     d.Error,
+			Retries:   d.Retries,
+			CreatedAt: d.CreatedAt,
+			FailedAt:  d.LastFailedAt,
+		})
+	}
+	return resp, nil
+}
+
+// retryDeadLetter implements control.Handlers.RetryDeadLetter. The retry is
+// claimed synchronously (so the cap is enforced before the caller gets a
+// response) and the turn itself runs in the background, like a fresh event.
+// A successful turn removes the entry; a failed one records the new error.
+func (a *App) retryDeadLetter(id int64) error {
+	d, found, err := a.db.GetDeadLetter(id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return control.ErrDeadLetterNotFound
+	}
+	var evt envelope.Event
+	if err := json.Unmarshal([]byte(d.EventJSON), &evt); err != nil {
+		return fmt.Errorf("decode dead-lettered event %d: %w", id, err)
+	}
+	claimed, err := a.db.ClaimDeadLetterRetry(id, a.dlqMaxRetries())
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return control.ErrDeadLetterRetryLimit
+	}
+
+	go func() {
+		if err := a.runEventTurn(context.Background(), &evt); err != nil {
+			slog.Warn("dlq retry failed", "dlq_id", id, "source", evt.Source, "err", err)
+			if rerr := a.db.RecordDeadLetterFailure(id, err.Error()); rerr != nil {
+				slog.Error("dlq: record retry failure", "dlq_id", id, "err", rerr)
+			}
+			return
+		}
+		slog.Info("dlq retry succeeded", "dlq_id", id, "source", evt.Source)
+		if derr := a.db.DeleteDeadLetter(id); derr != nil {
+			slog.Error("dlq: delete entry", "dlq_id", id, "err", derr)
+		}
+	}()
+	return nil
+}
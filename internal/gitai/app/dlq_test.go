@@ -0,0 +1,173 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// flakyLLM fails its first `failures` calls and succeeds afterwards.
+type flakyLLM struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (f *flakyLLM) Complete(_ context.Context, _ llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("upstream unavailable")
+	}
+	return &llm.CompletionResponse{
+		Message:      llm.Message{Role: llm.RoleAssistant, Content: "done"},
+		FinishReason: "stop",
+	}, nil
+}
+
+func (f *flakyLLM) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func newDLQApp(t *testing.T, prov llm.Provider, maxRetries int) *App {
+	t.Helper()
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.cfg = &Config{EventDLQEnabled: true, EventDLQMaxRetries: maxRetries}
+	return a
+}
+
+// waitForDLQ polls the dead-letter queue until cond holds or the deadline
+// elapses, returning the last listing.
+func waitForDLQ(t *testing.T, a *App, cond func(control.DeadLetterListResponse) bool) control.DeadLetterListResponse {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		resp, err := a.listDeadLetters()
+		if err != nil {
+			t.Fatalf("listDeadLetters: %v", err)
+		}
+		if cond(resp) || time.Now().After(deadline) {
+			return resp
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDLQ_FailedTurnIsDeadLettered(t *testing.T) {
+	prov := &flakyLLM{failures: 1}
+	a := newDLQApp(t, prov, 3)
+
+	a.handleEvent(context.Background(), makeTestEvent("scheduler", "cron.tick", "run"))
+
+	resp := waitForDLQ(t, a, func(r control.DeadLetterListResponse) bool { return len(r.Entries) == 1 })
+	if len(resp.Entries) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(resp.Entries))
+	}
+	d := resp.Entries[0]
+	if d.Source != "scheduler" || d.EventType != "cron.tick" {
+		t.Errorf("entry = %s/%s, want scheduler/cron.tick", d.Source, d.EventType)
+	}
+	if d.Retries != 0 {
+		t.Errorf("retries = %d, want 0", d.Retries)
+	}
+	if d.Error == "" {
+		t.Error("expected the turn error to be recorded")
+	}
+	if !resp.Enabled || resp.MaxRetries != 3 {
+		t.Errorf("enabled=%v max=%d, want true/3", resp.Enabled, resp.MaxRetries)
+	}
+}
+
+func TestDLQ_DisabledDoesNotRecord(t *testing.T) {
+	prov := &flakyLLM{failures: 1}
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+
+	if err := a.runEventTurn(context.Background(), makeTestEvent("scheduler", "cron.tick", "run")); err == nil {
+		t.Fatal("expected turn error")
+	}
+	a.deadLetterEvent(makeTestEvent("scheduler", "cron.tick", "run"), errors.New("boom"))
+
+	resp, err := a.listDeadLetters()
+	if err != nil {
+		t.Fatalf("listDeadLetters: %v", err)
+	}
+	if resp.Enabled || len(resp.Entries) != 0 {
+		t.Errorf("enabled=%v entries=%d, want disabled and empty", resp.Enabled, len(resp.Entries))
+	}
+}
+
+func TestDLQ_MaxEntriesDropsOldest(t *testing.T) {
+	a := newDLQApp(t, &flakyLLM{}, 3)
+	a.cfg.EventDLQMaxEntries = 2
+
+	for _, typ := range []string{"first", "second", "third"} {
+		a.deadLetterEvent(makeTestEvent("scheduler", typ, "run"), errors.New("boom"))
+	}
+
+	resp, err := a.listDeadLetters()
+	if err != nil {
+		t.Fatalf("listDeadLetters: %v", err)
+	}
+	if len(resp.Entries) != 2 || resp.Entries[0].EventType != "second" || resp.Entries[1].EventType != "third" {
+		t.Errorf("kept entries = %+v, want second and third", resp.Entries)
+	}
+}
+
+func TestDLQ_RetryRepostsAndClearsOnSuccess(t *testing.T) {
+	prov := &flakyLLM{failures: 1}
+	a := newDLQApp(t, prov, 3)
+
+	a.handleEvent(context.Background(), makeTestEvent("scheduler", "cron.tick", "run"))
+	resp := waitForDLQ(t, a, func(r control.DeadLetterListResponse) bool { return len(r.Entries) == 1 })
+	if len(resp.Entries) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(resp.Entries))
+	}
+
+	if err := a.retryDeadLetter(resp.Entries[0].ID); err != nil {
+		t.Fatalf("retryDeadLetter: %v", err)
+	}
+	resp = waitForDLQ(t, a, func(r control.DeadLetterListResponse) bool { return len(r.Entries) == 0 })
+	if len(resp.Entries) != 0 {
+		t.Fatalf("expected entry to be removed after successful retry, got %+v", resp.Entries)
+	}
+	if got := prov.callCount(); got != 2 {
+		t.Errorf("LLM calls = %d, want 2 (original + retry)", got)
+	}
+}
+
+func TestDLQ_RetryCap(t *testing.T) {
+	prov := &flakyLLM{failures: 100}
+	a := newDLQApp(t, prov, 1)
+
+	a.handleEvent(context.Background(), makeTestEvent("scheduler", "cron.tick", "run"))
+	resp := waitForDLQ(t, a, func(r control.DeadLetterListResponse) bool { return len(r.Entries) == 1 })
+	if len(resp.Entries) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(resp.Entries))
+	}
+	id := resp.Entries[0].ID
+
+	if err := a.retryDeadLetter(id); err != nil {
+		t.Fatalf("first retry: %v", err)
+	}
+	resp = waitForDLQ(t, a, func(r control.DeadLetterListResponse) bool {
+		return len(r.Entries) == 1 && r.Entries[0].Retries == 1 && prov.callCount() == 2
+	})
+	if len(resp.Entries) != 1 || resp.Entries[0].Retries != 1 {
+		t.Fatalf("after failed retry: %+v", resp.Entries)
+	}
+
+	if err := a.retryDeadLetter(id); !errors.Is(err, control.ErrDeadLetterRetryLimit) {
+		t.Errorf("second retry err = %v, want ErrDeadLetterRetryLimit", err)
+	}
+	if err := a.retryDeadLetter(id + 100); !errors.Is(err, control.ErrDeadLetterNotFound) {
+		t.Errorf("unknown id err = %v, want ErrDeadLetterNotFound", err)
+	}
+}
@@ -18,10 +18,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bdobrica/Ruriko/common/policy"
+	"github.com/bdobrica/Ruriko/common/ratelimit"
 	"github.com/bdobrica/Ruriko/common/spec/envelope"
+	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
 	"github.com/bdobrica/Ruriko/internal/gitai/gosuto"
 	"github.com/bdobrica/Ruriko/internal/gitai/llm"
-	"github.com/bdobrica/Ruriko/internal/gitai/policy"
 	"github.com/bdobrica/Ruriko/internal/gitai/store"
 	"github.com/bdobrica/Ruriko/internal/gitai/supervisor"
 )
@@ -160,6 +162,8 @@ func newEventApp(t *testing.T, gosutoYAML string, prov llm.Provider) *App {
 		policyEng: policy.New(ldr),
 		cancelCh:  make(chan struct{}, 1),
 		// matrixCli intentionally nil — runEventTurn guards sends with nil check
+		senderRL:       ratelimit.NewKeyedFixedWindow(time.Minute),
+		senderRLNotice: ratelimit.NewKeyedFixedWindow(time.Minute),
 	}
 	a.setProvider(prov)
 	return a
@@ -228,6 +232,23 @@ func TestBuildEventMessage_AutoGenerates_WithData(t *testing.T) {
 	}
 }
 
+func TestBuildEventMessage_ListsAttachmentMetadata(t *testing.T) {
+	evt := makeTestEvent("imap", "imap.email", "New email from alice: Q3 report")
+	evt.Payload.Attachments = []envelope.Attachment{
+		{Name: "q3.pdf", MIME: "application/pdf", Size: 2048, URL: "https://files.example.com/q3.pdf"},
+		{Name: "notes.txt", Data: "aGVsbG8="},
+	}
+	got := buildEventMessage(evt)
+	for _, want := range []string{"New email from alice", "event.attachment", "[0] q3.pdf (application/pdf, 2048 bytes)", "[1] notes.txt (unknown type)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("buildEventMessage: got %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "aGVsbG8=") || strings.Contains(got, "files.example.com") {
+		t.Errorf("buildEventMessage must not include attachment content or URLs, got %q", got)
+	}
+}
+
 // --- handleEvent / runEventTurn integration tests ---
 
 // TestHandleEvent_TriggersCronTurnAndLogsIt verifies that a cron event causes
@@ -601,3 +622,19 @@ func TestHandleEvent_AuditRecordsIncludeGatewayMetadata(t *testing.T) {
 		t.Errorf("duration_ms = %d, want >= 0", durationMS)
 	}
 }
+
+func TestGatherTools_EventAttachmentOnlyWithAttachments(t *testing.T) {
+	a := newToolPolicyApp(t, eventTestGosutoYAML)
+	a.builtinReg.Register(builtin.NewEventAttachmentTool(nil))
+
+	defs, _ := a.gatherTools(context.Background())
+	if hasToolDef(defs, builtin.EventAttachmentToolName) {
+		t.Error("event.attachment exposed on a turn without attachments")
+	}
+
+	ctx := builtin.WithEventAttachments(context.Background(), []envelope.Attachment{{Name: "a.txt", Data: "YQ=="}})
+	defs, _ = a.gatherTools(ctx)
+	if !hasToolDef(defs, builtin.EventAttachmentToolName) {
+		t.Error("event.attachment not exposed on a turn with attachments")
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+
+	"github.com/bdobrica/Ruriko/common/spec/envelope"
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+// Defaults for Config.EventQueueSize and Config.EventWorkers.
+const (
+	defaultEventQueueSize = 64
+	defaultEventWorkers   = 4
+)
+
+// eventQueue is a bounded FIFO of gateway events drained by a fixed pool of
+// workers. Bounding both the backlog and the concurrency keeps a flooding
+// gateway from growing the agent's memory without limit; the overflow is
+// pushed back to the gateway as 503 instead.
+type eventQueue struct {
+	ch chan queuedEvent
+}
+
+// queuedEvent is an event waiting for a worker, with the trace ID of the
+// request that delivered it. The request context itself is not kept: it is
+// cancelled as soon as ingress answers.
+type queuedEvent struct {
+	traceID string
+	evt     *envelope.Event
+	// done, when set, receives the result of processing evt.
+	done func(error)
+}
+
+// newEventQueue starts workers goroutines that call process for each event.
+// The context passed to process carries the delivering request's trace ID.
+func newEventQueue(size, workers int, process func(context.Context, *envelope.Event) error) *eventQueue {
+	q := &eventQueue{ch: make(chan queuedEvent, size)}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for qe := range q.ch {
+				ctx := context.Background()
+				if qe.traceID != "" {
+					ctx = trace.WithTraceID(ctx, qe.traceID)
+				}
+				err := process(ctx, qe.evt)
+				if qe.done != nil {
+					qe.done(err)
+				}
+			}
+		}()
+	}
+	return q
+}
+
+// enqueue adds evt to the queue without blocking; done, if not nil, is called
+// once a worker has processed it. It returns control.ErrEventQueueFull when
+// the queue is at capacity.
+func (q *eventQueue) enqueue(ctx context.Context, evt *envelope.Event, done func(error)) error {
+	select {
+	case q.ch <- queuedEvent{traceID: trace.FromContext(ctx), evt: evt, done: done}:
+		return nil
+	default:
+		return control.ErrEventQueueFull
+	}
+}
+
+// stats returns the number of queued events and the queue capacity.
+func (q *eventQueue) stats() (depth, capacity int) {
+	return len(q.ch), cap(q.ch)
+}
+
+// events returns the app's event queue, starting its workers on first use.
+func (a *App) events() *eventQueue {
+	a.eventQOnce.Do(func() {
+		size, workers := defaultEventQueueSize, defaultEventWorkers
+		if a.cfg != nil && a.cfg.EventQueueSize > 0 {
+			size = a.cfg.EventQueueSize
+		}
+		if a.cfg != nil && a.cfg.EventWorkers > 0 {
+			workers = a.cfg.EventWorkers
+		}
+		a.eventQ = newEventQueue(size, workers, a.processEvent)
+	})
+	return a.eventQ
+}
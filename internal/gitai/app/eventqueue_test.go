@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// blockingLLM signals each call on started and blocks until release is closed.
+type blockingLLM struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingLLM) Complete(ctx context.Context, _ llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return &llm.CompletionResponse{
+		Message:      llm.Message{Role: llm.RoleAssistant, Content: "done"},
+		FinishReason: "stop",
+	}, nil
+}
+
+func TestEventQueue_OverflowIsRejected(t *testing.T) {
+	prov := &blockingLLM{started: make(chan struct{}, 8), release: make(chan struct{})}
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.cfg = &Config{EventQueueSize: 1, EventWorkers: 1}
+	defer close(prov.release)
+
+	// The first event occupies the only worker...
+	if err := a.handleEvent(context.Background(), makeTestEvent("scheduler", "cron.tick", "one")); err != nil {
+		t.Fatalf("first event: %v", err)
+	}
+	select {
+	case <-prov.started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("worker did not pick up the first event")
+	}
+
+	// ...the second fills the queue...
+	if err := a.handleEvent(context.Background(), makeTestEvent("scheduler", "cron.tick", "two")); err != nil {
+		t.Fatalf("second event: %v", err)
+	}
+	if depth, capacity := a.events().stats(); depth != 1 || capacity != 1 {
+		t.Errorf("stats = (%d, %d), want (1, 1)", depth, capacity)
+	}
+
+	// ...and the third overflows.
+	err := a.handleEvent(context.Background(), makeTestEvent("scheduler", "cron.tick", "three"))
+	if !errors.Is(err, control.ErrEventQueueFull) {
+		t.Fatalf("third event err = %v, want ErrEventQueueFull", err)
+	}
+}
+
+func TestEventQueue_Defaults(t *testing.T) {
+	a := newEventApp(t, eventTestGosutoYAML, newCapturingLLM("ok"))
+
+	if depth, capacity := a.events().stats(); depth != 0 || capacity != defaultEventQueueSize {
+		t.Errorf("stats = (%d, %d), want (0, %d)", depth, capacity, defaultEventQueueSize)
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+// fake_mcp_test.go — a fake stdio MCP server for gatherTools / tool-dispatch
+// tests. The test binary re-executes itself as the MCP process (the standard
+// helper-process pattern), so tests exercise the real supervisor and client.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/mcp"
+)
+
+const (
+	fakeMCPEnv    = "GITAI_FAKE_MCP"
+	fakeMCPLogEnv = "GITAI_FAKE_MCP_LOG"
+	// fakeMCPReadyEnv, when set, names a file: until it exists every
+	// tools/call returns a tool error, as from a server still loading.
+	fakeMCPReadyEnv = "GITAI_FAKE_MCP_READY_FILE"
+)
+
+// fakeMCPTools are the tools advertised by the fake MCP server.
+var fakeMCPTools = []mcp.Tool{
+	{
+		Name:        "search",
+		Description: "Search the index.",
+		InputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"query"},
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{"type": "string"},
+				"limit": map[string]interface{}{"type": "integer", "minimum": 1},
+			},
+		},
+	},
+	{Name: "fetch", Description: "Fetch a document."},
+	{Name: "delete_all", Description: "Delete every document."},
+}
+
+// fakeMCPResources are the resources advertised by the fake MCP server.
+var fakeMCPResources = []mcp.Resource{
+	{URI: "docs://handbook", Name: "Handbook", Description: "Team handbook.", MIME: "text/plain"},
+}
+
+// TestFakeMCPServerProcess is not a real test: it is the body of the fake MCP
+// process started by startFakeMCP and returns immediately otherwise.
+func TestFakeMCPServerProcess(t *testing.T) {
+	if os.Getenv(fakeMCPEnv) != "1" {
+		return
+	}
+	out := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     *int64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue // notifications need no response
+		}
+		resp := mcp.Response{JSONRPC: "2.0", ID: *req.ID}
+		switch req.Method {
+		case "initialize":
+			resp.Result = mcp.InitializeResult{
+				ProtocolVersion: "2024-11-05",
+				ServerInfo:      mcp.ServerInfo{Name: "fake-mcp", Version: "1"},
+			}
+		case "tools/list":
+			resp.Result = mcp.ListToolsResult{Tools: fakeMCPTools}
+		case "resources/list":
+			resp.Result = mcp.ListResourcesResult{Resources: fakeMCPResources}
+		case "resources/read":
+			var p mcp.ReadResourceParams
+			_ = json.Unmarshal(req.Params, &p)
+			logFakeMCPCall("read:" + p.URI)
+			if p.URI != "docs://handbook" {
+				resp.Error = &mcp.ResponseError{Code: -32002, Message: "resource not found: " + p.URI}
+				break
+			}
+			resp.Result = mcp.ReadResourceResult{Contents: []mcp.ResourceContents{
+				{URI: p.URI, MIME: "text/plain", Text: "Be kind. Ship often."},
+			}}
+		case "tools/call":
+			var p mcp.CallToolParams
+			_ = json.Unmarshal(req.Params, &p)
+			logFakeMCPCall(p.Name)
+			if ready := os.Getenv(fakeMCPReadyEnv); ready != "" {
+				if _, err := os.Stat(ready); err != nil {
+					resp.Result = mcp.CallToolResult{Content: []mcp.ContentItem{{Type: "text", Text: "still loading"}}, IsError: true}
+					break
+				}
+			}
+			resp.Result = mcp.CallToolResult{Content: []mcp.ContentItem{{Type: "text", Text: "ok:" + p.Name}}}
+		default:
+			resp.Error = &mcp.ResponseError{Code: -32601, Message: "method not found: " + req.Method}
+		}
+		_ = out.Encode(resp)
+	}
+	os.Exit(0)
+}
+
+// logFakeMCPCall appends a tools/call name to the file named by fakeMCPLogEnv.
+func logFakeMCPCall(name string) {
+	path := os.Getenv(fakeMCPLogEnv)
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, name)
+}
+
+// startFakeMCP starts the fake MCP server under a.supv as name and returns a
+// function reporting the tool names it has received tools/call requests for
+// (and "read:<uri>" for each resources/read).
+func startFakeMCP(t *testing.T, a *App, name string) (calls func() []string) {
+	t.Helper()
+	logPath := filepath.Join(t.TempDir(), "calls.log")
+	a.supv.Reconcile([]gosutospec.MCPServer{{
+		Name:    name,
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeMCPServerProcess$"},
+		Env:     map[string]string{fakeMCPEnv: "1", fakeMCPLogEnv: logPath},
+	}})
+	deadline := time.Now().Add(5 * time.Second)
+	for a.supv.Get(name) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("fake MCP %q did not start", name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return func() []string {
+		b, err := os.ReadFile(logPath)
+		if err != nil {
+			return nil
+		}
+		return strings.Fields(string(b))
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"log/slog"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/observability"
+)
+
+// Feature flag names read by the app. The recognised set, with kinds and
+// defaults, is gosutospec.KnownFeatures.
+const (
+	featureToolSchemaValidation = "toolSchemaValidation"
+	featureResourceTools        = "resourceTools"
+	featureConfigApplyNotices   = "configApplyNotices"
+	featureLogLevel             = "logLevel"
+)
+
+// featureFlags reads agent feature flags from the live Gosuto config, so a
+// config push changes behaviour on the next read without a restart. Flags
+// missing from the config (or with no config loaded) take their defaults.
+type featureFlags struct {
+	ldr *gosuto.Loader
+}
+
+// Bool returns the value of a boolean flag.
+func (f featureFlags) Bool(name string) bool {
+	return f.ldr.Config().FeatureBool(name)
+}
+
+// String returns the value of a string flag.
+func (f featureFlags) String(name string) string {
+	return f.ldr.Config().FeatureString(name)
+}
+
+// features returns the app's feature flag registry.
+func (a *App) features() featureFlags {
+	return featureFlags{ldr: a.gosutoLdr}
+}
+
+// applyFeatures applies flags whose effect is not read on demand. It is
+// called after every config apply.
+func (a *App) applyFeatures() {
+	lvl := a.features().String(featureLogLevel)
+	if lvl == "" && a.cfg != nil {
+		lvl = a.cfg.LogLevel
+	}
+	observability.SetLevel(lvl)
+	if cfg := a.gosutoLdr.Config(); cfg != nil && len(cfg.Features) > 0 {
+		slog.Info("feature flags applied", "features", cfg.Features)
+	}
+}
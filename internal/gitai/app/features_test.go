@@ -0,0 +1,96 @@
+package app
+
+// Tests for agent feature flags delivered via Gosuto and applied live.
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
+	"github.com/bdobrica/Ruriko/internal/gitai/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/observability"
+)
+
+// pushConfig applies yaml the way the ACP config handler does and runs the
+// feature hook, without restarting anything.
+func pushConfig(t *testing.T, a *App, yaml string) {
+	t.Helper()
+	if err := a.gosutoLdr.Apply([]byte(yaml)); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	a.applyFeatures()
+}
+
+func TestFeatures_DefaultsWithoutConfig(t *testing.T) {
+	f := featureFlags{ldr: gosuto.New()}
+	if !f.Bool(featureToolSchemaValidation) || !f.Bool(featureResourceTools) {
+		t.Error("boolean flags should take their defaults with no config loaded")
+	}
+	if f.String(featureLogLevel) != "" {
+		t.Errorf("logLevel = %q, want empty default", f.String(featureLogLevel))
+	}
+}
+
+func TestFeatures_ToolSchemaValidationToggledLive(t *testing.T) {
+	a, calls := newToolSchemaApp(t)
+	ctx := context.Background()
+	invalid := llmToolCall("docs__search", `{"limit":5}`)
+
+	var argsErr *ToolArgsError
+	if _, err := a.executeToolCall(ctx, "!room", "@user:example.com", invalid); !errors.As(err, &argsErr) {
+		t.Fatalf("with default flags: err = %v, want *ToolArgsError", err)
+	}
+
+	pushConfig(t, a, toolSchemaTestGosutoYAML+"features:\n  toolSchemaValidation: false\n")
+	if _, err := a.executeToolCall(ctx, "!room", "@user:example.com", invalid); err != nil {
+		t.Fatalf("with validation disabled: %v", err)
+	}
+	if c := calls(); len(c) != 1 {
+		t.Errorf("MCP calls = %v, want the unvalidated call dispatched", c)
+	}
+
+	pushConfig(t, a, toolSchemaTestGosutoYAML)
+	if _, err := a.executeToolCall(ctx, "!room", "@user:example.com", invalid); !errors.As(err, &argsErr) {
+		t.Errorf("after re-enabling: err = %v, want *ToolArgsError", err)
+	}
+}
+
+func TestFeatures_ResourceToolsToggledLive(t *testing.T) {
+	a, _ := newResourceApp(t, resourceTestGosutoYAML)
+
+	defs, _ := a.gatherTools(context.Background())
+	if !hasToolDef(defs, builtin.ResourceReadToolName) {
+		t.Fatal("resource.read should be offered by default")
+	}
+
+	pushConfig(t, a, resourceTestGosutoYAML+"features:\n  resourceTools: false\n")
+	defs, _ = a.gatherTools(context.Background())
+	if hasToolDef(defs, builtin.ResourceReadToolName) {
+		t.Error("resource.read offered after resourceTools was switched off")
+	}
+	if _, err := a.DispatchToolCall(context.Background(), ToolDispatchRequest{
+		Caller: dispatchCallerLLM,
+		Name:   builtin.ResourceReadToolName,
+		Args:   map[string]interface{}{"mcp": "docs", "uri": "docs://handbook"},
+	}); err == nil {
+		t.Error("resource.read dispatched after resourceTools was switched off")
+	}
+}
+
+func TestFeatures_LogLevelAppliedLive(t *testing.T) {
+	t.Cleanup(func() { observability.SetLevel("info") })
+	a := newToolPolicyApp(t, eventTestGosutoYAML)
+	a.cfg = &Config{LogLevel: "warn"}
+
+	pushConfig(t, a, eventTestGosutoYAML+"features:\n  logLevel: debug\n")
+	if got := observability.Level(); got != slog.LevelDebug {
+		t.Errorf("level = %v, want debug", got)
+	}
+
+	pushConfig(t, a, eventTestGosutoYAML)
+	if got := observability.Level(); got != slog.LevelWarn {
+		t.Errorf("level = %v, want LOG_LEVEL (warn) once the flag is removed", got)
+	}
+}
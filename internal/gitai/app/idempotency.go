@@ -0,0 +1,34 @@
+package app
+
+import (
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/store"
+)
+
+// saveIdempotency implements control.Handlers.SaveIdempotency.
+func (a *App) saveIdempotency(rec control.IdempotencyRecord) error {
+	return a.db.SaveIdempotencyEntry(store.IdempotencyEntry{
+		Key:       rec.Key,
+		Status:    rec.Status,
+		Body:      rec.Body,
+		ExpiresAt: rec.ExpiresAt,
+	})
+}
+
+// loadIdempotency implements control.Handlers.LoadIdempotency.
+func (a *App) loadIdempotency() ([]control.IdempotencyRecord, error) {
+	entries, err := a.db.LoadIdempotencyEntries()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]control.IdempotencyRecord, 0, len(entries))
+	for _, e := range entries {
+		records = append(records, control.IdempotencyRecord{
+			Key:       e.Key,
+			Status:    e.Status,
+			Body:      e.Body,
+			ExpiresAt: e.ExpiresAt,
+		})
+	}
+	return records, nil
+}
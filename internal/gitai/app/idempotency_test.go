@@ -0,0 +1,101 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/store"
+)
+
+func TestIdempotency_ReplaySurvivesRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "gitai.db")
+	db, err := store.New(dbPath)
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	a := &App{db: db}
+
+	calls := 0
+	newServer := func() *httptest.Server {
+		srv := control.New(":0", control.Handlers{
+			AgentID:         "test",
+			StartedAt:       time.Now(),
+			SaveIdempotency: a.saveIdempotency,
+			LoadIdempotency: a.loadIdempotency,
+			ApplyConfig: func(yaml, hash string) error {
+				calls++
+				return nil
+			},
+		})
+		ts := httptest.NewServer(srv.TestHandler())
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	post := func(ts *httptest.Server) int {
+		t.Helper()
+		const yaml = "metadata:\n  name: test"
+		sum := sha256.Sum256([]byte(yaml))
+		body, _ := json.Marshal(control.ConfigApplyRequest{YAML: yaml, Hash: hex.EncodeToString(sum[:])})
+		req, _ := http.NewRequest("POST", ts.URL+"/config/apply", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Idempotency-Key", "idem-restart")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /config/apply: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post(newServer()); code != http.StatusOK {
+		t.Fatalf("first apply: status %d", code)
+	}
+
+	// Simulate a restart: reopen the database and build a fresh server that
+	// reloads the cache from it.
+	db.Close()
+	if db, err = store.New(dbPath); err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	a.db = db
+
+	if code := post(newServer()); code != http.StatusOK {
+		t.Fatalf("replay: status %d", code)
+	}
+	if calls != 1 {
+		t.Errorf("ApplyConfig called %d times; want 1 (replay after restart must hit the cache)", calls)
+	}
+}
+
+func TestLoadIdempotency_DropsExpiredEntries(t *testing.T) {
+	db, err := store.New(filepath.Join(t.TempDir(), "gitai.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	a := &App{db: db}
+
+	if err := a.saveIdempotency(control.IdempotencyRecord{Key: "old", Status: 200, ExpiresAt: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("save expired: %v", err)
+	}
+	if err := a.saveIdempotency(control.IdempotencyRecord{Key: "live", Status: 202, Body: []byte(`{"status":"restarting"}`), ExpiresAt: time.Now().Add(time.Minute)}); err != nil {
+		t.Fatalf("save live: %v", err)
+	}
+
+	records, err := a.loadIdempotency()
+	if err != nil {
+		t.Fatalf("loadIdempotency: %v", err)
+	}
+	if len(records) != 1 || records[0].Key != "live" || records[0].Status != 202 || string(records[0].Body) != `{"status":"restarting"}` {
+		t.Errorf("records = %+v, want only the live entry", records)
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+// Tests for inbound sender filtering: the agent drops its own messages and
+// messages from trust.ignoreSenders before running a turn.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/matrix"
+)
+
+const ignoreSendersGosutoYAML = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms:
+    - "!chat-room:example.com"
+  allowedSenders:
+    - "*"
+  ignoreSenders:
+    - "@otherbot:example.com"
+persona:
+  llmProvider: openai
+  model: gpt-4o-mini
+  systemPrompt: "You are a helpful test agent."
+`
+
+func newIgnoreSendersApp(t *testing.T, prov *capturingLLM) *App {
+	t.Helper()
+	a := newEventApp(t, ignoreSendersGosutoYAML, prov)
+	a.cfg = &Config{Matrix: matrix.Config{UserID: "@test-agent:example.com"}}
+	return a
+}
+
+func TestHandleMessage_DropsOwnMessages(t *testing.T) {
+	prov := newCapturingLLM("should not be called")
+	a := newIgnoreSendersApp(t, prov)
+
+	a.handleMessage(context.Background(), makeMessageEvent("!chat-room:example.com", "@test-agent:example.com", "$evt-self", "Hey test-agent, hello"))
+
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("agent must not run a turn for its own message")
+	}
+}
+
+func TestHandleMessage_DropsIgnoredSenders(t *testing.T) {
+	prov := newCapturingLLM("should not be called")
+	a := newIgnoreSendersApp(t, prov)
+
+	a.handleMessage(context.Background(), makeMessageEvent("!chat-room:example.com", "@otherbot:example.com", "$evt-bot", "Hey test-agent, hello"))
+
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("agent must not run a turn for a sender in trust.ignoreSenders")
+	}
+}
+
+func TestHandleMessage_ProcessesOtherSenders(t *testing.T) {
+	prov := newCapturingLLM("hi")
+	a := newIgnoreSendersApp(t, prov)
+
+	a.handleMessage(context.Background(), makeMessageEvent("!chat-room:example.com", "@user:example.com", "$evt-user", "Hey test-agent, hello"))
+
+	if _, ok := prov.waitForCall(3 * time.Second); !ok {
+		t.Fatal("expected a turn for a sender that is neither self nor ignored")
+	}
+}
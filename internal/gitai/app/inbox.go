@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	"maunium.net/go/mautrix/event"
+)
+
+// matrixInboxSize is how many inbound messages may wait for the message
+// worker before the Matrix sync loop blocks.
+const matrixInboxSize = 64
+
+// onMatrixMessage is the Matrix client's message handler. It runs on the
+// sync goroutine, so it only routes: replies to pending agent.request calls
+// are delivered here, because the turn waiting for them occupies the message
+// worker; every other message is queued for runInbox.
+func (a *App) onMatrixMessage(ctx context.Context, evt *event.Event) {
+	if a.deliverAgentReply(ctx, evt) {
+		return
+	}
+	select {
+	case a.inbox <- evt:
+	case <-ctx.Done():
+	}
+}
+
+// runInbox hands queued messages to handleMessage one at a time, in arrival
+// order, until ctx is cancelled.
+func (a *App) runInbox(ctx context.Context) {
+	for {
+		select {
+		case evt := <-a.inbox:
+			a.handleMessage(ctx, evt)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliverAgentReply hands evt to the agent.request call it answers, if any,
+// and reports whether it did. The sender must pass the same ignore and allow
+// checks as any other message before it can resolve a request.
+func (a *App) deliverAgentReply(ctx context.Context, evt *event.Event) bool {
+	msg := evt.Content.AsMessage()
+	if msg == nil || msg.RelatesTo.GetReplaceID() != "" {
+		return false
+	}
+	roomID := evt.RoomID.String()
+	sender := evt.Sender.String()
+	if a.isSelf(sender) {
+		return false
+	}
+	if c := a.canaryFor(roomID); c != nil {
+		ctx = withCanary(ctx, c)
+	}
+	pol := a.policyFor(ctx)
+	if pol.IsSenderIgnored(sender) || !pol.IsSenderAllowed(sender) {
+		return false
+	}
+	if !a.agentReplies.Deliver(roomID, sender, msg.Body) {
+		return false
+	}
+	slog.Debug("agent.request reply delivered", "room", roomID, "sender", sender)
+	return true
+}
@@ -0,0 +1,122 @@
+package app
+
+// Tests for the per-MCP tool allowlist and description overrides applied by
+// gatherTools.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+const mcpToolsTestGosutoYAML = eventTestGosutoYAML + `mcps:
+  - name: docs
+    command: fake
+    tools:
+      - name: search
+        description: "Search the team knowledge base. Prefer short queries."
+      - name: fetch
+`
+
+func toolDef(defs []llm.ToolDefinition, name string) (llm.ToolDefinition, bool) {
+	for _, d := range defs {
+		if d.Function.Name == name {
+			return d, true
+		}
+	}
+	return llm.ToolDefinition{}, false
+}
+
+func TestGatherTools_MCPAllowlistHidesUnlistedTools(t *testing.T) {
+	a := newToolPolicyApp(t, mcpToolsTestGosutoYAML)
+	startFakeMCP(t, a, "docs")
+
+	defs, toolMap := a.gatherTools(context.Background())
+	for _, name := range []string{"docs__search", "docs__fetch"} {
+		if !hasToolDef(defs, name) {
+			t.Errorf("expected allowlisted tool %q to be exposed", name)
+		}
+	}
+	if hasToolDef(defs, "docs__delete_all") {
+		t.Error("tool not in allowlist was exposed to the LLM")
+	}
+	if _, ok := toolMap["docs__delete_all"]; ok {
+		t.Error("tool not in allowlist was added to the dispatch map")
+	}
+}
+
+func TestGatherTools_MCPAllowlistOverridesDescriptions(t *testing.T) {
+	a := newToolPolicyApp(t, mcpToolsTestGosutoYAML)
+	startFakeMCP(t, a, "docs")
+
+	defs, _ := a.gatherTools(context.Background())
+	search, ok := toolDef(defs, "docs__search")
+	if !ok {
+		t.Fatal("docs__search not exposed")
+	}
+	if want := "Search the team knowledge base. Prefer short queries."; search.Function.Description != want {
+		t.Errorf("search description = %q, want %q", search.Function.Description, want)
+	}
+	fetch, ok := toolDef(defs, "docs__fetch")
+	if !ok {
+		t.Fatal("docs__fetch not exposed")
+	}
+	if fetch.Function.Description != "Fetch a document." {
+		t.Errorf("fetch description = %q, want the MCP's own", fetch.Function.Description)
+	}
+}
+
+func TestGatherTools_NoMCPAllowlistExposesAllTools(t *testing.T) {
+	a := newToolPolicyApp(t, eventTestGosutoYAML)
+	startFakeMCP(t, a, "docs")
+
+	defs, _ := a.gatherTools(context.Background())
+	for _, name := range []string{"docs__search", "docs__fetch", "docs__delete_all"} {
+		if !hasToolDef(defs, name) {
+			t.Errorf("expected %q to be exposed without an allowlist", name)
+		}
+	}
+}
+
+func TestGatherTools_WaitsForMCPReadiness(t *testing.T) {
+	a := newToolPolicyApp(t, eventTestGosutoYAML)
+	readyFile := filepath.Join(t.TempDir(), "ready")
+	a.supv.Reconcile([]gosutospec.MCPServer{{
+		Name:      "docs",
+		Command:   os.Args[0],
+		Args:      []string{"-test.run=^TestFakeMCPServerProcess$"},
+		Env:       map[string]string{fakeMCPEnv: "1", fakeMCPReadyEnv: readyFile},
+		Readiness: &gosutospec.MCPReadiness{Tool: "search", Arguments: map[string]interface{}{"query": "ping"}},
+	}})
+
+	defs, _ := a.gatherTools(context.Background())
+	if hasToolDef(defs, "docs__search") {
+		t.Fatal("tools of an MCP that is not ready were offered")
+	}
+	if got := a.supv.Initializing(); len(got) != 1 || got[0] != "docs" {
+		t.Errorf("initializing = %v, want [docs]", got)
+	}
+
+	if err := os.WriteFile(readyFile, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		defs, _ = a.gatherTools(context.Background())
+		if hasToolDef(defs, "docs__search") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("tools were not offered after the MCP became ready")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if got := a.supv.Initializing(); len(got) != 0 {
+		t.Errorf("initializing = %v after the probe passed, want none", got)
+	}
+}
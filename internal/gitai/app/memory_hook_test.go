@@ -7,9 +7,9 @@ import (
 	"time"
 
 	commonmemory "github.com/bdobrica/Ruriko/common/memory"
+	"github.com/bdobrica/Ruriko/common/policy"
 	"github.com/bdobrica/Ruriko/internal/gitai/gosuto"
 	"github.com/bdobrica/Ruriko/internal/gitai/llm"
-	"github.com/bdobrica/Ruriko/internal/gitai/policy"
 	"github.com/bdobrica/Ruriko/internal/gitai/supervisor"
 )
 
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+)
+
+// matrixToPrefix starts a user pill link in a message's formatted_body.
+const matrixToPrefix = "matrix.to/#/"
+
+// acceptsFreeForm decides whether a free-form message (neither a directive
+// nor a workflow protocol message) starts a turn, according to
+// trust.requireMention. See the Trust.RequireMention doc comment.
+func (a *App) acceptsFreeForm(cfg *gosutospec.Config, msg *event.MessageEventContent) bool {
+	if cfg == nil || cfg.Trust.RequireMention == nil {
+		return false
+	}
+	if !*cfg.Trust.RequireMention {
+		return true
+	}
+	self := ""
+	if a.cfg != nil {
+		self = a.cfg.Matrix.UserID
+	}
+	return mentionsSelf(msg, self, cfg.Trust.MentionKeywords)
+}
+
+// mentionsSelf reports whether msg mentions the user selfMXID or contains one
+// of keywords as a whole word. selfMXID may be empty, in which case only the
+// keywords are checked.
+func mentionsSelf(msg *event.MessageEventContent, selfMXID string, keywords []string) bool {
+	if msg == nil {
+		return false
+	}
+	if selfMXID != "" {
+		if msg.Mentions != nil && msg.Mentions.Has(id.UserID(selfMXID)) {
+			return true
+		}
+		if pillMentions(msg.FormattedBody, selfMXID) {
+			return true
+		}
+		if containsWord(msg.Body, selfMXID) {
+			return true
+		}
+	}
+	for _, kw := range keywords {
+		if kw = strings.TrimSpace(kw); kw != "" && containsWord(msg.Body, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// pillMentions reports whether formatted links to mxid through a matrix.to
+// pill. The link target may be percent-encoded and may carry a query string.
+func pillMentions(formatted, mxid string) bool {
+	rest := formatted
+	for {
+		i := strings.Index(rest, matrixToPrefix)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(matrixToPrefix):]
+		end := strings.IndexAny(rest, "\"'?> ")
+		if end < 0 {
+			end = len(rest)
+		}
+		target, err := url.PathUnescape(rest[:end])
+		if err == nil && strings.EqualFold(target, mxid) {
+			return true
+		}
+	}
+}
+
+// containsWord reports whether needle occurs in haystack, case-insensitively,
+// without a letter or digit directly before or after it.
+func containsWord(haystack, needle string) bool {
+	h, n := strings.ToLower(haystack), strings.ToLower(needle)
+	if n == "" {
+		return false
+	}
+	for start := 0; start <= len(h)-len(n); {
+		i := strings.Index(h[start:], n)
+		if i < 0 {
+			return false
+		}
+		i += start
+		before, _ := utf8.DecodeLastRuneInString(h[:i])
+		after, _ := utf8.DecodeRuneInString(h[i+len(n):])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		start = i + 1
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,137 @@
+package app
+
+// Tests for trust.requireMention: free-form messages are processed only when
+// they mention the agent (option on), always (option off), or never (unset).
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/matrix"
+)
+
+const mentionGosutoYAMLFormat = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms:
+    - "!chat-room:example.com"
+  allowedSenders:
+    - "*"
+%s
+persona:
+  llmProvider: openai
+  model: gpt-4o-mini
+  systemPrompt: "You are a helpful test agent."
+`
+
+func newMentionApp(t *testing.T, trustExtra string, prov *capturingLLM) *App {
+	t.Helper()
+	a := newEventApp(t, fmt.Sprintf(mentionGosutoYAMLFormat, trustExtra), prov)
+	a.cfg = &Config{Matrix: matrix.Config{UserID: "@test-agent:example.com"}}
+	return a
+}
+
+func freeFormEvent(eventID string, content *event.MessageEventContent) *event.Event {
+	content.MsgType = event.MsgText
+	return &event.Event{
+		ID:      id.EventID(eventID),
+		RoomID:  id.RoomID("!chat-room:example.com"),
+		Sender:  id.UserID("@user:example.com"),
+		Content: event.Content{Parsed: content},
+	}
+}
+
+func TestRequireMention_On_ProcessesMentions(t *testing.T) {
+	const requireOn = "  requireMention: true\n  mentionKeywords: [\"helper\"]"
+	cases := map[string]*event.MessageEventContent{
+		"m.mentions": {
+			Body:     "Test agent: what is the status?",
+			Mentions: &event.Mentions{UserIDs: []id.UserID{"@test-agent:example.com"}},
+		},
+		"pill": {
+			Body:          "Test agent: what is the status?",
+			Format:        event.FormatHTML,
+			FormattedBody: `<a href="https://matrix.to/#/%40test-agent%3Aexample.com">Test agent</a>: what is the status?`,
+		},
+		"plain mxid": {Body: "what is the status @test-agent:example.com?"},
+		"keyword":    {Body: "Helper, what is the status?"},
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			prov := newCapturingLLM("ok")
+			a := newMentionApp(t, requireOn, prov)
+
+			a.handleMessage(context.Background(), freeFormEvent("$evt-"+name, content))
+
+			if _, ok := prov.waitForCall(3 * time.Second); !ok {
+				t.Fatal("expected a turn for a message that mentions the agent")
+			}
+		})
+	}
+}
+
+func TestRequireMention_On_IgnoresOtherMessages(t *testing.T) {
+	prov := newCapturingLLM("should not be called")
+	a := newMentionApp(t, "  requireMention: true\n  mentionKeywords: [\"helper\"]", prov)
+
+	a.handleMessage(context.Background(), freeFormEvent("$evt-other", &event.MessageEventContent{
+		Body:          "ping @other-agent:example.com: helpers are busy. What is the status?",
+		Format:        event.FormatHTML,
+		FormattedBody: `<a href="https://matrix.to/#/@other-agent:example.com">other</a>, what is the status?`,
+		Mentions:      &event.Mentions{UserIDs: []id.UserID{"@other-agent:example.com"}},
+	}))
+
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("agent must not run a turn for a message that does not mention it")
+	}
+}
+
+func TestRequireMention_Off_ProcessesAllMessages(t *testing.T) {
+	prov := newCapturingLLM("ok")
+	a := newMentionApp(t, "  requireMention: false", prov)
+
+	a.handleMessage(context.Background(), freeFormEvent("$evt-any", &event.MessageEventContent{Body: "what is the status?"}))
+
+	if _, ok := prov.waitForCall(3 * time.Second); !ok {
+		t.Fatal("expected a turn for every free-form message when requireMention is false")
+	}
+}
+
+func TestRequireMention_Unset_IgnoresFreeForm(t *testing.T) {
+	prov := newCapturingLLM("should not be called")
+	a := newMentionApp(t, "", prov)
+
+	a.handleMessage(context.Background(), freeFormEvent("$evt-unset", &event.MessageEventContent{
+		Body:     "what is the status?",
+		Mentions: &event.Mentions{UserIDs: []id.UserID{"@test-agent:example.com"}},
+	}))
+
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("free-form messages are ignored by default")
+	}
+}
+
+func TestContainsWord(t *testing.T) {
+	cases := []struct {
+		haystack, needle string
+		want             bool
+	}{
+		{"Helper, hi", "helper", true},
+		{"ask the HELPER", "helper", true},
+		{"helpers are busy", "helper", false},
+		{"unhelper", "helper", false},
+		{"ping @bot:example.com!", "@bot:example.com", true},
+		{"ping @bot:example.community", "@bot:example.com", false},
+	}
+	for _, tc := range cases {
+		if got := containsWord(tc.haystack, tc.needle); got != tc.want {
+			t.Errorf("containsWord(%q, %q) = %v, want %v", tc.haystack, tc.needle, got, tc.want)
+		}
+	}
+}
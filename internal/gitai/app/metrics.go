@@ -0,0 +1,47 @@
+package app
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+// appMetrics holds the process-lifetime counters reported by ACP
+// GET /metrics. The zero value is ready to use.
+type appMetrics struct {
+	turns             atomic.Int64
+	toolCalls         atomic.Int64
+	policyDenials     atomic.Int64
+	senderRateLimited atomic.Int64
+
+	mu     sync.Mutex
+	events map[string]int64 // accepted events by source
+}
+
+// eventIngested counts an event from source accepted onto the event queue.
+func (m *appMetrics) eventIngested(source string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.events == nil {
+		m.events = make(map[string]int64)
+	}
+	m.events[source]++
+}
+
+// snapshot implements control.Handlers.Metrics.
+func (m *appMetrics) snapshot() control.Metrics {
+	m.mu.Lock()
+	events := make(map[string]int64, len(m.events))
+	for src, n := range m.events {
+		events[src] = n
+	}
+	m.mu.Unlock()
+	return control.Metrics{
+		TurnsProcessed:    m.turns.Load(),
+		ToolCalls:         m.toolCalls.Load(),
+		PolicyDenials:     m.policyDenials.Load(),
+		EventsIngested:    events,
+		SenderRateLimited: m.senderRateLimited.Load(),
+	}
+}
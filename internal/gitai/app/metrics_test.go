@@ -0,0 +1,27 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/common/ratelimit"
+)
+
+func TestMetrics_CountsTurnsAndSenderRateLimits(t *testing.T) {
+	prov := newCapturingLLM("pong")
+	a, _ := newStreamingApp(t, prov)
+	if err := a.gosutoLdr.Apply([]byte(senderRateGosutoYAML)); err != nil {
+		t.Fatalf("gosuto loader Apply: %v", err)
+	}
+	clock := time.Unix(1_700_000_000, 0)
+	a.senderRL = ratelimit.NewKeyedFixedWindowWithClock(time.Minute, func() time.Time { return clock })
+
+	// maxRequestsPerMinute is 3: alice gets 3 turns and 2 refusals, bob 1 turn.
+	sendFrom(a, prov, "@alice:example.com", 5)
+	sendFrom(a, prov, "@bob:example.com", 1)
+
+	m := a.metrics.snapshot()
+	if m.TurnsProcessed != 4 || m.SenderRateLimited != 2 {
+		t.Errorf("metrics turns = %d, rate limited = %d; want 4 and 2", m.TurnsProcessed, m.SenderRateLimited)
+	}
+}
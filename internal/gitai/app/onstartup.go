@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bdobrica/Ruriko/common/spec/envelope"
+)
+
+// onStartupSource is the event source of the instructions.onStartup turn.
+const onStartupSource = "startup"
+
+// runOnStartup queues the instructions.onStartup action of the current
+// config as an event turn, so it goes through the same policy checks, turn
+// log and admin-room output as a gateway event. It runs once per config
+// version: on the first load after the agent starts and whenever a config
+// with a different hash is applied. The hash is recorded only when the turn
+// completes, so re-applying the same config does not run it again unless the
+// previous run failed; a run still in flight is not queued twice.
+func (a *App) runOnStartup(hash string) {
+	cfg := a.gosutoLdr.Config()
+	if cfg == nil {
+		return
+	}
+	action := strings.TrimSpace(cfg.Instructions.OnStartup)
+	if action == "" {
+		return
+	}
+
+	a.onStartupMu.Lock()
+	if a.onStartupHash == hash || a.onStartupPending == hash {
+		a.onStartupMu.Unlock()
+		return
+	}
+	a.onStartupPending = hash
+	a.onStartupMu.Unlock()
+
+	evt := &envelope.Event{
+		Source:  onStartupSource,
+		Type:    "onStartup",
+		TS:      time.Now().UTC(),
+		Payload: envelope.EventPayload{Message: action},
+	}
+	if err := a.queueEvent(context.Background(), evt, func(err error) { a.finishOnStartup(hash, err) }); err != nil {
+		a.finishOnStartup(hash, err)
+		slog.Warn("onStartup action not queued", "hash", hash, "err", err)
+		return
+	}
+	slog.Info("onStartup action queued", "hash", hash)
+}
+
+// finishOnStartup records the outcome of the onStartup run for hash. A
+// successful run is recorded unless a newer config's run superseded it; a
+// failed one is forgotten so the next apply of that config retries it.
+func (a *App) finishOnStartup(hash string, err error) {
+	a.onStartupMu.Lock()
+	defer a.onStartupMu.Unlock()
+	if a.onStartupPending != hash {
+		return
+	}
+	a.onStartupPending = ""
+	if err == nil {
+		a.onStartupHash = hash
+	}
+}
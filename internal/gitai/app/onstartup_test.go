@@ -0,0 +1,102 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const onStartupGosutoYAML = eventTestGosutoYAML + `instructions:
+  onStartup: "Announce that you are online."
+`
+
+func TestRunOnStartup_RunsOncePerConfigVersion(t *testing.T) {
+	prov := newCapturingLLM("online")
+	a := newConfigApplyApp(t)
+	a.setProvider(prov)
+	if err := a.gosutoLdr.Apply([]byte(onStartupGosutoYAML)); err != nil {
+		t.Fatalf("gosuto loader Apply: %v", err)
+	}
+
+	// First load, as Run does after the initial reconcile.
+	a.runOnStartup(a.gosutoLdr.Hash())
+	req, ok := prov.waitForCall(3 * time.Second)
+	if !ok {
+		t.Fatal("onStartup action did not run on first load")
+	}
+	if last := req.Messages[len(req.Messages)-1]; !strings.Contains(last.Content, "Announce that you are online.") {
+		t.Errorf("turn message = %q, want the onStartup action", last.Content)
+	}
+
+	// Re-applying the identical config must not run it again.
+	if err := a.applyConfig(onStartupGosutoYAML, "same"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if _, ok := prov.waitForCall(200 * time.Millisecond); ok {
+		t.Fatal("onStartup action ran again on an identical re-apply")
+	}
+
+	// A new config version runs it once more.
+	if err := a.applyConfig(onStartupGosutoYAML+"limits:\n  maxRequestsPerMinute: 10\n", "changed"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if _, ok := prov.waitForCall(3 * time.Second); !ok {
+		t.Fatal("onStartup action did not run for a changed config")
+	}
+}
+
+func TestRunOnStartup_NoActionConfigured(t *testing.T) {
+	prov := newCapturingLLM("unused")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+
+	a.runOnStartup(a.gosutoLdr.Hash())
+	if _, ok := prov.waitForCall(200 * time.Millisecond); ok {
+		t.Fatal("a turn ran without instructions.onStartup")
+	}
+}
+
+func TestRunOnStartup_RetriesAfterFailedTurn(t *testing.T) {
+	prov := &flakyLLM{failures: 1}
+	a := newConfigApplyApp(t)
+	a.setProvider(prov)
+	if err := a.gosutoLdr.Apply([]byte(onStartupGosutoYAML)); err != nil {
+		t.Fatalf("gosuto loader Apply: %v", err)
+	}
+	hash := a.gosutoLdr.Hash()
+
+	a.runOnStartup(hash)
+	waitForOnStartup(t, a, func() bool { return a.onStartupPending == "" })
+	if a.onStartupHash == hash {
+		t.Fatal("a failed onStartup turn recorded the config hash")
+	}
+
+	// The same config runs it again, and this time it sticks.
+	a.runOnStartup(hash)
+	waitForOnStartup(t, a, func() bool { return a.onStartupHash == hash })
+	if got := prov.callCount(); got != 2 {
+		t.Errorf("LLM calls = %d, want 2", got)
+	}
+	a.runOnStartup(hash)
+	time.Sleep(100 * time.Millisecond)
+	if got := prov.callCount(); got != 2 {
+		t.Errorf("LLM calls = %d after a completed run, want 2", got)
+	}
+}
+
+// waitForOnStartup polls the onStartup bookkeeping until cond holds.
+func waitForOnStartup(t *testing.T, a *App, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		a.onStartupMu.Lock()
+		ok := cond()
+		a.onStartupMu.Unlock()
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("onStartup run did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -77,6 +77,9 @@ func buildSystemPrompt(cfg *gosutospec.Config, messagingTargets []string, memory
 			for _, peer := range cfg.Instructions.Context.Peers {
 				name := strings.TrimSpace(peer.Name)
 				role := strings.TrimSpace(peer.Role)
+				if mxid := strings.TrimSpace(peer.MXID); mxid != "" && name != "" {
+					name += " (" + mxid + ")"
+				}
 				if name != "" {
 					if role != "" {
 						fmt.Fprintf(&sb, "- **%s**: %s\n", name, role)
@@ -221,6 +221,23 @@ func TestBuildSystemPrompt_PeerAgentsAbsent_NoPeerSection(t *testing.T) {
 	}
 }
 
+func TestBuildSystemPrompt_DeclaredPeerIsMessagingTarget(t *testing.T) {
+	cfg := withInstructions(minimalConfig("kairo", ""), gosutospec.Instructions{
+		Context: gosutospec.InstructionsContext{
+			Peers: []gosutospec.PeerRef{
+				{Name: "kumo", Role: "News agent.", RoomID: "!kumo-admin:localhost", MXID: "@kumo:localhost"},
+			},
+		},
+	})
+	got := buildSystemPrompt(cfg, buildMessagingTargets(cfg), "")
+
+	for _, fragment := range []string{"**kumo (@kumo:localhost)**: News agent.", "## Messaging Targets", "kumo (!kumo-admin:localhost)"} {
+		if !strings.Contains(got, fragment) {
+			t.Errorf("system prompt missing %q\ngot:\n%s", fragment, got)
+		}
+	}
+}
+
 // ── R14.3 composite: persona + instructions (both present) ──────────────────
 
 func TestBuildSystemPrompt_BothPersonaAndInstructions(t *testing.T) {
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/bdobrica/Ruriko/common/retry"
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+	"github.com/bdobrica/Ruriko/common/version"
+)
+
+// registerTimeout bounds a single self-registration attempt.
+const registerTimeout = 10 * time.Second
+
+// registerRetry is the retry policy for self-registration. Ruriko may still
+// be starting when the agent comes up, so attempts are spread over ~1 minute.
+var registerRetry = retry.Config{
+	MaxAttempts:  6,
+	InitialDelay: 2 * time.Second,
+	MaxDelay:     30 * time.Second,
+}
+
+// registerWithRuriko announces this agent's control URL and ACP token to
+// Ruriko when RegisterURL is configured. Failures are logged; the agent keeps
+// running and can still be reached if Ruriko already knows its address.
+func (a *App) registerWithRuriko(ctx context.Context) {
+	if a.cfg.RegisterURL == "" {
+		return
+	}
+	controlURL := a.cfg.AdvertiseURL
+	if controlURL == "" {
+		controlURL = defaultAdvertiseURL(a.cfg.ACPAddr, a.cfg.ACPTLSCertFile != "")
+	}
+	req := acpspec.RegisterRequest{
+		AgentID:    a.cfg.AgentID,
+		ControlURL: controlURL,
+		ACPToken:   a.acpTokens.Current(),
+		Version:    version.Version,
+		GosutoHash: a.gosutoLdr.Hash(),
+	}
+	err := retry.Do(ctx, registerRetry, func() error {
+		return postRegistration(ctx, http.DefaultClient, a.cfg.RegisterURL, a.cfg.RegisterToken, req)
+	})
+	if err != nil {
+		slog.Warn("self-registration with Ruriko failed", "url", a.cfg.RegisterURL, "err", err)
+		return
+	}
+	slog.Info("registered with Ruriko", "control_url", controlURL)
+}
+
+// postRegistration sends a single registration request.
+func postRegistration(ctx context.Context, client *http.Client, url, token string, req acpspec.RegisterRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal registration: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, registerTimeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build registration request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("registration request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("registration rejected: HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// defaultAdvertiseURL derives a control URL from the host name and the port
+// of the ACP listen address (e.g. ":8765" → "http://<hostname>:8765"), using
+// https when the ACP server serves TLS.
+func defaultAdvertiseURL(acpAddr string, useTLS bool) string {
+	host, port, err := net.SplitHostPort(acpAddr)
+	if err != nil {
+		port = "8765"
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		if h, err := os.Hostname(); err == nil {
+			host = h
+		} else {
+			host = "localhost"
+		}
+	}
+	scheme := "http://"
+	if useTLS {
+		scheme = "https://"
+	}
+	return scheme + net.JoinHostPort(host, port)
+}
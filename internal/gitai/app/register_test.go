@@ -0,0 +1,69 @@
+package app
+
+// Tests for agent self-registration (RURIKO_REGISTER_URL).
+//
+// All tests are whitebox (package app) and run against an httptest server
+// standing in for Ruriko's POST /agents/register endpoint.
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+func TestPostRegistration_SendsBearerAndPayload(t *testing.T) {
+	var gotAuth string
+	var got acpspec.RegisterRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	req := acpspec.RegisterRequest{
+		AgentID:    "saito",
+		ControlURL: "http://saito:8765",
+		ACPToken:   "acp-token",
+		GosutoHash: "abc",
+	}
+	if err := postRegistration(context.Background(), srv.Client(), srv.URL, "bootstrap", req); err != nil {
+		t.Fatalf("postRegistration: %v", err)
+	}
+	if gotAuth != "Bearer bootstrap" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if got != req {
+		t.Errorf("payload = %+v, want %+v", got, req)
+	}
+}
+
+func TestPostRegistration_RejectedReturnsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	err := postRegistration(context.Background(), srv.Client(), srv.URL, "wrong", acpspec.RegisterRequest{AgentID: "saito"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected HTTP 401 error, got %v", err)
+	}
+}
+
+func TestDefaultAdvertiseURL(t *testing.T) {
+	if got := defaultAdvertiseURL("10.0.0.5:9000", false); got != "http://10.0.0.5:9000" {
+		t.Errorf("explicit host: got %q", got)
+	}
+	if got := defaultAdvertiseURL("10.0.0.5:9000", true); got != "https://10.0.0.5:9000" {
+		t.Errorf("tls: got %q", got)
+	}
+	got := defaultAdvertiseURL(":8765", false)
+	if !strings.HasPrefix(got, "http://") || !strings.HasSuffix(got, ":8765") || got == "http://:8765" {
+		t.Errorf("wildcard host: got %q", got)
+	}
+}
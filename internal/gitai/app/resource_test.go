@@ -0,0 +1,139 @@
+package app
+
+// Tests for the MCP resource built-ins (resource.list / resource.read) and
+// their per-MCP policy gating.
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
+)
+
+const resourceTestGosutoYAML = eventTestGosutoYAML + `capabilities:
+  - name: allow-docs-resource-list
+    mcp: docs
+    tool: resource.list
+    allow: true
+  - name: allow-docs-resource-read
+    mcp: docs
+    tool: resource.read
+    allow: true
+  - name: deny-rest
+    mcp: "*"
+    tool: "*"
+    allow: false
+`
+
+// newResourceApp returns an App with the resource built-ins registered and
+// the fake MCP running as both "docs" and "private".
+func newResourceApp(t *testing.T, gosutoYAML string) (*App, func() []string) {
+	t.Helper()
+	a := newToolPolicyApp(t, gosutoYAML)
+	a.builtinReg.Register(builtin.NewResourceListTool(supervisorResources(a.supv)))
+	a.builtinReg.Register(builtin.NewResourceReadTool(supervisorResources(a.supv)))
+	calls := startFakeMCP(t, a, "docs")
+	return a, calls
+}
+
+func TestMCPClient_ListAndReadResources(t *testing.T) {
+	a, _ := newResourceApp(t, resourceTestGosutoYAML)
+	client := a.supv.Get("docs")
+	ctx := context.Background()
+
+	resources, err := client.ListResources(ctx)
+	if err != nil {
+		t.Fatalf("ListResources: %v", err)
+	}
+	if len(resources) != 1 || resources[0].URI != "docs://handbook" {
+		t.Fatalf("resources = %+v, want docs://handbook", resources)
+	}
+
+	result, err := client.ReadResource(ctx, "docs://handbook")
+	if err != nil {
+		t.Fatalf("ReadResource: %v", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].Text != "Be kind. Ship often." {
+		t.Errorf("contents = %+v", result.Contents)
+	}
+}
+
+func TestResourceTools_ListAndReadUnderPolicy(t *testing.T) {
+	a, _ := newResourceApp(t, resourceTestGosutoYAML)
+	ctx := context.Background()
+
+	listed, err := a.DispatchToolCall(ctx, ToolDispatchRequest{
+		Caller: dispatchCallerLLM,
+		Name:   builtin.ResourceListToolName,
+		Args:   map[string]interface{}{"mcp": "docs"},
+	})
+	if err != nil {
+		t.Fatalf("resource.list: %v", err)
+	}
+	if !strings.Contains(listed, "docs://handbook") || !strings.Contains(listed, "Handbook") {
+		t.Errorf("resource.list = %q, want the handbook resource", listed)
+	}
+
+	read, err := a.DispatchToolCall(ctx, ToolDispatchRequest{
+		Caller: dispatchCallerLLM,
+		Name:   builtin.ResourceReadToolName,
+		Args:   map[string]interface{}{"mcp": "docs", "uri": "docs://handbook"},
+	})
+	if err != nil {
+		t.Fatalf("resource.read: %v", err)
+	}
+	if !strings.Contains(read, "Be kind. Ship often.") {
+		t.Errorf("resource.read = %q, want the handbook text", read)
+	}
+}
+
+func TestResourceTools_DeniedForUngrantedMCP(t *testing.T) {
+	a, calls := newResourceApp(t, resourceTestGosutoYAML)
+	startFakeMCP(t, a, "private")
+
+	_, err := a.DispatchToolCall(context.Background(), ToolDispatchRequest{
+		Caller: dispatchCallerLLM,
+		Name:   builtin.ResourceReadToolName,
+		Args:   map[string]interface{}{"mcp": "private", "uri": "docs://handbook"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "policy denied") {
+		t.Fatalf("err = %v, want policy denial", err)
+	}
+	if c := calls(); len(c) != 0 {
+		t.Errorf("docs MCP saw calls %v", c)
+	}
+}
+
+func TestResourceTools_BuiltinRuleDoesNotGrantMCPResources(t *testing.T) {
+	a, _ := newResourceApp(t, eventTestGosutoYAML+`capabilities:
+  - name: allow-builtin-read
+    mcp: builtin
+    tool: resource.read
+    allow: true
+`)
+	_, err := a.DispatchToolCall(context.Background(), ToolDispatchRequest{
+		Caller: dispatchCallerLLM,
+		Name:   builtin.ResourceReadToolName,
+		Args:   map[string]interface{}{"mcp": "docs", "uri": "docs://handbook"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "policy denied") {
+		t.Fatalf("err = %v, want policy denial (rules are scoped to the MCP)", err)
+	}
+}
+
+func TestGatherTools_ResourceToolsOnlyWithRunningMCPs(t *testing.T) {
+	a := newToolPolicyApp(t, eventTestGosutoYAML)
+	a.builtinReg.Register(builtin.NewResourceReadTool(supervisorResources(a.supv)))
+
+	defs, _ := a.gatherTools(context.Background())
+	if hasToolDef(defs, builtin.ResourceReadToolName) {
+		t.Error("resource.read exposed with no MCP servers running")
+	}
+
+	startFakeMCP(t, a, "docs")
+	defs, _ = a.gatherTools(context.Background())
+	if !hasToolDef(defs, builtin.ResourceReadToolName) {
+		t.Error("resource.read not exposed while an MCP server is running")
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// defaultRoomHistoryMaxTokens is used when Config.RoomHistoryMaxTokens is
+// unset.
+const defaultRoomHistoryMaxTokens = 2000
+
+// roomHistory keeps the most recent user/assistant exchanges of each Matrix
+// room so a follow-up message is answered with the conversation in view. It
+// is an in-memory window, separate from the memory subsystem: nothing is
+// summarised or persisted, and a restart starts every room afresh. A nil
+// *roomHistory is disabled.
+type roomHistory struct {
+	maxTurns  int
+	maxTokens int
+
+	mu    sync.Mutex
+	rooms map[string][]exchange
+}
+
+// exchange is one user message and the agent's reply to it.
+type exchange struct {
+	user      string
+	assistant string
+}
+
+// tokens estimates the prompt tokens an exchange adds, at roughly four
+// characters per token plus a small per-message overhead.
+func (e exchange) tokens() int {
+	return (len(e.user)+len(e.assistant))/4 + 8
+}
+
+// newRoomHistory returns a window of maxTurns exchanges per room, trimmed
+// further to about maxTokens estimated tokens (defaultRoomHistoryMaxTokens
+// when maxTokens <= 0). It returns nil when maxTurns <= 0.
+func newRoomHistory(maxTurns, maxTokens int) *roomHistory {
+	if maxTurns <= 0 {
+		return nil
+	}
+	if maxTokens <= 0 {
+		maxTokens = defaultRoomHistoryMaxTokens
+	}
+	return &roomHistory{maxTurns: maxTurns, maxTokens: maxTokens, rooms: make(map[string][]exchange)}
+}
+
+// messages returns roomID's window as alternating user and assistant
+// messages, oldest first.
+func (h *roomHistory) messages(roomID string) []llm.Message {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	window := h.rooms[roomID]
+	out := make([]llm.Message, 0, 2*len(window))
+	for _, e := range window {
+		out = append(out,
+			llm.Message{Role: llm.RoleUser, Content: e.user},
+			llm.Message{Role: llm.RoleAssistant, Content: e.assistant},
+		)
+	}
+	return out
+}
+
+// record appends an exchange to roomID's window, then drops the oldest
+// exchanges until both the turn and token caps hold. An exchange larger
+// than the token cap on its own is not kept.
+func (h *roomHistory) record(roomID, user, assistant string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	window := append(h.rooms[roomID], exchange{user: user, assistant: assistant})
+	if len(window) > h.maxTurns {
+		window = window[len(window)-h.maxTurns:]
+	}
+	total := 0
+	for _, e := range window {
+		total += e.tokens()
+	}
+	for len(window) > 0 && total > h.maxTokens {
+		total -= window[0].tokens()
+		window = window[1:]
+	}
+	// Copy so the dropped prefix does not pin the old backing array.
+	h.rooms[roomID] = append([]exchange(nil), window...)
+}
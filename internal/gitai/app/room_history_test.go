@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+func TestRunTurn_FollowUpIncludesPriorExchange(t *testing.T) {
+	prov := newCapturingLLM("It will be sunny.")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.roomHist = newRoomHistory(3, 0)
+	ctx := context.Background()
+
+	if _, _, err := a.runTurn(ctx, "!room:example.com", "@user:example.com", "What is the weather today?", "$evt-1"); err != nil {
+		t.Fatalf("first turn: %v", err)
+	}
+	if _, ok := prov.waitForCall(time.Second); !ok {
+		t.Fatal("expected first LLM call")
+	}
+	if _, _, err := a.runTurn(ctx, "!room:example.com", "@user:example.com", "And tomorrow?", "$evt-2"); err != nil {
+		t.Fatalf("follow-up turn: %v", err)
+	}
+	req, ok := prov.waitForCall(time.Second)
+	if !ok {
+		t.Fatal("expected follow-up LLM call")
+	}
+
+	want := []llm.Message{
+		{Role: llm.RoleUser, Content: "What is the weather today?"},
+		{Role: llm.RoleAssistant, Content: "It will be sunny."},
+		{Role: llm.RoleUser, Content: "And tomorrow?"},
+	}
+	got := req.Messages[1:]
+	if len(got) != len(want) {
+		t.Fatalf("follow-up history = %+v, want %+v after the system prompt", got, want)
+	}
+	for i := range want {
+		if got[i].Role != want[i].Role || got[i].Content != want[i].Content {
+			t.Errorf("message %d = %s %q, want %s %q", i, got[i].Role, got[i].Content, want[i].Role, want[i].Content)
+		}
+	}
+}
+
+func TestRunTurn_RoomHistoryIsPerRoomAndSkipsEventTurns(t *testing.T) {
+	prov := newCapturingLLM("ok")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.roomHist = newRoomHistory(3, 0)
+	ctx := context.Background()
+
+	if _, _, err := a.runTurn(ctx, "!a:example.com", "@user:example.com", "first room", "$evt-1"); err != nil {
+		t.Fatalf("turn: %v", err)
+	}
+	prov.waitForCall(time.Second)
+
+	for _, tc := range []struct{ room, replyTo string }{
+		{"!b:example.com", "$evt-2"}, // another room
+		{"!a:example.com", ""},       // gateway event turn
+	} {
+		if _, _, err := a.runTurn(ctx, tc.room, "@user:example.com", "hello", tc.replyTo); err != nil {
+			t.Fatalf("turn: %v", err)
+		}
+		req, _ := prov.waitForCall(time.Second)
+		if len(req.Messages) != 2 {
+			t.Errorf("room %s reply-to %q: got %d messages, want system + user only", tc.room, tc.replyTo, len(req.Messages))
+		}
+	}
+}
+
+func TestRoomHistory_RespectsTurnCap(t *testing.T) {
+	h := newRoomHistory(2, 0)
+	for _, q := range []string{"q1", "q2", "q3"} {
+		h.record("!room:example.com", q, "a"+q[1:])
+	}
+
+	msgs := h.messages("!room:example.com")
+	if len(msgs) != 4 {
+		t.Fatalf("got %d messages, want 2 exchanges", len(msgs))
+	}
+	if msgs[0].Content != "q2" || msgs[2].Content != "q3" {
+		t.Errorf("window = %+v, want the two newest exchanges", msgs)
+	}
+}
+
+func TestRoomHistory_RespectsTokenCap(t *testing.T) {
+	// Each exchange is about 58 estimated tokens; a 130-token cap holds two.
+	h := newRoomHistory(10, 130)
+	long := strings.Repeat("x", 100)
+	for i := 0; i < 4; i++ {
+		h.record("!room:example.com", long, long)
+	}
+	if n := len(h.messages("!room:example.com")); n != 4 {
+		t.Errorf("got %d messages, want 2 exchanges under the token cap", n)
+	}
+
+	// An exchange larger than the whole cap is not kept.
+	h.record("!room:example.com", strings.Repeat("y", 1000), "ok")
+	if n := len(h.messages("!room:example.com")); n != 0 {
+		t.Errorf("got %d messages, want none after an oversized exchange", n)
+	}
+}
+
+func TestRoomHistory_NilIsDisabled(t *testing.T) {
+	if h := newRoomHistory(0, 0); h != nil {
+		t.Fatal("expected nil history when turns is 0")
+	}
+	var h *roomHistory
+	h.record("!room:example.com", "q", "a")
+	if msgs := h.messages("!room:example.com"); len(msgs) != 0 {
+		t.Errorf("nil history returned %d messages", len(msgs))
+	}
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+const (
+	// secretRefreshCooldown is the minimum gap between two secret refresh
+	// requests, so a burst of failing tool calls asks Ruriko only once.
+	secretRefreshCooldown = time.Minute
+	// secretRefreshTimeout bounds a refresh request. Ruriko pushes the new
+	// Kuze tokens to the agent before it answers, so this covers a round
+	// trip back into our own ACP server.
+	secretRefreshTimeout = 30 * time.Second
+)
+
+// secretRefresher rate-limits agent-initiated secret refresh requests.
+// The zero value is ready to use.
+type secretRefresher struct {
+	mu   sync.Mutex
+	last time.Time
+	now  func() time.Time // nil means time.Now
+}
+
+// claim reports whether a refresh may be sent now and, if so, starts a new
+// cooldown window.
+func (r *secretRefresher) claim() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	if !r.last.IsZero() && now.Sub(r.last) < secretRefreshCooldown {
+		return false
+	}
+	r.last = now
+	return true
+}
+
+// refreshSecretArgs asks Ruriko for fresh secret leases when args reference
+// secrets that are missing or expired. It reports whether Ruriko confirmed
+// the push, in which case the caller should resolve args again. At most one
+// request is sent per secretRefreshCooldown.
+func (a *App) refreshSecretArgs(ctx context.Context, args map[string]interface{}) bool {
+	if a.cfg == nil || a.cfg.SecretRefreshURL == "" {
+		return false
+	}
+	refs := a.unavailableSecretRefs(args)
+	if len(refs) == 0 {
+		return false
+	}
+	return a.requestSecretRefresh(ctx, refs)
+}
+
+// requestSecretRefresh asks Ruriko to push fresh leases for refs and
+// reports whether it confirmed. It sends nothing when no refresh URL is
+// configured or the cooldown since the previous request is still running.
+func (a *App) requestSecretRefresh(ctx context.Context, refs []string) bool {
+	if a.cfg == nil || a.cfg.SecretRefreshURL == "" {
+		return false
+	}
+	if !a.secretRefresh.claim() {
+		slog.Debug("secrets: refresh skipped; cooldown active", "refs", refs)
+		return false
+	}
+	token := ""
+	if a.acpTokens != nil {
+		token = a.acpTokens.Current()
+	}
+	req := acpspec.SecretRefreshRequest{AgentID: a.cfg.AgentID, Secrets: refs}
+	if err := postSecretRefresh(ctx, http.DefaultClient, a.cfg.SecretRefreshURL, token, req); err != nil {
+		slog.Warn("secrets: refresh request to Ruriko failed", "refs", refs, "err", err)
+		return false
+	}
+	slog.Info("secrets: refreshed leases from Ruriko", "refs", refs)
+	return true
+}
+
+// unavailableSecretRefs returns the sorted refs of secret placeholders in
+// args whose values are missing or expired.
+func (a *App) unavailableSecretRefs(args map[string]interface{}) []string {
+	const prefix, suffix = "{{secret:", "}}"
+	seen := make(map[string]struct{})
+	for _, v := range args {
+		s, ok := v.(string)
+		if !ok || !strings.HasPrefix(s, prefix) || !strings.HasSuffix(s, suffix) || len(s) <= len(prefix)+len(suffix) {
+			continue
+		}
+		ref := s[len(prefix) : len(s)-len(suffix)]
+		seen[ref] = struct{}{}
+	}
+	candidates := make([]string, 0, len(seen))
+	for ref := range seen {
+		candidates = append(candidates, ref)
+	}
+	refs := a.missingSecrets(candidates)
+	sort.Strings(refs)
+	return refs
+}
+
+// postSecretRefresh sends a single refresh request, authenticated with the
+// agent's ACP token.
+func postSecretRefresh(ctx context.Context, client *http.Client, url, token string, req acpspec.SecretRefreshRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal refresh request: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, secretRefreshTimeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build refresh request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("refresh request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("refresh rejected: HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/secrets"
+)
+
+// newSecretRefreshApp returns an App whose secret refresh URL points at a
+// fake Ruriko. onRefresh runs for each refresh request; the returned
+// counter reports how many arrived.
+func newSecretRefreshApp(t *testing.T, mgr *secrets.Manager, onRefresh func(acpspec.SecretRefreshRequest)) (*App, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer acp-token" {
+			t.Errorf("Authorization = %q, want the agent ACP token", got)
+		}
+		var req acpspec.SecretRefreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode refresh request: %v", err)
+		}
+		calls.Add(1)
+		if onRefresh != nil {
+			onRefresh(req)
+		}
+		json.NewEncoder(w).Encode(acpspec.SecretRefreshResponse{Pushed: len(req.Secrets)})
+	}))
+	t.Cleanup(srv.Close)
+
+	a := &App{
+		cfg:        &Config{AgentID: "weatherbot", SecretRefreshURL: srv.URL},
+		secretsMgr: mgr,
+		acpTokens:  control.NewTokenSet("acp-token"),
+	}
+	return a, &calls
+}
+
+func TestRefreshSecretArgs_RetriedResolveSucceeds(t *testing.T) {
+	mgr := secrets.NewManager(secrets.New(), time.Hour)
+	var requested []string
+	a, calls := newSecretRefreshApp(t, mgr, func(req acpspec.SecretRefreshRequest) {
+		requested = req.Secrets
+		// Ruriko pushes fresh tokens before it answers.
+		mgr.Apply(map[string]string{"api_key": base64.StdEncoding.EncodeToString([]byte("fresh"))}, 0)
+	})
+	args := map[string]interface{}{"key": "{{secret:api_key}}", "city": "Oslo"}
+
+	if _, err := a.resolveSecretArgs(args); err == nil {
+		t.Fatal("expected resolve to fail before refresh")
+	}
+	if !a.refreshSecretArgs(context.Background(), args) {
+		t.Fatal("expected refresh to succeed")
+	}
+	got, err := a.resolveSecretArgs(args)
+	if err != nil {
+		t.Fatalf("resolve after refresh: %v", err)
+	}
+	if got["key"] != "fresh" {
+		t.Errorf("key = %v, want refreshed value", got["key"])
+	}
+	if calls.Load() != 1 {
+		t.Errorf("refresh requests = %d, want 1", calls.Load())
+	}
+	if len(requested) != 1 || requested[0] != "api_key" {
+		t.Errorf("requested secrets = %v, want [api_key]", requested)
+	}
+}
+
+func TestRefreshSecretArgs_CooldownLimitsRequests(t *testing.T) {
+	mgr := secrets.NewManager(secrets.New(), time.Hour)
+	a, calls := newSecretRefreshApp(t, mgr, nil)
+	now := time.Now()
+	a.secretRefresh.now = func() time.Time { return now }
+	args := map[string]interface{}{"key": "{{secret:api_key}}"}
+
+	for i := 0; i < 3; i++ {
+		a.refreshSecretArgs(context.Background(), args)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("refresh requests within cooldown = %d, want 1", calls.Load())
+	}
+
+	now = now.Add(secretRefreshCooldown + time.Second)
+	a.refreshSecretArgs(context.Background(), args)
+	if calls.Load() != 2 {
+		t.Errorf("refresh requests after cooldown = %d, want 2", calls.Load())
+	}
+}
+
+func TestRefreshSecretArgs_SkipsAvailableSecrets(t *testing.T) {
+	mgr := secrets.NewManager(secrets.New(), time.Hour)
+	applySecret(t, mgr, "api_key", "v", time.Hour)
+	a, calls := newSecretRefreshApp(t, mgr, nil)
+
+	if a.refreshSecretArgs(context.Background(), map[string]interface{}{"key": "{{secret:api_key}}", "n": 3}) {
+		t.Error("expected no refresh when every referenced secret is available")
+	}
+	if calls.Load() != 0 {
+		t.Errorf("refresh requests = %d, want 0", calls.Load())
+	}
+}
+
+func TestRefreshSecretArgs_DisabledWithoutURL(t *testing.T) {
+	a := &App{cfg: &Config{}, secretsMgr: secrets.NewManager(secrets.New(), time.Hour)}
+	if a.refreshSecretArgs(context.Background(), map[string]interface{}{"key": "{{secret:api_key}}"}) {
+		t.Error("expected no refresh without a refresh URL")
+	}
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/store"
+)
+
+// recordStartup counts this process start in db and returns the number of
+// earlier starts and the time of the first one, for /status. Uptime resets
+// on every start, so the persisted count is what exposes a crash loop. A
+// store error is logged and reported as no history.
+func recordStartup(db *store.Store, now time.Time) (restarts int, firstStartedAt time.Time) {
+	starts, first, err := db.RecordStartup(now)
+	if err != nil {
+		slog.Warn("could not record startup", "err", err)
+		return 0, time.Time{}
+	}
+	restarts = int(starts - 1)
+	if restarts > 0 {
+		slog.Info("agent restarted", "restarts", restarts, "first_started_at", first.Format(time.RFC3339))
+	}
+	return restarts, first
+}
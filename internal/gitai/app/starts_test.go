@@ -0,0 +1,61 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/store"
+)
+
+func TestRecordStartup_CountsRestartsAcrossReopens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitai.db")
+	first := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var restarts int
+	var firstStartedAt time.Time
+	for i := 0; i < 3; i++ {
+		db, err := store.New(path)
+		if err != nil {
+			t.Fatalf("store.New: %v", err)
+		}
+		restarts, firstStartedAt = recordStartup(db, first.Add(time.Duration(i)*time.Hour))
+		db.Close()
+
+		if restarts != i {
+			t.Errorf("start %d: restarts = %d, want %d", i+1, restarts, i)
+		}
+		if !firstStartedAt.Equal(first) {
+			t.Errorf("start %d: first started at %s, want %s", i+1, firstStartedAt, first)
+		}
+	}
+
+	srv := control.New(":0", control.Handlers{
+		AgentID:        "restarty",
+		StartedAt:      time.Now(),
+		Restarts:       restarts,
+		FirstStartedAt: firstStartedAt,
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status control.StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status.Restarts != 2 {
+		t.Errorf("status restarts = %d, want 2", status.Restarts)
+	}
+	if status.FirstStartedAt == nil || !status.FirstStartedAt.Equal(first) {
+		t.Errorf("status first_started_at = %v, want %s", status.FirstStartedAt, first)
+	}
+}
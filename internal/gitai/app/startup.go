@@ -0,0 +1,270 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+)
+
+// startupProbeInterval is how often the startup probe polls MCP servers.
+const startupProbeInterval = 500 * time.Millisecond
+
+// startupGate holds Matrix turns and gateway events until the startup checks
+// (the MCP probe and the secrets preflight) finish. A nil gate is always open.
+type startupGate struct {
+	ready chan struct{}
+	once  sync.Once
+
+	mu      sync.Mutex
+	pending int // startup checks still running
+}
+
+// newStartupGate returns a closed gate when cfg enables the startup probe
+// and configures MCP servers or required secrets to wait for, and nil
+// otherwise.
+func newStartupGate(cfg *gosutospec.Config) *startupGate {
+	if cfg == nil || !cfg.StartupProbe.Enabled {
+		return nil
+	}
+	pending := 0
+	if len(cfg.MCPs) > 0 {
+		pending++
+	}
+	if len(requiredSecretRefs(cfg)) > 0 {
+		pending++
+	}
+	if pending == 0 {
+		return nil
+	}
+	return &startupGate{ready: make(chan struct{}), pending: pending}
+}
+
+// release opens the gate. It is safe to call more than once.
+func (g *startupGate) release() {
+	g.once.Do(func() { close(g.ready) })
+}
+
+// done records that one startup check has finished and opens the gate once
+// every check has.
+func (g *startupGate) done() {
+	g.mu.Lock()
+	g.pending--
+	open := g.pending <= 0
+	g.mu.Unlock()
+	if open {
+		g.release()
+	}
+}
+
+// wait blocks until the gate opens or ctx ends, and reports whether it
+// opened.
+func (g *startupGate) wait(ctx context.Context) bool {
+	if g == nil {
+		return true
+	}
+	select {
+	case <-g.ready:
+		return true
+	default:
+	}
+	slog.Debug("startup probe pending; holding turn")
+	select {
+	case <-g.ready:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// startStartupChecks starts the MCP probe and the secrets preflight that
+// gate waits for. gate must have been built from cfg; nil does nothing.
+func (a *App) startStartupChecks(ctx context.Context, cfg *gosutospec.Config, gate *startupGate) {
+	if gate == nil {
+		return
+	}
+	if len(cfg.MCPs) > 0 {
+		go a.runStartupProbe(ctx, cfg, gate)
+	}
+	if len(requiredSecretRefs(cfg)) > 0 {
+		go a.runSecretsPreflight(ctx, cfg, gate)
+	}
+}
+
+// extendStartupGate holds traffic for the startup checks of cfg on top of
+// any checks still running: the new gate opens once its own checks finish
+// and the gate it replaces has opened. When cfg needs no checks the current
+// gate is kept as is. Turns held by the previous gate move on to the new one.
+func (a *App) extendStartupGate(ctx context.Context, cfg *gosutospec.Config) {
+	gate := newStartupGate(cfg)
+	if gate == nil {
+		return
+	}
+	gate.pending++ // the previous gate
+	old := a.startup.Swap(gate)
+	go func() {
+		old.wait(context.Background())
+		gate.done()
+	}()
+	a.startStartupChecks(ctx, cfg, gate)
+}
+
+// startupAdditions returns next reduced to the MCP servers and required
+// secrets that prev does not have, so a config apply only waits for what it
+// added. A nil prev returns next.
+func startupAdditions(prev, next *gosutospec.Config) *gosutospec.Config {
+	if prev == nil {
+		return next
+	}
+	known := make(map[string]bool)
+	for _, sp := range prev.MCPs {
+		known[sp.Name] = true
+	}
+	add := *next
+	add.MCPs = nil
+	for _, sp := range next.MCPs {
+		if !known[sp.Name] {
+			add.MCPs = append(add.MCPs, sp)
+		}
+	}
+	known = make(map[string]bool)
+	for _, name := range requiredSecretRefs(prev) {
+		known[name] = true
+	}
+	add.Secrets = nil
+	for _, sr := range next.Secrets {
+		if sr.Required && !known[sr.Name] {
+			add.Secrets = append(add.Secrets, sr)
+		}
+	}
+	return &add
+}
+
+// waitStartup blocks until the current startup gate opens or ctx ends, and
+// reports whether it opened. A gate replaced while waiting is followed to
+// its successor.
+func (a *App) waitStartup(ctx context.Context) bool {
+	for {
+		gate := a.startup.Load()
+		if !gate.wait(ctx) {
+			return false
+		}
+		if a.startup.Load() == gate {
+			return true
+		}
+	}
+}
+
+// runStartupProbe polls the MCP servers named in cfg until each answers
+// tools/list, then marks its check done on gate. When the probe's timeout
+// elapses first it logs the MCPs that are still not ready and gives up.
+func (a *App) runStartupProbe(ctx context.Context, cfg *gosutospec.Config, gate *startupGate) {
+	defer gate.done()
+
+	start := time.Now()
+	timeout := cfg.StartupProbe.Timeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pending := make([]string, 0, len(cfg.MCPs))
+	for _, sp := range cfg.MCPs {
+		pending = append(pending, sp.Name)
+	}
+	tick := time.NewTicker(startupProbeInterval)
+	defer tick.Stop()
+	for {
+		pending = a.unreadyMCPs(ctx, pending)
+		if len(pending) == 0 {
+			slog.Info("startup probe passed; MCP servers ready",
+				"mcps", len(cfg.MCPs), "elapsed", time.Since(start).Round(time.Millisecond))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			slog.Warn("startup probe timed out; releasing traffic",
+				"not_ready", pending, "timeout", timeout)
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// unreadyMCPs returns the subset of names whose MCP server is not running
+// or does not yet answer tools/list.
+func (a *App) unreadyMCPs(ctx context.Context, names []string) []string {
+	var pending []string
+	for _, name := range names {
+		client := a.supv.Get(name)
+		if client == nil {
+			pending = append(pending, name)
+			continue
+		}
+		if _, err := client.ListTools(ctx); err != nil {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
+
+// requiredSecretRefs returns the names of the secrets cfg marks as required.
+func requiredSecretRefs(cfg *gosutospec.Config) []string {
+	var refs []string
+	for _, s := range cfg.Secrets {
+		if s.Required {
+			refs = append(refs, s.Name)
+		}
+	}
+	return refs
+}
+
+// runSecretsPreflight asks Ruriko for the required secrets declared in cfg
+// and waits until they have all arrived, then marks its check done on gate.
+// Like the MCP probe it gives up when the startup probe's timeout elapses,
+// logging the secrets that are still missing.
+func (a *App) runSecretsPreflight(ctx context.Context, cfg *gosutospec.Config, gate *startupGate) {
+	defer gate.done()
+
+	start := time.Now()
+	timeout := cfg.StartupProbe.Timeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	missing := a.missingSecrets(requiredSecretRefs(cfg))
+	if len(missing) == 0 {
+		return
+	}
+	tick := time.NewTicker(startupProbeInterval)
+	defer tick.Stop()
+	for {
+		missing = a.missingSecrets(missing)
+		if len(missing) == 0 {
+			slog.Info("secrets preflight passed; required secrets arrived",
+				"secrets", requiredSecretRefs(cfg), "elapsed", time.Since(start).Round(time.Millisecond))
+			return
+		}
+		// Ask Ruriko for the secrets; the refresh cooldown spaces out
+		// repeat requests while Ruriko is still starting. The request is
+		// bounded by ctx, so a hung Ruriko cannot outlast the timeout.
+		a.requestSecretRefresh(ctx, missing)
+		select {
+		case <-ctx.Done():
+			slog.Warn("secrets preflight timed out; releasing traffic",
+				"missing", missing, "timeout", timeout)
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// missingSecrets returns the subset of refs with no unexpired value.
+func (a *App) missingSecrets(refs []string) []string {
+	var missing []string
+	for _, ref := range refs {
+		if a.secretsMgr.IsExpired(ref) {
+			missing = append(missing, ref)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,290 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/secrets"
+)
+
+func probeConfig(timeoutSeconds int, mcps ...string) *gosutospec.Config {
+	cfg := &gosutospec.Config{StartupProbe: gosutospec.StartupProbe{Enabled: true, TimeoutSeconds: timeoutSeconds}}
+	for _, name := range mcps {
+		cfg.MCPs = append(cfg.MCPs, gosutospec.MCPServer{Name: name, Command: "unused"})
+	}
+	return cfg
+}
+
+func waitGate(g *startupGate, timeout time.Duration) bool {
+	select {
+	case <-g.ready:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestNewStartupGate_OnlyWhenEnabledWithMCPs(t *testing.T) {
+	if newStartupGate(nil) != nil {
+		t.Error("nil config should not gate")
+	}
+	if newStartupGate(&gosutospec.Config{MCPs: []gosutospec.MCPServer{{Name: "docs"}}}) != nil {
+		t.Error("disabled probe should not gate")
+	}
+	if newStartupGate(probeConfig(0)) != nil {
+		t.Error("probe without MCPs should not gate")
+	}
+	if newStartupGate(probeConfig(0, "docs")) == nil {
+		t.Error("enabled probe with MCPs should gate")
+	}
+}
+
+func TestStartupGate_HoldsEventsUntilReleased(t *testing.T) {
+	prov := newCapturingLLM("done")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.startup.Store(newStartupGate(probeConfig(0, "docs")))
+
+	go a.runEventTurn(context.Background(), makeTestEvent("scheduler", "cron.tick", "run"))
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("event turn ran before the startup probe passed")
+	}
+
+	a.startup.Load().release()
+	if _, ok := prov.waitForCall(3 * time.Second); !ok {
+		t.Fatal("held event was not processed after the startup probe passed")
+	}
+}
+
+func TestStartupGate_HoldsMessagesUntilReleased(t *testing.T) {
+	prov := newCapturingLLM("done")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.startup.Store(newStartupGate(probeConfig(0, "docs")))
+
+	evt := makeMessageEvent("!chat-room:example.com", "@user:example.com", "$evt-startup", "Hey test-agent, hello")
+	go a.handleMessage(context.Background(), evt)
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("message turn ran before the startup probe passed")
+	}
+
+	a.startup.Load().release()
+	if _, ok := prov.waitForCall(3 * time.Second); !ok {
+		t.Fatal("held message was not processed after the startup probe passed")
+	}
+}
+
+func TestExtendStartupGate_WaitsForPreviousGate(t *testing.T) {
+	prov := newCapturingLLM("done")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	old := newStartupGate(probeConfig(0, "docs"))
+	a.startup.Store(old)
+
+	go a.runEventTurn(context.Background(), makeTestEvent("scheduler", "cron.tick", "run"))
+	time.Sleep(100 * time.Millisecond)
+
+	// The added MCP never starts, so the new probe gives up after 1s; the
+	// held turn still waits because the previous gate is closed.
+	a.extendStartupGate(context.Background(), probeConfig(1, "never-started"))
+	if _, ok := prov.waitForCall(1500 * time.Millisecond); ok {
+		t.Fatal("held turn ran while the previous gate was still closed")
+	}
+	old.release()
+	if _, ok := prov.waitForCall(3 * time.Second); !ok {
+		t.Fatal("held turn did not run after both gates opened")
+	}
+
+	// A config that needs no checks keeps the current gate.
+	cur := a.startup.Load()
+	a.extendStartupGate(context.Background(), probeConfig(0))
+	if a.startup.Load() != cur {
+		t.Error("gate replaced for a config that needs no checks")
+	}
+}
+
+func TestStartupAdditions(t *testing.T) {
+	prev := secretsPreflightConfig(5, "api_key")
+	prev.MCPs = []gosutospec.MCPServer{{Name: "docs", Command: "unused"}}
+	next := secretsPreflightConfig(5, "api_key", "db_password")
+	next.MCPs = []gosutospec.MCPServer{{Name: "docs", Command: "unused"}, {Name: "search", Command: "unused"}}
+
+	add := startupAdditions(prev, next)
+	if len(add.MCPs) != 1 || add.MCPs[0].Name != "search" {
+		t.Errorf("added MCPs = %+v, want [search]", add.MCPs)
+	}
+	if refs := requiredSecretRefs(add); len(refs) != 1 || refs[0] != "db_password" {
+		t.Errorf("added required secrets = %v, want [db_password]", refs)
+	}
+	if newStartupGate(startupAdditions(next, next)) != nil {
+		t.Error("an unchanged config should not gate")
+	}
+	if startupAdditions(nil, next) != next {
+		t.Error("with no previous config every check is an addition")
+	}
+}
+
+func TestApplyConfig_GatesOnlyWhenMCPsAreAdded(t *testing.T) {
+	a := newConfigApplyApp(t)
+	if a.startup.Load() != nil {
+		t.Fatal("new app should start without a gate")
+	}
+	yaml := configApplyBrokenMCPYAML + "startupProbe:\n  enabled: true\n  timeoutSeconds: 1\n"
+	if err := a.applyConfig(yaml, "probe"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	gate := a.startup.Load()
+	if gate == nil {
+		t.Fatal("applying a config that adds an MCP did not gate traffic")
+	}
+	if !waitGate(gate, 3*time.Second) {
+		t.Fatal("gate did not open after the probe timeout")
+	}
+
+	// Re-applying the same config, or one that adds no MCPs, keeps the open
+	// gate instead of probing the broken MCP again.
+	for _, y := range []string{yaml, yaml + "# comment only\n"} {
+		if err := a.applyConfig(y, "probe"); err != nil {
+			t.Fatalf("applyConfig: %v", err)
+		}
+		if a.startup.Load() != gate {
+			t.Fatal("config apply without added MCPs replaced the startup gate")
+		}
+	}
+}
+
+func TestStartupGate_CancelledWaitReturnsFalse(t *testing.T) {
+	g := newStartupGate(probeConfig(0, "docs"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if g.wait(ctx) {
+		t.Fatal("wait should report false when its context ends first")
+	}
+}
+
+func TestRunStartupProbe_ReleasesWhenMCPsReady(t *testing.T) {
+	a := newEventApp(t, eventTestGosutoYAML, newCapturingLLM("ok"))
+	startFakeMCP(t, a, "docs")
+	cfg := probeConfig(10, "docs")
+	gate := newStartupGate(cfg)
+
+	go a.runStartupProbe(context.Background(), cfg, gate)
+	if !waitGate(gate, 5*time.Second) {
+		t.Fatal("startup probe did not release once the MCP answered tools/list")
+	}
+}
+
+func TestRunStartupProbe_TimesOutOnBrokenMCP(t *testing.T) {
+	a := newEventApp(t, eventTestGosutoYAML, newCapturingLLM("ok"))
+	cfg := probeConfig(1, "never-started")
+	gate := newStartupGate(cfg)
+
+	start := time.Now()
+	go a.runStartupProbe(context.Background(), cfg, gate)
+	if waitGate(gate, 500*time.Millisecond) {
+		t.Fatal("startup probe released before its timeout with an unready MCP")
+	}
+	if !waitGate(gate, 3*time.Second) {
+		t.Fatal("startup probe did not release after its timeout")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("released after %v, want at least the 1s timeout", elapsed)
+	}
+}
+
+func secretsPreflightConfig(timeoutSeconds int, required ...string) *gosutospec.Config {
+	cfg := &gosutospec.Config{StartupProbe: gosutospec.StartupProbe{Enabled: true, TimeoutSeconds: timeoutSeconds}}
+	for _, name := range required {
+		cfg.Secrets = append(cfg.Secrets, gosutospec.SecretRef{Name: name, Required: true})
+	}
+	cfg.Secrets = append(cfg.Secrets, gosutospec.SecretRef{Name: "optional"})
+	return cfg
+}
+
+func TestNewStartupGate_RequiredSecretsGate(t *testing.T) {
+	cfg := &gosutospec.Config{
+		StartupProbe: gosutospec.StartupProbe{Enabled: true},
+		Secrets:      []gosutospec.SecretRef{{Name: "optional"}},
+	}
+	if newStartupGate(cfg) != nil {
+		t.Error("optional secrets alone should not gate")
+	}
+	if newStartupGate(secretsPreflightConfig(0, "api_key")) == nil {
+		t.Error("enabled probe with required secrets should gate")
+	}
+}
+
+func TestStartupGate_WaitsForProbeAndPreflight(t *testing.T) {
+	cfg := secretsPreflightConfig(0, "api_key")
+	cfg.MCPs = []gosutospec.MCPServer{{Name: "docs", Command: "unused"}}
+	g := newStartupGate(cfg)
+
+	g.done()
+	if waitGate(g, 100*time.Millisecond) {
+		t.Fatal("gate opened after only one of two startup checks finished")
+	}
+	g.done()
+	if !waitGate(g, time.Second) {
+		t.Fatal("gate did not open after both startup checks finished")
+	}
+}
+
+func TestRunSecretsPreflight_HoldsEventsUntilSecretsArrive(t *testing.T) {
+	prov := newCapturingLLM("done")
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.secretsMgr = secrets.NewManager(secrets.New(), time.Hour)
+	cfg := secretsPreflightConfig(10, "api_key")
+	a.startup.Store(newStartupGate(cfg))
+
+	go a.runSecretsPreflight(context.Background(), cfg, a.startup.Load())
+	go a.runEventTurn(context.Background(), makeTestEvent("scheduler", "cron.tick", "run"))
+	if _, ok := prov.waitForCall(300 * time.Millisecond); ok {
+		t.Fatal("event turn ran before the required secrets arrived")
+	}
+
+	applySecret(t, a.secretsMgr, "api_key", "v", time.Hour)
+	if _, ok := prov.waitForCall(3 * time.Second); !ok {
+		t.Fatal("held event was not processed after the required secrets arrived")
+	}
+}
+
+func TestRunSecretsPreflight_RequestsSecretsFromRuriko(t *testing.T) {
+	mgr := secrets.NewManager(secrets.New(), time.Hour)
+	var requested []string
+	a, calls := newSecretRefreshApp(t, mgr, func(req acpspec.SecretRefreshRequest) {
+		requested = req.Secrets
+		mgr.Apply(map[string]string{"api_key": base64.StdEncoding.EncodeToString([]byte("v"))}, 0)
+	})
+	cfg := secretsPreflightConfig(10, "api_key")
+	gate := newStartupGate(cfg)
+
+	go a.runSecretsPreflight(context.Background(), cfg, gate)
+	if !waitGate(gate, 3*time.Second) {
+		t.Fatal("secrets preflight did not release once Ruriko pushed the secrets")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("refresh requests = %d, want 1", calls.Load())
+	}
+	if len(requested) != 1 || requested[0] != "api_key" {
+		t.Errorf("requested secrets = %v, want [api_key]", requested)
+	}
+}
+
+func TestRunSecretsPreflight_TimesOutWithoutSecrets(t *testing.T) {
+	a := &App{secretsMgr: secrets.NewManager(secrets.New(), time.Hour)}
+	cfg := secretsPreflightConfig(1, "api_key")
+	gate := newStartupGate(cfg)
+
+	start := time.Now()
+	go a.runSecretsPreflight(context.Background(), cfg, gate)
+	if waitGate(gate, 500*time.Millisecond) {
+		t.Fatal("secrets preflight released before its timeout with a missing secret")
+	}
+	if !waitGate(gate, 3*time.Second) {
+		t.Fatal("secrets preflight did not release after its timeout")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("released after %v, want at least the 1s timeout", elapsed)
+	}
+}
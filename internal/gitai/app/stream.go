@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// streamEditInterval is the minimum time between edits of a streamed reply,
+// so a fast model does not flood the room with m.replace events.
+const streamEditInterval = time.Second
+
+// emptyStreamedReply replaces a streamed message whose final round produced
+// no text, so the room is not left showing the text of an earlier round as
+// if it were the answer.
+const emptyStreamedReply = "✅ Done."
+
+// errTurnCancelled is returned by a turn aborted through POST /tasks/cancel.
+var errTurnCancelled = errors.New("turn cancelled")
+
+// replyStreamer abstracts the Matrix operations used to stream a reply:
+// post the first text as a reply, then edit it in place. It is satisfied by
+// *matrix.Client and can be replaced with a recording stub in tests.
+type replyStreamer interface {
+	SendReplyEvent(roomID, replyToEventID, text string) (string, error)
+	EditText(roomID, eventID, text string) error
+}
+
+// streamedReply is the Matrix message a chat turn streams its answer into.
+// Nothing is posted until the model produces text; after that the message is
+// edited at most once per interval as more text arrives.
+type streamedReply struct {
+	out      replyStreamer
+	roomID   string
+	replyTo  string
+	interval time.Duration
+	now      func() time.Time
+
+	eventID  string    // posted message; empty until the first text
+	shown    string    // text the message currently shows
+	lastEdit time.Time // when shown was last sent
+}
+
+func newStreamedReply(out replyStreamer, roomID, replyTo string) *streamedReply {
+	return &streamedReply{
+		out:      out,
+		roomID:   roomID,
+		replyTo:  replyTo,
+		interval: streamEditInterval,
+		now:      time.Now,
+	}
+}
+
+// isKnownAgent reports whether sender is a peer agent named in cfg, under
+// instructions.context.peers or trust.trustedPeers. Agents ignore the edits a
+// streamed reply is made of, so they are answered with a single message.
+func isKnownAgent(cfg *gosutospec.Config, sender string) bool {
+	if cfg == nil {
+		return false
+	}
+	for _, peer := range cfg.Instructions.Context.Peers {
+		if peer.MXID != "" && peer.MXID == sender {
+			return true
+		}
+	}
+	for _, peer := range cfg.Trust.TrustedPeers {
+		if peer.MXID == sender {
+			return true
+		}
+	}
+	return false
+}
+
+type replyStreamCtxKey struct{}
+
+// withReplyStream marks ctx as a chat turn whose answer streams into r.
+func withReplyStream(ctx context.Context, r *streamedReply) context.Context {
+	return context.WithValue(ctx, replyStreamCtxKey{}, r)
+}
+
+func replyStreamFromContext(ctx context.Context) *streamedReply {
+	r, _ := ctx.Value(replyStreamCtxKey{}).(*streamedReply)
+	return r
+}
+
+// posted reports whether the reply message has been sent.
+func (r *streamedReply) posted() bool { return r != nil && r.eventID != "" }
+
+// update shows text, the answer so far, posting the message on the first
+// call and otherwise editing it when the throttle interval has passed.
+func (r *streamedReply) update(text string) {
+	if strings.TrimSpace(text) == "" || text == r.shown {
+		return
+	}
+	if r.eventID == "" {
+		evtID, err := r.out.SendReplyEvent(r.roomID, r.replyTo, text)
+		if err != nil {
+			slog.Warn("could not post streamed reply", "room", r.roomID, "err", err)
+			return
+		}
+		r.eventID, r.shown, r.lastEdit = evtID, text, r.now()
+		return
+	}
+	if r.now().Sub(r.lastEdit) < r.interval {
+		return
+	}
+	r.edit(text)
+}
+
+// finish shows the complete text regardless of the throttle.
+func (r *streamedReply) finish(text string) error {
+	if text == r.shown {
+		return nil
+	}
+	return r.edit(text)
+}
+
+func (r *streamedReply) edit(text string) error {
+	if err := r.out.EditText(r.roomID, r.eventID, text); err != nil {
+		slog.Warn("could not edit streamed reply", "room", r.roomID, "err", err)
+		return err
+	}
+	r.shown, r.lastEdit = text, r.now()
+	return nil
+}
+
+// completeStreamed runs one LLM round through the streaming API, showing
+// the text as it arrives in reply, and returns the assembled response.
+func completeStreamed(ctx context.Context, prov llm.StreamingProvider, req llm.CompletionRequest, reply *streamedReply) (*llm.CompletionResponse, error) {
+	deltas, err := prov.CompleteStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	for d := range deltas {
+		switch {
+		case d.Err != nil:
+			return nil, d.Err
+		case d.Response != nil:
+			return d.Response, nil
+		}
+		text.WriteString(d.Content)
+		reply.update(text.String())
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("stream ended without a response")
+}
+
+// watchCancel returns a context that is cancelled with errTurnCancelled when
+// POST /tasks/cancel signals while the turn runs, and a stop function to
+// call when the turn ends. A signal left over from before the turn started
+// is discarded.
+func (a *App) watchCancel(ctx context.Context) (context.Context, func()) {
+	if a.cancelCh == nil {
+		return ctx, func() {}
+	}
+	select {
+	case <-a.cancelCh:
+	default:
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-a.cancelCh:
+			cancel(errTurnCancelled)
+		case <-done:
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		close(done)
+		cancel(nil)
+	}
+}
@@ -0,0 +1,348 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+	"github.com/bdobrica/Ruriko/internal/gitai/matrix"
+)
+
+// streamRound is one scripted streamed completion: the text chunks sent as
+// deltas, then the final response.
+type streamRound struct {
+	chunks []string
+	resp   llm.CompletionResponse
+}
+
+// streamingLLM is a StreamingProvider that plays back scripted rounds. With
+// block set, the last round stops after its chunks and waits for the stream
+// to be cancelled.
+type streamingLLM struct {
+	mu       sync.Mutex
+	rounds   []streamRound
+	block    bool
+	requests []llm.CompletionRequest
+}
+
+func (s *streamingLLM) Complete(context.Context, llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	return nil, fmt.Errorf("Complete called on a streamed turn")
+}
+
+func (s *streamingLLM) CompleteStream(ctx context.Context, req llm.CompletionRequest) (<-chan llm.StreamDelta, error) {
+	s.mu.Lock()
+	n := len(s.requests)
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	if n >= len(s.rounds) {
+		return nil, fmt.Errorf("unexpected round %d", n+1)
+	}
+	round := s.rounds[n]
+	block := s.block && n == len(s.rounds)-1
+
+	out := make(chan llm.StreamDelta)
+	go func() {
+		defer close(out)
+		for _, c := range round.chunks {
+			out <- llm.StreamDelta{Content: c}
+		}
+		if block {
+			<-ctx.Done()
+			out <- llm.StreamDelta{Err: ctx.Err()}
+			return
+		}
+		resp := round.resp
+		out <- llm.StreamDelta{Response: &resp}
+	}()
+	return out, nil
+}
+
+func (s *streamingLLM) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// recordingStreamer records the streamed reply's post and edits.
+type recordingStreamer struct {
+	mu    sync.Mutex
+	posts []string
+	edits []string
+}
+
+func (r *recordingStreamer) SendReplyEvent(_, _, text string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.posts = append(r.posts, text)
+	return "$streamed", nil
+}
+
+func (r *recordingStreamer) EditText(_, eventID, text string) error {
+	if eventID != "$streamed" {
+		return fmt.Errorf("edit of unknown event %s", eventID)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.edits = append(r.edits, text)
+	return nil
+}
+
+func (r *recordingStreamer) snapshot() (posts, edits []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.posts...), append([]string(nil), r.edits...)
+}
+
+func newStreamingApp(t *testing.T, prov llm.Provider) (*App, *recordingStreamer) {
+	t.Helper()
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.cfg = &Config{Matrix: matrix.Config{UserID: "@test-agent:example.com"}}
+	rec := &recordingStreamer{}
+	a.replyStream = rec
+	return a, rec
+}
+
+func directedEvent(body string) *event.Event {
+	return &event.Event{
+		ID:      id.EventID("$evt-stream"),
+		RoomID:  id.RoomID("!chat-room:example.com"),
+		Sender:  id.UserID("@user:example.com"),
+		Content: event.Content{Parsed: &event.MessageEventContent{MsgType: event.MsgText, Body: body}},
+	}
+}
+
+func TestHandleMessage_StreamsReplyAsEdits(t *testing.T) {
+	prov := &streamingLLM{rounds: []streamRound{{
+		chunks: []string{"Once ", "upon ", "a time."},
+		resp: llm.CompletionResponse{
+			Message:      llm.Message{Role: llm.RoleAssistant, Content: "Once upon a time."},
+			FinishReason: "stop",
+		},
+	}}}
+	a, rec := newStreamingApp(t, prov)
+
+	a.handleMessage(context.Background(), directedEvent("Hey test-agent, tell me a story"))
+
+	posts, edits := rec.snapshot()
+	if len(posts) != 1 || posts[0] != "Once " {
+		t.Fatalf("posts = %q, want the first chunk posted once", posts)
+	}
+	// The chunks arrive well inside the edit interval, so only the final
+	// text is sent as an edit.
+	if len(edits) != 1 || edits[0] != "Once upon a time." {
+		t.Errorf("edits = %q, want one final edit", edits)
+	}
+}
+
+func TestHandleMessage_StreamsAcrossToolRounds(t *testing.T) {
+	prov := &streamingLLM{rounds: []streamRound{
+		{
+			chunks: []string{"Checking."},
+			resp: llm.CompletionResponse{
+				Message: llm.Message{Role: llm.RoleAssistant, Content: "Checking.", ToolCalls: []llm.ToolCall{{
+					ID: "call-1", Type: "function", Function: llm.FunctionCall{Name: "nothing__here", Arguments: "{}"},
+				}}},
+				FinishReason: "tool_calls",
+			},
+		},
+		{
+			chunks: []string{"All ", "done."},
+			resp: llm.CompletionResponse{
+				Message:      llm.Message{Role: llm.RoleAssistant, Content: "All done."},
+				FinishReason: "stop",
+			},
+		},
+	}}
+	a, rec := newStreamingApp(t, prov)
+
+	a.handleMessage(context.Background(), directedEvent("Hey test-agent, check something"))
+
+	if prov.calls() != 2 {
+		t.Fatalf("LLM rounds = %d, want 2", prov.calls())
+	}
+	second := prov.requests[1].Messages
+	if last := second[len(second)-1]; last.Role != llm.RoleTool || last.ToolCallID != "call-1" {
+		t.Errorf("second round ends with %+v, want the tool result", last)
+	}
+	posts, edits := rec.snapshot()
+	if len(posts) != 1 || posts[0] != "Checking." {
+		t.Errorf("posts = %q, want one message", posts)
+	}
+	if len(edits) == 0 || edits[len(edits)-1] != "All done." {
+		t.Errorf("edits = %q, want the final answer last", edits)
+	}
+}
+
+func TestHandleMessage_FinalisesStreamWhenLastRoundIsEmpty(t *testing.T) {
+	prov := &streamingLLM{rounds: []streamRound{
+		{
+			chunks: []string{"Checking."},
+			resp: llm.CompletionResponse{
+				Message: llm.Message{Role: llm.RoleAssistant, Content: "Checking.", ToolCalls: []llm.ToolCall{{
+					ID: "call-1", Type: "function", Function: llm.FunctionCall{Name: "nothing__here", Arguments: "{}"},
+				}}},
+				FinishReason: "tool_calls",
+			},
+		},
+		{resp: llm.CompletionResponse{Message: llm.Message{Role: llm.RoleAssistant}, FinishReason: "stop"}},
+	}}
+	a, rec := newStreamingApp(t, prov)
+
+	a.handleMessage(context.Background(), directedEvent("Hey test-agent, check something"))
+
+	_, edits := rec.snapshot()
+	if len(edits) != 1 || edits[0] != emptyStreamedReply {
+		t.Errorf("edits = %q, want the intermediate text replaced by %q", edits, emptyStreamedReply)
+	}
+}
+
+// completingLLM is a streamingLLM that also answers blocking calls, with the
+// response of its first round.
+type completingLLM struct {
+	streamingLLM
+	completed chan struct{}
+}
+
+func (c *completingLLM) Complete(context.Context, llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	c.completed <- struct{}{}
+	resp := c.rounds[0].resp
+	return &resp, nil
+}
+
+const knownAgentGosutoYAML = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms:
+    - "!chat-room:example.com"
+  allowedSenders:
+    - "@user:example.com"
+    - "@kairo:example.com"
+instructions:
+  context:
+    peers:
+      - name: kairo
+        role: "Analysis agent."
+        mxid: "@kairo:example.com"
+persona:
+  llmProvider: openai
+  model: gpt-4o-mini
+`
+
+func TestHandleMessage_DoesNotStreamToKnownAgents(t *testing.T) {
+	prov := &completingLLM{completed: make(chan struct{}, 1), streamingLLM: streamingLLM{rounds: []streamRound{{
+		chunks: []string{"Markets ", "flat."},
+		resp: llm.CompletionResponse{
+			Message:      llm.Message{Role: llm.RoleAssistant, Content: "Markets flat."},
+			FinishReason: "stop",
+		},
+	}}}}
+	a, rec := newStreamingApp(t, prov)
+	if err := a.gosutoLdr.Apply([]byte(knownAgentGosutoYAML)); err != nil {
+		t.Fatalf("gosuto loader Apply: %v", err)
+	}
+
+	evt := directedEvent("Hey test-agent, any news?")
+	evt.Sender = id.UserID("@kairo:example.com")
+	a.handleMessage(context.Background(), evt)
+
+	select {
+	case <-prov.completed:
+	default:
+		t.Fatal("expected the peer's message to be answered with a blocking LLM call")
+	}
+	if posts, edits := rec.snapshot(); len(posts) != 0 || len(edits) != 0 {
+		t.Errorf("posts = %q, edits = %q; want a peer agent answered without streaming", posts, edits)
+	}
+}
+
+func TestHandleMessage_CancelAbortsStream(t *testing.T) {
+	prov := &streamingLLM{block: true, rounds: []streamRound{{chunks: []string{"Partial answer"}}}}
+	a, rec := newStreamingApp(t, prov)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.handleMessage(context.Background(), directedEvent("Hey test-agent, write an essay"))
+	}()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if posts, _ := rec.snapshot(); len(posts) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("streamed reply was never posted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	a.cancelCh <- struct{}{}
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("turn did not stop after cancel")
+	}
+	_, edits := rec.snapshot()
+	if len(edits) != 1 || !strings.HasPrefix(edits[0], "Partial answer") || !strings.Contains(edits[0], errTurnCancelled.Error()) {
+		t.Errorf("edits = %q, want the partial answer marked as cancelled", edits)
+	}
+}
+
+func TestHandleMessage_NonStreamingProviderFallsBack(t *testing.T) {
+	prov := newCapturingLLM("whole answer")
+	a, rec := newStreamingApp(t, prov)
+
+	a.handleMessage(context.Background(), directedEvent("Hey test-agent, hello"))
+
+	if _, ok := prov.waitForCall(time.Second); !ok {
+		t.Fatal("expected a blocking LLM call")
+	}
+	if posts, edits := rec.snapshot(); len(posts) != 0 || len(edits) != 0 {
+		t.Errorf("posts = %q, edits = %q; want nothing streamed", posts, edits)
+	}
+}
+
+func TestHandleMessage_IgnoresEdits(t *testing.T) {
+	prov := newCapturingLLM("should not be called")
+	a, _ := newStreamingApp(t, prov)
+
+	evt := directedEvent("* Hey test-agent, hello")
+	evt.Content.AsMessage().RelatesTo = (&event.RelatesTo{}).SetReplace("$original")
+	a.handleMessage(context.Background(), evt)
+
+	if _, ok := prov.waitForCall(100 * time.Millisecond); ok {
+		t.Fatal("an edit started a turn")
+	}
+}
+
+func TestStreamedReply_ThrottlesEdits(t *testing.T) {
+	rec := &recordingStreamer{}
+	clock := time.Unix(0, 0)
+	r := newStreamedReply(rec, "!room", "$evt")
+	r.now = func() time.Time { return clock }
+
+	r.update("a")
+	clock = clock.Add(500 * time.Millisecond)
+	r.update("ab")
+	clock = clock.Add(600 * time.Millisecond)
+	r.update("abc")
+	if err := r.finish("abcd"); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	posts, edits := rec.snapshot()
+	if len(posts) != 1 || posts[0] != "a" {
+		t.Errorf("posts = %q", posts)
+	}
+	if strings.Join(edits, ",") != "abc,abcd" {
+		t.Errorf("edits = %q, want the throttled edit and the final one", edits)
+	}
+}
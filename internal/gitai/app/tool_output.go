@@ -0,0 +1,131 @@
+package app
+
+import (
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+)
+
+// defaultOutputRedaction replaces matches of a redaction rule that sets no
+// replacement.
+const defaultOutputRedaction = "[REDACTED]"
+
+// redactionPatterns caches compiled redaction patterns by source, so each
+// pattern of the active config is compiled once rather than on every tool
+// result. A pattern that fails to compile is cached as a nil regexp.
+var redactionPatterns sync.Map // string → *regexp.Regexp
+
+// redactionPattern returns the compiled pattern, or nil when it is invalid.
+func redactionPattern(pattern string) *regexp.Regexp {
+	if re, ok := redactionPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		// Validated at parse time; a bad pattern here is skipped.
+		slog.Warn("invalid tool output redaction pattern", "pattern", pattern, "err", err)
+		re = nil
+	}
+	actual, _ := redactionPatterns.LoadOrStore(pattern, re)
+	return actual.(*regexp.Regexp)
+}
+
+// applyOutputTransform applies a capability's output transform to one text
+// item of a tool result: field filters first (only when text is a JSON
+// object or array), then regex redactions. A nil transform returns text
+// unchanged.
+func applyOutputTransform(text string, t *gosutospec.OutputTransform) string {
+	if t == nil {
+		return text
+	}
+	if len(t.AllowFields) > 0 || len(t.DenyFields) > 0 {
+		text = filterJSONFields(text, t.AllowFields, t.DenyFields)
+	}
+	for _, r := range t.Redact {
+		re := redactionPattern(r.Pattern)
+		if re == nil {
+			continue
+		}
+		replacement := r.Replacement
+		if replacement == "" {
+			replacement = defaultOutputRedaction
+		}
+		text = re.ReplaceAllString(text, replacement)
+	}
+	return text
+}
+
+// filterJSONFields keeps only allow's top-level fields, or removes deny's
+// fields at any depth, from a JSON object or array of objects. Text that is
+// not a JSON object or array is returned unchanged.
+func filterJSONFields(text string, allow, deny []string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return text
+	}
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return text
+	}
+	if len(allow) > 0 {
+		v = keepFields(v, toSet(allow))
+	} else {
+		v = dropFields(v, toSet(deny))
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return text
+	}
+	return string(out)
+}
+
+// keepFields keeps only the fields in keep on v, or on each object element
+// when v is an array.
+func keepFields(v any, keep map[string]struct{}) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k := range x {
+			if _, ok := keep[k]; !ok {
+				delete(x, k)
+			}
+		}
+	case []any:
+		for i := range x {
+			x[i] = keepFields(x[i], keep)
+		}
+	}
+	return v
+}
+
+// dropFields removes the fields in drop from every object nested in v.
+func dropFields(v any, drop map[string]struct{}) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, child := range x {
+			if _, ok := drop[k]; ok {
+				delete(x, k)
+				continue
+			}
+			x[k] = dropFields(child, drop)
+		}
+	case []any:
+		for i := range x {
+			x[i] = dropFields(x[i], drop)
+		}
+	}
+	return v
+}
+
+func toSet(items []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(items))
+	for _, it := range items {
+		set[it] = struct{}{}
+	}
+	return set
+}
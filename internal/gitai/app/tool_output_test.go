@@ -0,0 +1,83 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/mcp"
+)
+
+const sampleToolResult = `{"results":[{"title":"Quarterly report","internal_id":"db-4411","owner":{"name":"Alice","internal_id":"u-17"}}],"email":"alice@example.com","total":1}`
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []mcp.ContentItem{{Type: "text", Text: text}}}
+}
+
+func TestFormatToolResult_DenyFieldsStrippedAtAnyDepth(t *testing.T) {
+	out := formatToolResult(textResult(sampleToolResult), &gosutospec.OutputTransform{
+		DenyFields: []string{"internal_id"},
+	})
+
+	if strings.Contains(out, "internal_id") || strings.Contains(out, "db-4411") || strings.Contains(out, "u-17") {
+		t.Errorf("denylisted field survived: %s", out)
+	}
+	for _, want := range []string{`"title":"Quarterly report"`, `"name":"Alice"`, `"total":1`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in output: %s", want, out)
+		}
+	}
+}
+
+func TestFormatToolResult_AllowFieldsKeepsTopLevelOnly(t *testing.T) {
+	out := formatToolResult(textResult(sampleToolResult), &gosutospec.OutputTransform{
+		AllowFields: []string{"total"},
+	})
+
+	if strings.TrimSpace(out) != `{"total":1}` {
+		t.Errorf("output = %s, want only the total field", out)
+	}
+}
+
+func TestFormatToolResult_RegexRedaction(t *testing.T) {
+	out := formatToolResult(textResult(sampleToolResult+"\nCall 555-0199 for details."), &gosutospec.OutputTransform{
+		Redact: []gosutospec.OutputRedaction{
+			{Pattern: `[a-z0-9.]+@[a-z0-9.]+`},
+			{Pattern: `\b(\d{3})-\d{4}\b`, Replacement: "$1-XXXX"},
+		},
+	})
+
+	if strings.Contains(out, "alice@example.com") {
+		t.Errorf("email not redacted: %s", out)
+	}
+	if !strings.Contains(out, `"email":"[REDACTED]"`) {
+		t.Errorf("expected default replacement for the email: %s", out)
+	}
+	if !strings.Contains(out, "Call 555-XXXX for details.") {
+		t.Errorf("expected group-aware replacement for the phone number: %s", out)
+	}
+}
+
+func TestFormatToolResult_FieldFiltersIgnoreNonJSON(t *testing.T) {
+	out := formatToolResult(textResult("internal_id: db-4411"), &gosutospec.OutputTransform{
+		DenyFields: []string{"internal_id"},
+	})
+	if out != "internal_id: db-4411\n" {
+		t.Errorf("non-JSON output changed: %q", out)
+	}
+
+	if out := formatToolResult(textResult(sampleToolResult), nil); out != sampleToolResult+"\n" {
+		t.Errorf("nil transform changed output: %q", out)
+	}
+}
+
+func TestRedactionPattern_CompiledOnce(t *testing.T) {
+	const pattern = `cache-test-\d+`
+	first := redactionPattern(pattern)
+	if first == nil || redactionPattern(pattern) != first {
+		t.Error("pattern was recompiled instead of reused")
+	}
+	if redactionPattern(`(unclosed`) != nil || redactionPattern(`(unclosed`) != nil {
+		t.Error("an invalid pattern should yield nil")
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// toolLoopLLM asks for perRound tool calls on every completion and never
+// produces a final answer.
+type toolLoopLLM struct {
+	mu       sync.Mutex
+	perRound int
+	rounds   int
+}
+
+func (l *toolLoopLLM) Complete(_ context.Context, _ llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rounds++
+	calls := make([]llm.ToolCall, l.perRound)
+	for i := range calls {
+		calls[i] = llm.ToolCall{
+			ID:       fmt.Sprintf("call-%d-%d", l.rounds, i),
+			Type:     "function",
+			Function: llm.FunctionCall{Name: "nothing__here", Arguments: "{}"},
+		}
+	}
+	return &llm.CompletionResponse{
+		Message:      llm.Message{Role: llm.RoleAssistant, ToolCalls: calls},
+		FinishReason: "tool_calls",
+	}, nil
+}
+
+func TestRunTurn_ToolCallBudgetAbortsTurn(t *testing.T) {
+	prov := &toolLoopLLM{perRound: 2}
+	a := newEventApp(t, eventTestGosutoYAML+"limits:\n  maxToolCallsPerTurn: 5\n", prov)
+
+	_, calls, err := a.runTurn(context.Background(), "!chat-room:example.com", "@user:example.com", "loop", "")
+	if err == nil || !strings.Contains(err.Error(), "exceeded maximum tool calls per turn (5)") {
+		t.Fatalf("err = %v, want tool-call budget error", err)
+	}
+	if calls != 4 {
+		t.Errorf("tool calls = %d, want 4 (the third round would exceed the budget)", calls)
+	}
+	if prov.rounds != 3 {
+		t.Errorf("LLM rounds = %d, want 3", prov.rounds)
+	}
+}
+
+func TestRunTurn_NoToolCallBudgetFallsBackToRoundLimit(t *testing.T) {
+	prov := &toolLoopLLM{perRound: 2}
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+
+	_, calls, err := a.runTurn(context.Background(), "!chat-room:example.com", "@user:example.com", "loop", "")
+	if err == nil || !strings.Contains(err.Error(), "tool call rounds") {
+		t.Fatalf("err = %v, want round limit error", err)
+	}
+	if calls != 2*maxToolCallRounds {
+		t.Errorf("tool calls = %d, want %d", calls, 2*maxToolCallRounds)
+	}
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bdobrica/Ruriko/common/redact"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/store"
+)
+
+// toolCallRejected is the audit-trail decision for calls that failed before
+// policy evaluation (malformed name, schema violation, disabled tool).
+const toolCallRejected = "rejected"
+
+// maxToolCallErrorLen caps the error text stored per audit-trail entry.
+const maxToolCallErrorLen = 300
+
+// defaultToolCallRetention is used when Config.ToolCallRetention is unset.
+const defaultToolCallRetention = 10000
+
+// defaultToolCallListLimit and maxToolCallListLimit bound GET /calls.
+const (
+	defaultToolCallListLimit = 50
+	maxToolCallListLimit     = 500
+)
+
+// toolCallRetention returns the number of audit-trail entries kept.
+func (a *App) toolCallRetention() int {
+	if a.cfg == nil || a.cfg.ToolCallRetention <= 0 {
+		return defaultToolCallRetention
+	}
+	return a.cfg.ToolCallRetention
+}
+
+// argKeys returns the sorted argument names of a tool call. Values are
+// deliberately dropped: the audit trail must never hold them.
+func argKeys(args map[string]interface{}) []string {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// recordToolCall completes rec with the call's duration and outcome and
+// appends it to the audit trail. A failed write is logged, never returned:
+// auditing must not change the result of the call.
+func (a *App) recordToolCall(rec store.ToolCall, start time.Time, callErr error) {
+	if a.db == nil {
+		return
+	}
+	rec.DurationMS = time.Since(start).Milliseconds()
+	if rec.MCP == "" {
+		rec.MCP = "-"
+	}
+	if rec.Error == "" && callErr != nil {
+		// Errors raised before secrets are resolved (schema validation,
+		// built-in tools) may still echo arguments; scrub credentials and
+		// keep the entry short. Later failures are classified by
+		// DispatchToolCall instead.
+		rec.Error = truncateUTF8(redact.Secrets(callErr.Error()), maxToolCallErrorLen)
+	}
+	if err := a.db.RecordToolCall(rec, a.toolCallRetention()); err != nil {
+		slog.Warn("tool call audit write failed", "tool", rec.Tool, "err", err)
+	}
+}
+
+// mcpCallErrorClass is the audit-trail error for a failed MCP tools/call.
+// The error itself can carry resolved secret values or server output, so
+// only its kind is recorded.
+func mcpCallErrorClass(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "mcp call timed out"
+	case errors.Is(err, context.Canceled):
+		return "mcp call canceled"
+	default:
+		return "mcp call failed"
+	}
+}
+
+// truncateUTF8 returns s cut to at most n bytes without splitting a UTF-8
+// sequence, with "…" appended when anything was cut.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+// listToolCalls implements control.Handlers.ListToolCalls.
+func (a *App) listToolCalls(limit int) (control.ToolCallListResponse, error) {
+	if limit <= 0 {
+		limit = defaultToolCallListLimit
+	}
+	if limit > maxToolCallListLimit {
+		limit = maxToolCallListLimit
+	}
+	calls, err := a.db.ListToolCalls(limit)
+	if err != nil {
+		return control.ToolCallListResponse{}, err
+	}
+	resp := control.ToolCallListResponse{Calls: make([]control.ToolCallRecord, 0, len(calls))}
+	for _, c := range calls {
+		resp.Calls = append(resp.Calls, control.ToolCallRecord{
+			TraceID:    c.TraceID,
+			Caller:     c.Caller,
+			MCP:        c.MCP,
+			Tool:       c.Tool,
+			Decision:   c.Decision,
+			Rule:       c.Rule,
+			ArgKeys:    c.ArgKeys,
+			DurationMS: c.DurationMS,
+			Error:      c.Error,
+			At:         c.CreatedAt,
+		})
+	}
+	return resp, nil
+}
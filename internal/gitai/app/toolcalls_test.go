@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
+	"github.com/bdobrica/Ruriko/internal/gitai/store"
+)
+
+// toolCallValues are argument values that must never reach the audit trail.
+var toolCallValues = []string{"kairo", "the launch code is 1234"}
+
+func dispatchSendForAudit(t *testing.T, a *App, traceID string) error {
+	t.Helper()
+	ctx := trace.WithTraceID(context.Background(), traceID)
+	_, err := a.DispatchToolCall(ctx, ToolDispatchRequest{
+		Caller: dispatchCallerLLM,
+		Sender: "@user:example.com",
+		Name:   builtin.MatrixSendToolName,
+		Args:   map[string]interface{}{"target": toolCallValues[0], "message": toolCallValues[1]},
+	})
+	return err
+}
+
+func assertToolCallHasNoValues(t *testing.T, c store.ToolCall) {
+	t.Helper()
+	fields := strings.Join([]string{c.TraceID, c.Caller, c.MCP, c.Tool, c.Decision, c.Rule, strings.Join(c.ArgKeys, ","), c.Error}, "|")
+	for _, v := range toolCallValues {
+		if strings.Contains(fields, v) {
+			t.Errorf("audit entry leaks argument value %q: %+v", v, c)
+		}
+	}
+}
+
+func TestDispatchToolCall_RecordsAllowedCall(t *testing.T) {
+	a, sender := newDispatcherTestApp(t, strings.Replace(dispatcherDenyYAML, "allow: false", "allow: true", 1))
+
+	if err := dispatchSendForAudit(t, a, "trace-allow"); err != nil {
+		t.Fatalf("DispatchToolCall: %v", err)
+	}
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected one send, got %d", len(sender.calls))
+	}
+
+	calls, err := a.db.ListToolCalls(10)
+	if err != nil {
+		t.Fatalf("ListToolCalls: %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(calls))
+	}
+	c := calls[0]
+	if c.TraceID != "trace-allow" || c.Caller != dispatchCallerLLM || c.MCP != builtin.BuiltinMCPNamespace ||
+		c.Tool != builtin.MatrixSendToolName || c.Decision != "allow" || c.Rule != "deny-matrix-send" || c.Error != "" {
+		t.Errorf("unexpected audit entry: %+v", c)
+	}
+	if strings.Join(c.ArgKeys, ",") != "message,target" {
+		t.Errorf("ArgKeys = %v, want [message target]", c.ArgKeys)
+	}
+	assertToolCallHasNoValues(t, c)
+}
+
+func TestDispatchToolCall_RecordsDeniedCall(t *testing.T) {
+	a, _ := newDispatcherTestApp(t, dispatcherDenyYAML)
+
+	if err := dispatchSendForAudit(t, a, "trace-deny"); err == nil {
+		t.Fatal("expected policy deny error")
+	}
+
+	calls, err := a.db.ListToolCalls(10)
+	if err != nil {
+		t.Fatalf("ListToolCalls: %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(calls))
+	}
+	c := calls[0]
+	if c.Decision != "deny" || c.Rule != "deny-matrix-send" || !strings.HasPrefix(c.Error, "policy denied") {
+		t.Errorf("unexpected audit entry: %+v", c)
+	}
+	if strings.Join(c.ArgKeys, ",") != "message,target" {
+		t.Errorf("ArgKeys = %v, want [message target]", c.ArgKeys)
+	}
+	assertToolCallHasNoValues(t, c)
+}
+
+func TestDispatchToolCall_RecordsRejectedCall(t *testing.T) {
+	a, _ := newDispatcherTestApp(t, dispatcherDenyYAML)
+
+	_, err := a.DispatchToolCall(context.Background(), ToolDispatchRequest{
+		Caller: dispatchCallerWorkflow,
+		Name:   "not-a-tool",
+		Args:   map[string]interface{}{"q": "secret query"},
+	})
+	if err == nil {
+		t.Fatal("expected error for unknown tool name")
+	}
+
+	resp, err := a.listToolCalls(0)
+	if err != nil {
+		t.Fatalf("listToolCalls: %v", err)
+	}
+	if len(resp.Calls) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(resp.Calls))
+	}
+	c := resp.Calls[0]
+	if c.Decision != toolCallRejected || c.MCP != "-" || c.Tool != "not-a-tool" || c.Error == "" {
+		t.Errorf("unexpected audit entry: %+v", c)
+	}
+}
+
+func TestRecordToolCall_RetentionKeepsNewest(t *testing.T) {
+	a, _ := newDispatcherTestApp(t, dispatcherDenyYAML)
+	a.cfg = &Config{ToolCallRetention: 2}
+
+	for _, id := range []string{"trace-1", "trace-2", "trace-3"} {
+		a.recordToolCall(store.ToolCall{TraceID: id, Caller: dispatchCallerLLM, Tool: "t", Decision: "allow"}, time.Now(), nil)
+	}
+
+	calls, err := a.db.ListToolCalls(10)
+	if err != nil {
+		t.Fatalf("ListToolCalls: %v", err)
+	}
+	if len(calls) != 2 || calls[0].TraceID != "trace-3" || calls[1].TraceID != "trace-2" {
+		t.Errorf("kept entries = %+v, want trace-3 and trace-2", calls)
+	}
+}
+
+func TestDispatchToolCall_RecordsMCPErrorClassOnly(t *testing.T) {
+	a := newToolPolicyApp(t, eventTestGosutoYAML+`capabilities:
+  - name: allow-docs
+    mcp: docs
+    tool: "*"
+    allow: true
+`)
+	// The fake answers every tools/call with a tool error until the ready
+	// file exists; the error content must not reach the audit trail.
+	a.supv.Reconcile([]gosutospec.MCPServer{{
+		Name:    "docs",
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeMCPServerProcess$"},
+		Env:     map[string]string{fakeMCPEnv: "1", fakeMCPReadyEnv: filepath.Join(t.TempDir(), "never")},
+	}})
+	deadline := time.Now().Add(5 * time.Second)
+	for a.supv.Get("docs") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("fake MCP did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, err := a.DispatchToolCall(context.Background(), ToolDispatchRequest{
+		Caller: dispatchCallerLLM,
+		Name:   "docs__search",
+		Args:   map[string]interface{}{"query": toolCallValues[1]},
+	})
+	if err == nil || !strings.Contains(err.Error(), "still loading") {
+		t.Fatalf("err = %v, want the tool error", err)
+	}
+
+	resp, err := a.listToolCalls(0)
+	if err != nil {
+		t.Fatalf("listToolCalls: %v", err)
+	}
+	if len(resp.Calls) != 1 || resp.Calls[0].Error != "tool returned error" {
+		t.Fatalf("audit entries = %+v, want one with error %q", resp.Calls, "tool returned error")
+	}
+}
+
+func TestDispatchToolCall_TransformsToolErrorText(t *testing.T) {
+	a := newToolPolicyApp(t, eventTestGosutoYAML+`capabilities:
+  - name: allow-docs
+    mcp: docs
+    tool: "*"
+    allow: true
+    output:
+      redact:
+        - pattern: 'still \w+'
+`)
+	a.supv.Reconcile([]gosutospec.MCPServer{{
+		Name:    "docs",
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeMCPServerProcess$"},
+		Env:     map[string]string{fakeMCPEnv: "1", fakeMCPReadyEnv: filepath.Join(t.TempDir(), "never")},
+	}})
+	deadline := time.Now().Add(5 * time.Second)
+	for a.supv.Get("docs") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("fake MCP did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, err := a.DispatchToolCall(context.Background(), ToolDispatchRequest{
+		Caller: dispatchCallerLLM,
+		Name:   "docs__search",
+		Args:   map[string]interface{}{"query": "q"},
+	})
+	if err == nil || strings.Contains(err.Error(), "still loading") || !strings.Contains(err.Error(), "[REDACTED]") {
+		t.Fatalf("err = %v, want the tool error with its text redacted", err)
+	}
+}
+
+func TestTruncateUTF8_KeepsRunesWhole(t *testing.T) {
+	if got := truncateUTF8("héllo", 2); got != "h…" {
+		t.Errorf("truncateUTF8 = %q, want %q", got, "h…")
+	}
+	if got := truncateUTF8("héllo", 3); got != "hé…" {
+		t.Errorf("truncateUTF8 = %q, want %q", got, "hé…")
+	}
+	if got := truncateUTF8("short", 10); got != "short" {
+		t.Errorf("truncateUTF8 = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+// listTools implements control.Handlers.ListTools. It reports exactly what
+// gatherTools would offer the LLM on the next turn, so built-ins hidden by
+// policy (e.g. matrix.send_message without messaging targets) and MCP tools
+// filtered by the Gosuto allowlist are absent.
+func (a *App) listTools(ctx context.Context, withSchemas bool) (control.ToolListResponse, error) {
+	defs, toolMap := a.gatherTools(ctx)
+	resp := control.ToolListResponse{Tools: make([]control.ToolInfo, 0, len(defs))}
+	for _, d := range defs {
+		info := control.ToolInfo{
+			Name:        d.Function.Name,
+			MCP:         builtin.BuiltinMCPNamespace,
+			Tool:        d.Function.Name,
+			Description: d.Function.Description,
+		}
+		if ref, ok := toolMap[d.Function.Name]; ok {
+			info.MCP, info.Tool = ref[0], ref[1]
+		}
+		if withSchemas {
+			info.InputSchema = d.Function.Parameters
+		}
+		resp.Tools = append(resp.Tools, info)
+	}
+	return resp, nil
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+func findToolInfo(tools []control.ToolInfo, name string) (control.ToolInfo, bool) {
+	for _, t := range tools {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return control.ToolInfo{}, false
+}
+
+func TestListTools_ReflectsRunningMCPTools(t *testing.T) {
+	a := newToolPolicyApp(t, mcpToolsTestGosutoYAML)
+	startFakeMCP(t, a, "docs")
+
+	resp, err := a.listTools(context.Background(), false)
+	if err != nil {
+		t.Fatalf("listTools: %v", err)
+	}
+	search, ok := findToolInfo(resp.Tools, "docs__search")
+	if !ok {
+		t.Fatalf("docs__search missing from %+v", resp.Tools)
+	}
+	if search.MCP != "docs" || search.Tool != "search" {
+		t.Errorf("search source = %s/%s, want docs/search", search.MCP, search.Tool)
+	}
+	if search.Description != "Search the team knowledge base. Prefer short queries." {
+		t.Errorf("search description = %q, want the Gosuto override", search.Description)
+	}
+	if search.InputSchema != nil {
+		t.Error("input schema included without withSchemas")
+	}
+	if _, ok := findToolInfo(resp.Tools, "docs__delete_all"); ok {
+		t.Error("tool outside the MCP allowlist was listed")
+	}
+
+	resp, err = a.listTools(context.Background(), true)
+	if err != nil {
+		t.Fatalf("listTools(withSchemas): %v", err)
+	}
+	if search, _ := findToolInfo(resp.Tools, "docs__search"); search.InputSchema == nil {
+		t.Error("input schema missing with withSchemas")
+	}
+}
+
+func TestListTools_ExcludesPolicyHiddenBuiltins(t *testing.T) {
+	a := newToolPolicyApp(t, eventTestGosutoYAML)
+	resp, err := a.listTools(context.Background(), false)
+	if err != nil {
+		t.Fatalf("listTools: %v", err)
+	}
+	if _, ok := findToolInfo(resp.Tools, builtin.MatrixSendToolName); ok {
+		t.Errorf("%s listed without messaging targets", builtin.MatrixSendToolName)
+	}
+
+	a = newToolPolicyApp(t, toolsPolicyTestGosutoYAML_WithMessaging)
+	resp, err = a.listTools(context.Background(), false)
+	if err != nil {
+		t.Fatalf("listTools: %v", err)
+	}
+	send, ok := findToolInfo(resp.Tools, builtin.MatrixSendToolName)
+	if !ok {
+		t.Fatalf("%s not listed with messaging targets", builtin.MatrixSendToolName)
+	}
+	if send.MCP != builtin.BuiltinMCPNamespace || send.Tool != builtin.MatrixSendToolName {
+		t.Errorf("send source = %s/%s, want builtin/%s", send.MCP, send.Tool, builtin.MatrixSendToolName)
+	}
+}
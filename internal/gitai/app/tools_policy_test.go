@@ -12,11 +12,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/bdobrica/Ruriko/common/policy"
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
 	"github.com/bdobrica/Ruriko/internal/gitai/builtin"
 	"github.com/bdobrica/Ruriko/internal/gitai/gosuto"
 	"github.com/bdobrica/Ruriko/internal/gitai/llm"
-	"github.com/bdobrica/Ruriko/internal/gitai/policy"
 	"github.com/bdobrica/Ruriko/internal/gitai/store"
 	"github.com/bdobrica/Ruriko/internal/gitai/supervisor"
 )
@@ -58,6 +58,7 @@ func newToolPolicyApp(t *testing.T, gosutoYAML string) *App {
 	// Wire the built-in registry with matrix.send_message.
 	reg := builtin.New()
 	reg.Register(builtin.NewMatrixSendTool(&toolPolicyConfigProvider{ldr: ldr}, toolPolicyStubbedSender{}))
+	reg.Register(builtin.NewNotifyOperatorTool(&toolPolicyConfigProvider{ldr: ldr}, toolPolicyStubbedSender{}))
 
 	a := &App{
 		db:         db,
@@ -135,3 +136,25 @@ func TestGatherTools_IncludesMatrixSend_WhenMessagingConfigured(t *testing.T) {
 			builtin.MatrixSendToolName)
 	}
 }
+
+// TestGatherTools_NotifyOperator_OnlyWhenNotificationsConfigured verifies that
+// notify.operator is offered to the LLM only while the Gosuto config has a
+// notifications section.
+func TestGatherTools_NotifyOperator_OnlyWhenNotificationsConfigured(t *testing.T) {
+	a := newToolPolicyApp(t, eventTestGosutoYAML)
+	defs, _ := a.gatherTools(context.Background())
+	if hasToolDef(defs, builtin.NotifyOperatorToolName) {
+		t.Errorf("gatherTools exposed %q without a notifications section; want excluded",
+			builtin.NotifyOperatorToolName)
+	}
+
+	a = newToolPolicyApp(t, eventTestGosutoYAML+`notifications:
+  channel: admin-room
+  minSeverity: warning
+`)
+	defs, _ = a.gatherTools(context.Background())
+	if !hasToolDef(defs, builtin.NotifyOperatorToolName) {
+		t.Errorf("gatherTools did not expose %q with notifications configured; want included",
+			builtin.NotifyOperatorToolName)
+	}
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ToolArgsError is returned when tool-call arguments do not match the tool's
+// advertised input schema. The message lists every violation so the LLM can
+// correct its call on the next round instead of wasting an MCP round-trip.
+type ToolArgsError struct {
+	Tool     string
+	Problems []string
+}
+
+func (e *ToolArgsError) Error() string {
+	return fmt.Sprintf("invalid arguments for tool %q: %s (fix the arguments to match the tool's input schema and retry)",
+		e.Tool, strings.Join(e.Problems, "; "))
+}
+
+// toolSchema is a cached compiled input schema. err records why the
+// advertised schema could not be compiled; such tools are not validated, and
+// the failure is kept until the tool advertises a different schema.
+type toolSchema struct {
+	raw    []byte
+	schema *jsonschema.Schema
+	err    error
+}
+
+// toolSchemaCache holds the compiled input schemas of MCP tools, keyed by
+// composed tool name (mcp__tool). Schemas are recorded by gatherTools and
+// compiled lazily on first use; a changed schema is recompiled. The zero
+// value is ready to use.
+type toolSchemaCache struct {
+	mu      sync.Mutex
+	schemas map[string]*toolSchema
+}
+
+// record stores the advertised schema for name, discarding the compiled copy
+// if the schema changed since it was last seen.
+func (c *toolSchemaCache) record(name string, inputSchema interface{}) {
+	raw, err := json.Marshal(inputSchema)
+	if err != nil || inputSchema == nil {
+		raw = nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.schemas == nil {
+		c.schemas = make(map[string]*toolSchema)
+	}
+	if prev, ok := c.schemas[name]; ok && bytes.Equal(prev.raw, raw) {
+		return
+	}
+	if raw == nil {
+		delete(c.schemas, name)
+		return
+	}
+	c.schemas[name] = &toolSchema{raw: raw}
+}
+
+// compiled returns the compiled schema for name, compiling it on first use.
+// It returns nil when no usable schema is known.
+func (c *toolSchemaCache) compiled(name string) *jsonschema.Schema {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ts, ok := c.schemas[name]
+	if !ok || ts.err != nil {
+		return nil
+	}
+	if ts.schema == nil {
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(name, bytes.NewReader(ts.raw)); err != nil {
+			slog.Warn("tool input schema rejected; arguments will not be validated", "tool", name, "err", err)
+			ts.err = err
+			return nil
+		}
+		schema, err := compiler.Compile(name)
+		if err != nil {
+			slog.Warn("tool input schema does not compile; arguments will not be validated", "tool", name, "err", err)
+			ts.err = err
+			return nil
+		}
+		ts.schema = schema
+	}
+	return ts.schema
+}
+
+// validate checks args against the cached schema for name. Tools without a
+// known or compilable schema are not validated.
+func (c *toolSchemaCache) validate(name string, args map[string]interface{}) error {
+	schema := c.compiled(name)
+	if schema == nil {
+		return nil
+	}
+
+	// Round-trip through JSON so values built in Go (ints, typed slices)
+	// match the types the validator expects from decoded JSON.
+	var doc interface{} = map[string]interface{}{}
+	if len(args) > 0 {
+		b, err := json.Marshal(args)
+		if err != nil {
+			return &ToolArgsError{Tool: name, Problems: []string{err.Error()}}
+		}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return &ToolArgsError{Tool: name, Problems: []string{err.Error()}}
+		}
+	}
+
+	err := schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return &ToolArgsError{Tool: name, Problems: []string{err.Error()}}
+	}
+	return &ToolArgsError{Tool: name, Problems: schemaProblems(ve)}
+}
+
+// schemaProblems flattens a validation error into one line per leaf
+// violation, e.g. "/limit: must be >= 1 but found 0".
+func schemaProblems(ve *jsonschema.ValidationError) []string {
+	if len(ve.Causes) == 0 {
+		loc := ve.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		return []string{loc + ": " + ve.Message}
+	}
+	var out []string
+	for _, cause := range ve.Causes {
+		out = append(out, schemaProblems(cause)...)
+	}
+	return out
+}
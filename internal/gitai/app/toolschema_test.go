@@ -0,0 +1,136 @@
+package app
+
+// Tests for JSON-schema validation of MCP tool arguments before dispatch.
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+const toolSchemaTestGosutoYAML = eventTestGosutoYAML + `capabilities:
+  - name: allow-docs
+    mcp: docs
+    tool: "*"
+    allow: true
+mcps:
+  - name: docs
+    command: fake
+`
+
+// newToolSchemaApp returns an App with the fake MCP running as "docs" and
+// its tool schemas recorded by a gatherTools pass.
+func newToolSchemaApp(t *testing.T) (*App, func() []string) {
+	t.Helper()
+	a := newToolPolicyApp(t, toolSchemaTestGosutoYAML)
+	calls := startFakeMCP(t, a, "docs")
+	a.gatherTools(context.Background())
+	return a, calls
+}
+
+func llmToolCall(name, args string) llm.ToolCall {
+	return llm.ToolCall{ID: "call-1", Function: llm.FunctionCall{Name: name, Arguments: args}}
+}
+
+func TestExecuteToolCall_ValidArgsAreDispatched(t *testing.T) {
+	a, calls := newToolSchemaApp(t)
+
+	got, err := a.executeToolCall(context.Background(), "!room", "@user:example.com",
+		llmToolCall("docs__search", `{"query":"ruriko","limit":5}`))
+	if err != nil {
+		t.Fatalf("executeToolCall: %v", err)
+	}
+	if !strings.Contains(got, "ok:search") {
+		t.Errorf("result = %q, want the MCP's response", got)
+	}
+	if c := calls(); len(c) != 1 || c[0] != "search" {
+		t.Errorf("MCP calls = %v, want [search]", c)
+	}
+}
+
+func TestExecuteToolCall_InvalidArgsAreRejectedWithoutMCPCall(t *testing.T) {
+	a, calls := newToolSchemaApp(t)
+
+	cases := []struct {
+		name    string
+		args    string
+		mention string
+	}{
+		{"missing required", `{"limit":5}`, "query"},
+		{"wrong type", `{"query":42}`, "/query"},
+		{"below minimum", `{"query":"x","limit":0}`, "/limit"},
+		{"no arguments", ``, "query"},
+	}
+	for _, tc := range cases {
+		_, err := a.executeToolCall(context.Background(), "!room", "@user:example.com",
+			llmToolCall("docs__search", tc.args))
+		var argsErr *ToolArgsError
+		if !errors.As(err, &argsErr) {
+			t.Errorf("%s: err = %v, want *ToolArgsError", tc.name, err)
+			continue
+		}
+		if argsErr.Tool != "docs__search" || !strings.Contains(err.Error(), tc.mention) {
+			t.Errorf("%s: unhelpful error %q, want mention of %q", tc.name, err, tc.mention)
+		}
+	}
+	if c := calls(); len(c) != 0 {
+		t.Errorf("MCP calls = %v, want none for invalid arguments", c)
+	}
+}
+
+func TestExecuteToolCall_ToolWithoutSchemaIsNotValidated(t *testing.T) {
+	a, calls := newToolSchemaApp(t)
+
+	if _, err := a.executeToolCall(context.Background(), "!room", "@user:example.com",
+		llmToolCall("docs__fetch", `{"anything":true}`)); err != nil {
+		t.Fatalf("executeToolCall: %v", err)
+	}
+	if c := calls(); len(c) != 1 || c[0] != "fetch" {
+		t.Errorf("MCP calls = %v, want [fetch]", c)
+	}
+}
+
+func TestToolSchemaCache_RecompilesChangedSchema(t *testing.T) {
+	var c toolSchemaCache
+	c.record("m__t", map[string]interface{}{"type": "object", "required": []string{"a"}})
+	if err := c.validate("m__t", map[string]interface{}{"b": 1}); err == nil {
+		t.Fatal("expected missing property a to be rejected")
+	}
+	first := c.compiled("m__t")
+	if c.compiled("m__t") != first {
+		t.Error("expected compiled schema to be cached")
+	}
+
+	c.record("m__t", map[string]interface{}{"type": "object", "required": []string{"b"}})
+	if err := c.validate("m__t", map[string]interface{}{"b": 1}); err != nil {
+		t.Errorf("expected updated schema to be used, got %v", err)
+	}
+}
+
+func TestToolSchemaCache_CachesCompileFailure(t *testing.T) {
+	var c toolSchemaCache
+	bad := map[string]interface{}{"type": "object", "properties": map[string]interface{}{"a": map[string]interface{}{"type": 42}}}
+	c.record("m__t", bad)
+	if c.compiled("m__t") != nil {
+		t.Fatal("expected an invalid schema not to compile")
+	}
+	failed := c.schemas["m__t"]
+	if failed.err == nil {
+		t.Fatal("expected the compile error to be cached")
+	}
+
+	// Re-advertising the same schema keeps the cached failure.
+	c.record("m__t", bad)
+	if c.schemas["m__t"] != failed || c.compiled("m__t") != nil {
+		t.Error("expected the failed compile to be reused for an unchanged schema")
+	}
+
+	// A corrected schema is compiled afresh.
+	c.record("m__t", map[string]interface{}{"type": "object", "required": []string{"a"}})
+	if err := c.validate("m__t", map[string]interface{}{}); err == nil {
+		t.Error("expected the corrected schema to be used")
+	}
+}
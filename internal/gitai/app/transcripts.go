@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/bdobrica/Ruriko/common/redact"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// defaultTurnTranscriptRetention is used when Config.TurnTranscriptRetention
+// is unset.
+const defaultTurnTranscriptRetention = 100
+
+// transcriptsEnabled reports whether turn transcripts are recorded.
+func (a *App) transcriptsEnabled() bool {
+	return a.cfg != nil && a.cfg.TurnTranscriptsEnabled
+}
+
+// transcriptRetention returns the number of transcripts kept.
+func (a *App) transcriptRetention() int {
+	if a.cfg == nil || a.cfg.TurnTranscriptRetention <= 0 {
+		return defaultTurnTranscriptRetention
+	}
+	return a.cfg.TurnTranscriptRetention
+}
+
+// recordTranscript stores the message history of the turn traceID. Every
+// message is redacted first: the values of the agent's cached secrets and
+// well-known credential formats never reach the database. A failed write is
+// logged, never returned, so transcripts cannot change a turn's outcome.
+func (a *App) recordTranscript(traceID string, messages []llm.Message) {
+	if a.db == nil || traceID == "" {
+		return
+	}
+	scrub := a.transcriptRedactor()
+	out := make([]control.TranscriptMessage, 0, len(messages))
+	for _, m := range messages {
+		tm := control.TranscriptMessage{
+			Role:       string(m.Role),
+			Content:    scrub(m.Content),
+			ToolCallID: m.ToolCallID,
+			Name:       m.Name,
+		}
+		for _, tc := range m.ToolCalls {
+			tm.ToolCalls = append(tm.ToolCalls, control.TranscriptToolCall{
+				ID:        tc.ID,
+				Name:      tc.Function.Name,
+				Arguments: scrub(tc.Function.Arguments),
+			})
+		}
+		out = append(out, tm)
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		slog.Warn("turn transcript encode failed", "trace_id", traceID, "err", err)
+		return
+	}
+	if err := a.db.RecordTranscript(traceID, string(raw), a.transcriptRetention()); err != nil {
+		slog.Warn("turn transcript write failed", "trace_id", traceID, "err", err)
+	}
+}
+
+// transcriptRedactor returns a function that scrubs the current secret
+// values and well-known credential formats from a string.
+func (a *App) transcriptRedactor() func(string) string {
+	var values []string
+	if a.secretsMgr != nil {
+		st := a.secretsMgr.BackingStore()
+		for _, name := range st.Names() {
+			if v, err := st.GetString(name); err == nil {
+				values = append(values, v)
+			}
+		}
+	}
+	return func(s string) string {
+		return redact.Secrets(redact.String(s, values...))
+	}
+}
+
+// turnTranscript implements control.Handlers.TurnTranscript.
+func (a *App) turnTranscript(traceID string) (control.TurnTranscriptResponse, error) {
+	t, found, err := a.db.GetTranscript(traceID)
+	if err != nil {
+		return control.TurnTranscriptResponse{}, err
+	}
+	if !found {
+		if !a.transcriptsEnabled() {
+			return control.TurnTranscriptResponse{}, fmt.Errorf("%w (turn transcripts are disabled; set FEATURE_TURN_TRANSCRIPTS)",
+				control.ErrTurnTranscriptNotFound)
+		}
+		return control.TurnTranscriptResponse{}, control.ErrTurnTranscriptNotFound
+	}
+	resp := control.TurnTranscriptResponse{TraceID: t.TraceID, At: t.CreatedAt}
+	if err := json.Unmarshal([]byte(t.MessagesJSON), &resp.Messages); err != nil {
+		return control.TurnTranscriptResponse{}, fmt.Errorf("decode transcript: %w", err)
+	}
+	return resp, nil
+}
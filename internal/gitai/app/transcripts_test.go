@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+	"github.com/bdobrica/Ruriko/internal/gitai/secrets"
+)
+
+const transcriptSecret = "hunter2-transcript-value"
+
+// transcriptLLM requests one tool call whose arguments echo the agent's
+// secret, then answers with a reply that contains an API key.
+type transcriptLLM struct {
+	mu     sync.Mutex
+	rounds int
+}
+
+func (l *transcriptLLM) Complete(_ context.Context, _ llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rounds++
+	if l.rounds == 1 {
+		return &llm.CompletionResponse{
+			Message: llm.Message{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{
+				ID:       "call-1",
+				Type:     "function",
+				Function: llm.FunctionCall{Name: "nothing__here", Arguments: `{"q":"` + transcriptSecret + `"}`},
+			}}},
+			FinishReason: "tool_calls",
+		}, nil
+	}
+	return &llm.CompletionResponse{
+		Message:      llm.Message{Role: llm.RoleAssistant, Content: "done with sk-abcdefghijklmnopqrstuvwxyz"},
+		FinishReason: "stop",
+	}, nil
+}
+
+func newTranscriptApp(t *testing.T, prov llm.Provider, cfg *Config) *App {
+	t.Helper()
+	a := newEventApp(t, eventTestGosutoYAML, prov)
+	a.cfg = cfg
+	a.secretsMgr = secrets.NewManager(secrets.New(), time.Hour)
+	applySecret(t, a.secretsMgr, "api.token", transcriptSecret, time.Hour)
+	return a
+}
+
+func runTracedTurn(t *testing.T, a *App, traceID, text string) {
+	t.Helper()
+	ctx := trace.WithTraceID(context.Background(), traceID)
+	if _, _, err := a.runTurn(ctx, "!chat-room:example.com", "@user:example.com", text, ""); err != nil {
+		t.Fatalf("runTurn: %v", err)
+	}
+}
+
+func TestTurnTranscript_CapturedAndRedacted(t *testing.T) {
+	a := newTranscriptApp(t, &transcriptLLM{}, &Config{TurnTranscriptsEnabled: true})
+
+	runTracedTurn(t, a, "trace-transcript", "please use "+transcriptSecret)
+
+	resp, err := a.turnTranscript("trace-transcript")
+	if err != nil {
+		t.Fatalf("turnTranscript: %v", err)
+	}
+	var roles []string
+	for _, m := range resp.Messages {
+		roles = append(roles, m.Role)
+	}
+	if got := strings.Join(roles, ","); got != "system,user,assistant,tool,assistant" {
+		t.Fatalf("roles = %s, want system,user,assistant,tool,assistant", got)
+	}
+	if !strings.Contains(resp.Messages[0].Content, "helpful test agent") {
+		t.Errorf("system message = %q, want the persona prompt", resp.Messages[0].Content)
+	}
+	user, call, reply := resp.Messages[1], resp.Messages[2], resp.Messages[4]
+	if strings.Contains(user.Content, transcriptSecret) || !strings.Contains(user.Content, "[REDACTED]") {
+		t.Errorf("user message not redacted: %q", user.Content)
+	}
+	if len(call.ToolCalls) != 1 || call.ToolCalls[0].Name != "nothing__here" {
+		t.Fatalf("tool calls = %+v, want one call to nothing__here", call.ToolCalls)
+	}
+	if strings.Contains(call.ToolCalls[0].Arguments, transcriptSecret) {
+		t.Errorf("tool call arguments not redacted: %q", call.ToolCalls[0].Arguments)
+	}
+	if resp.Messages[3].ToolCallID != "call-1" {
+		t.Errorf("tool message call ID = %q, want call-1", resp.Messages[3].ToolCallID)
+	}
+	if strings.Contains(reply.Content, "sk-abcdefghijklmnopqrstuvwxyz") {
+		t.Errorf("assistant reply not redacted: %q", reply.Content)
+	}
+}
+
+func TestTurnTranscript_RetentionKeepsNewest(t *testing.T) {
+	a := newTranscriptApp(t, newCapturingLLM("ok"), &Config{TurnTranscriptsEnabled: true, TurnTranscriptRetention: 2})
+
+	for _, id := range []string{"trace-1", "trace-2", "trace-3"} {
+		runTracedTurn(t, a, id, "hello")
+	}
+
+	if _, err := a.turnTranscript("trace-1"); !errors.Is(err, control.ErrTurnTranscriptNotFound) {
+		t.Errorf("oldest transcript: err = %v, want ErrTurnTranscriptNotFound", err)
+	}
+	for _, id := range []string{"trace-2", "trace-3"} {
+		if _, err := a.turnTranscript(id); err != nil {
+			t.Errorf("transcript %s: %v", id, err)
+		}
+	}
+}
+
+func TestTurnTranscript_DisabledRecordsNothing(t *testing.T) {
+	a := newTranscriptApp(t, newCapturingLLM("ok"), &Config{})
+
+	runTracedTurn(t, a, "trace-off", "hello")
+
+	_, err := a.turnTranscript("trace-off")
+	if !errors.Is(err, control.ErrTurnTranscriptNotFound) {
+		t.Fatalf("err = %v, want ErrTurnTranscriptNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "FEATURE_TURN_TRANSCRIPTS") {
+		t.Errorf("err = %v, want a hint that transcripts are disabled", err)
+	}
+}
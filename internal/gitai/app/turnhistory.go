@@ -0,0 +1,55 @@
+package app
+
+import (
+	"github.com/bdobrica/Ruriko/common/redact"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+// defaultTurnListLimit and maxTurnListLimit bound GET /turns.
+const (
+	defaultTurnListLimit = 20
+	maxTurnListLimit     = 500
+)
+
+// maxTurnErrorLen caps the error text returned per turn summary.
+const maxTurnErrorLen = 300
+
+// turnRunning is the status of a turn that has not finished yet.
+const turnRunning = "running"
+
+// listTurns implements control.Handlers.ListTurns.
+func (a *App) listTurns(limit int) (control.TurnListResponse, error) {
+	if limit <= 0 {
+		limit = defaultTurnListLimit
+	}
+	if limit > maxTurnListLimit {
+		limit = maxTurnListLimit
+	}
+	turns, err := a.db.ListTurns(limit)
+	if err != nil {
+		return control.TurnListResponse{}, err
+	}
+	resp := control.TurnListResponse{Turns: make([]control.TurnSummary, 0, len(turns))}
+	for _, t := range turns {
+		status := t.Result
+		if status == "" {
+			status = turnRunning
+		}
+		// Turn errors can quote tool output; scrub credentials and keep
+		// the entry short.
+		errMsg := truncateUTF8(redact.Secrets(t.Error), maxTurnErrorLen)
+		resp.Turns = append(resp.Turns, control.TurnSummary{
+			TraceID:    t.TraceID,
+			Trigger:    t.Trigger,
+			Sender:     t.Sender,
+			Gateway:    t.Gateway,
+			EventType:  t.EventType,
+			Status:     status,
+			ToolCalls:  t.ToolCalls,
+			DurationMS: t.DurationMS,
+			Error:      errMsg,
+			StartedAt:  t.StartedAt,
+		})
+	}
+	return resp, nil
+}
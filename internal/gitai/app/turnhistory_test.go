@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestListTurns_SummarizesTurns(t *testing.T) {
+	prov := newCapturingLLM("pong")
+	a, _ := newStreamingApp(t, prov)
+
+	a.handleMessage(context.Background(), directedEvent("Hey test-agent, ping"))
+	if _, ok := prov.waitForCall(time.Second); !ok {
+		t.Fatal("expected the message to run a turn")
+	}
+	if _, err := a.db.LogGatewayTurn("trace-gw", "!admin:example.com", "gateway:cron", "tick", "cron", "cron.tick"); err != nil {
+		t.Fatalf("LogGatewayTurn: %v", err)
+	}
+
+	resp, err := a.listTurns(0)
+	if err != nil {
+		t.Fatalf("listTurns: %v", err)
+	}
+	if len(resp.Turns) != 2 {
+		t.Fatalf("turns = %+v, want 2", resp.Turns)
+	}
+	gw, chat := resp.Turns[0], resp.Turns[1]
+	if gw.TraceID != "trace-gw" || gw.Trigger != "gateway" || gw.Gateway != "cron" || gw.EventType != "cron.tick" || gw.Status != turnRunning {
+		t.Errorf("newest turn = %+v, want the running cron turn", gw)
+	}
+	if chat.Trigger != "matrix" || chat.Sender != "@user:example.com" || chat.Status != "success" || chat.TraceID == "" || chat.StartedAt.IsZero() {
+		t.Errorf("chat turn = %+v, want a successful matrix turn", chat)
+	}
+
+	if resp, err := a.listTurns(1); err != nil || len(resp.Turns) != 1 || resp.Turns[0].TraceID != "trace-gw" {
+		t.Errorf("listTurns(1) = %+v, %v; want only the newest turn", resp.Turns, err)
+	}
+}
+
+func TestListTurns_TruncatesErrorOnRuneBoundary(t *testing.T) {
+	a, _ := newStreamingApp(t, newCapturingLLM("pong"))
+
+	id, err := a.db.LogTurn("trace-long", "!admin:example.com", "@user:example.com", "hi")
+	if err != nil {
+		t.Fatalf("LogTurn: %v", err)
+	}
+	// A one-byte prefix puts every later multi-byte rune across the cap.
+	if err := a.db.FinishTurn(id, 0, "error", "x"+strings.Repeat("é", maxTurnErrorLen)); err != nil {
+		t.Fatalf("FinishTurn: %v", err)
+	}
+
+	resp, err := a.listTurns(1)
+	if err != nil || len(resp.Turns) != 1 {
+		t.Fatalf("listTurns = %+v, %v", resp.Turns, err)
+	}
+	got := resp.Turns[0].Error
+	if !utf8.ValidString(got) || !strings.HasSuffix(got, "…") {
+		t.Errorf("error = %q, want valid UTF-8 ending in an ellipsis", got)
+	}
+	if n := len(strings.TrimSuffix(got, "…")); n > maxTurnErrorLen {
+		t.Errorf("truncated error is %d bytes, want at most %d", n, maxTurnErrorLen)
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errAgentBusy is returned by runEventTurn when limits.maxConcurrentRequests
+// turns are already in flight.
+var errAgentBusy = errors.New("agent busy: maxConcurrentRequests turns in flight")
+
+// busyReply is the notice sent to a Matrix message that arrives while the
+// agent is at its concurrency limit.
+const busyReply = "⏳ Busy with other requests — please try again in a moment."
+
+// rateLimitReply is the notice sent to a Matrix message from a sender that
+// has used up limits.maxRequestsPerMinute for the current minute. A sender
+// gets it at most once a minute; further dropped messages are not answered.
+const rateLimitReply = "⏳ Rate limit reached — please wait a minute before sending more requests."
+
+// turnLimiter is a semaphore capping the turns in flight at the Gosuto
+// limits.maxConcurrentRequests. A limit of 0 means unlimited. The limit is
+// reset on every config apply; turns already running keep their slot, so
+// after a decrease the new limit applies once enough of them finish. The
+// zero value is unlimited.
+type turnLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	// freed is closed (and cleared) when a slot may have become available,
+	// waking acquire calls. Created lazily by the first waiter.
+	freed chan struct{}
+}
+
+// setLimit changes the number of concurrent turns allowed.
+func (l *turnLimiter) setLimit(n int) {
+	l.mu.Lock()
+	l.limit = n
+	l.wakeLocked()
+	l.mu.Unlock()
+}
+
+// tryAcquire takes a slot without blocking and reports whether it got one.
+// Every successful call must be paired with release.
+func (l *turnLimiter) tryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit > 0 && l.inFlight >= l.limit {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+// acquire takes a slot, waiting until one is free or ctx is done. Every
+// nil return must be paired with release.
+func (l *turnLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.limit <= 0 || l.inFlight < l.limit {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		if l.freed == nil {
+			l.freed = make(chan struct{})
+		}
+		freed := l.freed
+		l.mu.Unlock()
+
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release returns a slot taken by tryAcquire or acquire.
+func (l *turnLimiter) release() {
+	l.mu.Lock()
+	l.inFlight--
+	l.wakeLocked()
+	l.mu.Unlock()
+}
+
+// wakeLocked wakes every acquire waiting for a slot. Caller must hold l.mu.
+func (l *turnLimiter) wakeLocked() {
+	if l.freed != nil {
+		close(l.freed)
+		l.freed = nil
+	}
+}
@@ -0,0 +1,247 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"maunium.net/go/mautrix/id"
+
+	"github.com/bdobrica/Ruriko/common/ratelimit"
+)
+
+func TestTurnLimiter(t *testing.T) {
+	var l turnLimiter
+	for i := 0; i < 3; i++ {
+		if !l.tryAcquire() {
+			t.Fatal("zero-value limiter refused a turn")
+		}
+	}
+
+	l.setLimit(4)
+	if !l.tryAcquire() {
+		t.Fatal("expected the fourth slot")
+	}
+	if l.tryAcquire() {
+		t.Fatal("expected the limiter to be full")
+	}
+	l.release()
+	if !l.tryAcquire() {
+		t.Fatal("expected a released slot to be reusable")
+	}
+
+	l.setLimit(0)
+	if !l.tryAcquire() {
+		t.Fatal("limit 0 must be unlimited")
+	}
+
+	// acquire waits for a slot; raising the limit frees one.
+	l.setLimit(5)
+	got := make(chan error, 1)
+	go func() { got <- l.acquire(context.Background()) }()
+	select {
+	case <-got:
+		t.Fatal("acquire returned while the limiter was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	l.setLimit(6)
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire did not wake when the limit was raised")
+	}
+}
+
+const turnLimitGosutoYAML = eventTestGosutoYAML + `limits:
+  maxConcurrentRequests: 1
+`
+
+func TestApplyConfig_SetsTurnLimit(t *testing.T) {
+	a := newConfigApplyApp(t)
+	if err := a.applyConfig(turnLimitGosutoYAML, "h"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if !a.turns.tryAcquire() {
+		t.Fatal("expected one slot")
+	}
+	if a.turns.tryAcquire() {
+		t.Fatal("expected maxConcurrentRequests: 1 to refuse a second turn")
+	}
+
+	// Dropping the limit in a later config lifts it.
+	if err := a.applyConfig(eventTestGosutoYAML, "h2"); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if !a.turns.tryAcquire() {
+		t.Fatal("expected the limit to be lifted")
+	}
+}
+
+func TestRunEventTurn_DropsWhenBusy(t *testing.T) {
+	prov := newCapturingLLM("unused")
+	a := newEventApp(t, turnLimitGosutoYAML, prov)
+	a.turns.setLimit(1)
+	a.turns.tryAcquire() // a turn already in flight
+
+	evt := makeTestEvent("scheduler", "cron.tick", "tick")
+	if err := a.runEventTurn(context.Background(), evt); !errors.Is(err, errAgentBusy) {
+		t.Fatalf("runEventTurn err = %v, want errAgentBusy", err)
+	}
+	a.processEvent(context.Background(), evt)
+	if _, ok := prov.waitForCall(100 * time.Millisecond); ok {
+		t.Fatal("LLM called while at the concurrency limit")
+	}
+	dl, err := a.db.ListDeadLetters()
+	if err != nil {
+		t.Fatalf("ListDeadLetters: %v", err)
+	}
+	if len(dl) != 0 {
+		t.Errorf("dead letters = %d, want busy events dropped", len(dl))
+	}
+
+	a.turns.release()
+	if err := a.runEventTurn(context.Background(), evt); err != nil {
+		t.Fatalf("runEventTurn after release: %v", err)
+	}
+	if _, ok := prov.waitForCall(time.Second); !ok {
+		t.Fatal("expected the turn to run once a slot is free")
+	}
+}
+
+func TestRunEventTurn_OnStartupWaitsForSlot(t *testing.T) {
+	prov := newCapturingLLM("unused")
+	a := newEventApp(t, turnLimitGosutoYAML, prov)
+	a.turns.setLimit(1)
+	a.turns.tryAcquire() // a turn already in flight
+
+	evt := makeTestEvent(onStartupSource, "onStartup", "check in")
+	done := make(chan error, 1)
+	go func() { done <- a.runEventTurn(context.Background(), evt) }()
+	if _, ok := prov.waitForCall(100 * time.Millisecond); ok {
+		t.Fatal("LLM called while at the concurrency limit")
+	}
+
+	a.turns.release()
+	if _, ok := prov.waitForCall(time.Second); !ok {
+		t.Fatal("expected the onStartup turn to run once a slot is free")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("runEventTurn: %v", err)
+	}
+}
+
+func TestRunEventTurn_OnStartupWaitIsBoundedByContext(t *testing.T) {
+	prov := newCapturingLLM("unused")
+	a := newEventApp(t, turnLimitGosutoYAML, prov)
+	a.turns.setLimit(1)
+	a.turns.tryAcquire()
+	defer a.turns.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	evt := makeTestEvent(onStartupSource, "onStartup", "check in")
+	if err := a.runEventTurn(ctx, evt); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("runEventTurn err = %v, want context.DeadlineExceeded", err)
+	}
+	if _, ok := prov.waitForCall(50 * time.Millisecond); ok {
+		t.Fatal("LLM called while at the concurrency limit")
+	}
+}
+
+func TestHandleMessage_BusyWhenAtLimit(t *testing.T) {
+	prov := newCapturingLLM("unused")
+	a, rec := newStreamingApp(t, prov)
+	a.turns.setLimit(1)
+	a.turns.tryAcquire()
+
+	a.handleMessage(context.Background(), directedEvent("Hey test-agent, hello"))
+
+	if _, ok := prov.waitForCall(100 * time.Millisecond); ok {
+		t.Fatal("LLM called while at the concurrency limit")
+	}
+	if posts, _ := rec.snapshot(); len(posts) != 0 {
+		t.Errorf("posts = %q, want no streamed reply", posts)
+	}
+}
+
+const senderRateGosutoYAML = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms:
+    - "!chat-room:example.com"
+  allowedSenders:
+    - "*"
+  adminRoom: "!admin-room:example.com"
+limits:
+  maxRequestsPerMinute: 3
+persona:
+  llmProvider: openai
+  model: gpt-4o-mini
+`
+
+// sendFrom delivers n directed messages from sender and returns how many of
+// them reached the LLM.
+func sendFrom(a *App, prov *capturingLLM, sender string, n int) int {
+	for i := 0; i < n; i++ {
+		evt := directedEvent("Hey test-agent, ping")
+		evt.Sender = id.UserID(sender)
+		a.handleMessage(context.Background(), evt)
+	}
+	calls := 0
+	for {
+		select {
+		case <-prov.requests:
+			calls++
+		default:
+			return calls
+		}
+	}
+}
+
+func TestHandleMessage_SenderRateLimit(t *testing.T) {
+	prov := newCapturingLLM("pong")
+	a, _ := newStreamingApp(t, prov)
+	if err := a.gosutoLdr.Apply([]byte(senderRateGosutoYAML)); err != nil {
+		t.Fatalf("gosuto loader Apply: %v", err)
+	}
+	clock := time.Unix(1_700_000_000, 0)
+	a.senderRL = ratelimit.NewKeyedFixedWindowWithClock(time.Minute, func() time.Time { return clock })
+	a.senderRLNotice = ratelimit.NewKeyedFixedWindowWithClock(time.Minute, func() time.Time { return clock })
+
+	if got := sendFrom(a, prov, "@alice:example.com", 5); got != 3 {
+		t.Fatalf("processed %d of 5 messages, want 3", got)
+	}
+	// The limit is per sender.
+	if got := sendFrom(a, prov, "@bob:example.com", 1); got != 1 {
+		t.Errorf("other sender processed %d, want 1", got)
+	}
+	// Alice's two dropped messages used her one notice for the window; Bob
+	// was never limited and still has his.
+	if a.senderRLNotice.Allow(1, "@alice:example.com") {
+		t.Error("alice's rate-limit notice was not used")
+	}
+	if !a.senderRLNotice.Allow(1, "@bob:example.com") {
+		t.Error("bob's rate-limit notice was used without a dropped message")
+	}
+
+	// A new window starts a fresh count.
+	clock = clock.Add(61 * time.Second)
+	if got := sendFrom(a, prov, "@alice:example.com", 5); got != 3 {
+		t.Errorf("processed %d of 5 messages after rollover, want 3", got)
+	}
+
+	// A config with a higher limit applies to the current window.
+	clock = clock.Add(61 * time.Second)
+	if err := a.gosutoLdr.Apply([]byte(strings.Replace(senderRateGosutoYAML, "maxRequestsPerMinute: 3", "maxRequestsPerMinute: 4", 1))); err != nil {
+		t.Fatalf("gosuto loader Apply: %v", err)
+	}
+	if got := sendFrom(a, prov, "@alice:example.com", 6); got != 4 {
+		t.Errorf("processed %d of 6 messages with limit 4, want 4", got)
+	}
+}
@@ -68,6 +68,9 @@ func (d *workflowDispatcher) Summarize(ctx context.Context, prompt string, _ *wo
 	if err := d.app.enforceLLMCallHardLimit(); err != nil {
 		return "", err
 	}
+	if err := d.app.checkBudget(cfg); err != nil {
+		return "", err
+	}
 
 	systemPrompt := buildSystemPrompt(cfg, buildMessagingTargets(cfg), "")
 	resp, err := prov.Complete(ctx, llm.CompletionRequest{
@@ -83,5 +86,6 @@ func (d *workflowDispatcher) Summarize(ctx context.Context, prompt string, _ *wo
 	if resp == nil {
 		return "", fmt.Errorf("workflow summarize returned no response")
 	}
+	d.app.recordSpend(d.app.llmModel(cfg), resp.Usage)
 	return resp.Message.Content, nil
 }
@@ -0,0 +1,262 @@
+package builtin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// AgentRequestToolName is the canonical name of the built-in tool that sends
+// a request to a peer agent and waits for its reply.
+const AgentRequestToolName = "agent.request"
+
+// Reply wait bounds for agent.request.
+const (
+	// DefaultAgentRequestTimeout is how long agent.request waits for a reply
+	// when the call does not set timeout_seconds.
+	DefaultAgentRequestTimeout = 30 * time.Second
+	// MaxAgentRequestTimeout caps the wait a call may ask for.
+	MaxAgentRequestTimeout = 120 * time.Second
+)
+
+// Correlation markers. A request carries "[agent-request:<token>]"; the
+// peer's Gitai runtime appends "[agent-reply:<token>]" to the reply of the
+// turn that request started, so the requester can match it without relying
+// on the peer's LLM to copy the token.
+var (
+	requestMarkerRe = regexp.MustCompile(`\[agent-request:([0-9a-f]{16})\]`)
+	replyMarkerRe   = regexp.MustCompile(`\[agent-reply:([0-9a-f]{16})\]`)
+)
+
+// RequestMarker returns the marker appended to a request with token.
+func RequestMarker(token string) string { return "[agent-request:" + token + "]" }
+
+// ReplyMarker returns the marker a peer appends to its reply to token.
+func ReplyMarker(token string) string { return "[agent-reply:" + token + "]" }
+
+// RequestToken returns the correlation token of the agent.request marker in
+// body, if any.
+func RequestToken(body string) (string, bool) {
+	m := requestMarkerRe.FindStringSubmatch(body)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// StripRequestMarker removes the agent.request marker from body so the
+// request reads as the peer wrote it.
+func StripRequestMarker(body string) string {
+	if !requestMarkerRe.MatchString(body) {
+		return body
+	}
+	return strings.TrimSpace(requestMarkerRe.ReplaceAllString(body, ""))
+}
+
+// ReplyRouter hands peer replies to the agent.request calls waiting for
+// them. The app feeds every inbound message through Deliver; a message that
+// carries the reply marker of a pending request, in the room the request was
+// sent to and from the peer it was addressed to, resolves that request and is
+// not processed further.
+type ReplyRouter struct {
+	mu      sync.Mutex
+	pending map[string]pendingRequest
+}
+
+type pendingRequest struct {
+	roomID string
+	// sender is the peer's Matrix user ID, or "" when the config does not
+	// name one and any allowed sender in roomID may answer.
+	sender string
+	reply  chan string
+}
+
+// NewReplyRouter returns an empty ReplyRouter.
+func NewReplyRouter() *ReplyRouter {
+	return &ReplyRouter{pending: make(map[string]pendingRequest)}
+}
+
+// expect registers a pending request for token in roomID, answerable by
+// sender (any sender when empty).
+func (r *ReplyRouter) expect(roomID, sender, token string) <-chan string {
+	ch := make(chan string, 1)
+	r.mu.Lock()
+	r.pending[token] = pendingRequest{roomID: roomID, sender: sender, reply: ch}
+	r.mu.Unlock()
+	return ch
+}
+
+// forget drops the pending request for token.
+func (r *ReplyRouter) forget(token string) {
+	r.mu.Lock()
+	delete(r.pending, token)
+	r.mu.Unlock()
+}
+
+// Deliver matches body, sent by sender in roomID, against the pending
+// requests and reports whether it was a reply to one of them. The reply
+// marker is stripped from the text handed to the waiting call. Replies from
+// any other room or from anyone but the addressed peer are ignored. A nil
+// router never matches.
+func (r *ReplyRouter) Deliver(roomID, sender, body string) bool {
+	if r == nil {
+		return false
+	}
+	m := replyMarkerRe.FindStringSubmatch(body)
+	if m == nil {
+		return false
+	}
+	r.mu.Lock()
+	p, ok := r.pending[m[1]]
+	ok = ok && p.roomID == roomID && (p.sender == "" || p.sender == sender)
+	if ok {
+		delete(r.pending, m[1])
+	}
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+	p.reply <- strings.TrimSpace(replyMarkerRe.ReplaceAllString(body, ""))
+	return true
+}
+
+// AgentRequestTool implements the agent.request built-in tool. It sends a
+// message to an allowed messaging target with a fresh correlation token and
+// blocks until the peer's reply tagged with that token arrives, returning
+// the reply as the tool result. The wait is bounded by timeout_seconds
+// (default DefaultAgentRequestTimeout, at most MaxAgentRequestTimeout) and
+// by the turn's context.
+type AgentRequestTool struct {
+	cfg     MessagingConfigProvider
+	sender  MatrixSender
+	replies *ReplyRouter
+	rl      *rateLimiter
+}
+
+// NewAgentRequestTool constructs an AgentRequestTool. Replies are matched
+// through replies, which the app must feed with inbound messages. All
+// arguments must be non-nil.
+func NewAgentRequestTool(cfg MessagingConfigProvider, sender MatrixSender, replies *ReplyRouter) *AgentRequestTool {
+	return &AgentRequestTool{
+		cfg:     cfg,
+		sender:  sender,
+		replies: replies,
+		rl:      &rateLimiter{},
+	}
+}
+
+// Definition returns the LLM-facing tool specification for agent.request.
+func (t *AgentRequestTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Type: "function",
+		Function: llm.FunctionDef{
+			Name: AgentRequestToolName,
+			Description: "Send a request to another agent and wait for its reply, which is returned " +
+				"as the result. Address the agent as you would with matrix.send_message " +
+				"(e.g. \"Hey kumo, ...\"). Fails if no reply arrives in time.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"target": map[string]interface{}{
+						"type": "string",
+						"description": "Alias of the target from the allowed targets list " +
+							"(e.g. \"kumo\"). Unknown aliases are rejected.",
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "The request to send.",
+					},
+					"timeout_seconds": map[string]interface{}{
+						"type": "integer",
+						"description": fmt.Sprintf("How long to wait for the reply (default %d, max %d).",
+							int(DefaultAgentRequestTimeout.Seconds()), int(MaxAgentRequestTimeout.Seconds())),
+					},
+				},
+				"required": []string{"target", "message"},
+			},
+		},
+	}
+}
+
+// Execute sends the request and waits for the correlated reply.
+func (t *AgentRequestTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	targetAlias, ok := stringArg(args, "target")
+	if !ok || targetAlias == "" {
+		return "", fmt.Errorf("agent.request: missing required argument 'target'")
+	}
+	message, ok := stringArg(args, "message")
+	if !ok || message == "" {
+		return "", fmt.Errorf("agent.request: missing required argument 'message'")
+	}
+	timeout := DefaultAgentRequestTimeout
+	if secs, has, err := optionalInt64Arg(args, "timeout_seconds"); err != nil {
+		return "", fmt.Errorf("agent.request: %w", err)
+	} else if has {
+		if secs <= 0 {
+			return "", fmt.Errorf("agent.request: timeout_seconds must be positive, got %d", secs)
+		}
+		timeout = min(time.Duration(secs)*time.Second, MaxAgentRequestTimeout)
+	}
+
+	cfg := t.cfg.Config()
+	if cfg == nil {
+		return "", fmt.Errorf("agent.request: no Gosuto config loaded")
+	}
+	roomID, found := resolveTarget(cfg.MessagingTargets(), targetAlias)
+	if !found {
+		return "", fmt.Errorf("agent.request: target %q is not in the allowed targets list", targetAlias)
+	}
+	if !t.rl.allow(cfg.Messaging.MaxMessagesPerMinute) {
+		return "", fmt.Errorf("agent.request: rate limit exceeded (%d messages/minute)", cfg.Messaging.MaxMessagesPerMinute)
+	}
+
+	token, err := newRequestToken()
+	if err != nil {
+		return "", fmt.Errorf("agent.request: %w", err)
+	}
+	// Register before sending so a fast reply cannot be missed.
+	reply := t.replies.expect(roomID, peerMXID(cfg, targetAlias), token)
+	defer t.replies.forget(token)
+
+	if err := t.sender.SendText(roomID, message+"\n\n"+RequestMarker(token)); err != nil {
+		return "", fmt.Errorf("agent.request: send failed: %w", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case text := <-reply:
+		return fmt.Sprintf("Reply from %q: %s", targetAlias, text), nil
+	case <-timer.C:
+		return "", fmt.Errorf("agent.request: no reply from %q within %s", targetAlias, timeout)
+	case <-ctx.Done():
+		return "", fmt.Errorf("agent.request: gave up waiting for %q: %w", targetAlias, ctx.Err())
+	}
+}
+
+// peerMXID returns the Matrix user ID configured for the peer named alias in
+// instructions.context.peers, or "" when none is known.
+func peerMXID(cfg *gosutospec.Config, alias string) string {
+	for _, peer := range cfg.Instructions.Context.Peers {
+		if strings.TrimSpace(peer.Name) == alias {
+			return peer.MXID
+		}
+	}
+	return ""
+}
+
+func newRequestToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate correlation token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+)
+
+// kumoMXID is the peer's Matrix user ID in agentRequestConfig.
+const kumoMXID = "@kumo:example.com"
+
+// echoPeer is a MatrixSender standing in for a peer agent: after delay it
+// answers each request in replyRoom as replySender (kumoMXID by default),
+// tagging the reply with the request's correlation token the way the peer's
+// Gitai runtime does.
+type echoPeer struct {
+	replies     *ReplyRouter
+	replyRoom   string
+	replySender string
+	delay       time.Duration
+	sent        []string
+}
+
+func (p *echoPeer) SendText(roomID, text string) error {
+	p.sent = append(p.sent, text)
+	token, ok := RequestToken(text)
+	if !ok {
+		return nil
+	}
+	sender := p.replySender
+	if sender == "" {
+		sender = kumoMXID
+	}
+	go func() {
+		time.Sleep(p.delay)
+		p.replies.Deliver(p.replyRoom, sender, "Top story: markets flat.\n\n"+ReplyMarker(token))
+	}()
+	return nil
+}
+
+func agentRequestConfig() *staticConfigProvider {
+	return &staticConfigProvider{cfg: &gosutospec.Config{
+		Messaging: gosutospec.Messaging{
+			AllowedTargets: []gosutospec.MessagingTarget{{RoomID: "!kumo:example.com", Alias: "kumo"}},
+		},
+		Instructions: gosutospec.Instructions{
+			Context: gosutospec.InstructionsContext{
+				Peers: []gosutospec.PeerRef{{Name: "kumo", MXID: kumoMXID}},
+			},
+		},
+	}}
+}
+
+func TestAgentRequest_ReturnsCorrelatedReply(t *testing.T) {
+	replies := NewReplyRouter()
+	peer := &echoPeer{replies: replies, replyRoom: "!kumo:example.com", delay: 20 * time.Millisecond}
+	tool := NewAgentRequestTool(agentRequestConfig(), peer, replies)
+
+	got, err := tool.Execute(context.Background(), map[string]interface{}{
+		"target": "kumo", "message": "Hey kumo, any news?", "timeout_seconds": float64(5),
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got != `Reply from "kumo": Top story: markets flat.` {
+		t.Errorf("result = %q", got)
+	}
+	if len(peer.sent) != 1 || !strings.HasPrefix(peer.sent[0], "Hey kumo, any news?\n\n[agent-request:") {
+		t.Errorf("sent = %q, want the message followed by the request marker", peer.sent)
+	}
+}
+
+func TestAgentRequest_TimesOutWithoutReply(t *testing.T) {
+	replies := NewReplyRouter()
+	peer := &echoPeer{replies: replies, replyRoom: "!kumo:example.com", delay: 1500 * time.Millisecond}
+	tool := NewAgentRequestTool(agentRequestConfig(), peer, replies)
+
+	start := time.Now()
+	_, err := tool.Execute(context.Background(), map[string]interface{}{
+		"target": "kumo", "message": "Hey kumo, any news?", "timeout_seconds": float64(1),
+	})
+	if err == nil || !strings.Contains(err.Error(), "no reply") {
+		t.Fatalf("err = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1400*time.Millisecond {
+		t.Errorf("waited %s, want about the 1s timeout", elapsed)
+	}
+}
+
+func TestAgentRequest_IgnoresReplyFromOtherRoom(t *testing.T) {
+	replies := NewReplyRouter()
+	peer := &echoPeer{replies: replies, replyRoom: "!elsewhere:example.com", delay: 10 * time.Millisecond}
+	tool := NewAgentRequestTool(agentRequestConfig(), peer, replies)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := tool.Execute(ctx, map[string]interface{}{"target": "kumo", "message": "Hey kumo, news?"}); err == nil {
+		t.Fatal("a reply from another room must not resolve the request")
+	}
+}
+
+func TestAgentRequest_IgnoresReplyFromOtherSender(t *testing.T) {
+	replies := NewReplyRouter()
+	peer := &echoPeer{replies: replies, replyRoom: "!kumo:example.com", replySender: "@mallory:example.com", delay: 10 * time.Millisecond}
+	tool := NewAgentRequestTool(agentRequestConfig(), peer, replies)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := tool.Execute(ctx, map[string]interface{}{"target": "kumo", "message": "Hey kumo, news?"}); err == nil {
+		t.Fatal("a reply from someone other than the addressed peer must not resolve the request")
+	}
+}
+
+func TestAgentRequest_RejectsNonPositiveTimeout(t *testing.T) {
+	replies := NewReplyRouter()
+	sender := &stubSender{}
+	tool := NewAgentRequestTool(agentRequestConfig(), sender, replies)
+
+	for _, secs := range []float64{-5, 0} {
+		_, err := tool.Execute(context.Background(), map[string]interface{}{
+			"target": "kumo", "message": "Hey kumo, news?", "timeout_seconds": secs,
+		})
+		if err == nil || !strings.Contains(err.Error(), "timeout_seconds") {
+			t.Errorf("timeout_seconds=%v: err = %v, want a validation error", secs, err)
+		}
+	}
+	if len(sender.calls) != 0 {
+		t.Errorf("sent %d messages, want none for an invalid timeout", len(sender.calls))
+	}
+}
+
+func TestAgentRequest_RejectsUnknownTarget(t *testing.T) {
+	replies := NewReplyRouter()
+	tool := NewAgentRequestTool(agentRequestConfig(), &stubSender{}, replies)
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"target": "nobody", "message": "hi"}); err == nil {
+		t.Fatal("expected an error for a target outside the allowed list")
+	}
+}
+
+func TestReplyRouter_DeliverOnlyMatchesPendingTokens(t *testing.T) {
+	r := NewReplyRouter()
+	if r.Deliver("!room:example.com", kumoMXID, "plain message") {
+		t.Error("a message without a reply marker must not match")
+	}
+	if r.Deliver("!room:example.com", kumoMXID, "late reply "+ReplyMarker("0123456789abcdef")) {
+		t.Error("a reply to no pending request must not match")
+	}
+	var nilRouter *ReplyRouter
+	if nilRouter.Deliver("!room:example.com", kumoMXID, ReplyMarker("0123456789abcdef")) {
+		t.Error("a nil router must not match")
+	}
+}
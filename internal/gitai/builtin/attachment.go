@@ -0,0 +1,155 @@
+package builtin
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bdobrica/Ruriko/common/spec/envelope"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// EventAttachmentToolName is the canonical name of the built-in tool that
+// reads an attachment of the event being handled.
+const EventAttachmentToolName = "event.attachment"
+
+const (
+	// maxAttachmentFetch caps how much of a by-reference attachment is
+	// downloaded.
+	maxAttachmentFetch = 4 * 1024 * 1024
+	// attachmentFetchTimeout bounds a by-reference download.
+	attachmentFetchTimeout = 30 * time.Second
+)
+
+type attachmentsKey struct{}
+
+// WithEventAttachments returns a context carrying the attachments of the
+// event whose turn runs under it. event.attachment reads them from there,
+// so each turn only sees its own event's files.
+func WithEventAttachments(ctx context.Context, atts []envelope.Attachment) context.Context {
+	if len(atts) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, attachmentsKey{}, atts)
+}
+
+// HasEventAttachments reports whether ctx carries event attachments.
+func HasEventAttachments(ctx context.Context) bool {
+	return len(eventAttachments(ctx)) > 0
+}
+
+func eventAttachments(ctx context.Context) []envelope.Attachment {
+	atts, _ := ctx.Value(attachmentsKey{}).([]envelope.Attachment)
+	return atts
+}
+
+// EventAttachmentTool implements event.attachment. Inline attachments are
+// decoded; by-reference ones are downloaded with a size cap. Text content is
+// returned as-is (truncated like resource.read); binary content is returned
+// base64-encoded under the same cap.
+type EventAttachmentTool struct {
+	client *http.Client
+}
+
+// NewEventAttachmentTool constructs an EventAttachmentTool. A nil client uses
+// newFetchClient, which only reaches public addresses.
+func NewEventAttachmentTool(client *http.Client) *EventAttachmentTool {
+	if client == nil {
+		client = newFetchClient()
+	}
+	return &EventAttachmentTool{client: client}
+}
+
+// Definition returns the LLM-facing tool specification for event.attachment.
+func (t *EventAttachmentTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Type: "function",
+		Function: llm.FunctionDef{
+			Name:        EventAttachmentToolName,
+			Description: "Read the content of an attachment of the event being handled, by its index in the event's attachment list.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"index": map[string]interface{}{
+						"type":        "integer",
+						"description": "Zero-based index of the attachment, as listed in the event.",
+					},
+				},
+				"required": []string{"index"},
+			},
+		},
+	}
+}
+
+// Execute returns the content of the requested attachment.
+func (t *EventAttachmentTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	atts := eventAttachments(ctx)
+	if len(atts) == 0 {
+		return "", fmt.Errorf("event.attachment: the current turn has no event attachments")
+	}
+	raw, ok := args["index"].(float64)
+	if !ok || raw != float64(int(raw)) {
+		return "", fmt.Errorf("event.attachment: argument 'index' must be an integer")
+	}
+	idx := int(raw)
+	if idx < 0 || idx >= len(atts) {
+		return "", fmt.Errorf("event.attachment: index %d out of range (event has %d attachments)", idx, len(atts))
+	}
+	att := atts[idx]
+
+	var content []byte
+	var err error
+	if att.Data != "" {
+		content, err = att.Decode()
+	} else {
+		content, err = fetchCapped(ctx, t.client, att.URL, maxAttachmentFetch)
+	}
+	if err != nil {
+		return "", fmt.Errorf("event.attachment: %w", err)
+	}
+
+	header := fmt.Sprintf("Attachment %d: %s (%s, %d bytes)", idx, att.Name, mimeOrUnknown(att.MIME), len(content))
+	if isTextContent(att.MIME, content) {
+		return header + "\n\n" + truncateText(string(content)), nil
+	}
+	return header + ", base64-encoded:\n\n" + truncateText(base64.StdEncoding.EncodeToString(content)), nil
+}
+
+// isTextContent reports whether content should be shown to the LLM as text:
+// a text-like MIME type, or no MIME type and valid UTF-8.
+func isTextContent(mime string, content []byte) bool {
+	mime = strings.ToLower(mime)
+	switch {
+	case strings.HasPrefix(mime, "text/"),
+		strings.HasSuffix(mime, "+json"), strings.HasSuffix(mime, "+xml"),
+		mime == "application/json", mime == "application/xml", mime == "application/yaml":
+		return true
+	case mime == "":
+		return utf8.Valid(content)
+	}
+	return false
+}
+
+// truncateText cuts s to at most maxResourceText bytes without splitting a
+// UTF-8 sequence.
+func truncateText(s string) string {
+	if len(s) <= maxResourceText {
+		return s
+	}
+	n := maxResourceText
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "\n[truncated]"
+}
+
+func mimeOrUnknown(mime string) string {
+	if mime == "" {
+		return "unknown type"
+	}
+	return mime
+}
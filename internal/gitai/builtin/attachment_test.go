@@ -0,0 +1,88 @@
+package builtin
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bdobrica/Ruriko/common/spec/envelope"
+)
+
+func TestEventAttachmentTool_DecodesInlineText(t *testing.T) {
+	ctx := WithEventAttachments(context.Background(), []envelope.Attachment{
+		{Name: "notes.txt", MIME: "text/plain", Data: base64.StdEncoding.EncodeToString([]byte("ship it"))},
+	})
+	got, err := NewEventAttachmentTool(nil).Execute(ctx, map[string]interface{}{"index": float64(0)})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(got, "notes.txt") || !strings.HasSuffix(got, "ship it") {
+		t.Errorf("Execute = %q, want header and decoded text", got)
+	}
+}
+
+func TestEventAttachmentTool_BinaryIsBase64(t *testing.T) {
+	raw := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	ctx := WithEventAttachments(context.Background(), []envelope.Attachment{
+		{Name: "pic.png", MIME: "image/png", Data: base64.StdEncoding.EncodeToString(raw)},
+	})
+	got, err := NewEventAttachmentTool(nil).Execute(ctx, map[string]interface{}{"index": float64(0)})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(got, "base64") || !strings.HasSuffix(got, base64.StdEncoding.EncodeToString(raw)) {
+		t.Errorf("Execute = %q, want base64 content", got)
+	}
+}
+
+func TestEventAttachmentTool_FetchesByReference(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"total": 42}`))
+	}))
+	defer srv.Close()
+
+	ctx := WithEventAttachments(context.Background(), []envelope.Attachment{
+		{Name: "a.txt", Data: base64.StdEncoding.EncodeToString([]byte("a"))},
+		{Name: "totals.json", MIME: "application/json", URL: srv.URL + "/totals.json"},
+	})
+	got, err := NewEventAttachmentTool(srv.Client()).Execute(ctx, map[string]interface{}{"index": float64(1)})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(got, `{"total": 42}`) {
+		t.Errorf("Execute = %q, want fetched content", got)
+	}
+}
+
+func TestEventAttachmentTool_Errors(t *testing.T) {
+	tool := NewEventAttachmentTool(nil)
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"index": float64(0)}); err == nil {
+		t.Error("expected error when the turn has no attachments")
+	}
+
+	ctx := WithEventAttachments(context.Background(), []envelope.Attachment{{Name: "a", Data: "YQ=="}})
+	for _, args := range []map[string]interface{}{
+		{"index": float64(1)},
+		{"index": float64(-1)},
+		{"index": 0.5},
+		{},
+	} {
+		if _, err := tool.Execute(ctx, args); err == nil {
+			t.Errorf("Execute(%v): expected error", args)
+		}
+	}
+}
+
+func TestTruncateText_KeepsRunesWhole(t *testing.T) {
+	// A one-byte prefix puts a two-byte rune across the cut.
+	got := truncateText("x" + strings.Repeat("é", maxResourceText))
+	body := strings.TrimSuffix(got, "\n[truncated]")
+	if body == got || !utf8.ValidString(body) || len(body) > maxResourceText {
+		t.Errorf("truncateText produced %d bytes (valid %v), want at most %d valid bytes and a marker",
+			len(body), utf8.ValidString(body), maxResourceText)
+	}
+}
@@ -0,0 +1,145 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// maxFetchRedirects caps the redirects followed by newFetchClient.
+const maxFetchRedirects = 5
+
+// newFetchClient returns the http.Client built-in tools use to download URLs
+// taken from events or tool arguments. Its dialer refuses loopback, private,
+// link-local, shared (CGNAT) and other special-purpose addresses, so such
+// URLs cannot reach the agent's host, its ACP port or the internal network.
+// The check runs on the resolved address of every connection, including
+// those opened to follow a redirect, and proxies are not used so the checked
+// address is the one dialed.
+func newFetchClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: publicOnlyControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:       attachmentFetchTimeout,
+		Transport:     transport,
+		CheckRedirect: checkFetchRedirect,
+	}
+}
+
+// publicOnlyControl is a net.Dialer Control hook that fails the dial unless
+// address is a public IP.
+func publicOnlyControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !isPublicIP(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", host)
+	}
+	return nil
+}
+
+// nonPublicPrefixes lists the IANA special-purpose ranges (RFC 6890 and its
+// updates) plus multicast; none of them is a globally routable unicast
+// destination.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space (CGNAT)
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, limited broadcast
+	netip.MustParsePrefix("::/96"),           // unspecified, loopback, IPv4-compatible
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use IPv4/IPv6 translation
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, incl. Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("3fff::/20"),       // documentation
+	netip.MustParsePrefix("5f00::/16"),       // segment routing SIDs
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("fec0::/10"),       // site-local (deprecated)
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// Prefixes whose addresses embed an IPv4 address that is reached through a
+// translator or tunnel; the embedded address is checked instead.
+var (
+	nat64Prefix     = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// isPublicIP reports whether ip is a globally routable unicast address.
+// IPv4-mapped addresses are checked as IPv4, and NAT64 and 6to4 addresses by
+// the IPv4 address they embed.
+func isPublicIP(ip netip.Addr) bool {
+	ip = ip.WithZone("").Unmap()
+	if nat64Prefix.Contains(ip) {
+		b := ip.As16()
+		return isPublicIP(netip.AddrFrom4([4]byte(b[12:16])))
+	}
+	if sixToFourPrefix.Contains(ip) {
+		b := ip.As16()
+		return isPublicIP(netip.AddrFrom4([4]byte(b[2:6])))
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return ip.IsValid()
+}
+
+// checkFetchRedirect limits redirects and keeps them on http(s). The target
+// address is checked again by publicOnlyControl when it is dialed.
+func checkFetchRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxFetchRedirects {
+		return errors.New("too many redirects")
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+	return nil
+}
+
+// fetchCapped downloads url, failing when the body exceeds limit bytes.
+func fetchCapped(ctx context.Context, client *http.Client, url string, limit int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetch: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+	if len(body) > limit {
+		return nil, fmt.Errorf("content exceeds the %d-byte download cap", limit)
+	}
+	return body, nil
+}
@@ -0,0 +1,75 @@
+package builtin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"0.0.0.0":          false,
+		"100.64.0.1":       false,
+		"100.127.255.254":  false,
+		"192.0.0.170":      false,
+		"198.18.0.1":       false,
+		"203.0.113.7":      false,
+		"240.0.0.1":        false,
+		"255.255.255.255":  false,
+		"224.0.0.251":      false,
+		"::ffff:10.0.0.1":  false,
+		"::ffff:8.8.8.8":   true,
+		"64:ff9b::a00:1":   false,
+		"64:ff9b::808:808": true,
+		"64:ff9b:1::1":     false,
+		"2002:7f00:1::":    false,
+		"2002:808:808::1":  true,
+		"2001::1":          false,
+		"2001:db8::1":      false,
+		"ff02::1":          false,
+		"::":               false,
+	} {
+		if got := isPublicIP(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestFetchClient_RefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	_, err := fetchCapped(context.Background(), newFetchClient(), srv.URL, 1024)
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("fetchCapped(%s) err = %v, want the loopback address refused", srv.URL, err)
+	}
+}
+
+func TestCheckFetchRedirect(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "file:///etc/passwd", nil)
+	if err := checkFetchRedirect(req, nil); err == nil {
+		t.Error("expected a redirect to file:// to be refused")
+	}
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/next", nil)
+	if err := checkFetchRedirect(req, make([]*http.Request, maxFetchRedirects)); err == nil {
+		t.Error("expected the redirect limit to apply")
+	}
+	if err := checkFetchRedirect(req, nil); err != nil {
+		t.Errorf("https redirect refused: %v", err)
+	}
+}
@@ -34,8 +34,9 @@ type MessagingConfigProvider interface {
 // On each Execute call it:
 //  1. Validates the "target" and "message" arguments are present.
 //  2. Resolves the target alias → Matrix room ID from
-//     cfg.Messaging.AllowedTargets (default deny: unknown targets are
-//     rejected with an error returned to the LLM).
+//     cfg.MessagingTargets() — messaging.allowedTargets plus peers with a
+//     roomId (default deny: unknown targets are rejected with an error
+//     returned to the LLM).
 //  3. Enforces the per-minute rate limit from cfg.Messaging.MaxMessagesPerMinute.
 //  4. Sends the message via the Matrix client.
 //  5. Returns a success or failure string to the LLM.
@@ -100,7 +101,7 @@ func (t *MatrixSendTool) Execute(_ context.Context, args map[string]interface{})
 	if cfg == nil {
 		return "", fmt.Errorf("matrix.send_message: no Gosuto config loaded")
 	}
-	roomID, found := resolveTarget(cfg.Messaging.AllowedTargets, targetAlias)
+	roomID, found := resolveTarget(cfg.MessagingTargets(), targetAlias)
 	if !found {
 		return "", fmt.Errorf("matrix.send_message: target %q is not in the allowed targets list", targetAlias)
 	}
@@ -0,0 +1,165 @@
+package builtin
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// MatrixSendFileToolName is the canonical name of the built-in file/image
+// sending tool.
+const MatrixSendFileToolName = "matrix.send_file"
+
+// maxSendFileBytes caps the size of a file sent with matrix.send_file,
+// whether it arrives inline or is fetched from a URL.
+const maxSendFileBytes = 4 * 1024 * 1024
+
+// MatrixFileSender is the subset of the Matrix client required by
+// MatrixSendFileTool. It is satisfied by *matrix.Client.
+type MatrixFileSender interface {
+	Upload(data []byte, contentType, fileName string) (string, error)
+	SendFile(roomID, mxcURI, fileName, contentType string, size int) error
+}
+
+// MatrixSendFileTool implements the matrix.send_file built-in tool. It
+// uploads a file to the homeserver's media repository and posts it to one of
+// the Gosuto messaging targets, so it is subject to the same target
+// allowlist and per-minute rate limit as matrix.send_message (counted
+// separately).
+type MatrixSendFileTool struct {
+	cfg    MessagingConfigProvider
+	sender MatrixFileSender
+	client *http.Client
+	rl     *rateLimiter
+}
+
+// NewMatrixSendFileTool constructs a MatrixSendFileTool. cfg and sender must
+// be non-nil; a nil client uses newFetchClient for URL sources, so the LLM
+// cannot have the agent read internal addresses and post them to a room.
+func NewMatrixSendFileTool(cfg MessagingConfigProvider, sender MatrixFileSender, client *http.Client) *MatrixSendFileTool {
+	if client == nil {
+		client = newFetchClient()
+	}
+	return &MatrixSendFileTool{cfg: cfg, sender: sender, client: client, rl: &rateLimiter{}}
+}
+
+// Definition returns the LLM-facing tool specification for matrix.send_file.
+func (t *MatrixSendFileTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Type: "function",
+		Function: llm.FunctionDef{
+			Name: MatrixSendFileToolName,
+			Description: "Send a file or image (e.g. a chart or report) to another agent or user via Matrix. " +
+				"Provide the content either as base64 in 'data' or as an http(s) 'url' to fetch.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"target": map[string]interface{}{
+						"type":        "string",
+						"description": "Alias of the target from the allowed targets list. Unknown aliases are rejected.",
+					},
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "File name shown to the recipient, e.g. \"report.pdf\".",
+					},
+					"mime": map[string]interface{}{
+						"type":        "string",
+						"description": "Media type, e.g. \"image/png\". Inferred from the file name when omitted.",
+					},
+					"data": map[string]interface{}{
+						"type":        "string",
+						"description": "Base64-encoded file content. Mutually exclusive with 'url'.",
+					},
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "http(s) URL to fetch the file from. Mutually exclusive with 'data'.",
+					},
+				},
+				"required": []string{"target", "filename"},
+			},
+		},
+	}
+}
+
+// Execute uploads the file and posts it to the resolved target room.
+func (t *MatrixSendFileTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	targetAlias, ok := stringArg(args, "target")
+	if !ok || targetAlias == "" {
+		return "", fmt.Errorf("matrix.send_file: missing required argument 'target'")
+	}
+	fileName, ok := stringArg(args, "filename")
+	if !ok || strings.TrimSpace(fileName) == "" {
+		return "", fmt.Errorf("matrix.send_file: missing required argument 'filename'")
+	}
+	data, _ := stringArg(args, "data")
+	rawURL, _ := stringArg(args, "url")
+	if (data == "") == (rawURL == "") {
+		return "", fmt.Errorf("matrix.send_file: exactly one of 'data' or 'url' must be set")
+	}
+
+	cfg := t.cfg.Config()
+	if cfg == nil {
+		return "", fmt.Errorf("matrix.send_file: no Gosuto config loaded")
+	}
+	roomID, found := resolveTarget(cfg.MessagingTargets(), targetAlias)
+	if !found {
+		return "", fmt.Errorf("matrix.send_file: target %q is not in the allowed targets list", targetAlias)
+	}
+
+	var content []byte
+	if data != "" {
+		// Reject oversized input before decoding it.
+		if base64.StdEncoding.DecodedLen(len(data)) > maxSendFileBytes+2 {
+			return "", fmt.Errorf("matrix.send_file: file exceeds the %d-byte cap", maxSendFileBytes)
+		}
+		b, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return "", fmt.Errorf("matrix.send_file: 'data' is not valid base64: %w", err)
+		}
+		content = b
+	} else {
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("matrix.send_file: 'url' must be an absolute http(s) URL")
+		}
+		b, err := fetchCapped(ctx, t.client, rawURL, maxSendFileBytes)
+		if err != nil {
+			return "", fmt.Errorf("matrix.send_file: %w", err)
+		}
+		content = b
+	}
+	if len(content) > maxSendFileBytes {
+		return "", fmt.Errorf("matrix.send_file: file exceeds the %d-byte cap", maxSendFileBytes)
+	}
+	if len(content) == 0 {
+		return "", fmt.Errorf("matrix.send_file: file is empty")
+	}
+
+	contentType, _ := stringArg(args, "mime")
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(fileName))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	if !t.rl.allow(cfg.Messaging.MaxMessagesPerMinute) {
+		return "", fmt.Errorf("matrix.send_file: rate limit exceeded (%d messages/minute)", cfg.Messaging.MaxMessagesPerMinute)
+	}
+
+	mxc, err := t.sender.Upload(content, contentType, fileName)
+	if err != nil {
+		return "", fmt.Errorf("matrix.send_file: upload failed: %w", err)
+	}
+	if err := t.sender.SendFile(roomID, mxc, fileName, contentType, len(content)); err != nil {
+		return "", fmt.Errorf("matrix.send_file: send failed: %w", err)
+	}
+	return fmt.Sprintf("File %q (%s, %d bytes) sent to %q (%s).", fileName, contentType, len(content), targetAlias, roomID), nil
+}
@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+)
+
+// stubFileSender records Upload and SendFile calls.
+type stubFileSender struct {
+	uploads [][]byte
+	sends   []fileSend
+}
+
+type fileSend struct {
+	roomID, mxc, fileName, contentType string
+	size                               int
+}
+
+func (s *stubFileSender) Upload(data []byte, _, _ string) (string, error) {
+	s.uploads = append(s.uploads, data)
+	return "mxc://example.com/up1", nil
+}
+
+func (s *stubFileSender) SendFile(roomID, mxcURI, fileName, contentType string, size int) error {
+	s.sends = append(s.sends, fileSend{roomID, mxcURI, fileName, contentType, size})
+	return nil
+}
+
+func sendFileTool(maxPerMin int, client *http.Client) (*MatrixSendFileTool, *stubFileSender) {
+	sender := &stubFileSender{}
+	cfg := configWithMessaging([]gosutospec.MessagingTarget{
+		{Alias: "user", RoomID: "!user-room:example.com"},
+	}, maxPerMin)
+	return NewMatrixSendFileTool(&staticConfigProvider{cfg: cfg}, sender, client), sender
+}
+
+func TestMatrixSendFileTool_InlineData(t *testing.T) {
+	tool, sender := sendFileTool(0, nil)
+	content := []byte("a,b\n1,2\n")
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{
+		"target":   "user",
+		"filename": "data.csv",
+		"data":     base64.StdEncoding.EncodeToString(content),
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(sender.uploads) != 1 || string(sender.uploads[0]) != string(content) {
+		t.Errorf("uploads = %q, want the decoded content", sender.uploads)
+	}
+	if len(sender.sends) != 1 {
+		t.Fatalf("sends = %d, want 1", len(sender.sends))
+	}
+	s := sender.sends[0]
+	if s.roomID != "!user-room:example.com" || s.mxc != "mxc://example.com/up1" || s.size != len(content) {
+		t.Errorf("send = %+v", s)
+	}
+	if !strings.HasPrefix(s.contentType, "text/csv") {
+		t.Errorf("contentType = %q, want it inferred from the extension", s.contentType)
+	}
+	if !strings.Contains(result, "data.csv") {
+		t.Errorf("result = %q", result)
+	}
+}
+
+func TestMatrixSendFileTool_FetchesURL(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nfake")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(png)
+	}))
+	defer srv.Close()
+
+	tool, sender := sendFileTool(0, srv.Client())
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{
+		"target":   "user",
+		"filename": "chart.png",
+		"url":      srv.URL + "/chart.png",
+	}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(sender.sends) != 1 || sender.sends[0].contentType != "image/png" {
+		t.Errorf("sends = %+v, want one image/png file", sender.sends)
+	}
+}
+
+func TestMatrixSendFileTool_DefaultClientRefusesInternalURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("internal secret"))
+	}))
+	defer srv.Close()
+
+	tool, sender := sendFileTool(0, nil)
+	_, err := tool.Execute(context.Background(), map[string]interface{}{
+		"target":   "user",
+		"filename": "secret.txt",
+		"url":      srv.URL + "/secret.txt",
+	})
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("Execute err = %v, want the loopback URL refused", err)
+	}
+	if len(sender.uploads) != 0 {
+		t.Errorf("refused fetch uploaded %d files", len(sender.uploads))
+	}
+}
+
+func TestMatrixSendFileTool_Rejections(t *testing.T) {
+	inline := base64.StdEncoding.EncodeToString([]byte("x"))
+	oversized := base64.StdEncoding.EncodeToString(make([]byte, maxSendFileBytes+1))
+	cases := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{"unknown target", map[string]interface{}{"target": "nobody", "filename": "a", "data": inline}, "not in the allowed targets"},
+		{"missing filename", map[string]interface{}{"target": "user", "data": inline}, "filename"},
+		{"no source", map[string]interface{}{"target": "user", "filename": "a"}, "exactly one"},
+		{"both sources", map[string]interface{}{"target": "user", "filename": "a", "data": inline, "url": "https://x.example.com/a"}, "exactly one"},
+		{"bad base64", map[string]interface{}{"target": "user", "filename": "a", "data": "%%%"}, "base64"},
+		{"oversized", map[string]interface{}{"target": "user", "filename": "a", "data": oversized}, "cap"},
+		{"file url", map[string]interface{}{"target": "user", "filename": "a", "url": "file:///etc/passwd"}, "http(s)"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tool, sender := sendFileTool(0, nil)
+			_, err := tool.Execute(context.Background(), tc.args)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Execute: got %v, want error containing %q", err, tc.want)
+			}
+			if len(sender.uploads) != 0 {
+				t.Errorf("rejected call uploaded %d files", len(sender.uploads))
+			}
+		})
+	}
+}
+
+func TestMatrixSendFileTool_RateLimitEnforced(t *testing.T) {
+	tool, sender := sendFileTool(1, nil)
+	args := map[string]interface{}{"target": "user", "filename": "a.txt", "data": base64.StdEncoding.EncodeToString([]byte("x"))}
+
+	if _, err := tool.Execute(context.Background(), args); err != nil {
+		t.Fatalf("first Execute: %v", err)
+	}
+	if _, err := tool.Execute(context.Background(), args); err == nil || !strings.Contains(err.Error(), "rate limit") {
+		t.Errorf("second Execute: got %v, want rate limit error", err)
+	}
+	if len(sender.sends) != 1 {
+		t.Errorf("sends = %d, want 1", len(sender.sends))
+	}
+}
@@ -187,6 +187,27 @@ func TestMatrixSendTool_Execute_AllowedTarget_Succeeds(t *testing.T) {
 	}
 }
 
+// A peer declared with a roomId is a messaging target under its name, with
+// no allowedTargets entry.
+func TestMatrixSendTool_Execute_DeclaredPeerTarget_Succeeds(t *testing.T) {
+	sender := &stubSender{}
+	cfg := configWithMessaging(nil, 0)
+	cfg.Instructions.Context.Peers = []gosutospec.PeerRef{
+		{Name: "kumo", Role: "News agent.", RoomID: "!kumo:localhost"},
+	}
+
+	tool := NewMatrixSendTool(&staticConfigProvider{cfg}, sender)
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{
+		"target":  "kumo",
+		"message": "Any news?",
+	}); err != nil {
+		t.Fatalf("Execute returned unexpected error: %v", err)
+	}
+	if len(sender.calls) != 1 || sender.calls[0].roomID != "!kumo:localhost" {
+		t.Errorf("send calls = %+v, want one to !kumo:localhost", sender.calls)
+	}
+}
+
 // --- MatrixSendTool: Execute (R15.2: message to unknown target is rejected) ---
 
 func TestMatrixSendTool_Execute_UnknownTarget_Rejected(t *testing.T) {
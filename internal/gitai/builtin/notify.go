@@ -0,0 +1,114 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// NotifyOperatorToolName is the canonical name of the built-in operator
+// alert tool.
+const NotifyOperatorToolName = "notify.operator"
+
+// NotifyOperatorTool implements the notify.operator built-in tool. It posts
+// an alert to the channel configured in the Gosuto notifications section
+// (currently the admin room), dropping alerts below notifications.minSeverity
+// and enforcing notifications.maxPerMinute.
+type NotifyOperatorTool struct {
+	cfg    MessagingConfigProvider
+	sender MatrixSender
+	rl     *rateLimiter
+}
+
+// NewNotifyOperatorTool constructs a NotifyOperatorTool backed by the given
+// config provider and Matrix sender. Both must be non-nil.
+func NewNotifyOperatorTool(cfg MessagingConfigProvider, sender MatrixSender) *NotifyOperatorTool {
+	return &NotifyOperatorTool{
+		cfg:    cfg,
+		sender: sender,
+		rl:     &rateLimiter{},
+	}
+}
+
+// Definition returns the LLM-facing tool specification for notify.operator.
+func (t *NotifyOperatorTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Type: "function",
+		Function: llm.FunctionDef{
+			Name: NotifyOperatorToolName,
+			Description: "Raise an alert to the human operator. Use it for problems that need " +
+				"attention (failures, anomalies, blocked work), not for routine results.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"severity": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{gosutospec.NotificationSeverityInfo, gosutospec.NotificationSeverityWarning, gosutospec.NotificationSeverityCritical},
+						"description": "How urgent the alert is. Defaults to \"warning\".",
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "What happened and what the operator should look at.",
+					},
+				},
+				"required": []string{"message"},
+			},
+		},
+	}
+}
+
+// Execute posts the alert. Alerts below the configured minimum severity are
+// acknowledged to the LLM without being delivered.
+func (t *NotifyOperatorTool) Execute(_ context.Context, args map[string]interface{}) (string, error) {
+	message, ok := stringArg(args, "message")
+	if !ok || strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("notify.operator: missing required argument 'message'")
+	}
+	severity, _ := stringArg(args, "severity")
+	if severity == "" {
+		severity = gosutospec.NotificationSeverityWarning
+	}
+	if gosutospec.NotificationSeverityRank(severity) < 0 {
+		return "", fmt.Errorf("notify.operator: severity %q must be one of info, warning, critical", severity)
+	}
+
+	cfg := t.cfg.Config()
+	if cfg == nil {
+		return "", fmt.Errorf("notify.operator: no Gosuto config loaded")
+	}
+	n := cfg.Notifications
+	if !n.Enabled() {
+		return "", fmt.Errorf("notify.operator: notifications are not configured")
+	}
+	if n.MinSeverity != "" && gosutospec.NotificationSeverityRank(severity) < gosutospec.NotificationSeverityRank(n.MinSeverity) {
+		return fmt.Sprintf("Alert not delivered: severity %q is below the configured minimum %q.", severity, n.MinSeverity), nil
+	}
+	roomID := cfg.Trust.AdminRoom
+	if roomID == "" {
+		return "", fmt.Errorf("notify.operator: no admin room configured")
+	}
+
+	if !t.rl.allow(n.MaxPerMinute) {
+		return "", fmt.Errorf("notify.operator: rate limit exceeded (%d alerts/minute)", n.MaxPerMinute)
+	}
+
+	text := fmt.Sprintf("%s **%s** alert from %s\n\n%s", severityIcon(severity), strings.ToUpper(severity), cfg.Metadata.Name, message)
+	if err := t.sender.SendText(roomID, text); err != nil {
+		return "", fmt.Errorf("notify.operator: send failed: %w", err)
+	}
+	return fmt.Sprintf("Operator alerted (%s).", severity), nil
+}
+
+// severityIcon returns the emoji prefix for an alert severity.
+func severityIcon(severity string) string {
+	switch severity {
+	case gosutospec.NotificationSeverityCritical:
+		return "🚨"
+	case gosutospec.NotificationSeverityWarning:
+		return "⚠️"
+	}
+	return "ℹ️"
+}
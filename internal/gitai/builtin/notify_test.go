@@ -0,0 +1,114 @@
+package builtin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+)
+
+const notifyAdminRoom = "!admin:example.com"
+
+// configWithNotifications builds a minimal Gosuto config with an admin room
+// and the given notifications section.
+func configWithNotifications(n gosutospec.Notifications) *gosutospec.Config {
+	return &gosutospec.Config{
+		APIVersion:    gosutospec.SpecVersion,
+		Metadata:      gosutospec.Metadata{Name: "test-agent"},
+		Trust:         gosutospec.Trust{AdminRoom: notifyAdminRoom},
+		Notifications: n,
+	}
+}
+
+func TestNotifyOperatorTool_PostsToAdminRoom(t *testing.T) {
+	sender := &stubSender{}
+	tool := NewNotifyOperatorTool(&staticConfigProvider{cfg: configWithNotifications(gosutospec.Notifications{
+		Channel: gosutospec.NotificationChannelAdminRoom,
+	})}, sender)
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{
+		"severity": "critical",
+		"message":  "upstream feed has been down for an hour",
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(result, "critical") {
+		t.Errorf("result = %q, want severity acknowledged", result)
+	}
+	if len(sender.calls) != 1 {
+		t.Fatalf("SendText calls = %d, want 1", len(sender.calls))
+	}
+	call := sender.calls[0]
+	if call.roomID != notifyAdminRoom {
+		t.Errorf("room = %q, want %q", call.roomID, notifyAdminRoom)
+	}
+	for _, want := range []string{"CRITICAL", "test-agent", "upstream feed has been down"} {
+		if !strings.Contains(call.message, want) {
+			t.Errorf("message %q missing %q", call.message, want)
+		}
+	}
+}
+
+func TestNotifyOperatorTool_RateLimitEnforced(t *testing.T) {
+	sender := &stubSender{}
+	tool := NewNotifyOperatorTool(&staticConfigProvider{cfg: configWithNotifications(gosutospec.Notifications{
+		Channel:      gosutospec.NotificationChannelAdminRoom,
+		MaxPerMinute: 2,
+	})}, sender)
+
+	args := map[string]interface{}{"message": "disk almost full"}
+	for i := 0; i < 2; i++ {
+		if _, err := tool.Execute(context.Background(), args); err != nil {
+			t.Fatalf("alert %d: %v", i+1, err)
+		}
+	}
+	_, err := tool.Execute(context.Background(), args)
+	if err == nil || !strings.Contains(err.Error(), "rate limit") {
+		t.Fatalf("third alert err = %v, want rate limit error", err)
+	}
+	if len(sender.calls) != 2 {
+		t.Errorf("SendText calls = %d, want 2", len(sender.calls))
+	}
+}
+
+func TestNotifyOperatorTool_BelowMinSeverityNotDelivered(t *testing.T) {
+	sender := &stubSender{}
+	tool := NewNotifyOperatorTool(&staticConfigProvider{cfg: configWithNotifications(gosutospec.Notifications{
+		Channel:     gosutospec.NotificationChannelAdminRoom,
+		MinSeverity: gosutospec.NotificationSeverityWarning,
+	})}, sender)
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"severity": "info", "message": "fyi"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(result, "not delivered") || len(sender.calls) != 0 {
+		t.Errorf("result = %q, calls = %d; want suppressed alert", result, len(sender.calls))
+	}
+}
+
+func TestNotifyOperatorTool_Rejections(t *testing.T) {
+	configured := configWithNotifications(gosutospec.Notifications{Channel: gosutospec.NotificationChannelAdminRoom})
+	cases := []struct {
+		name string
+		cfg  *gosutospec.Config
+		args map[string]interface{}
+	}{
+		{"no config", nil, map[string]interface{}{"message": "x"}},
+		{"not configured", configWithNotifications(gosutospec.Notifications{}), map[string]interface{}{"message": "x"}},
+		{"missing message", configured, map[string]interface{}{}},
+		{"bad severity", configured, map[string]interface{}{"message": "x", "severity": "panic"}},
+	}
+	for _, tc := range cases {
+		sender := &stubSender{}
+		tool := NewNotifyOperatorTool(&staticConfigProvider{cfg: tc.cfg}, sender)
+		if _, err := tool.Execute(context.Background(), tc.args); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+		if len(sender.calls) != 0 {
+			t.Errorf("%s: unexpected send", tc.name)
+		}
+	}
+}
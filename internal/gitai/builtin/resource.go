@@ -0,0 +1,197 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+	"github.com/bdobrica/Ruriko/internal/gitai/mcp"
+)
+
+const (
+	// ResourceListToolName lists the resources exposed by an MCP server.
+	ResourceListToolName = "resource.list"
+	// ResourceReadToolName reads a single MCP resource by URI.
+	ResourceReadToolName = "resource.read"
+)
+
+// maxResourceText caps the resource text returned to the LLM so a large
+// document cannot exhaust the context window.
+const maxResourceText = 64 * 1024
+
+// ResourceReader is the read-only subset of an MCP client used by the
+// resource tools. It is satisfied by *mcp.Client.
+type ResourceReader interface {
+	ListResources(ctx context.Context) ([]mcp.Resource, error)
+	ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error)
+}
+
+// ResourceSource returns the reader for the named MCP server, or nil when
+// that server is not running.
+type ResourceSource func(mcpName string) ResourceReader
+
+// PolicyScoped is implemented by built-in tools that act on behalf of an MCP
+// server. Instead of the "builtin" namespace, policy for such tools is
+// evaluated against the MCP named in the call, so a capability rule like
+// (mcp: docs, tool: resource.read) grants access per server.
+type PolicyScoped interface {
+	PolicyTarget(args map[string]interface{}) (mcpName, tool string)
+}
+
+// resourceTool holds the state shared by resource.list and resource.read.
+type resourceTool struct {
+	source ResourceSource
+}
+
+func (t resourceTool) reader(toolName string, args map[string]interface{}) (string, ResourceReader, error) {
+	mcpName, ok := stringArg(args, "mcp")
+	if !ok || mcpName == "" {
+		return "", nil, fmt.Errorf("%s: missing required argument 'mcp'", toolName)
+	}
+	r := t.source(mcpName)
+	if r == nil {
+		return "", nil, fmt.Errorf("%s: MCP server %q is not running", toolName, mcpName)
+	}
+	return mcpName, r, nil
+}
+
+// ResourceListTool implements resource.list.
+type ResourceListTool struct{ resourceTool }
+
+// NewResourceListTool constructs a ResourceListTool reading from source.
+func NewResourceListTool(source ResourceSource) *ResourceListTool {
+	return &ResourceListTool{resourceTool{source: source}}
+}
+
+// Definition returns the LLM-facing tool specification for resource.list.
+func (t *ResourceListTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Type: "function",
+		Function: llm.FunctionDef{
+			Name:        ResourceListToolName,
+			Description: "List the read-only resources (documents, files, records) exposed by an MCP server.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"mcp": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the MCP server whose resources to list.",
+					},
+				},
+				"required": []string{"mcp"},
+			},
+		},
+	}
+}
+
+// PolicyTarget scopes policy to the MCP named in args.
+func (t *ResourceListTool) PolicyTarget(args map[string]interface{}) (string, string) {
+	mcpName, _ := stringArg(args, "mcp")
+	return mcpName, ResourceListToolName
+}
+
+// Execute lists the resources of the requested MCP server.
+func (t *ResourceListTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	mcpName, r, err := t.reader(ResourceListToolName, args)
+	if err != nil {
+		return "", err
+	}
+	resources, err := r.ListResources(ctx)
+	if err != nil {
+		return "", fmt.Errorf("resource.list %s: %w", mcpName, err)
+	}
+	if len(resources) == 0 {
+		return fmt.Sprintf("MCP server %q exposes no resources.", mcpName), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Resources exposed by %q:\n", mcpName)
+	for _, res := range resources {
+		fmt.Fprintf(&b, "- %s", res.URI)
+		if res.Name != "" {
+			fmt.Fprintf(&b, " (%s)", res.Name)
+		}
+		if res.MIME != "" {
+			fmt.Fprintf(&b, " [%s]", res.MIME)
+		}
+		if res.Description != "" {
+			fmt.Fprintf(&b, ": %s", res.Description)
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// ResourceReadTool implements resource.read.
+type ResourceReadTool struct{ resourceTool }
+
+// NewResourceReadTool constructs a ResourceReadTool reading from source.
+func NewResourceReadTool(source ResourceSource) *ResourceReadTool {
+	return &ResourceReadTool{resourceTool{source: source}}
+}
+
+// Definition returns the LLM-facing tool specification for resource.read.
+func (t *ResourceReadTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Type: "function",
+		Function: llm.FunctionDef{
+			Name:        ResourceReadToolName,
+			Description: "Read a resource exposed by an MCP server. Use resource.list to discover URIs.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"mcp": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the MCP server that exposes the resource.",
+					},
+					"uri": map[string]interface{}{
+						"type":        "string",
+						"description": "URI of the resource, as returned by resource.list.",
+					},
+				},
+				"required": []string{"mcp", "uri"},
+			},
+		},
+	}
+}
+
+// PolicyTarget scopes policy to the MCP named in args.
+func (t *ResourceReadTool) PolicyTarget(args map[string]interface{}) (string, string) {
+	mcpName, _ := stringArg(args, "mcp")
+	return mcpName, ResourceReadToolName
+}
+
+// Execute reads the requested resource. Text contents are returned as-is
+// (truncated to maxResourceText); binary contents are summarised.
+func (t *ResourceReadTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	mcpName, r, err := t.reader(ResourceReadToolName, args)
+	if err != nil {
+		return "", err
+	}
+	uri, ok := stringArg(args, "uri")
+	if !ok || uri == "" {
+		return "", fmt.Errorf("resource.read: missing required argument 'uri'")
+	}
+	result, err := r.ReadResource(ctx, uri)
+	if err != nil {
+		return "", fmt.Errorf("resource.read %s %s: %w", mcpName, uri, err)
+	}
+
+	var b strings.Builder
+	for _, c := range result.Contents {
+		switch {
+		case c.Text != "":
+			b.WriteString(c.Text)
+			if !strings.HasSuffix(c.Text, "\n") {
+				b.WriteString("\n")
+			}
+		case c.Blob != "":
+			fmt.Fprintf(&b, "[binary content %s, %d bytes base64]\n", c.MIME, len(c.Blob))
+		}
+	}
+	out := b.String()
+	if out == "" {
+		return "(empty resource)", nil
+	}
+	return truncateText(out), nil
+}
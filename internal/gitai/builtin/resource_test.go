@@ -0,0 +1,35 @@
+package builtin
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/mcp"
+)
+
+// textResource is a ResourceReader serving one text resource.
+type textResource string
+
+func (r textResource) ListResources(context.Context) ([]mcp.Resource, error) { return nil, nil }
+
+func (r textResource) ReadResource(_ context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	return &mcp.ReadResourceResult{Contents: []mcp.ResourceContents{{URI: uri, Text: string(r)}}}, nil
+}
+
+func TestResourceReadTool_TruncatesOnRuneBoundary(t *testing.T) {
+	// A one-byte prefix puts a two-byte rune across the cut.
+	src := textResource("x" + strings.Repeat("é", maxResourceText))
+	tool := NewResourceReadTool(func(string) ResourceReader { return src })
+
+	got, err := tool.Execute(context.Background(), map[string]interface{}{"mcp": "docs", "uri": "docs://big"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	body := strings.TrimSuffix(got, "\n[truncated]")
+	if body == got || !utf8.ValidString(body) || len(body) > maxResourceText {
+		t.Errorf("resource.read returned %d bytes (valid %v), want at most %d valid bytes and a marker",
+			len(body), utf8.ValidString(body), maxResourceText)
+	}
+}
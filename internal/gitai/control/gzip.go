@@ -0,0 +1,170 @@
+package control
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// decompressBody returns a handler that inflates request bodies sent with
+// Content-Encoding: gzip before calling next, so handlers always read plain
+// JSON. At most limit bytes are accepted after decompression: a larger body —
+// typically a small archive that inflates enormously — is refused with 413
+// before any handler runs. Other content encodings are refused with 415.
+func decompressBody(limit int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); enc {
+		case "", "identity":
+			next.ServeHTTP(w, r)
+			return
+		case "gzip", "x-gzip":
+		default:
+			writeError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported Content-Encoding %q", enc))
+			return
+		}
+
+		// Compressed data is never meaningfully larger than its output, so
+		// the same cap bounds how much of the wire body is read.
+		zr, err := gzip.NewReader(http.MaxBytesReader(w, r.Body, limit))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid gzip body: "+err.Error())
+			return
+		}
+		defer zr.Close()
+		body, err := io.ReadAll(io.LimitReader(zr, limit+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid gzip body: "+err.Error())
+			return
+		}
+		if int64(len(body)) > limit {
+			slog.Warn("ACP: gzip body rejected", "path", r.URL.Path, "limit", limit)
+			writeError(w, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("decompressed body exceeds %d bytes", limit))
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.Header.Del("Content-Encoding")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// inflateEventBody is decompressBody for the event routes, which check auth
+// themselves: a gzip body is inflated only once the caller passes the
+// bearer check (or the localhost bypass), so anonymous callers cannot make
+// the agent decompress anything. HMAC webhook gateways are the exception,
+// since their signature covers the body; theirs is inflated, within limit,
+// before the signature is checked.
+func (s *Server) inflateEventBody(limit int64, next http.Handler) http.Handler {
+	inflate := decompressBody(limit, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "" && !s.localhostBypass(r) && !s.isHMACWebhook(r.PathValue("source")) {
+			if msg := s.bearerError(r); msg != "" {
+				writeError(w, http.StatusUnauthorized, msg)
+				return
+			}
+		}
+		inflate.ServeHTTP(w, r)
+	})
+}
+
+// isHMACWebhook reports whether source is a webhook gateway authenticated by
+// an HMAC signature in the active config.
+func (s *Server) isHMACWebhook(source string) bool {
+	if s.handlers.ActiveConfig == nil {
+		return false
+	}
+	cfg := s.handlers.ActiveConfig()
+	if cfg == nil {
+		return false
+	}
+	for _, gw := range cfg.Gateways {
+		if gw.Name == source {
+			return gw.Type == "webhook" && gw.Config["authType"] == "hmac-sha256"
+		}
+	}
+	return false
+}
+
+// compressResponse gzips response bodies for clients that send
+// Accept-Encoding: gzip. Go's HTTP client does so by default and inflates
+// the body transparently, so large /status, /calls and /turns responses
+// shrink on the wire without any change on the caller's side.
+func compressResponse(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header value allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			v, err := strconv.ParseFloat(q, 64)
+			return err == nil && v > 0
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter compresses everything written after a status that
+// carries a body.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	zw          *gzip.Writer
+	wroteHeader bool
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+	h := g.Header()
+	if status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified &&
+		h.Get("Content-Encoding") == "" {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		g.zw = gzip.NewWriter(g.ResponseWriter)
+	}
+	g.ResponseWriter.WriteHeader(status)
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !g.wroteHeader {
+		// Sniff the type from the plain bytes; net/http would otherwise see
+		// only compressed ones.
+		if g.Header().Get("Content-Type") == "" {
+			g.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.zw == nil {
+		return g.ResponseWriter.Write(p)
+	}
+	return g.zw.Write(p)
+}
+
+func (g *gzipResponseWriter) close() {
+	if g.zw != nil {
+		g.zw.Close()
+	}
+}
@@ -0,0 +1,172 @@
+package control
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// idempotencyTTL is how long the server caches responses by idempotency key.
+const idempotencyTTL = 60 * time.Second
+
+// DefaultIdempotencyMaxEntries is the idempotency cache size used when
+// Handlers.IdempotencyMaxEntries is 0.
+const DefaultIdempotencyMaxEntries = 10000
+
+// idempotencySweepInterval is how often Start sweeps expired entries out of
+// the idempotency cache.
+const idempotencySweepInterval = idempotencyTTL / 2
+
+// idempotencyEntry is a cached response for a single idempotency key.
+type idempotencyEntry struct {
+	key       string
+	status    int
+	body      []byte
+	expiresAt time.Time
+}
+
+// IdempotencyRecord is a response cached under an X-Idempotency-Key, in the
+// form exchanged with Handlers.SaveIdempotency and Handlers.LoadIdempotency.
+type IdempotencyRecord struct {
+	Key       string
+	Status    int
+	Body      []byte
+	ExpiresAt time.Time
+}
+
+// idempotencyCache is an in-memory store keyed by X-Idempotency-Key, holding
+// at most maxEntries responses. Entries are kept in expiry order, so the
+// oldest is evicted first when the cache is full and expired entries are
+// swept from the front. When save is set, every entry is also written
+// through to persistent storage.
+type idempotencyCache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element // values are *idempotencyEntry
+	order      *list.List               // oldest expiry at the front
+	maxEntries int
+	save       func(IdempotencyRecord) error
+}
+
+// newIdempotencyCache returns an empty cache holding at most maxEntries
+// responses; maxEntries <= 0 means DefaultIdempotencyMaxEntries.
+func newIdempotencyCache(maxEntries int) *idempotencyCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultIdempotencyMaxEntries
+	}
+	return &idempotencyCache{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// load seeds the cache with persisted records, skipping expired ones.
+func (c *idempotencyCache) load(records []IdempotencyRecord) {
+	sort.Slice(records, func(i, j int) bool { return records[i].ExpiresAt.Before(records[j].ExpiresAt) })
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range records {
+		if !now.Before(r.ExpiresAt) {
+			continue
+		}
+		c.putLocked(&idempotencyEntry{key: r.Key, status: r.Status, body: r.Body, expiresAt: r.ExpiresAt})
+	}
+}
+
+// get returns the cached entry (ok=true) if the key exists and has not expired.
+func (c *idempotencyCache) get(key string) (idempotencyEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return idempotencyEntry{}, false
+	}
+	e := el.Value.(*idempotencyEntry)
+	if time.Now().After(e.expiresAt) {
+		return idempotencyEntry{}, false
+	}
+	return *e, true
+}
+
+// set stores a response for the given key with the configured TTL and
+// writes it through to persistent storage when configured. A failed write
+// is logged; the in-memory entry still deduplicates retries until restart.
+func (c *idempotencyCache) set(key string, status int, body []byte) {
+	e := &idempotencyEntry{
+		key:       key,
+		status:    status,
+		body:      body,
+		expiresAt: time.Now().Add(idempotencyTTL),
+	}
+	c.mu.Lock()
+	c.putLocked(e)
+	c.mu.Unlock()
+
+	if c.save != nil {
+		if err := c.save(IdempotencyRecord{Key: key, Status: status, Body: body, ExpiresAt: e.expiresAt}); err != nil {
+			slog.Warn("ACP: failed to persist idempotency entry", "key", key, "err", err)
+		}
+	}
+}
+
+// putLocked inserts e at the back of the expiry order, replacing any entry
+// for the same key. When the cache is full it first sweeps expired entries
+// and then evicts the oldest ones. c.mu must be held.
+func (c *idempotencyCache) putLocked(e *idempotencyEntry) {
+	if el, ok := c.entries[e.key]; ok {
+		c.order.Remove(el)
+		delete(c.entries, e.key)
+	}
+	if len(c.entries) >= c.maxEntries {
+		c.sweepLocked(time.Now())
+	}
+	for len(c.entries) >= c.maxEntries {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*idempotencyEntry).key)
+	}
+	c.entries[e.key] = c.order.PushBack(e)
+}
+
+// sweep drops every entry that has expired by now.
+func (c *idempotencyCache) sweep(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweepLocked(now)
+}
+
+func (c *idempotencyCache) sweepLocked(now time.Time) {
+	for el := c.order.Front(); el != nil; el = c.order.Front() {
+		e := el.Value.(*idempotencyEntry)
+		if now.Before(e.expiresAt) {
+			return
+		}
+		c.order.Remove(el)
+		delete(c.entries, e.key)
+	}
+}
+
+// len returns the number of cached entries, expired or not.
+func (c *idempotencyCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// runSweeper sweeps expired entries every interval until ctx is done.
+func (c *idempotencyCache) runSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.sweep(now)
+		}
+	}
+}
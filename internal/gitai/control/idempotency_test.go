@@ -0,0 +1,63 @@
+package control
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyCache_NeverExceedsCap(t *testing.T) {
+	c := newIdempotencyCache(3)
+	for i := 0; i < 10; i++ {
+		c.set(fmt.Sprintf("key-%d", i), 200, nil)
+		if n := c.len(); n > 3 {
+			t.Fatalf("after %d sets the cache holds %d entries, want at most 3", i+1, n)
+		}
+	}
+	for _, key := range []string{"key-0", "key-6"} {
+		if _, ok := c.get(key); ok {
+			t.Errorf("%s should have been evicted", key)
+		}
+	}
+	for _, key := range []string{"key-7", "key-8", "key-9"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("%s should still be cached", key)
+		}
+	}
+}
+
+func TestIdempotencyCache_ResetMovesKeyToBack(t *testing.T) {
+	c := newIdempotencyCache(2)
+	c.set("a", 200, nil)
+	c.set("b", 200, nil)
+	c.set("a", 202, nil) // a is now the newest entry
+	c.set("c", 200, nil) // evicts b, not a
+
+	if e, ok := c.get("a"); !ok || e.status != 202 {
+		t.Errorf("a = %+v, %v; want the re-set entry", e, ok)
+	}
+	if _, ok := c.get("b"); ok {
+		t.Error("b should have been evicted as the oldest entry")
+	}
+}
+
+func TestIdempotencyCache_SweepDropsExpiredEntries(t *testing.T) {
+	c := newIdempotencyCache(0)
+	now := time.Now()
+	c.load([]IdempotencyRecord{
+		{Key: "later", Status: 200, ExpiresAt: now.Add(30 * time.Second)},
+		{Key: "soon", Status: 200, ExpiresAt: now.Add(time.Second)},
+	})
+	c.set("fresh", 200, nil)
+	if n := c.len(); n != 3 {
+		t.Fatalf("len = %d, want 3", n)
+	}
+
+	c.sweep(now.Add(45 * time.Second))
+	if n := c.len(); n != 1 {
+		t.Errorf("after sweep len = %d, want 1", n)
+	}
+	if _, ok := c.get("fresh"); !ok {
+		t.Error("unexpired entry was swept")
+	}
+}
@@ -0,0 +1,102 @@
+package control
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metrics is a snapshot of the agent counters exposed by GET /metrics. All
+// counts are totals since agent startup.
+type Metrics struct {
+	// TurnsProcessed counts Matrix and event turns started, counted when the
+	// turn is logged and before the LLM is called, so turns that later fail
+	// are included.
+	TurnsProcessed int64
+	// ToolCalls counts tool calls dispatched, whatever their outcome.
+	ToolCalls int64
+	// PolicyDenials counts tool calls rejected by the Gosuto policy.
+	PolicyDenials int64
+	// EventsIngested counts events accepted onto the event queue, keyed by
+	// event source.
+	EventsIngested map[string]int64
+	// SenderRateLimited counts Matrix messages refused by the per-sender
+	// limits.maxRequestsPerMinute.
+	SenderRateLimited int64
+}
+
+// metricsContentType is the Prometheus text exposition format, version 0.0.4.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var m Metrics
+	if s.handlers.Metrics != nil {
+		m = s.handlers.Metrics()
+	}
+	var msgsOut int64
+	if s.handlers.MessagesOutbound != nil {
+		msgsOut = s.handlers.MessagesOutbound()
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	writeMetric(w, "gitai_turns_total", "counter", "Turns processed since agent startup.", nil, m.TurnsProcessed)
+	writeMetric(w, "gitai_tool_calls_total", "counter", "Tool calls dispatched since agent startup.", nil, m.ToolCalls)
+	writeMetric(w, "gitai_policy_denials_total", "counter", "Tool calls denied by policy since agent startup.", nil, m.PolicyDenials)
+
+	sources := make([]string, 0, len(m.EventsIngested))
+	for src := range m.EventsIngested {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+	samples := make([]metricSample, 0, len(sources))
+	for _, src := range sources {
+		samples = append(samples, metricSample{label: `source="` + escapeLabel(src) + `"`, value: m.EventsIngested[src]})
+	}
+	writeMetric(w, "gitai_events_ingested_total", "counter", "Events accepted onto the event queue, by source.", samples, 0)
+
+	writeMetric(w, "gitai_rate_limit_rejections_total", "counter", "Requests rejected by a rate limit, by scope.", []metricSample{
+		{label: `scope="event"`, value: s.eventLimiter.rejected.Load()},
+		{label: `scope="sender"`, value: m.SenderRateLimited},
+	}, 0)
+	writeMetric(w, "gitai_messages_outbound_total", "counter", "Successful matrix.send_message calls since agent startup.", nil, msgsOut)
+	if s.handlers.EventQueueStats != nil {
+		depth, capacity := s.handlers.EventQueueStats()
+		writeMetric(w, "gitai_event_queue_depth", "gauge", "Events waiting on the event queue for a turn.", nil, int64(depth))
+		writeMetric(w, "gitai_event_queue_capacity", "gauge", "Events the event queue holds before ingress answers 503.", nil, int64(capacity))
+	}
+
+	fmt.Fprintf(w, "# HELP gitai_uptime_seconds Seconds since the agent started.\n# TYPE gitai_uptime_seconds gauge\ngitai_uptime_seconds %g\n",
+		time.Since(s.handlers.StartedAt).Seconds())
+}
+
+// metricSample is one labelled sample of a metric family.
+type metricSample struct {
+	label string // rendered label set without braces, e.g. source="cron"
+	value int64
+}
+
+// writeMetric writes a metric family. With samples nil it writes a single
+// unlabelled sample with value; otherwise it writes one line per sample.
+func writeMetric(w io.Writer, name, typ, help string, samples []metricSample, value int64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	if samples == nil {
+		fmt.Fprintf(w, "%s %d\n", name, value)
+		return
+	}
+	for _, s := range samples {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, s.label, s.value)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value for the text exposition format.
+func escapeLabel(v string) string { return labelEscaper.Replace(v) }
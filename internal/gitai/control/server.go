@@ -7,21 +7,50 @@
 //   - Bearer-token authentication: set Handlers.Token to require
 //     "Authorization: Bearer <token>" on every request.  When Token is empty
 //     authentication is disabled (dev/test mode).
+//   - Request bodies may be sent with Content-Encoding: gzip; they are
+//     decompressed up to the route's body limit (1 MiB, 8 MiB for event
+//     batches) and anything that inflates further is refused with 413.
+//     Responses are gzipped for clients that send Accept-Encoding: gzip.
 //   - Idempotency cache: mutating endpoints (/config/apply, /secrets/apply,
 //     /process/restart, /tasks/cancel) record the X-Idempotency-Key header and
-//     return the cached 200 response on replay within the TTL window.
+//     return the cached 200 response on replay within the TTL window. With
+//     Handlers.SaveIdempotency/LoadIdempotency set, the cache survives a
+//     restart.
 //
 // Endpoints:
 //
 //	GET  /health              → HealthResponse
 //	GET  /status              → StatusResponse
-//	POST /config/apply        → ConfigApplyRequest → 200 OK
+//	GET  /metrics             → Prometheus text exposition of agent counters
+//	GET  /config              → ConfigResponse (404 when no config is loaded)
+//	POST /config/apply        → ConfigApplyRequest → 200 OK (422 when the hash does not match the YAML)
+//	POST /config/canary       → ConfigCanaryRequest → 200 OK (config for one room only)
+//	POST /config/canary/abort → 200 OK (404 when no canary is active)
 //	POST /secrets/apply       → SecretsApplyRequest → 200 OK  [disabled by default, see R4.4]
-//	POST /secrets/token       → SecretsTokenRequest → 200 OK (redeems via Kuze)
+//	POST /secrets/token       → SecretsTokenRequest → SecretsTokenResponse (redeems via Kuze; 207 on partial failure)
 //	POST /process/restart     → 202 Accepted (triggers shutdown via restartFn)
 //	POST /tasks/cancel        → 202 Accepted (cancels current in-flight task)
 //	POST /approvals/decision  → 202 Accepted (R6.4: approval decision via Ruriko)
 //	POST /events/{source}     → Event envelope → 202 Accepted (R12.1)
+//	POST /events/{source}/batch → []Event envelope → EventBatchResponse (per-item results)
+//	GET  /tools               → ToolListResponse (tools offered to the LLM)
+//	GET  /calls               → ToolCallListResponse (tool-call audit trail)
+//	GET  /turns               → TurnListResponse (recent turn summaries)
+//	GET  /turns/{trace}/transcript → TurnTranscriptResponse (opt-in turn transcripts)
+//	POST /token/rotate        → TokenRotateRequest → TokenRotateResponse
+//	POST /broadcast           → BroadcastRequest → BroadcastResponse
+//	GET  /dlq                 → DeadLetterListResponse (failed event turns)
+//	POST /dlq/retry           → DeadLetterRetryRequest → 202 Accepted
+//
+// Transport security:
+//   - Plaintext HTTP by default (localhost sidecar / private network).  When
+//     Handlers.TLSCertFile and Handlers.TLSKeyFile are set the listener
+//     serves HTTPS instead, so the bearer token is not sent in the clear.
+//
+// Timeouts:
+//   - Every route runs under its own deadline (see DefaultRouteTimeouts),
+//     overridable per route via Handlers.RouteTimeouts.  A handler that
+//     overruns gets a 503 and its request context is cancelled.
 //
 // Security hardening (Phase R4.4):
 //   - POST /secrets/apply is disabled by default (Handlers.DirectSecretPushEnabled=false).
@@ -32,98 +61,136 @@
 // Event ingress (Phase R12.1 + R12.4):
 //   - POST /events/{source} accepts normalised Event envelopes from gateway processes
 //     (cron, external binaries) AND raw webhook deliveries from type:webhook gateways.
-//   - Built-in gateways (cron) run on localhost and bypass bearer-token auth.
+//   - Built-in gateways (cron) run on localhost and bypass bearer-token auth,
+//     unless Handlers.DisableLocalhostBypass is set (FEATURE_STRICT_EVENT_AUTH).
 //   - External gateways must supply the ACP bearer token in Authorization: Bearer <token>.
 //   - Webhook gateways (type:webhook) support either bearer or hmac-sha256 auth.
 //     HMAC-SHA256 validates X-Hub-Signature-256 over the raw request body against the
 //     secret named by config["hmacSecretRef"]; raw body is then wrapped into an Event.
-//   - A fixed-window rate limiter (per-source + global) enforces MaxEventsPerMinute
-//     from the active Gosuto Limits, returning 429 when exceeded.
+//   - A rate limiter (per-source + global) enforces MaxEventsPerMinute from the
+//     active Gosuto Limits over a sliding (or, if configured, fixed) window,
+//     returning 429 when exceeded. An unset limit falls back to
+//     Handlers.DefaultMaxEventsPerMinute unless UnlimitedEvents is set.
+//     A gateway's own maxEventsPerMinute caps its source window more tightly.
 package control
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bdobrica/Ruriko/common/ratelimit"
+	"github.com/bdobrica/Ruriko/common/redact"
 	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
 	"github.com/bdobrica/Ruriko/common/spec/envelope"
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/common/trace"
 	"github.com/bdobrica/Ruriko/internal/gitai/gateway"
 )
 
-// idempotencyTTL is how long the server caches responses by idempotency key.
-const idempotencyTTL = 60 * time.Second
-
 // maxEventBodyBytes caps the inbound event request body to prevent memory
 // exhaustion from a misbehaving gateway process.
 const maxEventBodyBytes = 1 * 1024 * 1024 // 1 MiB
 
-// idempotencyEntry is a cached response for a single idempotency key.
-type idempotencyEntry struct {
-	status    int
-	body      []byte
-	expiresAt time.Time
-}
-
-// idempotencyCache is a simple in-memory store keyed by X-Idempotency-Key.
-type idempotencyCache struct {
-	mu      sync.Mutex
-	entries map[string]idempotencyEntry
-}
+// Batch event ingress bounds: a batch may carry at most maxEventBatchItems
+// envelopes and maxEventBatchBytes of JSON.
+const (
+	maxEventBatchItems = 500
+	maxEventBatchBytes = 8 * 1024 * 1024 // 8 MiB
+)
 
-func newIdempotencyCache() *idempotencyCache {
-	return &idempotencyCache{entries: make(map[string]idempotencyEntry)}
-}
+// Per-envelope statuses in an EventBatchResult.
+const (
+	eventBatchQueued   = "queued"
+	eventBatchRejected = "rejected"
+)
 
 // --- event rate limiter ---
 
+// Event rate-limit algorithms accepted by Handlers.EventRateAlgorithm.
+const (
+	// EventRateSlidingWindow counts events over the trailing minute, so no
+	// more than the limit pass in any 60-second span. This is the default.
+	EventRateSlidingWindow = "sliding"
+	// EventRateFixedWindow counts events per 1-minute window
+	// that resets wholesale; up to twice the limit may pass across a reset.
+	EventRateFixedWindow = "fixed"
+)
+
 // eventRateLimiter enforces per-source and global event ingress rate limits
-// using a fixed 1-minute window. When maxPerMinute is 0 all events are allowed.
+// over a 1-minute window. A limit of 0 allows all events.
 type eventRateLimiter struct {
-	limiter *ratelimit.KeyedFixedWindow
+	limiter interface {
+		AllowLimits(checks ...ratelimit.KeyLimit) (ratelimit.KeyLimit, bool)
+	}
+	// rejected counts events refused by the limiter, for GET /metrics.
+	rejected atomic.Int64
 }
 
-func newEventRateLimiter() *eventRateLimiter {
-	return &eventRateLimiter{
-		limiter: ratelimit.NewKeyedFixedWindow(time.Minute),
+// newEventRateLimiter returns a limiter using algorithm, one of the
+// EventRate* constants. Unknown values fall back to the sliding window.
+func newEventRateLimiter(algorithm string) *eventRateLimiter {
+	switch algorithm {
+	case EventRateFixedWindow:
+		return &eventRateLimiter{limiter: ratelimit.NewKeyedFixedWindow(time.Minute)}
+	case "", EventRateSlidingWindow:
+	default:
+		slog.Warn("unknown event rate algorithm; using sliding window", "algorithm", algorithm)
 	}
+	return &eventRateLimiter{limiter: ratelimit.NewKeyedSlidingWindow(time.Minute)}
 }
 
-// allow returns true when the event may proceed. It checks both the per-source
-// window and the global window; both must have remaining capacity.
-func (l *eventRateLimiter) allow(source string, maxPerMinute int) bool {
-	return l.limiter.AllowAll(maxPerMinute, "__global__", "source:"+source)
+// eventLimit returns the events-per-minute limit for limits: the configured
+// value when set, 0 (unlimited) when the config explicitly opts out, and
+// the deployment default otherwise.
+func (s *Server) eventLimit(limits gosutospec.Limits) int {
+	if limits.MaxEventsPerMinute > 0 || limits.UnlimitedEvents {
+		return limits.MaxEventsPerMinute
+	}
+	return s.handlers.DefaultMaxEventsPerMinute
 }
 
-// get returns the cached entry (ok=true) if the key exists and has not expired.
-func (c *idempotencyCache) get(key string) (idempotencyEntry, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	e, ok := c.entries[key]
-	if !ok || time.Now().After(e.expiresAt) {
-		return idempotencyEntry{}, false
+// gatewayEventLimit returns the per-source events-per-minute limit set on gw,
+// or 0 when gw is nil or sets none.
+func gatewayEventLimit(gw *gosutospec.Gateway) int {
+	if gw == nil {
+		return 0
 	}
-	return e, true
+	return gw.MaxEventsPerMinute
 }
 
-// set stores a response for the given key with the configured TTL.
-func (c *idempotencyCache) set(key string, status int, body []byte) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.entries[key] = idempotencyEntry{
-		status:    status,
-		body:      body,
-		expiresAt: time.Now().Add(idempotencyTTL),
-	}
+// allow reports whether the event may proceed. It checks both the per-source
+// window and the global window; both must have remaining capacity. The
+// per-source window is held to min(global, perSource) of the positive limits.
+// When the event is refused it also returns the limit that was exhausted.
+func (l *eventRateLimiter) allow(source string, global, perSource int) (int, bool) {
+	sourceLimit := global
+	if perSource > 0 && (sourceLimit <= 0 || perSource < sourceLimit) {
+		sourceLimit = perSource
+	}
+	denied, ok := l.limiter.AllowLimits(
+		ratelimit.KeyLimit{Key: "__global__", Limit: global},
+		ratelimit.KeyLimit{Key: "source:" + source, Limit: sourceLimit},
+	)
+	if !ok {
+		l.rejected.Add(1)
+	}
+	return denied.Limit, ok
 }
 
 // ACP wire schema aliases (Phase 1 deduplication).
@@ -131,12 +198,35 @@ func (c *idempotencyCache) set(key string, status int, body []byte) {
 // Keep these aliases in the control package for backward compatibility with
 // existing imports and tests while using a single shared schema source.
 type ConfigApplyRequest = acpspec.ConfigApplyRequest
+type ConfigResponse = acpspec.ConfigResponse
+type ConfigCanaryRequest = acpspec.ConfigCanaryRequest
 type SecretsApplyRequest = acpspec.SecretsApplyRequest
 type SecretLease = acpspec.SecretLease
 type SecretsTokenRequest = acpspec.SecretsTokenRequest
+type SecretsTokenResponse = acpspec.SecretsTokenResponse
+type SecretLeaseFailure = acpspec.SecretLeaseFailure
 type ApprovalDecisionRequest = acpspec.ApprovalDecisionRequest
 type ToolCallRequest = acpspec.ToolCallRequest
 type ToolCallResponse = acpspec.ToolCallResponse
+type DeadLetter = acpspec.DeadLetter
+type DeadLetterListResponse = acpspec.DeadLetterListResponse
+type DeadLetterRetryRequest = acpspec.DeadLetterRetryRequest
+type ToolInfo = acpspec.ToolInfo
+type ToolListResponse = acpspec.ToolListResponse
+type ToolCallRecord = acpspec.ToolCallRecord
+type ToolCallListResponse = acpspec.ToolCallListResponse
+type TurnSummary = acpspec.TurnSummary
+type TurnListResponse = acpspec.TurnListResponse
+type TranscriptMessage = acpspec.TranscriptMessage
+type TranscriptToolCall = acpspec.TranscriptToolCall
+type TurnTranscriptResponse = acpspec.TurnTranscriptResponse
+type TokenRotateRequest = acpspec.TokenRotateRequest
+type TokenRotateResponse = acpspec.TokenRotateResponse
+type BroadcastRequest = acpspec.BroadcastRequest
+type BroadcastResponse = acpspec.BroadcastResponse
+type EventBatchResult = acpspec.EventBatchResult
+type EventBatchResponse = acpspec.EventBatchResponse
+type ErrorResponse = acpspec.ErrorResponse
 
 // kuzeRedeemResponse mirrors the JSON returned by GET /kuze/redeem/<token>.
 type kuzeRedeemResponse struct {
@@ -146,12 +236,50 @@ type kuzeRedeemResponse struct {
 	Value string `json:"value"`
 }
 
+// DefaultMaxSecretLeases is the lease cap per POST /secrets/token used when
+// Handlers.MaxSecretLeases is 0.
+const DefaultMaxSecretLeases = 100
+
+// MaxConcurrentRedemptions bounds the Kuze redemption calls one
+// POST /secrets/token makes at a time.
+const MaxConcurrentRedemptions = 4
+
 // maxRedeemResponseBytes caps the Kuze redemption response body to prevent
 // memory exhaustion from a misbehaving (or compromised) Kuze endpoint.
 const maxRedeemResponseBytes = 64 * 1024 // 64 KiB
 
 type HealthResponse = acpspec.HealthResponse
 type StatusResponse = acpspec.StatusResponse
+type ConfigApplyStatus = acpspec.ConfigApplyStatus
+type CanaryStatus = acpspec.CanaryStatus
+type MonthlySpend = acpspec.MonthlySpend
+type MCPServerStatus = acpspec.MCPServerStatus
+
+// Values of ConfigApplyStatus.Result.
+const (
+	ConfigApplyOK      = acpspec.ConfigApplyOK
+	ConfigApplyWarning = acpspec.ConfigApplyWarning
+	ConfigApplyError   = acpspec.ConfigApplyError
+)
+
+// Values of ErrorResponse.Code.
+const (
+	ErrorCodeBadRequest           = acpspec.ErrorCodeBadRequest
+	ErrorCodeUnauthorized         = acpspec.ErrorCodeUnauthorized
+	ErrorCodeNotFound             = acpspec.ErrorCodeNotFound
+	ErrorCodeMethodNotAllowed     = acpspec.ErrorCodeMethodNotAllowed
+	ErrorCodeConflict             = acpspec.ErrorCodeConflict
+	ErrorCodeGone                 = acpspec.ErrorCodeGone
+	ErrorCodePayloadTooLarge      = acpspec.ErrorCodePayloadTooLarge
+	ErrorCodeUnsupportedMediaType = acpspec.ErrorCodeUnsupportedMediaType
+	ErrorCodeUnprocessable        = acpspec.ErrorCodeUnprocessable
+	ErrorCodeRateLimited          = acpspec.ErrorCodeRateLimited
+	ErrorCodeInternal             = acpspec.ErrorCodeInternal
+	ErrorCodeBadGateway           = acpspec.ErrorCodeBadGateway
+	ErrorCodeUnavailable          = acpspec.ErrorCodeUnavailable
+	ErrorCodeHashMismatch         = acpspec.ErrorCodeHashMismatch
+	ErrorCodeTimeout              = acpspec.ErrorCodeTimeout
+)
 
 // Handlers bundles the callbacks the server delegates to.
 type Handlers struct {
@@ -161,11 +289,44 @@ type Handlers struct {
 	Version string
 	// StartedAt is the time the binary started.
 	StartedAt time.Time
+	// Restarts is the number of earlier starts recorded in the agent's
+	// store, and FirstStartedAt the time of the first one. Both are
+	// reported on /status; a zero FirstStartedAt leaves them out.
+	Restarts       int
+	FirstStartedAt time.Time
 
 	// Token, when non-empty, is the expected bearer token for all requests.
 	// When empty, authentication is disabled (useful in local dev/test).
+	// Ignored when Tokens is set.
 	Token string
 
+	// Tokens, when set, holds the accepted bearer tokens instead of Token,
+	// so the caller can share it (e.g. with the cron gateways) and observe
+	// rotations made through POST /token/rotate.
+	Tokens *TokenSet
+
+	// PersistToken stores a token installed by POST /token/rotate so it
+	// survives a restart. It runs before the rotation takes effect; an
+	// error aborts the rotation. When nil the new token lives in memory only.
+	PersistToken func(token string) error
+
+	// SaveIdempotency persists a response cached under an X-Idempotency-Key
+	// so a retry that arrives after a restart is still answered from the
+	// cache. LoadIdempotency returns the persisted responses; New seeds the
+	// cache with the unexpired ones. When nil the cache lives in memory only.
+	SaveIdempotency func(rec IdempotencyRecord) error
+	LoadIdempotency func() ([]IdempotencyRecord, error)
+
+	// IdempotencyMaxEntries caps the number of responses held in the
+	// idempotency cache; when it is full the oldest entry is evicted.
+	// 0 means DefaultIdempotencyMaxEntries.
+	IdempotencyMaxEntries int
+
+	// MaxSecretLeases caps the leases one POST /secrets/token may carry;
+	// larger requests are rejected with 400 before Kuze is contacted.
+	// 0 means DefaultMaxSecretLeases.
+	MaxSecretLeases int
+
 	// DirectSecretPushEnabled controls whether the legacy POST /secrets/apply
 	// endpoint (which carries raw secret values in the ACP request body) is
 	// active.  Production deployments MUST leave this false (the default) so
@@ -175,14 +336,69 @@ type Handlers struct {
 	// Feature flag: FEATURE_DIRECT_SECRET_PUSH (default: false / OFF)
 	DirectSecretPushEnabled bool
 
+	// DisableLocalhostBypass makes POST /events/{source} demand the bearer
+	// token from loopback callers too, so a compromised process on the same
+	// host cannot inject events. Built-in gateways must then carry the token
+	// (the cron manager does when given one via SetACPToken).
+	//
+	// Feature flag: FEATURE_STRICT_EVENT_AUTH (default: false)
+	DisableLocalhostBypass bool
+
+	// DefaultMaxEventsPerMinute is the event rate limit applied when the
+	// active Gosuto config sets no limits.maxEventsPerMinute and does not
+	// opt out with limits.unlimitedEvents. 0 disables the default.
+	//
+	// Environment variable: GITAI_DEFAULT_MAX_EVENTS_PER_MINUTE (default: 60)
+	DefaultMaxEventsPerMinute int
+
+	// EventRateAlgorithm selects how event rate limits are counted:
+	// EventRateSlidingWindow (default) or EventRateFixedWindow.
+	//
+	// Environment variable: GITAI_EVENT_RATE_ALGORITHM (default: "sliding")
+	EventRateAlgorithm string
+
+	// TLSCertFile and TLSKeyFile are PEM file paths for the server
+	// certificate and its private key. When both are set the ACP listener
+	// serves HTTPS; when both are empty it serves plaintext HTTP.
+	TLSCertFile string
+	TLSKeyFile  string
+
 	// GosutoHash returns the hash of the currently applied Gosuto config.
 	GosutoHash func() string
+	// ConfigYAML returns the raw YAML of the currently applied Gosuto config,
+	// or "" when no config has been loaded. Used by GET /config.
+	// When nil the endpoint returns 503 Service Unavailable.
+	ConfigYAML func() string
 	// MCPNames returns the names of running MCP servers.
 	MCPNames func() []string
+	// MCPsInitializing returns the names of MCP servers whose readiness
+	// probe has not passed yet. When nil, the field is omitted from the
+	// status response.
+	MCPsInitializing func() []string
+	// MCPStatus returns the health of every configured MCP server. When
+	// nil, the field is omitted from the status response.
+	MCPStatus func() []MCPServerStatus
+	// StartupFailures returns what failed to start when the agent booted,
+	// until the next config apply clears it.
+	// When nil, the field is omitted from the status response.
+	StartupFailures func() []string
 	// ApplyConfig validates and applies a new Gosuto YAML.
 	ApplyConfig func(yaml, hash string) error
-	// ApplySecrets updates the in-memory secret store.
-	ApplySecrets func(secrets map[string]string) error
+	// ApplyCanary validates a Gosuto YAML and uses it only for turns in
+	// room, replacing any earlier canary. When nil POST /config/canary
+	// returns 503 Service Unavailable.
+	ApplyCanary func(yaml, hash, room string) error
+	// AbortCanary drops the active canary and reports whether there was one.
+	AbortCanary func() bool
+	// Canary returns the active canary for GET /status, or nil.
+	Canary func() *CanaryStatus
+	// MonthlySpend returns the current month's estimated LLM spend for
+	// GET /status, or nil.
+	MonthlySpend func() *MonthlySpend
+	// ApplySecrets updates the in-memory secret store. ttls carries
+	// per-ref cache TTL overrides delivered with Kuze leases; it is nil for
+	// the legacy direct push.
+	ApplySecrets func(secrets map[string]string, ttls map[string]time.Duration) error
 	// RequestRestart signals the application to perform a graceful restart.
 	RequestRestart func()
 	// RequestCancel signals the application to cancel the current in-flight task.
@@ -212,16 +428,86 @@ type Handlers struct {
 
 	// HandleEvent is invoked with a fully validated inbound event envelope.
 	// Implementations must be non-blocking (e.g. a channel send or goroutine
-	// launch) so the HTTP response is returned promptly.
+	// launch) so the HTTP response is returned promptly. Returning
+	// ErrEventQueueFull makes the endpoint answer 503 with Retry-After so the
+	// gateway backs off.
 	// When nil, POST /events/{source} returns 503 Service Unavailable.
-	HandleEvent func(ctx context.Context, evt *envelope.Event)
+	HandleEvent func(ctx context.Context, evt *envelope.Event) error
+
+	// RouteTimeouts overrides the per-route request timeouts, keyed by route
+	// pattern as registered (e.g. "/health", "/events/{source}"). Routes
+	// missing from the map use DefaultRouteTimeouts; a zero or negative
+	// duration disables the per-route timeout (the server-wide read/write
+	// deadline, sized from the longest route timeout, still applies).
+	RouteTimeouts map[string]time.Duration
+
+	// LastConfigApply returns the outcome of the most recent config apply, or
+	// nil when there has been none. When nil, the field is omitted from the
+	// status response.
+	LastConfigApply func() *ConfigApplyStatus
+
+	// EventQueueStats returns the number of events waiting for a turn and the
+	// queue capacity. When nil, the fields are omitted from the status response.
+	EventQueueStats func() (depth, capacity int)
 
 	// MessagesOutbound returns the total number of successful
 	// matrix.send_message calls since agent startup (R15.5).
 	// When nil, the field is omitted from the status response.
 	MessagesOutbound func() int64
+
+	// Metrics returns the agent counters exposed by GET /metrics. When nil
+	// the endpoint still reports uptime, outbound messages and event
+	// rate-limit rejections, with the other counters at zero.
+	Metrics func() Metrics
+
+	// ListTools returns the tools currently offered to the LLM, with their
+	// input schemas when withSchemas is set.
+	// When nil, GET /tools returns 503 Service Unavailable.
+	ListTools func(ctx context.Context, withSchemas bool) (ToolListResponse, error)
+
+	// ListToolCalls returns the most recent tool-call audit entries, at
+	// most limit of them (0 means the handler's default).
+	// When nil, GET /calls returns 503 Service Unavailable.
+	ListToolCalls func(limit int) (ToolCallListResponse, error)
+
+	// ListTurns returns summaries of the most recent turns, at most limit
+	// of them (0 means the handler's default).
+	// When nil, GET /turns returns 503 Service Unavailable.
+	ListTurns func(limit int) (TurnListResponse, error)
+
+	// Broadcast posts an operator notice to every room the agent is in.
+	// When nil, POST /broadcast returns 503 Service Unavailable.
+	Broadcast func(ctx context.Context, message string) (BroadcastResponse, error)
+
+	// ListDeadLetters returns the failed-event dead-letter queue.
+	// When nil, GET /dlq returns 503 Service Unavailable.
+	ListDeadLetters func() (DeadLetterListResponse, error)
+
+	// RetryDeadLetter re-dispatches a dead-lettered event. It returns
+	// ErrDeadLetterNotFound or ErrDeadLetterRetryLimit for the corresponding
+	// client errors. When nil, POST /dlq/retry returns 503.
+	RetryDeadLetter func(id int64) error
+
+	// TurnTranscript returns the recorded LLM message history of the turn
+	// with the given trace ID, or ErrTurnTranscriptNotFound.
+	// When nil, GET /turns/{trace}/transcript returns 503.
+	TurnTranscript func(traceID string) (TurnTranscriptResponse, error)
 }
 
+// ErrEventQueueFull is returned by Handlers.HandleEvent when the agent's
+// event queue has no room for another event.
+var ErrEventQueueFull = errors.New("event queue full")
+
+// Errors returned by Handlers.RetryDeadLetter.
+var (
+	ErrDeadLetterNotFound   = errors.New("dead letter not found")
+	ErrDeadLetterRetryLimit = errors.New("dead letter retry limit reached")
+)
+
+// ErrTurnTranscriptNotFound is returned by Handlers.TurnTranscript when no
+// transcript is stored for the requested trace.
+var ErrTurnTranscriptNotFound = errors.New("turn transcript not found")
+
 // Server is the ACP HTTP server.
 type Server struct {
 	addr         string
@@ -230,6 +516,60 @@ type Server struct {
 	idemCache    *idempotencyCache
 	httpClient   *http.Client // used by handleSecretsToken to call Kuze
 	eventLimiter *eventRateLimiter
+	tokens       *TokenSet
+	// maxRouteTimeout is the longest per-route timeout, used to size the
+	// http.Server read/write deadlines.
+	maxRouteTimeout time.Duration
+
+	addrMu    sync.Mutex
+	boundAddr net.Addr // set by Start
+}
+
+// defaultRouteTimeout applies to routes without an entry in
+// DefaultRouteTimeouts.
+const defaultRouteTimeout = 30 * time.Second
+
+// serverTimeoutSlack is added to the longest route timeout to derive the
+// http.Server read/write timeouts, so the per-route 503 is written before
+// the connection-level deadline closes the connection.
+const serverTimeoutSlack = 5 * time.Second
+
+// DefaultRouteTimeouts returns the built-in per-route timeouts. Probes are
+// short so a wedged agent is noticed quickly; event ingress is longer to
+// allow large webhook bodies. Routes not listed use 30s.
+func DefaultRouteTimeouts() map[string]time.Duration {
+	return map[string]time.Duration{
+		"/health":                5 * time.Second,
+		"/status":                5 * time.Second,
+		"/metrics":               5 * time.Second,
+		"/config":                5 * time.Second,
+		"/events/{source}":       60 * time.Second,
+		"/events/{source}/batch": 60 * time.Second,
+	}
+}
+
+// routeTimeout returns the effective timeout for pattern.
+func (s *Server) routeTimeout(pattern string) time.Duration {
+	if d, ok := s.handlers.RouteTimeouts[pattern]; ok {
+		return d
+	}
+	if d, ok := DefaultRouteTimeouts()[pattern]; ok {
+		return d
+	}
+	return defaultRouteTimeout
+}
+
+// withTimeout wraps h in an http.TimeoutHandler using the route's timeout
+// and records it so New can size the server-wide deadlines.
+func (s *Server) withTimeout(pattern string, h http.HandlerFunc) http.Handler {
+	d := s.routeTimeout(pattern)
+	if d <= 0 {
+		return h
+	}
+	if d > s.maxRouteTimeout {
+		s.maxRouteTimeout = d
+	}
+	return jsonErrorHeader(http.TimeoutHandler(h, d, `{"error":"request timed out","code":"`+ErrorCodeTimeout+`"}`))
 }
 
 // New creates a new ACP Server listening on addr.
@@ -237,22 +577,49 @@ func New(addr string, h Handlers) *Server {
 	s := &Server{
 		addr:         addr,
 		handlers:     h,
-		idemCache:    newIdempotencyCache(),
+		idemCache:    newIdempotencyCache(h.IdempotencyMaxEntries),
 		httpClient:   &http.Client{Timeout: 15 * time.Second},
-		eventLimiter: newEventRateLimiter(),
+		eventLimiter: newEventRateLimiter(h.EventRateAlgorithm),
+		tokens:       h.Tokens,
+	}
+	if s.tokens == nil {
+		s.tokens = NewTokenSet(h.Token)
+	}
+	s.idemCache.save = h.SaveIdempotency
+	if h.LoadIdempotency != nil {
+		if records, err := h.LoadIdempotency(); err != nil {
+			slog.Warn("ACP: failed to load persisted idempotency entries", "err", err)
+		} else {
+			s.idemCache.load(records)
+		}
 	}
 
 	// innerMux: ACP management endpoints — all protected by auth middleware.
 	innerMux := http.NewServeMux()
-	innerMux.HandleFunc("/health", s.handleHealth)
-	innerMux.HandleFunc("/status", s.handleStatus)
-	innerMux.HandleFunc("/config/apply", s.handleConfigApply)
-	innerMux.HandleFunc("/secrets/apply", s.handleSecretsApply)
-	innerMux.HandleFunc("/secrets/token", s.handleSecretsToken)
-	innerMux.HandleFunc("/process/restart", s.handleRestart)
-	innerMux.HandleFunc("/tasks/cancel", s.handleCancel)
-	innerMux.HandleFunc("/approvals/decision", s.handleApprovalDecision)
-	innerMux.HandleFunc("/tools/call", s.handleToolCall)
+	innerMux.Handle("/health", s.withTimeout("/health", s.handleHealth))
+	innerMux.Handle("/status", s.withTimeout("/status", s.handleStatus))
+	innerMux.Handle("/metrics", s.withTimeout("/metrics", s.handleMetrics))
+	innerMux.Handle("/config", s.withTimeout("/config", s.handleConfig))
+	innerMux.Handle("/config/apply", s.withTimeout("/config/apply", s.handleConfigApply))
+	innerMux.Handle("/config/canary", s.withTimeout("/config/canary", s.handleConfigCanary))
+	innerMux.Handle("/config/canary/abort", s.withTimeout("/config/canary/abort", s.handleConfigCanaryAbort))
+	innerMux.Handle("/secrets/apply", s.withTimeout("/secrets/apply", s.handleSecretsApply))
+	innerMux.Handle("/secrets/token", s.withTimeout("/secrets/token", s.handleSecretsToken))
+	innerMux.Handle("/process/restart", s.withTimeout("/process/restart", s.handleRestart))
+	innerMux.Handle("/tasks/cancel", s.withTimeout("/tasks/cancel", s.handleCancel))
+	innerMux.Handle("/approvals/decision", s.withTimeout("/approvals/decision", s.handleApprovalDecision))
+	innerMux.Handle("/tools", s.withTimeout("/tools", s.handleTools))
+	innerMux.Handle("/tools/call", s.withTimeout("/tools/call", s.handleToolCall))
+	innerMux.Handle("/calls", s.withTimeout("/calls", s.handleToolCalls))
+	innerMux.Handle("/turns", s.withTimeout("/turns", s.handleTurns))
+	innerMux.Handle("/turns/{trace}/transcript", s.withTimeout("/turns/{trace}/transcript", s.handleTurnTranscript))
+	innerMux.Handle("/token/rotate", s.withTimeout("/token/rotate", s.handleTokenRotate))
+	innerMux.Handle("/broadcast", s.withTimeout("/broadcast", s.handleBroadcast))
+	innerMux.Handle("/dlq", s.withTimeout("/dlq", s.handleDeadLetters))
+	innerMux.Handle("/dlq/retry", s.withTimeout("/dlq/retry", s.handleDeadLetterRetry))
+	innerMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no such endpoint: "+r.URL.Path)
+	})
 
 	// outerMux: event ingress lives here with its own per-handler auth
 	// (built-in gateways on localhost bypass bearer-token auth; external
@@ -262,34 +629,52 @@ func New(addr string, h Handlers) *Server {
 	// Note: /events/{source} is registered without a method prefix so that
 	// wrong-method requests reach the handler and receive a proper 405 rather
 	// than falling through to the catch-all and getting a 404.
+	//
+	// Gzip-encoded request bodies are inflated before the handlers run, capped
+	// at the route's body limit after decompression. Every route checks the
+	// token first (the event routes through inflateEventBody) so anonymous
+	// callers cannot make the agent decompress anything.
 	outerMux := http.NewServeMux()
-	outerMux.HandleFunc("/events/{source}", s.handleEventIngress)
-	outerMux.Handle("/", s.authMiddleware(innerMux))
+	outerMux.Handle("/events/{source}", s.inflateEventBody(maxEventBodyBytes,
+		s.withTimeout("/events/{source}", s.handleEventIngress)))
+	outerMux.Handle("/events/{source}/batch", s.inflateEventBody(maxEventBatchBytes,
+		s.withTimeout("/events/{source}/batch", s.handleEventBatch)))
+	outerMux.Handle("/", s.authMiddleware(decompressBody(maxEventBodyBytes, innerMux)))
 
+	serverTimeout := defaultRouteTimeout
+	if s.maxRouteTimeout > serverTimeout {
+		serverTimeout = s.maxRouteTimeout
+	}
 	s.server = &http.Server{
 		Addr:         addr,
-		Handler:      outerMux,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		Handler:      traceMiddleware(compressResponse(outerMux)),
+		ReadTimeout:  serverTimeout + serverTimeoutSlack,
+		WriteTimeout: serverTimeout + serverTimeoutSlack,
 	}
 	return s
 }
 
+// traceMiddleware adopts the caller's trace ID header, or generates one when
+// it is missing or malformed, echoes it on the response and stores it in the
+// request context so handlers and queued event turns log under the same
+// trace as the caller.
+func traceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(trace.Header)
+		if !trace.ValidID(id) {
+			id = trace.GenerateID()
+		}
+		w.Header().Set(trace.Header, id)
+		next.ServeHTTP(w, r.WithContext(trace.WithTraceID(r.Context(), id)))
+	})
+}
+
 // authMiddleware rejects requests that do not carry the correct bearer token.
 // When Handlers.Token is empty, all requests are allowed (dev/test mode).
 func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if s.handlers.Token == "" {
-			next.ServeHTTP(w, r)
-			return
-		}
-		auth := r.Header.Get("Authorization")
-		if !strings.HasPrefix(auth, "Bearer ") {
-			writeError(w, http.StatusUnauthorized, "missing bearer token")
-			return
-		}
-		if auth[len("Bearer "):] != s.handlers.Token {
-			writeError(w, http.StatusUnauthorized, "invalid bearer token")
+		if msg := s.bearerError(r); msg != "" {
+			writeError(w, http.StatusUnauthorized, msg)
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -299,11 +684,21 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 // Start begins listening. It returns once the listener is bound so callers
 // can immediately start sending requests.
 func (s *Server) Start(ctx context.Context) error {
+	tlsCfg, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
 	ln, err := net.Listen("tcp", s.addr)
 	if err != nil {
 		return fmt.Errorf("acp listen %s: %w", s.addr, err)
 	}
-	slog.Info("ACP server listening", "addr", ln.Addr().String())
+	s.addrMu.Lock()
+	s.boundAddr = ln.Addr()
+	s.addrMu.Unlock()
+	if tlsCfg != nil {
+		ln = tls.NewListener(ln, tlsCfg)
+	}
+	slog.Info("ACP server listening", "addr", ln.Addr().String(), "tls", tlsCfg != nil)
 	go func() {
 		if err := s.server.Serve(ln); err != nil && err != http.ErrServerClosed {
 			slog.Error("ACP server error", "err", err)
@@ -313,9 +708,40 @@ func (s *Server) Start(ctx context.Context) error {
 		<-ctx.Done()
 		s.server.Shutdown(context.Background())
 	}()
+	go s.idemCache.runSweeper(ctx, idempotencySweepInterval)
 	return nil
 }
 
+// tlsConfig loads the server certificate when TLS is configured. It returns
+// nil for plaintext operation.
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	certFile, keyFile := s.handlers.TLSCertFile, s.handlers.TLSKeyFile
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("acp tls: both certificate and key files are required")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("acp tls: load key pair: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// Addr returns the address the server is bound to, or "" before Start.
+func (s *Server) Addr() string {
+	s.addrMu.Lock()
+	defer s.addrMu.Unlock()
+	if s.boundAddr == nil {
+		return ""
+	}
+	return s.boundAddr.String()
+}
+
 // Stop gracefully shuts down the server.
 func (s *Server) Stop() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -327,7 +753,7 @@ func (s *Server) Stop() {
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 	writeJSON(w, http.StatusOK, HealthResponse{
@@ -338,7 +764,7 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 	uptime := time.Since(s.handlers.StartedAt).Seconds()
@@ -350,24 +776,88 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if s.handlers.MCPNames != nil {
 		mcps = s.handlers.MCPNames()
 	}
+	var mcpsInit []string
+	if s.handlers.MCPsInitializing != nil {
+		mcpsInit = s.handlers.MCPsInitializing()
+	}
+	var mcpStatus []MCPServerStatus
+	if s.handlers.MCPStatus != nil {
+		mcpStatus = s.handlers.MCPStatus()
+	}
 	var msgsOut int64
 	if s.handlers.MessagesOutbound != nil {
 		msgsOut = s.handlers.MessagesOutbound()
 	}
+	var queueDepth, queueCap int
+	if s.handlers.EventQueueStats != nil {
+		queueDepth, queueCap = s.handlers.EventQueueStats()
+	}
+	var startupFailures []string
+	if s.handlers.StartupFailures != nil {
+		startupFailures = s.handlers.StartupFailures()
+	}
+	var lastApply *ConfigApplyStatus
+	if s.handlers.LastConfigApply != nil {
+		lastApply = s.handlers.LastConfigApply()
+	}
+	var canary *CanaryStatus
+	if s.handlers.Canary != nil {
+		canary = s.handlers.Canary()
+	}
+	var spend *MonthlySpend
+	if s.handlers.MonthlySpend != nil {
+		spend = s.handlers.MonthlySpend()
+	}
+	var firstStarted *time.Time
+	if !s.handlers.FirstStartedAt.IsZero() {
+		t := s.handlers.FirstStartedAt
+		firstStarted = &t
+	}
 	writeJSON(w, http.StatusOK, StatusResponse{
-		AgentID:          s.handlers.AgentID,
-		Version:          s.handlers.Version,
-		GosutoHash:       hash,
-		Uptime:           uptime,
-		StartedAt:        s.handlers.StartedAt,
-		MCPs:             mcps,
-		MessagesOutbound: msgsOut,
+		AgentID:            s.handlers.AgentID,
+		Version:            s.handlers.Version,
+		GosutoHash:         hash,
+		Uptime:             uptime,
+		StartedAt:          s.handlers.StartedAt,
+		Restarts:           s.handlers.Restarts,
+		FirstStartedAt:     firstStarted,
+		MCPs:               mcps,
+		MCPsInitializing:   mcpsInit,
+		MCPStatus:          mcpStatus,
+		MessagesOutbound:   msgsOut,
+		EventQueueDepth:    queueDepth,
+		EventQueueCapacity: queueCap,
+		StartupFailures:    startupFailures,
+		LastConfigApply:    lastApply,
+		Canary:             canary,
+		Spend:              spend,
 	})
 }
 
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.ConfigYAML == nil {
+		writeError(w, http.StatusServiceUnavailable, "config read not available")
+		return
+	}
+	raw := s.handlers.ConfigYAML()
+	if raw == "" {
+		writeError(w, http.StatusNotFound, "no config loaded")
+		return
+	}
+	hash := ""
+	if s.handlers.GosutoHash != nil {
+		hash = s.handlers.GosutoHash()
+	}
+	writeJSON(w, http.StatusOK, ConfigResponse{YAML: raw, Hash: hash})
+}
+
 func (s *Server) handleConfigApply(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -389,6 +879,11 @@ func (s *Server) handleConfigApply(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusServiceUnavailable, "config apply not available")
 		return
 	}
+	if err := verifyConfigHash(req.YAML, req.Hash); err != nil {
+		slog.Error("ACP: config apply rejected", "err", err)
+		writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeHashMismatch, err.Error())
+		return
+	}
 	if err := s.handlers.ApplyConfig(req.YAML, req.Hash); err != nil {
 		slog.Error("ACP: config apply failed", "err", err)
 		writeError(w, http.StatusUnprocessableEntity, err.Error())
@@ -402,9 +897,69 @@ func (s *Server) handleConfigApply(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// verifyConfigHash checks that hash is the hex SHA-256 of the pushed YAML,
+// either as sent or in canonical Gosuto form, so a push corrupted in transit
+// (or paired with the wrong hash) is refused instead of applied and then
+// reported under a hash it does not have.
+func verifyConfigHash(yaml, hash string) error {
+	if hash == "" {
+		return fmt.Errorf("config hash is required")
+	}
+	sum := sha256.Sum256([]byte(yaml))
+	if hex.EncodeToString(sum[:]) == hash {
+		return nil
+	}
+	if canonical, err := gosutospec.Canonicalize([]byte(yaml)); err == nil {
+		sum = sha256.Sum256(canonical)
+		if hex.EncodeToString(sum[:]) == hash {
+			return nil
+		}
+	}
+	return fmt.Errorf("config hash mismatch: payload does not hash to %s", hash[:min(12, len(hash))])
+}
+
+func (s *Server) handleConfigCanary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req ConfigCanaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	if s.handlers.ApplyCanary == nil {
+		writeError(w, http.StatusServiceUnavailable, "canary config not available")
+		return
+	}
+	if err := s.handlers.ApplyCanary(req.YAML, req.Hash, req.Room); err != nil {
+		slog.Error("ACP: canary config rejected", "err", err)
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	slog.Info("ACP: canary config applied", "hash", req.Hash[:min(12, len(req.Hash))], "room", req.Room)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleConfigCanaryAbort(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.AbortCanary == nil {
+		writeError(w, http.StatusServiceUnavailable, "canary config not available")
+		return
+	}
+	if !s.handlers.AbortCanary() {
+		writeError(w, http.StatusNotFound, "no canary config is active")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func (s *Server) handleSecretsApply(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -435,7 +990,7 @@ func (s *Server) handleSecretsApply(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusServiceUnavailable, "secrets apply not available")
 		return
 	}
-	if err := s.handlers.ApplySecrets(req.Secrets); err != nil {
+	if err := s.handlers.ApplySecrets(req.Secrets, nil); err != nil {
 		slog.Error("ACP: secrets apply failed", "err", err)
 		writeError(w, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -455,13 +1010,17 @@ func (s *Server) handleSecretsApply(w http.ResponseWriter, r *http.Request) {
 // identity via X-Agent-ID. The decoded values are passed to ApplySecrets so
 // the rest of the runtime sees no change; only the delivery path differs.
 //
+// The response lists the applied refs and, with 207 Multi-Status, any refs
+// that failed to redeem and why, so Ruriko can re-issue just those. Only the
+// redeemed values are applied; when every lease fails the request is a 502.
+//
 // Security properties:
 //   - Raw secret values never appear in the ACP request body.
 //   - Each token is single-use and short-lived (AgentTTL ≈ 60 s).
 //   - The agent identity is verified by Kuze on each redemption.
 func (s *Server) handleSecretsToken(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -484,6 +1043,14 @@ func (s *Server) handleSecretsToken(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
+	maxLeases := s.handlers.MaxSecretLeases
+	if maxLeases <= 0 {
+		maxLeases = DefaultMaxSecretLeases
+	}
+	if len(req.Leases) > maxLeases {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("too many leases: %d (max %d)", len(req.Leases), maxLeases))
+		return
+	}
 
 	if s.handlers.ApplySecrets == nil {
 		writeError(w, http.StatusServiceUnavailable, "secrets apply not available")
@@ -492,57 +1059,112 @@ func (s *Server) handleSecretsToken(w http.ResponseWriter, r *http.Request) {
 
 	// Redeem each Kuze token to fetch the plaintext secret value.
 	redeemed := make(map[string]string, len(req.Leases))
-	var failedRefs []string
+	ttls := make(map[string]time.Duration)
+	resp := SecretsTokenResponse{Applied: []string{}}
 
-	for _, lease := range req.Leases {
-		val, err := s.redeemLease(r.Context(), lease)
-		if err != nil {
+	for i, res := range s.redeemLeases(r.Context(), req.Leases) {
+		lease := req.Leases[i]
+		if res.err != nil {
 			slog.Warn("ACP: failed to redeem secret lease",
-				"ref", lease.SecretRef, "err", err)
-			failedRefs = append(failedRefs, lease.SecretRef)
+				"ref", lease.SecretRef, "err", res.err)
+			resp.Failed = append(resp.Failed, SecretLeaseFailure{SecretRef: lease.SecretRef, Error: res.err.Error()})
 			continue
 		}
-		redeemed[lease.SecretRef] = val
+		redeemed[lease.SecretRef] = res.value
+		resp.Applied = append(resp.Applied, lease.SecretRef)
+		if lease.TTLSeconds > 0 {
+			ttls[lease.SecretRef] = time.Duration(lease.TTLSeconds) * time.Second
+		}
 	}
 
 	if len(redeemed) == 0 {
+		failedRefs := make([]string, 0, len(resp.Failed))
+		for _, f := range resp.Failed {
+			failedRefs = append(failedRefs, f.SecretRef)
+		}
 		slog.Error("ACP: all secret token redemptions failed", "refs", failedRefs)
 		writeError(w, http.StatusBadGateway, fmt.Sprintf("all %d secret redemption(s) failed", len(req.Leases)))
 		return
 	}
 
-	if err := s.handlers.ApplySecrets(redeemed); err != nil {
+	if err := s.handlers.ApplySecrets(redeemed, ttls); err != nil {
 		slog.Error("ACP: secrets token apply failed", "err", err)
 		writeError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
 
 	slog.Info("ACP: secrets applied via Kuze token redemption",
-		"applied", len(redeemed), "failed", len(failedRefs))
+		"applied", len(resp.Applied), "failed", len(resp.Failed))
 
+	// A partial outcome is still a success for the applied refs; 207 tells
+	// the caller to look at the failed list.
+	status := http.StatusOK
+	if len(resp.Failed) > 0 {
+		status = http.StatusMultiStatus
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
 	if key := r.Header.Get("X-Idempotency-Key"); key != "" {
-		s.idemCache.set(key, http.StatusOK, nil)
+		s.idemCache.set(key, status, body)
 	}
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// redeemResult is the outcome of redeeming one lease.
+type redeemResult struct {
+	value string
+	err   error
+}
+
+// redeemLeases redeems leases with at most MaxConcurrentRedemptions calls to
+// Kuze in flight and returns the results in lease order.
+func (s *Server) redeemLeases(ctx context.Context, leases []SecretLease) []redeemResult {
+	results := make([]redeemResult, len(leases))
+	sem := make(chan struct{}, MaxConcurrentRedemptions)
+	var wg sync.WaitGroup
+	for i, lease := range leases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			val, err := s.redeemLease(ctx, lease)
+			results[i] = redeemResult{value: val, err: err}
+		}()
+	}
+	wg.Wait()
+	return results
 }
 
 // redeemLease calls the Kuze redemption URL for a single lease, presenting
 // the agent's identity via X-Agent-ID. Returns the base64-encoded secret
-// value on success (ready to pass directly into ApplySecrets).
+// value on success (ready to pass directly into ApplySecrets). The URL
+// carries a one-time token, so errors name the secret ref and only ever
+// include the URL with the token redacted.
 func (s *Server) redeemLease(ctx context.Context, lease SecretLease) (string, error) {
 	if lease.KuzeURL == "" {
 		return "", fmt.Errorf("empty kuze_url for secret %q", lease.SecretRef)
 	}
+	safeURL := redact.URL(lease.KuzeURL)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lease.KuzeURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("build redeem request: %w", err)
+		return "", fmt.Errorf("build redeem request for %q (%s): invalid kuze_url", lease.SecretRef, safeURL)
 	}
 	req.Header.Set("X-Agent-ID", s.handlers.AgentID)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("kuze GET %q: %w", lease.KuzeURL, err)
+		// *url.Error embeds the full URL; keep only the underlying cause.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("kuze GET %s for %q: %w", safeURL, lease.SecretRef, err)
 	}
 	defer resp.Body.Close()
 
@@ -572,7 +1194,7 @@ func (s *Server) redeemLease(ctx context.Context, lease SecretLease) (string, er
 
 func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -599,7 +1221,7 @@ func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -628,7 +1250,7 @@ func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleApprovalDecision(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 	if s.handlers.RecordApprovalDecision == nil {
@@ -662,7 +1284,7 @@ func (s *Server) handleApprovalDecision(w http.ResponseWriter, r *http.Request)
 
 func (s *Server) handleToolCall(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -718,6 +1340,233 @@ func (s *Server) handleToolCall(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(body)
 }
 
+// handleTools handles GET /tools. Input schemas are included only when the
+// schemas query parameter is "true" or "1", since they can be large.
+func (s *Server) handleTools(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.ListTools == nil {
+		writeError(w, http.StatusServiceUnavailable, "tool listing not available")
+		return
+	}
+	withSchemas := false
+	switch r.URL.Query().Get("schemas") {
+	case "true", "1":
+		withSchemas = true
+	}
+	resp, err := s.handlers.ListTools(r.Context(), withSchemas)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleToolCalls handles GET /calls.
+func (s *Server) handleToolCalls(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.ListToolCalls == nil {
+		writeError(w, http.StatusServiceUnavailable, "tool-call audit trail not available")
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	resp, err := s.handlers.ListToolCalls(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleTurns handles GET /turns.
+func (s *Server) handleTurns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.ListTurns == nil {
+		writeError(w, http.StatusServiceUnavailable, "turn history not available")
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	resp, err := s.handlers.ListTurns(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleTurnTranscript handles GET /turns/{trace}/transcript.
+func (s *Server) handleTurnTranscript(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.TurnTranscript == nil {
+		writeError(w, http.StatusServiceUnavailable, "turn transcripts not available")
+		return
+	}
+	resp, err := s.handlers.TurnTranscript(r.PathValue("trace"))
+	switch {
+	case errors.Is(err, ErrTurnTranscriptNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// handleTokenRotate handles POST /token/rotate. The new token becomes
+// current at once; the old one keeps authenticating until the overlap
+// window ends, so requests already using it are not dropped.
+func (s *Server) handleTokenRotate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.tokens.Required() {
+		writeError(w, http.StatusConflict, "ACP authentication is disabled; nothing to rotate")
+		return
+	}
+	var req TokenRotateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	if len(req.Token) < minTokenLen {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("token must be at least %d characters", minTokenLen))
+		return
+	}
+	if req.Token == s.tokens.Current() {
+		writeError(w, http.StatusBadRequest, "token is already current")
+		return
+	}
+	overlap := time.Duration(req.OverlapSeconds) * time.Second
+	switch {
+	case req.OverlapSeconds < 0:
+		writeError(w, http.StatusBadRequest, "overlap_seconds must not be negative")
+		return
+	case overlap == 0:
+		overlap = DefaultTokenOverlap
+	case overlap > MaxTokenOverlap:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("overlap_seconds must not exceed %d", int(MaxTokenOverlap.Seconds())))
+		return
+	}
+	if s.handlers.PersistToken != nil {
+		if err := s.handlers.PersistToken(req.Token); err != nil {
+			slog.Error("ACP: token persist failed", "err", err)
+			writeError(w, http.StatusInternalServerError, "failed to persist token: "+err.Error())
+			return
+		}
+	}
+	retiresAt := s.tokens.Rotate(req.Token, overlap)
+	slog.Info("ACP: bearer token rotated", "previous_retires_at", retiresAt.UTC().Format(time.RFC3339))
+	writeJSON(w, http.StatusOK, TokenRotateResponse{RetiresAt: retiresAt})
+}
+
+// maxBroadcastLen caps the notice text accepted by POST /broadcast.
+const maxBroadcastLen = 4000
+
+// handleBroadcast handles POST /broadcast.
+func (s *Server) handleBroadcast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.Broadcast == nil {
+		writeError(w, http.StatusServiceUnavailable, "broadcast not available")
+		return
+	}
+	var req BroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeError(w, http.StatusBadRequest, "message is required")
+		return
+	}
+	if len(req.Message) > maxBroadcastLen {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("message exceeds %d bytes", maxBroadcastLen))
+		return
+	}
+	resp, err := s.handlers.Broadcast(r.Context(), req.Message)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleDeadLetters handles GET /dlq.
+func (s *Server) handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.ListDeadLetters == nil {
+		writeError(w, http.StatusServiceUnavailable, "dead-letter queue not available")
+		return
+	}
+	resp, err := s.handlers.ListDeadLetters()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleDeadLetterRetry handles POST /dlq/retry. The event is re-dispatched
+// asynchronously; 202 means the retry was started, not that it succeeded.
+func (s *Server) handleDeadLetterRetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.handlers.RetryDeadLetter == nil {
+		writeError(w, http.StatusServiceUnavailable, "dead-letter queue not available")
+		return
+	}
+	var req DeadLetterRetryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	switch err := s.handlers.RetryDeadLetter(req.ID); {
+	case errors.Is(err, ErrDeadLetterNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrDeadLetterRetryLimit):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
 // handleEventIngress handles POST /events/{source} (R12.1 + R12.4).
 //
 // It dispatches to one of two sub-handlers based on the gateway type
@@ -732,49 +1581,28 @@ func (s *Server) handleToolCall(w http.ResponseWriter, r *http.Request) {
 //     HMAC-SHA256 (X-Hub-Signature-256 header).  The raw body is wrapped in
 //     an Event envelope by handleWebhookEvent.
 //
+// Requests other than POST to a known source get 405 with an Allow header.
+//
 // Auth rules apply before body parsing:
 //   - Built-in gateways (cron etc.) run within the same host and connect from
-//     127.0.0.1 / ::1. Localhost connections bypass bearer-token auth.
+//     127.0.0.1 / ::1. Localhost connections bypass bearer-token auth unless
+//     Handlers.DisableLocalhostBypass is set.
 //   - External gateway processes must supply the ACP bearer token.
 //   - Webhook HMAC auth bypasses the bearer check and validates the signature
 //     over the raw body instead.
 //   - When Handlers.Token is empty (dev/test) all connections are accepted.
 func (s *Server) handleEventIngress(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	source := r.PathValue("source")
 	if source == "" {
 		writeError(w, http.StatusBadRequest, "missing event source in path")
 		return
 	}
 
-	// Validate source against the active Gosuto gateway list and read rate
-	// limit from config. When ActiveConfig is nil (dev mode) we skip name
-	// validation and apply no rate limit. Capture the gateway config so that
-	// we can detect webhook-type gateways and read their auth settings.
-	var maxEventsPerMinute int
-	var foundGW *gosutospec.Gateway
-	if s.handlers.ActiveConfig != nil {
-		cfg := s.handlers.ActiveConfig()
-		if cfg != nil {
-			for i := range cfg.Gateways {
-				if cfg.Gateways[i].Name == source {
-					gw := cfg.Gateways[i] // copy to avoid loop-var alias
-					foundGW = &gw
-					break
-				}
-			}
-			if foundGW == nil {
-				slog.Warn("event dropped", "source", source, "reason", "unknown_source")
-				writeError(w, http.StatusNotFound,
-					fmt.Sprintf("unknown gateway source %q", source))
-				return
-			}
-			maxEventsPerMinute = cfg.Limits.MaxEventsPerMinute
-		}
+	// Capture the gateway config so that we can detect webhook-type gateways
+	// and read their auth settings.
+	foundGW, maxEventsPerMinute, ok := s.eventSource(w, source)
+	if !ok {
+		return
 	}
 
 	// Route webhook gateways to the dedicated sub-handler which performs
@@ -786,15 +1614,16 @@ func (s *Server) handleEventIngress(w http.ResponseWriter, r *http.Request) {
 
 	// ── Non-webhook path (cron, external gateway processes) ──────────────────
 
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
 	// Auth: localhost (built-in gateways) bypasses bearer-token check.
-	if s.handlers.Token != "" && !isLocalhost(r) {
-		auth := r.Header.Get("Authorization")
-		if !strings.HasPrefix(auth, "Bearer ") {
-			writeError(w, http.StatusUnauthorized, "missing bearer token")
-			return
-		}
-		if auth[len("Bearer "):] != s.handlers.Token {
-			writeError(w, http.StatusUnauthorized, "invalid bearer token")
+	if !s.localhostBypass(r) {
+		if msg := s.bearerError(r); msg != "" {
+			writeError(w, http.StatusUnauthorized, msg)
 			return
 		}
 	}
@@ -823,10 +1652,10 @@ func (s *Server) handleEventIngress(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Rate limiting: token-bucket per source + global (maxEventsPerMinute).
-	if !s.eventLimiter.allow(source, maxEventsPerMinute) {
-		slog.Warn("event dropped", "source", source, "reason", "rate_limit", "limit", maxEventsPerMinute)
+	if limit, ok := s.eventLimiter.allow(source, maxEventsPerMinute, gatewayEventLimit(foundGW)); !ok {
+		slog.Warn("event dropped", "source", source, "reason", "rate_limit", "limit", limit)
 		writeError(w, http.StatusTooManyRequests,
-			fmt.Sprintf("rate limit exceeded for gateway %q (%d events/min)", source, maxEventsPerMinute))
+			fmt.Sprintf("rate limit exceeded for gateway %q (%d events/min)", source, limit))
 		return
 	}
 
@@ -835,19 +1664,164 @@ func (s *Server) handleEventIngress(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusServiceUnavailable, "event handling not available")
 		return
 	}
-	s.handlers.HandleEvent(r.Context(), &evt)
+	if !s.dispatchEvent(w, r, &evt) {
+		return
+	}
 	// "event received" — source, type, timestamp (payload content never logged at INFO).
-	slog.Info("event received", "source", source, "type", evt.Type, "ts", evt.TS)
+	slog.Info("event received", "source", source, "type", evt.Type, "ts", evt.TS,
+		"trace_id", trace.FromContext(r.Context()))
 	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
 }
 
+// eventSource validates source against the active Gosuto gateway list and
+// returns its gateway and the global events-per-minute limit. When
+// ActiveConfig is nil (dev mode) name validation is skipped, and the gateway
+// is nil with no rate limit. An unknown source is answered with 404 and ok is
+// false.
+func (s *Server) eventSource(w http.ResponseWriter, source string) (gw *gosutospec.Gateway, maxEventsPerMinute int, ok bool) {
+	if s.handlers.ActiveConfig == nil {
+		return nil, 0, true
+	}
+	cfg := s.handlers.ActiveConfig()
+	if cfg == nil {
+		return nil, 0, true
+	}
+	for i := range cfg.Gateways {
+		if cfg.Gateways[i].Name == source {
+			found := cfg.Gateways[i] // copy to avoid loop-var alias
+			gw = &found
+			break
+		}
+	}
+	if gw == nil {
+		slog.Warn("event dropped", "source", source, "reason", "unknown_source")
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown gateway source %q", source))
+		return nil, 0, false
+	}
+	return gw, s.eventLimit(cfg.Limits), true
+}
+
+// handleEventBatch handles POST /events/{source}/batch: a JSON array of
+// event envelopes from one gateway, so a gateway draining a backlog (RSS
+// catch-up, a mailbox) needs one request instead of one per item.
+//
+// Source lookup and auth apply to the request as a whole, as for
+// POST /events/{source}. Each envelope is then validated, rate limited and
+// dispatched on its own, in order, and the response lists a result per
+// envelope; the per-source and global limits are charged once per envelope,
+// so a batch cannot exceed them. The request itself succeeds with 200 even
+// when some or all envelopes are rejected. Webhook gateways do not accept
+// batches.
+func (s *Server) handleEventBatch(w http.ResponseWriter, r *http.Request) {
+	source := r.PathValue("source")
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	gw, maxEventsPerMinute, ok := s.eventSource(w, source)
+	if !ok {
+		return
+	}
+	if gw != nil && gw.Type == "webhook" {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("webhook gateway %q does not accept event batches", source))
+		return
+	}
+	if !s.localhostBypass(r) {
+		if msg := s.bearerError(r); msg != "" {
+			writeError(w, http.StatusUnauthorized, msg)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxEventBatchBytes+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request body: "+err.Error())
+		return
+	}
+	if len(body) > maxEventBatchBytes {
+		writeError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("event batch exceeds %d bytes", maxEventBatchBytes))
+		return
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: expected an array of event envelopes: "+err.Error())
+		return
+	}
+	if len(items) == 0 {
+		writeError(w, http.StatusBadRequest, "event batch is empty")
+		return
+	}
+	if len(items) > maxEventBatchItems {
+		writeError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("event batch has %d envelopes; at most %d are accepted", len(items), maxEventBatchItems))
+		return
+	}
+	if s.handlers.HandleEvent == nil {
+		writeError(w, http.StatusServiceUnavailable, "event handling not available")
+		return
+	}
+
+	resp := EventBatchResponse{Results: make([]EventBatchResult, len(items))}
+	for i, raw := range items {
+		res := s.ingestBatchEvent(r.Context(), source, gw, maxEventsPerMinute, raw)
+		res.Index = i
+		if res.Status == eventBatchQueued {
+			resp.Accepted++
+		} else {
+			resp.Rejected++
+		}
+		resp.Results[i] = res
+	}
+	slog.Info("event batch received", "source", source, "accepted", resp.Accepted, "rejected", resp.Rejected,
+		"trace_id", trace.FromContext(r.Context()))
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ingestBatchEvent validates, rate limits and dispatches one envelope of an
+// event batch, applying the same checks as POST /events/{source}.
+func (s *Server) ingestBatchEvent(ctx context.Context, source string, gw *gosutospec.Gateway, maxEventsPerMinute int, raw json.RawMessage) EventBatchResult {
+	reject := func(code int, msg string) EventBatchResult {
+		return EventBatchResult{Status: eventBatchRejected, Code: code, Error: msg}
+	}
+	var evt envelope.Event
+	if err := json.Unmarshal(raw, &evt); err != nil {
+		return reject(http.StatusBadRequest, "invalid JSON: "+err.Error())
+	}
+	if err := evt.Validate(); err != nil {
+		return reject(http.StatusBadRequest, "invalid event envelope: "+err.Error())
+	}
+	if evt.Source != source {
+		return reject(http.StatusBadRequest,
+			fmt.Sprintf("envelope source %q does not match URL source %q", evt.Source, source))
+	}
+	if limit, ok := s.eventLimiter.allow(source, maxEventsPerMinute, gatewayEventLimit(gw)); !ok {
+		slog.Warn("event dropped", "source", source, "reason", "rate_limit", "limit", limit)
+		return reject(http.StatusTooManyRequests,
+			fmt.Sprintf("rate limit exceeded for gateway %q (%d events/min)", source, limit))
+	}
+	if err := s.handlers.HandleEvent(ctx, &evt); err != nil {
+		if errors.Is(err, ErrEventQueueFull) {
+			slog.Warn("event dropped", "source", source, "reason", "queue_full")
+			return reject(http.StatusServiceUnavailable, "event queue full; retry later")
+		}
+		return reject(http.StatusInternalServerError, err.Error())
+	}
+	slog.Info("event received", "source", source, "type", evt.Type, "ts", evt.TS,
+		"trace_id", trace.FromContext(ctx))
+	return EventBatchResult{Status: eventBatchQueued, Code: http.StatusAccepted}
+}
+
 // handleWebhookEvent handles inbound webhook deliveries for gateways with
 // type: webhook (R12.4).
 //
 // Unlike the standard event ingress which expects a pre-formed Event envelope,
 // this handler accepts a raw HTTP POST body from an external webhook sender
 // (GitHub, Stripe, custom services, etc.) and wraps it in an Event envelope
-// using gateway.WrapRawWebhookBody.
+// using gateway.WrapWebhookDelivery, which derives the event type from the
+// gateway's config.typeFrom when set.
 //
 // Auth:
 //   - authType "bearer" (default): ACP bearer token, localhost-bypass applies.
@@ -855,6 +1829,10 @@ func (s *Server) handleEventIngress(w http.ResponseWriter, r *http.Request) {
 //     against the secret named by config["hmacSecretRef"] in the agent's
 //     secret store.  Bearer auth is deliberately skipped so caller does not
 //     need the ACP token — only the HMAC shared secret.
+//
+// Deliveries that are not POST get 405 with an Allow header. When the
+// gateway sets config["contentType"], a delivery with a different media type
+// gets 415 before authentication runs.
 func (s *Server) handleWebhookEvent(
 	w http.ResponseWriter,
 	r *http.Request,
@@ -862,6 +1840,19 @@ func (s *Server) handleWebhookEvent(
 	gwCfg *gosutospec.Gateway,
 	maxEventsPerMinute int,
 ) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("webhook gateway %q accepts POST only, got %s", source, r.Method))
+		return
+	}
+	if want := gwCfg.Config["contentType"]; want != "" {
+		if msg := webhookContentTypeError(r.Header.Get("Content-Type"), want); msg != "" {
+			writeError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("webhook gateway %q: %s", source, msg))
+			return
+		}
+	}
+
 	// Read the raw body first — HMAC validation must be computed over the
 	// exact bytes received (including whitespace, key ordering, etc.).
 	rawBody, err := io.ReadAll(io.LimitReader(r.Body, maxEventBodyBytes))
@@ -879,14 +1870,9 @@ func (s *Server) handleWebhookEvent(
 	switch authType {
 	case "bearer":
 		// Same localhost-bypass bearer check as non-webhook gateways.
-		if s.handlers.Token != "" && !isLocalhost(r) {
-			auth := r.Header.Get("Authorization")
-			if !strings.HasPrefix(auth, "Bearer ") {
-				writeError(w, http.StatusUnauthorized, "missing bearer token")
-				return
-			}
-			if auth[len("Bearer "):] != s.handlers.Token {
-				writeError(w, http.StatusUnauthorized, "invalid bearer token")
+		if !s.localhostBypass(r) {
+			if msg := s.bearerError(r); msg != "" {
+				writeError(w, http.StatusUnauthorized, msg)
 				return
 			}
 		}
@@ -933,27 +1919,91 @@ func (s *Server) handleWebhookEvent(
 	}
 
 	// Rate limiting.
-	if !s.eventLimiter.allow(source, maxEventsPerMinute) {
-		slog.Warn("event dropped", "source", source, "reason", "rate_limit", "limit", maxEventsPerMinute)
+	if limit, ok := s.eventLimiter.allow(source, maxEventsPerMinute, gatewayEventLimit(gwCfg)); !ok {
+		slog.Warn("event dropped", "source", source, "reason", "rate_limit", "limit", limit)
 		writeError(w, http.StatusTooManyRequests,
-			fmt.Sprintf("rate limit exceeded for gateway %q (%d events/min)", source, maxEventsPerMinute))
+			fmt.Sprintf("rate limit exceeded for gateway %q (%d events/min)", source, limit))
 		return
 	}
 
 	// Wrap the raw body into a normalised Event envelope.
-	evt := gateway.WrapRawWebhookBody(source, rawBody)
+	evt := gateway.WrapWebhookDelivery(source, rawBody, r.Header, gwCfg.Config["typeFrom"])
 
 	// Dispatch to app handler.
 	if s.handlers.HandleEvent == nil {
 		writeError(w, http.StatusServiceUnavailable, "event handling not available")
 		return
 	}
-	s.handlers.HandleEvent(r.Context(), evt)
+	if !s.dispatchEvent(w, r, evt) {
+		return
+	}
 	// "event received" — source, type, timestamp (payload content never logged at INFO).
-	slog.Info("event received", "source", source, "type", evt.Type, "ts", evt.TS)
+	slog.Info("event received", "source", source, "type", evt.Type, "ts", evt.TS,
+		"trace_id", trace.FromContext(r.Context()))
 	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
 }
 
+// webhookContentTypeError compares a delivery's Content-Type header with the
+// media type a webhook gateway expects, ignoring parameters such as charset.
+// It returns "" when they match and a description of the mismatch otherwise.
+func webhookContentTypeError(header, want string) string {
+	if header == "" {
+		return fmt.Sprintf("missing Content-Type; expected %s", want)
+	}
+	got, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return fmt.Sprintf("malformed Content-Type %q; expected %s", header, want)
+	}
+	wantType, _, err := mime.ParseMediaType(want)
+	if err != nil {
+		wantType = strings.ToLower(want)
+	}
+	if got != wantType {
+		return fmt.Sprintf("Content-Type %s is not accepted; expected %s", got, wantType)
+	}
+	return ""
+}
+
+// dispatchEvent hands evt to the app. When the app refuses it, the error
+// response is written and false is returned.
+func (s *Server) dispatchEvent(w http.ResponseWriter, r *http.Request, evt *envelope.Event) bool {
+	err := s.handlers.HandleEvent(r.Context(), evt)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrEventQueueFull):
+		slog.Warn("event dropped", "source", evt.Source, "reason", "queue_full")
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, "event queue full; retry later")
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+	return false
+}
+
+// bearerError checks r's bearer token against the accepted tokens and
+// returns the rejection message, or "" when the request is authenticated
+// or no token is configured.
+func (s *Server) bearerError(r *http.Request) string {
+	if !s.tokens.Required() {
+		return ""
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return "missing bearer token"
+	}
+	if !s.tokens.Valid(auth[len("Bearer "):]) {
+		return "invalid bearer token"
+	}
+	return ""
+}
+
+// localhostBypass reports whether r may skip the event-ingress bearer check
+// because it comes from loopback and the bypass has not been disabled.
+func (s *Server) localhostBypass(r *http.Request) bool {
+	return !s.handlers.DisableLocalhostBypass && isLocalhost(r)
+}
+
 // isLocalhost reports whether the request originates from the loopback
 // interface (127.0.0.1 or ::1). Used to allow built-in gateway processes
 // (which run in-process and connect from localhost) to bypass bearer-token
@@ -974,8 +2024,63 @@ func writeJSON(w http.ResponseWriter, status int, body interface{}) {
 	json.NewEncoder(w).Encode(body)
 }
 
+// writeError writes an ErrorResponse whose code follows status.
 func writeError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, map[string]string{"error": msg})
+	writeErrorCode(w, status, errorCode(status), msg)
+}
+
+// writeErrorCode writes an ErrorResponse with a specific code.
+func writeErrorCode(w http.ResponseWriter, status int, code, msg string) {
+	writeJSON(w, status, ErrorResponse{Error: msg, Code: code})
+}
+
+// errorCode maps an HTTP status to its generic ErrorResponse code.
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return ErrorCodeMethodNotAllowed
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusGone:
+		return ErrorCodeGone
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return ErrorCodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return ErrorCodeUnprocessable
+	case http.StatusTooManyRequests:
+		return ErrorCodeRateLimited
+	case http.StatusBadGateway:
+		return ErrorCodeBadGateway
+	case http.StatusServiceUnavailable:
+		return ErrorCodeUnavailable
+	default:
+		return ErrorCodeInternal
+	}
+}
+
+// jsonErrorHeader marks error responses that next writes without a
+// Content-Type (the http.TimeoutHandler timeout body) as JSON.
+func jsonErrorHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(jsonErrorWriter{w}, r)
+	})
+}
+
+type jsonErrorWriter struct{ http.ResponseWriter }
+
+func (w jsonErrorWriter) WriteHeader(status int) {
+	if status >= 400 && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(status)
 }
 
 // TestHandler exposes the server's HTTP handler for use in httptest.NewServer.
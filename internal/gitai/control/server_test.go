@@ -2,6 +2,7 @@ package control_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
@@ -9,9 +10,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -19,6 +22,7 @@ import (
 	"github.com/bdobrica/Ruriko/common/spec/envelope"
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
 	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/gitai/gosuto"
 )
 
 // --- helpers ---------------------------------------------------------------
@@ -38,7 +42,7 @@ func newTestServer(token string) *control.Server {
 		ApplyConfig: func(yaml, hash string) error {
 			return nil
 		},
-		ApplySecrets: func(secrets map[string]string) error {
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
 			return nil
 		},
 		RequestRestart: func() {},
@@ -124,6 +128,137 @@ func TestAuthMiddleware_NoTokenMeansOpen(t *testing.T) {
 	}
 }
 
+// configHash returns the hash Ruriko sends with a config push: the hex
+// SHA-256 of the YAML.
+func configHash(yaml string) string {
+	sum := sha256.Sum256([]byte(yaml))
+	return hex.EncodeToString(sum[:])
+}
+
+// --- POST /config/apply ---------------------------------------------------
+
+func TestConfigApply_VerifiesHash(t *testing.T) {
+	var applied []string
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		StartedAt: time.Now(),
+		ApplyConfig: func(yaml, hash string) error {
+			applied = append(applied, hash)
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	post := func(req control.ConfigApplyRequest) int {
+		t.Helper()
+		body, _ := json.Marshal(req)
+		resp, err := http.Post(ts.URL+"/config/apply", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST /config/apply: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	const yaml = "apiVersion: gosuto/v1\nmetadata:\n  name: test\n"
+	if code := post(control.ConfigApplyRequest{YAML: yaml, Hash: configHash(yaml)}); code != http.StatusOK {
+		t.Fatalf("matching hash: status %d, want 200", code)
+	}
+	// A payload altered after hashing must be refused.
+	if code := post(control.ConfigApplyRequest{YAML: yaml + "# tampered\n", Hash: configHash(yaml)}); code != http.StatusUnprocessableEntity {
+		t.Errorf("mismatched hash: status %d, want 422", code)
+	}
+	if code := post(control.ConfigApplyRequest{YAML: yaml}); code != http.StatusUnprocessableEntity {
+		t.Errorf("missing hash: status %d, want 422", code)
+	}
+	if len(applied) != 1 {
+		t.Errorf("ApplyConfig called %d times, want only for the matching hash", len(applied))
+	}
+}
+
+// gzipBody returns data gzip-compressed.
+func gzipBody(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestConfigApply_GzipBodyDecoded(t *testing.T) {
+	var gotYAML string
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		StartedAt: time.Now(),
+		ApplyConfig: func(yaml, hash string) error {
+			gotYAML = yaml
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	const yaml = "apiVersion: gosuto/v1\nmetadata:\n  name: test\n"
+	body, _ := json.Marshal(control.ConfigApplyRequest{YAML: yaml, Hash: configHash(yaml)})
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/config/apply", bytes.NewReader(gzipBody(t, body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /config/apply: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if gotYAML != yaml {
+		t.Errorf("applied YAML = %q, want the decompressed config", gotYAML)
+	}
+}
+
+func TestConfigApply_GzipBombRejected(t *testing.T) {
+	called := false
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		StartedAt: time.Now(),
+		ApplyConfig: func(yaml, hash string) error {
+			called = true
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	// 16 MiB of spaces inside a valid JSON document compresses to a few
+	// tens of KiB.
+	yaml := strings.Repeat(" ", 16<<20)
+	body, _ := json.Marshal(control.ConfigApplyRequest{YAML: yaml, Hash: configHash(yaml)})
+	bomb := gzipBody(t, body)
+	if len(bomb) > 1<<20 {
+		t.Fatalf("compressed body is %d bytes; the test needs a small archive", len(bomb))
+	}
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/config/apply", bytes.NewReader(bomb))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /config/apply: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", resp.StatusCode)
+	}
+	if called {
+		t.Error("ApplyConfig must not run for a body that inflates past the limit")
+	}
+}
+
 // --- Idempotency tests (R2.2) ---------------------------------------------
 
 func TestIdempotency_DuplicateConfigApply(t *testing.T) {
@@ -142,7 +277,7 @@ func TestIdempotency_DuplicateConfigApply(t *testing.T) {
 
 	body, _ := json.Marshal(control.ConfigApplyRequest{
 		YAML: "metadata:\n  name: test",
-		Hash: "abcdef1234567890",
+		Hash: configHash("metadata:\n  name: test"),
 	})
 	key := "idem-key-12345"
 
@@ -193,7 +328,7 @@ func TestIdempotency_DifferentKeysCallTwice(t *testing.T) {
 
 	body, _ := json.Marshal(control.ConfigApplyRequest{
 		YAML: "metadata:\n  name: test",
-		Hash: "abcdef1234567890",
+		Hash: configHash("metadata:\n  name: test"),
 	})
 
 	for _, key := range []string{"key-A", "key-B"} {
@@ -271,6 +406,186 @@ func TestCancelEndpoint_Unavailable(t *testing.T) {
 	}
 }
 
+// --- POST /config/canary ----------------------------------------------------
+
+func TestConfigCanaryEndpoints(t *testing.T) {
+	var active *control.ConfigCanaryRequest
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		Version:   "v0.1",
+		StartedAt: time.Now(),
+		ApplyCanary: func(yaml, hash, room string) error {
+			if yaml == "" {
+				return fmt.Errorf("empty config")
+			}
+			active = &control.ConfigCanaryRequest{YAML: yaml, Hash: hash, Room: room}
+			return nil
+		},
+		AbortCanary: func() bool {
+			was := active != nil
+			active = nil
+			return was
+		},
+		Canary: func() *control.CanaryStatus {
+			if active == nil {
+				return nil
+			}
+			return &control.CanaryStatus{Room: active.Room, Hash: active.Hash}
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	post := func(path, body string) int {
+		t.Helper()
+		resp, err := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("/config/canary", `{"yaml":"","hash":"h","room":"!r:x"}`); code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid canary: expected 422, got %d", code)
+	}
+	if code := post("/config/canary", `{"yaml":"apiVersion: gosuto/v1","hash":"h1","room":"!r:x"}`); code != http.StatusOK {
+		t.Fatalf("canary: expected 200, got %d", code)
+	}
+
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	var st control.StatusResponse
+	_ = json.NewDecoder(resp.Body).Decode(&st)
+	resp.Body.Close()
+	if st.Canary == nil || st.Canary.Room != "!r:x" || st.Canary.Hash != "h1" {
+		t.Errorf("status canary = %+v, want room !r:x hash h1", st.Canary)
+	}
+
+	if code := post("/config/canary/abort", ""); code != http.StatusOK {
+		t.Errorf("abort: expected 200, got %d", code)
+	}
+	if code := post("/config/canary/abort", ""); code != http.StatusNotFound {
+		t.Errorf("abort without canary: expected 404, got %d", code)
+	}
+}
+
+// --- GET /config ------------------------------------------------------------
+
+const configEndpointYAML = `apiVersion: gosuto/v1
+metadata:
+  name: test-agent
+trust:
+  allowedRooms: ["!room:example.com"]
+  allowedSenders: ["*"]
+secrets:
+  - name: openai
+    envVar: OPENAI_API_KEY
+    required: true
+`
+
+func newConfigTestServer(t *testing.T, token string, ldr *gosuto.Loader) *httptest.Server {
+	t.Helper()
+	srv := control.New(":0", control.Handlers{
+		AgentID:      "test-agent",
+		Version:      "v0.1",
+		StartedAt:    time.Now(),
+		Token:        token,
+		GosutoHash:   ldr.Hash,
+		ActiveConfig: ldr.Config,
+		ConfigYAML:   ldr.YAML,
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestConfigEndpoint_ReturnsAppliedYAMLAndHash(t *testing.T) {
+	ldr := gosuto.New()
+	if err := ldr.Apply([]byte(configEndpointYAML)); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	ts := newConfigTestServer(t, "", ldr)
+
+	resp, err := http.Get(ts.URL + "/config")
+	if err != nil {
+		t.Fatalf("GET /config: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var got control.ConfigResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Hash != ldr.Hash() {
+		t.Errorf("hash = %q, want GosutoHash %q", got.Hash, ldr.Hash())
+	}
+	if got.YAML != configEndpointYAML {
+		t.Errorf("yaml mismatch:\n%s", got.YAML)
+	}
+}
+
+func TestConfigEndpoint_NoConfigReturns404(t *testing.T) {
+	ts := newConfigTestServer(t, "", gosuto.New())
+
+	resp, err := http.Get(ts.URL + "/config")
+	if err != nil {
+		t.Fatalf("GET /config: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestConfigEndpoint_RequiresAuth(t *testing.T) {
+	ldr := gosuto.New()
+	if err := ldr.Apply([]byte(configEndpointYAML)); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	ts := newConfigTestServer(t, "my-secret-token", ldr)
+
+	resp, err := http.Get(ts.URL + "/config")
+	if err != nil {
+		t.Fatalf("GET /config: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestConfigEndpoint_WrongMethod(t *testing.T) {
+	ts := newConfigTestServer(t, "", gosuto.New())
+
+	resp, err := http.Post(ts.URL+"/config", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /config: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestConfigEndpoint_Unavailable(t *testing.T) {
+	ts := startTestServer(t, "")
+
+	resp, err := http.Get(ts.URL + "/config")
+	if err != nil {
+		t.Fatalf("GET /config: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+}
+
 func TestToolCallEndpoint_ExecutesTool(t *testing.T) {
 	var (
 		gotSender string
@@ -394,6 +709,8 @@ func TestApprovalDecisionEndpoint_RejectsInvalidDecision(t *testing.T) {
 // a redeemResponse with the base64-encoded secret value for known tokens.
 func fakeKuzeServer(t *testing.T, agentID string, tokenSecrets map[string]string) *httptest.Server {
 	t.Helper()
+	// Leases are redeemed concurrently.
+	var mu sync.Mutex
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -414,7 +731,11 @@ func fakeKuzeServer(t *testing.T, agentID string, tokenSecrets map[string]string
 			return
 		}
 
+		mu.Lock()
 		b64val, ok := tokenSecrets[token]
+		// Remove from map to simulate single-use burn.
+		delete(tokenSecrets, token)
+		mu.Unlock()
 		if !ok {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusGone)
@@ -422,8 +743,6 @@ func fakeKuzeServer(t *testing.T, agentID string, tokenSecrets map[string]string
 			return
 		}
 
-		// Remove from map to simulate single-use burn.
-		delete(tokenSecrets, token)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"secret_ref":  "ref-for-" + token,
@@ -445,7 +764,7 @@ func TestSecretsToken_SingleLease(t *testing.T) {
 		AgentID:   "test-agent",
 		Version:   "v0.1",
 		StartedAt: time.Now(),
-		ApplySecrets: func(secrets map[string]string) error {
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
 			applied = secrets
 			return nil
 		},
@@ -481,6 +800,54 @@ func TestSecretsToken_SingleLease(t *testing.T) {
 	}
 }
 
+// TestSecretsToken_PassesLeaseTTLs verifies that per-lease TTL overrides
+// reach ApplySecrets, keyed by secret ref.
+func TestSecretsToken_PassesLeaseTTLs(t *testing.T) {
+	tokenSecrets := map[string]string{
+		"tok-bbb": "dmFsdWUtYmJi", // base64("value-bbb")
+		"tok-ccc": "dmFsdWUtY2Nj", // base64("value-ccc")
+	}
+	kuze := fakeKuzeServer(t, "test-agent", tokenSecrets)
+	defer kuze.Close()
+
+	var gotTTLs map[string]time.Duration
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test-agent",
+		Version:   "v0.1",
+		StartedAt: time.Now(),
+		ApplySecrets: func(_ map[string]string, ttls map[string]time.Duration) error {
+			gotTTLs = ttls
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	body, _ := json.Marshal(control.SecretsTokenRequest{
+		Leases: []control.SecretLease{
+			{SecretRef: "key-b", RedemptionToken: "tok-bbb", KuzeURL: kuze.URL + "/kuze/redeem/tok-bbb", TTLSeconds: 300},
+			{SecretRef: "key-c", RedemptionToken: "tok-ccc", KuzeURL: kuze.URL + "/kuze/redeem/tok-ccc"},
+		},
+	})
+
+	resp, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /secrets/token: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, b)
+	}
+
+	if gotTTLs["key-b"] != 5*time.Minute {
+		t.Errorf("ttl for key-b = %v, want 5m", gotTTLs["key-b"])
+	}
+	if _, ok := gotTTLs["key-c"]; ok {
+		t.Errorf("key-c has no lease TTL and should not be in ttls, got %v", gotTTLs["key-c"])
+	}
+}
+
 func TestSecretsToken_MultipleLeases(t *testing.T) {
 	tokenSecrets := map[string]string{
 		"tok-bbb": "dmFsdWUtYmJi", // base64("value-bbb")
@@ -494,7 +861,7 @@ func TestSecretsToken_MultipleLeases(t *testing.T) {
 		AgentID:   "test-agent",
 		Version:   "v0.1",
 		StartedAt: time.Now(),
-		ApplySecrets: func(secrets map[string]string) error {
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
 			applied = secrets
 			return nil
 		},
@@ -522,6 +889,14 @@ func TestSecretsToken_MultipleLeases(t *testing.T) {
 	if len(applied) != 2 {
 		t.Fatalf("expected 2 secrets applied, got %d", len(applied))
 	}
+
+	var got control.SecretsTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got.Applied) != 2 || len(got.Failed) != 0 {
+		t.Errorf("response = %+v, want both refs applied and none failed", got)
+	}
 }
 
 func TestSecretsToken_AllFail_ReturnsBadGateway(t *testing.T) {
@@ -532,7 +907,7 @@ func TestSecretsToken_AllFail_ReturnsBadGateway(t *testing.T) {
 		AgentID:   "test-agent",
 		Version:   "v0.1",
 		StartedAt: time.Now(),
-		ApplySecrets: func(secrets map[string]string) error {
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
 			t.Error("ApplySecrets should not be called when all redemptions fail")
 			return nil
 		},
@@ -556,59 +931,246 @@ func TestSecretsToken_AllFail_ReturnsBadGateway(t *testing.T) {
 	}
 }
 
-func TestSecretsToken_SecondRedemptionFails(t *testing.T) {
-	tokenSecrets := map[string]string{
-		"tok-once": "c2luZ2xl", // base64("single")
-	}
-	kuze := fakeKuzeServer(t, "test-agent", tokenSecrets)
+// TestSecretsToken_PartialFailureReportsEachRef verifies that a partial
+// outcome returns 207 with both the applied and the failed refs, and that
+// only the redeemed secrets are applied.
+func TestSecretsToken_PartialFailureReportsEachRef(t *testing.T) {
+	kuze := fakeKuzeServer(t, "test-agent", map[string]string{
+		"tok-good": "Z29vZA==", // base64("good")
+	})
 	defer kuze.Close()
 
+	var applied map[string]string
 	srv := control.New(":0", control.Handlers{
 		AgentID:   "test-agent",
-		Version:   "v0.1",
 		StartedAt: time.Now(),
-		ApplySecrets: func(secrets map[string]string) error {
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
+			applied = secrets
 			return nil
 		},
 	})
 	ts := httptest.NewServer(srv.TestHandler())
 	defer ts.Close()
 
-	leaseBody := func() []byte {
-		b, _ := json.Marshal(control.SecretsTokenRequest{
-			Leases: []control.SecretLease{
-				{SecretRef: "once-key", RedemptionToken: "tok-once", KuzeURL: kuze.URL + "/kuze/redeem/tok-once"},
-			},
-		})
-		return b
-	}
-
-	// First call — succeeds.
-	resp1, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(leaseBody()))
+	body, _ := json.Marshal(control.SecretsTokenRequest{
+		Leases: []control.SecretLease{
+			{SecretRef: "good-ref", RedemptionToken: "tok-good", KuzeURL: kuze.URL + "/kuze/redeem/tok-good"},
+			{SecretRef: "burnt-ref", RedemptionToken: "tok-burnt", KuzeURL: kuze.URL + "/kuze/redeem/tok-burnt"},
+		},
+	})
+	resp, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(body))
 	if err != nil {
-		t.Fatalf("first POST: %v", err)
+		t.Fatalf("POST /secrets/token: %v", err)
 	}
-	resp1.Body.Close()
-	if resp1.StatusCode != http.StatusOK {
-		t.Fatalf("first call: expected 200, got %d", resp1.StatusCode)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d", resp.StatusCode)
 	}
 
-	// Second call — token consumed, all fail → 502.
-	resp2, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(leaseBody()))
-	if err != nil {
-		t.Fatalf("second POST: %v", err)
+	var got control.SecretsTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
 	}
-	resp2.Body.Close()
-	if resp2.StatusCode != http.StatusBadGateway {
-		t.Fatalf("second call: expected 502, got %d", resp2.StatusCode)
+	if len(got.Applied) != 1 || got.Applied[0] != "good-ref" {
+		t.Errorf("applied = %v, want [good-ref]", got.Applied)
+	}
+	if len(got.Failed) != 1 || got.Failed[0].SecretRef != "burnt-ref" || !strings.Contains(got.Failed[0].Error, "410") {
+		t.Errorf("failed = %+v, want burnt-ref with the Kuze rejection", got.Failed)
+	}
+	if strings.Contains(got.Failed[0].Error, "tok-burnt") {
+		t.Errorf("failure reason leaks the redemption token: %q", got.Failed[0].Error)
+	}
+	if len(applied) != 1 || applied["good-ref"] != "Z29vZA==" {
+		t.Errorf("ApplySecrets got %v, want only good-ref", applied)
 	}
 }
 
-func TestSecretsToken_EmptyLeases_OK(t *testing.T) {
+// TestSecretsToken_RedeemErrorsRedactToken verifies that failed redemptions
+// are logged with the secret ref and never with the one-time token, both
+// when Kuze rejects the token and when it cannot be reached at all.
+func TestSecretsToken_RedeemErrorsRedactToken(t *testing.T) {
+	var logs bytes.Buffer
+	orig := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(orig) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	kuze := fakeKuzeServer(t, "test-agent", map[string]string{})
+	defer kuze.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
 	srv := control.New(":0", control.Handlers{
-		AgentID:   "test-agent",
-		Version:   "v0.1",
-		StartedAt: time.Now(),
+		AgentID:      "test-agent",
+		StartedAt:    time.Now(),
+		ApplySecrets: func(map[string]string, map[string]time.Duration) error { return nil },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	body, _ := json.Marshal(control.SecretsTokenRequest{
+		Leases: []control.SecretLease{
+			{SecretRef: "rejected-ref", RedemptionToken: "tok-rejected-raw", KuzeURL: kuze.URL + "/kuze/redeem/tok-rejected-raw"},
+			{SecretRef: "unreachable-ref", RedemptionToken: "tok-unreachable-raw", KuzeURL: downURL + "/kuze/redeem/tok-unreachable-raw"},
+		},
+	})
+	resp, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /secrets/token: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d", resp.StatusCode)
+	}
+
+	out := logs.String()
+	for _, want := range []string{"rejected-ref", "unreachable-ref", "/kuze/redeem/[REDACTED]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("logs missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "tok-rejected-raw") || strings.Contains(out, "tok-unreachable-raw") {
+		t.Errorf("logs contain a raw redemption token:\n%s", out)
+	}
+}
+
+func TestSecretsToken_RejectsTooManyLeases(t *testing.T) {
+	var kuzeCalls atomic.Int32
+	kuze := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kuzeCalls.Add(1)
+	}))
+	defer kuze.Close()
+
+	srv := control.New(":0", control.Handlers{
+		AgentID:         "test-agent",
+		StartedAt:       time.Now(),
+		MaxSecretLeases: 2,
+		ApplySecrets:    func(map[string]string, map[string]time.Duration) error { return nil },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	var leases []control.SecretLease
+	for i := 0; i < 3; i++ {
+		tok := fmt.Sprintf("tok-%d", i)
+		leases = append(leases, control.SecretLease{SecretRef: "ref-" + tok, RedemptionToken: tok, KuzeURL: kuze.URL + "/kuze/redeem/" + tok})
+	}
+	body, _ := json.Marshal(control.SecretsTokenRequest{Leases: leases})
+	resp, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /secrets/token: %v", err)
+	}
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusBadRequest, control.ErrorCodeBadRequest)
+	if n := kuzeCalls.Load(); n != 0 {
+		t.Errorf("Kuze was called %d times for a rejected request", n)
+	}
+}
+
+func TestSecretsToken_BoundsRedemptionConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	kuze := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		token := strings.TrimPrefix(r.URL.Path, "/kuze/redeem/")
+		json.NewEncoder(w).Encode(map[string]string{"secret_ref": token, "secret_type": "api_key", "value": "dmFs"})
+	}))
+	defer kuze.Close()
+
+	var applied map[string]string
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test-agent",
+		StartedAt: time.Now(),
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
+			applied = secrets
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	var leases []control.SecretLease
+	for i := 0; i < 16; i++ {
+		tok := fmt.Sprintf("tok-%d", i)
+		leases = append(leases, control.SecretLease{SecretRef: "ref-" + tok, RedemptionToken: tok, KuzeURL: kuze.URL + "/kuze/redeem/" + tok})
+	}
+	body, _ := json.Marshal(control.SecretsTokenRequest{Leases: leases})
+	resp, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /secrets/token: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if len(applied) != len(leases) {
+		t.Errorf("applied %d secrets, want %d", len(applied), len(leases))
+	}
+	if p := peak.Load(); p > control.MaxConcurrentRedemptions || p < 2 {
+		t.Errorf("peak concurrent redemptions = %d, want between 2 and %d", p, control.MaxConcurrentRedemptions)
+	}
+}
+
+func TestSecretsToken_SecondRedemptionFails(t *testing.T) {
+	tokenSecrets := map[string]string{
+		"tok-once": "c2luZ2xl", // base64("single")
+	}
+	kuze := fakeKuzeServer(t, "test-agent", tokenSecrets)
+	defer kuze.Close()
+
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test-agent",
+		Version:   "v0.1",
+		StartedAt: time.Now(),
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	leaseBody := func() []byte {
+		b, _ := json.Marshal(control.SecretsTokenRequest{
+			Leases: []control.SecretLease{
+				{SecretRef: "once-key", RedemptionToken: "tok-once", KuzeURL: kuze.URL + "/kuze/redeem/tok-once"},
+			},
+		})
+		return b
+	}
+
+	// First call — succeeds.
+	resp1, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(leaseBody()))
+	if err != nil {
+		t.Fatalf("first POST: %v", err)
+	}
+	resp1.Body.Close()
+	if resp1.StatusCode != http.StatusOK {
+		t.Fatalf("first call: expected 200, got %d", resp1.StatusCode)
+	}
+
+	// Second call — token consumed, all fail → 502.
+	resp2, err := http.Post(ts.URL+"/secrets/token", "application/json", bytes.NewReader(leaseBody()))
+	if err != nil {
+		t.Fatalf("second POST: %v", err)
+	}
+	resp2.Body.Close()
+	if resp2.StatusCode != http.StatusBadGateway {
+		t.Fatalf("second call: expected 502, got %d", resp2.StatusCode)
+	}
+}
+
+func TestSecretsToken_EmptyLeases_OK(t *testing.T) {
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test-agent",
+		Version:   "v0.1",
+		StartedAt: time.Now(),
 	})
 	ts := httptest.NewServer(srv.TestHandler())
 	defer ts.Close()
@@ -677,7 +1239,7 @@ func TestSecretsApply_EnabledWithFlag(t *testing.T) {
 		Version:                 "v0.1",
 		StartedAt:               time.Now(),
 		DirectSecretPushEnabled: true, // explicitly enable legacy path
-		ApplySecrets: func(secrets map[string]string) error {
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
 			for k, v := range secrets {
 				applied[k] = v
 			}
@@ -766,7 +1328,7 @@ func newEventTestServer(t *testing.T, token string, cfg *gosutospec.Config, rece
 		ApplyConfig: func(yaml, hash string) error {
 			return nil
 		},
-		ApplySecrets: func(secrets map[string]string) error {
+		ApplySecrets: func(secrets map[string]string, _ map[string]time.Duration) error {
 			return nil
 		},
 		RequestRestart: func() {},
@@ -774,10 +1336,11 @@ func newEventTestServer(t *testing.T, token string, cfg *gosutospec.Config, rece
 		ActiveConfig: func() *gosutospec.Config {
 			return cfg
 		},
-		HandleEvent: func(_ context.Context, _ *envelope.Event) {
+		HandleEvent: func(_ context.Context, _ *envelope.Event) error {
 			if received != nil {
 				received.Add(1)
 			}
+			return nil
 		},
 	})
 	ts := httptest.NewServer(srv.TestHandler())
@@ -953,6 +1516,244 @@ func TestEventIngress_RateLimitExceeded(t *testing.T) {
 	}
 }
 
+// newDefaultLimitEventServer creates an event ingress server whose
+// deployment-level default event rate limit is defaultLimit.
+func newDefaultLimitEventServer(t *testing.T, cfg *gosutospec.Config, defaultLimit int, received *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := control.New(":0", control.Handlers{
+		AgentID:                   "test-agent",
+		Version:                   "v0.0.1-test",
+		StartedAt:                 time.Now(),
+		DefaultMaxEventsPerMinute: defaultLimit,
+		ActiveConfig: func() *gosutospec.Config {
+			return cfg
+		},
+		HandleEvent: func(_ context.Context, _ *envelope.Event) error {
+			received.Add(1)
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestEventIngress_UnsetLimitUsesDefault verifies that a Gosuto config with
+// no maxEventsPerMinute is held to the deployment default.
+func TestEventIngress_UnsetLimitUsesDefault(t *testing.T) {
+	const defaultLimit = 2
+	var received atomic.Int32
+
+	cfg := makeTestGosutoConfig([]string{"scheduler"}, 0)
+	ts := newDefaultLimitEventServer(t, cfg, defaultLimit, &received)
+
+	var lastStatus int
+	for i := 0; i < defaultLimit+2; i++ {
+		resp := postEvent(t, ts, "scheduler", validEvent("scheduler"), "")
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		lastStatus = resp.StatusCode
+	}
+
+	if lastStatus != http.StatusTooManyRequests {
+		t.Errorf("expected 429 after exceeding default limit, got %d", lastStatus)
+	}
+	if received.Load() != defaultLimit {
+		t.Errorf("expected %d events forwarded under the default limit, got %d", defaultLimit, received.Load())
+	}
+}
+
+// TestEventIngress_ExplicitLimitOverridesDefault verifies that a configured
+// maxEventsPerMinute takes precedence over the deployment default.
+func TestEventIngress_ExplicitLimitOverridesDefault(t *testing.T) {
+	const limit = 4
+	var received atomic.Int32
+
+	cfg := makeTestGosutoConfig([]string{"scheduler"}, limit)
+	ts := newDefaultLimitEventServer(t, cfg, 1, &received)
+
+	for i := 0; i < limit+1; i++ {
+		resp := postEvent(t, ts, "scheduler", validEvent("scheduler"), "")
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if received.Load() != limit {
+		t.Errorf("expected %d events forwarded under the configured limit, got %d", limit, received.Load())
+	}
+}
+
+// TestEventIngress_UnlimitedOptOutIgnoresDefault verifies that
+// unlimitedEvents with an unset maxEventsPerMinute disables rate limiting
+// even when a deployment default is configured.
+func TestEventIngress_UnlimitedOptOutIgnoresDefault(t *testing.T) {
+	const events = 10
+	var received atomic.Int32
+
+	cfg := makeTestGosutoConfig([]string{"scheduler"}, 0)
+	cfg.Limits.UnlimitedEvents = true
+	ts := newDefaultLimitEventServer(t, cfg, 2, &received)
+
+	for i := 0; i < events; i++ {
+		resp := postEvent(t, ts, "scheduler", validEvent("scheduler"), "")
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("event %d: expected 202, got %d", i, resp.StatusCode)
+		}
+	}
+
+	if received.Load() != events {
+		t.Errorf("expected all %d events forwarded, got %d", events, received.Load())
+	}
+}
+
+// postEvents sends n events from source and returns how many were accepted.
+func postEvents(t *testing.T, ts *httptest.Server, source string, n int) int {
+	t.Helper()
+	accepted := 0
+	for i := 0; i < n; i++ {
+		resp := postEvent(t, ts, source, validEvent(source), "")
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusAccepted {
+			accepted++
+		}
+	}
+	return accepted
+}
+
+// TestEventIngress_PerSourceLimitHitBeforeGlobal verifies that a gateway's
+// own maxEventsPerMinute throttles that source while others keep flowing.
+func TestEventIngress_PerSourceLimitHitBeforeGlobal(t *testing.T) {
+	var received atomic.Int32
+	cfg := makeTestGosutoConfig([]string{"rss", "alerts"}, 10)
+	cfg.Gateways[0].MaxEventsPerMinute = 2
+	ts := newEventTestServer(t, "", cfg, &received)
+
+	if got := postEvents(t, ts, "rss", 5); got != 2 {
+		t.Errorf("rss: accepted %d events, want 2 (per-source limit)", got)
+	}
+	if got := postEvents(t, ts, "alerts", 5); got != 5 {
+		t.Errorf("alerts: accepted %d events, want 5 (unaffected by rss limit)", got)
+	}
+}
+
+// TestEventIngress_GlobalLimitHitBeforePerSource verifies that the global
+// limit still caps the total even when each source is within its own limit.
+func TestEventIngress_GlobalLimitHitBeforePerSource(t *testing.T) {
+	var received atomic.Int32
+	cfg := makeTestGosutoConfig([]string{"rss", "alerts"}, 4)
+	cfg.Gateways[0].MaxEventsPerMinute = 3
+	cfg.Gateways[1].MaxEventsPerMinute = 3
+	ts := newEventTestServer(t, "", cfg, &received)
+
+	if got := postEvents(t, ts, "rss", 3); got != 3 {
+		t.Errorf("rss: accepted %d events, want 3", got)
+	}
+	if got := postEvents(t, ts, "alerts", 3); got != 1 {
+		t.Errorf("alerts: accepted %d events, want 1 (global limit)", got)
+	}
+	if received.Load() != 4 {
+		t.Errorf("expected 4 events forwarded in total, got %d", received.Load())
+	}
+}
+
+// postEventBatch sends POST /events/{source}/batch with body and decodes the
+// per-item results.
+func postEventBatch(t *testing.T, ts *httptest.Server, source, body string) (int, control.EventBatchResponse) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/events/"+source+"/batch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /events/%s/batch: %v", source, err)
+	}
+	defer resp.Body.Close()
+	var out control.EventBatchResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("decode batch response: %v", err)
+		}
+	}
+	return resp.StatusCode, out
+}
+
+// TestEventBatch_MixedResults verifies that each envelope in a batch is
+// validated and rate limited on its own, with the per-source limit charged
+// across the whole batch, and that the response reports every item.
+func TestEventBatch_MixedResults(t *testing.T) {
+	var received atomic.Int32
+	cfg := makeTestGosutoConfig([]string{"rss", "alerts"}, 10)
+	cfg.Gateways[0].MaxEventsPerMinute = 2
+	ts := newEventTestServer(t, "", cfg, &received)
+
+	valid, _ := json.Marshal(validEvent("rss"))
+	wrongSource, _ := json.Marshal(validEvent("alerts"))
+	noType := validEvent("rss")
+	noType.Type = ""
+	invalid, _ := json.Marshal(noType)
+	body := "[" + strings.Join([]string{
+		string(valid), string(invalid), `"not an envelope"`, string(valid), string(wrongSource), string(valid),
+	}, ",") + "]"
+
+	code, got := postEventBatch(t, ts, "rss", body)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	want := []struct {
+		status string
+		code   int
+	}{
+		{"queued", http.StatusAccepted},
+		{"rejected", http.StatusBadRequest},
+		{"rejected", http.StatusBadRequest},
+		{"queued", http.StatusAccepted},
+		{"rejected", http.StatusBadRequest},
+		{"rejected", http.StatusTooManyRequests},
+	}
+	if len(got.Results) != len(want) {
+		t.Fatalf("results = %+v, want %d entries", got.Results, len(want))
+	}
+	for i, w := range want {
+		r := got.Results[i]
+		if r.Index != i || r.Status != w.status || r.Code != w.code {
+			t.Errorf("result[%d] = %+v, want %s/%d", i, r, w.status, w.code)
+		}
+		if w.status == "rejected" && r.Error == "" {
+			t.Errorf("result[%d] has no error message", i)
+		}
+	}
+	if got.Accepted != 2 || got.Rejected != 4 {
+		t.Errorf("accepted/rejected = %d/%d, want 2/4", got.Accepted, got.Rejected)
+	}
+	if received.Load() != 2 {
+		t.Errorf("HandleEvent called %d times, want 2", received.Load())
+	}
+	// The batch used up the source's budget for single posts too.
+	if n := postEvents(t, ts, "rss", 1); n != 0 {
+		t.Errorf("single post after batch accepted %d events, want 0 (limit shared)", n)
+	}
+}
+
+// TestEventBatch_RejectsMalformedRequests verifies request-level errors: an
+// unknown source, a body that is not an array and an empty batch.
+func TestEventBatch_RejectsMalformedRequests(t *testing.T) {
+	cfg := makeTestGosutoConfig([]string{"rss"}, 0)
+	ts := newEventTestServer(t, "", cfg, nil)
+
+	for _, tc := range []struct {
+		source, body string
+		want         int
+	}{
+		{"unknown", "[]", http.StatusNotFound},
+		{"rss", `{"source":"rss"}`, http.StatusBadRequest},
+		{"rss", "[]", http.StatusBadRequest},
+	} {
+		if code, _ := postEventBatch(t, ts, tc.source, tc.body); code != tc.want {
+			t.Errorf("POST /events/%s/batch %s: status %d, want %d", tc.source, tc.body, code, tc.want)
+		}
+	}
+}
+
 // TestEventIngress_WrongMethodRejected verifies that non-POST requests to the
 // event ingress endpoint are rejected with 405 Method Not Allowed.
 func TestEventIngress_WrongMethodRejected(t *testing.T) {
@@ -980,8 +1781,9 @@ func TestEventIngress_NoActiveConfigAllowsAnySource(t *testing.T) {
 	srv := control.New(":0", control.Handlers{
 		AgentID:   "test-agent",
 		StartedAt: time.Now(),
-		HandleEvent: func(_ context.Context, _ *envelope.Event) {
+		HandleEvent: func(_ context.Context, _ *envelope.Event) error {
 			received.Add(1)
+			return nil
 		},
 	})
 	ts := httptest.NewServer(srv.TestHandler())
@@ -1042,6 +1844,55 @@ func TestEventIngress_BearerTokenAccepted(t *testing.T) {
 	}
 }
 
+// newStrictEventTestServer is like newEventTestServer but with the localhost
+// bypass disabled (FEATURE_STRICT_EVENT_AUTH).
+func newStrictEventTestServer(t *testing.T, token string, cfg *gosutospec.Config) *httptest.Server {
+	t.Helper()
+	srv := control.New(":0", control.Handlers{
+		AgentID:                "test-agent",
+		StartedAt:              time.Now(),
+		Token:                  token,
+		DisableLocalhostBypass: true,
+		ActiveConfig:           func() *gosutospec.Config { return cfg },
+		HandleEvent:            func(_ context.Context, _ *envelope.Event) error { return nil },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestEventIngress_StrictAuthRejectsLoopbackWithoutToken verifies that with
+// DisableLocalhostBypass set, a loopback request must present the token.
+func TestEventIngress_StrictAuthRejectsLoopbackWithoutToken(t *testing.T) {
+	cfg := makeTestGosutoConfig([]string{"scheduler"}, 0)
+	ts := newStrictEventTestServer(t, "super-secret-token", cfg)
+
+	resp := postEvent(t, ts, "scheduler", validEvent("scheduler"), "" /* no token */)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	resp = postEvent(t, ts, "scheduler", validEvent("scheduler"), "super-secret-token")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 with token, got %d", resp.StatusCode)
+	}
+}
+
+// TestWebhookIngress_StrictAuthRejectsLoopbackWithoutToken verifies that
+// bearer-auth webhook gateways lose the localhost bypass too.
+func TestWebhookIngress_StrictAuthRejectsLoopbackWithoutToken(t *testing.T) {
+	cfg := makeWebhookTestGosutoConfig("hook", "bearer", "")
+	ts := newStrictEventTestServer(t, "super-secret-token", cfg)
+
+	resp := postWebhook(t, ts, "hook", []byte(`{"ok":true}`), "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", resp.StatusCode)
+	}
+}
+
 // --- R12.4: Built-in Webhook Gateway ----------------------------------------
 
 // makeWebhookTestGosutoConfig returns a minimal Gosuto config with a single
@@ -1095,7 +1946,7 @@ func newWebhookTestServer(
 		GosutoHash:   func() string { return "deadbeef" },
 		MCPNames:     func() []string { return nil },
 		ApplyConfig:  func(yaml, hash string) error { return nil },
-		ApplySecrets: func(sec map[string]string) error { return nil },
+		ApplySecrets: func(map[string]string, map[string]time.Duration) error { return nil },
 		ActiveConfig: func() *gosutospec.Config { return cfg },
 		GetSecret: func(ref string) ([]byte, error) {
 			if secrets == nil {
@@ -1107,10 +1958,11 @@ func newWebhookTestServer(
 			}
 			return val, nil
 		},
-		HandleEvent: func(_ context.Context, _ *envelope.Event) {
+		HandleEvent: func(_ context.Context, _ *envelope.Event) error {
 			if received != nil {
 				received.Add(1)
 			}
+			return nil
 		},
 	})
 	ts := httptest.NewServer(srv.TestHandler())
@@ -1162,14 +2014,120 @@ func TestWebhookIngress_BearerAuthAccepted(t *testing.T) {
 	}
 }
 
-// TestWebhookIngress_DefaultAuthIsBearerAccepted verifies that a webhook
-// gateway with no explicit authType defaults to bearer and still accepts
-// deliveries (localhost bypass active from httptest).
-func TestWebhookIngress_DefaultAuthIsBearerAccepted(t *testing.T) {
-	var received atomic.Int32
-	// authType is empty string → defaults to "bearer" in handleWebhookEvent
-	cfg := makeWebhookTestGosutoConfig("hook", "", "")
-	ts := newWebhookTestServer(t, "", cfg, nil, &received)
+// TestWebhookIngress_TypeFromSetsEventType verifies that a webhook gateway
+// with config.typeFrom dispatches the event under the derived type.
+func TestWebhookIngress_TypeFromSetsEventType(t *testing.T) {
+	cfg := makeWebhookTestGosutoConfig("github", "bearer", "")
+	cfg.Gateways[0].Config["typeFrom"] = "$.action"
+	got := make(chan string, 2)
+	srv := control.New(":0", control.Handlers{
+		AgentID:      "test-agent",
+		StartedAt:    time.Now(),
+		ActiveConfig: func() *gosutospec.Config { return cfg },
+		HandleEvent: func(_ context.Context, evt *envelope.Event) error {
+			got <- evt.Type
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	for _, body := range []string{`{"action":"opened"}`, `{"ref":"main"}`} {
+		resp := postWebhook(t, ts, "github", []byte(body), "", "")
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("expected 202, got %d", resp.StatusCode)
+		}
+	}
+	if typ := <-got; typ != "github.opened" {
+		t.Errorf("first event type = %q, want github.opened", typ)
+	}
+	if typ := <-got; typ != "webhook.delivery" {
+		t.Errorf("second event type = %q, want webhook.delivery (field absent)", typ)
+	}
+}
+
+// TestWebhookIngress_WrongMethodRejected verifies that a non-POST delivery to
+// a webhook gateway gets 405 with an Allow header and is not dispatched.
+func TestWebhookIngress_WrongMethodRejected(t *testing.T) {
+	var received atomic.Int32
+	cfg := makeWebhookTestGosutoConfig("github", "bearer", "")
+	ts := newWebhookTestServer(t, "", cfg, nil, &received)
+
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/events/github", strings.NewReader(`{"action":"opened"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT /events/github: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", resp.StatusCode)
+	}
+	if allow := resp.Header.Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Allow = %q, want POST", allow)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(b), "accepts POST only") {
+		t.Errorf("body = %s, want a webhook-specific error", b)
+	}
+	if received.Load() != 0 {
+		t.Errorf("HandleEvent called %d times, want 0", received.Load())
+	}
+}
+
+// TestWebhookIngress_ContentTypeMismatchRejected verifies that a webhook
+// gateway with config.contentType answers 415 for other media types and
+// accepts matching ones regardless of parameters.
+func TestWebhookIngress_ContentTypeMismatchRejected(t *testing.T) {
+	var received atomic.Int32
+	cfg := makeWebhookTestGosutoConfig("github", "bearer", "")
+	cfg.Gateways[0].Config["contentType"] = "application/json"
+	ts := newWebhookTestServer(t, "", cfg, nil, &received)
+
+	post := func(contentType string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/events/github", strings.NewReader(`{"action":"opened"}`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /events/github: %v", err)
+		}
+		return resp
+	}
+
+	for _, ct := range []string{"application/x-www-form-urlencoded", "", "text/plain; charset"} {
+		resp := post(ct)
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("Content-Type %q: expected 415, got %d: %s", ct, resp.StatusCode, b)
+		}
+		if !strings.Contains(string(b), "application/json") {
+			t.Errorf("Content-Type %q: body = %s, want the expected type named", ct, b)
+		}
+	}
+	if received.Load() != 0 {
+		t.Fatalf("HandleEvent called %d times for rejected deliveries", received.Load())
+	}
+
+	resp := post("Application/JSON; charset=utf-8")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("matching Content-Type: expected 202, got %d", resp.StatusCode)
+	}
+}
+
+// TestWebhookIngress_DefaultAuthIsBearerAccepted verifies that a webhook
+// gateway with no explicit authType defaults to bearer and still accepts
+// deliveries (localhost bypass active from httptest).
+func TestWebhookIngress_DefaultAuthIsBearerAccepted(t *testing.T) {
+	var received atomic.Int32
+	// authType is empty string → defaults to "bearer" in handleWebhookEvent
+	cfg := makeWebhookTestGosutoConfig("hook", "", "")
+	ts := newWebhookTestServer(t, "", cfg, nil, &received)
 
 	body := []byte(`{"type":"payment.succeeded"}`)
 	resp := postWebhook(t, ts, "hook", body, "", "")
@@ -1278,7 +2236,7 @@ func TestWebhookIngress_GetSecretNilReturns503(t *testing.T) {
 		StartedAt:    time.Now(),
 		ActiveConfig: func() *gosutospec.Config { return cfg },
 		GetSecret:    nil, // intentionally nil
-		HandleEvent:  func(_ context.Context, _ *envelope.Event) {},
+		HandleEvent:  func(_ context.Context, _ *envelope.Event) error { return nil },
 	})
 	ts := httptest.NewServer(srv.TestHandler())
 	t.Cleanup(ts.Close)
@@ -1431,3 +2389,911 @@ func TestWebhookIngress_RateLimitEnforced(t *testing.T) {
 		t.Errorf("expected %d events forwarded before rate limit, got %d", limit, received.Load())
 	}
 }
+
+// --- /tools -----------------------------------------------------------------
+
+func TestTools_ListsAndPassesSchemasFlag(t *testing.T) {
+	var gotSchemas []bool
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		Version:   "v0.1",
+		StartedAt: time.Now(),
+		ListTools: func(_ context.Context, withSchemas bool) (control.ToolListResponse, error) {
+			gotSchemas = append(gotSchemas, withSchemas)
+			return control.ToolListResponse{Tools: []control.ToolInfo{
+				{Name: "docs__search", MCP: "docs", Tool: "search", Description: "Search."},
+			}}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	for _, path := range []string{"/tools", "/tools?schemas=true"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		var body control.ToolListResponse
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		if resp.StatusCode != http.StatusOK || len(body.Tools) != 1 || body.Tools[0].MCP != "docs" {
+			t.Errorf("GET %s: status %d, body %+v", path, resp.StatusCode, body)
+		}
+	}
+	if len(gotSchemas) != 2 || gotSchemas[0] || !gotSchemas[1] {
+		t.Errorf("withSchemas = %v, want [false true]", gotSchemas)
+	}
+
+	resp, err := http.Post(ts.URL+"/tools", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /tools: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /tools: status %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestTools_Unavailable(t *testing.T) {
+	srv := control.New(":0", control.Handlers{AgentID: "test", StartedAt: time.Now()})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/tools")
+	if err != nil {
+		t.Fatalf("GET /tools: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+}
+
+// --- /calls -----------------------------------------------------------------
+
+func TestToolCalls_PassesLimit(t *testing.T) {
+	var gotLimits []int
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		StartedAt: time.Now(),
+		ListToolCalls: func(limit int) (control.ToolCallListResponse, error) {
+			gotLimits = append(gotLimits, limit)
+			return control.ToolCallListResponse{Calls: []control.ToolCallRecord{
+				{Caller: "llm", MCP: "docs", Tool: "search", Decision: "allow", ArgKeys: []string{"q"}},
+			}}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	for _, path := range []string{"/calls", "/calls?limit=5"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		var body control.ToolCallListResponse
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		if resp.StatusCode != http.StatusOK || len(body.Calls) != 1 || body.Calls[0].Tool != "search" {
+			t.Errorf("GET %s: status %d, body %+v", path, resp.StatusCode, body)
+		}
+	}
+	if len(gotLimits) != 2 || gotLimits[0] != 0 || gotLimits[1] != 5 {
+		t.Errorf("limits = %v, want [0 5]", gotLimits)
+	}
+
+	resp, err := http.Get(ts.URL + "/calls?limit=-1")
+	if err != nil {
+		t.Fatalf("GET /calls?limit=-1: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("GET /calls?limit=-1: status %d, want 400", resp.StatusCode)
+	}
+}
+
+// --- /turns ------------------------------------------------------------------
+
+func TestTurns_ListsSummaries(t *testing.T) {
+	var gotLimits []int
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		Token:     "secret",
+		StartedAt: time.Now(),
+		ListTurns: func(limit int) (control.TurnListResponse, error) {
+			gotLimits = append(gotLimits, limit)
+			return control.TurnListResponse{Turns: []control.TurnSummary{
+				{TraceID: "t-2", Trigger: "gateway", Gateway: "cron", EventType: "cron.tick", Status: "error", ToolCalls: 1, DurationMS: 40, Error: "boom"},
+				{TraceID: "t-1", Trigger: "matrix", Sender: "@alice:example.com", Status: "success", ToolCalls: 2, DurationMS: 1200},
+			}}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		return resp
+	}
+
+	resp := get("/turns?limit=2")
+	var body map[string][]map[string]interface{}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(body["turns"]) != 2 {
+		t.Fatalf("status %d, body %v", resp.StatusCode, body)
+	}
+	first := body["turns"][0]
+	for key, want := range map[string]interface{}{
+		"trace_id": "t-2", "trigger": "gateway", "gateway": "cron", "event_type": "cron.tick",
+		"status": "error", "tool_calls": float64(1), "duration_ms": float64(40), "error": "boom",
+	} {
+		if first[key] != want {
+			t.Errorf("turns[0][%s] = %v, want %v", key, first[key], want)
+		}
+	}
+	if len(gotLimits) != 1 || gotLimits[0] != 2 {
+		t.Errorf("limits = %v, want [2]", gotLimits)
+	}
+
+	for path, want := range map[string]int{"/turns?limit=0": http.StatusBadRequest, "/turns": http.StatusOK} {
+		resp := get(path)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s: status %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+	if gotLimits[len(gotLimits)-1] != 0 {
+		t.Errorf("limit without a query = %d, want 0", gotLimits[len(gotLimits)-1])
+	}
+}
+
+// --- /dlq -------------------------------------------------------------------
+
+func TestDeadLetterRetry_MapsErrors(t *testing.T) {
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		Version:   "v0.1",
+		StartedAt: time.Now(),
+		RetryDeadLetter: func(id int64) error {
+			switch id {
+			case 1:
+				return nil
+			case 2:
+				return control.ErrDeadLetterRetryLimit
+			default:
+				return control.ErrDeadLetterNotFound
+			}
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	cases := map[int64]int{
+		1: http.StatusAccepted,
+		2: http.StatusConflict,
+		3: http.StatusNotFound,
+	}
+	for id, want := range cases {
+		body := fmt.Sprintf(`{"id":%d}`, id)
+		resp, err := http.Post(ts.URL+"/dlq/retry", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST /dlq/retry: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("id %d: status = %d, want %d", id, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestDeadLetterList_Unavailable(t *testing.T) {
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		Version:   "v0.1",
+		StartedAt: time.Now(),
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/dlq")
+	if err != nil {
+		t.Fatalf("GET /dlq: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestTurnTranscript_ReturnsTranscriptByTrace(t *testing.T) {
+	var gotTrace string
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		Version:   "v0.1",
+		StartedAt: time.Now(),
+		TurnTranscript: func(traceID string) (control.TurnTranscriptResponse, error) {
+			gotTrace = traceID
+			if traceID != "abc123" {
+				return control.TurnTranscriptResponse{}, control.ErrTurnTranscriptNotFound
+			}
+			return control.TurnTranscriptResponse{TraceID: traceID, Messages: []control.TranscriptMessage{
+				{Role: "user", Content: "hi"},
+			}}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/turns/abc123/transcript")
+	if err != nil {
+		t.Fatalf("GET transcript: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if gotTrace != "abc123" {
+		t.Errorf("handler trace = %q, want abc123", gotTrace)
+	}
+	var body control.TurnTranscriptResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Messages) != 1 || body.Messages[0].Content != "hi" {
+		t.Errorf("messages = %+v, want the stored transcript", body.Messages)
+	}
+
+	missing, err := http.Get(ts.URL + "/turns/unknown/transcript")
+	if err != nil {
+		t.Fatalf("GET missing transcript: %v", err)
+	}
+	missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Errorf("missing transcript: status = %d, want 404", missing.StatusCode)
+	}
+}
+
+// --- event queue backpressure -----------------------------------------------
+
+func TestEventIngress_QueueFullReturns503(t *testing.T) {
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test-agent",
+		StartedAt: time.Now(),
+		HandleEvent: func(_ context.Context, _ *envelope.Event) error {
+			return control.ErrEventQueueFull
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+
+	evt := envelope.Event{Source: "scheduler", Type: "cron.tick", TS: time.Now()}
+	body, _ := json.Marshal(evt)
+	resp, err := http.Post(ts.URL+"/events/scheduler", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /events/scheduler: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+}
+
+func TestStatus_ReportsEventQueueDepth(t *testing.T) {
+	srv := control.New(":0", control.Handlers{
+		AgentID:         "test-agent",
+		StartedAt:       time.Now(),
+		EventQueueStats: func() (int, int) { return 5, 64 },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status control.StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.EventQueueDepth != 5 || status.EventQueueCapacity != 64 {
+		t.Errorf("queue = (%d, %d), want (5, 64)", status.EventQueueDepth, status.EventQueueCapacity)
+	}
+}
+
+func TestStatus_ReportsMonthlySpend(t *testing.T) {
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test-agent",
+		StartedAt: time.Now(),
+		MonthlySpend: func() *control.MonthlySpend {
+			return &control.MonthlySpend{Month: "2026-10", CostUSD: 3.25, BudgetUSD: 50}
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status control.StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.Spend == nil || *status.Spend != (control.MonthlySpend{Month: "2026-10", CostUSD: 3.25, BudgetUSD: 50}) {
+		t.Errorf("spend = %+v", status.Spend)
+	}
+}
+
+func TestStatus_ReportsInitializingMCPs(t *testing.T) {
+	srv := control.New(":0", control.Handlers{
+		AgentID:          "test-agent",
+		StartedAt:        time.Now(),
+		MCPNames:         func() []string { return []string{"docs", "search"} },
+		MCPsInitializing: func() []string { return []string{"search"} },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status control.StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(status.MCPsInitializing) != 1 || status.MCPsInitializing[0] != "search" {
+		t.Errorf("mcps_initializing = %v, want [search]", status.MCPsInitializing)
+	}
+}
+
+func TestStatus_ReportsMCPStatus(t *testing.T) {
+	exitAt := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test-agent",
+		StartedAt: time.Now(),
+		MCPStatus: func() []control.MCPServerStatus {
+			return []control.MCPServerStatus{
+				{Name: "docs", State: "running"},
+				{Name: "sqlite", State: "crashed", Restarts: 2, LastExit: "exit status 1", LastExitAt: &exitAt},
+			}
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	var raw struct {
+		MCPStatus []map[string]interface{} `json:"mcp_status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(raw.MCPStatus) != 2 {
+		t.Fatalf("mcp_status = %v, want two entries", raw.MCPStatus)
+	}
+	if running := raw.MCPStatus[0]; len(running) != 2 || running["name"] != "docs" || running["state"] != "running" {
+		t.Errorf("running entry = %v, want only name and state", running)
+	}
+	crashed := raw.MCPStatus[1]
+	if crashed["state"] != "crashed" || crashed["restarts"] != float64(2) ||
+		crashed["last_exit"] != "exit status 1" || crashed["last_exit_at"] != "2026-10-16T09:30:00Z" {
+		t.Errorf("crashed entry = %v", crashed)
+	}
+}
+
+func TestStatus_ReportsStartupFailures(t *testing.T) {
+	failures := []string{"mcp:ghost: exec: no such file or directory"}
+	for name, h := range map[string]control.Handlers{
+		"degraded": {AgentID: "test-agent", StartedAt: time.Now(), StartupFailures: func() []string { return failures }},
+		"clean":    {AgentID: "test-agent", StartedAt: time.Now(), StartupFailures: func() []string { return nil }},
+	} {
+		ts := httptest.NewServer(control.New(":0", h).TestHandler())
+		resp, err := http.Get(ts.URL + "/status")
+		if err != nil {
+			t.Fatalf("%s: GET /status: %v", name, err)
+		}
+		var raw map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		resp.Body.Close()
+		ts.Close()
+
+		got, present := raw["startup_failures"]
+		switch {
+		case name == "clean" && present:
+			t.Errorf("clean boot reports startup_failures = %v, want the field omitted", got)
+		case name == "degraded" && fmt.Sprint(got) != fmt.Sprint(failures):
+			t.Errorf("startup_failures = %v, want %v", got, failures)
+		}
+	}
+}
+
+// --- per-route timeouts ------------------------------------------------------
+
+func newSlowToolServer(t *testing.T, delay time.Duration, timeouts map[string]time.Duration) *httptest.Server {
+	t.Helper()
+	srv := control.New(":0", control.Handlers{
+		AgentID:       "test",
+		Version:       "v0.1",
+		StartedAt:     time.Now(),
+		RouteTimeouts: timeouts,
+		ExecuteTool: func(ctx context.Context, _, _ string, _ map[string]interface{}) (string, error) {
+			select {
+			case <-time.After(delay):
+				return "done", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func postToolCall(t *testing.T, ts *httptest.Server) *http.Response {
+	t.Helper()
+	body, _ := json.Marshal(control.ToolCallRequest{ToolRef: "slow.tool", Sender: "@admin:example.com"})
+	resp, err := http.Post(ts.URL+"/tools/call", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /tools/call: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestRouteTimeout_SlowHandlerOnShortRouteReturns503(t *testing.T) {
+	ts := newSlowToolServer(t, 500*time.Millisecond, map[string]time.Duration{
+		"/tools/call": 50 * time.Millisecond,
+	})
+
+	resp := postToolCall(t, ts)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestRouteTimeout_LongRouteCompletes(t *testing.T) {
+	ts := newSlowToolServer(t, 100*time.Millisecond, map[string]time.Duration{
+		"/tools/call": 2 * time.Second,
+	})
+
+	resp := postToolCall(t, ts)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestRouteTimeout_OverrideLeavesOtherRoutesOnDefaults(t *testing.T) {
+	ts := newSlowToolServer(t, 0, map[string]time.Duration{
+		"/tools/call": 50 * time.Millisecond,
+	})
+
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	if d := control.DefaultRouteTimeouts()["/events/{source}"]; d <= control.DefaultRouteTimeouts()["/health"] {
+		t.Errorf("event ingress timeout %v should exceed the probe timeout", d)
+	}
+}
+
+func TestStatus_ReportsLastConfigApply(t *testing.T) {
+	applied := &control.ConfigApplyStatus{Hash: "abc", Result: control.ConfigApplyWarning, Message: "mcp down"}
+	srv := control.New(":0", control.Handlers{
+		AgentID:         "test-agent",
+		StartedAt:       time.Now(),
+		LastConfigApply: func() *control.ConfigApplyStatus { return applied },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status control.StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.LastConfigApply == nil || status.LastConfigApply.Result != control.ConfigApplyWarning ||
+		status.LastConfigApply.Message != "mcp down" {
+		t.Errorf("last_config_apply = %+v", status.LastConfigApply)
+	}
+}
+
+func TestBroadcast_RelaysMessage(t *testing.T) {
+	var got string
+	srv := control.New(":0", control.Handlers{
+		AgentID:   "test",
+		StartedAt: time.Now(),
+		Broadcast: func(_ context.Context, message string) (control.BroadcastResponse, error) {
+			got = message
+			return control.BroadcastResponse{Rooms: 2}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/broadcast", "application/json", strings.NewReader(`{"message":"down at 22:00"}`))
+	if err != nil {
+		t.Fatalf("POST /broadcast: %v", err)
+	}
+	var body control.BroadcastResponse
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Rooms != 2 {
+		t.Errorf("status %d, body %+v", resp.StatusCode, body)
+	}
+	if got != "down at 22:00" {
+		t.Errorf("handler got message %q", got)
+	}
+
+	resp, err = http.Post(ts.URL+"/broadcast", "application/json", strings.NewReader(`{"message":"  "}`))
+	if err != nil {
+		t.Fatalf("POST /broadcast (empty): %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("empty message: status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestBroadcast_NotConfigured(t *testing.T) {
+	ts := httptest.NewServer(newTestServer("").TestHandler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/broadcast", "application/json", strings.NewReader(`{"message":"hi"}`))
+	if err != nil {
+		t.Fatalf("POST /broadcast: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want 503", resp.StatusCode)
+	}
+}
+
+// --- error responses -----------------------------------------------------------
+
+// assertErrorResponse checks that resp is a JSON ErrorResponse with the
+// given status and code and a non-empty message.
+func assertErrorResponse(t *testing.T, resp *http.Response, status int, code string) {
+	t.Helper()
+	if resp.StatusCode != status {
+		t.Errorf("status = %d, want %d", resp.StatusCode, status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body control.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	if body.Code != code || body.Error == "" {
+		t.Errorf("body = %+v, want code %q and a message", body, code)
+	}
+}
+
+func TestErrorResponses_WrongMethodOnEveryRoute(t *testing.T) {
+	ts := startTestServer(t, "")
+	for _, path := range []string{
+		"/health", "/status", "/config", "/config/apply", "/config/canary", "/config/canary/abort",
+		"/secrets/apply", "/secrets/token", "/process/restart", "/tasks/cancel", "/approvals/decision",
+		"/tools", "/tools/call", "/calls", "/turns/abc/transcript", "/token/rotate", "/broadcast",
+		"/dlq", "/dlq/retry", "/events/gw", "/events/gw/batch",
+	} {
+		t.Run(path, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPut, ts.URL+path, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("PUT %s: %v", path, err)
+			}
+			defer resp.Body.Close()
+			assertErrorResponse(t, resp, http.StatusMethodNotAllowed, control.ErrorCodeMethodNotAllowed)
+		})
+	}
+}
+
+func TestErrorResponses_CarryStableCodes(t *testing.T) {
+	ts := startTestServer(t, "secret")
+	yaml := "apiVersion: gosuto/v1\n"
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+		code   string
+	}{
+		{"unauthenticated", http.MethodGet, "/status", "", "", http.StatusUnauthorized, control.ErrorCodeUnauthorized},
+		{"unknown endpoint", http.MethodGet, "/nope", "secret", "", http.StatusNotFound, control.ErrorCodeNotFound},
+		{"malformed body", http.MethodPost, "/config/apply", "secret", "{", http.StatusBadRequest, control.ErrorCodeBadRequest},
+		{"hash mismatch", http.MethodPost, "/config/apply", "secret",
+			`{"yaml":` + fmt.Sprintf("%q", yaml) + `,"hash":"` + strings.Repeat("0", 64) + `"}`,
+			http.StatusUnprocessableEntity, control.ErrorCodeHashMismatch},
+		{"gone", http.MethodPost, "/secrets/apply", "secret", `{"secrets":{}}`, http.StatusGone, control.ErrorCodeGone},
+		{"unavailable", http.MethodPost, "/tools/call", "secret", `{"tool_ref":"x.y"}`, http.StatusServiceUnavailable, control.ErrorCodeUnavailable},
+		{"unsupported encoding", http.MethodPost, "/tasks/cancel", "secret", "", http.StatusUnsupportedMediaType, control.ErrorCodeUnsupportedMediaType},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, ts.URL+tc.path, strings.NewReader(tc.body))
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			if tc.code == control.ErrorCodeUnsupportedMediaType {
+				req.Header.Set("Content-Encoding", "br")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s: %v", tc.method, tc.path, err)
+			}
+			defer resp.Body.Close()
+			assertErrorResponse(t, resp, tc.status, tc.code)
+		})
+	}
+}
+
+func TestErrorResponses_RouteTimeoutIsJSON(t *testing.T) {
+	ts := newSlowToolServer(t, 500*time.Millisecond, map[string]time.Duration{
+		"/tools/call": 50 * time.Millisecond,
+	})
+	assertErrorResponse(t, postToolCall(t, ts), http.StatusServiceUnavailable, control.ErrorCodeTimeout)
+}
+
+// --- GET /metrics --------------------------------------------------------------
+
+// parseExposition parses a Prometheus text exposition into sample values
+// keyed by name and label set, failing on malformed lines and on samples
+// without a preceding # TYPE line.
+func parseExposition(t *testing.T, body string) map[string]float64 {
+	t.Helper()
+	samples := make(map[string]float64)
+	typed := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		if strings.HasPrefix(line, "# TYPE ") {
+			f := strings.Fields(line)
+			if len(f) != 4 || (f[3] != "counter" && f[3] != "gauge") {
+				t.Fatalf("bad TYPE line %q", line)
+			}
+			typed[f[2]] = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			t.Fatalf("malformed sample %q", line)
+		}
+		key := line[:i]
+		var v float64
+		if _, err := fmt.Sscanf(line[i+1:], "%g", &v); err != nil {
+			t.Fatalf("sample %q: %v", line, err)
+		}
+		name, _, _ := strings.Cut(key, "{")
+		if !typed[name] {
+			t.Fatalf("sample %q has no TYPE line", line)
+		}
+		samples[key] = v
+	}
+	return samples
+}
+
+func TestMetrics_ExposesCounters(t *testing.T) {
+	var turns atomic.Int64
+	srv := control.New(":0", control.Handlers{
+		AgentID:          "test-agent",
+		Token:            "secret",
+		StartedAt:        time.Now().Add(-time.Minute),
+		MessagesOutbound: func() int64 { return 4 },
+		EventQueueStats:  func() (int, int) { return 2, 64 },
+		Metrics: func() control.Metrics {
+			return control.Metrics{
+				TurnsProcessed:    turns.Load(),
+				ToolCalls:         7,
+				PolicyDenials:     2,
+				EventsIngested:    map[string]int64{"cron": 3, "github": 1},
+				SenderRateLimited: 5,
+			}
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+
+	scrape := func() map[string]float64 {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/metrics", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /metrics: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return parseExposition(t, string(body))
+	}
+
+	first := scrape()
+	turns.Add(1)
+	second := scrape()
+
+	if first["gitai_turns_total"] != 0 || second["gitai_turns_total"] != 1 {
+		t.Errorf("gitai_turns_total = %v then %v, want 0 then 1", first["gitai_turns_total"], second["gitai_turns_total"])
+	}
+	want := map[string]float64{
+		"gitai_tool_calls_total":                            7,
+		"gitai_policy_denials_total":                        2,
+		`gitai_events_ingested_total{source="cron"}`:        3,
+		`gitai_events_ingested_total{source="github"}`:      1,
+		`gitai_rate_limit_rejections_total{scope="event"}`:  0,
+		`gitai_rate_limit_rejections_total{scope="sender"}`: 5,
+		"gitai_messages_outbound_total":                     4,
+		"gitai_event_queue_depth":                           2,
+		"gitai_event_queue_capacity":                        64,
+	}
+	for key, v := range want {
+		if got, ok := second[key]; !ok || got != v {
+			t.Errorf("%s = %v (present %v), want %v", key, got, ok, v)
+		}
+	}
+	if up := second["gitai_uptime_seconds"]; up < 60 {
+		t.Errorf("gitai_uptime_seconds = %v, want at least 60", up)
+	}
+}
+
+func TestMetrics_RequiresAuth(t *testing.T) {
+	ts := httptest.NewServer(newTestServer("secret").TestHandler())
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestPprof_NotServedOnACP(t *testing.T) {
+	ts := httptest.NewServer(newTestServer("secret").TestHandler())
+	t.Cleanup(ts.Close)
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, resp.StatusCode)
+		}
+	}
+}
+
+// TestEventIngress_GzipRequiresTokenBeforeInflating verifies that a
+// compressed event from an unauthenticated caller is refused before its
+// body is inflated: the corrupt archive would otherwise get 400.
+func TestEventIngress_GzipRequiresTokenBeforeInflating(t *testing.T) {
+	cfg := makeTestGosutoConfig([]string{"scheduler"}, 0)
+	ts := newStrictEventTestServer(t, "super-secret-token", cfg)
+
+	for _, path := range []string{"/events/scheduler", "/events/scheduler/batch"} {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+path, strings.NewReader("not gzip"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("POST %s without token: status = %d, want 401", path, resp.StatusCode)
+		}
+	}
+
+	body, _ := json.Marshal(validEvent("scheduler"))
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/events/scheduler", bytes.NewReader(gzipBody(t, body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Authorization", "Bearer super-secret-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /events/scheduler: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("authenticated gzip event: status = %d, want 202", resp.StatusCode)
+	}
+}
+
+func TestResponses_GzippedWhenAccepted(t *testing.T) {
+	srv := control.New(":0", control.Handlers{AgentID: "test", StartedAt: time.Now()})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/health", nil)
+	// Setting the header explicitly stops the transport from inflating the
+	// body for us.
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	var health control.HealthResponse
+	if err := json.NewDecoder(zr).Decode(&health); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if health.AgentID != "test" {
+		t.Errorf("agent_id = %q, want test", health.AgentID)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/health", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	resp2.Body.Close()
+	if got := resp2.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q with gzip;q=0, want none", got)
+	}
+}
@@ -0,0 +1,80 @@
+package control
+
+import (
+	"crypto/subtle"
+	"sync"
+	"time"
+)
+
+// Token rotation bounds for POST /token/rotate.
+const (
+	// DefaultTokenOverlap is how long the previous token keeps working when
+	// a rotation request does not name an overlap.
+	DefaultTokenOverlap = 5 * time.Minute
+	// MaxTokenOverlap caps the overlap window a rotation may ask for.
+	MaxTokenOverlap = 24 * time.Hour
+	// minTokenLen rejects trivially short replacement tokens.
+	minTokenLen = 16
+)
+
+// TokenSet holds the ACP bearer tokens the server accepts. Normally that is
+// a single token; after Rotate the previous token stays valid until its
+// retirement time, so Ruriko can switch to the new one without downtime.
+// An empty current token disables authentication (dev/test).
+type TokenSet struct {
+	mu            sync.RWMutex
+	current       string
+	previous      string
+	previousUntil time.Time
+	now           func() time.Time
+}
+
+// NewTokenSet returns a TokenSet that accepts token.
+func NewTokenSet(token string) *TokenSet {
+	return NewTokenSetWithClock(token, time.Now)
+}
+
+// NewTokenSetWithClock is like NewTokenSet but injects a custom clock.
+// Intended for tests that need to move past a retirement time.
+func NewTokenSetWithClock(token string, now func() time.Time) *TokenSet {
+	return &TokenSet{current: token, now: now}
+}
+
+// Current returns the token clients should present.
+func (t *TokenSet) Current() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.current
+}
+
+// Required reports whether requests must present a token at all.
+func (t *TokenSet) Required() bool {
+	return t.Current() != ""
+}
+
+// Valid reports whether tok is the current token, or the previous one
+// while it has not yet been retired.
+func (t *TokenSet) Valid(tok string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if tokenEqual(tok, t.current) {
+		return true
+	}
+	return t.previous != "" && t.now().Before(t.previousUntil) && tokenEqual(tok, t.previous)
+}
+
+// Rotate makes token the current token and keeps the old one valid for
+// overlap. It returns the time the old token retires. A previous token
+// still inside an earlier window is dropped.
+func (t *TokenSet) Rotate(token string, overlap time.Duration) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.previous = t.current
+	t.previousUntil = t.now().Add(overlap)
+	t.current = token
+	return t.previousUntil
+}
+
+func tokenEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
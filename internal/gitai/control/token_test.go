@@ -0,0 +1,156 @@
+package control_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+const (
+	oldACPToken = "old-token-0123456789"
+	newACPToken = "new-token-0123456789"
+)
+
+// testClock is a manually advanced clock for TokenSet retirement tests.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func getStatusWithToken(t *testing.T, ts *httptest.Server, token string) int {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/health", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func rotateToken(t *testing.T, ts *httptest.Server, auth string, body control.TokenRotateRequest) (*http.Response, control.TokenRotateResponse) {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/token/rotate", bytes.NewReader(raw))
+	req.Header.Set("Authorization", "Bearer "+auth)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /token/rotate: %v", err)
+	}
+	defer resp.Body.Close()
+	var out control.TokenRotateResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("decode rotate response: %v", err)
+		}
+	}
+	return resp, out
+}
+
+func TestTokenRotate_BothTokensValidUntilRetirement(t *testing.T) {
+	clk := &testClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	tokens := control.NewTokenSetWithClock(oldACPToken, clk.Now)
+	var persisted string
+	srv := control.New(":0", control.Handlers{
+		AgentID:      "test",
+		StartedAt:    time.Now(),
+		Tokens:       tokens,
+		PersistToken: func(token string) error { persisted = token; return nil },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	resp, out := rotateToken(t, ts, oldACPToken, control.TokenRotateRequest{Token: newACPToken, OverlapSeconds: 600})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("rotate: status %d", resp.StatusCode)
+	}
+	if want := clk.Now().Add(10 * time.Minute); !out.RetiresAt.Equal(want) {
+		t.Errorf("RetiresAt = %v, want %v", out.RetiresAt, want)
+	}
+	if persisted != newACPToken {
+		t.Errorf("persisted = %q, want the new token", persisted)
+	}
+	if tokens.Current() != newACPToken {
+		t.Errorf("Current() = %q, want the new token", tokens.Current())
+	}
+
+	// Inside the window both tokens authenticate.
+	clk.Advance(9 * time.Minute)
+	for _, tok := range []string{oldACPToken, newACPToken} {
+		if got := getStatusWithToken(t, ts, tok); got != http.StatusOK {
+			t.Errorf("token %q inside window: status %d, want 200", tok, got)
+		}
+	}
+
+	// After retirement only the new token does.
+	clk.Advance(2 * time.Minute)
+	if got := getStatusWithToken(t, ts, oldACPToken); got != http.StatusUnauthorized {
+		t.Errorf("old token after retirement: status %d, want 401", got)
+	}
+	if got := getStatusWithToken(t, ts, newACPToken); got != http.StatusOK {
+		t.Errorf("new token after retirement: status %d, want 200", got)
+	}
+}
+
+func TestTokenRotate_RejectsBadRequests(t *testing.T) {
+	srv := control.New(":0", control.Handlers{AgentID: "test", StartedAt: time.Now(), Token: oldACPToken})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	cases := map[string]control.TokenRotateRequest{
+		"short token":      {Token: "short"},
+		"same token":       {Token: oldACPToken},
+		"negative overlap": {Token: newACPToken, OverlapSeconds: -1},
+		"overlap too long": {Token: newACPToken, OverlapSeconds: int(control.MaxTokenOverlap.Seconds()) + 1},
+	}
+	for name, req := range cases {
+		if resp, _ := rotateToken(t, ts, oldACPToken, req); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, resp.StatusCode)
+		}
+	}
+	if resp, _ := rotateToken(t, ts, "wrong-token", control.TokenRotateRequest{Token: newACPToken}); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong auth: status %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestTokenRotate_PersistFailureKeepsOldToken(t *testing.T) {
+	tokens := control.NewTokenSet(oldACPToken)
+	srv := control.New(":0", control.Handlers{
+		AgentID:      "test",
+		StartedAt:    time.Now(),
+		Tokens:       tokens,
+		PersistToken: func(string) error { return errors.New("disk full") },
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	defer ts.Close()
+
+	if resp, _ := rotateToken(t, ts, oldACPToken, control.TokenRotateRequest{Token: newACPToken}); resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", resp.StatusCode)
+	}
+	if tokens.Current() != oldACPToken {
+		t.Errorf("Current() = %q, want the old token after a failed persist", tokens.Current())
+	}
+	if got := getStatusWithToken(t, ts, newACPToken); got != http.StatusUnauthorized {
+		t.Errorf("new token after failed rotation: status %d, want 401", got)
+	}
+}
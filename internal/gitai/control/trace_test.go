@@ -0,0 +1,88 @@
+package control_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/common/spec/envelope"
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+)
+
+func TestTraceHeader_EchoesCallerTraceID(t *testing.T) {
+	ts := startTestServer(t, "")
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/health", nil)
+	req.Header.Set(trace.Header, "t_caller")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get(trace.Header); got != "t_caller" {
+		t.Errorf("%s = %q, want t_caller", trace.Header, got)
+	}
+}
+
+func TestTraceHeader_GeneratedWhenMissingOrInvalid(t *testing.T) {
+	ts := startTestServer(t, "")
+
+	for _, sent := range []string{"", "bad id forged=1"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/health", nil)
+		if sent != "" {
+			req.Header.Set(trace.Header, sent)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /health: %v", err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get(trace.Header); !strings.HasPrefix(got, "t_") {
+			t.Errorf("sent %q: %s = %q, want a generated trace ID", sent, trace.Header, got)
+		}
+	}
+}
+
+func TestTraceHeader_ReachesEventHandler(t *testing.T) {
+	got := make(chan string, 1)
+	cfg := makeTestGosutoConfig([]string{"scheduler"}, 0)
+	srv := control.New(":0", control.Handlers{
+		AgentID:      "test-agent",
+		StartedAt:    time.Now(),
+		ActiveConfig: func() *gosutospec.Config { return cfg },
+		HandleEvent: func(ctx context.Context, _ *envelope.Event) error {
+			got <- trace.FromContext(ctx)
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+
+	body, _ := json.Marshal(validEvent("scheduler"))
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/events/scheduler", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(trace.Header, "t_event")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /events/scheduler: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", resp.StatusCode)
+	}
+	select {
+	case id := <-got:
+		if id != "t_event" {
+			t.Errorf("handler trace ID = %q, want t_event", id)
+		}
+	default:
+		t.Fatal("HandleEvent was not called")
+	}
+}
@@ -0,0 +1,165 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+// tunnelRemoteAddr is the RemoteAddr given to requests arriving over the
+// control tunnel. It is deliberately not a loopback address so tunnelled
+// requests never qualify for the localhost bypass on event ingress.
+const tunnelRemoteAddr = "tunnel"
+
+// TunnelConfig configures the optional outbound control tunnel.
+type TunnelConfig struct {
+	// URL is Ruriko's tunnel endpoint, e.g. "ws://ruriko:8080/agents/tunnel".
+	URL string
+	// Token is the bootstrap token presented when connecting without an ACP
+	// token.
+	Token string
+	// ACPToken, when set, returns the agent's current ACP token. Ruriko
+	// requires it instead of the bootstrap token once it has one on record.
+	ACPToken func() string
+	// AgentID identifies this agent to Ruriko.
+	AgentID string
+	// MaxBackoff caps the delay between reconnect attempts. Defaults to 1m.
+	MaxBackoff time.Duration
+}
+
+// RunTunnel keeps an outbound WebSocket to Ruriko open and serves ACP
+// requests received over it, reconnecting with exponential backoff until ctx
+// is cancelled. Agents behind NAT use this instead of inbound HTTP.
+func (s *Server) RunTunnel(ctx context.Context, cfg TunnelConfig) {
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+	delay := time.Second
+	for {
+		err := s.dialTunnel(ctx, cfg)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("ACP tunnel disconnected; reconnecting", "url", cfg.URL, "err", err, "retry_in", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > cfg.MaxBackoff {
+			delay = cfg.MaxBackoff
+		}
+	}
+}
+
+// dialTunnel connects once and serves until the connection drops.
+func (s *Server) dialTunnel(ctx context.Context, cfg TunnelConfig) error {
+	wsCfg, err := websocket.NewConfig(cfg.URL, "http://"+cfg.AgentID)
+	if err != nil {
+		return fmt.Errorf("tunnel config: %w", err)
+	}
+	token := cfg.Token
+	if cfg.ACPToken != nil {
+		if t := cfg.ACPToken(); t != "" {
+			token = t
+		}
+	}
+	wsCfg.Header.Set("Authorization", "Bearer "+token)
+	wsCfg.Header.Set("X-Agent-ID", cfg.AgentID)
+	conn, err := wsCfg.DialContext(ctx)
+	if err != nil {
+		return fmt.Errorf("tunnel dial: %w", err)
+	}
+	slog.Info("ACP tunnel connected", "url", cfg.URL)
+	return s.ServeTunnel(ctx, conn)
+}
+
+// ServeTunnel answers ACP request frames received on conn with the same
+// handler (and bearer-token auth) as the HTTP listener. Requests are handled
+// concurrently so a slow config apply does not block status checks. It
+// returns when conn fails or ctx is cancelled.
+func (s *Server) ServeTunnel(ctx context.Context, conn *websocket.Conn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	var sendMu sync.Mutex
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		var req acpspec.TunnelFrame
+		if err := websocket.JSON.Receive(conn, &req); err != nil {
+			return fmt.Errorf("tunnel receive: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := s.serveTunnelFrame(ctx, req)
+			sendMu.Lock()
+			defer sendMu.Unlock()
+			if err := websocket.JSON.Send(conn, resp); err != nil {
+				slog.Warn("ACP tunnel send failed", "id", req.ID, "err", err)
+			}
+		}()
+	}
+}
+
+// serveTunnelFrame runs a single request frame through the server handler.
+func (s *Server) serveTunnelFrame(ctx context.Context, f acpspec.TunnelFrame) acpspec.TunnelFrame {
+	r, err := http.NewRequestWithContext(ctx, f.Method, f.Path, bytes.NewReader(f.Body))
+	if err != nil {
+		return acpspec.TunnelFrame{ID: f.ID, Status: http.StatusBadRequest, Body: []byte(err.Error())}
+	}
+	for k, v := range f.Header {
+		r.Header.Set(k, v)
+	}
+	r.RemoteAddr = tunnelRemoteAddr
+	r.RequestURI = f.Path
+
+	w := &frameWriter{header: make(http.Header)}
+	s.server.Handler.ServeHTTP(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	header := make(map[string]string, len(w.header))
+	for k, v := range w.header {
+		if len(v) > 0 {
+			header[k] = v[0]
+		}
+	}
+	return acpspec.TunnelFrame{ID: f.ID, Status: w.status, Header: header, Body: w.body.Bytes()}
+}
+
+// frameWriter is a minimal http.ResponseWriter that buffers the response.
+type frameWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *frameWriter) Header() http.Header { return w.header }
+
+func (w *frameWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *frameWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
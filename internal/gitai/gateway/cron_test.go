@@ -12,6 +12,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -344,6 +345,66 @@ func TestManager_FiresOnSchedule(t *testing.T) {
 	}
 }
 
+// TestManager_SendsACPTokenWhenSet verifies that cron events carry the ACP
+// bearer token once SetACPToken is called, as strict event auth requires.
+func TestManager_SendsACPTokenWhenSet(t *testing.T) {
+	authCh := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		authCh <- r.Header.Get("Authorization")
+	}))
+	t.Cleanup(srv.Close)
+
+	clk := newFakeClock(time.Date(2026, 1, 15, 10, 7, 0, 0, time.UTC))
+	mgr := NewManagerWithClock(srv.URL, clk)
+	defer mgr.Stop()
+	mgr.SetACPToken(func() string { return "acp-secret" })
+
+	mgr.Reconcile([]gosutospec.Gateway{
+		cronGW("scheduler", "*/15 * * * *", "tick"),
+	})
+	if !clk.WaitForWaiter(1, 2*time.Second) {
+		t.Fatal("cron goroutine did not register a timer waiter in time")
+	}
+	clk.Advance(9 * time.Minute)
+
+	select {
+	case got := <-authCh:
+		if got != "Bearer acp-secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer acp-secret")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for cron event")
+	}
+}
+
+// TestManager_FireDoesNotTakeManagerLock verifies that delivering an event
+// does not need m.mu, which Reconcile and Stop hold while waiting for jobs
+// to exit.
+func TestManager_FireDoesNotTakeManagerLock(t *testing.T) {
+	srv, events := captureServer(t)
+
+	clk := newFakeClock(time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC))
+	mgr := NewManagerWithClock(srv.URL, clk)
+	defer mgr.Stop()
+	mgr.SetACPToken(func() string { return "acp-secret" })
+
+	mgr.Reconcile([]gosutospec.Gateway{
+		cronGW("every-min", "* * * * *", "tick"),
+	})
+	if !clk.WaitForWaiter(1, 2*time.Second) {
+		t.Fatal("cron goroutine did not register a timer waiter in time")
+	}
+
+	mgr.mu.Lock()
+	clk.Advance(time.Minute)
+	_, ok := waitEvent(t, events, 2*time.Second)
+	mgr.mu.Unlock()
+	if !ok {
+		t.Fatal("fire blocked on the manager lock")
+	}
+}
+
 // TestManager_FiresMultipleTicks verifies that the gateway continues firing
 // after the first tick.
 func TestManager_FiresMultipleTicks(t *testing.T) {
@@ -553,6 +614,41 @@ func TestManager_RemovesExpiredJob(t *testing.T) {
 	}
 }
 
+// TestManager_ReconcileReportsResult verifies that Reconcile reports which
+// jobs it started, restarted, stopped or could not start.
+func TestManager_ReconcileReportsResult(t *testing.T) {
+	srv, _ := captureServer(t)
+	mgr := NewManagerWithClock(srv.URL, newFakeClock(time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)))
+	defer mgr.Stop()
+
+	res := mgr.Reconcile([]gosutospec.Gateway{
+		cronGW("a", "* * * * *", "tick"),
+		cronGW("b", "*/5 * * * *", "tick"),
+		cronGW("bad", "not a cron", "tick"),
+	})
+	if got := strings.Join(res.Started, ","); got != "a,b" {
+		t.Errorf("started = %q, want a,b", got)
+	}
+	if len(res.Failed) != 1 || res.Failed[0].Name != "bad" {
+		t.Errorf("failed = %+v, want [bad]", res.Failed)
+	}
+
+	res = mgr.Reconcile([]gosutospec.Gateway{cronGW("b", "*/10 * * * *", "tick")})
+	if got := strings.Join(res.Stopped, ","); got != "a" {
+		t.Errorf("stopped = %q, want a", got)
+	}
+	if got := strings.Join(res.Restarted, ","); got != "b" {
+		t.Errorf("restarted = %q, want b", got)
+	}
+	if len(res.Started) != 0 || len(res.Failed) != 0 {
+		t.Errorf("unexpected started/failed: %+v", res)
+	}
+
+	if res = mgr.Reconcile([]gosutospec.Gateway{cronGW("b", "*/10 * * * *", "tick")}); !res.Empty() {
+		t.Errorf("unchanged reconcile = %s, want no changes", res)
+	}
+}
+
 // TestManager_NoOpForNonCronGateways verifies that gateways with type != "cron"
 // are silently ignored by the Manager.
 func TestManager_NoOpForNonCronGateways(t *testing.T) {
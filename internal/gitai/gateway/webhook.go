@@ -20,10 +20,12 @@ package gateway
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/bdobrica/Ruriko/common/spec/envelope"
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
 	"github.com/bdobrica/Ruriko/common/webhookauth"
 )
 
@@ -51,8 +53,9 @@ func ValidateHMACSHA256(secret, body []byte, sigHeader string) bool {
 // common webhook fields (action, event, type, ref, repository.full_name) but
 // degrades gracefully to a generic description.
 //
-// The Event.Type is always "webhook.delivery" so agents can distinguish
-// webhook turns from cron (cron.tick) turns in their audit or routing logic.
+// The Event.Type is "webhook.delivery" so agents can distinguish webhook
+// turns from cron (cron.tick) turns in their audit or routing logic; see
+// WrapWebhookDelivery for gateways that derive the type from the delivery.
 func WrapRawWebhookBody(source string, rawBody []byte) *envelope.Event {
 	evt := &envelope.Event{
 		Source: source,
@@ -76,6 +79,72 @@ func WrapRawWebhookBody(source string, rawBody []byte) *envelope.Event {
 	return evt
 }
 
+// WrapWebhookDelivery is WrapRawWebhookBody for a gateway with an optional
+// config.typeFrom. When typeFrom is set and the named payload field or header
+// holds a usable value, the event type becomes "<source>.<value>" (e.g.
+// "github.opened" for typeFrom "$.action"); otherwise it stays
+// "webhook.delivery".
+func WrapWebhookDelivery(source string, rawBody []byte, header http.Header, typeFrom string) *envelope.Event {
+	evt := WrapRawWebhookBody(source, rawBody)
+	if typeFrom == "" {
+		return evt
+	}
+	tf, err := gosutospec.ParseWebhookTypeFrom(typeFrom)
+	if err != nil {
+		// Rejected when the config was applied; keep the default.
+		return evt
+	}
+	if v, ok := webhookTypeValue(tf, evt.Payload.Data, header); ok {
+		evt.Type = source + "." + v
+	}
+	return evt
+}
+
+// webhookTypeValue resolves tf against the decoded payload or the request
+// header. Only scalar values made of event-type-safe characters are used, so
+// a missing field, an object or free text falls back to the default type.
+func webhookTypeValue(tf gosutospec.WebhookTypeFrom, data map[string]interface{}, header http.Header) (string, bool) {
+	var v string
+	if tf.Header != "" {
+		v = header.Get(tf.Header)
+	} else {
+		var cur interface{} = data
+		for _, key := range tf.Path {
+			obj, ok := cur.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+			if cur, ok = obj[key]; !ok {
+				return "", false
+			}
+		}
+		switch x := cur.(type) {
+		case string:
+			v = x
+		case float64, bool:
+			v = fmt.Sprint(x)
+		default:
+			return "", false
+		}
+	}
+	v = strings.TrimSpace(v)
+	if v == "" || len(v) > maxWebhookTypeLen {
+		return "", false
+	}
+	for _, r := range v {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '_', r == '-', r == '.', r == ':', r == '/':
+		default:
+			return "", false
+		}
+	}
+	return v, true
+}
+
+// maxWebhookTypeLen caps a derived event-type value.
+const maxWebhookTypeLen = 64
+
 // summariseWebhookData produces a concise human-readable description of the
 // webhook payload.  It recognises common envelope fields that most webhook
 // providers include.
@@ -3,12 +3,14 @@ package gateway
 // Tests for the built-in webhook gateway (R12.4):
 //   - ValidateHMACSHA256: correct signature passes, wrong/malformed fail
 //   - WrapRawWebhookBody: JSON body, non-JSON body, empty body, GitHub-like fields
+//   - WrapWebhookDelivery: event type derived from config.typeFrom
 
 import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
 )
@@ -237,3 +239,48 @@ func TestWrapRawWebhookBody_EnvelopeValidates(t *testing.T) {
 		t.Errorf("WrapRawWebhookBody produced an invalid event envelope: %v", err)
 	}
 }
+
+// ────────────────────────────────────────────────────────────────────────────
+// WrapWebhookDelivery tests
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestWrapWebhookDelivery_TypeFromPayloadField(t *testing.T) {
+	body := []byte(`{"action":"opened","check_run":{"status":"completed"}}`)
+
+	if evt := WrapWebhookDelivery("github", body, nil, "$.action"); evt.Type != "github.opened" {
+		t.Errorf("$.action: Type = %q, want %q", evt.Type, "github.opened")
+	}
+	if evt := WrapWebhookDelivery("github", body, nil, "$.check_run.status"); evt.Type != "github.completed" {
+		t.Errorf("$.check_run.status: Type = %q, want %q", evt.Type, "github.completed")
+	}
+}
+
+func TestWrapWebhookDelivery_TypeFromHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-GitHub-Event", "pull_request")
+
+	evt := WrapWebhookDelivery("github", []byte(`{}`), h, "header:x-github-event")
+	if evt.Type != "github.pull_request" {
+		t.Errorf("Type = %q, want %q", evt.Type, "github.pull_request")
+	}
+}
+
+func TestWrapWebhookDelivery_FallsBackToDefault(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body     string
+		typeFrom string
+	}{
+		"unset":          {`{"action":"opened"}`, ""},
+		"field absent":   {`{"ref":"main"}`, "$.action"},
+		"not an object":  {`{"action":"opened"}`, "$.action.name"},
+		"object value":   {`{"action":{"name":"x"}}`, "$.action"},
+		"unsafe value":   {`{"action":"drop table; --"}`, "$.action"},
+		"non-JSON body":  {`hello`, "$.action"},
+		"header missing": {`{}`, "header:X-Event"},
+	} {
+		evt := WrapWebhookDelivery("hook", []byte(tc.body), http.Header{}, tc.typeFrom)
+		if evt.Type != "webhook.delivery" {
+			t.Errorf("%s: Type = %q, want webhook.delivery", name, evt.Type)
+		}
+	}
+}
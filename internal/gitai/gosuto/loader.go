@@ -64,6 +64,13 @@ func (l *Loader) Apply(data []byte) error {
 		"agent", cfg.Metadata.Name,
 		"hash", hash[:12],
 	)
+	if !cfg.Metadata.IsEnabled() {
+		slog.Warn("agent disabled by config; Matrix turns and events will be refused",
+			"agent", cfg.Metadata.Name)
+	}
+	for _, w := range gosutospec.Warnings(&cfg) {
+		slog.Warn("gosuto config warning", "field", w.Field, "warning", w.Message)
+	}
 	return nil
 }
 
@@ -0,0 +1,279 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAnthropicBase = "https://api.anthropic.com/v1"
+	// anthropicVersion is the Messages API version sent in every request.
+	anthropicVersion = "2023-06-01"
+	// defaultAnthropicMaxTokens is used when CompletionRequest.MaxTokens is
+	// zero; the Messages API requires max_tokens on every request.
+	defaultAnthropicMaxTokens = 4096
+)
+
+// AnthropicConfig configures the Anthropic Messages API adapter.
+type AnthropicConfig struct {
+	// APIKey is sent in the x-api-key header.
+	APIKey string
+	// BaseURL overrides the API endpoint. Defaults to
+	// https://api.anthropic.com/v1.
+	BaseURL string
+	// Model is the default model to use when CompletionRequest.Model is empty.
+	Model string
+	// Timeout for each HTTP request. Defaults to 120s.
+	Timeout time.Duration
+}
+
+// anthropicProvider implements Provider using the Anthropic Messages API.
+type anthropicProvider struct {
+	cfg  AnthropicConfig
+	http *http.Client
+}
+
+// NewAnthropic returns a Provider backed by the Anthropic Messages API.
+func NewAnthropic(cfg AnthropicConfig) Provider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultAnthropicBase
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 120 * time.Second
+	}
+	return &anthropicProvider{
+		cfg:  cfg,
+		http: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// anthropicRequest is the POST /messages request body.
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Tools     []anthropicTool    `json:"tools,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+// anthropicBlock is a content block: text, tool_use or tool_result.
+type anthropicBlock struct {
+	Type string `json:"type"`
+	// text
+	Text string `json:"text,omitempty"`
+	// tool_use
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+// anthropicResponse is the POST /messages response body, or an error
+// envelope when Type is "error".
+type anthropicResponse struct {
+	Type       string           `json:"type"`
+	Role       string           `json:"role"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Complete sends a Messages API request.
+func (p *anthropicProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = p.cfg.Model
+	}
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultAnthropicMaxTokens
+	}
+
+	system, messages, err := toAnthropicMessages(req.Messages)
+	if err != nil {
+		return nil, err
+	}
+	body := anthropicRequest{
+		Model:     model,
+		MaxTokens: maxTokens,
+		System:    system,
+		Messages:  messages,
+		Tools:     toAnthropicTools(req.Tools),
+	}
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.cfg.BaseURL, "/")+"/messages", bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("create http request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	if p.cfg.APIKey != "" {
+		httpReq.Header.Set("x-api-key", p.cfg.APIKey)
+	}
+
+	httpResp, err := p.http.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	var resp anthropicResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("decode response (status %d): %w", httpResp.StatusCode, err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("anthropic error %s: %s", resp.Error.Type, resp.Error.Message)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("anthropic returned status %d", httpResp.StatusCode)
+	}
+
+	msg := Message{Role: RoleAssistant}
+	var text []string
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text = append(text, block.Text)
+		case "tool_use":
+			args := string(block.Input)
+			if args == "" {
+				args = "{}"
+			}
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: FunctionCall{Name: block.Name, Arguments: args},
+			})
+		}
+	}
+	msg.Content = strings.Join(text, "")
+
+	return &CompletionResponse{
+		Message:      msg,
+		FinishReason: anthropicFinishReason(resp.StopReason),
+		Usage: TokenUsage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// anthropicFinishReason maps a Messages API stop_reason onto the
+// OpenAI-style finish reasons the turn loop understands.
+func anthropicFinishReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "end_turn", "stop_sequence", "":
+		return "stop"
+	default:
+		return stopReason
+	}
+}
+
+// toAnthropicMessages converts the turn history into a system prompt and
+// Messages API messages. System messages are joined into the top-level
+// system prompt; tool results become tool_result blocks in a user message;
+// consecutive messages with the same role are merged, since the API expects
+// user and assistant turns to alternate.
+func toAnthropicMessages(msgs []Message) (string, []anthropicMessage, error) {
+	var system []string
+	var out []anthropicMessage
+	add := func(role string, blocks ...anthropicBlock) {
+		if len(blocks) == 0 {
+			return
+		}
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content = append(out[n-1].Content, blocks...)
+			return
+		}
+		out = append(out, anthropicMessage{Role: role, Content: blocks})
+	}
+
+	for _, m := range msgs {
+		switch m.Role {
+		case RoleSystem:
+			if m.Content != "" {
+				system = append(system, m.Content)
+			}
+		case RoleTool:
+			add("user", anthropicBlock{Type: "tool_result", ToolUseID: m.ToolCallID, Content: m.Content})
+		case RoleAssistant:
+			var blocks []anthropicBlock
+			if m.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				input := json.RawMessage(tc.Function.Arguments)
+				if strings.TrimSpace(tc.Function.Arguments) == "" {
+					input = json.RawMessage("{}")
+				} else if !json.Valid(input) {
+					return "", nil, fmt.Errorf("tool call %s (%s) has invalid JSON arguments", tc.ID, tc.Function.Name)
+				}
+				blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: tc.ID, Name: tc.Function.Name, Input: input})
+			}
+			add("assistant", blocks...)
+		default:
+			if m.Content != "" {
+				add("user", anthropicBlock{Type: "text", Text: m.Content})
+			}
+		}
+	}
+	return strings.Join(system, "\n\n"), out, nil
+}
+
+// toAnthropicTools converts tool definitions to the Messages API format.
+// Every tool needs an input_schema, so a tool without parameters gets an
+// empty object schema.
+func toAnthropicTools(defs []ToolDefinition) []anthropicTool {
+	tools := make([]anthropicTool, 0, len(defs))
+	for _, d := range defs {
+		schema := d.Function.Parameters
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		tools = append(tools, anthropicTool{
+			Name:        d.Function.Name,
+			Description: d.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	return tools
+}
@@ -0,0 +1,167 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// anthropicStub records each Messages API request and answers with the next
+// canned response.
+type anthropicStub struct {
+	requests  []map[string]interface{}
+	responses []string
+}
+
+func (s *anthropicStub) serve(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("path = %q, want /v1/messages", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("x-api-key = %q", got)
+		}
+		if r.Header.Get("anthropic-version") == "" {
+			t.Error("anthropic-version header missing")
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		s.requests = append(s.requests, body)
+		resp := s.responses[0]
+		s.responses = s.responses[1:]
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAnthropic_ToolRoundTrip(t *testing.T) {
+	stub := &anthropicStub{responses: []string{
+		`{"type":"message","role":"assistant","content":[
+			{"type":"text","text":"Checking."},
+			{"type":"tool_use","id":"toolu_1","name":"weather__get_forecast","input":{"city":"Oslo"}}
+		],"stop_reason":"tool_use","usage":{"input_tokens":20,"output_tokens":7}}`,
+		`{"type":"message","role":"assistant","content":[{"type":"text","text":"Sunny in Oslo."}],
+		"stop_reason":"end_turn","usage":{"input_tokens":40,"output_tokens":5}}`,
+	}}
+	srv := stub.serve(t)
+	p := llm.NewAnthropic(llm.AnthropicConfig{APIKey: "test-key", BaseURL: srv.URL + "/v1", Model: "claude-test"})
+
+	tools := []llm.ToolDefinition{{
+		Type: "function",
+		Function: llm.FunctionDef{
+			Name:        "weather__get_forecast",
+			Description: "Get a forecast.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+			},
+		},
+	}}
+	history := []llm.Message{
+		{Role: llm.RoleSystem, Content: "You are helpful."},
+		{Role: llm.RoleUser, Content: "Weather in Oslo?"},
+	}
+
+	first, err := p.Complete(context.Background(), llm.CompletionRequest{Messages: history, Tools: tools})
+	if err != nil {
+		t.Fatalf("first Complete: %v", err)
+	}
+	if first.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", first.FinishReason)
+	}
+	if first.Message.Content != "Checking." || len(first.Message.ToolCalls) != 1 {
+		t.Fatalf("message = %+v, want text and one tool call", first.Message)
+	}
+	call := first.Message.ToolCalls[0]
+	if call.ID != "toolu_1" || call.Function.Name != "weather__get_forecast" || call.Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("tool call = %+v", call)
+	}
+	if first.Usage.TotalTokens != 27 {
+		t.Errorf("TotalTokens = %d, want 27", first.Usage.TotalTokens)
+	}
+
+	req := stub.requests[0]
+	if req["model"] != "claude-test" || req["system"] != "You are helpful." || req["max_tokens"].(float64) <= 0 {
+		t.Errorf("request model/system/max_tokens = %v / %v / %v", req["model"], req["system"], req["max_tokens"])
+	}
+	tool := req["tools"].([]interface{})[0].(map[string]interface{})
+	if tool["name"] != "weather__get_forecast" || tool["input_schema"] == nil {
+		t.Errorf("tool = %v, want name and input_schema", tool)
+	}
+
+	// Feed the tool result back as the turn loop does.
+	history = append(history, first.Message, llm.Message{
+		Role: llm.RoleTool, ToolCallID: call.ID, Name: call.Function.Name, Content: "sunny",
+	})
+	second, err := p.Complete(context.Background(), llm.CompletionRequest{Messages: history, Tools: tools})
+	if err != nil {
+		t.Fatalf("second Complete: %v", err)
+	}
+	if second.FinishReason != "stop" || second.Message.Content != "Sunny in Oslo." {
+		t.Errorf("second response = %q / %q", second.FinishReason, second.Message.Content)
+	}
+
+	msgs := stub.requests[1]["messages"].([]interface{})
+	if len(msgs) != 3 {
+		t.Fatalf("messages = %d, want user, assistant, user", len(msgs))
+	}
+	assistant := msgs[1].(map[string]interface{})
+	blocks := assistant["content"].([]interface{})
+	toolUse := blocks[len(blocks)-1].(map[string]interface{})
+	if assistant["role"] != "assistant" || toolUse["type"] != "tool_use" || toolUse["id"] != "toolu_1" {
+		t.Errorf("assistant message = %v, want a tool_use block", assistant)
+	}
+	if input := toolUse["input"].(map[string]interface{}); input["city"] != "Oslo" {
+		t.Errorf("tool_use input = %v", input)
+	}
+	result := msgs[2].(map[string]interface{})
+	resultBlock := result["content"].([]interface{})[0].(map[string]interface{})
+	if result["role"] != "user" || resultBlock["type"] != "tool_result" || resultBlock["tool_use_id"] != "toolu_1" || resultBlock["content"] != "sunny" {
+		t.Errorf("tool result message = %v", result)
+	}
+}
+
+func TestAnthropic_FinishReasons(t *testing.T) {
+	for stop, want := range map[string]string{
+		"end_turn":      "stop",
+		"stop_sequence": "stop",
+		"tool_use":      "tool_calls",
+		"max_tokens":    "length",
+	} {
+		stub := &anthropicStub{responses: []string{
+			`{"type":"message","role":"assistant","content":[],"stop_reason":"` + stop + `","usage":{}}`,
+		}}
+		srv := stub.serve(t)
+		p := llm.NewAnthropic(llm.AnthropicConfig{APIKey: "test-key", BaseURL: srv.URL + "/v1"})
+		resp, err := p.Complete(context.Background(), llm.CompletionRequest{Messages: []llm.Message{{Role: llm.RoleUser, Content: "hi"}}})
+		if err != nil {
+			t.Fatalf("%s: %v", stop, err)
+		}
+		if resp.FinishReason != want {
+			t.Errorf("stop_reason %q -> %q, want %q", stop, resp.FinishReason, want)
+		}
+	}
+}
+
+func TestAnthropic_SurfacesAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens too large"}}`))
+	}))
+	defer srv.Close()
+	p := llm.NewAnthropic(llm.AnthropicConfig{APIKey: "test-key", BaseURL: srv.URL})
+	_, err := p.Complete(context.Background(), llm.CompletionRequest{Messages: []llm.Message{{Role: llm.RoleUser, Content: "hi"}}})
+	if err == nil || !strings.Contains(err.Error(), "invalid_request_error") || !strings.Contains(err.Error(), "max_tokens too large") {
+		t.Errorf("err = %v, want the API error type and message", err)
+	}
+}
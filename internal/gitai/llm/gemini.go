@@ -0,0 +1,405 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultGeminiBase = "https://generativelanguage.googleapis.com/v1beta"
+
+// GeminiConfig configures the Google Gemini generateContent adapter.
+type GeminiConfig struct {
+	// APIKey is sent in the x-goog-api-key header.
+	APIKey string
+	// BaseURL overrides the API endpoint. Defaults to
+	// https://generativelanguage.googleapis.com/v1beta.
+	BaseURL string
+	// Model is the default model to use when CompletionRequest.Model is empty.
+	Model string
+	// Timeout for each HTTP request. Defaults to 120s.
+	Timeout time.Duration
+}
+
+// geminiProvider implements Provider using the Gemini generateContent API.
+type geminiProvider struct {
+	cfg  GeminiConfig
+	http *http.Client
+}
+
+// NewGemini returns a Provider backed by the Gemini generateContent API.
+func NewGemini(cfg GeminiConfig) Provider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultGeminiBase
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 120 * time.Second
+	}
+	return &geminiProvider{
+		cfg:  cfg,
+		http: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// geminiRequest is the generateContent request body.
+type geminiRequest struct {
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// geminiPart holds exactly one of text, a function call or a function
+// response.
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Parameters  interface{} `json:"parameters,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	MaxOutputTokens int `json:"maxOutputTokens,omitempty"`
+}
+
+// geminiResponse is the generateContent response body, or an error envelope.
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
+}
+
+// Complete sends a generateContent request.
+func (p *geminiProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = p.cfg.Model
+	}
+	model = strings.TrimPrefix(model, "models/")
+	if model == "" {
+		return nil, fmt.Errorf("gemini: no model configured")
+	}
+
+	system, contents, err := toGeminiContents(req.Messages)
+	if err != nil {
+		return nil, err
+	}
+	body := geminiRequest{
+		SystemInstruction: system,
+		Contents:          contents,
+		Tools:             toGeminiTools(req.Tools),
+	}
+	if req.MaxTokens > 0 {
+		body.GenerationConfig = &geminiGenerationConfig{MaxOutputTokens: req.MaxTokens}
+	}
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	endpoint := strings.TrimRight(p.cfg.BaseURL, "/") + "/models/" + url.PathEscape(model) + ":generateContent"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("create http request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.cfg.APIKey != "" {
+		httpReq.Header.Set("x-goog-api-key", p.cfg.APIKey)
+	}
+
+	httpResp, err := p.http.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	var resp geminiResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("decode response (status %d): %w", httpResp.StatusCode, err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("gemini error %s: %s", resp.Error.Status, resp.Error.Message)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gemini returned status %d", httpResp.StatusCode)
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("gemini returned no candidates")
+	}
+
+	candidate := resp.Candidates[0]
+	msg := Message{Role: RoleAssistant}
+	var text []string
+	for _, part := range candidate.Content.Parts {
+		switch {
+		case part.FunctionCall != nil:
+			// Older models do not return call IDs; synthesise a unique one
+			// so tool results can still be matched to their call.
+			id := part.FunctionCall.ID
+			if id == "" {
+				id = newGeminiCallID()
+			}
+			args := string(part.FunctionCall.Args)
+			if args == "" {
+				args = "{}"
+			}
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+				ID:       id,
+				Type:     "function",
+				Function: FunctionCall{Name: part.FunctionCall.Name, Arguments: args},
+			})
+		case part.Text != "":
+			text = append(text, part.Text)
+		}
+	}
+	msg.Content = strings.Join(text, "")
+
+	return &CompletionResponse{
+		Message:      msg,
+		FinishReason: geminiFinishReason(candidate.FinishReason, len(msg.ToolCalls) > 0),
+		Usage: TokenUsage{
+			PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+			CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+		},
+	}, nil
+}
+
+// geminiFinishReason maps a Gemini finishReason onto the OpenAI-style finish
+// reasons the turn loop understands. Gemini reports STOP even when the
+// candidate contains function calls, so the calls decide "tool_calls".
+func geminiFinishReason(reason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	switch reason {
+	case "STOP", "":
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return "content_filter"
+	default:
+		return strings.ToLower(reason)
+	}
+}
+
+// toGeminiContents converts the turn history into a system instruction and
+// Gemini contents. Gemini has no system role in contents, so system messages
+// go to systemInstruction; assistant messages use the "model" role; tool
+// results become functionResponse parts in a user turn. Consecutive messages
+// with the same role are merged into one turn.
+func toGeminiContents(msgs []Message) (*geminiContent, []geminiContent, error) {
+	var system []geminiPart
+	var out []geminiContent
+	// callNames resolves tool results that do not carry the tool name.
+	callNames := make(map[string]string)
+	add := func(role string, parts ...geminiPart) {
+		if len(parts) == 0 {
+			return
+		}
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Parts = append(out[n-1].Parts, parts...)
+			return
+		}
+		out = append(out, geminiContent{Role: role, Parts: parts})
+	}
+
+	for _, m := range msgs {
+		switch m.Role {
+		case RoleSystem:
+			if m.Content != "" {
+				system = append(system, geminiPart{Text: m.Content})
+			}
+		case RoleTool:
+			name := m.Name
+			if name == "" {
+				name = callNames[m.ToolCallID]
+			}
+			add("user", geminiPart{FunctionResponse: &geminiFunctionResponse{
+				Name:     name,
+				Response: geminiToolResponse(m.Content),
+			}})
+		case RoleAssistant:
+			var parts []geminiPart
+			if m.Content != "" {
+				parts = append(parts, geminiPart{Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				callNames[tc.ID] = tc.Function.Name
+				args := json.RawMessage(tc.Function.Arguments)
+				if strings.TrimSpace(tc.Function.Arguments) == "" {
+					args = json.RawMessage("{}")
+				} else if !json.Valid(args) {
+					return nil, nil, fmt.Errorf("tool call %s (%s) has invalid JSON arguments", tc.ID, tc.Function.Name)
+				}
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{Name: tc.Function.Name, Args: args}})
+			}
+			add("model", parts...)
+		default:
+			if m.Content != "" {
+				add("user", geminiPart{Text: m.Content})
+			}
+		}
+	}
+
+	var instruction *geminiContent
+	if len(system) > 0 {
+		instruction = &geminiContent{Parts: system}
+	}
+	return instruction, out, nil
+}
+
+// geminiToolResponse wraps a tool result for a functionResponse part, which
+// must be a JSON object. Results that already are objects pass through.
+func geminiToolResponse(content string) json.RawMessage {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	wrapped, _ := json.Marshal(map[string]string{"content": content})
+	return wrapped
+}
+
+// newGeminiCallID returns a random tool call ID.
+func newGeminiCallID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
+
+// toGeminiTools converts tool definitions into a single Gemini tool holding
+// all function declarations.
+func toGeminiTools(defs []ToolDefinition) []geminiTool {
+	if len(defs) == 0 {
+		return nil
+	}
+	decls := make([]geminiFunctionDeclaration, 0, len(defs))
+	for _, d := range defs {
+		decl := geminiFunctionDeclaration{Name: d.Function.Name, Description: d.Function.Description}
+		if d.Function.Parameters != nil {
+			decl.Parameters = geminiSchema(d.Function.Parameters)
+		}
+		decls = append(decls, decl)
+	}
+	return []geminiTool{{FunctionDeclarations: decls}}
+}
+
+// geminiSchemaKeys are the schema keywords Gemini function declarations
+// accept (a subset of OpenAPI 3.0). A request carrying any other keyword —
+// additionalProperties, $schema, oneOf, … which MCP servers commonly emit —
+// is rejected outright.
+var geminiSchemaKeys = map[string]bool{
+	"type": true, "format": true, "title": true, "description": true, "nullable": true,
+	"enum": true, "default": true, "example": true,
+	"properties": true, "required": true, "propertyOrdering": true, "minProperties": true, "maxProperties": true,
+	"items": true, "minItems": true, "maxItems": true,
+	"minLength": true, "maxLength": true, "pattern": true,
+	"minimum": true, "maximum": true,
+	"anyOf": true,
+}
+
+// geminiSchema returns a copy of a JSON Schema keeping only the keywords in
+// geminiSchemaKeys, at every depth. A type list such as ["string", "null"]
+// becomes a single type with nullable set, as Gemini expects.
+func geminiSchema(schema interface{}) interface{} {
+	m, ok := schema.(map[string]interface{})
+	if !ok {
+		// Schemas held as raw JSON or structs are normalised to a map first.
+		raw, err := json.Marshal(schema)
+		if err != nil || json.Unmarshal(raw, &m) != nil || m == nil {
+			return schema
+		}
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if !geminiSchemaKeys[k] {
+			continue
+		}
+		switch k {
+		case "properties":
+			if props, ok := v.(map[string]interface{}); ok {
+				clean := make(map[string]interface{}, len(props))
+				for name, p := range props {
+					clean[name] = geminiSchema(p)
+				}
+				v = clean
+			}
+		case "items":
+			v = geminiSchema(v)
+		case "anyOf":
+			if list, ok := v.([]interface{}); ok {
+				clean := make([]interface{}, len(list))
+				for i, sub := range list {
+					clean[i] = geminiSchema(sub)
+				}
+				v = clean
+			}
+		case "type":
+			if types, ok := v.([]interface{}); ok {
+				v = nil
+				for _, t := range types {
+					if t == "null" {
+						out["nullable"] = true
+					} else if v == nil {
+						v = t
+					}
+				}
+				if v == nil {
+					continue
+				}
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+// geminiStub records each generateContent request and answers with the next
+// canned response.
+type geminiStub struct {
+	requests  []map[string]interface{}
+	responses []string
+}
+
+func (s *geminiStub) serve(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-test:generateContent" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "test-key" {
+			t.Errorf("x-goog-api-key = %q", got)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		s.requests = append(s.requests, body)
+		resp := s.responses[0]
+		s.responses = s.responses[1:]
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func geminiFunctionCallResponse(name, args string) string {
+	return `{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"` + name + `","args":` + args + `}}]},
+		"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":3,"totalTokenCount":13}}`
+}
+
+func TestGemini_MultiRoundToolCalls(t *testing.T) {
+	stub := &geminiStub{responses: []string{
+		geminiFunctionCallResponse("geo__lookup", `{"city":"Oslo"}`),
+		geminiFunctionCallResponse("weather__get_forecast", `{"lat":59.9,"lon":10.7}`),
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"Sunny in Oslo."}]},"finishReason":"STOP"}],
+		"usageMetadata":{"promptTokenCount":30,"candidatesTokenCount":4,"totalTokenCount":34}}`,
+	}}
+	srv := stub.serve(t)
+	p := llm.NewGemini(llm.GeminiConfig{APIKey: "test-key", BaseURL: srv.URL + "/v1beta", Model: "gemini-test"})
+
+	tools := []llm.ToolDefinition{
+		{Type: "function", Function: llm.FunctionDef{Name: "geo__lookup", Description: "Find coordinates.",
+			Parameters: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}}}}},
+		{Type: "function", Function: llm.FunctionDef{Name: "weather__get_forecast", Description: "Get a forecast."}},
+	}
+	history := []llm.Message{
+		{Role: llm.RoleSystem, Content: "You are helpful."},
+		{Role: llm.RoleUser, Content: "Weather in Oslo?"},
+	}
+	results := []string{`{"lat":59.9,"lon":10.7}`, "sunny"}
+
+	for round := 0; ; round++ {
+		resp, err := p.Complete(context.Background(), llm.CompletionRequest{Messages: history, Tools: tools})
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if round == 2 {
+			if resp.FinishReason != "stop" || resp.Message.Content != "Sunny in Oslo." {
+				t.Errorf("final response = %q / %q", resp.FinishReason, resp.Message.Content)
+			}
+			break
+		}
+		if resp.FinishReason != "tool_calls" || len(resp.Message.ToolCalls) != 1 {
+			t.Fatalf("round %d: response = %q %+v, want one tool call", round, resp.FinishReason, resp.Message)
+		}
+		call := resp.Message.ToolCalls[0]
+		if call.ID == "" || call.Type != "function" {
+			t.Errorf("round %d: tool call = %+v, want an ID and function type", round, call)
+		}
+		history = append(history, resp.Message, llm.Message{
+			Role: llm.RoleTool, ToolCallID: call.ID, Name: call.Function.Name, Content: results[round],
+		})
+	}
+
+	first := stub.requests[0]
+	instruction := first["systemInstruction"].(map[string]interface{})
+	if text := instruction["parts"].([]interface{})[0].(map[string]interface{})["text"]; text != "You are helpful." {
+		t.Errorf("systemInstruction text = %v", text)
+	}
+	decls := first["tools"].([]interface{})[0].(map[string]interface{})["functionDeclarations"].([]interface{})
+	if len(decls) != 2 || decls[0].(map[string]interface{})["name"] != "geo__lookup" {
+		t.Errorf("functionDeclarations = %v", decls)
+	}
+
+	// The last request replays both rounds: user, model call, user
+	// response, model call, user response. No system role appears.
+	contents := stub.requests[2]["contents"].([]interface{})
+	var roles []string
+	for _, c := range contents {
+		roles = append(roles, c.(map[string]interface{})["role"].(string))
+	}
+	if got := strings.Join(roles, ","); got != "user,model,user,model,user" {
+		t.Fatalf("roles = %s", got)
+	}
+	call := contents[3].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["functionCall"].(map[string]interface{})
+	if call["name"] != "weather__get_forecast" || call["args"].(map[string]interface{})["lat"] != 59.9 {
+		t.Errorf("second functionCall = %v", call)
+	}
+	objResult := contents[2].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["functionResponse"].(map[string]interface{})
+	if objResult["name"] != "geo__lookup" || objResult["response"].(map[string]interface{})["lat"] != 59.9 {
+		t.Errorf("first functionResponse = %v, want the JSON object passed through", objResult)
+	}
+	textResult := contents[4].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["functionResponse"].(map[string]interface{})
+	if textResult["name"] != "weather__get_forecast" || textResult["response"].(map[string]interface{})["content"] != "sunny" {
+		t.Errorf("second functionResponse = %v, want the text wrapped in an object", textResult)
+	}
+}
+
+func TestGemini_StripsUnsupportedSchemaKeywords(t *testing.T) {
+	stub := &geminiStub{responses: []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}]}`,
+	}}
+	srv := stub.serve(t)
+	p := llm.NewGemini(llm.GeminiConfig{APIKey: "test-key", BaseURL: srv.URL + "/v1beta", Model: "gemini-test"})
+
+	schema := json.RawMessage(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["query"],
+		"properties": {
+			"query": {"type": "string", "description": "Search text.", "examples": ["go"]},
+			"tags": {"type": "array", "items": {"type": "string", "additionalProperties": false}},
+			"limit": {"type": ["integer", "null"], "minimum": 1, "exclusiveMinimum": 0},
+			"filter": {"type": "object", "additionalProperties": {"type": "string"},
+				"properties": {"additionalProperties": {"type": "boolean"}}}
+		}
+	}`)
+	tools := []llm.ToolDefinition{{Type: "function", Function: llm.FunctionDef{Name: "docs__search", Parameters: schema}}}
+	if _, err := p.Complete(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: "find go"}},
+		Tools:    tools,
+	}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	decl := stub.requests[0]["tools"].([]interface{})[0].(map[string]interface{})["functionDeclarations"].([]interface{})[0].(map[string]interface{})
+	got, _ := json.Marshal(decl["parameters"])
+	want := `{"properties":{"filter":{"properties":{"additionalProperties":{"type":"boolean"}},"type":"object"},` +
+		`"limit":{"minimum":1,"nullable":true,"type":"integer"},` +
+		`"query":{"description":"Search text.","type":"string"},` +
+		`"tags":{"items":{"type":"string"},"type":"array"}},"required":["query"],"type":"object"}`
+	if string(got) != want {
+		t.Errorf("parameters = %s\nwant       %s", got, want)
+	}
+}
+
+func TestGemini_SurfacesAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`))
+	}))
+	defer srv.Close()
+	p := llm.NewGemini(llm.GeminiConfig{APIKey: "bad", BaseURL: srv.URL, Model: "gemini-test"})
+	_, err := p.Complete(context.Background(), llm.CompletionRequest{Messages: []llm.Message{{Role: llm.RoleUser, Content: "hi"}}})
+	if err == nil || !strings.Contains(err.Error(), "INVALID_ARGUMENT") || !strings.Contains(err.Error(), "API key not valid") {
+		t.Errorf("err = %v, want the API error status and message", err)
+	}
+}
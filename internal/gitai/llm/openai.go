@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	openaicore "github.com/bdobrica/Ruriko/common/llm/openai"
@@ -49,6 +50,53 @@ func NewOpenAI(cfg OpenAIConfig) Provider {
 
 // Complete sends a chat completion request.
 func (p *openAIProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	body := p.chatRequest(req)
+
+	result, err := p.client.CreateChatCompletion(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	oaiResp := result.Response
+
+	if oaiResp.Error != nil {
+		return nil, fmt.Errorf("openai error %s: %s", oaiResp.Error.Type, oaiResp.Error.Message)
+	}
+
+	if len(oaiResp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response (status %d)", result.StatusCode)
+	}
+
+	choice := oaiResp.Choices[0]
+	msg := Message{
+		Role: Role(choice.Message.Role),
+	}
+	if s, ok := choice.Message.Content.(string); ok {
+		msg.Content = s
+	}
+	for _, tc := range choice.Message.ToolCalls {
+		msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+			ID:   tc.ID,
+			Type: tc.Type,
+			Function: FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.Arguments,
+			},
+		})
+	}
+
+	return &CompletionResponse{
+		Message:      msg,
+		FinishReason: choice.FinishReason,
+		Usage: TokenUsage{
+			PromptTokens:     oaiResp.Usage.PromptTokens,
+			CompletionTokens: oaiResp.Usage.CompletionTokens,
+			TotalTokens:      oaiResp.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// chatRequest converts req to a chat completions request body.
+func (p *openAIProvider) chatRequest(req CompletionRequest) openaicore.ChatCompletionRequest {
 	model := req.Model
 	if model == "" {
 		model = p.cfg.Model
@@ -91,52 +139,90 @@ func (p *openAIProvider) Complete(ctx context.Context, req CompletionRequest) (*
 		})
 	}
 
-	body := openaicore.ChatCompletionRequest{
+	return openaicore.ChatCompletionRequest{
 		Model:     model,
 		Messages:  oaiMessages,
 		Tools:     oaiTools,
 		MaxTokens: req.MaxTokens,
 	}
+}
 
-	result, err := p.client.CreateChatCompletion(ctx, body)
-	if err != nil {
-		return nil, err
-	}
-	oaiResp := result.Response
+// CompleteStream sends a streamed chat completion request. Text deltas are
+// forwarded as they arrive; tool-call fragments are assembled by index and
+// only returned in the final Response.
+func (p *openAIProvider) CompleteStream(ctx context.Context, req CompletionRequest) (<-chan StreamDelta, error) {
+	body := p.chatRequest(req)
+	out := make(chan StreamDelta, 16)
 
-	if oaiResp.Error != nil {
-		return nil, fmt.Errorf("openai error %s: %s", oaiResp.Error.Type, oaiResp.Error.Message)
-	}
+	go func() {
+		defer close(out)
+		// send gives up once ctx is cancelled so an abandoned stream never
+		// blocks this goroutine.
+		send := func(d StreamDelta) error {
+			select {
+			case out <- d:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
 
-	if len(oaiResp.Choices) == 0 {
-		return nil, fmt.Errorf("no choices in response (status %d)", result.StatusCode)
-	}
+		msg := Message{Role: RoleAssistant}
+		var content strings.Builder
+		var calls []ToolCall
+		var finishReason string
+		var usage TokenUsage
 
-	choice := oaiResp.Choices[0]
-	msg := Message{
-		Role: Role(choice.Message.Role),
-	}
-	if s, ok := choice.Message.Content.(string); ok {
-		msg.Content = s
-	}
-	for _, tc := range choice.Message.ToolCalls {
-		msg.ToolCalls = append(msg.ToolCalls, ToolCall{
-			ID:   tc.ID,
-			Type: tc.Type,
-			Function: FunctionCall{
-				Name:      tc.Function.Name,
-				Arguments: tc.Function.Arguments,
-			},
+		err := p.client.CreateChatCompletionStream(ctx, body, func(chunk openaicore.ChatCompletionChunk) error {
+			if chunk.Usage != nil {
+				usage = TokenUsage{
+					PromptTokens:     chunk.Usage.PromptTokens,
+					CompletionTokens: chunk.Usage.CompletionTokens,
+					TotalTokens:      chunk.Usage.TotalTokens,
+				}
+			}
+			if len(chunk.Choices) == 0 {
+				return nil
+			}
+			choice := chunk.Choices[0]
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+			for _, tc := range choice.Delta.ToolCalls {
+				for len(calls) <= tc.Index {
+					calls = append(calls, ToolCall{Type: "function"})
+				}
+				call := &calls[tc.Index]
+				if tc.ID != "" {
+					call.ID = tc.ID
+				}
+				if tc.Type != "" {
+					call.Type = tc.Type
+				}
+				call.Function.Name += tc.Function.Name
+				call.Function.Arguments += tc.Function.Arguments
+			}
+			if choice.Delta.Content == "" {
+				return nil
+			}
+			content.WriteString(choice.Delta.Content)
+			return send(StreamDelta{Content: choice.Delta.Content})
 		})
-	}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			_ = send(StreamDelta{Err: err})
+			return
+		}
 
-	return &CompletionResponse{
-		Message:      msg,
-		FinishReason: choice.FinishReason,
-		Usage: TokenUsage{
-			PromptTokens:     oaiResp.Usage.PromptTokens,
-			CompletionTokens: oaiResp.Usage.CompletionTokens,
-			TotalTokens:      oaiResp.Usage.TotalTokens,
-		},
-	}, nil
+		msg.Content = content.String()
+		msg.ToolCalls = calls
+		_ = send(StreamDelta{Response: &CompletionResponse{
+			Message:      msg,
+			FinishReason: finishReason,
+			Usage:        usage,
+		}})
+	}()
+	return out, nil
 }
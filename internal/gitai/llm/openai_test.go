@@ -0,0 +1,105 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+func TestOpenAI_CompleteStreamAssemblesToolCalls(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(
+			`data: {"choices":[{"delta":{"role":"assistant","content":"Let me "}}]}` + "\n\n" +
+				`data: {"choices":[{"delta":{"content":"check."}}]}` + "\n\n" +
+				`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather__get","arguments":"{\"ci"}}]}}]}` + "\n\n" +
+				`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ty\":\"Oslo\"}"}}]},"finish_reason":"tool_calls"}]}` + "\n\n" +
+				`data: {"choices":[],"usage":{"prompt_tokens":9,"completion_tokens":4,"total_tokens":13}}` + "\n\n" +
+				"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	p := llm.NewOpenAI(llm.OpenAIConfig{BaseURL: srv.URL, Model: "gpt-test"}).(llm.StreamingProvider)
+	deltas, err := p.CompleteStream(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: "Weather in Oslo?"}},
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream: %v", err)
+	}
+
+	var texts []string
+	var final *llm.CompletionResponse
+	for d := range deltas {
+		if d.Err != nil {
+			t.Fatalf("stream error: %v", d.Err)
+		}
+		if d.Response != nil {
+			final = d.Response
+			continue
+		}
+		texts = append(texts, d.Content)
+	}
+	if len(texts) != 2 || texts[0] != "Let me " || texts[1] != "check." {
+		t.Errorf("text deltas = %q", texts)
+	}
+	if final == nil {
+		t.Fatal("stream closed without a final response")
+	}
+	if final.FinishReason != "tool_calls" || final.Message.Content != "Let me check." || final.Usage.TotalTokens != 13 {
+		t.Errorf("final = %+v", final)
+	}
+	if len(final.Message.ToolCalls) != 1 {
+		t.Fatalf("tool calls = %+v, want one", final.Message.ToolCalls)
+	}
+	call := final.Message.ToolCalls[0]
+	if call.ID != "call_1" || call.Function.Name != "weather__get" || call.Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("tool call = %+v", call)
+	}
+}
+
+func TestOpenAI_CompleteStreamCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"choices":[{"delta":{"content":"partial"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := llm.NewOpenAI(llm.OpenAIConfig{BaseURL: srv.URL, Model: "gpt-test"}).(llm.StreamingProvider)
+	deltas, err := p.CompleteStream(ctx, llm.CompletionRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream: %v", err)
+	}
+	if d := <-deltas; d.Content != "partial" {
+		t.Fatalf("first delta = %+v", d)
+	}
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case d, ok := <-deltas:
+			if !ok {
+				return
+			}
+			if d.Response != nil {
+				t.Fatal("cancelled stream produced a response")
+			}
+			if d.Err != nil && !errors.Is(d.Err, context.Canceled) {
+				t.Errorf("err = %v, want context.Canceled", d.Err)
+			}
+		case <-timeout:
+			t.Fatal("stream did not close after cancel")
+		}
+	}
+}
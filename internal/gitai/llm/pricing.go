@@ -0,0 +1,59 @@
+package llm
+
+import "strings"
+
+// ModelPrice is a model's list price in USD per million tokens.
+type ModelPrice struct {
+	PromptPerMTok     float64
+	CompletionPerMTok float64
+}
+
+// DefaultModelPrice prices models missing from the table, so a cost budget
+// still applies to them.
+var DefaultModelPrice = ModelPrice{PromptPerMTok: 2.50, CompletionPerMTok: 10.00}
+
+// modelPrices maps model name prefixes to list prices. Lookups use the
+// longest matching prefix, so dated snapshots ("gpt-4o-2024-08-06") and
+// smaller variants ("gpt-4o-mini") resolve correctly.
+var modelPrices = map[string]ModelPrice{
+	"gpt-4o":            {2.50, 10.00},
+	"gpt-4o-mini":       {0.15, 0.60},
+	"gpt-4.1":           {2.00, 8.00},
+	"gpt-4.1-mini":      {0.40, 1.60},
+	"gpt-4.1-nano":      {0.10, 0.40},
+	"o3-mini":           {1.10, 4.40},
+	"claude-3-5-haiku":  {0.80, 4.00},
+	"claude-3-5-sonnet": {3.00, 15.00},
+	"claude-3-7-sonnet": {3.00, 15.00},
+	"claude-sonnet-4":   {3.00, 15.00},
+	"claude-3-opus":     {15.00, 75.00},
+	"claude-opus-4":     {15.00, 75.00},
+	"gemini-1.5-flash":  {0.075, 0.30},
+	"gemini-1.5-pro":    {1.25, 5.00},
+	"gemini-2.0-flash":  {0.10, 0.40},
+	"gemini-2.5-flash":  {0.30, 2.50},
+	"gemini-2.5-pro":    {1.25, 10.00},
+}
+
+// PriceFor returns the list price for model and whether the model is in
+// the price table; unknown models get DefaultModelPrice.
+func PriceFor(model string) (ModelPrice, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	best, found := "", false
+	for prefix := range modelPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best, found = prefix, true
+		}
+	}
+	if !found {
+		return DefaultModelPrice, false
+	}
+	return modelPrices[best], true
+}
+
+// EstimateCostUSD returns the estimated cost of one call to model with
+// usage u.
+func EstimateCostUSD(model string, u TokenUsage) float64 {
+	p, _ := PriceFor(model)
+	return (float64(u.PromptTokens)*p.PromptPerMTok + float64(u.CompletionTokens)*p.CompletionPerMTok) / 1e6
+}
@@ -0,0 +1,34 @@
+package llm_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/llm"
+)
+
+func TestPriceFor_LongestPrefix(t *testing.T) {
+	cases := []struct {
+		model string
+		want  llm.ModelPrice
+		known bool
+	}{
+		{"gpt-4o", llm.ModelPrice{PromptPerMTok: 2.50, CompletionPerMTok: 10.00}, true},
+		{"gpt-4o-mini-2024-07-18", llm.ModelPrice{PromptPerMTok: 0.15, CompletionPerMTok: 0.60}, true},
+		{"Claude-3-5-Sonnet-20241022", llm.ModelPrice{PromptPerMTok: 3.00, CompletionPerMTok: 15.00}, true},
+		{"llama3:8b", llm.DefaultModelPrice, false},
+	}
+	for _, tc := range cases {
+		got, known := llm.PriceFor(tc.model)
+		if got != tc.want || known != tc.known {
+			t.Errorf("PriceFor(%q) = %+v, %v; want %+v, %v", tc.model, got, known, tc.want, tc.known)
+		}
+	}
+}
+
+func TestEstimateCostUSD(t *testing.T) {
+	got := llm.EstimateCostUSD("gpt-4o", llm.TokenUsage{PromptTokens: 1000, CompletionTokens: 500})
+	if want := 0.0075; math.Abs(got-want) > 1e-12 {
+		t.Errorf("cost = %v, want %v", got, want)
+	}
+}
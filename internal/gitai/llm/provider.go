@@ -85,3 +85,28 @@ type Provider interface {
 	// (which may contain tool call requests).
 	Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error)
 }
+
+// StreamDelta is one increment of a streamed completion. Every delta but the
+// last carries Content; the last carries either Response, the fully
+// assembled completion (including any tool calls), or Err. The channel is
+// closed after it.
+type StreamDelta struct {
+	// Content is the text generated since the previous delta.
+	Content string
+	// Response is set on the final delta of a successful stream.
+	Response *CompletionResponse
+	// Err is set on the final delta of a failed or cancelled stream.
+	Err error
+}
+
+// StreamingProvider is implemented by providers that can stream a
+// completion as it is generated. It is separate from Provider so backends
+// without streaming keep working; callers type-assert the provider and fall
+// back to Complete.
+type StreamingProvider interface {
+	Provider
+	// CompleteStream starts a completion and returns a channel of deltas.
+	// Cancelling ctx aborts the stream; the final delta then carries the
+	// context error.
+	CompleteStream(ctx context.Context, req CompletionRequest) (<-chan StreamDelta, error)
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/bdobrica/Ruriko/common/matrixcore"
 	"maunium.net/go/mautrix/event"
@@ -111,6 +112,69 @@ func (c *Client) SendReply(roomID, replyToEventID, text string) error {
 	return c.core.SendMessageEvent(context.Background(), id.RoomID(roomID), event.EventMessage, content)
 }
 
+// SendReplyEvent sends a reply like SendReply and returns the event ID of
+// the new message, so it can later be edited with EditText.
+func (c *Client) SendReplyEvent(roomID, replyToEventID, text string) (string, error) {
+	content := event.MessageEventContent{
+		MsgType: event.MsgText,
+		Body:    text,
+		RelatesTo: &event.RelatesTo{
+			InReplyTo: &event.InReplyTo{EventID: id.EventID(replyToEventID)},
+		},
+	}
+	evtID, err := c.core.SendMessageEventID(context.Background(), id.RoomID(roomID), event.EventMessage, content)
+	if err != nil {
+		return "", err
+	}
+	return evtID.String(), nil
+}
+
+// EditText replaces the text of a message previously sent by this client
+// (an m.replace edit).
+func (c *Client) EditText(roomID, eventID, text string) error {
+	content := event.MessageEventContent{
+		MsgType: event.MsgText,
+		Body:    text,
+	}
+	content.SetEdit(id.EventID(eventID))
+	return c.core.SendMessageEvent(context.Background(), id.RoomID(roomID), event.EventMessage, content)
+}
+
+// Upload stores data in the homeserver's media repository and returns its
+// mxc:// URI.
+func (c *Client) Upload(data []byte, contentType, fileName string) (string, error) {
+	uri, err := c.core.UploadBytes(context.Background(), data, contentType, fileName)
+	if err != nil {
+		return "", fmt.Errorf("upload media: %w", err)
+	}
+	return uri.String(), nil
+}
+
+// SendFile posts a previously uploaded file to the given room. Images
+// (image/* content types) are sent as m.image so clients render them inline;
+// everything else is sent as m.file.
+func (c *Client) SendFile(roomID, mxcURI, fileName, contentType string, size int) error {
+	uri, err := id.ParseContentURI(mxcURI)
+	if err != nil {
+		return fmt.Errorf("invalid content URI %q: %w", mxcURI, err)
+	}
+	msgType := event.MsgFile
+	if strings.HasPrefix(contentType, "image/") {
+		msgType = event.MsgImage
+	}
+	content := event.MessageEventContent{
+		MsgType:  msgType,
+		Body:     fileName,
+		FileName: fileName,
+		URL:      uri.CUString(),
+		Info: &event.FileInfo{
+			MimeType: contentType,
+			Size:     size,
+		},
+	}
+	return c.core.SendMessageEvent(context.Background(), id.RoomID(roomID), event.EventMessage, content)
+}
+
 // join joins a room, ignoring "already joined" errors.
 func (c *Client) join(roomID id.RoomID) error {
 	err := c.core.JoinRoomByID(context.Background(), roomID)
@@ -0,0 +1,105 @@
+package matrix
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeHomeserver serves the media upload and room send endpoints and
+// records what it receives.
+type fakeHomeserver struct {
+	mu          sync.Mutex
+	uploads     [][]byte
+	uploadTypes []string
+	events      []map[string]interface{}
+}
+
+func (f *fakeHomeserver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/_matrix/media/v3/upload"):
+		body, _ := io.ReadAll(r.Body)
+		f.uploads = append(f.uploads, body)
+		f.uploadTypes = append(f.uploadTypes, r.Header.Get("Content-Type"))
+		_, _ = w.Write([]byte(`{"content_uri":"mxc://example.com/media123"}`))
+	case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/send/m.room.message/"):
+		var content map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&content)
+		f.events = append(f.events, content)
+		_, _ = w.Write([]byte(`{"event_id":"$evt"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeHomeserver) {
+	t.Helper()
+	hs := &fakeHomeserver{}
+	srv := httptest.NewServer(hs)
+	t.Cleanup(srv.Close)
+	c, err := New(&Config{Homeserver: srv.URL, UserID: "@bot:example.com", AccessToken: "tok"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c, hs
+}
+
+func TestClient_UploadAndSendImage(t *testing.T) {
+	c, hs := newTestClient(t)
+
+	png := []byte("\x89PNG fake image")
+	mxc, err := c.Upload(png, "image/png", "chart.png")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if mxc != "mxc://example.com/media123" {
+		t.Errorf("Upload = %q, want the homeserver's content URI", mxc)
+	}
+	if err := c.SendFile("!room:example.com", mxc, "chart.png", "image/png", len(png)); err != nil {
+		t.Fatalf("SendFile: %v", err)
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if len(hs.uploads) != 1 || string(hs.uploads[0]) != string(png) || hs.uploadTypes[0] != "image/png" {
+		t.Errorf("uploads = %q (%v), want the PNG bytes as image/png", hs.uploads, hs.uploadTypes)
+	}
+	if len(hs.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(hs.events))
+	}
+	evt := hs.events[0]
+	if evt["msgtype"] != "m.image" || evt["url"] != mxc || evt["body"] != "chart.png" {
+		t.Errorf("event = %v, want m.image referencing the upload", evt)
+	}
+	info, _ := evt["info"].(map[string]interface{})
+	if info["mimetype"] != "image/png" || info["size"] != float64(len(png)) {
+		t.Errorf("event info = %v, want mimetype and size", info)
+	}
+}
+
+func TestClient_SendFile_NonImageIsMFile(t *testing.T) {
+	c, hs := newTestClient(t)
+
+	if err := c.SendFile("!room:example.com", "mxc://example.com/doc", "report.pdf", "application/pdf", 10); err != nil {
+		t.Fatalf("SendFile: %v", err)
+	}
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if len(hs.events) != 1 || hs.events[0]["msgtype"] != "m.file" {
+		t.Errorf("events = %v, want one m.file event", hs.events)
+	}
+}
+
+func TestClient_SendFile_RejectsBadURI(t *testing.T) {
+	c, _ := newTestClient(t)
+	if err := c.SendFile("!room:example.com", "https://example.com/x", "x", "text/plain", 1); err == nil {
+		t.Error("expected error for a non-mxc URI")
+	}
+}
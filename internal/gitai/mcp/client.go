@@ -10,8 +10,14 @@ import (
 	"os/exec"
 	"sync"
 	"sync/atomic"
+	"syscall"
+	"time"
 )
 
+// DefaultShutdownGrace is how long Close waits for the MCP process to exit
+// after SIGTERM before killing it.
+const DefaultShutdownGrace = 5 * time.Second
+
 // Client communicates with a single MCP server process over stdin/stdout using
 // JSON-RPC 2.0 (newline-delimited).
 type Client struct {
@@ -23,12 +29,27 @@ type Client struct {
 
 	pending map[int64]chan *Response
 	pendMu  sync.Mutex
+
+	// callSlots bounds concurrent tools/call requests; nil means unlimited.
+	callSlots atomic.Pointer[chan struct{}]
+
+	exited  chan struct{} // closed once the process has exited
+	exitErr error         // result of cmd.Wait; set before exited closes
 }
 
-// NewClient starts the MCP process described by command+args+env and performs
-// the initial MCP handshake. The Client is ready to call ListTools / CallTool
-// once New returns without error.
-func NewClient(ctx context.Context, name, command string, args []string, env []string) (*Client, error) {
+// NewClient starts the MCP process described by command+args+env under
+// limits, and performs the initial MCP handshake. The Client is ready to call
+// ListTools / CallTool once New returns without error. Limits are set before
+// the server executes (see RunLimitsWrapper); limits the platform cannot
+// apply are logged and skipped.
+func NewClient(ctx context.Context, name, command string, args []string, env []string, limits ProcessLimits) (*Client, error) {
+	if !limits.IsZero() {
+		if wrapper, wrapperArgs, err := limitedCommand(command, args, limits); err != nil {
+			slog.Warn("mcp server resource limits not applied", "name", name, "err", err)
+		} else {
+			command, args = wrapper, wrapperArgs
+		}
+	}
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Env = env
 
@@ -52,7 +73,12 @@ func NewClient(ctx context.Context, name, command string, args []string, env []s
 		cmd:     cmd,
 		stdin:   stdin,
 		pending: make(map[int64]chan *Response),
+		exited:  make(chan struct{}),
 	}
+	go func() {
+		c.exitErr = cmd.Wait()
+		close(c.exited)
+	}()
 
 	// Start reader goroutine.
 	go c.readLoop(stdout)
@@ -94,8 +120,33 @@ func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
 	return result.Tools, nil
 }
 
-// CallTool invokes a named tool with the given arguments.
+// LimitConcurrentCalls caps the number of CallTool requests in flight at
+// once; calls beyond it wait for a free slot or for their context to end.
+// n <= 0 removes the cap. Calls already in flight keep the slot they hold, so
+// right after a change the total may briefly exceed the new cap.
+func (c *Client) LimitConcurrentCalls(n int) {
+	if n <= 0 {
+		c.callSlots.Store(nil)
+		return
+	}
+	if cur := c.callSlots.Load(); cur != nil && cap(*cur) == n {
+		return
+	}
+	slots := make(chan struct{}, n)
+	c.callSlots.Store(&slots)
+}
+
+// CallTool invokes a named tool with the given arguments. When a concurrency
+// cap is set (see LimitConcurrentCalls) it first waits for a free slot.
 func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (*CallToolResult, error) {
+	if slots := c.callSlots.Load(); slots != nil {
+		select {
+		case *slots <- struct{}{}:
+			defer func() { <-*slots }()
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for a free call slot on mcp %s: %w", c.name, ctx.Err())
+		}
+	}
 	var result CallToolResult
 	if err := c.call(ctx, "tools/call", CallToolParams{Name: toolName, Arguments: args}, &result); err != nil {
 		return nil, err
@@ -103,10 +154,61 @@ func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]
 	return &result, nil
 }
 
-// Close shuts down the MCP process.
+// ListResources returns the resources exposed by this MCP server.
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var result ListResourcesResult
+	if err := c.call(ctx, "resources/list", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Resources, nil
+}
+
+// ReadResource reads the resource identified by uri.
+func (c *Client) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	var result ReadResourceResult
+	if err := c.call(ctx, "resources/read", ReadResourceParams{URI: uri}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Done returns a channel that is closed when the MCP process exits, whether
+// it crashed or was shut down.
+func (c *Client) Done() <-chan struct{} { return c.exited }
+
+// ExitErr returns how the process exited: nil for a zero exit status,
+// otherwise the *exec.ExitError or wait error. Only meaningful once Done is
+// closed.
+func (c *Client) ExitErr() error {
+	select {
+	case <-c.exited:
+		return c.exitErr
+	default:
+		return nil
+	}
+}
+
+// Close shuts down the MCP process, allowing it DefaultShutdownGrace to
+// exit cleanly. See Shutdown.
 func (c *Client) Close() error {
+	return c.Shutdown(DefaultShutdownGrace)
+}
+
+// Shutdown stops the MCP process gracefully: it closes stdin, sends SIGTERM
+// and waits up to grace for the process to exit, so a server can finish
+// in-flight writes. A process still running after grace is killed.
+func (c *Client) Shutdown(grace time.Duration) error {
 	c.stdin.Close()
-	return c.cmd.Wait()
+	_ = c.cmd.Process.Signal(syscall.SIGTERM)
+
+	select {
+	case <-c.exited:
+	case <-time.After(grace):
+		slog.Warn("mcp server did not exit after SIGTERM; killing", "name", c.name, "grace", grace)
+		_ = c.cmd.Process.Kill()
+		<-c.exited
+	}
+	return c.exitErr
 }
 
 // --- internal ---
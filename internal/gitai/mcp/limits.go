@@ -0,0 +1,37 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+)
+
+// ProcessLimits are resource limits an MCP server process runs under from
+// its first instruction. Zero fields leave the corresponding limit unchanged.
+type ProcessLimits struct {
+	// MaxMemoryMB caps the process address space (RLIMIT_AS) in MiB.
+	MaxMemoryMB int
+	// Niceness is the CPU scheduling niceness, 0 (unchanged) to 19.
+	Niceness int
+}
+
+// IsZero reports whether no limit is set.
+func (l ProcessLimits) IsZero() bool {
+	return l == ProcessLimits{}
+}
+
+// limitsWrapperArg is the first argument of a gitai process that NewClient
+// re-executes to set ProcessLimits on itself before it execs the MCP server.
+const limitsWrapperArg = "__gitai-mcp-limits"
+
+// RunLimitsWrapper returns immediately unless the process was started by
+// NewClient as the limits wrapper; then it applies the limits, execs the MCP
+// server in its place and never returns. Binaries that start MCP servers with
+// limits must call it first thing in main.
+func RunLimitsWrapper() {
+	if len(os.Args) < 2 || os.Args[1] != limitsWrapperArg {
+		return
+	}
+	err := execWithLimits(os.Args[2:])
+	fmt.Fprintf(os.Stderr, "gitai: start mcp server with resource limits: %v\n", err)
+	os.Exit(127)
+}
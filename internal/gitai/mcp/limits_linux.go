@@ -0,0 +1,58 @@
+//go:build linux
+
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// limitedCommand returns the command line that starts command with args
+// under l: the running gitai binary, re-executed as the limits wrapper.
+func limitedCommand(command string, args []string, l ProcessLimits) (string, []string, error) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return "", nil, err
+	}
+	wrapped := []string{limitsWrapperArg, strconv.Itoa(l.MaxMemoryMB), strconv.Itoa(l.Niceness), path, command}
+	return "/proc/self/exe", append(wrapped, args...), nil
+}
+
+// execWithLimits is the limits wrapper. args are the memory limit, the
+// niceness, the server's path and its argv; the limits are set on this
+// process, which then execs the server. It returns only on failure.
+func execWithLimits(args []string) error {
+	if len(args) < 4 {
+		return errors.New("missing wrapper arguments")
+	}
+	maxMemoryMB, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("memory limit: %w", err)
+	}
+	niceness, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("niceness: %w", err)
+	}
+
+	// Linux keeps niceness per thread and execve keeps only the calling
+	// thread, so set it on the thread that execs.
+	runtime.LockOSThread()
+	if niceness != 0 {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, 0, niceness); err != nil {
+			return fmt.Errorf("set niceness: %w", err)
+		}
+	}
+	if maxMemoryMB > 0 {
+		n := uint64(maxMemoryMB) << 20
+		if err := unix.Setrlimit(unix.RLIMIT_AS, &unix.Rlimit{Cur: n, Max: n}); err != nil {
+			return fmt.Errorf("set memory limit: %w", err)
+		}
+	}
+	return unix.Exec(args[2], args[3:], os.Environ())
+}
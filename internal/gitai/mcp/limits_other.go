@@ -0,0 +1,17 @@
+//go:build !linux
+
+package mcp
+
+import "errors"
+
+var errLimitsUnsupported = errors.New("process resource limits are only supported on Linux")
+
+// limitedCommand reports that limits cannot be applied outside Linux.
+func limitedCommand(_ string, _ []string, _ ProcessLimits) (string, []string, error) {
+	return "", nil, errLimitsUnsupported
+}
+
+// execWithLimits is never reached outside Linux.
+func execWithLimits(_ []string) error {
+	return errLimitsUnsupported
+}
@@ -63,7 +63,8 @@ type ServerInfo struct {
 
 // ServerCaps describes server-side MCP capabilities.
 type ServerCaps struct {
-	Tools *struct{} `json:"tools,omitempty"`
+	Tools     *struct{} `json:"tools,omitempty"`
+	Resources *struct{} `json:"resources,omitempty"`
 }
 
 // ListToolsResult is returned by tools/list.
@@ -97,3 +98,35 @@ type ContentItem struct {
 	Data string `json:"data,omitempty"` // base64 for images
 	MIME string `json:"mimeType,omitempty"`
 }
+
+// ListResourcesResult is returned by resources/list.
+type ListResourcesResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+// Resource describes a single readable MCP resource.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	MIME        string `json:"mimeType,omitempty"`
+}
+
+// ReadResourceParams is sent to read a resource.
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ReadResourceResult holds the contents of a read resource.
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourceContents is one part of a resource's contents. Exactly one of
+// Text or Blob (base64) is set.
+type ResourceContents struct {
+	URI  string `json:"uri"`
+	MIME string `json:"mimeType,omitempty"`
+	Text string `json:"text,omitempty"`
+	Blob string `json:"blob,omitempty"`
+}
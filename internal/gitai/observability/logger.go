@@ -6,36 +6,111 @@ package observability
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
+	"sync"
 
 	"github.com/bdobrica/Ruriko/common/redact"
 	"github.com/bdobrica/Ruriko/common/trace"
 )
 
+// level is the global log level; SetLevel changes it without rebuilding the
+// handler.
+var level slog.LevelVar
+
+// Options are the optional handler wrappers applied by Setup.
+type Options struct {
+	// RedactSecrets scrubs well-known credential formats from every record.
+	RedactSecrets bool
+	// SampleEvery emits 1 in SampleEvery INFO records for each message in
+	// SampleMessages. Values <= 1 disable sampling.
+	SampleEvery int
+	// SampleMessages are the INFO messages subject to sampling; nil selects
+	// DefaultSampleMessages.
+	SampleMessages []string
+
+	// File, when set, also writes logs to this path, rotating it at
+	// FileMaxBytes and keeping FileMaxBackups rotated files.
+	File           string
+	FileMaxBytes   int64
+	FileMaxBackups int
+	// FileOnly stops writing to stdout when File is set.
+	FileOnly bool
+}
+
+// logFile is the file opened by the last Setup, closed when Setup runs again.
+var (
+	logFileMu sync.Mutex
+	logFile   *RotatingFile
+)
+
 // Setup configures the global slog logger according to the provided level and
-// format strings (e.g. level="info", format="json").
-func Setup(level, format string) {
-	var lvl slog.Level
-	switch level {
-	case "debug":
-		lvl = slog.LevelDebug
-	case "warn":
-		lvl = slog.LevelWarn
-	case "error":
-		lvl = slog.LevelError
-	default:
-		lvl = slog.LevelInfo
+// format strings (e.g. level="info", format="json") and options. It fails
+// only when o.File cannot be opened, leaving the previous logger in place.
+func Setup(lvl, format string, o Options) error {
+	var out io.Writer = os.Stdout
+	var file *RotatingFile
+	if o.File != "" {
+		var err error
+		file, err = OpenRotatingFile(o.File, o.FileMaxBytes, o.FileMaxBackups)
+		if err != nil {
+			return err
+		}
+		out = file
+		if !o.FileOnly {
+			out = io.MultiWriter(os.Stdout, file)
+		}
 	}
+	SetLevel(lvl)
 
-	opts := &slog.HandlerOptions{Level: lvl}
+	opts := &slog.HandlerOptions{Level: &level}
 	var handler slog.Handler
 	if format == "json" {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(out, opts)
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(out, opts)
+	}
+	if o.RedactSecrets {
+		handler = NewRedactHandler(handler)
+	}
+	msgs := o.SampleMessages
+	if msgs == nil {
+		msgs = DefaultSampleMessages
 	}
+	handler = NewSampleHandler(handler, o.SampleEvery, msgs)
 	slog.SetDefault(slog.New(handler))
+
+	logFileMu.Lock()
+	prev := logFile
+	logFile = file
+	logFileMu.Unlock()
+	if prev != nil {
+		if err := prev.Close(); err != nil {
+			slog.Warn("could not close previous log file", "err", err)
+		}
+	}
+	return nil
+}
+
+// SetLevel changes the global log level at runtime. Unrecognised names select
+// "info".
+func SetLevel(name string) {
+	switch name {
+	case "debug":
+		level.Set(slog.LevelDebug)
+	case "warn":
+		level.Set(slog.LevelWarn)
+	case "error":
+		level.Set(slog.LevelError)
+	default:
+		level.Set(slog.LevelInfo)
+	}
+}
+
+// Level returns the current global log level.
+func Level() slog.Level {
+	return level.Level()
 }
 
 // WithTrace returns a child logger that always includes the trace_id from ctx.
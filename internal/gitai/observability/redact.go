@@ -0,0 +1,71 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/bdobrica/Ruriko/common/redact"
+)
+
+// redactHandler wraps a slog.Handler and scrubs well-known credential
+// formats (see redact.Secrets) from the message and from every attribute
+// before the record reaches the underlying handler.
+type redactHandler struct {
+	next slog.Handler
+}
+
+// NewRedactHandler returns a handler that redacts secret-looking values from
+// records and passes them on to next.
+func NewRedactHandler(next slog.Handler) slog.Handler {
+	return &redactHandler{next: next}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.next.Enabled(ctx, lvl)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, redact.Secrets(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = redactAttr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(out)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr returns a with secret-looking values replaced. Strings are
+// scrubbed in place; groups are walked recursively; any other value
+// (errors, maps, structs) is formatted and, only if that text contains a
+// secret, replaced by its redacted string form.
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redact.Secrets(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		out := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			out[i] = redactAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(out...)}
+	case slog.KindAny:
+		if s := fmt.Sprint(v.Any()); redact.ContainsSecret(s, false) {
+			return slog.String(a.Key, redact.Secrets(s))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,103 @@
+package observability_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/observability"
+)
+
+func TestSetup_WritesLogFile(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() {
+		_ = observability.Setup("info", "text", observability.Options{})
+		slog.SetDefault(prev)
+	})
+	path := filepath.Join(t.TempDir(), "logs", "gitai.log")
+
+	if err := observability.Setup("info", "json", observability.Options{File: path, FileOnly: true}); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	slog.Info("agent started", "agent", "kairo")
+	slog.Debug("below the level")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	out := string(data)
+	if !strings.Contains(out, `"msg":"agent started"`) || !strings.Contains(out, `"agent":"kairo"`) {
+		t.Errorf("log file = %q, want the info line", out)
+	}
+	if strings.Contains(out, "below the level") {
+		t.Errorf("log file = %q, want debug lines filtered", out)
+	}
+}
+
+func TestSetup_BadLogFileFails(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := observability.Setup("info", "text", observability.Options{File: filepath.Join(blocker, "gitai.log")}); err == nil {
+		t.Fatal("expected an error for a log path under a regular file")
+	}
+}
+
+func TestRotatingFile_RotatesAtSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitai.log")
+	f, err := observability.OpenRotatingFile(path, 100, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	line := strings.Repeat("x", 39) + "\n" // 40 bytes: two lines fit per file
+	for i := 0; i < 7; i++ {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write %d: %v", i, err)
+		}
+	}
+
+	for name, want := range map[string]int{path: 40, path + ".1": 80, path + ".2": 80} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("stat %s: %v", name, err)
+		}
+		if info.Size() != int64(want) {
+			t.Errorf("%s is %d bytes, want %d", filepath.Base(name), info.Size(), want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept, stat .3: %v", err)
+	}
+}
+
+func TestRotatingFile_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitai.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("y", 90)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := observability.OpenRotatingFile(path, 100, 1)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	if _, err := f.Write([]byte("new line\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := f.Write([]byte("rotates\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "rotates\n" {
+		t.Errorf("current file = %q, want only the line written after rotation", data)
+	}
+	if data, _ := os.ReadFile(path + ".1"); !strings.HasSuffix(string(data), "yyy"+"new line\n") {
+		t.Errorf("backup = %q, want the existing content plus the first line", data)
+	}
+}
@@ -0,0 +1,78 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// DefaultSampleMessages are the high-volume INFO lines sampled when sampling
+// is on and no explicit message list is configured.
+var DefaultSampleMessages = []string{"event received", "event processed"}
+
+// sampleHandler wraps a slog.Handler and passes on only the first of every
+// `every` INFO records per sampled message. Records at any other level, and
+// INFO records with other messages, always pass through.
+type sampleHandler struct {
+	next  slog.Handler
+	state *sampleState
+}
+
+// sampleState is shared by a handler and every handler derived from it via
+// WithAttrs/WithGroup, so loggers built with slog.With count together.
+type sampleState struct {
+	every    uint64
+	messages map[string]bool
+
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// NewSampleHandler returns a handler that emits 1 in every INFO records whose
+// message is one of messages. every <= 1 or an empty message list disables
+// sampling and returns next unchanged.
+func NewSampleHandler(next slog.Handler, every int, messages []string) slog.Handler {
+	if every <= 1 || len(messages) == 0 {
+		return next
+	}
+	st := &sampleState{
+		every:    uint64(every),
+		messages: make(map[string]bool, len(messages)),
+		counts:   make(map[string]uint64, len(messages)),
+	}
+	for _, m := range messages {
+		st.messages[m] = true
+	}
+	return &sampleHandler{next: next, state: st}
+}
+
+func (h *sampleHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.next.Enabled(ctx, lvl)
+}
+
+func (h *sampleHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelInfo && !h.state.keep(r.Message) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *sampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampleHandler{next: h.next.WithAttrs(attrs), state: h.state}
+}
+
+func (h *sampleHandler) WithGroup(name string) slog.Handler {
+	return &sampleHandler{next: h.next.WithGroup(name), state: h.state}
+}
+
+// keep reports whether the next record with message msg should be emitted.
+func (s *sampleState) keep(msg string) bool {
+	if !s.messages[msg] {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.counts[msg]
+	s.counts[msg] = n + 1
+	return n%s.every == 0
+}
@@ -0,0 +1,70 @@
+package observability_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/observability"
+)
+
+func newSampleLogger(buf *bytes.Buffer, every int) *slog.Logger {
+	base := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(observability.NewSampleHandler(base, every, observability.DefaultSampleMessages))
+}
+
+func TestSampleHandler_SamplesInfoAtRate(t *testing.T) {
+	var buf bytes.Buffer
+	log := newSampleLogger(&buf, 10)
+
+	for i := 0; i < 100; i++ {
+		log.Info("event received", "i", i)
+		log.With("source", "cron").Info("event processed", "i", i)
+		log.Info("mcp server ready")
+	}
+
+	out := buf.String()
+	if got := strings.Count(out, `msg="event received"`); got != 10 {
+		t.Errorf("event received lines = %d, want 10", got)
+	}
+	if got := strings.Count(out, `msg="event processed"`); got != 10 {
+		t.Errorf("event processed lines = %d, want 10", got)
+	}
+	if got := strings.Count(out, `msg="mcp server ready"`); got != 100 {
+		t.Errorf("unsampled message lines = %d, want 100", got)
+	}
+	if !strings.Contains(out, `msg="event received" i=0`) || !strings.Contains(out, `msg="event received" i=90`) {
+		t.Errorf("expected the first of every 10 records to be kept:\n%s", out)
+	}
+}
+
+func TestSampleHandler_KeepsWarningsAndErrors(t *testing.T) {
+	var buf bytes.Buffer
+	log := newSampleLogger(&buf, 10)
+
+	for i := 0; i < 20; i++ {
+		log.Warn("event received")
+		log.Error("event processed", "status", "error")
+	}
+
+	out := buf.String()
+	if got := strings.Count(out, "level=WARN"); got != 20 {
+		t.Errorf("warn lines = %d, want 20", got)
+	}
+	if got := strings.Count(out, "level=ERROR"); got != 20 {
+		t.Errorf("error lines = %d, want 20", got)
+	}
+}
+
+func TestSampleHandler_DisabledForEveryOne(t *testing.T) {
+	var buf bytes.Buffer
+	log := newSampleLogger(&buf, 1)
+
+	for i := 0; i < 5; i++ {
+		log.Info("event received")
+	}
+	if got := strings.Count(buf.String(), `msg="event received"`); got != 5 {
+		t.Errorf("lines = %d, want 5 with sampling disabled", got)
+	}
+}
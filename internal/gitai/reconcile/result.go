@@ -0,0 +1,63 @@
+// Package reconcile describes what a supervisor changed when it reconciled
+// its running processes (MCP servers, gateways) against a Gosuto config.
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Failure is an entry that was wanted but could not be started.
+type Failure struct {
+	Name string
+	Err  string
+}
+
+// Result lists the entries a Reconcile call started, stopped, restarted
+// (stopped and started again because their spec changed) or failed to
+// start. Entries left untouched are not listed.
+type Result struct {
+	Started   []string
+	Stopped   []string
+	Restarted []string
+	Failed    []Failure
+}
+
+// Empty reports whether the reconcile changed nothing and nothing failed.
+func (r Result) Empty() bool {
+	return len(r.Started) == 0 && len(r.Stopped) == 0 && len(r.Restarted) == 0 && len(r.Failed) == 0
+}
+
+// Sort orders every list by name so results are deterministic despite map
+// iteration in the supervisors.
+func (r *Result) Sort() {
+	sort.Strings(r.Started)
+	sort.Strings(r.Stopped)
+	sort.Strings(r.Restarted)
+	sort.Slice(r.Failed, func(i, j int) bool { return r.Failed[i].Name < r.Failed[j].Name })
+}
+
+// String renders the non-empty lists, e.g. "started=[a b] failed=[c: boom]".
+func (r Result) String() string {
+	var parts []string
+	add := func(label string, names []string) {
+		if len(names) > 0 {
+			parts = append(parts, fmt.Sprintf("%s=[%s]", label, strings.Join(names, " ")))
+		}
+	}
+	add("started", r.Started)
+	add("stopped", r.Stopped)
+	add("restarted", r.Restarted)
+	if len(r.Failed) > 0 {
+		fs := make([]string, len(r.Failed))
+		for i, f := range r.Failed {
+			fs[i] = f.Name + ": " + f.Err
+		}
+		parts = append(parts, fmt.Sprintf("failed=[%s]", strings.Join(fs, "; ")))
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, " ")
+}
@@ -92,20 +92,35 @@ func (m *Manager) Apply(sec map[string]string, ttl time.Duration) error {
 	if ttl <= 0 {
 		ttl = m.ttl
 	}
+	return m.apply(sec, nil, ttl)
+}
+
+// ApplyWithTTLs is like Apply but takes a TTL per ref. Refs missing from
+// ttls, or with a TTL <= 0, use the Manager's default TTL. This lets
+// high-sensitivity secrets expire (and be evicted) sooner than the rest of
+// a batch delivered together.
+func (m *Manager) ApplyWithTTLs(sec map[string]string, ttls map[string]time.Duration) error {
+	return m.apply(sec, ttls, m.ttl)
+}
 
+func (m *Manager) apply(sec map[string]string, ttls map[string]time.Duration, fallback time.Duration) error {
 	// Store first so that a concurrent GetSecret never sees a TTL entry
 	// without a corresponding backing-store value.
 	if err := m.store.Apply(sec); err != nil {
 		return err
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	now := time.Now()
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for ref := range sec {
-		m.entries[ref] = cacheEntry{expiresAt: expiresAt}
+		ttl := fallback
+		if t := ttls[ref]; t > 0 {
+			ttl = t
+		}
+		m.entries[ref] = cacheEntry{expiresAt: now.Add(ttl)}
 	}
 
 	// Build ref list for debug logging. Values are never included.
@@ -116,7 +131,8 @@ func (m *Manager) Apply(sec map[string]string, ttl time.Duration) error {
 	slog.Debug("secrets: manager applied secrets",
 		"count", len(sec),
 		"refs", refs,
-		"ttl", ttl,
+		"ttl", fallback,
+		"overrides", len(ttls),
 	)
 	return nil
 }
@@ -243,6 +243,51 @@ func TestEvict_PreservesUnexpiredSecrets(t *testing.T) {
 	}
 }
 
+func TestEvict_HonoursPerRefTTL(t *testing.T) {
+	store := secrets.New()
+	m := secrets.NewManager(store, time.Hour)
+	encoded := map[string]string{
+		"short": base64.StdEncoding.EncodeToString([]byte("s")),
+		"long":  base64.StdEncoding.EncodeToString([]byte("l")),
+	}
+	if err := m.ApplyWithTTLs(encoded, map[string]time.Duration{
+		"short": time.Millisecond,
+		"long":  time.Hour,
+	}); err != nil {
+		t.Fatalf("ApplyWithTTLs: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if n := m.Evict(); n != 1 {
+		t.Fatalf("Evict() = %d, want 1 (only the short-TTL ref)", n)
+	}
+	if _, err := store.Get("short"); err == nil {
+		t.Error("expected short-TTL secret to be evicted from the store")
+	}
+	if got, err := m.GetSecret("long"); err != nil || got != "l" {
+		t.Errorf("GetSecret(long) = %q, %v; want the long-TTL value to survive", got, err)
+	}
+}
+
+func TestApplyWithTTLs_MissingOverrideUsesDefault(t *testing.T) {
+	m := secrets.NewManager(secrets.New(), time.Millisecond)
+	encoded := map[string]string{
+		"override": base64.StdEncoding.EncodeToString([]byte("o")),
+		"default":  base64.StdEncoding.EncodeToString([]byte("d")),
+	}
+	if err := m.ApplyWithTTLs(encoded, map[string]time.Duration{"override": time.Hour}); err != nil {
+		t.Fatalf("ApplyWithTTLs: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if m.IsExpired("override") {
+		t.Error("override ref should use its own 1h TTL")
+	}
+	if !m.IsExpired("default") {
+		t.Error("ref without an override should use the manager default TTL")
+	}
+}
+
 func TestEvict_NopWhenNothingExpired(t *testing.T) {
 	m := secrets.NewManager(secrets.New(), time.Hour)
 	applyWith(t, m, map[string]string{"k": "v"}, time.Hour)
@@ -320,3 +365,20 @@ func TestApply_MultipleRefsInOneCall(t *testing.T) {
 		}
 	}
 }
+
+func TestManager_SealedStoreRoundTrip(t *testing.T) {
+	store, err := secrets.NewSealed()
+	if err != nil {
+		t.Fatalf("NewSealed: %v", err)
+	}
+	m := secrets.NewManager(store, time.Hour)
+	applyWith(t, m, map[string]string{"api_key": "sk-live-123"}, 0)
+
+	got, err := m.GetSecret("api_key")
+	if err != nil {
+		t.Fatalf("GetSecret: %v", err)
+	}
+	if got != "sk-live-123" {
+		t.Errorf("GetSecret = %q, want the decrypted plaintext", got)
+	}
+}
@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestSealedStore_StoresCiphertext(t *testing.T) {
+	s, err := NewSealed()
+	if err != nil {
+		t.Fatalf("NewSealed: %v", err)
+	}
+	plaintext := []byte("supersecret-value")
+	if err := s.Apply(map[string]string{"db_password": base64.StdEncoding.EncodeToString(plaintext)}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	stored := s.secrets["db_password"]
+	if bytes.Contains(stored, plaintext) {
+		t.Fatal("stored bytes contain the plaintext secret")
+	}
+	if len(stored) <= len(plaintext) {
+		t.Errorf("stored %d bytes, want ciphertext longer than the %d-byte plaintext", len(stored), len(plaintext))
+	}
+
+	got, err := s.GetString("db_password")
+	if err != nil {
+		t.Fatalf("GetString: %v", err)
+	}
+	if got != string(plaintext) {
+		t.Errorf("GetString = %q, want %q", got, plaintext)
+	}
+}
+
+func TestSealedStore_ReadsDoNotLeavePlaintextInMap(t *testing.T) {
+	s, err := NewSealed()
+	if err != nil {
+		t.Fatalf("NewSealed: %v", err)
+	}
+	plaintext := []byte("token-123")
+	if err := s.Apply(map[string]string{"tok": base64.StdEncoding.EncodeToString(plaintext)}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if _, err := s.Get("tok"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	env := s.Env(map[string]string{"TOKEN": "tok"})
+	if env["TOKEN"] != string(plaintext) {
+		t.Errorf("Env TOKEN = %q, want %q", env["TOKEN"], plaintext)
+	}
+	if bytes.Contains(s.secrets["tok"], plaintext) {
+		t.Error("map holds plaintext after reads")
+	}
+}
+
+func TestUnsealedStore_StoresPlaintext(t *testing.T) {
+	s := New()
+	if s.Sealed() {
+		t.Fatal("New() store should not be sealed")
+	}
+	if err := s.Apply(map[string]string{"k": base64.StdEncoding.EncodeToString([]byte("v"))}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if string(s.secrets["k"]) != "v" {
+		t.Errorf("unsealed store holds %q, want plaintext", s.secrets["k"])
+	}
+}
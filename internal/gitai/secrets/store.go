@@ -3,13 +3,21 @@
 // Ruriko pushes secrets to the agent via the ACP POST /secrets/apply endpoint.
 // The agent stores them in memory only — nothing is written to disk —
 // and injects them into MCP process environments and LLM requests as needed.
+//
+// A sealed Store (NewSealed) additionally keeps each value AES-GCM encrypted
+// under a key generated for the life of the process, decrypting only when a
+// value is read. A memory dump then shows ciphertext for cached secrets
+// rather than plaintext, at the cost of a decryption per read.
 package secrets
 
 import (
+	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"log/slog"
 	"sync"
+
+	"github.com/bdobrica/Ruriko/common/crypto"
 )
 
 // Store holds the current set of secrets in memory.
@@ -17,6 +25,9 @@ import (
 type Store struct {
 	mu      sync.RWMutex
 	secrets map[string][]byte
+	// key seals stored values when non-nil. It is generated in NewSealed
+	// and never leaves the process.
+	key []byte
 }
 
 // New returns an empty Store.
@@ -24,6 +35,27 @@ func New() *Store {
 	return &Store{secrets: make(map[string][]byte)}
 }
 
+// NewSealed returns an empty Store that encrypts values at rest in memory
+// with a process-ephemeral key.
+func NewSealed() (*Store, error) {
+	key := make([]byte, crypto.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate secret sealing key: %w", err)
+	}
+	return &Store{secrets: make(map[string][]byte), key: key}, nil
+}
+
+// Sealed reports whether the store encrypts values at rest.
+func (s *Store) Sealed() bool { return s.key != nil }
+
+// open returns the plaintext of a stored value.
+func (s *Store) open(stored []byte) ([]byte, error) {
+	if s.key == nil {
+		return stored, nil
+	}
+	return crypto.Decrypt(s.key, stored)
+}
+
 // Apply replaces the entire secret set with the provided map.
 // Keys are secret names; values are base64-encoded secret bytes (as received
 // from Ruriko's ACP /secrets/apply payload).
@@ -34,6 +66,14 @@ func (s *Store) Apply(incoming map[string]string) error {
 		if err != nil {
 			return fmt.Errorf("decode secret %q: %w", name, err)
 		}
+		if s.key != nil {
+			sealed, err := crypto.Encrypt(s.key, val)
+			clear(val)
+			if err != nil {
+				return fmt.Errorf("seal secret %q: %w", name, err)
+			}
+			val = sealed
+		}
 		decoded[name] = val
 	}
 
@@ -53,6 +93,10 @@ func (s *Store) Get(name string) ([]byte, error) {
 	if !ok {
 		return nil, fmt.Errorf("secret %q not found", name)
 	}
+	val, err := s.open(val)
+	if err != nil {
+		return nil, fmt.Errorf("unseal secret %q: %w", name, err)
+	}
 	return val, nil
 }
 
@@ -94,9 +138,16 @@ func (s *Store) Env(mapping map[string]string) map[string]string {
 	defer s.mu.RUnlock()
 	out := make(map[string]string, len(mapping))
 	for envKey, secretName := range mapping {
-		if val, ok := s.secrets[secretName]; ok {
-			out[envKey] = string(val)
+		val, ok := s.secrets[secretName]
+		if !ok {
+			continue
+		}
+		val, err := s.open(val)
+		if err != nil {
+			slog.Warn("secrets: cannot unseal secret for env", "ref", secretName, "err", err)
+			continue
 		}
+		out[envKey] = string(val)
 	}
 	return out
 }
@@ -0,0 +1,19 @@
+-- Dead-letter queue for gateway event turns that failed.
+--
+-- Rows are written only when the DLQ is enabled (GITAI_EVENT_DLQ_ENABLE).
+-- event_json holds the full event envelope so the turn can be re-dispatched;
+-- a successful retry deletes the row. Only the newest
+-- GITAI_EVENT_DLQ_MAX_ENTRIES rows are kept; older ones are pruned on insert.
+
+CREATE TABLE IF NOT EXISTS event_dlq (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	source         TEXT NOT NULL,
+	event_type     TEXT NOT NULL,
+	event_json     TEXT NOT NULL,
+	error          TEXT NOT NULL,
+	retries        INTEGER NOT NULL DEFAULT 0,
+	created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	last_failed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_event_dlq_created ON event_dlq(created_at);
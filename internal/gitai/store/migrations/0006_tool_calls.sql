@@ -0,0 +1,24 @@
+-- Audit trail of tool calls (MCP and built-in) dispatched by the agent.
+--
+-- One row per call, whatever its outcome. Only argument KEYS are stored
+-- (comma-separated, sorted); argument values are never written here.
+-- decision is the policy decision ("allow", "require_approval", "deny") or
+-- "rejected" when the call failed before policy evaluation.
+-- Only the newest GITAI_TOOL_CALL_RETENTION rows are kept; older ones are
+-- pruned on every insert.
+
+CREATE TABLE IF NOT EXISTS tool_calls (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	trace_id    TEXT,
+	caller      TEXT NOT NULL,
+	mcp         TEXT NOT NULL,
+	tool        TEXT NOT NULL,
+	decision    TEXT NOT NULL,
+	rule        TEXT,
+	arg_keys    TEXT NOT NULL DEFAULT '',
+	duration_ms INTEGER NOT NULL DEFAULT 0,
+	error       TEXT,
+	created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_tool_calls_created ON tool_calls(created_at);
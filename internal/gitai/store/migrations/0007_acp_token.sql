@@ -0,0 +1,13 @@
+-- ACP bearer token installed by POST /token/rotate.
+--
+-- A single row. base_token is the GITAI_ACP_TOKEN the agent was started
+-- with when the rotation happened: the stored token only overrides the
+-- environment while that still matches, so an operator who sets a new
+-- GITAI_ACP_TOKEN (or Ruriko recreating the container) wins over it.
+
+CREATE TABLE IF NOT EXISTS acp_token (
+	id         INTEGER PRIMARY KEY CHECK (id = 1),
+	base_token TEXT NOT NULL,
+	token      TEXT NOT NULL,
+	rotated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
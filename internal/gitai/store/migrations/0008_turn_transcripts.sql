@@ -0,0 +1,14 @@
+-- Opt-in per-turn transcripts: the message history each LLM turn sent.
+--
+-- Rows are written only when transcripts are enabled
+-- (FEATURE_TURN_TRANSCRIPTS). messages_json holds the secret-redacted
+-- messages; only the newest rows (GITAI_TURN_TRANSCRIPT_RETENTION) are kept.
+
+CREATE TABLE IF NOT EXISTS turn_transcripts (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	trace_id      TEXT NOT NULL,
+	messages_json TEXT NOT NULL,
+	created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_turn_transcripts_trace ON turn_transcripts(trace_id);
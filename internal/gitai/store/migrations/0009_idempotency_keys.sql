@@ -0,0 +1,15 @@
+-- Responses cached by the ACP server under X-Idempotency-Key.
+--
+-- Persisting them lets a client retry that arrives after an agent restart
+-- still be answered from the cache instead of re-running the operation.
+-- Rows are only useful until expires_at (unix seconds) and are pruned on
+-- every write and load.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	key        TEXT PRIMARY KEY,
+	status     INTEGER NOT NULL,
+	body       BLOB,
+	expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys(expires_at);
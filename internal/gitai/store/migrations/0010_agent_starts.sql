@@ -0,0 +1,11 @@
+-- Process starts recorded by the agent runtime.
+--
+-- A single row. starts counts every startup against this database and
+-- first_started_at (unix seconds) is the first of them, so /status can
+-- report restarts that the in-memory uptime hides.
+
+CREATE TABLE IF NOT EXISTS agent_starts (
+	id               INTEGER PRIMARY KEY CHECK (id = 1),
+	starts           INTEGER NOT NULL,
+	first_started_at INTEGER NOT NULL
+);
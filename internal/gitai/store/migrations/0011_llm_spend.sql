@@ -0,0 +1,12 @@
+-- Estimated LLM spend per calendar month (UTC), for the Gosuto
+-- limits.maxMonthlyCostUSD budget.
+--
+-- One row per month, keyed "YYYY-MM". cost_usd is estimated from token
+-- usage and the agent's model price table, not taken from provider invoices.
+
+CREATE TABLE IF NOT EXISTS llm_spend (
+	month             TEXT PRIMARY KEY,
+	prompt_tokens     INTEGER NOT NULL DEFAULT 0,
+	completion_tokens INTEGER NOT NULL DEFAULT 0,
+	cost_usd          REAL    NOT NULL DEFAULT 0
+);
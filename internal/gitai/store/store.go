@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/bdobrica/Ruriko/common/sqliteutil"
@@ -111,6 +112,76 @@ func (s *Store) LoadAppliedConfig() (hash, yaml string, err error) {
 	return
 }
 
+// RecordStartup counts a process start at now and returns the total number
+// of starts recorded, including this one, and the time of the first.
+func (s *Store) RecordStartup(now time.Time) (starts int64, firstStartedAt time.Time, err error) {
+	if _, err = s.db.Exec(`
+		INSERT INTO agent_starts (id, starts, first_started_at)
+		VALUES (1, 1, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			starts = starts + 1
+	`, now.Unix()); err != nil {
+		return 0, time.Time{}, err
+	}
+	var first int64
+	row := s.db.QueryRow("SELECT starts, first_started_at FROM agent_starts WHERE id = 1")
+	if err = row.Scan(&starts, &first); err != nil {
+		return 0, time.Time{}, err
+	}
+	return starts, time.Unix(first, 0).UTC(), nil
+}
+
+// AddLLMSpend adds one LLM call's token usage and estimated cost to the
+// running total for month ("YYYY-MM") and returns the new total cost.
+func (s *Store) AddLLMSpend(month string, promptTokens, completionTokens int, costUSD float64) (float64, error) {
+	if _, err := s.db.Exec(`
+		INSERT INTO llm_spend (month, prompt_tokens, completion_tokens, cost_usd)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(month) DO UPDATE SET
+			prompt_tokens     = prompt_tokens + excluded.prompt_tokens,
+			completion_tokens = completion_tokens + excluded.completion_tokens,
+			cost_usd          = cost_usd + excluded.cost_usd
+	`, month, promptTokens, completionTokens, costUSD); err != nil {
+		return 0, err
+	}
+	return s.LLMSpend(month)
+}
+
+// LLMSpend returns the estimated LLM cost recorded for month ("YYYY-MM"),
+// or 0 when nothing was spent.
+func (s *Store) LLMSpend(month string) (float64, error) {
+	var cost float64
+	err := s.db.QueryRow("SELECT cost_usd FROM llm_spend WHERE month = ?", month).Scan(&cost)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return cost, err
+}
+
+// SaveRotatedACPToken stores (or replaces) the ACP token installed by a
+// rotation, together with the GITAI_ACP_TOKEN the agent was started with.
+func (s *Store) SaveRotatedACPToken(baseToken, token string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO acp_token (id, base_token, token, rotated_at)
+		VALUES (1, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET
+			base_token = excluded.base_token,
+			token      = excluded.token,
+			rotated_at = excluded.rotated_at
+	`, baseToken, token)
+	return err
+}
+
+// LoadRotatedACPToken retrieves the token stored by SaveRotatedACPToken.
+// Returns ("", "", nil) when no rotation has happened.
+func (s *Store) LoadRotatedACPToken() (baseToken, token string, err error) {
+	row := s.db.QueryRow("SELECT base_token, token FROM acp_token WHERE id = 1")
+	if err = row.Scan(&baseToken, &token); err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	return
+}
+
 // LogTurn inserts a new row into turn_log and returns the inserted ID.
 func (s *Store) LogTurn(traceID, roomID, senderMXID, message string) (int64, error) {
 	res, err := s.db.Exec(`
@@ -163,6 +234,48 @@ func (s *Store) FinishTurnWithDuration(id int64, toolCalls int, durationMS int64
 	return err
 }
 
+// TurnSummary is one row of the turn history without the message text.
+type TurnSummary struct {
+	TraceID    string
+	Trigger    string // "matrix" or "gateway"
+	Sender     string
+	Gateway    string
+	EventType  string
+	Result     string // empty while the turn is running
+	ToolCalls  int
+	DurationMS int64
+	Error      string
+	StartedAt  time.Time
+}
+
+// ListTurns returns the most recent turns, newest first, at most limit.
+// Legacy rows without a trigger are reported as Matrix turns.
+func (s *Store) ListTurns(limit int) ([]TurnSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT trace_id, COALESCE(trigger, 'matrix'), sender_mxid, COALESCE(gateway_name, ''),
+		       COALESCE(event_type, ''), COALESCE(result, ''), tool_calls, COALESCE(duration_ms, 0),
+		       COALESCE(error_msg, ''), started_at
+		FROM turn_log
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]TurnSummary, 0)
+	for rows.Next() {
+		var t TurnSummary
+		if err := rows.Scan(&t.TraceID, &t.Trigger, &t.Sender, &t.Gateway, &t.EventType, &t.Result,
+			&t.ToolCalls, &t.DurationMS, &t.Error, &t.StartedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
 // SaveApproval persists a new approval request.
 func (s *Store) SaveApproval(approvalID, traceID, roomID, action, target, paramsJSON, requestorMXID string, expiresAt time.Time) error {
 	_, err := s.db.Exec(`
@@ -611,6 +724,317 @@ func (s *Store) CountKairoNotifiedRunsLastHour() (int, error) {
 	return count, nil
 }
 
+// DeadLetter is one failed gateway event held in the dead-letter queue.
+type DeadLetter struct {
+	ID           int64
+	Source       string
+	EventType    string
+	EventJSON    string
+	Error        string
+	Retries      int
+	CreatedAt    time.Time
+	LastFailedAt time.Time
+}
+
+// AddDeadLetter records a failed gateway event, prunes all but the newest
+// keep entries and returns the new entry's ID.
+func (s *Store) AddDeadLetter(source, eventType, eventJSON, errMsg string, keep int) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin dlq tx: %w", err)
+	}
+	res, err := tx.Exec(`
+		INSERT INTO event_dlq (source, event_type, event_json, error)
+		VALUES (?, ?, ?, ?)
+	`, source, eventType, eventJSON, errMsg)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("insert event_dlq: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM event_dlq
+		WHERE id NOT IN (SELECT id FROM event_dlq ORDER BY id DESC LIMIT ?)
+	`, keep); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("prune event_dlq: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit dlq tx: %w", err)
+	}
+	return id, nil
+}
+
+// ListDeadLetters returns dead-lettered events, oldest first.
+func (s *Store) ListDeadLetters() ([]DeadLetter, error) {
+	rows, err := s.db.Query(`
+		SELECT id, source, event_type, event_json, error, retries, created_at, last_failed_at
+		FROM event_dlq
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]DeadLetter, 0)
+	for rows.Next() {
+		var d DeadLetter
+		if err := rows.Scan(&d.ID, &d.Source, &d.EventType, &d.EventJSON, &d.Error, &d.Retries, &d.CreatedAt, &d.LastFailedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// GetDeadLetter loads a dead-lettered event by ID.
+// When the entry does not exist, found is false and err is nil.
+func (s *Store) GetDeadLetter(id int64) (d DeadLetter, found bool, err error) {
+	err = s.db.QueryRow(`
+		SELECT id, source, event_type, event_json, error, retries, created_at, last_failed_at
+		FROM event_dlq
+		WHERE id = ?
+	`, id).Scan(&d.ID, &d.Source, &d.EventType, &d.EventJSON, &d.Error, &d.Retries, &d.CreatedAt, &d.LastFailedAt)
+	if err == sql.ErrNoRows {
+		return DeadLetter{}, false, nil
+	}
+	if err != nil {
+		return DeadLetter{}, false, err
+	}
+	return d, true, nil
+}
+
+// ClaimDeadLetterRetry increments the retry count of a dead-lettered event
+// if it is still below maxRetries. It reports false when the entry does not
+// exist or its retry budget is spent, so concurrent retries cannot exceed
+// the cap.
+func (s *Store) ClaimDeadLetterRetry(id int64, maxRetries int) (bool, error) {
+	res, err := s.db.Exec(`
+		UPDATE event_dlq
+		SET retries = retries + 1
+		WHERE id = ? AND retries < ?
+	`, id, maxRetries)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// RecordDeadLetterFailure stores the error of a failed retry.
+func (s *Store) RecordDeadLetterFailure(id int64, errMsg string) error {
+	_, err := s.db.Exec(`
+		UPDATE event_dlq
+		SET error = ?, last_failed_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, errMsg, id)
+	return err
+}
+
+// DeleteDeadLetter removes a dead-lettered event, e.g. after a successful retry.
+func (s *Store) DeleteDeadLetter(id int64) error {
+	_, err := s.db.Exec(`DELETE FROM event_dlq WHERE id = ?`, id)
+	return err
+}
+
+// ToolCall is one entry in the tool-call audit trail. ArgKeys holds the
+// names of the call's arguments only; values are never stored.
+type ToolCall struct {
+	ID         int64
+	TraceID    string
+	Caller     string
+	MCP        string
+	Tool       string
+	Decision   string
+	Rule       string
+	ArgKeys    []string
+	DurationMS int64
+	Error      string
+	CreatedAt  time.Time
+}
+
+// RecordToolCall appends c to the tool-call audit trail and prunes all but
+// the newest keep entries.
+func (s *Store) RecordToolCall(c ToolCall, keep int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tool call tx: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO tool_calls (trace_id, caller, mcp, tool, decision, rule, arg_keys, duration_ms, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, nullableString(c.TraceID), c.Caller, c.MCP, c.Tool, c.Decision, nullableString(c.Rule),
+		strings.Join(c.ArgKeys, ","), c.DurationMS, nullableString(c.Error)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("insert tool_calls: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM tool_calls
+		WHERE id NOT IN (SELECT id FROM tool_calls ORDER BY id DESC LIMIT ?)
+	`, keep); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("prune tool_calls: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tool call tx: %w", err)
+	}
+	return nil
+}
+
+// ListToolCalls returns the most recent tool calls, newest first, at most
+// limit of them.
+func (s *Store) ListToolCalls(limit int) ([]ToolCall, error) {
+	rows, err := s.db.Query(`
+		SELECT id, COALESCE(trace_id, ''), caller, mcp, tool, decision, COALESCE(rule, ''),
+		       arg_keys, duration_ms, COALESCE(error, ''), created_at
+		FROM tool_calls
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ToolCall, 0)
+	for rows.Next() {
+		var c ToolCall
+		var argKeys string
+		if err := rows.Scan(&c.ID, &c.TraceID, &c.Caller, &c.MCP, &c.Tool, &c.Decision, &c.Rule,
+			&argKeys, &c.DurationMS, &c.Error, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		if argKeys != "" {
+			c.ArgKeys = strings.Split(argKeys, ",")
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// TurnTranscript is the stored message history of one LLM turn.
+type TurnTranscript struct {
+	ID           int64
+	TraceID      string
+	MessagesJSON string
+	CreatedAt    time.Time
+}
+
+// RecordTranscript stores the message history of the turn traceID and
+// prunes all but the newest keep transcripts.
+func (s *Store) RecordTranscript(traceID, messagesJSON string, keep int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transcript tx: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO turn_transcripts (trace_id, messages_json)
+		VALUES (?, ?)
+	`, traceID, messagesJSON); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("insert turn_transcripts: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM turn_transcripts
+		WHERE id NOT IN (SELECT id FROM turn_transcripts ORDER BY id DESC LIMIT ?)
+	`, keep); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("prune turn_transcripts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transcript tx: %w", err)
+	}
+	return nil
+}
+
+// GetTranscript loads the newest transcript recorded for traceID.
+// When there is none, found is false and err is nil.
+func (s *Store) GetTranscript(traceID string) (t TurnTranscript, found bool, err error) {
+	err = s.db.QueryRow(`
+		SELECT id, trace_id, messages_json, created_at
+		FROM turn_transcripts
+		WHERE trace_id = ?
+		ORDER BY id DESC
+		LIMIT 1
+	`, traceID).Scan(&t.ID, &t.TraceID, &t.MessagesJSON, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return TurnTranscript{}, false, nil
+	}
+	if err != nil {
+		return TurnTranscript{}, false, err
+	}
+	return t, true, nil
+}
+
+// IdempotencyEntry is an ACP response cached under an idempotency key.
+type IdempotencyEntry struct {
+	Key       string
+	Status    int
+	Body      []byte
+	ExpiresAt time.Time
+}
+
+// SaveIdempotencyEntry stores (or replaces) the response cached under
+// e.Key and prunes expired entries.
+func (s *Store) SaveIdempotencyEntry(e IdempotencyEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin idempotency tx: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO idempotency_keys (key, status, body, expires_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			status = excluded.status,
+			body = excluded.body,
+			expires_at = excluded.expires_at
+	`, e.Key, e.Status, e.Body, e.ExpiresAt.Unix()); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("upsert idempotency_keys: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, time.Now().Unix()); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("prune idempotency_keys: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit idempotency tx: %w", err)
+	}
+	return nil
+}
+
+// LoadIdempotencyEntries prunes expired entries and returns the rest.
+func (s *Store) LoadIdempotencyEntries() ([]IdempotencyEntry, error) {
+	now := time.Now().Unix()
+	if _, err := s.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, now); err != nil {
+		return nil, fmt.Errorf("prune idempotency_keys: %w", err)
+	}
+	rows, err := s.db.Query(`SELECT key, status, body, expires_at FROM idempotency_keys`)
+	if err != nil {
+		return nil, fmt.Errorf("query idempotency_keys: %w", err)
+	}
+	defer rows.Close()
+
+	var out []IdempotencyEntry
+	for rows.Next() {
+		var e IdempotencyEntry
+		var expiresAt int64
+		if err := rows.Scan(&e.Key, &e.Status, &e.Body, &expiresAt); err != nil {
+			return nil, fmt.Errorf("scan idempotency_keys: %w", err)
+		}
+		e.ExpiresAt = time.Unix(expiresAt, 0)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
 func nullableString(s string) interface{} {
 	if s == "" {
 		return nil
@@ -0,0 +1,119 @@
+package supervisor
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// expandSpecEnv resolves variable references in the values of a Gosuto env
+// map (MCP server or external gateway) against the injected secret env and,
+// failing that, the agent's own environment. Supported forms:
+//
+//	${VAR}           value of VAR, or empty when unset
+//	${VAR:-default}  value of VAR, or default when VAR is unset or empty
+//	${VAR:?}         value of VAR; an error when VAR is unset or empty
+//	${VAR:?message}  as above, with message appended to the error
+//
+// A "$" not followed by "{" is kept literally. Expansion happens each time a
+// process is started, so refreshed secrets are picked up on restart.
+func expandSpecEnv(env map[string]string, secretEnv map[string]string) (map[string]string, error) {
+	if len(env) == 0 {
+		return env, nil
+	}
+	lookup := func(name string) (string, bool) {
+		if v, ok := secretEnv[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
+	// Iterate in key order so the first error reported is deterministic.
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make(map[string]string, len(env))
+	for _, k := range keys {
+		v, err := expandEnvValue(env[k], lookup)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", k, err)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// expandEnvValue expands the ${...} references in s using lookup.
+func expandEnvValue(s string, lookup func(string) (string, bool)) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		sb.WriteString(s[:i])
+		v, err := resolveEnvRef(s[i+2:i+end], lookup)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(v)
+		s = s[i+end+1:]
+	}
+}
+
+// resolveEnvRef resolves the body of one ${...} reference.
+func resolveEnvRef(ref string, lookup func(string) (string, bool)) (string, error) {
+	name, op, arg := ref, "", ""
+	if i := strings.Index(ref, ":"); i >= 0 {
+		name = ref[:i]
+		if len(ref) < i+2 || (ref[i+1] != '-' && ref[i+1] != '?') {
+			return "", fmt.Errorf("unsupported variable reference ${%s}; use ${VAR}, ${VAR:-default} or ${VAR:?}", ref)
+		}
+		op, arg = ref[i:i+2], ref[i+2:]
+	}
+	if !validEnvName(name) {
+		return "", fmt.Errorf("invalid variable name %q in ${%s}", name, ref)
+	}
+	v, _ := lookup(name)
+	switch op {
+	case ":-":
+		if v == "" {
+			return arg, nil
+		}
+	case ":?":
+		if v == "" {
+			if arg != "" {
+				return "", fmt.Errorf("required variable %s is not set: %s", name, arg)
+			}
+			return "", fmt.Errorf("required variable %s is not set", name)
+		}
+	}
+	return v, nil
+}
+
+// validEnvName reports whether name is a conventional environment variable
+// name: letters, digits and underscores, not starting with a digit.
+func validEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package supervisor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+)
+
+func TestExpandSpecEnv(t *testing.T) {
+	secretEnv := map[string]string{"BRAVE_API_KEY": "sk-brave", "EMPTY": ""}
+	t.Setenv("GITAI_TEST_REGION", "eu-west-1")
+
+	got, err := expandSpecEnv(map[string]string{
+		"KEY":      "${BRAVE_API_KEY}",
+		"AUTH":     "Bearer ${BRAVE_API_KEY:?}",
+		"REGION":   "${GITAI_TEST_REGION:-us-east-1}",
+		"TIMEOUT":  "${GITAI_TEST_TIMEOUT:-30s}",
+		"FALLBACK": "${EMPTY:-fallback}",
+		"UNSET":    "${GITAI_TEST_UNSET}",
+		"LITERAL":  "cost: $5",
+	}, secretEnv)
+	if err != nil {
+		t.Fatalf("expandSpecEnv: %v", err)
+	}
+	want := map[string]string{
+		"KEY":      "sk-brave",
+		"AUTH":     "Bearer sk-brave",
+		"REGION":   "eu-west-1",
+		"TIMEOUT":  "30s",
+		"FALLBACK": "fallback",
+		"UNSET":    "",
+		"LITERAL":  "cost: $5",
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s = %q, want %q", k, got[k], w)
+		}
+	}
+}
+
+func TestExpandSpecEnv_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		wantErr string
+	}{
+		{"${GITAI_TEST_MISSING:?}", "required variable GITAI_TEST_MISSING is not set"},
+		{"${GITAI_TEST_MISSING:?set it in the agent secrets}", "not set: set it in the agent secrets"},
+		{"${GITAI_TEST_MISSING", "unterminated"},
+		{"${GITAI_TEST_MISSING:=x}", "unsupported"},
+		{"${1BAD}", "invalid variable name"},
+	} {
+		_, err := expandSpecEnv(map[string]string{"TOKEN": tc.value}, nil)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: err = %v, want containing %q", tc.value, err, tc.wantErr)
+		}
+		if err != nil && !strings.HasPrefix(err.Error(), "env TOKEN:") {
+			t.Errorf("%s: err = %q, want the env key named", tc.value, err)
+		}
+	}
+}
+
+func TestReconcile_RequiredEnvMissingFails(t *testing.T) {
+	s := New()
+	defer s.Stop()
+
+	sp := fakeMCP("needs-key")
+	sp.Env["API_KEY"] = "${GITAI_TEST_MISSING_KEY:?}"
+	res := s.Reconcile([]gosutospec.MCPServer{sp})
+	if len(res.Failed) != 1 || !strings.Contains(res.Failed[0].Err, "GITAI_TEST_MISSING_KEY") {
+		t.Fatalf("Failed = %+v, want the missing variable reported", res.Failed)
+	}
+	if s.Get("needs-key") != nil {
+		t.Error("server started despite the unresolved required variable")
+	}
+
+	s.ApplySecrets(map[string]string{"GITAI_TEST_MISSING_KEY": "present"})
+	res = s.Reconcile([]gosutospec.MCPServer{sp})
+	if len(res.Failed) != 0 || s.Get("needs-key") == nil {
+		t.Errorf("after the secret arrived: result %s, want started", res.String())
+	}
+}
+
+func TestExternalGatewayReconcile_RequiredEnvMissingFails(t *testing.T) {
+	s := NewExternalGatewaySupervisor("http://127.0.0.1:0")
+	defer s.Stop()
+
+	res := s.Reconcile([]gosutospec.Gateway{{
+		Name:    "imap",
+		Command: "/nonexistent/gw",
+		Env:     map[string]string{"GW_IMAP_PASSWORD": "${IMAP_PASSWORD:?}"},
+	}})
+	if len(res.Failed) != 1 || res.Failed[0].Name != "imap" || len(res.Started) != 0 {
+		t.Errorf("result = %+v, want imap failed and nothing started", res)
+	}
+}
+
+func TestExternalGatewayApplySecrets_StartsGatewayOnceEnvResolves(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "started")
+	s := NewExternalGatewaySupervisor("http://127.0.0.1:0")
+	defer s.Stop()
+
+	res := s.Reconcile([]gosutospec.Gateway{{
+		Name:    "imap",
+		Command: "/bin/sh",
+		Args:    []string{"-c", `echo "$GW_IMAP_PASSWORD" > ` + marker + `; exec sleep 30`},
+		Env:     map[string]string{"GW_IMAP_PASSWORD": "${IMAP_PASSWORD:?}"},
+	}})
+	if len(res.Failed) != 1 {
+		t.Fatalf("result = %+v, want imap failed", res)
+	}
+
+	s.ApplySecrets(map[string]string{"IMAP_PASSWORD": "hunter2"})
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		data, err := os.ReadFile(marker)
+		if err == nil && strings.TrimSpace(string(data)) == "hunter2" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("gateway did not start after the missing secret arrived")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
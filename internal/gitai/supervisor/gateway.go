@@ -26,10 +26,12 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/reconcile"
 )
 
 const gracefulShutdownTimeout = 5 * time.Second
@@ -55,6 +57,10 @@ type externalGatewayProcess struct {
 	spec   gosutospec.Gateway
 	cancel context.CancelFunc // cancels this process's lifecycle context
 	done   chan struct{}      // closed when the process goroutine exits
+	// envFailed is set when the goroutine gave up because the env could not
+	// be built. The next Reconcile or ApplySecrets drops the entry so the
+	// gateway is started again once the missing variable is available.
+	envFailed atomic.Bool
 }
 
 // NewExternalGatewaySupervisor creates an ExternalGatewaySupervisor whose
@@ -79,12 +85,16 @@ func (s *ExternalGatewaySupervisor) withRestartDelay(d time.Duration) *ExternalG
 }
 
 // ApplySecrets updates the environment variables injected into newly-started
-// gateway processes.  Existing processes are NOT restarted — call Reconcile
-// after ApplySecrets if you want the running processes to pick up the changes.
+// gateway processes.  Existing processes are NOT restarted, but gateways of
+// the last reconciled spec that could not start for want of a variable (e.g.
+// an unresolved ${SECRET:?}) are started now if the new env resolves it.
 func (s *ExternalGatewaySupervisor) ApplySecrets(env map[string]string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.secretEnv = env
+	if res := s.reconcileLocked(s.specs); len(res.Started) > 0 {
+		slog.Info("supervisor/gateway: started external gateways after secrets update", "started", res.Started)
+	}
 }
 
 // Reconcile ensures exactly the external gateways described in gateways are
@@ -93,9 +103,24 @@ func (s *ExternalGatewaySupervisor) ApplySecrets(env map[string]string) {
 //
 // Processes no longer in the spec, or whose config has changed, are stopped
 // before the new version is started.
-func (s *ExternalGatewaySupervisor) Reconcile(gateways []gosutospec.Gateway) {
+func (s *ExternalGatewaySupervisor) Reconcile(gateways []gosutospec.Gateway) reconcile.Result {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.reconcileLocked(gateways)
+}
+
+// reconcileLocked implements Reconcile. The caller must hold s.mu.
+func (s *ExternalGatewaySupervisor) reconcileLocked(gateways []gosutospec.Gateway) reconcile.Result {
+	var res reconcile.Result
+
+	// Forget gateways whose goroutine gave up on an unresolvable env, so
+	// they are retried below.
+	for name, proc := range s.processes {
+		if proc.envFailed.Load() {
+			<-proc.done
+			delete(s.processes, name)
+		}
+	}
 
 	// Index the wanted external gateways (those that specify a binary Command).
 	wanted := make(map[string]gosutospec.Gateway, len(gateways))
@@ -106,6 +131,7 @@ func (s *ExternalGatewaySupervisor) Reconcile(gateways []gosutospec.Gateway) {
 	}
 
 	// Stop processes that are no longer wanted or whose config has changed.
+	changed := make(map[string]bool)
 	for name, proc := range s.processes {
 		newSpec, ok := wanted[name]
 		if !ok || externalGatewayChanged(proc.spec, newSpec) {
@@ -116,16 +142,37 @@ func (s *ExternalGatewaySupervisor) Reconcile(gateways []gosutospec.Gateway) {
 			// attempt to acquire it (it doesn't).
 			<-proc.done
 			delete(s.processes, name)
+			if ok {
+				changed[name] = true
+			} else {
+				res.Stopped = append(res.Stopped, name)
+			}
 		}
 	}
 
-	// Start gateways that are wanted but not currently running.
+	// Start gateways that are wanted but not currently running. The binary
+	// is launched asynchronously, so start failures surface in the logs
+	// rather than in the result; only an env that cannot be built (e.g. an
+	// unresolved ${VAR:?}) is reported as failed.
 	for name, gw := range wanted {
-		if _, running := s.processes[name]; !running {
-			s.startLocked(gw)
+		if _, running := s.processes[name]; running {
+			continue
+		}
+		if _, err := buildGatewayEnv(gw, s.acpURL, s.secretEnv); err != nil {
+			slog.Error("supervisor/gateway: cannot build external gateway env", "name", name, "err", err)
+			res.Failed = append(res.Failed, reconcile.Failure{Name: name, Err: err.Error()})
+			continue
+		}
+		s.startLocked(gw)
+		if changed[name] {
+			res.Restarted = append(res.Restarted, name)
+		} else {
+			res.Started = append(res.Started, name)
 		}
 	}
 	s.specs = gateways
+	res.Sort()
+	return res
 }
 
 // Stop cancels all external gateway processes and waits for them to exit.
@@ -164,7 +211,15 @@ func (s *ExternalGatewaySupervisor) runGateway(ctx context.Context, proc *extern
 	defer close(proc.done)
 
 	for {
-		env := s.buildEnv(proc.spec)
+		env, err := s.buildEnv(proc.spec)
+		if err != nil {
+			// A missing required variable will not fix itself; Reconcile
+			// reports it, so give up instead of retrying.
+			slog.Error("supervisor/gateway: cannot build external gateway env",
+				"name", proc.name, "err", err)
+			proc.envFailed.Store(true)
+			return
+		}
 		cmd := exec.Command(proc.spec.Command, proc.spec.Args...)
 		cmd.Env = env
 		// Route gateway stdout/stderr to the agent's own stderr so that
@@ -244,10 +299,11 @@ func (s *ExternalGatewaySupervisor) runGateway(ctx context.Context, proc *extern
 //
 //  1. os.Environ()        — inherited process environment
 //  2. secretEnv           — Ruriko-managed secrets (same as MCP processes)
-//  3. spec.Env            — static env vars from the Gosuto spec
+//  3. spec.Env            — env vars from the Gosuto spec, with ${...}
+//     references expanded (see expandSpecEnv)
 //  4. GATEWAY_TARGET_URL  — ACP /events/{name} endpoint for this gateway
 //  5. GATEWAY_<KEY>       — one var per config entry (key uppercased)
-func (s *ExternalGatewaySupervisor) buildEnv(gw gosutospec.Gateway) []string {
+func (s *ExternalGatewaySupervisor) buildEnv(gw gosutospec.Gateway) ([]string, error) {
 	s.mu.RLock()
 	secretEnv := s.secretEnv
 	s.mu.RUnlock()
@@ -256,14 +312,18 @@ func (s *ExternalGatewaySupervisor) buildEnv(gw gosutospec.Gateway) []string {
 
 // buildGatewayEnv is the pure-function core of buildEnv, separated for
 // testability without needing a fully-wired supervisor instance.
-func buildGatewayEnv(gw gosutospec.Gateway, acpURL string, secretEnv map[string]string) []string {
+func buildGatewayEnv(gw gosutospec.Gateway, acpURL string, secretEnv map[string]string) ([]string, error) {
+	specEnv, err := expandSpecEnv(gw.Env, secretEnv)
+	if err != nil {
+		return nil, err
+	}
 	base := os.Environ()
-	extra := make([]string, 0, len(secretEnv)+len(gw.Env)+len(gw.Config)+1)
+	extra := make([]string, 0, len(secretEnv)+len(specEnv)+len(gw.Config)+1)
 
 	for k, v := range secretEnv {
 		extra = append(extra, k+"="+v)
 	}
-	for k, v := range gw.Env {
+	for k, v := range specEnv {
 		extra = append(extra, k+"="+v)
 	}
 	// Inject the ACP event ingress URL so the gateway knows where to POST events.
@@ -272,7 +332,7 @@ func buildGatewayEnv(gw gosutospec.Gateway, acpURL string, secretEnv map[string]
 	for k, v := range gw.Config {
 		extra = append(extra, fmt.Sprintf("GATEWAY_%s=%s", strings.ToUpper(k), v))
 	}
-	return append(base, extra...)
+	return append(base, extra...), nil
 }
 
 // ────────────────────────────────────────────────────────────────────────────
@@ -18,7 +18,7 @@ gw := gosutospec.Gateway{
 Name:    "my-gw",
 Command: "/usr/bin/my-gateway",
 }
-env := buildGatewayEnv(gw, "http://127.0.0.1:8765", nil)
+env, _ := buildGatewayEnv(gw, "http://127.0.0.1:8765", nil)
 
 want := "GATEWAY_TARGET_URL=http://127.0.0.1:8765/events/my-gw"
 for _, e := range env {
@@ -38,7 +38,7 @@ Config: map[string]string{
 "topic":    "finance",
 },
 }
-env := buildGatewayEnv(gw, "http://127.0.0.1:9000", nil)
+env, _ := buildGatewayEnv(gw, "http://127.0.0.1:9000", nil)
 
 wants := []string{"GATEWAY_INTERVAL=30", "GATEWAY_TOPIC=finance"}
 for _, want := range wants {
@@ -62,7 +62,7 @@ Command: "/usr/bin/gw",
 Env:     map[string]string{"STATIC_VAR": "hello"},
 }
 secretEnv := map[string]string{"API_KEY": "supersecret"}
-env := buildGatewayEnv(gw, "http://127.0.0.1:8765", secretEnv)
+env, _ := buildGatewayEnv(gw, "http://127.0.0.1:8765", secretEnv)
 
 for _, want := range []string{"API_KEY=supersecret", "STATIC_VAR=hello"} {
 found := false
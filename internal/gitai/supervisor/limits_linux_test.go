@@ -0,0 +1,77 @@
+//go:build linux
+
+package supervisor
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+)
+
+func TestReconcile_AppliesResourceLimits(t *testing.T) {
+	dir := t.TempDir()
+	s := New()
+	defer s.Stop()
+
+	sp := fakeMCP("limited")
+	sp.Env[fakeMCPDirEnv] = dir
+	sp.Resources = gosutospec.MCPResources{MaxMemoryMB: 8192, Niceness: 7}
+	res := s.Reconcile([]gosutospec.MCPServer{sp})
+	if len(res.Started) != 1 {
+		t.Fatalf("reconcile = %+v, want limited started", res)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "pid"))
+	if err != nil {
+		t.Fatalf("read pid: %v", err)
+	}
+	pid, _ := strconv.Atoi(string(raw))
+
+	var lim unix.Rlimit
+	if err := unix.Prlimit(pid, unix.RLIMIT_AS, nil, &lim); err != nil {
+		t.Fatalf("prlimit: %v", err)
+	}
+	if want := uint64(8192) << 20; lim.Cur != want || lim.Max != want {
+		t.Errorf("RLIMIT_AS = %d/%d, want %d", lim.Cur, lim.Max, want)
+	}
+
+	// Niceness is per thread: every thread of the server must have it, not
+	// just the one a post-start setpriority(pid) would reach. Field 19 of
+	// /proc/<pid>/task/<tid>/stat is the niceness.
+	tasks, err := os.ReadDir(filepath.Join("/proc", strconv.Itoa(pid), "task"))
+	if err != nil {
+		t.Fatalf("read tasks: %v", err)
+	}
+	if len(tasks) < 2 {
+		t.Fatalf("tasks = %d, want the Go runtime's threads", len(tasks))
+	}
+	for _, task := range tasks {
+		stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "task", task.Name(), "stat"))
+		if err != nil {
+			t.Fatalf("read stat: %v", err)
+		}
+		fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+		if nice := fields[16]; nice != "7" {
+			t.Errorf("thread %s niceness = %s, want 7", task.Name(), nice)
+		}
+	}
+}
+
+func TestReconcile_RestartsOnResourceChange(t *testing.T) {
+	s := New()
+	defer s.Stop()
+
+	s.Reconcile([]gosutospec.MCPServer{fakeMCP("alpha")})
+	sp := fakeMCP("alpha")
+	sp.Resources.Niceness = 5
+	res := s.Reconcile([]gosutospec.MCPServer{sp})
+	if got := strings.Join(res.Restarted, ","); got != "alpha" {
+		t.Errorf("restarted = %q, want alpha", got)
+	}
+}
@@ -0,0 +1,86 @@
+package supervisor
+
+import (
+	"sort"
+	"time"
+)
+
+// MCP server states reported by Status.
+const (
+	// StateRunning is a live server that is serving tool calls.
+	StateRunning = "running"
+	// StateInitializing is a live server whose readiness probe has not
+	// passed yet.
+	StateInitializing = "initializing"
+	// StateRestarting is a server that exited or failed to start and that
+	// its auto_restart watcher will start again.
+	StateRestarting = "restarting"
+	// StateCrashed is a server that exited or failed to start and is not
+	// restarted automatically.
+	StateCrashed = "crashed"
+)
+
+// ServerStatus describes the health of one configured MCP server.
+type ServerStatus struct {
+	Name  string
+	State string
+	// Restarts counts automatic restarts since the server was configured.
+	Restarts int
+	// LastExit describes the most recent unexpected exit or failed start
+	// (e.g. "exit status 1"); empty when there has been none.
+	LastExit   string
+	LastExitAt time.Time
+}
+
+// serverHealth is the exit history the supervisor keeps per server.
+type serverHealth struct {
+	restarts   int
+	lastExit   string
+	lastExitAt time.Time
+}
+
+// healthLocked returns the health record for name, creating it on first
+// use. Must be called with s.mu held for writing.
+func (s *Supervisor) healthLocked(name string) *serverHealth {
+	h, ok := s.health[name]
+	if !ok {
+		h = &serverHealth{}
+		s.health[name] = h
+	}
+	return h
+}
+
+// recordExitLocked notes that name exited or failed to start for reason.
+// Must be called with s.mu held for writing.
+func (s *Supervisor) recordExitLocked(name, reason string) {
+	h := s.healthLocked(name)
+	h.lastExit, h.lastExitAt = reason, time.Now()
+}
+
+// Status returns the health of every configured MCP server, sorted by name.
+func (s *Supervisor) Status() []ServerStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]ServerStatus, 0, len(s.specs))
+	for _, sp := range s.specs {
+		st := ServerStatus{Name: sp.Name}
+		_, running := s.clients[sp.Name]
+		_, watched := s.watchers[sp.Name]
+		switch {
+		case running && s.initializing[sp.Name]:
+			st.State = StateInitializing
+		case running:
+			st.State = StateRunning
+		case watched:
+			st.State = StateRestarting
+		default:
+			st.State = StateCrashed
+		}
+		if h := s.health[sp.Name]; h != nil {
+			st.Restarts, st.LastExit, st.LastExitAt = h.restarts, h.lastExit, h.lastExitAt
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -6,65 +6,117 @@ package supervisor
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
 	"github.com/bdobrica/Ruriko/internal/gitai/mcp"
+	"github.com/bdobrica/Ruriko/internal/gitai/reconcile"
 )
 
 const restartDelay = 5 * time.Second
 
+// readinessProbeInterval is the wait between readiness probe calls.
+const readinessProbeInterval = 500 * time.Millisecond
+
 // Supervisor manages a set of MCP server processes.
 type Supervisor struct {
-	mu        sync.RWMutex
-	clients   map[string]*mcp.Client
+	// opMu serialises Reconcile, ApplySecrets and Stop. They stop servers
+	// with mu released, so Get and status calls are not held up for a
+	// shutdown grace period, and opMu keeps them from interleaving meanwhile.
+	opMu     sync.Mutex
+	mu       sync.RWMutex
+	clients  map[string]*mcp.Client
+	watchers map[string]context.CancelFunc // auto_restart watchers by server name
+	// initializing holds running servers whose readiness probe has not
+	// passed yet; Get hides them.
+	initializing map[string]bool
+	// health records restarts and the last exit of each configured server.
+	health    map[string]*serverHealth
 	specs     []gosutospec.MCPServer
 	secretEnv map[string]string // env vars injected into all MCP processes
-	ctx       context.Context
-	cancel    context.CancelFunc
+	grace     time.Duration     // SIGTERM-to-SIGKILL wait when stopping a server
+	// restartDelay is the wait before an exited auto_restart server is
+	// started again; restartDelay by default, shortened by tests.
+	restartDelay time.Duration
+	ctx          context.Context
+	cancel       context.CancelFunc
 }
 
 // New creates a Supervisor with no servers running yet.
 func New() *Supervisor {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Supervisor{
-		clients:   make(map[string]*mcp.Client),
-		secretEnv: make(map[string]string),
-		ctx:       ctx,
-		cancel:    cancel,
+		clients:      make(map[string]*mcp.Client),
+		watchers:     make(map[string]context.CancelFunc),
+		initializing: make(map[string]bool),
+		health:       make(map[string]*serverHealth),
+		secretEnv:    make(map[string]string),
+		grace:        mcp.DefaultShutdownGrace,
+		restartDelay: restartDelay,
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 }
 
+// SetShutdownGrace sets how long a stopping MCP server may take to exit
+// after SIGTERM before it is killed. Values <= 0 are ignored.
+func (s *Supervisor) SetShutdownGrace(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.grace = d
+	s.mu.Unlock()
+}
+
 // ApplySecrets updates the environment injected into MCP processes.
 // Running processes are restarted so they pick up refreshed credentials.
 func (s *Supervisor) ApplySecrets(env map[string]string) {
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.secretEnv = env
-
 	if len(s.clients) == 0 {
+		s.mu.Unlock()
 		return
 	}
 
 	// Restart running MCP servers so refreshed secret env is applied immediately.
-	for name, client := range s.clients {
+	var stopping []*mcp.Client
+	for name := range s.clients {
 		slog.Info("supervisor: restarting mcp server to apply updated secrets", "name", name)
-		client.Close()
-		delete(s.clients, name)
+		stopping = append(stopping, s.detachLocked(name))
 	}
+	grace := s.grace
+	s.mu.Unlock()
+
+	shutdownClients(stopping, grace)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	for _, sp := range s.specs {
-		s.startLocked(sp)
+		_ = s.startLocked(sp)
 	}
 }
 
 // Reconcile ensures that exactly the servers in specs are running.
-// Servers no longer in the new spec are stopped; new ones are started.
-func (s *Supervisor) Reconcile(specs []gosutospec.MCPServer) {
+// Servers no longer in the new spec are stopped, servers whose process spec
+// (command, args, env, auto_restart, resources, readiness) changed are
+// restarted, and new or not-yet-running ones are started. The result lists
+// what changed.
+func (s *Supervisor) Reconcile(specs []gosutospec.MCPServer) reconcile.Result {
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	var res reconcile.Result
 
 	// Index new specs by name.
 	wanted := make(map[string]gosutospec.MCPServer, len(specs))
@@ -72,32 +124,132 @@ func (s *Supervisor) Reconcile(specs []gosutospec.MCPServer) {
 		wanted[sp.Name] = sp
 	}
 
-	// Stop servers not in new spec.
-	for name, client := range s.clients {
-		if _, ok := wanted[name]; !ok {
-			slog.Info("supervisor: stopping mcp server", "name", name)
-			client.Close()
-			delete(s.clients, name)
+	// Stop servers not in the new spec, and those whose spec changed.
+	changed := make(map[string]bool)
+	var stopping []*mcp.Client
+	for _, old := range s.specs {
+		newSpec, ok := wanted[old.Name]
+		switch {
+		case !ok:
+			slog.Info("supervisor: stopping mcp server", "name", old.Name)
+			if _, running := s.clients[old.Name]; running {
+				res.Stopped = append(res.Stopped, old.Name)
+			}
+			stopping = append(stopping, s.detachLocked(old.Name))
+			delete(s.health, old.Name)
+		case mcpProcessChanged(old, newSpec):
+			slog.Info("supervisor: restarting changed mcp server", "name", old.Name)
+			stopping = append(stopping, s.detachLocked(old.Name))
+			changed[old.Name] = true
 		}
 	}
+	grace := s.grace
+	s.mu.Unlock()
+
+	// A changed server's old process is gone before its replacement starts.
+	shutdownClients(stopping, grace)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// Start new or changed servers.
+	// Start new, changed, or previously failed servers. Running servers
+	// pick up a new call limit without a restart.
 	for name, sp := range wanted {
-		if _, running := s.clients[name]; !running {
-			s.startLocked(sp)
+		if client, running := s.clients[name]; running {
+			client.LimitConcurrentCalls(sp.MaxConcurrentCalls)
+			continue
+		}
+		if err := s.startLocked(sp); err != nil {
+			res.Failed = append(res.Failed, reconcile.Failure{Name: name, Err: err.Error()})
+			continue
+		}
+		if changed[name] {
+			res.Restarted = append(res.Restarted, name)
+		} else {
+			res.Started = append(res.Started, name)
 		}
 	}
 	s.specs = specs
+	res.Sort()
+	return res
+}
+
+// detachLocked forgets the named server and cancels its restart watcher,
+// returning its client, or nil when it is not running, for the caller to
+// shut down once s.mu is released. Must be called with s.mu held.
+func (s *Supervisor) detachLocked(name string) *mcp.Client {
+	if cancel, ok := s.watchers[name]; ok {
+		cancel()
+		delete(s.watchers, name)
+	}
+	client := s.clients[name]
+	delete(s.clients, name)
+	delete(s.initializing, name)
+	return client
+}
+
+// shutdownClients shuts the clients down in parallel, each with the given
+// grace period, and waits for all of them. nil entries are skipped.
+func shutdownClients(clients []*mcp.Client, grace time.Duration) {
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		if c == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Shutdown(grace)
+		}()
+	}
+	wg.Wait()
+}
+
+// mcpProcessChanged reports whether newSpec needs a fresh process. Changes
+// to tool exposure only affect the agent, not the server process.
+func mcpProcessChanged(old, newSpec gosutospec.MCPServer) bool {
+	return old.Command != newSpec.Command ||
+		!stringSliceEqual(old.Args, newSpec.Args) ||
+		!stringMapEqual(old.Env, newSpec.Env) ||
+		old.AutoRestart != newSpec.AutoRestart ||
+		old.Resources != newSpec.Resources ||
+		!reflect.DeepEqual(old.Readiness, newSpec.Readiness)
+}
+
+// processLimits returns the resource limits the spec asks for.
+func processLimits(sp gosutospec.MCPServer) mcp.ProcessLimits {
+	return mcp.ProcessLimits{
+		MaxMemoryMB: sp.Resources.MaxMemoryMB,
+		Niceness:    sp.Resources.Niceness,
+	}
 }
 
-// Get returns the live mcp.Client for the named server, or nil.
+// Get returns the live mcp.Client for the named server, or nil when it is
+// not running or its readiness probe has not passed yet.
 func (s *Supervisor) Get(name string) *mcp.Client {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	if s.initializing[name] {
+		return nil
+	}
 	return s.clients[name]
 }
 
-// Names returns all currently running MCP server names.
+// Initializing returns the sorted names of running MCP servers that are
+// still waiting for their readiness probe to pass.
+func (s *Supervisor) Initializing() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]string, 0, len(s.initializing))
+	for k := range s.initializing {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Names returns all currently running MCP server names, including those
+// still initializing.
 func (s *Supervisor) Names() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -108,43 +260,174 @@ func (s *Supervisor) Names() []string {
 	return out
 }
 
-// Stop shuts down all managed MCP processes.
+// Stop shuts down all managed MCP processes. Each gets SIGTERM and the
+// shutdown grace period to exit before it is killed; the servers are stopped
+// in parallel, so Stop takes at most one grace period.
 func (s *Supervisor) Stop() {
-	s.cancel()
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	for _, cancel := range s.watchers {
+		cancel()
+	}
+	stopping := make([]*mcp.Client, 0, len(s.clients))
 	for name, c := range s.clients {
 		slog.Info("supervisor: stopping mcp server", "name", name)
-		c.Close()
+		stopping = append(stopping, c)
 	}
+	grace := s.grace
 	s.clients = make(map[string]*mcp.Client)
+	s.watchers = make(map[string]context.CancelFunc)
+	s.initializing = make(map[string]bool)
+	s.mu.Unlock()
+
+	shutdownClients(stopping, grace)
+	// Cancel the process context only after the graceful shutdown: its
+	// cancellation kills every process started under it.
+	s.cancel()
 }
 
 // startLocked starts a single MCP server and, if auto_restart is enabled,
-// watches for unexpected exit and restarts it. Must be called with s.mu held.
-func (s *Supervisor) startLocked(sp gosutospec.MCPServer) {
-	env := s.buildEnvLocked(sp)
-	client, err := mcp.NewClient(s.ctx, sp.Name, sp.Command, sp.Args, env)
+// watches for unexpected exit and restarts it. Any previous watcher for the
+// same name is replaced. Must be called with s.mu held.
+func (s *Supervisor) startLocked(sp gosutospec.MCPServer) error {
+	if cancel, ok := s.watchers[sp.Name]; ok {
+		cancel()
+		delete(s.watchers, sp.Name)
+	}
+	if sp.AutoRestart {
+		ctx, cancel := context.WithCancel(s.ctx)
+		s.watchers[sp.Name] = cancel
+		go s.watchAndRestart(ctx, sp)
+	}
+
+	env, err := s.buildEnvLocked(sp)
+	if err != nil {
+		slog.Error("supervisor: cannot build mcp server env", "name", sp.Name, "err", err)
+		return err
+	}
+	client, err := mcp.NewClient(s.ctx, sp.Name, sp.Command, sp.Args, env, processLimits(sp))
 	if err != nil {
 		slog.Error("supervisor: failed to start mcp server", "name", sp.Name, "err", err)
-		if sp.AutoRestart {
-			go s.watchAndRestart(sp)
-		}
-		return
+		s.recordExitLocked(sp.Name, "start failed: "+err.Error())
+		return err
 	}
+	s.addClientLocked(sp, client)
+	return nil
+}
+
+// addClientLocked registers a freshly started client and begins watching
+// it. Must be called with s.mu held.
+func (s *Supervisor) addClientLocked(sp gosutospec.MCPServer, client *mcp.Client) {
+	client.LimitConcurrentCalls(sp.MaxConcurrentCalls)
 	s.clients[sp.Name] = client
-	if sp.AutoRestart {
-		go s.watchAndRestart(sp)
+	s.awaitReadyLocked(sp, client)
+	go s.watchExit(sp.Name, client)
+}
+
+// watchExit waits for client's process to exit. An exit the supervisor did
+// not ask for removes the client, so Get stops returning it and the
+// auto_restart watcher, if any, starts a new process.
+func (s *Supervisor) watchExit(name string, client *mcp.Client) {
+	<-client.Done()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clients[name] != client {
+		return // stopped or replaced on purpose
+	}
+	delete(s.clients, name)
+	delete(s.initializing, name)
+	reason := exitReason(client.ExitErr())
+	s.recordExitLocked(name, reason)
+	slog.Warn("supervisor: mcp server exited unexpectedly", "name", name, "reason", reason)
+}
+
+// exitReason describes how a process exited.
+func exitReason(err error) string {
+	if err == nil {
+		return "exited with status 0"
 	}
+	return err.Error()
 }
 
-// watchAndRestart waits for a process to exit, then restarts it after restartDelay.
-func (s *Supervisor) watchAndRestart(sp gosutospec.MCPServer) {
+// awaitReadyLocked marks client as initializing and starts its readiness
+// probe when sp configures one. Must be called with s.mu held.
+func (s *Supervisor) awaitReadyLocked(sp gosutospec.MCPServer, client *mcp.Client) {
+	if sp.Readiness == nil {
+		return
+	}
+	s.initializing[sp.Name] = true
+	go s.probeReadiness(sp.Name, *sp.Readiness, client)
+}
+
+// probeReadiness calls the readiness probe tool until it succeeds, then
+// marks client ready. A server that is not ready within the timeout is
+// stopped; its auto_restart watcher, if any, starts it again later. The
+// probe gives up as soon as client is stopped or replaced.
+func (s *Supervisor) probeReadiness(name string, r gosutospec.MCPReadiness, client *mcp.Client) {
+	ctx, cancel := context.WithTimeout(s.ctx, r.Timeout())
+	defer cancel()
+	start := time.Now()
 	for {
+		err := probeOnce(ctx, client, r)
+		s.mu.Lock()
+		if s.clients[name] != client {
+			s.mu.Unlock()
+			return
+		}
+		if err == nil {
+			delete(s.initializing, name)
+			s.mu.Unlock()
+			slog.Info("supervisor: mcp server passed readiness probe",
+				"name", name, "elapsed", time.Since(start).Round(time.Millisecond))
+			return
+		}
+		if ctx.Err() != nil {
+			slog.Error("supervisor: mcp server not ready in time; stopping it",
+				"name", name, "timeout", r.Timeout(), "err", err)
+			delete(s.clients, name)
+			delete(s.initializing, name)
+			grace := s.grace
+			s.mu.Unlock()
+			client.Shutdown(grace)
+			return
+		}
+		s.mu.Unlock()
 		select {
-		case <-s.ctx.Done():
+		case <-ctx.Done():
+		case <-time.After(readinessProbeInterval):
+		}
+	}
+}
+
+// probeOnce makes one readiness probe call. A call that fails or returns a
+// tool error means the server is not ready.
+func probeOnce(ctx context.Context, client *mcp.Client, r gosutospec.MCPReadiness) error {
+	args := r.Arguments
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	res, err := client.CallTool(ctx, r.Tool, args)
+	if err != nil {
+		return err
+	}
+	if res.IsError {
+		return fmt.Errorf("probe tool %q reported an error", r.Tool)
+	}
+	return nil
+}
+
+// watchAndRestart waits for a process to exit, then restarts it after
+// restartDelay. It returns when ctx is cancelled (server stopped or replaced).
+// Each restart uses the server's current spec: a config apply that only
+// changes settings such as maxConcurrentCalls keeps this watcher.
+func (s *Supervisor) watchAndRestart(ctx context.Context, sp gosutospec.MCPServer) {
+	for {
+		select {
+		case <-ctx.Done():
 			return
-		case <-time.After(restartDelay):
+		case <-time.After(s.restartDelay):
 		}
 
 		s.mu.RLock()
@@ -156,38 +439,75 @@ func (s *Supervisor) watchAndRestart(sp gosutospec.MCPServer) {
 		}
 
 		slog.Info("supervisor: restarting mcp server", "name", sp.Name)
-		env := s.buildEnvLocked(sp)
-		client, err := mcp.NewClient(s.ctx, sp.Name, sp.Command, sp.Args, env)
+		s.mu.RLock()
+		sp = s.specLocked(sp)
+		env, err := s.buildEnvLocked(sp)
+		s.mu.RUnlock()
 		if err != nil {
 			slog.Error("supervisor: restart failed", "name", sp.Name, "err", err)
 			continue
 		}
+		client, err := mcp.NewClient(s.ctx, sp.Name, sp.Command, sp.Args, env, processLimits(sp))
+		if err != nil {
+			slog.Error("supervisor: restart failed", "name", sp.Name, "err", err)
+			s.mu.Lock()
+			if ctx.Err() == nil {
+				s.recordExitLocked(sp.Name, "restart failed: "+err.Error())
+			}
+			s.mu.Unlock()
+			continue
+		}
 		s.mu.Lock()
-		s.clients[sp.Name] = client
+		if ctx.Err() != nil {
+			// Stopped or replaced while the process was starting.
+			s.mu.Unlock()
+			client.Close()
+			return
+		}
+		s.addClientLocked(sp, client)
+		s.healthLocked(sp.Name).restarts++
 		s.mu.Unlock()
 	}
 }
 
+// specLocked returns the configured spec for sp's server, or sp itself when
+// it is not (yet) in the applied config. Must be called with s.mu held.
+func (s *Supervisor) specLocked(sp gosutospec.MCPServer) gosutospec.MCPServer {
+	for _, cur := range s.specs {
+		if cur.Name == sp.Name {
+			return cur
+		}
+	}
+	return sp
+}
+
 // buildEnv merges the system environment, static MCP spec env, and injected secrets.
-func (s *Supervisor) buildEnv(sp gosutospec.MCPServer) []string {
+func (s *Supervisor) buildEnv(sp gosutospec.MCPServer) ([]string, error) {
 	s.mu.RLock()
 	secretEnv := s.secretEnv
 	s.mu.RUnlock()
 	return buildEnv(sp, secretEnv)
 }
 
-func (s *Supervisor) buildEnvLocked(sp gosutospec.MCPServer) []string {
+func (s *Supervisor) buildEnvLocked(sp gosutospec.MCPServer) ([]string, error) {
 	return buildEnv(sp, s.secretEnv)
 }
 
-func buildEnv(sp gosutospec.MCPServer, secretEnv map[string]string) []string {
+// buildEnv returns the process environment for sp. Variable references in
+// sp.Env are expanded (see expandSpecEnv); an unresolved required variable
+// is an error.
+func buildEnv(sp gosutospec.MCPServer, secretEnv map[string]string) ([]string, error) {
+	specEnv, err := expandSpecEnv(sp.Env, secretEnv)
+	if err != nil {
+		return nil, err
+	}
 	base := os.Environ()
-	extra := make([]string, 0, len(sp.Env)+len(secretEnv))
-	for k, v := range sp.Env {
+	extra := make([]string, 0, len(specEnv)+len(secretEnv))
+	for k, v := range specEnv {
 		extra = append(extra, k+"="+v)
 	}
 	for k, v := range secretEnv {
 		extra = append(extra, k+"="+v)
 	}
-	return append(base, extra...)
+	return append(base, extra...), nil
 }
@@ -0,0 +1,472 @@
+package supervisor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/gitai/mcp"
+)
+
+const (
+	fakeMCPEnv = "GITAI_SUPERVISOR_FAKE_MCP"
+	// fakeMCPTermEnv makes the fake outlive stdin EOF and either exit cleanly
+	// on SIGTERM ("clean") or ignore it ("ignore").
+	fakeMCPTermEnv = "GITAI_SUPERVISOR_FAKE_MCP_SIGTERM"
+	// fakeMCPDirEnv, when set, is where the fake writes its pid and, on a
+	// clean SIGTERM exit, a "terminated" marker.
+	fakeMCPDirEnv = "GITAI_SUPERVISOR_FAKE_MCP_DIR"
+	// fakeMCPReadyEnv, when set, names a file: tools/call succeeds once it
+	// exists and returns a tool error before.
+	fakeMCPReadyEnv = "GITAI_SUPERVISOR_FAKE_MCP_READY_FILE"
+	// fakeMCPSlowEnv makes tools/call take fakeSlowCall, answered
+	// concurrently, with the number of calls in flight when it started.
+	fakeMCPSlowEnv = "GITAI_SUPERVISOR_FAKE_MCP_SLOW"
+	// fakeMCPCrashEnv makes the fake exit with status 3 on any tools/call.
+	fakeMCPCrashEnv = "GITAI_SUPERVISOR_FAKE_MCP_CRASH"
+)
+
+const fakeSlowCall = 200 * time.Millisecond
+
+// TestFakeMCPProcess is not a real test: it is the body of the fake MCP
+// process started by fakeMCP and returns immediately otherwise.
+func TestMain(m *testing.M) {
+	// MCP servers started with resource limits re-execute this test binary
+	// as the limits wrapper.
+	mcp.RunLimitsWrapper()
+	os.Exit(m.Run())
+}
+
+func TestFakeMCPProcess(t *testing.T) {
+	if os.Getenv(fakeMCPEnv) != "1" {
+		return
+	}
+	mode, dir := os.Getenv(fakeMCPTermEnv), os.Getenv(fakeMCPDirEnv)
+	if dir != "" {
+		_ = os.WriteFile(filepath.Join(dir, "pid"), []byte(strconv.Itoa(os.Getpid())), 0o600)
+	}
+	if mode != "" {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGTERM)
+		go func() {
+			for range sigs {
+				if mode == "clean" {
+					_ = os.WriteFile(filepath.Join(dir, "terminated"), nil, 0o600)
+					os.Exit(0)
+				}
+			}
+		}()
+	}
+	out := json.NewEncoder(os.Stdout)
+	var outMu sync.Mutex
+	var inFlight atomic.Int64
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     *int64 `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue
+		}
+		resp := mcp.Response{JSONRPC: "2.0", ID: *req.ID}
+		switch {
+		case req.Method == "initialize":
+			resp.Result = mcp.InitializeResult{
+				ProtocolVersion: "2024-11-05",
+				ServerInfo:      mcp.ServerInfo{Name: "fake-mcp", Version: "1"},
+			}
+		case req.Method == "tools/call" && os.Getenv(fakeMCPCrashEnv) == "1" && req.Params.Name == "crash":
+			os.Exit(3)
+		case req.Method == "tools/call" && os.Getenv(fakeMCPSlowEnv) == "1":
+			go func(resp mcp.Response) {
+				n := inFlight.Add(1)
+				time.Sleep(fakeSlowCall)
+				inFlight.Add(-1)
+				resp.Result = mcp.CallToolResult{Content: []mcp.ContentItem{{Type: "text", Text: strconv.FormatInt(n, 10)}}}
+				outMu.Lock()
+				_ = out.Encode(resp)
+				outMu.Unlock()
+			}(resp)
+			continue
+		case req.Method == "tools/call" && os.Getenv(fakeMCPReadyEnv) != "":
+			_, err := os.Stat(os.Getenv(fakeMCPReadyEnv))
+			resp.Result = mcp.CallToolResult{IsError: err != nil}
+		default:
+			resp.Error = &mcp.ResponseError{Code: -32601, Message: "method not found: " + req.Method}
+		}
+		outMu.Lock()
+		_ = out.Encode(resp)
+		outMu.Unlock()
+	}
+	if mode != "" {
+		select {} // wait for SIGTERM or SIGKILL
+	}
+	os.Exit(0)
+}
+
+// fakeMCP returns a spec that runs the test binary as a fake MCP server.
+func fakeMCP(name string) gosutospec.MCPServer {
+	return gosutospec.MCPServer{
+		Name:    name,
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeMCPProcess$"},
+		Env:     map[string]string{fakeMCPEnv: "1"},
+	}
+}
+
+// fakeTermMCP returns a fake MCP server that stays up after stdin closes and
+// handles SIGTERM according to mode, recording its pid in dir.
+func fakeTermMCP(name, mode, dir string) gosutospec.MCPServer {
+	sp := fakeMCP(name)
+	sp.Env = map[string]string{fakeMCPEnv: "1", fakeMCPTermEnv: mode, fakeMCPDirEnv: dir}
+	return sp
+}
+
+// fakeSlowMCP returns a fake MCP server with a readiness probe that passes
+// once readyFile exists.
+func fakeSlowMCP(name, readyFile string, timeoutSeconds int) gosutospec.MCPServer {
+	sp := fakeMCP(name)
+	sp.Env = map[string]string{fakeMCPEnv: "1", fakeMCPReadyEnv: readyFile}
+	sp.Readiness = &gosutospec.MCPReadiness{Tool: "ping", TimeoutSeconds: timeoutSeconds}
+	return sp
+}
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestReconcile_HidesServerUntilReady(t *testing.T) {
+	readyFile := filepath.Join(t.TempDir(), "ready")
+	s := New()
+	defer s.Stop()
+
+	res := s.Reconcile([]gosutospec.MCPServer{fakeSlowMCP("slow", readyFile, 0)})
+	if got := strings.Join(res.Started, ","); got != "slow" {
+		t.Fatalf("started = %q, want slow", got)
+	}
+	if s.Get("slow") != nil {
+		t.Error("Get returned a server whose readiness probe has not passed")
+	}
+	if got := strings.Join(s.Initializing(), ","); got != "slow" {
+		t.Errorf("initializing = %q, want slow", got)
+	}
+	if got := strings.Join(s.Names(), ","); got != "slow" {
+		t.Errorf("names = %q, want the initializing server listed", got)
+	}
+
+	if err := os.WriteFile(readyFile, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "slow to become ready", func() bool { return s.Get("slow") != nil })
+	if got := s.Initializing(); len(got) != 0 {
+		t.Errorf("initializing = %v after the probe passed, want none", got)
+	}
+}
+
+func TestReadiness_StopsServerNotReadyInTime(t *testing.T) {
+	s := New()
+	defer s.Stop()
+	s.SetShutdownGrace(100 * time.Millisecond)
+
+	s.Reconcile([]gosutospec.MCPServer{fakeSlowMCP("stuck", filepath.Join(t.TempDir(), "never"), 1)})
+	waitFor(t, "stuck to be stopped", func() bool { return len(s.Names()) == 0 })
+	if got := s.Initializing(); len(got) != 0 {
+		t.Errorf("initializing = %v, want none after the timeout", got)
+	}
+}
+
+// overlappingCalls makes two concurrent tool calls to the slow fake MCP and
+// returns the in-flight counts they report.
+func overlappingCalls(t *testing.T, client *mcp.Client) []string {
+	t.Helper()
+	var wg sync.WaitGroup
+	seen := make([]string, 2)
+	for i := range seen {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			res, err := client.CallTool(ctx, "slow", nil)
+			if err != nil {
+				t.Errorf("CallTool: %v", err)
+				return
+			}
+			seen[i] = res.Content[0].Text
+		}()
+	}
+	wg.Wait()
+	sort.Strings(seen)
+	return seen
+}
+
+func TestMaxConcurrentCalls_SerializesCalls(t *testing.T) {
+	s := New()
+	defer s.Stop()
+	sp := fakeMCP("sqlite")
+	sp.Env[fakeMCPSlowEnv] = "1"
+	sp.MaxConcurrentCalls = 1
+	s.Reconcile([]gosutospec.MCPServer{sp})
+	client := s.Get("sqlite")
+	if client == nil {
+		t.Fatal("expected sqlite to be running")
+	}
+
+	start := time.Now()
+	if got := strings.Join(overlappingCalls(t, client), ","); got != "1,1" {
+		t.Errorf("in-flight counts = %s, want 1,1 (calls serialized)", got)
+	}
+	if elapsed := time.Since(start); elapsed < 2*fakeSlowCall {
+		t.Errorf("two calls took %v, want at least %v when serialized", elapsed, 2*fakeSlowCall)
+	}
+
+	// Lifting the limit applies to the running server without a restart.
+	sp.MaxConcurrentCalls = 0
+	if res := s.Reconcile([]gosutospec.MCPServer{sp}); len(res.Restarted) != 0 {
+		t.Errorf("restarted = %v, want no restart for a call limit change", res.Restarted)
+	}
+	if s.Get("sqlite") != client {
+		t.Fatal("server was replaced")
+	}
+	if got := strings.Join(overlappingCalls(t, client), ","); got != "1,2" {
+		t.Errorf("in-flight counts = %s, want 1,2 without a limit", got)
+	}
+}
+
+// crashMCP makes the fake MCP server named name exit with status 3.
+func crashMCP(t *testing.T, s *Supervisor, name string) {
+	t.Helper()
+	client := s.Get(name)
+	if client == nil {
+		t.Fatalf("expected %s to be running", name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.CallTool(ctx, "crash", nil); err == nil {
+		t.Fatal("expected the crashing call to fail")
+	}
+	waitFor(t, name+" to be marked down", func() bool { return s.Get(name) == nil })
+}
+
+func TestStatus_ReportsCrashedServer(t *testing.T) {
+	s := New()
+	defer s.Stop()
+	healthy := fakeMCP("docs")
+	fragile := fakeMCP("sqlite")
+	fragile.Env[fakeMCPCrashEnv] = "1"
+	s.Reconcile([]gosutospec.MCPServer{healthy, fragile})
+
+	crashMCP(t, s, "sqlite")
+
+	st := s.Status()
+	if len(st) != 2 {
+		t.Fatalf("status = %+v, want two servers", st)
+	}
+	if st[0].Name != "docs" || st[0].State != StateRunning || st[0].LastExit != "" {
+		t.Errorf("docs = %+v, want running", st[0])
+	}
+	if st[1].Name != "sqlite" || st[1].State != StateCrashed || st[1].LastExit != "exit status 3" || st[1].LastExitAt.IsZero() {
+		t.Errorf("sqlite = %+v, want crashed with exit status 3", st[1])
+	}
+	if got := strings.Join(s.Names(), ","); got != "docs" {
+		t.Errorf("names = %q, want the crashed server removed", got)
+	}
+
+	// A reconcile starts the crashed server again.
+	res := s.Reconcile([]gosutospec.MCPServer{healthy, fragile})
+	if got := strings.Join(res.Started, ","); got != "sqlite" {
+		t.Errorf("started = %q, want sqlite", got)
+	}
+	if st := s.Status(); st[1].State != StateRunning || st[1].LastExit != "exit status 3" {
+		t.Errorf("sqlite after reconcile = %+v, want running with the last exit kept", st[1])
+	}
+}
+
+func TestStatus_CrashedAutoRestartServerIsRestarting(t *testing.T) {
+	s := New()
+	defer s.Stop()
+	sp := fakeMCP("sqlite")
+	sp.Env[fakeMCPCrashEnv] = "1"
+	sp.AutoRestart = true
+	s.Reconcile([]gosutospec.MCPServer{sp})
+
+	crashMCP(t, s, "sqlite")
+
+	if st := s.Status(); len(st) != 1 || st[0].State != StateRestarting || st[0].LastExit != "exit status 3" {
+		t.Errorf("status = %+v, want restarting after exit status 3", st)
+	}
+}
+
+func TestAutoRestart_UsesCurrentSpec(t *testing.T) {
+	s := New()
+	defer s.Stop()
+	s.restartDelay = 50 * time.Millisecond
+	sp := fakeMCP("sqlite")
+	sp.Env[fakeMCPCrashEnv] = "1"
+	sp.Env[fakeMCPSlowEnv] = "1"
+	sp.AutoRestart = true
+	s.Reconcile([]gosutospec.MCPServer{sp})
+
+	// A call limit change keeps the process and its restart watcher.
+	sp.MaxConcurrentCalls = 1
+	if res := s.Reconcile([]gosutospec.MCPServer{sp}); len(res.Restarted) != 0 {
+		t.Fatalf("restarted = %v, want no restart for a call limit change", res.Restarted)
+	}
+
+	crashMCP(t, s, "sqlite")
+	waitFor(t, "sqlite to be restarted", func() bool { return s.Get("sqlite") != nil })
+	if got := strings.Join(overlappingCalls(t, s.Get("sqlite")), ","); got != "1,1" {
+		t.Errorf("in-flight counts = %s, want 1,1 (the restarted server keeps the new limit)", got)
+	}
+}
+
+func TestReconcile_StopsServerGracefully(t *testing.T) {
+	dir := t.TempDir()
+	s := New()
+	defer s.Stop()
+	s.SetShutdownGrace(10 * time.Second)
+
+	s.Reconcile([]gosutospec.MCPServer{fakeTermMCP("stateful", "clean", dir)})
+	if s.Get("stateful") == nil {
+		t.Fatal("expected stateful to be running")
+	}
+
+	start := time.Now()
+	res := s.Reconcile(nil)
+	if got := strings.Join(res.Stopped, ","); got != "stateful" {
+		t.Errorf("stopped = %q, want stateful", got)
+	}
+	if elapsed := time.Since(start); elapsed >= 10*time.Second {
+		t.Errorf("stop took %v; the server should have exited on SIGTERM", elapsed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "terminated")); err != nil {
+		t.Errorf("server did not see SIGTERM before exiting: %v", err)
+	}
+}
+
+func TestStop_KillsServerAfterGrace(t *testing.T) {
+	dir := t.TempDir()
+	s := New()
+	const grace = 300 * time.Millisecond
+	s.SetShutdownGrace(grace)
+
+	s.Reconcile([]gosutospec.MCPServer{fakeTermMCP("stubborn", "ignore", dir)})
+	raw, err := os.ReadFile(filepath.Join(dir, "pid"))
+	if err != nil {
+		t.Fatalf("read pid: %v", err)
+	}
+	pid, _ := strconv.Atoi(string(raw))
+
+	start := time.Now()
+	s.Stop()
+	if elapsed := time.Since(start); elapsed < grace {
+		t.Errorf("Stop returned after %v, before the %v grace period", elapsed, grace)
+	}
+	if err := syscall.Kill(pid, 0); !errors.Is(err, syscall.ESRCH) {
+		t.Errorf("process %d still exists after Stop (kill 0: %v)", pid, err)
+	}
+}
+
+func TestReconcile_StopDoesNotBlockLookups(t *testing.T) {
+	dir := t.TempDir()
+	s := New()
+	defer s.Stop()
+	const grace = time.Second
+	s.SetShutdownGrace(grace)
+
+	s.Reconcile([]gosutospec.MCPServer{fakeTermMCP("stubborn", "ignore", dir), fakeMCP("steady")})
+	if s.Get("steady") == nil {
+		t.Fatal("expected steady to be running")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Reconcile([]gosutospec.MCPServer{fakeMCP("steady")})
+	}()
+	waitFor(t, "stubborn to be detached", func() bool { return len(s.Names()) == 1 })
+
+	start := time.Now()
+	if s.Get("steady") == nil {
+		t.Error("steady disappeared while stubborn was stopping")
+	}
+	if elapsed := time.Since(start); elapsed > grace/2 {
+		t.Errorf("Get took %v while a server was stopping", elapsed)
+	}
+	select {
+	case <-done:
+		t.Error("Reconcile returned before the stubborn server's grace period")
+	default:
+	}
+	<-done
+}
+
+func TestReconcile_ReportsStartedStoppedAndFailed(t *testing.T) {
+	s := New()
+	defer s.Stop()
+
+	broken := gosutospec.MCPServer{Name: "ghost", Command: "/nonexistent/ghost-mcp"}
+	res := s.Reconcile([]gosutospec.MCPServer{fakeMCP("alpha"), broken})
+	if got := strings.Join(res.Started, ","); got != "alpha" {
+		t.Errorf("started = %q, want alpha", got)
+	}
+	if len(res.Failed) != 1 || res.Failed[0].Name != "ghost" || res.Failed[0].Err == "" {
+		t.Errorf("failed = %+v, want ghost with an error", res.Failed)
+	}
+	if s.Get("alpha") == nil {
+		t.Fatal("expected alpha to be running")
+	}
+
+	res = s.Reconcile([]gosutospec.MCPServer{fakeMCP("beta")})
+	if got := strings.Join(res.Started, ","); got != "beta" {
+		t.Errorf("started = %q, want beta", got)
+	}
+	if got := strings.Join(res.Stopped, ","); got != "alpha" {
+		t.Errorf("stopped = %q, want alpha", got)
+	}
+	if s.Get("alpha") != nil {
+		t.Error("expected alpha to be stopped")
+	}
+}
+
+func TestReconcile_RestartsChangedServer(t *testing.T) {
+	s := New()
+	defer s.Stop()
+
+	s.Reconcile([]gosutospec.MCPServer{fakeMCP("alpha")})
+	changed := fakeMCP("alpha")
+	changed.Env["EXTRA"] = "1"
+
+	res := s.Reconcile([]gosutospec.MCPServer{changed})
+	if got := strings.Join(res.Restarted, ","); got != "alpha" {
+		t.Errorf("restarted = %q, want alpha", got)
+	}
+	if res = s.Reconcile([]gosutospec.MCPServer{changed}); !res.Empty() {
+		t.Errorf("unchanged reconcile = %s, want no changes", res)
+	}
+}
@@ -15,16 +15,20 @@ import (
 
 	"maunium.net/go/mautrix/event"
 
+	"github.com/bdobrica/Ruriko/common/debugserver"
 	"github.com/bdobrica/Ruriko/internal/ruriko/approvals"
 	"github.com/bdobrica/Ruriko/internal/ruriko/audit"
 	"github.com/bdobrica/Ruriko/internal/ruriko/commands"
 	rurikoconfig "github.com/bdobrica/Ruriko/internal/ruriko/config"
+	"github.com/bdobrica/Ruriko/internal/ruriko/dashboard"
 	"github.com/bdobrica/Ruriko/internal/ruriko/kuze"
 	"github.com/bdobrica/Ruriko/internal/ruriko/matrix"
 	"github.com/bdobrica/Ruriko/internal/ruriko/memory"
 	"github.com/bdobrica/Ruriko/internal/ruriko/nlp"
 	"github.com/bdobrica/Ruriko/internal/ruriko/provisioning"
+	"github.com/bdobrica/Ruriko/internal/ruriko/registration"
 	"github.com/bdobrica/Ruriko/internal/ruriko/runtime"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
 	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/docker"
 	"github.com/bdobrica/Ruriko/internal/ruriko/secrets"
 	"github.com/bdobrica/Ruriko/internal/ruriko/store"
@@ -40,6 +44,15 @@ type Config struct {
 	EnableDocker      bool
 	DockerNetwork     string
 	ReconcileInterval time.Duration
+	// HeartbeatInterval is how often Ruriko polls ACP GET /health on every
+	// enabled agent with a control URL to refresh last_seen.  Zero disables
+	// the heartbeat monitor.
+	HeartbeatInterval time.Duration
+	// LivenessStaleAfter and LivenessOfflineAfter are the last_seen ages at
+	// which agents.list / agents.show report an agent as stale or offline.
+	// Zero values use runtime.DefaultLivenessThresholds.
+	LivenessStaleAfter   time.Duration
+	LivenessOfflineAfter time.Duration
 	// AdminSenders is an optional allowlist of Matrix user IDs (e.g. "@alice:example.com")
 	// permitted to execute commands. When empty, any room member can send commands.
 	AdminSenders []string
@@ -53,6 +66,13 @@ type Config struct {
 	// HTTPAddr is the TCP address for the optional health/status HTTP server
 	// (e.g. ":8080"). When empty the server is disabled.
 	HTTPAddr string
+	// HTTPAccessLog logs every request to the HTTP server (method, path,
+	// status, duration, remote address). Kuze tokens in paths are redacted.
+	HTTPAccessLog bool
+	// DebugPprof serves the net/http/pprof handlers on DebugPprofAddr, a
+	// separate listener that must be bound to a loopback address.
+	DebugPprof     bool
+	DebugPprofAddr string
 	// KuzeBaseURL is the externally reachable base URL of the Ruriko HTTP
 	// server (e.g. "https://ruriko.example.com"). When non-empty and HTTPAddr
 	// is also set, the Kuze one-time-link routes are mounted on the HTTP
@@ -65,11 +85,58 @@ type Config struct {
 	// Ruriko posts human-friendly summaries of major control-plane events.
 	// When empty, audit room notifications are disabled.
 	AuditRoomID string
-	// DefaultAgentImage is the container image used for agents created through
-	// the natural-language provisioning wizard (R5.4 stretch goal).
+	// AuditQuietHours is an optional daily window ("HH:MM-HH:MM", local time)
+	// during which non-critical audit room notifications are batched into a
+	// single digest posted when the window ends. Critical events (failures,
+	// security) are always posted immediately. Empty disables quiet hours.
+	AuditQuietHours string
+	// AuditMinSeverity is the minimum severity ("info", "warn", "critical")
+	// an event must have to be posted to the audit room. Empty posts all.
+	AuditMinSeverity string
+	// AuditExtraRoomIDs lists additional Matrix rooms that receive the same
+	// audit notices as AuditRoomID (subject to AuditMinSeverity).
+	AuditExtraRoomIDs []string
+	// AuditSlackWebhookURL is an optional Slack incoming-webhook URL that
+	// receives audit notices. AuditSlackMinSeverity filters them.
+	AuditSlackWebhookURL  string
+	AuditSlackMinSeverity string
+	// AuditWebhookURL is an optional generic HTTP endpoint that receives audit
+	// events as JSON. AuditWebhookMinSeverity filters them.
+	AuditWebhookURL         string
+	AuditWebhookMinSeverity string
+	// DefaultAgentImage is the container image used for new agents when
+	// neither --image nor the template's metadata.image names one.
 	// When empty, "ghcr.io/bdobrica/gitai:latest" is used as a fallback.
 	DefaultAgentImage string
 
+	// RegisterBootstrapToken is the shared secret Gitai agents present when
+	// self-registering via POST /agents/register. When empty (or HTTPAddr is
+	// empty) self-registration is disabled.
+	RegisterBootstrapToken string
+
+	// DashboardToken protects the read-only web dashboard served under /ui/
+	// on the HTTP server. Operators present it as a bearer token or as the
+	// basic-auth password. When empty (or HTTPAddr is empty) the dashboard
+	// is disabled.
+	DashboardToken string
+
+	// GosutoStrict rejects Gosuto configs that produce validation warnings
+	// (unknown gateway keys, uncovered MCP servers, ...) on gosuto set
+	// instead of accepting them with a notice.
+	GosutoStrict bool
+
+	// ACPCAFile is a PEM bundle of CA certificates trusted (on top of the
+	// system roots) when connecting to agents whose ACP server serves HTTPS.
+	ACPCAFile string
+
+	// ACPTLSInsecureSkipVerify disables verification of agent ACP server
+	// certificates. Intended for development with self-signed certificates.
+	ACPTLSInsecureSkipVerify bool
+
+	// ACPGzipMinBytes gzips ACP request bodies of at least this many bytes.
+	// 0 (the default) sends every body uncompressed.
+	ACPGzipMinBytes int
+
 	// WebhookRateLimit is the maximum number of inbound webhook deliveries
 	// accepted per agent per minute before Ruriko starts returning 429.
 	// Defaults to webhook.DefaultRateLimit (60) when zero.
@@ -185,10 +252,14 @@ type App struct {
 	router       *commands.Router
 	handlers     *commands.Handlers
 	reconciler   *runtime.Reconciler
+	heartbeat    *runtime.HeartbeatMonitor
 	healthServer *HealthServer
+	pprofServer  *debugserver.Server
 	kuzeServer   *kuze.Server
 	webhookProxy *webhook.Proxy
 	sealRunner   *memory.SealPipelineRunner
+	quietAudit   *audit.QuietHoursNotifier
+	auditFanout  *audit.Multi
 }
 
 // kuzeTokenAdapter bridges *kuze.Server → secrets.TokenIssuer, breaking the
@@ -212,6 +283,22 @@ func (a *kuzeTokenAdapter) IssueAgentToken(ctx context.Context, agentID, secretR
 
 // New creates a new Ruriko application
 func New(config *Config) (*App, error) {
+	if err := acp.ConfigureTLS(config.ACPCAFile, config.ACPTLSInsecureSkipVerify); err != nil {
+		return nil, fmt.Errorf("failed to configure ACP TLS: %w", err)
+	}
+	if config.ACPTLSInsecureSkipVerify {
+		slog.Warn("ACP TLS certificate verification is disabled")
+	}
+	acp.ConfigureGzip(config.ACPGzipMinBytes)
+
+	var pprofServer *debugserver.Server
+	if config.DebugPprof {
+		var err error
+		if pprofServer, err = debugserver.New(config.DebugPprofAddr); err != nil {
+			return nil, fmt.Errorf("DEBUG_PPROF: %w", err)
+		}
+	}
+
 	// Initialize database
 	slog.Info("opening database", "path", config.DatabasePath)
 	store, err := store.New(config.DatabasePath)
@@ -252,6 +339,8 @@ func New(config *Config) (*App, error) {
 		Secrets:          secretsStore,
 		ConfigStore:      configStore,
 		MatrixHomeserver: config.Matrix.Homeserver,
+		SecretRefreshURL: secretRefreshURL(config),
+		GosutoStrict:     config.GosutoStrict,
 	}
 
 	// Initialize Docker runtime if enabled
@@ -403,11 +492,56 @@ func New(config *Config) (*App, error) {
 	handlersCfg.Approvals = approvalsGate
 	slog.Info("approval workflow ready")
 
-	// Initialise audit room notifier.
+	// Initialise audit notifier sinks (Matrix rooms, Slack, generic webhook).
+	// Each sink filters by its own minimum severity; with more than one sink
+	// configured they are fanned out so a failing sink never blocks the rest.
 	var notifier audit.Notifier = audit.Noop{}
-	if config.AuditRoomID != "" {
-		notifier = audit.NewMatrixNotifier(matrixClient, config.AuditRoomID)
-		slog.Info("audit room notifier ready", "room", config.AuditRoomID)
+	var auditSinks []audit.Notifier
+	if config.AuditRoomID != "" || len(config.AuditExtraRoomIDs) > 0 {
+		minSeverity, err := parseAuditSeverity("audit min severity", config.AuditMinSeverity)
+		if err != nil {
+			return nil, err
+		}
+		for _, roomID := range append([]string{config.AuditRoomID}, config.AuditExtraRoomIDs...) {
+			if roomID == "" {
+				continue
+			}
+			auditSinks = append(auditSinks, audit.NewMatrixNotifier(matrixClient, roomID, audit.MatrixOptions{MinSeverity: minSeverity}))
+			slog.Info("audit room notifier ready", "room", roomID, "min_severity", minSeverity)
+		}
+	}
+	if config.AuditSlackWebhookURL != "" {
+		minSeverity, err := parseAuditSeverity("audit slack min severity", config.AuditSlackMinSeverity)
+		if err != nil {
+			return nil, err
+		}
+		auditSinks = append(auditSinks, audit.NewSlackNotifier(config.AuditSlackWebhookURL, audit.HTTPOptions{MinSeverity: minSeverity}))
+		slog.Info("audit slack notifier ready", "min_severity", minSeverity)
+	}
+	if config.AuditWebhookURL != "" {
+		minSeverity, err := parseAuditSeverity("audit webhook min severity", config.AuditWebhookMinSeverity)
+		if err != nil {
+			return nil, err
+		}
+		auditSinks = append(auditSinks, audit.NewWebhookNotifier(config.AuditWebhookURL, audit.HTTPOptions{MinSeverity: minSeverity}))
+		slog.Info("audit webhook notifier ready", "min_severity", minSeverity)
+	}
+	// Even a single sink goes through Multi so that a slow endpoint never
+	// holds up the command that raised the event.
+	var auditFanout *audit.Multi
+	if len(auditSinks) > 0 {
+		auditFanout = audit.NewMulti(auditSinks...)
+		notifier = auditFanout
+	}
+	var quietAudit *audit.QuietHoursNotifier
+	if config.AuditQuietHours != "" {
+		window, err := audit.ParseQuietHours(config.AuditQuietHours)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit quiet hours: %w", err)
+		}
+		quietAudit = audit.NewQuietHoursNotifier(notifier, window)
+		notifier = quietAudit
+		slog.Info("audit quiet hours enabled", "window", config.AuditQuietHours)
 	}
 	handlersCfg.Notifier = notifier
 
@@ -423,6 +557,22 @@ func New(config *Config) (*App, error) {
 	// template variables ({{.AdminRoom}}, {{.UserRoom}}, etc.).
 	handlersCfg.AdminRooms = config.Matrix.AdminRooms
 
+	// Heartbeat monitor: refreshes last_seen for every reachable agent,
+	// including those running outside the Docker runtime.
+	liveness := runtime.LivenessThresholds{
+		Stale:   config.LivenessStaleAfter,
+		Offline: config.LivenessOfflineAfter,
+	}
+	handlersCfg.Liveness = liveness
+	var heartbeat *runtime.HeartbeatMonitor
+	if config.HeartbeatInterval > 0 {
+		heartbeat = runtime.NewHeartbeatMonitor(store, runtime.HeartbeatConfig{
+			Interval:         config.HeartbeatInterval,
+			ACPClientFactory: runtime.NewACPChecker,
+			Thresholds:       liveness,
+		})
+	}
+
 	// --- R10: Conversation memory -------------------------------------------
 	// Wire the memory subsystem when the NLP provider is available (or will
 	// become available via lazy rebuild), or when explicitly enabled via
@@ -547,6 +697,15 @@ func New(config *Config) (*App, error) {
 	router.Register("agents.delete", handlers.HandleAgentsDelete)
 	router.Register("agents.status", handlers.HandleAgentsStatus)
 	router.Register("agents.cancel", handlers.HandleAgentsCancel)
+	router.Register("agents.diff-live", handlers.HandleAgentsDiffLive)
+	router.Register("agents.dlq", handlers.HandleAgentsDLQ)
+	router.Register("agents.tools", handlers.HandleAgentsTools)
+	router.Register("agents.calls", handlers.HandleAgentsCalls)
+	router.Register("agents.logs", handlers.HandleAgentsLogs)
+	router.Register("agents.transcript", handlers.HandleAgentsTranscript)
+	router.Register("agents.rotate-token", handlers.HandleAgentsRotateToken)
+	router.Register("agents.broadcast", handlers.HandleAgentsBroadcast)
+	router.Register("agents.secrets-check", handlers.HandleAgentsSecretsCheck)
 	router.Register("agents.matrix", handlers.HandleAgentsMatrixRegister)
 	router.Register("agents.disable", handlers.HandleAgentsDisable)
 	router.Register("schedule.upsert", handlers.HandleScheduleUpsert)
@@ -569,9 +728,15 @@ func New(config *Config) (*App, error) {
 	router.Register("gosuto.show", handlers.HandleGosutoShow)
 	router.Register("gosuto.versions", handlers.HandleGosutoVersions)
 	router.Register("gosuto.diff", handlers.HandleGosutoDiff)
+	router.Register("gosuto.history", handlers.HandleGosutoHistory)
+	router.Register("gosuto.coverage", handlers.HandleGosutoCoverage)
 	router.Register("gosuto.set", handlers.HandleGosutoSet)
 	router.Register("gosuto.rollback", handlers.HandleGosutoRollback)
 	router.Register("gosuto.push", handlers.HandleGosutoPush)
+	router.Register("gosuto.suggest", handlers.HandleGosutoSuggest)
+	router.Register("gosuto.canary", handlers.HandleGosutoCanary)
+	router.Register("gosuto.promote", handlers.HandleGosutoPromote)
+	router.Register("gosuto.abort", handlers.HandleGosutoAbort)
 	router.Register("gosuto.set-instructions", handlers.HandleGosutoSetInstructions)
 	router.Register("gosuto.set-persona", handlers.HandleGosutoSetPersona)
 	router.Register("approvals.list", handlers.HandleApprovalsList)
@@ -592,6 +757,7 @@ func New(config *Config) (*App, error) {
 	if config.HTTPAddr != "" {
 		healthServer = NewHealthServer(config.HTTPAddr, store)
 		healthServer.SetNLPStatusProvider(handlers)
+		healthServer.SetAccessLog(config.HTTPAccessLog)
 		if kuzeServer != nil {
 			kuzeServer.RegisterRoutes(healthServer)
 			slog.Info("Kuze routes registered on HTTP server")
@@ -602,6 +768,23 @@ func New(config *Config) (*App, error) {
 		})
 		webhookProxy.RegisterRoutes(healthServer)
 		slog.Info("webhook reverse proxy registered on HTTP server")
+		registration.New(store, registration.Config{
+			BootstrapToken: config.RegisterBootstrapToken,
+			Tunnels:        acp.Tunnels,
+			Refresher:      distributor,
+		}).RegisterRoutes(healthServer)
+		if config.RegisterBootstrapToken != "" {
+			slog.Info("agent self-registration and control tunnel endpoints registered on HTTP server")
+		}
+		slog.Info("agent secret refresh endpoint registered on HTTP server")
+		if config.DashboardToken != "" {
+			dashCfg := dashboard.Config{Token: config.DashboardToken, Approvals: approvalsStore}
+			if kuzeServer != nil {
+				dashCfg.Kuze = kuzeServer
+			}
+			dashboard.New(store, dashCfg).RegisterRoutes(healthServer)
+			slog.Info("read-only dashboard registered on HTTP server", "path", "/ui/")
+		}
 		slog.Info("health server configured", "addr", config.HTTPAddr)
 	}
 
@@ -614,10 +797,14 @@ func New(config *Config) (*App, error) {
 		router:       router,
 		handlers:     handlers,
 		reconciler:   reconciler,
+		heartbeat:    heartbeat,
 		healthServer: healthServer,
+		pprofServer:  pprofServer,
 		kuzeServer:   kuzeServer,
 		webhookProxy: webhookProxy,
 		sealRunner:   sealRunner,
+		quietAudit:   quietAudit,
+		auditFanout:  auditFanout,
 	}, nil
 }
 
@@ -632,6 +819,11 @@ func (a *App) Run() error {
 			slog.Warn("health server failed to start; continuing without it", "err", err)
 		}
 	}
+	if a.pprofServer != nil {
+		if err := a.pprofServer.Start(ctx); err != nil {
+			slog.Warn("pprof server failed to start; continuing without it", "err", err)
+		}
+	}
 
 	// Start Matrix client
 	slog.Info("starting Matrix sync")
@@ -644,6 +836,11 @@ func (a *App) Run() error {
 		go a.reconciler.Run(ctx)
 	}
 
+	// Start heartbeat monitor in background if configured
+	if a.heartbeat != nil {
+		go a.heartbeat.Run(ctx)
+	}
+
 	// Start conversation seal-check runner (R10.5).  Periodically scans for
 	// conversations past their cooldown and processes them through the
 	// summarise → embed → store LTM pipeline.
@@ -651,6 +848,11 @@ func (a *App) Run() error {
 		go a.sealRunner.Run(ctx)
 	}
 
+	// Flush the quiet-hours audit digest once the window ends.
+	if a.quietAudit != nil {
+		go a.quietAudit.Run(ctx)
+	}
+
 	// Start Kuze token-pruning loop.  Expired tokens are detected, Matrix
 	// expiry notifications are sent, then the rows are deleted.  The loop
 	// runs on the same cadence as KuzeTTL (defaulting to kuze.DefaultTTL).
@@ -693,6 +895,11 @@ func (a *App) Run() error {
 
 // Stop stops the Ruriko application
 func (a *App) Stop() {
+	// Deliver queued audit notices while the Matrix client still runs.
+	if a.auditFanout != nil {
+		a.auditFanout.Close()
+	}
+
 	slog.Info("stopping Matrix client")
 	a.matrix.Stop()
 
@@ -700,6 +907,9 @@ func (a *App) Stop() {
 		slog.Info("stopping health server")
 		a.healthServer.Stop()
 	}
+	if a.pprofServer != nil {
+		a.pprofServer.Stop()
+	}
 
 	slog.Info("closing database")
 	a.store.Close()
@@ -967,6 +1177,19 @@ func resolveMemoryAPIKey(explicit, envRef string) string {
 	return os.Getenv("RURIKO_NLP_API_KEY")
 }
 
+// parseAuditSeverity parses an optional severity setting; empty means no
+// filtering (zero severity).
+func parseAuditSeverity(name, value string) (audit.Severity, error) {
+	if value == "" {
+		return 0, nil
+	}
+	sev, err := audit.ParseSeverity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return sev, nil
+}
+
 // orDefault returns s if non-empty, otherwise fallback.
 func orDefault(s, fallback string) string {
 	if s != "" {
@@ -974,3 +1197,12 @@ func orDefault(s, fallback string) string {
 	}
 	return fallback
 }
+
+// secretRefreshURL returns the URL agents call to request fresh secret
+// leases, or "" when Ruriko has no externally reachable HTTP server.
+func secretRefreshURL(config *Config) string {
+	if config.HTTPAddr == "" || config.KuzeBaseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(config.KuzeBaseURL, "/") + "/agents/secrets/refresh"
+}
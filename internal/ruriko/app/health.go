@@ -1,6 +1,7 @@
 package app
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/bdobrica/Ruriko/common/redact"
 	"github.com/bdobrica/Ruriko/common/version"
 )
 
@@ -22,6 +24,7 @@ type HealthServer struct {
 	startedAt time.Time
 	server    *http.Server
 	mux       *http.ServeMux
+	accessLog bool // log every request; see SetAccessLog
 }
 
 // statusProvider is the minimal interface the health server needs from Store.
@@ -73,7 +76,50 @@ func NewHealthServer(addr string, sp statusProvider) *HealthServer {
 // ServeHTTP implements http.Handler so the server can be tested without a
 // live network listener (e.g. with httptest.NewRecorder).
 func (h *HealthServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h.mux.ServeHTTP(w, r)
+	if !h.accessLog {
+		h.mux.ServeHTTP(w, r)
+		return
+	}
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.mux.ServeHTTP(rec, r)
+	slog.Info("http access",
+		"method", r.Method,
+		"path", redact.URL(r.URL.Path),
+		"status", rec.status,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"remote", r.RemoteAddr,
+	)
+}
+
+// SetAccessLog enables or disables the access log: one structured log line
+// per request with method, path, status, duration and remote address. Kuze
+// one-time tokens in the path and any query string are redacted. Call this
+// before Start.
+func (h *HealthServer) SetAccessLog(enabled bool) {
+	h.accessLog = enabled
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack passes through to the underlying writer so the WebSocket control
+// tunnel keeps working with the access log enabled.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("health server: response writer cannot be hijacked")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hj.Hijack()
 }
 
 // SetNLPStatusProvider wires in the NLP provider health reporter.  Call this
@@ -1,10 +1,13 @@
 package app_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/bdobrica/Ruriko/internal/ruriko/app"
@@ -119,3 +122,88 @@ func TestHealthServer_StatusNLPProvider_NoProvider(t *testing.T) {
 		t.Errorf("expected nlp_provider='unavailable' without provider, got %v", resp["nlp_provider"])
 	}
 }
+
+// captureLogs routes the default slog logger into a buffer of JSON lines for
+// the duration of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	orig := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(orig) })
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	return &buf
+}
+
+// TestHealthServer_AccessLog verifies that each request produces one access
+// log line and that Kuze tokens never appear in it.
+func TestHealthServer_AccessLog(t *testing.T) {
+	logs := captureLogs(t)
+	hs := app.NewHealthServer("127.0.0.1:0", &noopStore{})
+	hs.SetAccessLog(true)
+	hs.Handle("/kuze/redeem/", http.NotFoundHandler())
+	hs.Handle("/s/", http.NotFoundHandler())
+
+	for _, path := range []string{"/health", "/kuze/redeem/tok-secret-1", "/s/tok-secret-2?x=tok-secret-3"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.7:4242"
+		hs.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if strings.Contains(logs.String(), "tok-secret") {
+		t.Fatalf("access log leaked a token:\n%s", logs.String())
+	}
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode log line %q: %v", line, err)
+		}
+		if rec["msg"] == "http access" {
+			lines = append(lines, rec)
+		}
+	}
+	want := []struct {
+		path   string
+		status float64
+	}{
+		{"/health", 200},
+		{"/kuze/redeem/[REDACTED]", 404},
+		{"/s/[REDACTED]", 404},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d access log lines, want %d:\n%s", len(lines), len(want), logs.String())
+	}
+	for i, w := range want {
+		got := lines[i]
+		if got["method"] != "GET" || got["path"] != w.path || got["status"] != w.status || got["remote"] != "10.0.0.7:4242" {
+			t.Errorf("line %d = %v, want GET %s %v from 10.0.0.7:4242", i, got, w.path, w.status)
+		}
+		if _, ok := got["duration_ms"]; !ok {
+			t.Errorf("line %d has no duration_ms", i)
+		}
+	}
+}
+
+// TestHealthServer_AccessLogDisabledByDefault verifies that nothing is
+// logged per request unless SetAccessLog(true) is called.
+func TestHealthServer_AccessLogDisabledByDefault(t *testing.T) {
+	logs := captureLogs(t)
+	hs := app.NewHealthServer("127.0.0.1:0", &noopStore{})
+	hs.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if strings.Contains(logs.String(), "http access") {
+		t.Errorf("unexpected access log line:\n%s", logs.String())
+	}
+}
+
+func TestHealthServer_DoesNotServePprof(t *testing.T) {
+	hs := app.NewHealthServer("127.0.0.1:0", &noopStore{})
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/cmdline"} {
+		w := httptest.NewRecorder()
+		hs.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404: pprof must only be served on the loopback debug listener", path, w.Code)
+		}
+	}
+}
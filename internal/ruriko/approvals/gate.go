@@ -13,6 +13,7 @@ import (
 // gatedActions is the set of command handler keys that require approval before
 // execution. Handlers check this via IsGated() before proceeding.
 var gatedActions = map[string]bool{
+	"agents.broadcast":     true,
 	"agents.delete":        true,
 	"agents.disable":       true,
 	"secrets.delete":       true,
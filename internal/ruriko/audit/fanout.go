@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+)
+
+// sinkTimeout bounds each HTTP sink delivery so a slow endpoint cannot hold
+// up the caller.
+const sinkTimeout = 5 * time.Second
+
+// multiQueueSize is how many events may wait for one slow sink before
+// further events to that sink are dropped.
+const multiQueueSize = 64
+
+// Multi fans each event out to several sinks without blocking the caller.
+// Every sink has its own queue and delivery goroutine, so events reach each
+// sink in order, and one slow or broken sink never delays the others or the
+// command that raised the event. Every sink applies its own severity filter
+// and handles its own failures. Close drains the queues.
+type Multi struct {
+	sinks []*multiSink
+	wg    sync.WaitGroup
+
+	mu     sync.RWMutex // guards closed against Notify sending on closed queues
+	closed bool
+}
+
+// multiSink is one sink and its queue of pending deliveries.
+type multiSink struct {
+	n  Notifier
+	ch chan delivery
+}
+
+// delivery is a queued event with the context it was raised under.
+type delivery struct {
+	ctx context.Context
+	evt Event
+}
+
+// NewMulti creates a Multi notifier over sinks and starts their delivery
+// goroutines. Nil sinks are ignored.
+func NewMulti(sinks ...Notifier) *Multi {
+	m := &Multi{}
+	for _, n := range sinks {
+		if n == nil {
+			continue
+		}
+		s := &multiSink{n: n, ch: make(chan delivery, multiQueueSize)}
+		m.sinks = append(m.sinks, s)
+		m.wg.Add(1)
+		go m.run(s)
+	}
+	return m
+}
+
+// Notify queues evt for every sink and returns at once. The deliveries keep
+// ctx's values, such as the trace ID, but not its cancellation, since the
+// caller is usually done before they are. A sink whose queue is full misses
+// the event, which is logged. After Close, events are dropped.
+func (m *Multi) Notify(ctx context.Context, evt Event) {
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+	d := delivery{ctx: context.WithoutCancel(ctx), evt: evt}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		slog.Warn("audit notifier: closed; event dropped", "kind", evt.Kind)
+		return
+	}
+	for _, s := range m.sinks {
+		select {
+		case s.ch <- d:
+		default:
+			slog.Warn("audit notifier: sink queue full; event dropped", "sink", fmt.Sprintf("%T", s.n), "kind", evt.Kind)
+		}
+	}
+}
+
+// Close stops accepting events and waits until every queued event has been
+// delivered.
+func (m *Multi) Close() {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		for _, s := range m.sinks {
+			close(s.ch)
+		}
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// run delivers s's queued events one at a time until Close. A panic in the
+// sink is recovered and logged.
+func (m *Multi) run(s *multiSink) {
+	defer m.wg.Done()
+	for d := range s.ch {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("audit notifier: sink panicked", "sink", fmt.Sprintf("%T", s.n), "kind", d.evt.Kind, "panic", r)
+				}
+			}()
+			s.n.Notify(d.ctx, d.evt)
+		}()
+	}
+}
+
+// HTTPOptions configures the Slack and webhook sinks.
+type HTTPOptions struct {
+	// MinSeverity drops events whose effective severity is below this level.
+	// Zero posts everything.
+	MinSeverity Severity
+	// Client overrides the HTTP client. Defaults to a client with a 5s timeout.
+	Client *http.Client
+}
+
+func (o HTTPOptions) client() *http.Client {
+	if o.Client != nil {
+		return o.Client
+	}
+	return &http.Client{Timeout: sinkTimeout}
+}
+
+// SlackNotifier posts notices to a Slack incoming-webhook URL.
+type SlackNotifier struct {
+	url         string
+	minSeverity Severity
+	client      *http.Client
+}
+
+// NewSlackNotifier creates a SlackNotifier posting to webhookURL.
+// Zero or one HTTPOptions value may be supplied.
+func NewSlackNotifier(webhookURL string, opts ...HTTPOptions) *SlackNotifier {
+	var o HTTPOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return &SlackNotifier{url: webhookURL, minSeverity: o.MinSeverity, client: o.client()}
+}
+
+// Notify posts evt as a Slack message. Errors are logged at WARN level.
+func (n *SlackNotifier) Notify(ctx context.Context, evt Event) {
+	if n.url == "" || evt.EffectiveSeverity() < n.minSeverity {
+		return
+	}
+	body := map[string]string{"text": formatNotice(ctx, evt)}
+	if err := postJSON(ctx, n.client, n.url, body); err != nil {
+		slog.Warn("audit notifier: slack delivery failed", "kind", evt.Kind, "err", err)
+	}
+}
+
+// webhookPayload is the JSON body sent by WebhookNotifier.
+type webhookPayload struct {
+	Kind      Kind      `json:"kind"`
+	Severity  string    `json:"severity"`
+	Actor     string    `json:"actor,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	Message   string    `json:"message"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// WebhookNotifier posts events as structured JSON to a generic HTTP endpoint.
+type WebhookNotifier struct {
+	url         string
+	minSeverity Severity
+	client      *http.Client
+}
+
+// NewWebhookNotifier creates a WebhookNotifier posting to url.
+// Zero or one HTTPOptions value may be supplied.
+func NewWebhookNotifier(url string, opts ...HTTPOptions) *WebhookNotifier {
+	var o HTTPOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return &WebhookNotifier{url: url, minSeverity: o.MinSeverity, client: o.client()}
+}
+
+// Notify posts evt as JSON. Errors are logged at WARN level.
+func (n *WebhookNotifier) Notify(ctx context.Context, evt Event) {
+	if n.url == "" || evt.EffectiveSeverity() < n.minSeverity {
+		return
+	}
+	tid := evt.TraceID
+	if tid == "" {
+		tid = trace.FromContext(ctx)
+	}
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+	payload := webhookPayload{
+		Kind:      evt.Kind,
+		Severity:  evt.EffectiveSeverity().String(),
+		Actor:     evt.Actor,
+		Target:    evt.Target,
+		Message:   evt.Message,
+		TraceID:   tid,
+		Timestamp: evt.Timestamp.UTC(),
+	}
+	if err := postJSON(ctx, n.client, n.url, payload); err != nil {
+		slog.Warn("audit notifier: webhook delivery failed", "kind", evt.Kind, "err", err)
+	}
+}
+
+// postJSON POSTs body as JSON and treats any non-2xx response as an error.
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, sinkTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
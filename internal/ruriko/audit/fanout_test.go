@@ -0,0 +1,179 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/audit"
+)
+
+// captureServer records JSON bodies posted to it.
+type captureServer struct {
+	mu     sync.Mutex
+	bodies []map[string]interface{}
+	srv    *httptest.Server
+}
+
+func newCaptureServer(t *testing.T, status int) *captureServer {
+	t.Helper()
+	c := &captureServer{}
+	c.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		c.mu.Lock()
+		c.bodies = append(c.bodies, body)
+		c.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(c.srv.Close)
+	return c
+}
+
+func (c *captureServer) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.bodies)
+}
+
+// failingSender always errors.
+type failingSender struct{}
+
+func (failingSender) SendNotice(_, _ string) error { return errors.New("homeserver down") }
+
+// panickingNotifier simulates a buggy sink.
+type panickingNotifier struct{}
+
+func (panickingNotifier) Notify(context.Context, audit.Event) { panic("boom") }
+
+func TestMulti_DeliversToAllHealthySinks(t *testing.T) {
+	matrixSender := &fakeSender{}
+	slack := newCaptureServer(t, http.StatusOK)
+	hook := newCaptureServer(t, http.StatusOK)
+
+	m := audit.NewMulti(
+		audit.NewMatrixNotifier(matrixSender, "!audit:example.com"),
+		audit.NewSlackNotifier(slack.srv.URL),
+		audit.NewWebhookNotifier(hook.srv.URL),
+	)
+	m.Notify(context.Background(), audit.Event{
+		Kind: audit.KindAgentCreated, Target: "saito", Message: "created", TraceID: "t_fan",
+	})
+	m.Close()
+
+	if len(matrixSender.notices) != 1 {
+		t.Errorf("matrix: expected 1 notice, got %d", len(matrixSender.notices))
+	}
+	if slack.count() != 1 {
+		t.Fatalf("slack: expected 1 post, got %d", slack.count())
+	}
+	if text, _ := slack.bodies[0]["text"].(string); !strings.Contains(text, "saito") {
+		t.Errorf("slack text missing target: %q", text)
+	}
+	if hook.count() != 1 {
+		t.Fatalf("webhook: expected 1 post, got %d", hook.count())
+	}
+	body := hook.bodies[0]
+	if body["kind"] != string(audit.KindAgentCreated) || body["severity"] != "info" || body["trace_id"] != "t_fan" {
+		t.Errorf("unexpected webhook payload: %v", body)
+	}
+}
+
+func TestMulti_FailingSinkDoesNotBlockOthers(t *testing.T) {
+	broken := newCaptureServer(t, http.StatusInternalServerError)
+	healthy := newCaptureServer(t, http.StatusOK)
+	matrixSender := &fakeSender{}
+
+	m := audit.NewMulti(
+		audit.NewMatrixNotifier(failingSender{}, "!down:example.com"),
+		audit.NewWebhookNotifier(broken.srv.URL),
+		audit.NewWebhookNotifier("http://127.0.0.1:1/unreachable"),
+		panickingNotifier{},
+		audit.NewWebhookNotifier(healthy.srv.URL),
+		audit.NewMatrixNotifier(matrixSender, "!audit:example.com"),
+	)
+	m.Notify(context.Background(), audit.Event{Kind: audit.KindError, Message: "provisioning failed"})
+	m.Close()
+
+	if healthy.count() != 1 {
+		t.Errorf("healthy webhook: expected 1 post, got %d", healthy.count())
+	}
+	if len(matrixSender.notices) != 1 {
+		t.Errorf("healthy matrix sink: expected 1 notice, got %d", len(matrixSender.notices))
+	}
+}
+
+func TestMulti_PerSinkSeverity(t *testing.T) {
+	everything := newCaptureServer(t, http.StatusOK)
+	criticalOnly := newCaptureServer(t, http.StatusOK)
+
+	m := audit.NewMulti(
+		audit.NewWebhookNotifier(everything.srv.URL),
+		audit.NewSlackNotifier(criticalOnly.srv.URL, audit.HTTPOptions{MinSeverity: audit.SeverityCritical}),
+	)
+	ctx := context.Background()
+	m.Notify(ctx, audit.Event{Kind: audit.KindAgentStarted, Message: "started"})
+	m.Notify(ctx, audit.Event{Kind: audit.KindError, Message: "boom"})
+	m.Close()
+
+	if everything.count() != 2 {
+		t.Errorf("unfiltered sink: expected 2 posts, got %d", everything.count())
+	}
+	if criticalOnly.count() != 1 {
+		t.Errorf("critical-only sink: expected 1 post, got %d", criticalOnly.count())
+	}
+}
+
+// blockingNotifier records events but holds each delivery until release is
+// closed.
+type blockingNotifier struct {
+	release chan struct{}
+	mu      sync.Mutex
+	kinds   []audit.Kind
+}
+
+func (b *blockingNotifier) Notify(_ context.Context, evt audit.Event) {
+	<-b.release
+	b.mu.Lock()
+	b.kinds = append(b.kinds, evt.Kind)
+	b.mu.Unlock()
+}
+
+func TestMulti_NotifyDoesNotWaitForSinks(t *testing.T) {
+	slow := &blockingNotifier{release: make(chan struct{})}
+	matrixSender := &fakeSender{}
+	m := audit.NewMulti(slow, audit.NewMatrixNotifier(matrixSender, "!audit:example.com"))
+
+	returned := make(chan struct{})
+	go func() {
+		m.Notify(context.Background(), audit.Event{Kind: audit.KindAgentStarted, Message: "started"})
+		m.Notify(context.Background(), audit.Event{Kind: audit.KindAgentStopped, Message: "stopped"})
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Notify blocked on a slow sink")
+	}
+
+	close(slow.release)
+	m.Close()
+	if len(matrixSender.notices) != 2 {
+		t.Errorf("matrix: expected 2 notices, got %d", len(matrixSender.notices))
+	}
+	if len(slow.kinds) != 2 || slow.kinds[0] != audit.KindAgentStarted || slow.kinds[1] != audit.KindAgentStopped {
+		t.Errorf("slow sink got %v, want both events in order", slow.kinds)
+	}
+
+	// Late events after Close are dropped, not a panic.
+	m.Notify(context.Background(), audit.Event{Kind: audit.KindError, Message: "late"})
+	if len(matrixSender.notices) != 2 {
+		t.Errorf("matrix: got %d notices after Close, want 2", len(matrixSender.notices))
+	}
+}
@@ -10,6 +10,16 @@
 //   - KindApprovalRequested, KindApprovalApproved, KindApprovalDenied
 //   - KindSecretsRotated, KindSecretsPushed
 //   - KindError
+//   - KindDigest (quiet-hours summary, see QuietHoursNotifier)
+//
+// Besides MatrixNotifier, events can be delivered to a Slack incoming webhook
+// (SlackNotifier) or a generic JSON endpoint (WebhookNotifier); Multi fans
+// events out to several such sinks in the background, each with its own
+// severity filter.
+//
+// Each event carries a Severity (info, warn, critical). When a call site
+// leaves it unset, DefaultSeverity derives one from the Kind. MatrixNotifier
+// drops events below its configured minimum severity.
 //
 // All events include the originating trace ID so operators can quickly look
 // up the full audit log entry with /ruriko trace <id>.
@@ -19,6 +29,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/bdobrica/Ruriko/common/trace"
@@ -42,10 +53,68 @@ const (
 	KindError             Kind = "error"
 )
 
+// Severity ranks how urgently an operator needs to see an event. The zero
+// value means "unset" and is resolved with DefaultSeverity.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarn
+	SeverityCritical
+)
+
+// String returns the lower-case name of the severity ("info", "warn",
+// "critical"), or "unset" for the zero value.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unset"
+	}
+}
+
+// ParseSeverity parses "info", "warn"/"warning" or "critical"
+// (case-insensitive).
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warn", "warning":
+		return SeverityWarn, nil
+	case "critical":
+		return SeverityCritical, nil
+	default:
+		return 0, fmt.Errorf("unknown severity %q (want info, warn or critical)", s)
+	}
+}
+
+// DefaultSeverity returns the severity used for events of kind k when the
+// call site does not set one. Failures and security-relevant events are
+// critical; approval requests are also critical because they block work and
+// expire if not acted on.
+func DefaultSeverity(k Kind) Severity {
+	switch k {
+	case KindError, KindSecretsRotated, KindAgentDisabled, KindApprovalRequested:
+		return SeverityCritical
+	case KindAgentDeleted, KindAgentRespawned, KindApprovalDenied:
+		return SeverityWarn
+	default:
+		return SeverityInfo
+	}
+}
+
 // Event carries the data that the audit notifier formats and sends.
 type Event struct {
 	// Kind identifies the type of event.
 	Kind Kind
+	// Severity ranks the event for filtering. When zero, DefaultSeverity(Kind)
+	// is used.
+	Severity Severity
 	// Actor is the Matrix user ID that triggered the event.
 	Actor string
 	// Target is the primary resource affected (agent name, secret name, …).
@@ -59,6 +128,15 @@ type Event struct {
 	Timestamp time.Time
 }
 
+// EffectiveSeverity returns e.Severity, falling back to DefaultSeverity(e.Kind)
+// when unset.
+func (e Event) EffectiveSeverity() Severity {
+	if e.Severity != 0 {
+		return e.Severity
+	}
+	return DefaultSeverity(e.Kind)
+}
+
 // Notifier sends audit room notifications for major control-plane events.
 type Notifier interface {
 	// Notify posts an audit event. Implementations MUST NOT block the caller
@@ -73,15 +151,28 @@ type Sender interface {
 	SendNotice(roomID, message string) error
 }
 
+// MatrixOptions configures a MatrixNotifier.
+type MatrixOptions struct {
+	// MinSeverity drops events whose effective severity is below this level.
+	// Zero posts everything.
+	MinSeverity Severity
+}
+
 // MatrixNotifier posts formatted notices to a Matrix audit room.
 type MatrixNotifier struct {
-	sender Sender
-	roomID string
+	sender      Sender
+	roomID      string
+	minSeverity Severity
 }
 
 // NewMatrixNotifier creates a MatrixNotifier that posts to roomID via sender.
-func NewMatrixNotifier(sender Sender, roomID string) *MatrixNotifier {
-	return &MatrixNotifier{sender: sender, roomID: roomID}
+// Zero or one MatrixOptions value may be supplied.
+func NewMatrixNotifier(sender Sender, roomID string, opts ...MatrixOptions) *MatrixNotifier {
+	n := &MatrixNotifier{sender: sender, roomID: roomID}
+	if len(opts) > 0 {
+		n.minSeverity = opts[0].MinSeverity
+	}
+	return n
 }
 
 // Notify formats evt as a human-readable notice and posts it to the audit room.
@@ -90,14 +181,29 @@ func (n *MatrixNotifier) Notify(ctx context.Context, evt Event) {
 	if n.roomID == "" {
 		return
 	}
+	if evt.EffectiveSeverity() < n.minSeverity {
+		slog.Debug("audit notifier: below minimum severity",
+			"kind", evt.Kind, "severity", evt.EffectiveSeverity(), "min", n.minSeverity)
+		return
+	}
+
+	msg := formatNotice(ctx, evt)
+
+	if err := n.sender.SendNotice(n.roomID, msg); err != nil {
+		slog.Warn("audit notifier: failed to send room notice",
+			"room", n.roomID, "kind", evt.Kind, "err", err)
+	} else {
+		slog.Debug("audit notifier: sent notice", "room", n.roomID, "kind", evt.Kind)
+	}
+}
 
+// formatNotice renders evt as the human-readable notice text shared by the
+// Matrix and Slack sinks. The trace ID falls back to the one in ctx.
+func formatNotice(ctx context.Context, evt Event) string {
 	tid := evt.TraceID
 	if tid == "" {
 		tid = trace.FromContext(ctx)
 	}
-	if evt.Timestamp.IsZero() {
-		evt.Timestamp = time.Now()
-	}
 
 	icon := kindIcon(evt.Kind)
 	msg := fmt.Sprintf("%s [%s] %s", icon, evt.Kind, evt.Message)
@@ -110,13 +216,7 @@ func (n *MatrixNotifier) Notify(ctx context.Context, evt Event) {
 	if evt.Actor != "" {
 		msg = fmt.Sprintf("%s\n  actor: %s", msg, evt.Actor)
 	}
-
-	if err := n.sender.SendNotice(n.roomID, msg); err != nil {
-		slog.Warn("audit notifier: failed to send room notice",
-			"room", n.roomID, "kind", evt.Kind, "err", err)
-	} else {
-		slog.Debug("audit notifier: sent notice", "room", n.roomID, "kind", evt.Kind)
-	}
+	return msg
 }
 
 // Noop is a no-op Notifier used when audit room notifications are disabled.
@@ -152,6 +252,8 @@ func kindIcon(k Kind) string {
 		return "📤"
 	case KindError:
 		return "🚨"
+	case KindDigest:
+		return "🌙"
 	default:
 		return "ℹ️"
 	}
@@ -54,6 +54,56 @@ func TestMatrixNotifier_NoopWhenEmptyRoom(t *testing.T) {
 	}
 }
 
+func TestMatrixNotifier_MinSeverityFilters(t *testing.T) {
+	sender := &fakeSender{}
+	n := audit.NewMatrixNotifier(sender, "!room:example.com", audit.MatrixOptions{MinSeverity: audit.SeverityWarn})
+	ctx := context.Background()
+
+	// Below threshold: explicit info severity is dropped.
+	n.Notify(ctx, audit.Event{Kind: audit.KindAgentStarted, Severity: audit.SeverityInfo, Target: "a", Message: "started"})
+	if len(sender.notices) != 0 {
+		t.Fatalf("expected info event to be filtered, got %d notices", len(sender.notices))
+	}
+
+	// At/above threshold: posted.
+	n.Notify(ctx, audit.Event{Kind: audit.KindAgentDeleted, Severity: audit.SeverityWarn, Target: "a", Message: "deleted"})
+	n.Notify(ctx, audit.Event{Kind: audit.KindError, Severity: audit.SeverityCritical, Target: "a", Message: "boom"})
+	if len(sender.notices) != 2 {
+		t.Fatalf("expected 2 notices, got %d", len(sender.notices))
+	}
+}
+
+func TestMatrixNotifier_MinSeverityUsesKindDefault(t *testing.T) {
+	sender := &fakeSender{}
+	n := audit.NewMatrixNotifier(sender, "!room:example.com", audit.MatrixOptions{MinSeverity: audit.SeverityCritical})
+	ctx := context.Background()
+
+	n.Notify(ctx, audit.Event{Kind: audit.KindAgentStopped, Message: "stopped"})
+	n.Notify(ctx, audit.Event{Kind: audit.KindError, Message: "boom"})
+
+	if len(sender.notices) != 1 || !containsStr(sender.notices[0], "boom") {
+		t.Fatalf("expected only the error notice, got %q", sender.notices)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	cases := map[string]audit.Severity{
+		"info":     audit.SeverityInfo,
+		"WARN":     audit.SeverityWarn,
+		"warning":  audit.SeverityWarn,
+		"critical": audit.SeverityCritical,
+	}
+	for in, want := range cases {
+		got, err := audit.ParseSeverity(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSeverity(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := audit.ParseSeverity("loud"); err == nil {
+		t.Error("expected error for unknown severity")
+	}
+}
+
 func TestNoop(t *testing.T) {
 	// Must not panic.
 	audit.Noop{}.Notify(context.Background(), audit.Event{Kind: audit.KindError, Message: "boom"})
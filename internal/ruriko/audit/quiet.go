@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KindDigest is the kind used for the summary notice posted when a quiet-hours
+// window ends and batched events are flushed.
+const KindDigest Kind = "audit.digest"
+
+// quietFlushInterval is how often QuietHoursNotifier.Run checks whether the
+// quiet window has ended and a digest is due.
+const quietFlushInterval = time.Minute
+
+// QuietHours is a daily time-of-day window in local time, e.g. 22:00–07:00.
+// Start and End are offsets from midnight. A window where End <= Start wraps
+// past midnight.
+type QuietHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseQuietHours parses a window of the form "HH:MM-HH:MM" (e.g.
+// "22:00-07:00"). Start and end must differ.
+func ParseQuietHours(spec string) (QuietHours, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("quiet hours %q: expected HH:MM-HH:MM", spec)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("quiet hours %q: start: %w", spec, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("quiet hours %q: end: %w", spec, err)
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("quiet hours %q: start and end must differ", spec)
+	}
+	return QuietHours{Start: start, End: end}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls inside the quiet window.
+func (q QuietHours) Contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+// QuietHoursNotifier wraps another Notifier and batches non-critical events
+// while the quiet window is active. Critical events (see Event.EffectiveSeverity)
+// are forwarded immediately. Batched events are posted as a single KindDigest
+// notice once the window ends — either on the next Notify call outside the
+// window or from the periodic check in Run, whichever comes first.
+type QuietHoursNotifier struct {
+	next   Notifier
+	window QuietHours
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending []Event
+}
+
+// NewQuietHoursNotifier creates a QuietHoursNotifier forwarding to next.
+func NewQuietHoursNotifier(next Notifier, window QuietHours) *QuietHoursNotifier {
+	return NewQuietHoursNotifierWithClock(next, window, time.Now)
+}
+
+// NewQuietHoursNotifierWithClock is like NewQuietHoursNotifier but injects a
+// custom clock. Intended for tests.
+func NewQuietHoursNotifierWithClock(next Notifier, window QuietHours, now func() time.Time) *QuietHoursNotifier {
+	return &QuietHoursNotifier{next: next, window: window, now: now}
+}
+
+// Notify forwards evt immediately when it is critical or the quiet window is
+// inactive; otherwise it is queued for the end-of-window digest.
+func (n *QuietHoursNotifier) Notify(ctx context.Context, evt Event) {
+	now := n.now()
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = now
+	}
+
+	if n.window.Contains(now) {
+		if evt.EffectiveSeverity() >= SeverityCritical {
+			n.next.Notify(ctx, evt)
+			return
+		}
+		n.mu.Lock()
+		n.pending = append(n.pending, evt)
+		n.mu.Unlock()
+		return
+	}
+
+	n.Flush(ctx)
+	n.next.Notify(ctx, evt)
+}
+
+// Flush posts a digest of any batched events when the quiet window is no
+// longer active. It is a no-op while the window is active or when nothing is
+// pending.
+func (n *QuietHoursNotifier) Flush(ctx context.Context) {
+	if n.window.Contains(n.now()) {
+		return
+	}
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = nil
+	n.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	n.next.Notify(ctx, Event{
+		Kind:      KindDigest,
+		Severity:  maxSeverity(pending),
+		Message:   formatDigest(pending),
+		Timestamp: n.now(),
+	})
+}
+
+// Pending returns the number of events currently batched.
+func (n *QuietHoursNotifier) Pending() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.pending)
+}
+
+// Run periodically flushes the digest once the quiet window ends. It blocks
+// until ctx is cancelled. Call this in a goroutine.
+func (n *QuietHoursNotifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(quietFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.Flush(ctx)
+		}
+	}
+}
+
+// maxSeverity returns the highest effective severity among events, so the
+// digest passes any severity filter that at least one batched event would.
+func maxSeverity(events []Event) Severity {
+	highest := SeverityInfo
+	for _, evt := range events {
+		if s := evt.EffectiveSeverity(); s > highest {
+			highest = s
+		}
+	}
+	return highest
+}
+
+// formatDigest renders the batched events as a compact multi-line summary.
+func formatDigest(events []Event) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "quiet-hours digest: %d event(s)", len(events))
+	for _, evt := range events {
+		line := fmt.Sprintf("%s %s [%s] %s", evt.Timestamp.Format("15:04"), kindIcon(evt.Kind), evt.Kind, evt.Message)
+		if evt.Target != "" {
+			line = fmt.Sprintf("%s %s %s → %s", evt.Timestamp.Format("15:04"), kindIcon(evt.Kind), evt.Target, evt.Message)
+		}
+		if evt.TraceID != "" {
+			line += " (trace: " + evt.TraceID + ")"
+		}
+		sb.WriteString("\n• ")
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
@@ -0,0 +1,150 @@
+package audit_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/audit"
+)
+
+// recordingNotifier collects every event forwarded to it.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, evt audit.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, evt)
+}
+
+// fakeClock is a settable clock for quiet-hours tests.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+func at(hour, minute int) time.Time {
+	return time.Date(2026, 3, 10, hour, minute, 0, 0, time.Local)
+}
+
+func TestParseQuietHours(t *testing.T) {
+	q, err := audit.ParseQuietHours("22:00-07:30")
+	if err != nil {
+		t.Fatalf("ParseQuietHours: %v", err)
+	}
+	if q.Start != 22*time.Hour || q.End != 7*time.Hour+30*time.Minute {
+		t.Errorf("unexpected window: %+v", q)
+	}
+
+	for _, bad := range []string{"", "22:00", "25:00-07:00", "22:00-22:00", "late-early"} {
+		if _, err := audit.ParseQuietHours(bad); err == nil {
+			t.Errorf("ParseQuietHours(%q): expected error", bad)
+		}
+	}
+}
+
+func TestQuietHours_Contains(t *testing.T) {
+	overnight, _ := audit.ParseQuietHours("22:00-07:00")
+	daytime, _ := audit.ParseQuietHours("12:00-13:00")
+
+	cases := []struct {
+		q    audit.QuietHours
+		t    time.Time
+		want bool
+	}{
+		{overnight, at(23, 0), true},
+		{overnight, at(3, 0), true},
+		{overnight, at(7, 0), false},
+		{overnight, at(21, 59), false},
+		{daytime, at(12, 30), true},
+		{daytime, at(13, 0), false},
+	}
+	for _, tc := range cases {
+		if got := tc.q.Contains(tc.t); got != tc.want {
+			t.Errorf("Contains(%s) = %v, want %v", tc.t.Format("15:04"), got, tc.want)
+		}
+	}
+}
+
+func TestQuietHoursNotifier_BatchesNonCriticalDuringWindow(t *testing.T) {
+	window, _ := audit.ParseQuietHours("22:00-07:00")
+	clk := &fakeClock{t: at(23, 0)}
+	rec := &recordingNotifier{}
+	n := audit.NewQuietHoursNotifierWithClock(rec, window, clk.Now)
+	ctx := context.Background()
+
+	n.Notify(ctx, audit.Event{Kind: audit.KindAgentStarted, Target: "saito", Message: "started"})
+	n.Notify(ctx, audit.Event{Kind: audit.KindSecretsPushed, Target: "kumo", Message: "pushed", TraceID: "t_1"})
+
+	if len(rec.events) != 0 {
+		t.Fatalf("expected no events forwarded during quiet hours, got %d", len(rec.events))
+	}
+	if n.Pending() != 2 {
+		t.Fatalf("expected 2 pending events, got %d", n.Pending())
+	}
+
+	// Still inside the window: Flush must not post anything.
+	n.Flush(ctx)
+	if len(rec.events) != 0 {
+		t.Fatalf("Flush inside window forwarded %d events", len(rec.events))
+	}
+
+	// Window ends: a single digest is posted.
+	clk.t = at(7, 1)
+	n.Flush(ctx)
+	if len(rec.events) != 1 {
+		t.Fatalf("expected 1 digest, got %d events", len(rec.events))
+	}
+	digest := rec.events[0]
+	if digest.Kind != audit.KindDigest {
+		t.Errorf("digest kind = %q, want %q", digest.Kind, audit.KindDigest)
+	}
+	for _, want := range []string{"2 event(s)", "saito", "kumo", "t_1"} {
+		if !strings.Contains(digest.Message, want) {
+			t.Errorf("digest missing %q: %q", want, digest.Message)
+		}
+	}
+	if n.Pending() != 0 {
+		t.Errorf("expected pending to be cleared, got %d", n.Pending())
+	}
+}
+
+func TestQuietHoursNotifier_CriticalPostsImmediately(t *testing.T) {
+	window, _ := audit.ParseQuietHours("22:00-07:00")
+	clk := &fakeClock{t: at(2, 0)}
+	rec := &recordingNotifier{}
+	n := audit.NewQuietHoursNotifierWithClock(rec, window, clk.Now)
+
+	n.Notify(context.Background(), audit.Event{Kind: audit.KindError, Target: "saito", Message: "provisioning failed"})
+
+	if len(rec.events) != 1 || rec.events[0].Kind != audit.KindError {
+		t.Fatalf("expected critical event to be forwarded immediately, got %+v", rec.events)
+	}
+	if n.Pending() != 0 {
+		t.Errorf("critical event must not be batched, pending=%d", n.Pending())
+	}
+}
+
+func TestQuietHoursNotifier_NotifyAfterWindowFlushesDigestFirst(t *testing.T) {
+	window, _ := audit.ParseQuietHours("22:00-07:00")
+	clk := &fakeClock{t: at(23, 30)}
+	rec := &recordingNotifier{}
+	n := audit.NewQuietHoursNotifierWithClock(rec, window, clk.Now)
+	ctx := context.Background()
+
+	n.Notify(ctx, audit.Event{Kind: audit.KindAgentStopped, Target: "saito", Message: "stopped"})
+
+	clk.t = at(9, 0)
+	n.Notify(ctx, audit.Event{Kind: audit.KindAgentStarted, Target: "saito", Message: "started"})
+
+	if len(rec.events) != 2 {
+		t.Fatalf("expected digest + live event, got %d", len(rec.events))
+	}
+	if rec.events[0].Kind != audit.KindDigest || rec.events[1].Kind != audit.KindAgentStarted {
+		t.Errorf("unexpected order: %q then %q", rec.events[0].Kind, rec.events[1].Kind)
+	}
+}
@@ -195,7 +195,7 @@ func (h *Handlers) HandleApprovalDecision(ctx context.Context, text string, evt
 		h.store.WriteAudit(ctx, traceID, senderMXID, action, decision.ApprovalID, "success",
 			store.AuditPayload{"original_action": approval.Action, "target": approval.Target}, "")
 		h.notifier.Notify(ctx, audit.Event{
-			Kind: audit.KindApprovalApproved, Actor: senderMXID, Target: decision.ApprovalID,
+			Kind: audit.KindApprovalApproved, Severity: audit.SeverityInfo, Actor: senderMXID, Target: decision.ApprovalID,
 			Message: fmt.Sprintf("approved %s on %s", approval.Action, approval.Target), TraceID: traceID,
 		})
 
@@ -218,7 +218,7 @@ func (h *Handlers) HandleApprovalDecision(ctx context.Context, text string, evt
 	h.store.WriteAudit(ctx, traceID, senderMXID, action, decision.ApprovalID, "success",
 		store.AuditPayload{"original_action": approval.Action, "target": approval.Target, "reason": decision.Reason}, "")
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindApprovalDenied, Actor: senderMXID, Target: decision.ApprovalID,
+		Kind: audit.KindApprovalDenied, Severity: audit.SeverityWarn, Actor: senderMXID, Target: decision.ApprovalID,
 		Message: fmt.Sprintf("denied %s on %s (reason: %s)", approval.Action, approval.Target, decision.Reason), TraceID: traceID,
 	})
 
@@ -308,7 +308,7 @@ func (h *Handlers) requestApprovalIfNeeded(
 	h.store.WriteAudit(ctx, traceID, evt.Sender.String(), action+".approval_requested", target, "pending",
 		store.AuditPayload{"approval_id": ap.ID}, "")
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindApprovalRequested, Actor: evt.Sender.String(), Target: target,
+		Kind: audit.KindApprovalRequested, Severity: audit.SeverityCritical, Actor: evt.Sender.String(), Target: target,
 		Message: fmt.Sprintf("approval requested for %s (id: %s)", action, ap.ID), TraceID: traceID,
 	})
 
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// Broadcast rate limit: at most broadcastLimit notices per broadcastWindow,
+// counted across all operators. A maintenance window needs an announcement
+// and perhaps a follow-up, not a stream of messages to every room.
+const (
+	broadcastLimit  = 5
+	broadcastWindow = time.Hour
+	// broadcastAllAgents is the approval target recorded when no --agent
+	// filter is given.
+	broadcastAllAgents = "all-agents"
+)
+
+// HandleAgentsBroadcast asks agents to post an operator notice (typically a
+// maintenance announcement) to every room they are in. Without --agent the
+// notice goes to every agent that has a control URL. The operation requires
+// approval and is rate limited once approved.
+//
+// Usage: /ruriko agents broadcast --message "<text>" [--agent a,b]
+func (h *Handlers) HandleAgentsBroadcast(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	message := strings.TrimSpace(cmd.GetFlag("message", ""))
+	if message == "" {
+		return "", fmt.Errorf("usage: /ruriko agents broadcast --message \"<text>\" [--agent a,b]")
+	}
+
+	agents, err := h.broadcastTargets(ctx, cmd.GetFlag("agent", ""))
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.broadcast", broadcastAllAgents, "error", nil, err.Error())
+		return "", err
+	}
+	target := broadcastAllAgents
+	if cmd.HasFlag("agent") {
+		ids := make([]string, len(agents))
+		for i, a := range agents {
+			ids[i] = a.ID
+		}
+		target = strings.Join(ids, ",")
+	}
+
+	if msg, needed, err := h.requestApprovalIfNeeded(ctx, "agents.broadcast", target, cmd, evt); needed {
+		return msg, err
+	}
+
+	if !h.broadcastLimiter.Allow(broadcastLimit, "agents.broadcast") {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.broadcast", target, "error", nil, "rate limited")
+		return "", fmt.Errorf("broadcast rate limit reached (%d per %s); try again later", broadcastLimit, broadcastWindow)
+	}
+
+	var sb strings.Builder
+	var delivered, failed int
+	for _, agent := range agents {
+		var resp *acp.BroadcastResponse
+		client, err := h.resolveAgentACPClient(ctx, agent.ID)
+		if err == nil {
+			resp, err = client.Broadcast(ctx, acp.BroadcastRequest{Message: message})
+		}
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(&sb, "• ❌ **%s**: %v\n", agent.ID, err)
+		case len(resp.Failed) > 0:
+			failed++
+			fmt.Fprintf(&sb, "• ⚠️ **%s**: %d room(s), failed: %s\n", agent.ID, resp.Rooms, strings.Join(resp.Failed, ", "))
+		default:
+			delivered++
+			fmt.Fprintf(&sb, "• ✅ **%s**: %d room(s)\n", agent.ID, resp.Rooms)
+		}
+	}
+
+	result := "success"
+	if failed > 0 {
+		result = "error"
+	}
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.broadcast", target, result,
+		store.AuditPayload{"agents": len(agents), "delivered": delivered, "failed": failed}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.broadcast", "err", err)
+	}
+
+	return fmt.Sprintf("📢 Broadcast sent to %d of %d agent(s).\n\n%s\n(trace: %s)",
+		delivered, len(agents), sb.String(), traceID), nil
+}
+
+// broadcastTargets resolves the --agent filter (a comma-separated list of
+// agent IDs) to agents with a control URL. An empty filter selects every
+// such agent.
+func (h *Handlers) broadcastTargets(ctx context.Context, filter string) ([]*store.Agent, error) {
+	if filter == "" {
+		all, err := h.store.ListAgents(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list agents: %w", err)
+		}
+		var agents []*store.Agent
+		for _, a := range all {
+			if a.ControlURL.Valid && a.ControlURL.String != "" {
+				agents = append(agents, a)
+			}
+		}
+		if len(agents) == 0 {
+			return nil, fmt.Errorf("no running agents to broadcast to")
+		}
+		return agents, nil
+	}
+
+	var agents []*store.Agent
+	for _, id := range strings.Split(filter, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		agent, err := h.store.GetAgent(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("agent not found: %s", id)
+		}
+		if !agent.ControlURL.Valid || agent.ControlURL.String == "" {
+			return nil, fmt.Errorf("agent %q has no control URL; is it running?", id)
+		}
+		agents = append(agents, agent)
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("--agent must name at least one agent")
+	}
+	return agents, nil
+}
@@ -0,0 +1,164 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"maunium.net/go/mautrix/event"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/approvals"
+	"github.com/bdobrica/Ruriko/internal/ruriko/commands"
+	appstore "github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// broadcastRecorder is a fake agent ACP server that records POST /broadcast
+// bodies.
+type broadcastRecorder struct {
+	mu       sync.Mutex
+	messages []string
+	srv      *httptest.Server
+}
+
+func newBroadcastRecorder(t *testing.T) *broadcastRecorder {
+	t.Helper()
+	r := &broadcastRecorder{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/broadcast", func(w http.ResponseWriter, req *http.Request) {
+		var body acpspec.BroadcastRequest
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		r.mu.Lock()
+		r.messages = append(r.messages, body.Message)
+		r.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.BroadcastResponse{Rooms: 2})
+	})
+	r.srv = httptest.NewServer(mux)
+	t.Cleanup(r.srv.Close)
+	return r
+}
+
+func (r *broadcastRecorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.messages...)
+}
+
+func seedBroadcastAgent(t *testing.T, s *appstore.Store, id, controlURL string) {
+	t.Helper()
+	seedAgentWithGosuto(t, s, id, validGosutoWithPersonaAndInstructions)
+	if err := s.UpdateAgentHandle(context.Background(), id, "cid-"+id, controlURL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+}
+
+func TestAgentsBroadcast_RelaysToEveryAgent(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	a, b := newBroadcastRecorder(t), newBroadcastRecorder(t)
+	seedBroadcastAgent(t, s, "alpha", a.srv.URL)
+	seedBroadcastAgent(t, s, "beta", b.srv.URL)
+
+	resp, err := h.HandleAgentsBroadcast(context.Background(),
+		parseCmd(t, `/ruriko agents broadcast --message "Maintenance at 22:00 UTC"`), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsBroadcast: %v", err)
+	}
+	for name, rec := range map[string]*broadcastRecorder{"alpha": a, "beta": b} {
+		got := rec.received()
+		if len(got) != 1 || got[0] != "Maintenance at 22:00 UTC" {
+			t.Errorf("%s received %v, want the notice once", name, got)
+		}
+	}
+	if !strings.Contains(resp, "2 of 2 agent(s)") {
+		t.Errorf("expected delivery summary, got:\n%s", resp)
+	}
+}
+
+func TestAgentsBroadcast_AgentFilter(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	a, b := newBroadcastRecorder(t), newBroadcastRecorder(t)
+	seedBroadcastAgent(t, s, "alpha", a.srv.URL)
+	seedBroadcastAgent(t, s, "beta", b.srv.URL)
+
+	if _, err := h.HandleAgentsBroadcast(context.Background(),
+		parseCmd(t, `/ruriko agents broadcast --message "hi" --agent beta`), fakeEvent("@admin:example.com")); err != nil {
+		t.Fatalf("HandleAgentsBroadcast: %v", err)
+	}
+	if got := a.received(); len(got) != 0 {
+		t.Errorf("alpha should not be contacted, received %v", got)
+	}
+	if got := b.received(); len(got) != 1 {
+		t.Errorf("beta received %v, want one notice", got)
+	}
+}
+
+func TestAgentsBroadcast_RequiresMessage(t *testing.T) {
+	h, _, _ := newHandlerFixture(t)
+	if _, err := h.HandleAgentsBroadcast(context.Background(),
+		parseCmd(t, "/ruriko agents broadcast"), fakeEvent("@admin:example.com")); err == nil {
+		t.Fatal("expected usage error without --message")
+	}
+}
+
+func TestAgentsBroadcast_RateLimited(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	rec := newBroadcastRecorder(t)
+	seedBroadcastAgent(t, s, "alpha", rec.srv.URL)
+
+	cmd := parseCmd(t, `/ruriko agents broadcast --message "hi"`)
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		_, err = h.HandleAgentsBroadcast(context.Background(), cmd, fakeEvent("@admin:example.com"))
+	}
+	if err == nil || !strings.Contains(err.Error(), "rate limit") {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if n := len(rec.received()); n == 0 || n >= 10 {
+		t.Errorf("agent received %d broadcasts, want some but fewer than 10", n)
+	}
+}
+
+func TestAgentsBroadcast_RequiresApproval(t *testing.T) {
+	h, s := newTopologyFixture(t, true)
+	rec := newBroadcastRecorder(t)
+	seedBroadcastAgent(t, s, "alpha", rec.srv.URL)
+
+	h.SetDispatch(func(ctx context.Context, action string, cmd *commands.Command, evt *event.Event) (string, error) {
+		if action != "agents.broadcast" {
+			return "", nil
+		}
+		return h.HandleAgentsBroadcast(ctx, cmd, evt)
+	})
+
+	requestResp, err := h.HandleAgentsBroadcast(context.Background(),
+		parseCmd(t, `/ruriko agents broadcast --message "Maintenance at 22:00 UTC"`), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsBroadcast (request): %v", err)
+	}
+	if !strings.Contains(requestResp, "Approval required") {
+		t.Fatalf("expected approval-required response, got: %s", requestResp)
+	}
+	if got := rec.received(); len(got) != 0 {
+		t.Fatalf("broadcast sent before approval: %v", got)
+	}
+
+	pending, err := approvals.NewStore(s.DB()).List(context.Background(), string(approvals.StatusPending))
+	if err != nil {
+		t.Fatalf("approval list pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Action != "agents.broadcast" {
+		t.Fatalf("expected one pending agents.broadcast approval, got %+v", pending)
+	}
+
+	if _, err := h.HandleApprovalDecision(context.Background(), "approve "+pending[0].ID, fakeEvent("@reviewer:example.com")); err != nil {
+		t.Fatalf("HandleApprovalDecision: %v", err)
+	}
+	if got := rec.received(); len(got) != 1 || got[0] != "Maintenance at 22:00 UTC" {
+		t.Errorf("after approval agent received %v, want the notice once", got)
+	}
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// defaultCallsLimit is the number of tool calls shown when --limit is not set.
+const defaultCallsLimit = 20
+
+// HandleAgentsCalls shows an agent's tool-call audit trail: every MCP and
+// built-in call with its caller, policy decision, argument names and
+// outcome. Argument values are never recorded by the agent, so none are
+// shown here.
+//
+// Usage: /ruriko agents calls <agent> [--limit N]
+func (h *Handlers) HandleAgentsCalls(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents calls <agent> [--limit N]")
+	}
+	limit := defaultCallsLimit
+	if raw := cmd.GetFlag("limit", ""); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("--limit must be a positive integer")
+		}
+		limit = n
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.calls", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	resp, err := client.ListToolCalls(ctx, limit)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.calls", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to fetch tool calls from agent %q: %w", agentID, err)
+	}
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.calls", agentID, "success",
+		store.AuditPayload{"calls": len(resp.Calls)}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.calls", "err", err)
+	}
+
+	if len(resp.Calls) == 0 {
+		return fmt.Sprintf("**%s** has made no tool calls.\n\n(trace: %s)", agentID, traceID), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Tool calls on %s** (latest %d)\n\n", agentID, len(resp.Calls))
+	for _, c := range resp.Calls {
+		fmt.Fprintf(&sb, "- %s **%s** `%s/%s` by %s", c.At.UTC().Format("2006-01-02 15:04:05"), c.Decision, c.MCP, c.Tool, c.Caller)
+		if c.Rule != "" {
+			fmt.Fprintf(&sb, " — rule `%s`", c.Rule)
+		}
+		fmt.Fprintf(&sb, " — args [%s] — %dms", strings.Join(c.ArgKeys, ", "), c.DurationMS)
+		if c.Error != "" {
+			fmt.Fprintf(&sb, " — ❌ %s", c.Error)
+		}
+		if c.TraceID != "" {
+			fmt.Fprintf(&sb, " (trace %s)", c.TraceID)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n(trace: %s)", traceID)
+	return sb.String(), nil
+}
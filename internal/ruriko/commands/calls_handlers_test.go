@@ -0,0 +1,64 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+func TestAgentsCalls_ShowsAuditTrail(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "callbot", validGosutoWithPersonaAndInstructions)
+	var gotQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/calls", func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.ToolCallListResponse{Calls: []acpspec.ToolCallRecord{
+			{TraceID: "t-1", Caller: "llm", MCP: "docs", Tool: "delete_all", Decision: "deny", Rule: "deny-delete",
+				ArgKeys: []string{"path"}, Error: "policy denied", At: time.Now()},
+			{Caller: "workflow", MCP: "builtin", Tool: "matrix.send_message", Decision: "allow", Rule: "allow-send",
+				ArgKeys: []string{"message", "target"}, DurationMS: 12, At: time.Now()},
+		}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if err := s.UpdateAgentHandle(ctx, "callbot", "cid-callbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsCalls(ctx, parseCmd(t, "/ruriko agents calls callbot --limit 5"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsCalls: %v", err)
+	}
+	if gotQuery != "limit=5" {
+		t.Errorf("query = %q, want limit=5", gotQuery)
+	}
+	for _, want := range []string{
+		"**deny** `docs/delete_all` by llm — rule `deny-delete` — args [path]",
+		"❌ policy denied",
+		"**allow** `builtin/matrix.send_message` by workflow",
+		"args [message, target] — 12ms",
+	} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("expected %q in response, got:\n%s", want, resp)
+		}
+	}
+}
+
+func TestAgentsCalls_RejectsBadLimit(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "callbot", validGosutoWithPersonaAndInstructions)
+
+	if _, err := h.HandleAgentsCalls(context.Background(), parseCmd(t, "/ruriko agents calls callbot --limit zero"), fakeEvent("@admin:example.com")); err == nil {
+		t.Fatal("expected error for non-numeric --limit")
+	}
+}
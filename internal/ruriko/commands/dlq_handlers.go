@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// HandleAgentsDLQ lists the gateway events whose turn failed on an agent, or
+// re-dispatches one of them when --retry is given. The dead-letter queue
+// lives on the agent (opt-in via GITAI_EVENT_DLQ_ENABLE) and is reached over
+// ACP.
+//
+// Usage: /ruriko agents dlq <agent> [--retry <id>]
+func (h *Handlers) HandleAgentsDLQ(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents dlq <agent> [--retry <id>]")
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.dlq", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	if raw := cmd.GetFlag("retry", ""); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return "", fmt.Errorf("--retry must be a dead-letter id, got %q", raw)
+		}
+		if err := client.RetryDeadLetter(ctx, id); err != nil {
+			h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.dlq", agentID, "error",
+				store.AuditPayload{"retry": id}, err.Error())
+			return "", fmt.Errorf("failed to retry dead letter %d on agent %q: %w", id, agentID, err)
+		}
+		if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.dlq", agentID, "success",
+			store.AuditPayload{"retry": id}, ""); err != nil {
+			slog.Warn("audit write failed", "op", "agents.dlq", "err", err)
+		}
+		return fmt.Sprintf("🔁 Retrying dead letter **%d** on **%s**. It is removed from the queue if the turn succeeds.\n\n(trace: %s)",
+			id, agentID, traceID), nil
+	}
+
+	resp, err := client.ListDeadLetters(ctx)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.dlq", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to fetch dead-letter queue from agent %q: %w", agentID, err)
+	}
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.dlq", agentID, "success",
+		store.AuditPayload{"entries": len(resp.Entries)}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.dlq", "err", err)
+	}
+
+	if len(resp.Entries) == 0 {
+		if !resp.Enabled {
+			return fmt.Sprintf("Dead-letter queue is disabled on **%s** (set GITAI_EVENT_DLQ_ENABLE=true).\n\n(trace: %s)",
+				agentID, traceID), nil
+		}
+		return fmt.Sprintf("Dead-letter queue for **%s** is empty.\n\n(trace: %s)", agentID, traceID), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Dead-letter queue for %s** (%d entries)\n\n", agentID, len(resp.Entries))
+	for _, d := range resp.Entries {
+		fmt.Fprintf(&sb, "- **%d** `%s/%s` — retries %d/%d, failed %s\n  %s\n",
+			d.ID, d.Source, d.EventType, d.Retries, resp.MaxRetries,
+			d.FailedAt.Format("2006-01-02 15:04:05"), d.Error)
+	}
+	fmt.Fprintf(&sb, "\nRetry with `/ruriko agents dlq %s --retry <id>`.\n\n(trace: %s)", agentID, traceID)
+	return sb.String(), nil
+}
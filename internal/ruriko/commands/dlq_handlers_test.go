@@ -0,0 +1,128 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+// newDLQACPServer returns an httptest server that serves GET /dlq from the
+// supplied listing and records the ids posted to /dlq/retry.
+func newDLQACPServer(t *testing.T, list acpspec.DeadLetterListResponse) (*httptest.Server, *[]int64) {
+	t.Helper()
+	var retried []int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dlq", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("/dlq/retry", func(w http.ResponseWriter, r *http.Request) {
+		var req acpspec.DeadLetterRetryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.ID == 99 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(acpspec.ErrorResponse{Error: "dead letter retry limit reached"})
+			return
+		}
+		retried = append(retried, req.ID)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &retried
+}
+
+func TestAgentsDLQ_ListsEntries(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "dlqbot", validGosutoWithPersonaAndInstructions)
+	srv, _ := newDLQACPServer(t, acpspec.DeadLetterListResponse{
+		Enabled:    true,
+		MaxRetries: 3,
+		Entries: []acpspec.DeadLetter{{
+			ID: 7, Source: "scheduler", EventType: "cron.tick",
+			Error: "llm: upstream unavailable", Retries: 1, FailedAt: time.Now(),
+		}},
+	})
+	if err := s.UpdateAgentHandle(ctx, "dlqbot", "cid-dlqbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsDLQ(ctx, parseCmd(t, "/ruriko agents dlq dlqbot"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsDLQ: %v", err)
+	}
+	for _, want := range []string{"**7**", "scheduler/cron.tick", "retries 1/3", "upstream unavailable"} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("expected %q in response, got:\n%s", want, resp)
+		}
+	}
+}
+
+func TestAgentsDLQ_Disabled(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "offbot", validGosutoWithPersonaAndInstructions)
+	srv, _ := newDLQACPServer(t, acpspec.DeadLetterListResponse{MaxRetries: 3})
+	if err := s.UpdateAgentHandle(ctx, "offbot", "cid-offbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsDLQ(ctx, parseCmd(t, "/ruriko agents dlq offbot"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsDLQ: %v", err)
+	}
+	if !strings.Contains(resp, "disabled") {
+		t.Errorf("expected disabled notice, got:\n%s", resp)
+	}
+}
+
+func TestAgentsDLQ_Retry(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "retrybot", validGosutoWithPersonaAndInstructions)
+	srv, retried := newDLQACPServer(t, acpspec.DeadLetterListResponse{Enabled: true, MaxRetries: 3})
+	if err := s.UpdateAgentHandle(ctx, "retrybot", "cid-retrybot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsDLQ(ctx, parseCmd(t, "/ruriko agents dlq retrybot --retry 7"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsDLQ --retry: %v", err)
+	}
+	if !strings.Contains(resp, "Retrying dead letter **7**") {
+		t.Errorf("unexpected response:\n%s", resp)
+	}
+	if len(*retried) != 1 || (*retried)[0] != 7 {
+		t.Errorf("retried ids = %v, want [7]", *retried)
+	}
+
+	if _, err := h.HandleAgentsDLQ(ctx, parseCmd(t, "/ruriko agents dlq retrybot --retry 99"), fakeEvent("@admin:example.com")); err == nil {
+		t.Error("expected error when the agent refuses the retry")
+	}
+	if _, err := h.HandleAgentsDLQ(ctx, parseCmd(t, "/ruriko agents dlq retrybot --retry abc"), fakeEvent("@admin:example.com")); err == nil {
+		t.Error("expected error for a non-numeric id")
+	}
+}
+
+func TestAgentsDLQ_NoControlURL(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "idlebot", validGosutoWithPersonaAndInstructions)
+
+	if _, err := h.HandleAgentsDLQ(context.Background(), parseCmd(t, "/ruriko agents dlq idlebot"), fakeEvent("@admin:example.com")); err == nil {
+		t.Fatal("expected error for agent without control URL")
+	}
+}
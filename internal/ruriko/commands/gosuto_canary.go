@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// HandleGosutoCanary pushes a stored Gosuto version to a running agent as a
+// canary: the agent uses it only for turns in the given room and keeps its
+// live config everywhere else. The canary lasts until it is promoted,
+// aborted, replaced by another canary, or the agent restarts.
+//
+// Usage: /ruriko gosuto canary <agent> --room <room-id> [--version <n>]
+func (h *Handlers) HandleGosutoCanary(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	const usage = "usage: /ruriko gosuto canary <agent> --room <room-id> [--version <n>]"
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf(usage)
+	}
+	room := cmd.GetFlag("room", "")
+	if room == "" {
+		return "", fmt.Errorf(usage)
+	}
+	if !strings.HasPrefix(room, "!") {
+		return "", fmt.Errorf("--room must be a Matrix room ID (starting with '!'), got %q", room)
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.canary", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	var gv *store.GosutoVersion
+	if vStr := cmd.GetFlag("version", ""); vStr != "" {
+		var vNum int
+		if _, scanErr := fmt.Sscanf(vStr, "%d", &vNum); scanErr != nil {
+			return "", fmt.Errorf("--version must be an integer, got %q", vStr)
+		}
+		gv, err = h.store.GetGosutoVersion(ctx, agentID, vNum)
+	} else {
+		gv, err = h.store.GetLatestGosutoVersion(ctx, agentID)
+	}
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.canary", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("no gosuto config found for agent %q: %w", agentID, err)
+	}
+
+	if err := client.ApplyCanary(ctx, acp.ConfigCanaryRequest{YAML: gv.YAMLBlob, Hash: gv.Hash, Room: room}); err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.canary", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to push canary Gosuto config: %w", err)
+	}
+
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.canary", agentID, "success",
+		store.AuditPayload{"version": gv.Version, "hash": gv.Hash[:16], "room": room}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "gosuto.canary", "err", err)
+	}
+
+	return fmt.Sprintf(
+		"🐤 Gosuto v%d running as canary on **%s** in room %s\n\nOther rooms keep the live config. Use `/ruriko gosuto promote %s` to apply it everywhere or `/ruriko gosuto abort %s` to drop it.\n\n(trace: %s)",
+		gv.Version, agentID, room, agentID, agentID, traceID,
+	), nil
+}
+
+// HandleGosutoPromote applies an agent's active canary config to every room:
+// the version is looked up by the hash the agent reports and pushed as a
+// normal config, which also ends the canary.
+//
+// Usage: /ruriko gosuto promote <agent>
+func (h *Handlers) HandleGosutoPromote(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko gosuto promote <agent>")
+	}
+
+	agent, client, err := h.resolveAgentACP(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.promote", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.promote", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to read status of agent %q: %w", agentID, err)
+	}
+	if status.Canary == nil {
+		return "", fmt.Errorf("agent %q has no active canary", agentID)
+	}
+
+	matches, err := h.store.FindGosutoVersionsByHash(ctx, agentID, status.Canary.Hash)
+	if err != nil {
+		return "", fmt.Errorf("look up canary version: %w", err)
+	}
+	if len(matches) == 0 {
+		msg := fmt.Sprintf("canary hash %s does not match any stored Gosuto version", status.Canary.Hash)
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.promote", agentID, "error", nil, msg)
+		return "", fmt.Errorf("%s for agent %q", msg, agentID)
+	}
+	gv := matches[0]
+
+	if err := pushGosuto(ctx, agent.ControlURL.String, agent.ACPToken.String, gv); err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.promote", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to push Gosuto config: %w", err)
+	}
+	if err := h.store.SetAgentDesiredGosutoHash(ctx, agentID, gv.Hash); err != nil {
+		slog.Warn("failed to record desired gosuto hash", "agent", agentID, "err", err)
+	}
+
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.promote", agentID, "success",
+		store.AuditPayload{"version": gv.Version, "hash": gv.Hash[:16], "room": status.Canary.Room}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "gosuto.promote", "err", err)
+	}
+
+	return fmt.Sprintf(
+		"📤 Canary Gosuto v%d promoted on **%s**; it now applies to every room\n\n(trace: %s)",
+		gv.Version, agentID, traceID,
+	), nil
+}
+
+// HandleGosutoAbort drops an agent's active canary config; the canary room
+// goes back to the live config.
+//
+// Usage: /ruriko gosuto abort <agent>
+func (h *Handlers) HandleGosutoAbort(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko gosuto abort <agent>")
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.abort", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	if err := client.AbortCanary(ctx); err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.abort", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to abort canary: %w", err)
+	}
+
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.abort", agentID, "success",
+		store.AuditPayload{}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "gosuto.abort", "err", err)
+	}
+
+	return fmt.Sprintf("↩️ Canary dropped on **%s**; every room uses the live config again\n\n(trace: %s)", agentID, traceID), nil
+}
@@ -0,0 +1,139 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+// fakeCanaryAgent is an ACP server that tracks the canary and the live
+// config the way a Gitai agent does.
+type fakeCanaryAgent struct {
+	mu      sync.Mutex
+	canary  *acpspec.ConfigCanaryRequest
+	applied *acpspec.ConfigApplyRequest
+}
+
+func (f *fakeCanaryAgent) serve(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/config/canary", func(w http.ResponseWriter, r *http.Request) {
+		var req acpspec.ConfigCanaryRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.canary = &req
+		f.mu.Unlock()
+	})
+	mux.HandleFunc("/config/canary/abort", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.canary == nil {
+			http.Error(w, `{"error":"no canary config is active"}`, http.StatusNotFound)
+			return
+		}
+		f.canary = nil
+	})
+	mux.HandleFunc("/config/apply", func(w http.ResponseWriter, r *http.Request) {
+		var req acpspec.ConfigApplyRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.applied, f.canary = &req, nil
+		f.mu.Unlock()
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		resp := acpspec.StatusResponse{AgentID: "canarybot"}
+		if f.canary != nil {
+			resp.Canary = &acpspec.CanaryStatus{Room: f.canary.Room, Hash: f.canary.Hash}
+		}
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGosutoCanary_PushPromoteAbort(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	v1 := seedAgentWithGosuto(t, s, "canarybot", coverageGosutoYAML)
+	addGosutoVersion(t, s, "canarybot", hashA, "# version 2\n")
+	v2, err := s.GetGosutoVersion(ctx, "canarybot", 2)
+	if err != nil {
+		t.Fatalf("GetGosutoVersion: %v", err)
+	}
+	agent := &fakeCanaryAgent{}
+	srv := agent.serve(t)
+	if err := s.UpdateAgentHandle(ctx, "canarybot", "cid-canarybot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	// Canary the older version to check --version is honoured.
+	resp, err := h.HandleGosutoCanary(ctx, parseCmd(t, "/ruriko gosuto canary canarybot --room !ops:example.com --version 1"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoCanary: %v", err)
+	}
+	if !strings.Contains(resp, "v1") || !strings.Contains(resp, "!ops:example.com") {
+		t.Errorf("canary response = %q", resp)
+	}
+	if agent.canary == nil || agent.canary.Hash != v1.Hash || agent.canary.Room != "!ops:example.com" {
+		t.Fatalf("agent canary = %+v, want v1 in !ops:example.com", agent.canary)
+	}
+
+	if _, err := h.HandleGosutoAbort(ctx, parseCmd(t, "/ruriko gosuto abort canarybot"), fakeEvent("@admin:example.com")); err != nil {
+		t.Fatalf("HandleGosutoAbort: %v", err)
+	}
+	if agent.canary != nil {
+		t.Fatal("abort should drop the agent's canary")
+	}
+	if _, err := h.HandleGosutoAbort(ctx, parseCmd(t, "/ruriko gosuto abort canarybot"), fakeEvent("@admin:example.com")); err == nil {
+		t.Error("expected an error aborting with no active canary")
+	}
+	if _, err := h.HandleGosutoPromote(ctx, parseCmd(t, "/ruriko gosuto promote canarybot"), fakeEvent("@admin:example.com")); err == nil {
+		t.Error("expected an error promoting with no active canary")
+	}
+
+	// Canary the latest version, then promote it.
+	if _, err := h.HandleGosutoCanary(ctx, parseCmd(t, "/ruriko gosuto canary canarybot --room !ops:example.com"), fakeEvent("@admin:example.com")); err != nil {
+		t.Fatalf("HandleGosutoCanary: %v", err)
+	}
+	if agent.canary == nil || agent.canary.Hash != v2.Hash {
+		t.Fatalf("agent canary = %+v, want latest version v2", agent.canary)
+	}
+	if _, err := h.HandleGosutoPromote(ctx, parseCmd(t, "/ruriko gosuto promote canarybot"), fakeEvent("@admin:example.com")); err != nil {
+		t.Fatalf("HandleGosutoPromote: %v", err)
+	}
+	if agent.applied == nil || agent.applied.Hash != v2.Hash || agent.applied.YAML != v2.YAMLBlob {
+		t.Errorf("promote applied %+v, want v2", agent.applied)
+	}
+	got, err := s.GetAgent(ctx, "canarybot")
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if got.DesiredGosutoHash.String != v2.Hash {
+		t.Errorf("desired hash = %q, want %q", got.DesiredGosutoHash.String, v2.Hash)
+	}
+}
+
+func TestGosutoCanary_RequiresRoomID(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "canarybot", coverageGosutoYAML)
+
+	for _, line := range []string{
+		"/ruriko gosuto canary canarybot",
+		"/ruriko gosuto canary canarybot --room #ops:example.com",
+	} {
+		if _, err := h.HandleGosutoCanary(context.Background(), parseCmd(t, line), fakeEvent("@admin:example.com")); err == nil {
+			t.Errorf("%s: expected an error", line)
+		}
+	}
+}
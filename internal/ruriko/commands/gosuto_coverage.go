@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/policy"
+	"github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// Coverage outcomes reported by HandleGosutoCoverage. "unmatched" is a
+// default deny: no capability rule names the tool.
+const (
+	coverageAllow     = "allow"
+	coverageApproval  = "approval"
+	coverageDeny      = "deny"
+	coverageUnmatched = "unmatched"
+)
+
+// coverageRow is the policy outcome for one tool the agent exposes.
+type coverageRow struct {
+	tool     acp.ToolInfo
+	outcome  string
+	rule     string
+	wildcard bool // matched rule uses "*" for the MCP or the tool
+}
+
+// policyCoverage evaluates every tool against cfg's capability rules with the
+// agent's own policy engine. Arguments are not known here, so constraints are
+// not applied; the outcome is what the rule itself grants.
+func policyCoverage(cfg *gosuto.Config, tools []acp.ToolInfo) []coverageRow {
+	rules := make(map[string]gosuto.Capability, len(cfg.Capabilities))
+	for _, c := range cfg.Capabilities {
+		rules[c.Name] = c
+	}
+	eng := policy.ForConfig(cfg)
+	rows := make([]coverageRow, 0, len(tools))
+	for _, t := range tools {
+		res := eng.Evaluate(t.MCP, t.Tool, nil)
+		row := coverageRow{tool: t, rule: res.MatchedRule}
+		switch {
+		case res.MatchedRule == policy.DefaultRule:
+			row.outcome = coverageUnmatched
+		case res.Decision == policy.DecisionAllow:
+			row.outcome = coverageAllow
+		case res.Decision == policy.DecisionRequireApproval:
+			row.outcome = coverageApproval
+		default:
+			row.outcome = coverageDeny
+		}
+		if c, ok := rules[res.MatchedRule]; ok {
+			row.wildcard = c.MCP == "*" || c.Tool == "*"
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// HandleGosutoCoverage lists every tool the agent currently exposes and the
+// decision its latest stored Gosuto would give each one, highlighting tools
+// that fall through to the default deny and tools granted only by a wildcard
+// rule. Tools come from the agent's GET /tools.
+//
+// Usage: /ruriko gosuto coverage <agent>
+func (h *Handlers) HandleGosutoCoverage(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko gosuto coverage <agent>")
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.coverage", agentID, "error", nil, err.Error())
+		return "", err
+	}
+	gv, err := h.store.GetLatestGosutoVersion(ctx, agentID)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.coverage", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("no gosuto config found for agent %q: %w", agentID, err)
+	}
+	var cfg gosuto.Config
+	if err := yaml.Unmarshal([]byte(gv.YAMLBlob), &cfg); err != nil {
+		return "", fmt.Errorf("parse gosuto v%d for agent %q: %w", gv.Version, agentID, err)
+	}
+	resp, err := client.ListTools(ctx, false)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.coverage", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to fetch tools from agent %q: %w", agentID, err)
+	}
+
+	rows := policyCoverage(&cfg, resp.Tools)
+	counts := map[string]int{}
+	wildcards := 0
+	for _, r := range rows {
+		counts[r.outcome]++
+		if r.wildcard && r.outcome != coverageDeny {
+			wildcards++
+		}
+	}
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.coverage", agentID, "success",
+		store.AuditPayload{"version": gv.Version, "tools": len(rows), "unmatched": counts[coverageUnmatched]}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "gosuto.coverage", "err", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Policy coverage for %s** (Gosuto v%d, %d tools)\n", agentID, gv.Version, len(rows))
+	fmt.Fprintf(&sb, "%d allow, %d approval, %d deny, %d unmatched\n\n",
+		counts[coverageAllow], counts[coverageApproval], counts[coverageDeny], counts[coverageUnmatched])
+	for _, r := range rows {
+		ref := r.tool.MCP + "/" + r.tool.Tool
+		if r.outcome == coverageUnmatched {
+			fmt.Fprintf(&sb, "- **unmatched** `%s` — no rule; falls through to default deny\n", ref)
+			continue
+		}
+		fmt.Fprintf(&sb, "- **%s** `%s` — rule `%s`", r.outcome, ref, r.rule)
+		if r.wildcard && r.outcome != coverageDeny {
+			sb.WriteString(" ⚠️ wildcard")
+		}
+		sb.WriteString("\n")
+	}
+	if n := counts[coverageUnmatched]; n > 0 {
+		fmt.Fprintf(&sb, "\n%d tool(s) have no explicit rule. Add an allow or deny rule so the intent is recorded.\n", n)
+	}
+	if wildcards > 0 {
+		fmt.Fprintf(&sb, "\n%d tool(s) are granted by a wildcard rule. Check that each should be reachable.\n", wildcards)
+	}
+	fmt.Fprintf(&sb, "\n(trace: %s)", traceID)
+	return sb.String(), nil
+}
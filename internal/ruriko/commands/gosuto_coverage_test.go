@@ -0,0 +1,97 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+const coverageGosutoYAML = `apiVersion: gosuto/v1
+metadata:
+  name: covbot
+trust:
+  allowedRooms:
+    - "!admin:example.com"
+  allowedSenders:
+    - "*"
+capabilities:
+  - name: allow-search
+    mcp: docs
+    tool: search
+    allow: true
+  - name: deny-delete
+    mcp: docs
+    tool: delete_all
+    allow: false
+  - name: approve-write
+    mcp: docs
+    tool: write
+    allow: true
+    requireApproval: true
+  - name: allow-web
+    mcp: web
+    tool: "*"
+    allow: true
+persona:
+  llmProvider: openai
+  model: gpt-4o
+`
+
+func TestGosutoCoverage_ReportsDecisionPerTool(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "covbot", coverageGosutoYAML)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tools", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.ToolListResponse{Tools: []acpspec.ToolInfo{
+			{Name: "docs__search", MCP: "docs", Tool: "search"},
+			{Name: "docs__delete_all", MCP: "docs", Tool: "delete_all"},
+			{Name: "docs__write", MCP: "docs", Tool: "write"},
+			{Name: "docs__fetch", MCP: "docs", Tool: "fetch"},
+			{Name: "web__get", MCP: "web", Tool: "get"},
+			{Name: "schedule.list", MCP: "builtin", Tool: "schedule.list"},
+		}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if err := s.UpdateAgentHandle(ctx, "covbot", "cid-covbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleGosutoCoverage(ctx, parseCmd(t, "/ruriko gosuto coverage covbot"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoCoverage: %v", err)
+	}
+	for _, want := range []string{
+		"6 tools",
+		"2 allow, 1 approval, 1 deny, 2 unmatched",
+		"**allow** `docs/search` — rule `allow-search`\n",
+		"**deny** `docs/delete_all` — rule `deny-delete`",
+		"**approval** `docs/write` — rule `approve-write`",
+		"**unmatched** `docs/fetch`",
+		"**unmatched** `builtin/schedule.list`",
+		"**allow** `web/get` — rule `allow-web` ⚠️ wildcard",
+		"2 tool(s) have no explicit rule",
+		"1 tool(s) are granted by a wildcard rule",
+	} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("expected %q in response, got:\n%s", want, resp)
+		}
+	}
+}
+
+func TestGosutoCoverage_NoControlURL(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "idlecov", coverageGosutoYAML)
+
+	if _, err := h.HandleGosutoCoverage(context.Background(), parseCmd(t, "/ruriko gosuto coverage idlecov"), fakeEvent("@admin:example.com")); err == nil {
+		t.Fatal("expected error for agent without control URL")
+	}
+}
@@ -0,0 +1,88 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+// newConfigACPServer returns an httptest server that answers GET /config with
+// the supplied YAML and hash, mimicking a running Gitai agent.
+func newConfigACPServer(t *testing.T, liveYAML, liveHash string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.ConfigResponse{YAML: liveYAML, Hash: liveHash})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAgentsDiffLive_InSync(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	gv := seedAgentWithGosuto(t, s, "livebot", validGosutoWithPersonaAndInstructions)
+	srv := newConfigACPServer(t, gv.YAMLBlob, gv.Hash)
+	if err := s.UpdateAgentHandle(ctx, "livebot", "cid-livebot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsDiffLive(ctx, parseCmd(t, "/ruriko agents diff-live livebot"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsDiffLive: %v", err)
+	}
+	if !strings.Contains(resp, "in sync") {
+		t.Errorf("expected in-sync report, got:\n%s", resp)
+	}
+	if strings.Contains(resp, "```diff") {
+		t.Errorf("in-sync report should not include a diff, got:\n%s", resp)
+	}
+}
+
+func TestAgentsDiffLive_Drifted(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	gv := seedAgentWithGosuto(t, s, "driftbot", validGosutoWithPersonaAndInstructions)
+	liveYAML := strings.Replace(gv.YAMLBlob, "gpt-4o", "gpt-4o-mini", 1)
+	srv := newConfigACPServer(t, liveYAML, hashString(liveYAML))
+	if err := s.UpdateAgentHandle(ctx, "driftbot", "cid-driftbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsDiffLive(ctx, parseCmd(t, "/ruriko agents diff-live driftbot"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsDiffLive: %v", err)
+	}
+	if !strings.Contains(resp, "drift detected") {
+		t.Errorf("expected drift report, got:\n%s", resp)
+	}
+	if !strings.Contains(resp, "**persona**") {
+		t.Errorf("expected persona section to be reported as changed, got:\n%s", resp)
+	}
+	if strings.Contains(resp, "**instructions**") {
+		t.Errorf("instructions unexpectedly reported as changed, got:\n%s", resp)
+	}
+	if !strings.Contains(resp, "+     model: gpt-4o-mini") {
+		t.Errorf("expected line diff to include live model, got:\n%s", resp)
+	}
+}
+
+func TestAgentsDiffLive_NoControlURL(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+
+	seedAgentWithGosuto(t, s, "offbot", validGosutoWithPersonaAndInstructions)
+
+	_, err := h.HandleAgentsDiffLive(context.Background(), parseCmd(t, "/ruriko agents diff-live offbot"), fakeEvent("@admin:example.com"))
+	if err == nil || !strings.Contains(err.Error(), "control URL") {
+		t.Fatalf("expected control URL error, got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -297,7 +298,154 @@ func (h *Handlers) HandleGosutoDiff(ctx context.Context, cmd *Command, evt *even
 	), nil
 }
 
-// HandleGosutoSet parses, validates, and stores a new Gosuto version.
+// defaultGosutoHistoryLimit and maxGosutoHistoryLimit bound the number of
+// versions shown by gosuto history.
+const (
+	defaultGosutoHistoryLimit = 10
+	maxGosutoHistoryLimit     = GosutoVersionsRetainN
+)
+
+// HandleGosutoHistory shows a compact changelog of an agent's stored Gosuto
+// versions, newest first: for each version, who stored it, when, and which
+// sections changed against the version before it.
+//
+// Usage: /ruriko gosuto history <agent> [--limit N]
+func (h *Handlers) HandleGosutoHistory(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko gosuto history <agent> [--limit N]")
+	}
+	limit := defaultGosutoHistoryLimit
+	if raw := cmd.GetFlag("limit", ""); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("--limit must be a positive integer")
+		}
+		limit = min(n, maxGosutoHistoryLimit)
+	}
+
+	if _, err := h.store.GetAgent(ctx, agentID); err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.history", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+
+	versions, err := h.store.ListGosutoVersions(ctx, agentID)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.history", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.history", agentID, "success",
+		store.AuditPayload{"count": len(versions), "limit": limit}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "gosuto.history", "err", err)
+	}
+
+	if len(versions) == 0 {
+		return fmt.Sprintf("No Gosuto versions found for agent **%s**.\n\n(trace: %s)", agentID, traceID), nil
+	}
+
+	shown := versions
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Gosuto history for %s** (%d of %d versions)\n\n", agentID, len(shown), len(versions))
+	// versions is newest first, so each entry's predecessor is the next one.
+	for i, v := range shown {
+		fmt.Fprintf(&sb, "- **v%d** %s by %s — ", v.Version, v.CreatedAt.Format("2006-01-02 15:04"), v.CreatedByMXID)
+		switch {
+		case i+1 >= len(versions) && v.Version == 1:
+			sb.WriteString("initial version")
+		case i+1 >= len(versions):
+			fmt.Fprintf(&sb, "oldest retained version (v%d and earlier were pruned)", v.Version-1)
+		case versions[i+1].Hash == v.Hash:
+			fmt.Fprintf(&sb, "no changes since v%d", versions[i+1].Version)
+		default:
+			sb.WriteString(gosutoDiffSections(versions[i+1].YAMLBlob, v.YAMLBlob))
+		}
+		sb.WriteString("\n")
+	}
+	if len(versions) > len(shown) {
+		fmt.Fprintf(&sb, "\n_%d more not shown; use --limit to see older versions._\n", len(versions)-len(shown))
+	}
+	fmt.Fprintf(&sb, "\n(trace: %s)", traceID)
+	return sb.String(), nil
+}
+
+// HandleAgentsDiffLive compares the Gosuto config currently applied on a
+// running agent (fetched via ACP GET /config) with the latest version stored
+// in Ruriko and reports any drift at the section level.
+//
+// Usage: /ruriko agents diff-live <agent>
+func (h *Handlers) HandleAgentsDiffLive(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents diff-live <agent>")
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.diff-live", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	stored, err := h.store.GetLatestGosutoVersion(ctx, agentID)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.diff-live", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("no gosuto config found for agent %q: %w", agentID, err)
+	}
+
+	live, err := client.Config(ctx)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.diff-live", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to fetch live config from agent %q: %w", agentID, err)
+	}
+
+	inSync := live.Hash == stored.Hash
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.diff-live", agentID, "success",
+		store.AuditPayload{"version": stored.Version, "in_sync": inSync}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.diff-live", "err", err)
+	}
+
+	if inSync {
+		return fmt.Sprintf("**Live diff %s**: ✅ in sync with stored v%d (hash `%s…`)\n\n(trace: %s)",
+			agentID, stored.Version, stored.Hash[:16], traceID), nil
+	}
+
+	// Identify an older stored version matching the live hash, if any, so the
+	// operator can tell "stale push" apart from "unknown config".
+	liveLabel := "unknown version"
+	if versions, err := h.store.ListGosutoVersions(ctx, agentID); err == nil {
+		for _, v := range versions {
+			if v.Hash == live.Hash {
+				liveLabel = fmt.Sprintf("stored v%d", v.Version)
+				break
+			}
+		}
+	}
+	if live.Hash == "" {
+		liveLabel = "no hash reported"
+	}
+
+	diff := diffLines(stored.YAMLBlob, live.YAML)
+	sectionNote := gosutoDiffSections(stored.YAMLBlob, live.YAML)
+
+	return fmt.Sprintf(
+		"**Live diff %s**: ⚠️ drift detected — stored v%d → live (%s)\n\n%s\n```diff\n%s\n```\n\n(trace: %s)",
+		agentID, stored.Version, liveLabel, sectionNote, diff, traceID,
+	), nil
+}
+
+// HandleGosutoSet parses, validates, and stores a new Gosuto version. The
+// config is stored in canonical form (see gosuto.Canonicalize), so comments
+// and formatting are not kept and cosmetic edits are reported as unchanged.
 //
 // Usage: /ruriko gosuto set <agent> --content <base64-encoded-yaml>
 func (h *Handlers) HandleGosutoSet(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
@@ -332,8 +480,13 @@ func (h *Handlers) HandleGosutoSet(ctx context.Context, cmd *Command, evt *event
 		}
 	}
 
-	// Validate before storing.
-	if _, err := gosuto.Parse(rawYAML); err != nil {
+	// Validate before storing. In strict mode warnings are errors too.
+	parse := gosuto.Parse
+	if h.gosutoStrict {
+		parse = gosuto.ParseStrict
+	}
+	parsed, err := parse(rawYAML)
+	if err != nil {
 		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.set", agentID, "error", nil, err.Error())
 		return "", fmt.Errorf("invalid Gosuto config: %w", err)
 	}
@@ -343,8 +496,14 @@ func (h *Handlers) HandleGosutoSet(ctx context.Context, cmd *Command, evt *event
 		return msg, err
 	}
 
-	// Compute SHA-256 hash.
-	sum := sha256.Sum256(rawYAML)
+	// Store and hash the canonical form so that cosmetic differences (key
+	// order, quoting, comments) do not create new versions.
+	canonical, err := gosuto.Canonicalize(rawYAML)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.set", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("invalid Gosuto config: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
 	hash := fmt.Sprintf("%x", sum)
 
 	// Check for duplicate (same hash as latest).
@@ -367,7 +526,7 @@ func (h *Handlers) HandleGosutoSet(ctx context.Context, cmd *Command, evt *event
 		AgentID:       agentID,
 		Version:       nextVer,
 		Hash:          hash,
-		YAMLBlob:      string(rawYAML),
+		YAMLBlob:      string(canonical),
 		CreatedByMXID: evt.Sender.String(),
 	}
 
@@ -387,34 +546,72 @@ func (h *Handlers) HandleGosutoSet(ctx context.Context, cmd *Command, evt *event
 	}
 
 	return fmt.Sprintf(
-		"✅ Gosuto config for **%s** stored as **v%d** (hash: `%s…`)\n\nRun `/ruriko gosuto push %s` to apply it to the running agent.\n\n(trace: %s)",
-		agentID, nextVer, hash[:16], agentID, traceID,
+		"✅ Gosuto config for **%s** stored as **v%d** (hash: `%s…`)%s\n\nRun `/ruriko gosuto push %s` to apply it to the running agent.\n\n(trace: %s)",
+		agentID, nextVer, hash[:16], formatGosutoWarnings(gosuto.Warnings(parsed)), agentID, traceID,
 	), nil
 }
 
+// decodeGosutoSection decodes a single Gosuto section payload. In strict mode
+// unknown keys are rejected so a typo cannot silently drop part of the
+// section; otherwise they are ignored.
+func (h *Handlers) decodeGosutoSection(data []byte, out interface{}) error {
+	if h.gosutoStrict {
+		return gosuto.DecodeStrict(data, out)
+	}
+	return yaml.Unmarshal(data, out)
+}
+
+// formatGosutoWarnings renders Gosuto advisory warnings as a block to append
+// to a reply, or "" when there are none.
+func formatGosutoWarnings(ws []gosuto.Warning) string {
+	if len(ws) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n⚠️ Warnings:")
+	for _, w := range ws {
+		fmt.Fprintf(&b, "\n• `%s`: %s", w.Field, w.Message)
+	}
+	return b.String()
+}
+
+// minGosutoHashPrefix is the shortest hash prefix gosuto rollback --hash
+// accepts.
+const minGosutoHashPrefix = 6
+
 // HandleGosutoRollback reverts an agent to a previous Gosuto version by
-// re-saving it as a new version entry (preserving the audit trail).
+// re-saving it as a new version entry (preserving the audit trail). The
+// target is named by version number (--to) or by content hash (--hash),
+// which may be shortened to a unique prefix.
 //
-// Usage: /ruriko gosuto rollback <agent> --to <version>
+// Usage: /ruriko gosuto rollback <agent> --to <version> | --hash <hash>
 func (h *Handlers) HandleGosutoRollback(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
 	traceID := trace.GenerateID()
 	ctx = trace.WithTraceID(ctx, traceID)
 
 	agentID, ok := cmd.GetArg(0)
 	if !ok {
-		return "", fmt.Errorf("usage: /ruriko gosuto rollback <agent> --to <version>")
+		return "", fmt.Errorf("usage: /ruriko gosuto rollback <agent> --to <version> | --hash <hash>")
 	}
 
 	toStr := cmd.GetFlag("to", "")
-	if toStr == "" {
-		return "", fmt.Errorf("--to <version> is required")
+	hashStr := strings.ToLower(cmd.GetFlag("hash", ""))
+	if toStr == "" && hashStr == "" {
+		return "", fmt.Errorf("--to <version> or --hash <hash> is required")
+	}
+	if toStr != "" && hashStr != "" {
+		return "", fmt.Errorf("--to and --hash are mutually exclusive")
 	}
 
 	// Validate inputs before requesting approval so that only valid
 	// operations enter the approval queue.
 	var targetVer int
-	if _, err := fmt.Sscanf(toStr, "%d", &targetVer); err != nil {
-		return "", fmt.Errorf("--to must be an integer, got %q", toStr)
+	if toStr != "" {
+		if _, err := fmt.Sscanf(toStr, "%d", &targetVer); err != nil {
+			return "", fmt.Errorf("--to must be an integer, got %q", toStr)
+		}
+	} else if err := validateHashPrefix(hashStr); err != nil {
+		return "", err
 	}
 
 	if _, err := h.store.GetAgent(ctx, agentID); err != nil {
@@ -422,16 +619,31 @@ func (h *Handlers) HandleGosutoRollback(ctx context.Context, cmd *Command, evt *
 		return "", fmt.Errorf("agent not found: %s", agentID)
 	}
 
+	// Resolve the hash now so unknown or ambiguous hashes are reported
+	// before anyone is asked to approve the rollback.
+	var target *store.GosutoVersion
+	if hashStr != "" {
+		gv, err := h.resolveGosutoHash(ctx, agentID, hashStr)
+		if err != nil {
+			h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.rollback", agentID, "error", nil, err.Error())
+			return "", err
+		}
+		target, targetVer = gv, gv.Version
+	}
+
 	// Require approval for Gosuto rollback (after validation passes).
 	if msg, needed, err := h.requestApprovalIfNeeded(ctx, "gosuto.rollback", agentID, cmd, evt); needed {
 		return msg, err
 	}
 
 	// Load the target version.
-	target, err := h.store.GetGosutoVersion(ctx, agentID, targetVer)
-	if err != nil {
-		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.rollback", agentID, "error", nil, err.Error())
-		return "", fmt.Errorf("version %d not found: %w", targetVer, err)
+	if target == nil {
+		gv, err := h.store.GetGosutoVersion(ctx, agentID, targetVer)
+		if err != nil {
+			h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.rollback", agentID, "error", nil, err.Error())
+			return "", fmt.Errorf("version %d not found: %w", targetVer, err)
+		}
+		target = gv
 	}
 
 	// No-op detection: if the rollback target's content is identical to the
@@ -480,16 +692,57 @@ func (h *Handlers) HandleGosutoRollback(ctx context.Context, cmd *Command, evt *
 	), nil
 }
 
+// validateHashPrefix checks that prefix is a plausible SHA-256 hex prefix.
+func validateHashPrefix(prefix string) error {
+	if len(prefix) < minGosutoHashPrefix || len(prefix) > sha256.Size*2 {
+		return fmt.Errorf("--hash must be %d to %d hex characters, got %d", minGosutoHashPrefix, sha256.Size*2, len(prefix))
+	}
+	for _, c := range prefix {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return fmt.Errorf("--hash must be hexadecimal, got %q", prefix)
+		}
+	}
+	return nil
+}
+
+// resolveGosutoHash finds the stored version whose hash starts with prefix.
+// Versions sharing one hash hold identical content, so the newest of them is
+// returned; a prefix matching more than one distinct hash is an error.
+func (h *Handlers) resolveGosutoHash(ctx context.Context, agentID, prefix string) (*store.GosutoVersion, error) {
+	versions, err := h.store.FindGosutoVersionsByHash(ctx, agentID, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("look up hash %s: %w", prefix, err)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no stored Gosuto version of %s has a hash starting with %s", agentID, prefix)
+	}
+	var matches []string
+	seen := map[string]bool{}
+	for _, v := range versions {
+		if !seen[v.Hash] {
+			seen[v.Hash] = true
+			matches = append(matches, fmt.Sprintf("v%d (%s…)", v.Version, v.Hash[:16]))
+		}
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("hash prefix %s is ambiguous; it matches %s — use more characters",
+			prefix, strings.Join(matches, ", "))
+	}
+	return versions[0], nil
+}
+
 // HandleGosutoPush pushes the current Gosuto config to a running agent via ACP.
+// The push is refused while a secret the config requires is not stored and
+// bound to the agent, since the agent could not run it; --force overrides.
 //
-// Usage: /ruriko gosuto push <agent>
+// Usage: /ruriko gosuto push <agent> [--force]
 func (h *Handlers) HandleGosutoPush(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
 	traceID := trace.GenerateID()
 	ctx = trace.WithTraceID(ctx, traceID)
 
 	agentID, ok := cmd.GetArg(0)
 	if !ok {
-		return "", fmt.Errorf("usage: /ruriko gosuto push <agent>")
+		return "", fmt.Errorf("usage: /ruriko gosuto push <agent> [--force]")
 	}
 
 	agent, err := h.store.GetAgent(ctx, agentID)
@@ -508,6 +761,20 @@ func (h *Handlers) HandleGosutoPush(ctx context.Context, cmd *Command, evt *even
 		return "", fmt.Errorf("no Gosuto config stored for agent %q", agentID)
 	}
 
+	if !cmd.HasFlag("force") {
+		missing, err := h.missingRequiredSecrets(ctx, agentID, gv)
+		if err != nil {
+			return "", err
+		}
+		if len(missing) > 0 {
+			msg := fmt.Sprintf("required secret(s) not stored and bound for agent %q: %s",
+				agentID, strings.Join(missing, ", "))
+			h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.push", agentID, "error",
+				store.AuditPayload{"version": gv.Version, "missing_secrets": missing}, msg)
+			return "", fmt.Errorf("%s; run `/ruriko agents secrets-check %s` for details, or push with --force", msg, agentID)
+		}
+	}
+
 	if err := pushGosuto(ctx, agent.ControlURL.String, agent.ACPToken.String, gv); err != nil {
 		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.push", agentID, "error", nil, err.Error())
 		return "", fmt.Errorf("failed to push Gosuto config: %w", err)
@@ -557,7 +824,7 @@ func (h *Handlers) HandleSecretsPush(ctx context.Context, cmd *Command, evt *eve
 		slog.Warn("audit write failed", "op", "secrets.push", "err", err)
 	}
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindSecretsPushed, Actor: evt.Sender.String(), Target: agentID,
+		Kind: audit.KindSecretsPushed, Severity: audit.SeverityInfo, Actor: evt.Sender.String(), Target: agentID,
 		Message: fmt.Sprintf("pushed %d secret(s)", n), TraceID: traceID,
 	})
 
@@ -614,7 +881,7 @@ func (h *Handlers) HandleGosutoSetInstructions(ctx context.Context, cmd *Command
 	}
 
 	var newInstructions gosuto.Instructions
-	if err := yaml.Unmarshal(rawSection, &newInstructions); err != nil {
+	if err := h.decodeGosutoSection(rawSection, &newInstructions); err != nil {
 		return "", fmt.Errorf("invalid instructions YAML: %w", err)
 	}
 
@@ -695,7 +962,7 @@ func (h *Handlers) HandleGosutoSetPersona(ctx context.Context, cmd *Command, evt
 	}
 
 	var newPersona gosuto.Persona
-	if err := yaml.Unmarshal(rawSection, &newPersona); err != nil {
+	if err := h.decodeGosutoSection(rawSection, &newPersona); err != nil {
 		return "", fmt.Errorf("invalid persona YAML: %w", err)
 	}
 
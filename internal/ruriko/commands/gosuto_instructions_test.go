@@ -10,6 +10,7 @@ package commands_test
 //   - HandleGosutoSetPersona: updates only the persona section; creates a new versioned
 //     Gosuto entry; leaves instructions unchanged.
 //   - No-op detection: set-instructions with identical content does not create a new version.
+//   - Strict mode: unknown keys in a set-instructions payload are rejected.
 //   - Audit trail: instructions update emits a gosuto.set-instructions audit log entry.
 //   - Provisioned agent: a template with instructions produces a Gosuto version that
 //     contains those instructions.
@@ -24,6 +25,7 @@ import (
 	"testing/fstest"
 
 	"github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/internal/ruriko/commands"
 	appstore "github.com/bdobrica/Ruriko/internal/ruriko/store"
 	"github.com/bdobrica/Ruriko/internal/ruriko/templates"
 	"gopkg.in/yaml.v3"
@@ -395,6 +397,35 @@ func TestGosutoSetPersona_CreatesNewVersion(t *testing.T) {
 	}
 }
 
+// TestGosutoSetInstructions_StrictRejectsUnknownKeys verifies that in strict
+// mode a typo'd key in the instructions payload — top-level or nested — is
+// rejected, while lenient mode ignores it.
+func TestGosutoSetInstructions_StrictRejectsUnknownKeys(t *testing.T) {
+	typos := map[string]string{
+		"top-level": strings.Replace(updatedInstructionsYAML, "context:", "contxt:", 1),
+		"nested":    strings.Replace(updatedInstructionsYAML, "context:", "    priorty: high\ncontext:", 1),
+	}
+	for name, content := range typos {
+		t.Run(name, func(t *testing.T) {
+			_, s, _ := newHandlerFixture(t)
+			ctx := context.Background()
+			seedAgentWithGosuto(t, s, "typobot", validGosutoWithPersonaAndInstructions)
+			cmd := parseCmd(t, "/ruriko gosuto set-instructions typobot --content "+b64(content))
+
+			strict := commands.NewHandlers(commands.HandlersConfig{Store: s, GosutoStrict: true})
+			_, err := strict.HandleGosutoSetInstructions(ctx, cmd, fakeEvent("@admin:example.com"))
+			if err == nil || !strings.Contains(err.Error(), "not found in type") {
+				t.Fatalf("strict err = %v, want an unknown-field error", err)
+			}
+
+			lenient := commands.NewHandlers(commands.HandlersConfig{Store: s})
+			if _, err := lenient.HandleGosutoSetInstructions(ctx, cmd, fakeEvent("@admin:example.com")); err != nil {
+				t.Fatalf("lenient mode should ignore the unknown key: %v", err)
+			}
+		})
+	}
+}
+
 // ────────────────────────────────────────────────────────────────────────────
 // Audit trail
 
@@ -511,3 +542,89 @@ func TestGosutoDiff_AnnotatesSectionChanges(t *testing.T) {
 		t.Errorf("diff unexpectedly reports persona as changed; got:\n%s", resp)
 	}
 }
+
+func TestGosutoHistory_LabelsSectionChangesPerVersion(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	// v1 base, v2 persona only, v3 instructions only.
+	seedAgentWithGosuto(t, s, "histbot", validGosutoWithPersonaAndInstructions)
+	if _, err := h.HandleGosutoSetPersona(ctx, parseCmd(t, "/ruriko gosuto set-persona histbot --content "+b64(updatedPersonaYAML)), fakeEvent("@alice:example.com")); err != nil {
+		t.Fatalf("HandleGosutoSetPersona: %v", err)
+	}
+	if _, err := h.HandleGosutoSetInstructions(ctx, parseCmd(t, "/ruriko gosuto set-instructions histbot --content "+b64(updatedInstructionsYAML)), fakeEvent("@bob:example.com")); err != nil {
+		t.Fatalf("HandleGosutoSetInstructions: %v", err)
+	}
+
+	resp, err := h.HandleGosutoHistory(ctx, parseCmd(t, "/ruriko gosuto history histbot"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoHistory: %v", err)
+	}
+
+	lines := map[int]string{}
+	for _, line := range strings.Split(resp, "\n") {
+		for v := 1; v <= 3; v++ {
+			if strings.HasPrefix(line, fmt.Sprintf("- **v%d** ", v)) {
+				lines[v] = line
+			}
+		}
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected one changelog line per version, got:\n%s", resp)
+	}
+	if !strings.Contains(lines[3], "@bob:example.com") || !strings.HasSuffix(lines[3], "Changed sections: **instructions**") {
+		t.Errorf("v3 should be an instructions-only change by bob; got %q", lines[3])
+	}
+	if !strings.Contains(lines[2], "@alice:example.com") || !strings.HasSuffix(lines[2], "Changed sections: **persona**") {
+		t.Errorf("v2 should be a persona-only change by alice; got %q", lines[2])
+	}
+	if !strings.HasSuffix(lines[1], "initial version") {
+		t.Errorf("v1 should be labelled as the initial version; got %q", lines[1])
+	}
+}
+
+func TestGosutoHistory_LimitCapsVersionsShown(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "histbot", validGosutoWithPersonaAndInstructions)
+	if _, err := h.HandleGosutoSetPersona(ctx, parseCmd(t, "/ruriko gosuto set-persona histbot --content "+b64(updatedPersonaYAML)), fakeEvent("@alice:example.com")); err != nil {
+		t.Fatalf("HandleGosutoSetPersona: %v", err)
+	}
+
+	resp, err := h.HandleGosutoHistory(ctx, parseCmd(t, "/ruriko gosuto history histbot --limit 1"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoHistory: %v", err)
+	}
+	if !strings.Contains(resp, "(1 of 2 versions)") || strings.Contains(resp, "- **v1** ") {
+		t.Errorf("expected only v2 to be shown; got:\n%s", resp)
+	}
+	// The shown version is still compared against its (hidden) predecessor.
+	if !strings.Contains(resp, "Changed sections: **persona**") {
+		t.Errorf("expected v2 to be diffed against v1; got:\n%s", resp)
+	}
+	if !strings.Contains(resp, "1 more not shown") {
+		t.Errorf("expected a note about hidden versions; got:\n%s", resp)
+	}
+}
+
+func TestGosutoHistory_PrunedOldestIsNotInitial(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "histbot", validGosutoWithPersonaAndInstructions)
+	if _, err := h.HandleGosutoSetPersona(ctx, parseCmd(t, "/ruriko gosuto set-persona histbot --content "+b64(updatedPersonaYAML)), fakeEvent("@alice:example.com")); err != nil {
+		t.Fatalf("HandleGosutoSetPersona: %v", err)
+	}
+	if err := s.PruneGosutoVersions(ctx, "histbot", 1); err != nil {
+		t.Fatalf("PruneGosutoVersions: %v", err)
+	}
+
+	resp, err := h.HandleGosutoHistory(ctx, parseCmd(t, "/ruriko gosuto history histbot"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoHistory: %v", err)
+	}
+	if strings.Contains(resp, "initial version") || !strings.Contains(resp, "oldest retained version (v1 and earlier were pruned)") {
+		t.Errorf("expected v2 to be labelled as the oldest retained version; got:\n%s", resp)
+	}
+}
@@ -0,0 +1,118 @@
+package commands_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appstore "github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// addGosutoVersion stores yamlBlob under an explicit hash as the agent's next
+// version, so tests can control which hashes share a prefix.
+func addGosutoVersion(t *testing.T, s *appstore.Store, agentID, hash, yamlBlob string) int {
+	t.Helper()
+	ctx := context.Background()
+	next, err := s.NextGosutoVersion(ctx, agentID)
+	if err != nil {
+		t.Fatalf("NextGosutoVersion: %v", err)
+	}
+	if err := s.CreateGosutoVersion(ctx, &appstore.GosutoVersion{
+		AgentID: agentID, Version: next, Hash: hash, YAMLBlob: yamlBlob, CreatedByMXID: "@admin:example.com",
+	}); err != nil {
+		t.Fatalf("CreateGosutoVersion: %v", err)
+	}
+	return next
+}
+
+const (
+	hashA = "abcdef0111111111111111111111111111111111111111111111111111111111"
+	hashB = "abcdef0222222222222222222222222222222222222222222222222222222222"
+)
+
+// seedRollbackChain gives agent "rollbot" v1 (seed), v2 (hashA) and v3
+// (hashB); v2 and v3 share the hash prefix "abcdef0".
+func seedRollbackChain(t *testing.T, s *appstore.Store) {
+	t.Helper()
+	seedAgentWithGosuto(t, s, "rollbot", validGosutoWithPersonaAndInstructions)
+	addGosutoVersion(t, s, "rollbot", hashA, "# version A\n")
+	addGosutoVersion(t, s, "rollbot", hashB, "# version B\n")
+}
+
+func TestGosutoRollback_ByFullHash(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+	seedRollbackChain(t, s)
+
+	resp, err := h.HandleGosutoRollback(ctx, parseCmd(t, "/ruriko gosuto rollback rollbot --hash "+hashA), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoRollback: %v", err)
+	}
+	if !strings.Contains(resp, "rolled back to v2 content, saved as **v4**") {
+		t.Errorf("unexpected response:\n%s", resp)
+	}
+	latest, err := s.GetLatestGosutoVersion(ctx, "rollbot")
+	if err != nil {
+		t.Fatalf("GetLatestGosutoVersion: %v", err)
+	}
+	if latest.Hash != hashA || latest.YAMLBlob != "# version A\n" {
+		t.Errorf("latest = hash %s, blob %q; want version A content", latest.Hash, latest.YAMLBlob)
+	}
+}
+
+func TestGosutoRollback_ByShortHashPrefix(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+	seed := seedAgentWithGosuto(t, s, "rollbot", validGosutoWithPersonaAndInstructions)
+	addGosutoVersion(t, s, "rollbot", hashA, "# version A\n")
+
+	resp, err := h.HandleGosutoRollback(ctx, parseCmd(t, "/ruriko gosuto rollback rollbot --hash "+strings.ToUpper(seed.Hash[:8])), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoRollback: %v", err)
+	}
+	if !strings.Contains(resp, "rolled back to v1 content, saved as **v3**") {
+		t.Errorf("unexpected response:\n%s", resp)
+	}
+	latest, err := s.GetLatestGosutoVersion(ctx, "rollbot")
+	if err != nil {
+		t.Fatalf("GetLatestGosutoVersion: %v", err)
+	}
+	if latest.Hash != seed.Hash {
+		t.Errorf("latest hash = %s, want the v1 hash %s", latest.Hash, seed.Hash)
+	}
+}
+
+func TestGosutoRollback_AmbiguousHashPrefix(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+	seedRollbackChain(t, s)
+
+	_, err := h.HandleGosutoRollback(ctx, parseCmd(t, "/ruriko gosuto rollback rollbot --hash abcdef0"), fakeEvent("@admin:example.com"))
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("err = %v, want ambiguous-prefix error", err)
+	}
+	for _, want := range []string{"v2", "v3"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should list matching version %s: %v", want, err)
+		}
+	}
+	if n, _ := s.NextGosutoVersion(ctx, "rollbot"); n != 4 {
+		t.Errorf("next version = %d, want 4 (no rollback stored)", n)
+	}
+}
+
+func TestGosutoRollback_RejectsBadHashFlags(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedRollbackChain(t, s)
+
+	for _, c := range []string{
+		"/ruriko gosuto rollback rollbot --hash abc",                 // too short
+		"/ruriko gosuto rollback rollbot --hash zzzzzzzz",            // not hex
+		"/ruriko gosuto rollback rollbot --hash 999999",              // unknown
+		"/ruriko gosuto rollback rollbot --to 1 --hash " + hashA[:8], // both
+	} {
+		if _, err := h.HandleGosutoRollback(context.Background(), parseCmd(t, c), fakeEvent("@admin:example.com")); err == nil {
+			t.Errorf("%s: expected error", c)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package commands_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestGosutoSet_CosmeticChangeIsUnchanged verifies that gosuto set hashes the
+// canonical form of a config, so re-submitting the current config with only
+// comments and formatting changed does not create a new version.
+func TestGosutoSet_CosmeticChangeIsUnchanged(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+	seedAgentWithGosuto(t, s, "canonbot", validGosutoWithPersonaAndInstructions)
+
+	restyled := "# reviewed by ops\n" + strings.ReplaceAll(validGosutoWithPersonaAndInstructions, `"`, `'`)
+	resp, err := h.HandleGosutoSet(ctx, parseCmd(t, "/ruriko gosuto set canonbot --content "+b64(restyled)), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoSet: %v", err)
+	}
+	if !strings.Contains(resp, "unchanged") {
+		t.Errorf("response = %q, want the config reported unchanged", resp)
+	}
+	if gv, err := s.GetLatestGosutoVersion(ctx, "canonbot"); err != nil || gv.Version != 1 {
+		t.Fatalf("latest version = %v (err %v), want v1", gv, err)
+	}
+
+	changed := strings.Replace(validGosutoWithPersonaAndInstructions, "gpt-4o", "gpt-4o-mini", 1)
+	resp, err = h.HandleGosutoSet(ctx, parseCmd(t, "/ruriko gosuto set canonbot --content "+b64(changed)), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoSet: %v", err)
+	}
+	if !strings.Contains(resp, "v2") {
+		t.Errorf("response = %q, want a new v2", resp)
+	}
+}
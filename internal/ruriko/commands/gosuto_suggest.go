@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/nlp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// HandleGosutoSuggest asks the configured NLP provider to draft capabilities
+// and instructions edits for an agent and shows them as a diff against the
+// current Gosuto config. Nothing is stored or pushed: the reply carries a
+// ready-made `/ruriko gosuto set` command the operator can review and run,
+// which then goes through the usual validation and approval.
+//
+// Only the capabilities and instructions sections of the suggestion are
+// kept; any other change the LLM makes is discarded. Calls count against the
+// sender's NLP rate limit and daily token budget.
+//
+// Usage: /ruriko gosuto suggest <agent> --goal "<natural language>"
+func (h *Handlers) HandleGosutoSuggest(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko gosuto suggest <agent> --goal \"<natural language>\"")
+	}
+	goal := strings.TrimSpace(cmd.GetFlag("goal", ""))
+	if goal == "" {
+		return "", fmt.Errorf("--goal is required (describe the change in plain language)")
+	}
+
+	if _, err := h.store.GetAgent(ctx, agentID); err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "gosuto.suggest", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	current, err := h.store.GetLatestGosutoVersion(ctx, agentID)
+	if err != nil {
+		return "", fmt.Errorf("no gosuto config found for agent %q: %w", agentID, err)
+	}
+
+	suggester, ok := h.resolveNLPProvider(ctx).(nlp.Suggester)
+	if !ok {
+		return "", fmt.Errorf("gosuto suggest needs an NLP provider that can draft configs; none is configured")
+	}
+
+	sender := evt.Sender.String()
+	if h.nlpRateLimiter != nil && !h.nlpRateLimiter.Allow(sender) {
+		return nlp.RateLimitMessage, nil
+	}
+	if h.nlpTokenBudget != nil && !h.nlpTokenBudget.Allow(sender) {
+		return nlp.TokenBudgetExceededMessage, nil
+	}
+
+	resp, err := suggester.SuggestGosuto(ctx, nlp.SuggestRequest{
+		AgentID:     agentID,
+		Goal:        goal,
+		CurrentYAML: current.YAMLBlob,
+	})
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, sender, "gosuto.suggest", agentID, "error", nil, err.Error())
+		if errors.Is(err, nlp.ErrRateLimit) {
+			return nlp.APIRateLimitMessage, nil
+		}
+		return "", fmt.Errorf("failed to get a suggestion: %w", err)
+	}
+	if resp.Usage != nil && h.nlpTokenBudget != nil {
+		h.nlpTokenBudget.RecordUsage(sender, resp.Usage.TotalTokens)
+	}
+
+	fromYAML, toYAML, err := mergeGosutoSuggestion(current.YAMLBlob, resp.YAML)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, sender, "gosuto.suggest", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	payload := store.AuditPayload{"version": current.Version, "changed": fromYAML != toYAML}
+	if resp.Usage != nil {
+		payload["total_tokens"] = resp.Usage.TotalTokens
+	}
+	if err := h.store.WriteAudit(ctx, traceID, sender, "gosuto.suggest", agentID, "success", payload, ""); err != nil {
+		slog.Warn("audit write failed", "op", "gosuto.suggest", "err", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "💡 **Suggested Gosuto change for %s** (against v%d)\n\n", agentID, current.Version)
+	if explanation := strings.TrimSpace(resp.Explanation); explanation != "" {
+		fmt.Fprintf(&b, "%s\n\n", explanation)
+	}
+	if fromYAML == toYAML {
+		fmt.Fprintf(&b, "No capabilities or instructions changes were proposed.\n\n(trace: %s)", traceID)
+		return b.String(), nil
+	}
+	fmt.Fprintf(&b, "%s\n```diff\n%s\n```\n\n", gosutoDiffSections(fromYAML, toYAML), diffLines(fromYAML, toYAML))
+	fmt.Fprintf(&b, "This is only a proposal; nothing has been stored. To apply it, review the diff and run:\n\n`/ruriko gosuto set %s --content %s`\n\n(trace: %s)",
+		agentID, base64.StdEncoding.EncodeToString([]byte(toYAML)), traceID)
+	return b.String(), nil
+}
+
+// mergeGosutoSuggestion copies the capabilities and instructions sections of
+// suggestedYAML onto currentYAML and returns both configs in canonical form,
+// so the diff shows only what the suggestion changes. The merged config must
+// pass Gosuto validation.
+func mergeGosutoSuggestion(currentYAML, suggestedYAML string) (string, string, error) {
+	var cur gosuto.Config
+	if err := yaml.Unmarshal([]byte(currentYAML), &cur); err != nil {
+		return "", "", fmt.Errorf("parse current gosuto: %w", err)
+	}
+	var suggested gosuto.Config
+	if err := yaml.Unmarshal([]byte(suggestedYAML), &suggested); err != nil {
+		return "", "", fmt.Errorf("suggested config is not valid YAML: %w", err)
+	}
+
+	fromBytes, err := yaml.Marshal(&cur)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal current gosuto: %w", err)
+	}
+	cur.Capabilities = suggested.Capabilities
+	cur.Instructions = suggested.Instructions
+	toBytes, err := yaml.Marshal(&cur)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal suggested gosuto: %w", err)
+	}
+	if _, err := gosuto.Parse(toBytes); err != nil {
+		return "", "", fmt.Errorf("suggested config is invalid: %w", err)
+	}
+	return string(fromBytes), string(toBytes), nil
+}
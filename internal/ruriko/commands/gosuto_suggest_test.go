@@ -0,0 +1,138 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/commands"
+	"github.com/bdobrica/Ruriko/internal/ruriko/nlp"
+)
+
+// suggestProvider is an NLP provider that returns a canned Gosuto
+// suggestion and counts the calls it receives.
+type suggestProvider struct {
+	yaml  string
+	calls int
+	got   nlp.SuggestRequest
+}
+
+func (p *suggestProvider) Classify(context.Context, nlp.ClassifyRequest) (*nlp.ClassifyResponse, error) {
+	return &nlp.ClassifyResponse{Intent: nlp.IntentUnknown}, nil
+}
+
+func (p *suggestProvider) SuggestGosuto(_ context.Context, req nlp.SuggestRequest) (*nlp.SuggestResponse, error) {
+	p.calls++
+	p.got = req
+	return &nlp.SuggestResponse{
+		YAML:        p.yaml,
+		Explanation: "Allows the weather lookup.",
+		Usage:       &nlp.TokenUsage{TotalTokens: 42},
+	}, nil
+}
+
+// suggestedGosutoYAML adds a capability and instructions to
+// coverageGosutoYAML and also tries to widen trust, which must be dropped.
+var suggestedGosutoYAML = strings.Replace(
+	strings.Replace(coverageGosutoYAML, `    - "!admin:example.com"`, `    - "*"`, 1),
+	"persona:",
+	`  - name: allow-weather
+    mcp: weather
+    tool: get_forecast
+    allow: true
+instructions:
+  role: "Answer weather questions."
+persona:`, 1)
+
+func TestGosutoSuggest_ProposesDiffWithoutApplying(t *testing.T) {
+	_, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+	seeded := seedAgentWithGosuto(t, s, "covbot", coverageGosutoYAML)
+
+	prov := &suggestProvider{yaml: suggestedGosutoYAML}
+	h := commands.NewHandlers(commands.HandlersConfig{Store: s, NLPProvider: prov})
+
+	resp, err := h.HandleGosutoSuggest(ctx, parseCmd(t, `/ruriko gosuto suggest covbot --goal "let it check the weather"`), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoSuggest: %v", err)
+	}
+	if prov.calls != 1 || prov.got.Goal != "let it check the weather" || prov.got.CurrentYAML != seeded.YAMLBlob {
+		t.Errorf("provider got %d calls, request %+v", prov.calls, prov.got)
+	}
+	for _, want := range []string{"Allows the weather lookup.", "```diff", "- name: allow-weather", "/ruriko gosuto set covbot --content "} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("response missing %q:\n%s", want, resp)
+		}
+	}
+	var changed []string
+	for _, line := range strings.Split(resp, "\n") {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			changed = append(changed, line)
+		}
+	}
+	if diff := strings.Join(changed, "\n"); !strings.Contains(diff, "allow-weather") || strings.Contains(diff, "admin:example.com") {
+		t.Errorf("changed lines = %q, want the new capability and no trust change", diff)
+	}
+
+	// The proposed set command carries the merged config.
+	b64 := strings.Fields(resp[strings.Index(resp, "--content ")+len("--content "):])[0]
+	b64 = strings.TrimSuffix(b64, "`")
+	merged, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		t.Fatalf("decode proposed content: %v", err)
+	}
+	if !strings.Contains(string(merged), "allow-weather") || !strings.Contains(string(merged), "!admin:example.com") {
+		t.Errorf("proposed config = %s, want the new capability and the original trust", merged)
+	}
+
+	// Nothing was stored.
+	latest, err := s.GetLatestGosutoVersion(ctx, "covbot")
+	if err != nil {
+		t.Fatalf("GetLatestGosutoVersion: %v", err)
+	}
+	if latest.Version != seeded.Version || latest.Hash != seeded.Hash {
+		t.Errorf("latest version = v%d, want the seeded v1 untouched", latest.Version)
+	}
+}
+
+func TestGosutoSuggest_EnforcesRateLimit(t *testing.T) {
+	_, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "covbot", coverageGosutoYAML)
+
+	prov := &suggestProvider{yaml: suggestedGosutoYAML}
+	h := commands.NewHandlers(commands.HandlersConfig{
+		Store:          s,
+		NLPProvider:    prov,
+		NLPRateLimiter: nlp.NewRateLimiter(1, time.Minute),
+	})
+
+	cmd := parseCmd(t, `/ruriko gosuto suggest covbot --goal "weather"`)
+	if _, err := h.HandleGosutoSuggest(context.Background(), cmd, fakeEvent("@admin:example.com")); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	resp, err := h.HandleGosutoSuggest(context.Background(), cmd, fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	if resp != nlp.RateLimitMessage {
+		t.Errorf("second call = %q, want the rate-limit message", resp)
+	}
+	if prov.calls != 1 {
+		t.Errorf("provider calls = %d, want 1", prov.calls)
+	}
+}
+
+func TestGosutoSuggest_RequiresGoalAndSuggester(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "covbot", coverageGosutoYAML)
+
+	if _, err := h.HandleGosutoSuggest(context.Background(), parseCmd(t, "/ruriko gosuto suggest covbot"), fakeEvent("@admin:example.com")); err == nil {
+		t.Error("expected an error without --goal")
+	}
+	// The fixture has no NLP provider configured.
+	if _, err := h.HandleGosutoSuggest(context.Background(), parseCmd(t, `/ruriko gosuto suggest covbot --goal "weather"`), fakeEvent("@admin:example.com")); err == nil {
+		t.Error("expected an error without an NLP provider")
+	}
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -11,6 +12,7 @@ import (
 
 	"maunium.net/go/mautrix/event"
 
+	"github.com/bdobrica/Ruriko/common/ratelimit"
 	"github.com/bdobrica/Ruriko/common/trace"
 	"github.com/bdobrica/Ruriko/common/version"
 	"github.com/bdobrica/Ruriko/internal/ruriko/approvals"
@@ -21,6 +23,7 @@ import (
 	"github.com/bdobrica/Ruriko/internal/ruriko/nlp"
 	"github.com/bdobrica/Ruriko/internal/ruriko/provisioning"
 	"github.com/bdobrica/Ruriko/internal/ruriko/runtime"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
 	"github.com/bdobrica/Ruriko/internal/ruriko/secrets"
 	"github.com/bdobrica/Ruriko/internal/ruriko/store"
 	"github.com/bdobrica/Ruriko/internal/ruriko/templates"
@@ -44,13 +47,21 @@ type HandlersConfig struct {
 	// post Matrix breadcrumb notices back to the room where the operator
 	// issued the create command.  The *matrix.Client satisfies this interface.
 	RoomSender RoomSender // optional — enables provisioning breadcrumbs
-	// DefaultAgentImage is the container image used when creating agents via
-	// the natural-language provisioning wizard (R5.4).  When empty, the wizard
-	// falls back to "ghcr.io/bdobrica/gitai:latest".
-	DefaultAgentImage string // optional — default agent image for NL provisioning
+	// DefaultAgentImage is the container image used for new agents when
+	// neither --image nor the template's metadata.image names one.  When
+	// empty, "ghcr.io/bdobrica/gitai:latest" is used.
+	DefaultAgentImage string // optional — default agent image (DEFAULT_AGENT_IMAGE)
 	// MatrixHomeserver is the Matrix homeserver URL injected into agent
 	// container environments as MATRIX_HOMESERVER so gitai can connect.
 	MatrixHomeserver string // optional — injected into spawned agent containers
+	// SecretRefreshURL is Ruriko's POST /agents/secrets/refresh endpoint,
+	// injected into agent containers as RURIKO_SECRET_REFRESH_URL so agents
+	// can ask for fresh secret leases.
+	SecretRefreshURL string // optional — injected into spawned agent containers
+	// GosutoStrict makes gosuto set reject configs that produce validation
+	// warnings instead of accepting them with a notice, and makes
+	// set-instructions/set-persona reject unknown keys in their payload.
+	GosutoStrict bool // optional — strict Gosuto validation (RURIKO_GOSUTO_STRICT)
 
 	// NLPProvider, when non-nil, is used by HandleNaturalLanguage to
 	// classify free-form user messages via an LLM instead of the built-in
@@ -95,6 +106,11 @@ type HandlersConfig struct {
 	// The first entry is the primary admin room; subsequent entries are used
 	// as the user/report room when rendering Gosuto templates.
 	AdminRooms []string // optional — populates template vars during provisioning
+
+	// Liveness controls how agents.list / agents.show classify an agent's
+	// last_seen age as online, stale or offline.  Zero fields fall back to
+	// runtime.DefaultLivenessThresholds.
+	Liveness runtime.LivenessThresholds // optional — heartbeat thresholds
 }
 
 // RoomSender is the subset of the Matrix client needed for posting breadcrumb
@@ -119,6 +135,8 @@ type Handlers struct {
 	conversations     *conversationStore
 	defaultAgentImage string
 	matrixHomeserver  string
+	secretRefreshURL  string
+	gosutoStrict      bool
 	nlpProvider       nlp.Provider
 	nlpRateLimiter    *nlp.RateLimiter
 	nlpTokenBudget    *nlp.TokenBudget
@@ -149,6 +167,12 @@ type Handlers struct {
 	// as the user/report room when rendering Gosuto templates.
 	adminRooms []string
 
+	// liveness classifies last_seen ages for agents.list / agents.show.
+	liveness runtime.LivenessThresholds
+
+	// broadcastLimiter caps how often agents.broadcast may post notices.
+	broadcastLimiter *ratelimit.KeyedFixedWindow
+
 	// nlHistoryFallback stores short-term conversation history per room+sender
 	// for NLP calls when the R10 memory assembler is not configured.
 	nlHistoryFallback *nlHistoryStore
@@ -188,6 +212,8 @@ func NewHandlers(cfg HandlersConfig) *Handlers {
 		conversations:     newConversationStore(),
 		defaultAgentImage: cfg.DefaultAgentImage,
 		matrixHomeserver:  cfg.MatrixHomeserver,
+		secretRefreshURL:  cfg.SecretRefreshURL,
+		gosutoStrict:      cfg.GosutoStrict,
 		nlpProvider:       cfg.NLPProvider,
 		nlpRateLimiter:    cfg.NLPRateLimiter,
 		nlpTokenBudget:    cfg.NLPTokenBudget,
@@ -196,6 +222,8 @@ func NewHandlers(cfg HandlersConfig) *Handlers {
 		memory:            cfg.Memory,
 		sealPipeline:      cfg.SealPipeline,
 		adminRooms:        cfg.AdminRooms,
+		liveness:          cfg.Liveness,
+		broadcastLimiter:  ratelimit.NewKeyedFixedWindow(broadcastWindow),
 		nlHistoryFallback: newNLHistoryStore(),
 	}
 }
@@ -251,12 +279,21 @@ func (h *Handlers) HandleHelp(ctx context.Context, cmd *Command, evt *event.Even
 **Agent Commands:**
 • /ruriko agents list - List all agents
 • /ruriko agents show <name> - Show agent details
-• /ruriko agents create --name <id> --template <tmpl> --image <image> [--mxid <existing>] [--peer-alias <alias> --peer-mxid <mxid> --peer-room <room-id> --peer-protocol-id <id> --peer-protocol-prefix <prefix>] - Create agent
+• /ruriko agents create --name <id> --template <tmpl> [--image <image>] [--mxid <existing>] [--peer-alias <alias> --peer-mxid <mxid> --peer-room <room-id> --peer-protocol-id <id> --peer-protocol-prefix <prefix>] - Create agent
 • /ruriko agents stop <name> - Stop agent
 • /ruriko agents start <name> - Start agent
 • /ruriko agents respawn <name> - Force respawn agent
 • /ruriko agents status <name> - Show agent runtime status
 • /ruriko agents cancel <name> - Cancel in-flight task on agent
+• /ruriko agents diff-live <name> - Diff the live agent config against the latest stored Gosuto version
+• /ruriko agents dlq <name> [--retry <id>] - List failed event turns on an agent, or retry one
+• /ruriko agents tools <name> [--schemas] - List the tools an agent offers its LLM
+• /ruriko agents calls <name> [--limit N] - Show an agent's tool-call audit trail (argument names only)
+• /ruriko agents logs <name> [--limit N] - Show an agent's recent turns: trigger, status, tool calls, duration
+• /ruriko agents transcript <name> <trace> - Show the redacted LLM message history of one turn (needs FEATURE_TURN_TRANSCRIPTS)
+• /ruriko agents rotate-token <name> [--overlap 10m] - Roll an agent's ACP token; the old one works until the overlap ends
+• /ruriko agents broadcast --message "<text>" [--agent a,b] - Post a maintenance notice to every room of each agent (requires approval)
+• /ruriko agents secrets-check <name> - Compare the secrets an agent's Gosuto refers to with its bindings
 • /ruriko agents delete <name> - Delete agent
 • /ruriko agents matrix register <name> [--mxid <existing>] - Provision Matrix account
 • /ruriko agents disable <name> [--erase] - Soft-disable agent (deactivates Matrix account)
@@ -292,11 +329,17 @@ func (h *Handlers) HandleHelp(ctx context.Context, cmd *Command, evt *event.Even
 • /ruriko gosuto show <agent> [--version <n>] - Show current (or specific) Gosuto config with persona and instructions sections clearly labelled
 • /ruriko gosuto versions <agent> - List all stored versions
 • /ruriko gosuto diff <agent> --from <v1> --to <v2> - Diff between two versions (annotates which sections changed)
+• /ruriko gosuto history <agent> [--limit N] - Per-version changelog: author, date and sections changed vs the previous version
+• /ruriko gosuto coverage <agent> - Show the policy decision for every tool the agent exposes
 • /ruriko gosuto set <agent> --content <base64yaml> - Store new Gosuto version (full config)
 • /ruriko gosuto set-instructions <agent> --content <base64yaml> - Update only the instructions section (persona unchanged)
 • /ruriko gosuto set-persona <agent> --content <base64yaml> - Update only the persona section (instructions unchanged)
-• /ruriko gosuto rollback <agent> --to <version> - Revert to previous version
-• /ruriko gosuto push <agent> - Push current config to running agent
+• /ruriko gosuto rollback <agent> --to <version> | --hash <hash> - Revert to previous version (hash may be a unique prefix)
+• /ruriko gosuto push <agent> [--force] - Push current config to running agent (refused while required secrets are unbound unless --force)
+• /ruriko gosuto suggest <agent> --goal "<text>" - Draft capabilities/instructions edits as a diff to review (never applied)
+• /ruriko gosuto canary <agent> --room <room-id> [--version <n>] - Run a config in one room only; other rooms keep the live config
+• /ruriko gosuto promote <agent> - Apply the active canary config to every room
+• /ruriko gosuto abort <agent> - Drop the active canary config
 
 **Approvals Commands:**
 • /ruriko approvals list [--status pending|approved|denied|expired|cancelled] - List approvals
@@ -393,6 +436,7 @@ func (h *Handlers) HandleAgentsList(ctx context.Context, cmd *Command, evt *even
 		if agent.MXID.Valid {
 			sb.WriteString(fmt.Sprintf("  MXID: %s\n", agent.MXID.String))
 		}
+		sb.WriteString(fmt.Sprintf("  Liveness: %s\n", h.livenessSummary(agent.LastSeen)))
 		if agent.LastSeen.Valid {
 			sb.WriteString(fmt.Sprintf("  Last Seen: %s\n", agent.LastSeen.Time.Format(time.RFC3339)))
 		}
@@ -404,6 +448,57 @@ func (h *Handlers) HandleAgentsList(ctx context.Context, cmd *Command, evt *even
 	return sb.String(), nil
 }
 
+// livenessSummary renders an agent's heartbeat state, e.g.
+// "🟢 online (seen 12s ago)".
+func (h *Handlers) livenessSummary(lastSeen sql.NullTime) string {
+	state := h.liveness.Classify(lastSeen, time.Now())
+	icon := "⚪"
+	switch state {
+	case runtime.LivenessOnline:
+		icon = "🟢"
+	case runtime.LivenessStale:
+		icon = "🟡"
+	case runtime.LivenessOffline:
+		icon = "🔴"
+	}
+	if !lastSeen.Valid {
+		return fmt.Sprintf("%s %s (never seen)", icon, state)
+	}
+	age := time.Since(lastSeen.Time).Round(time.Second)
+	return fmt.Sprintf("%s %s (seen %s ago)", icon, state, age)
+}
+
+// lastConfigApplySummary fetches the agent's ACP status and formats the
+// outcome of its most recent config apply. Errors are reported inline: the
+// rest of agents.show comes from the database and should not fail with them.
+func lastConfigApplySummary(ctx context.Context, client *acp.Client) string {
+	st, err := client.Status(ctx)
+	if err != nil {
+		return "**Last Config Apply:** unavailable (agent unreachable)\n"
+	}
+	la := st.LastConfigApply
+	if la == nil {
+		return "**Last Config Apply:** none since agent start\n"
+	}
+	hash := la.Hash
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	line := fmt.Sprintf("**Last Config Apply:** %s (hash `%s`, %s)\n", la.Result, hash, la.AppliedAt.Format(time.RFC3339))
+	if la.Message != "" {
+		line += fmt.Sprintf("  %s\n", la.Message)
+	}
+	for _, c := range []struct {
+		label string
+		names []string
+	}{{"started", la.Started}, {"stopped", la.Stopped}, {"restarted", la.Restarted}} {
+		if len(c.names) > 0 {
+			line += fmt.Sprintf("  %s: %s\n", c.label, strings.Join(c.names, ", "))
+		}
+	}
+	return line
+}
+
 // HandleAgentsShow shows details for a specific agent
 func (h *Handlers) HandleAgentsShow(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
 	traceID := trace.GenerateID()
@@ -455,10 +550,15 @@ func (h *Handlers) HandleAgentsShow(ctx context.Context, cmd *Command, evt *even
 		sb.WriteString(fmt.Sprintf("**Gosuto Version:** %d\n", agent.GosutoVersion.Int64))
 	}
 
+	sb.WriteString(fmt.Sprintf("**Liveness:** %s\n", h.livenessSummary(agent.LastSeen)))
 	if agent.LastSeen.Valid {
 		sb.WriteString(fmt.Sprintf("**Last Seen:** %s\n", agent.LastSeen.Time.Format(time.RFC3339)))
 	}
 
+	if client, err := agentACPClient(agent); err == nil {
+		sb.WriteString(lastConfigApplySummary(ctx, client))
+	}
+
 	sb.WriteString(fmt.Sprintf("**Created:** %s\n", agent.CreatedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("**Updated:** %s\n", agent.UpdatedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("\n(trace: %s)", traceID))
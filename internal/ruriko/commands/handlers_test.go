@@ -8,16 +8,23 @@ package commands_test
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"maunium.net/go/mautrix/event"
 	"maunium.net/go/mautrix/id"
 
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
 	"github.com/bdobrica/Ruriko/internal/ruriko/commands"
 	"github.com/bdobrica/Ruriko/internal/ruriko/secrets"
 	appstore "github.com/bdobrica/Ruriko/internal/ruriko/store"
+	"github.com/bdobrica/Ruriko/internal/ruriko/templates"
 )
 
 // --- helpers ---------------------------------------------------------------
@@ -183,6 +190,20 @@ func TestHandleAgentsShow_Found(t *testing.T) {
 	if !strings.Contains(resp, "research-agent") {
 		t.Errorf("expected template in show response, got %q", resp)
 	}
+	if !strings.Contains(resp, "unknown (never seen)") {
+		t.Errorf("expected never-seen liveness in show response, got %q", resp)
+	}
+
+	if err := s.UpdateAgentLastSeen(ctx, "researchbot"); err != nil {
+		t.Fatalf("UpdateAgentLastSeen: %v", err)
+	}
+	resp, err = h.HandleAgentsShow(ctx, cmd, fakeEvent("@alice:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsShow: %v", err)
+	}
+	if !strings.Contains(resp, "online (seen") {
+		t.Errorf("expected online liveness after heartbeat, got %q", resp)
+	}
 }
 
 // --- HandleAgentsCreate (no runtime) ---------------------------------------
@@ -193,7 +214,6 @@ func TestHandleAgentsCreate_MissingFlags(t *testing.T) {
 	cases := []string{
 		"/ruriko agents create",
 		"/ruriko agents create --name mybot",
-		"/ruriko agents create --name mybot --template cron",
 	}
 	for _, input := range cases {
 		cmd := parseCmd(t, input)
@@ -227,6 +247,53 @@ func TestHandleAgentsCreate_NoRuntime(t *testing.T) {
 	}
 }
 
+func TestHandleAgentsCreate_ImageResolutionOrder(t *testing.T) {
+	reg := templates.NewRegistry(fstest.MapFS{
+		"imaged/gosuto.yaml": &fstest.MapFile{Data: []byte("apiVersion: gosuto/v1\nmetadata:\n  name: \"{{.AgentName}}\"\n  image: tmpl-image:1\n")},
+		"plain/gosuto.yaml":  &fstest.MapFile{Data: []byte("apiVersion: gosuto/v1\nmetadata:\n  name: \"{{.AgentName}}\"\n")},
+	})
+
+	cases := []struct {
+		name         string
+		defaultImage string
+		input        string
+		wantImage    string
+		wantSource   string
+	}{
+		{"flag beats template", "env-image:1", "--template imaged --image flag-image:1", "flag-image:1", "--image flag"},
+		{"template beats env", "env-image:1", "--template imaged", "tmpl-image:1", "template"},
+		{"env beats fallback", "env-image:1", "--template plain", "env-image:1", "DEFAULT_AGENT_IMAGE"},
+		{"fallback", "", "--template plain", "ghcr.io/bdobrica/gitai:latest", "built-in default"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, s, sec := newHandlerFixture(t)
+			h := commands.NewHandlers(commands.HandlersConfig{
+				Store:             s,
+				Secrets:           sec,
+				Templates:         reg,
+				DefaultAgentImage: tc.defaultImage,
+			})
+			ctx := context.Background()
+
+			resp, err := h.HandleAgentsCreate(ctx, parseCmd(t, "/ruriko agents create --name imgbot "+tc.input), fakeEvent("@alice:example.com"))
+			if err != nil {
+				t.Fatalf("HandleAgentsCreate: %v", err)
+			}
+			if want := "Image: " + tc.wantImage + " (from " + tc.wantSource + ")"; !strings.Contains(resp, want) {
+				t.Errorf("response missing %q:\n%s", want, resp)
+			}
+			agent, err := s.GetAgent(ctx, "imgbot")
+			if err != nil {
+				t.Fatalf("GetAgent: %v", err)
+			}
+			if agent.Image.String != tc.wantImage {
+				t.Errorf("stored image = %q, want %q", agent.Image.String, tc.wantImage)
+			}
+		})
+	}
+}
+
 func TestHandleAgentsCreate_Duplicate(t *testing.T) {
 	h, _, _ := newHandlerFixture(t)
 	ctx := context.Background()
@@ -375,3 +442,44 @@ func TestHandleAuditTail_Empty(t *testing.T) {
 		t.Errorf("expected audit header in response, got %q", resp)
 	}
 }
+
+func TestHandleAgentsShow_LastConfigApply(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "applybot", validGosutoWithPersonaAndInstructions)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.StatusResponse{
+			AgentID: "applybot",
+			LastConfigApply: &acpspec.ConfigApplyStatus{
+				Hash:      "0123456789abcdef0123",
+				AppliedAt: time.Now(),
+				Result:    acpspec.ConfigApplyWarning,
+				Message:   `mcp "ghost" failed to start: not found`,
+				Started:   []string{"mcp:search", "cron:daily"},
+				Failed:    []string{"mcp:ghost: not found"},
+			},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if err := s.UpdateAgentHandle(ctx, "applybot", "cid-applybot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsShow(ctx, parseCmd(t, "/ruriko agents show applybot"), fakeEvent("@alice:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsShow: %v", err)
+	}
+	if !strings.Contains(resp, "**Last Config Apply:** warning (hash `0123456789ab`") {
+		t.Errorf("expected last apply summary, got:\n%s", resp)
+	}
+	if !strings.Contains(resp, `mcp "ghost" failed to start`) {
+		t.Errorf("expected apply message, got:\n%s", resp)
+	}
+	if !strings.Contains(resp, "started: mcp:search, cron:daily") {
+		t.Errorf("expected reconcile changes, got:\n%s", resp)
+	}
+}
@@ -96,9 +96,43 @@ func generateACPToken() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
+// fallbackAgentImage is the container image used when neither the command,
+// the template nor DEFAULT_AGENT_IMAGE names one.
+const fallbackAgentImage = "ghcr.io/bdobrica/gitai:latest"
+
+// Sources reported by resolveAgentImage.
+const (
+	imageSourceFlag     = "--image flag"
+	imageSourceTemplate = "template"
+	imageSourceEnv      = "DEFAULT_AGENT_IMAGE"
+	imageSourceFallback = "built-in default"
+)
+
+// resolveAgentImage picks the container image for a new agent from, in
+// order: the explicit --image flag, the template's metadata.image, the
+// configured default (DEFAULT_AGENT_IMAGE) and fallbackAgentImage. It
+// returns the image and the name of the tier that supplied it.
+func (h *Handlers) resolveAgentImage(flagImage, templateName string) (image, source string) {
+	if img := strings.TrimSpace(flagImage); img != "" {
+		return img, imageSourceFlag
+	}
+	if h.templates != nil {
+		if info, err := h.templates.Describe(templateName); err == nil && info.Image != "" {
+			return info.Image, imageSourceTemplate
+		}
+	}
+	if h.defaultAgentImage != "" {
+		return h.defaultAgentImage, imageSourceEnv
+	}
+	return fallbackAgentImage, imageSourceFallback
+}
+
 // HandleAgentsCreate provisions a new agent container.
 //
-// Usage: /ruriko agents create --name <id> --template <tmpl> --image <image>
+// Usage: /ruriko agents create --name <id> --template <tmpl> [--image <image>]
+//
+// When --image is omitted the image comes from the template's metadata.image,
+// then DEFAULT_AGENT_IMAGE, then the built-in default (see resolveAgentImage).
 //
 // When a template registry is available the handler spawns the container
 // synchronously (so a container ID is immediately persisted), then launches
@@ -117,7 +151,7 @@ func (h *Handlers) HandleAgentsCreate(ctx context.Context, cmd *Command, evt *ev
 
 	agentID := cmd.GetFlag("name", "")
 	if agentID == "" {
-		return "", fmt.Errorf("usage: /ruriko agents create --name <id> --template <template> --image <image> [--peer-alias <alias> --peer-mxid <mxid> --peer-room <room-id> --peer-protocol-id <id> --peer-protocol-prefix <prefix>]")
+		return "", fmt.Errorf("usage: /ruriko agents create --name <id> --template <template> [--image <image>] [--peer-alias <alias> --peer-mxid <mxid> --peer-room <room-id> --peer-protocol-id <id> --peer-protocol-prefix <prefix>]")
 	}
 
 	if err := validateAgentID(agentID); err != nil {
@@ -129,10 +163,8 @@ func (h *Handlers) HandleAgentsCreate(ctx context.Context, cmd *Command, evt *ev
 		return "", fmt.Errorf("--template is required")
 	}
 
-	image := cmd.GetFlag("image", "")
-	if image == "" {
-		return "", fmt.Errorf("--image is required")
-	}
+	image, imageSource := h.resolveAgentImage(cmd.GetFlag("image", ""), template)
+	slog.Info("agents.create: resolved agent image", "agent", agentID, "image", image, "source", imageSource)
 
 	displayName := cmd.GetFlag("display-name", agentID)
 
@@ -195,12 +227,12 @@ func (h *Handlers) HandleAgentsCreate(ctx context.Context, cmd *Command, evt *ev
 		h.store.UpdateAgentStatus(ctx, agentID, "stopped")
 		h.store.UpdateAgentProvisioningState(ctx, agentID, "")
 		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.create", agentID, "success",
-			store.AuditPayload{"note": "no runtime configured, agent created as stopped"}, "")
+			store.AuditPayload{"note": "no runtime configured, agent created as stopped", "image": image, "image_source": imageSource}, "")
 		h.notifier.Notify(ctx, audit.Event{
-			Kind: audit.KindAgentCreated, Actor: evt.Sender.String(), Target: agentID,
+			Kind: audit.KindAgentCreated, Severity: audit.SeverityInfo, Actor: evt.Sender.String(), Target: agentID,
 			Message: "created (no runtime; status: stopped)", TraceID: traceID,
 		})
-		return fmt.Sprintf("✅ Agent **%s** created (no runtime configured, status: stopped)\n\n(trace: %s)", agentID, traceID), nil
+		return fmt.Sprintf("✅ Agent **%s** created (no runtime configured, status: stopped)\n\nImage: %s (from %s)\n\n(trace: %s)", agentID, image, imageSource, traceID), nil
 	}
 
 	// --- Matrix account provisioning ------------------------------------
@@ -241,6 +273,9 @@ func (h *Handlers) HandleAgentsCreate(ctx context.Context, cmd *Command, evt *ev
 	if h.matrixHomeserver != "" {
 		agentEnv["MATRIX_HOMESERVER"] = h.matrixHomeserver
 	}
+	if h.secretRefreshURL != "" {
+		agentEnv["RURIKO_SECRET_REFRESH_URL"] = h.secretRefreshURL
+	}
 	if agentMXID != "" {
 		agentEnv["MATRIX_USER_ID"] = agentMXID
 	}
@@ -302,19 +337,21 @@ func (h *Handlers) HandleAgentsCreate(ctx context.Context, cmd *Command, evt *ev
 				"container_id": truncateID(handle.ContainerID, 12),
 				"control_url":  handle.ControlURL,
 				"pipeline":     "async",
+				"image":        image,
+				"image_source": imageSource,
 			}, "")
 
 		return fmt.Sprintf(`⏳ Agent **%s** container spawned — provisioning pipeline started
 
 Template:    %s
-Image:       %s
+Image:       %s (from %s)
 Container:   %s
 Control URL: %s
 
 You will receive breadcrumb updates in this room as each step completes.
 
 (trace: %s)`,
-			agentID, template, image, truncateID(handle.ContainerID, 12), handle.ControlURL, traceID,
+			agentID, template, image, imageSource, truncateID(handle.ContainerID, 12), handle.ControlURL, traceID,
 		), nil
 	}
 
@@ -333,7 +370,7 @@ You will receive breadcrumb updates in this room as each step completes.
 			"note":         "no template registry; gosuto not applied",
 		}, "")
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindAgentCreated, Actor: evt.Sender.String(), Target: agentID,
+		Kind: audit.KindAgentCreated, Severity: audit.SeverityInfo, Actor: evt.Sender.String(), Target: agentID,
 		Message: fmt.Sprintf("created and started (container: %s; no gosuto applied)",
 			truncateID(handle.ContainerID, 12)), TraceID: traceID,
 	})
@@ -341,7 +378,7 @@ You will receive breadcrumb updates in this room as each step completes.
 	return fmt.Sprintf(`✅ Agent **%s** created and started
 
 Template:    %s
-Image:       %s
+Image:       %s (from %s)
 Container:   %s
 Control URL: %s
 
@@ -349,7 +386,7 @@ Control URL: %s
 Use /ruriko gosuto push %s after storing a config.
 
 (trace: %s)`,
-		agentID, template, image, truncateID(handle.ContainerID, 12), handle.ControlURL, agentID, traceID,
+		agentID, template, image, imageSource, truncateID(handle.ContainerID, 12), handle.ControlURL, agentID, traceID,
 	), nil
 }
 
@@ -391,7 +428,7 @@ func (h *Handlers) HandleAgentsStop(ctx context.Context, cmd *Command, evt *even
 		slog.Warn("audit write failed", "op", "agents.stop", "agent", agentID, "err", err)
 	}
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindAgentStopped, Actor: evt.Sender.String(), Target: agentID,
+		Kind: audit.KindAgentStopped, Severity: audit.SeverityInfo, Actor: evt.Sender.String(), Target: agentID,
 		Message: "stopped", TraceID: traceID,
 	})
 
@@ -452,7 +489,7 @@ func (h *Handlers) HandleAgentsStart(ctx context.Context, cmd *Command, evt *eve
 		slog.Warn("audit write failed", "op", "agents.start", "agent", agentID, "err", err)
 	}
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindAgentStarted, Actor: evt.Sender.String(), Target: agentID,
+		Kind: audit.KindAgentStarted, Severity: audit.SeverityInfo, Actor: evt.Sender.String(), Target: agentID,
 		Message: "started", TraceID: traceID,
 	})
 
@@ -512,7 +549,7 @@ func (h *Handlers) HandleAgentsRespawn(ctx context.Context, cmd *Command, evt *e
 		slog.Warn("audit write failed", "op", "agents.respawn", "agent", agentID, "err", err)
 	}
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindAgentRespawned, Actor: evt.Sender.String(), Target: agentID,
+		Kind: audit.KindAgentRespawned, Severity: audit.SeverityWarn, Actor: evt.Sender.String(), Target: agentID,
 		Message: "respawned", TraceID: traceID,
 	})
 
@@ -557,6 +594,9 @@ func (h *Handlers) recoverAgentContainer(ctx context.Context, agent *store.Agent
 	if h.matrixHomeserver != "" {
 		env["MATRIX_HOMESERVER"] = h.matrixHomeserver
 	}
+	if h.secretRefreshURL != "" {
+		env["RURIKO_SECRET_REFRESH_URL"] = h.secretRefreshURL
+	}
 	mxid := strings.TrimSpace(agent.MXID.String)
 	if !agent.MXID.Valid || mxid == "" {
 		return runtime.AgentHandle{}, fmt.Errorf("agent %s has no Matrix identity; run '/ruriko agents matrix register %s' then retry '/ruriko agents start %s'", agent.ID, agent.ID, agent.ID)
@@ -637,7 +677,7 @@ func (h *Handlers) HandleAgentsDelete(ctx context.Context, cmd *Command, evt *ev
 		slog.Warn("audit write failed", "op", "agents.delete", "agent", agentID, "err", err)
 	}
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindAgentDeleted, Actor: evt.Sender.String(), Target: agentID,
+		Kind: audit.KindAgentDeleted, Severity: audit.SeverityWarn, Actor: evt.Sender.String(), Target: agentID,
 		Message: "deleted", TraceID: traceID,
 	})
 
@@ -711,9 +751,12 @@ func (h *Handlers) HandleAgentsStatus(ctx context.Context, cmd *Command, evt *ev
 			statusResp, statusErr := acpClient.Status(statusCtx)
 			statusCancel()
 			if statusErr == nil {
-				if len(statusResp.MCPs) == 0 {
+				switch {
+				case len(statusResp.MCPStatus) > 0:
+					sb.WriteString(fmt.Sprintf("MCPs:         %s\n", formatMCPStatus(statusResp.MCPStatus)))
+				case len(statusResp.MCPs) == 0:
 					sb.WriteString("MCPs:         (none)\n")
-				} else {
+				default:
 					sb.WriteString(fmt.Sprintf("MCPs:         %s\n", strings.Join(statusResp.MCPs, ", ")))
 				}
 				if len(statusResp.Gateways) == 0 {
@@ -721,6 +764,9 @@ func (h *Handlers) HandleAgentsStatus(ctx context.Context, cmd *Command, evt *ev
 				} else {
 					sb.WriteString(fmt.Sprintf("Gateways:     %s\n", strings.Join(statusResp.Gateways, ", ")))
 				}
+				if len(statusResp.StartupFailures) > 0 {
+					sb.WriteString(fmt.Sprintf("Boot:         ⚠️ degraded — %s\n", strings.Join(statusResp.StartupFailures, "; ")))
+				}
 			}
 		}
 	}
@@ -737,6 +783,30 @@ func (h *Handlers) HandleAgentsStatus(ctx context.Context, cmd *Command, evt *ev
 	return sb.String(), nil
 }
 
+// formatMCPStatus renders per-MCP health for agents status, flagging
+// servers that are down with their last exit reason.
+func formatMCPStatus(statuses []acp.MCPServerStatus) string {
+	parts := make([]string, 0, len(statuses))
+	for _, st := range statuses {
+		icon := "✅"
+		switch st.State {
+		case "initializing", "restarting":
+			icon = "⏳"
+		case "crashed":
+			icon = "❌"
+		}
+		detail := st.State
+		if st.State != "running" && st.LastExit != "" {
+			detail += ": " + st.LastExit
+		}
+		if st.Restarts > 0 {
+			detail += fmt.Sprintf(", %d restarts", st.Restarts)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s (%s)", icon, st.Name, detail))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // HandleAgentsCancel cancels the currently in-flight task on a running agent
 // by calling POST /tasks/cancel on the agent's ACP endpoint.
 //
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// defaultLogsLimit is the number of turns shown when --limit is not set.
+const defaultLogsLimit = 20
+
+// HandleAgentsLogs shows an agent's most recent turns: what triggered each
+// one, how it ended, how many tool calls it made and how long it took. The
+// turn history lives in the agent's own database, so it is fetched over ACP.
+// Message texts are not shown.
+//
+// Usage: /ruriko agents logs <agent> [--limit N]
+func (h *Handlers) HandleAgentsLogs(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents logs <agent> [--limit N]")
+	}
+	limit := defaultLogsLimit
+	if raw := cmd.GetFlag("limit", ""); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("--limit must be a positive integer")
+		}
+		limit = n
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.logs", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	resp, err := client.ListTurns(ctx, limit)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.logs", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to fetch turns from agent %q: %w", agentID, err)
+	}
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.logs", agentID, "success",
+		store.AuditPayload{"turns": len(resp.Turns)}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.logs", "err", err)
+	}
+
+	if len(resp.Turns) == 0 {
+		return fmt.Sprintf("**%s** has not run any turns.\n\n(trace: %s)", agentID, traceID), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Recent turns on %s** (latest %d)\n\n", agentID, len(resp.Turns))
+	for _, t := range resp.Turns {
+		sb.WriteString(formatTurnSummary(t))
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n(trace: %s)", traceID)
+	return sb.String(), nil
+}
+
+// formatTurnSummary renders one turn as a list item.
+func formatTurnSummary(t acp.TurnSummary) string {
+	icon := "✅"
+	switch t.Status {
+	case "running":
+		icon = "⏳"
+	case "success":
+	default:
+		icon = "❌"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "- %s %s **%s**", t.StartedAt.UTC().Format("2006-01-02 15:04:05"), icon, t.Status)
+	if t.Trigger == "gateway" {
+		fmt.Fprintf(&sb, " — event `%s/%s`", t.Gateway, t.EventType)
+	} else {
+		fmt.Fprintf(&sb, " — message from %s", t.Sender)
+	}
+	fmt.Fprintf(&sb, " — %d tool calls", t.ToolCalls)
+	if t.DurationMS > 0 {
+		fmt.Fprintf(&sb, " — %dms", t.DurationMS)
+	}
+	if t.Error != "" {
+		fmt.Fprintf(&sb, " — %s", t.Error)
+	}
+	fmt.Fprintf(&sb, " (trace %s)", t.TraceID)
+	return sb.String()
+}
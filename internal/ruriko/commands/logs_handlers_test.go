@@ -0,0 +1,66 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+func TestAgentsLogs_ShowsRecentTurns(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "logbot", validGosutoWithPersonaAndInstructions)
+	var gotQuery string
+	started := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/turns", func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.TurnListResponse{Turns: []acpspec.TurnSummary{
+			{TraceID: "t-3", Trigger: "matrix", Sender: "@bob:example.com", Status: "running", StartedAt: started},
+			{TraceID: "t-2", Trigger: "gateway", Gateway: "cron", EventType: "cron.tick", Status: "error",
+				ToolCalls: 1, DurationMS: 40, Error: "mcp docs not running", StartedAt: started},
+			{TraceID: "t-1", Trigger: "matrix", Sender: "@alice:example.com", Status: "success",
+				ToolCalls: 2, DurationMS: 1200, StartedAt: started},
+		}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if err := s.UpdateAgentHandle(ctx, "logbot", "cid-logbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsLogs(ctx, parseCmd(t, "/ruriko agents logs logbot --limit 3"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsLogs: %v", err)
+	}
+	if gotQuery != "limit=3" {
+		t.Errorf("query = %q, want limit=3", gotQuery)
+	}
+	for _, want := range []string{
+		"**Recent turns on logbot** (latest 3)",
+		"- 2026-10-16 09:30:00 ⏳ **running** — message from @bob:example.com — 0 tool calls (trace t-3)",
+		"- 2026-10-16 09:30:00 ❌ **error** — event `cron/cron.tick` — 1 tool calls — 40ms — mcp docs not running (trace t-2)",
+		"- 2026-10-16 09:30:00 ✅ **success** — message from @alice:example.com — 2 tool calls — 1200ms (trace t-1)",
+	} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("expected %q in response, got:\n%s", want, resp)
+		}
+	}
+}
+
+func TestAgentsLogs_RejectsBadLimit(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "logbot", validGosutoWithPersonaAndInstructions)
+
+	if _, err := h.HandleAgentsLogs(context.Background(), parseCmd(t, "/ruriko agents logs logbot --limit 0"), fakeEvent("@admin:example.com")); err == nil {
+		t.Fatal("expected error for --limit 0")
+	}
+}
@@ -547,10 +547,7 @@ func (h *Handlers) handleKeywordIntent(ctx context.Context, text string, evt *ev
 	}
 
 	// Determine the container image to use.
-	image := h.defaultAgentImage
-	if image == "" {
-		image = "ghcr.io/bdobrica/gitai:latest"
-	}
+	image, _ := h.resolveAgentImage("", intent.TemplateName)
 
 	// Discover required secrets and check which are missing.
 	requiredSecrets, err := h.templates.RequiredSecrets(intent.TemplateName, intent.AgentName)
@@ -112,7 +112,7 @@ func (h *Handlers) runProvisioningPipeline(ctx context.Context, args provisionAr
 		h.store.WriteAudit(ctx, traceID, args.operatorMXID, "agents.provision", agentID, "error",
 			store.AuditPayload{"step": step}, err.Error())
 		h.notifier.Notify(ctx, audit.Event{
-			Kind: audit.KindError, Actor: args.operatorMXID, Target: agentID,
+			Kind: audit.KindError, Severity: audit.SeverityCritical, Actor: args.operatorMXID, Target: agentID,
 			Message: fmt.Sprintf("provisioning failed at %s: %v", step, err), TraceID: traceID,
 		})
 	}
@@ -358,11 +358,12 @@ func (h *Handlers) runProvisioningPipeline(ctx context.Context, args provisionAr
 	h.store.WriteAudit(ctx, traceID, args.operatorMXID, "agents.provision", agentID, "success",
 		store.AuditPayload{"gosuto_hash": hash[:16], "gosuto_version": gv.Version}, "")
 	h.notifier.Notify(ctx, audit.Event{
-		Kind:    audit.KindAgentCreated,
-		Actor:   args.operatorMXID,
-		Target:  agentID,
-		Message: fmt.Sprintf("provisioned and healthy (gosuto v%d, hash %s…)", gv.Version, hash[:8]),
-		TraceID: traceID,
+		Kind:     audit.KindAgentCreated,
+		Severity: audit.SeverityInfo,
+		Actor:    args.operatorMXID,
+		Target:   agentID,
+		Message:  fmt.Sprintf("provisioned and healthy (gosuto v%d, hash %s…)", gv.Version, hash[:8]),
+		TraceID:  traceID,
 	})
 
 	// Begin deterministic ensure-if-missing flow for canonical provisioning.
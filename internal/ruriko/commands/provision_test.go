@@ -96,6 +96,13 @@ type mockACPServer struct {
 	srv         *httptest.Server
 }
 
+// applied returns the hash of the last config applied via /config/apply.
+func (m *mockACPServer) applied() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.appliedHash
+}
+
 func (m *mockACPServer) setGateways(names []string) {
 	m.mu.Lock()
 	m.gateways = names
@@ -270,7 +270,7 @@ func (h *Handlers) HandleAgentsDisable(ctx context.Context, cmd *Command, evt *e
 		slog.Warn("audit write failed", "op", "agents.disable", "err", err)
 	}
 	h.notifier.Notify(ctx, audit.Event{
-		Kind: audit.KindAgentDisabled, Actor: evt.Sender.String(), Target: agentID,
+		Kind: audit.KindAgentDisabled, Severity: audit.SeverityCritical, Actor: evt.Sender.String(), Target: agentID,
 		Message: fmt.Sprintf("disabled (erase=%v)", erase), TraceID: traceID,
 	})
 
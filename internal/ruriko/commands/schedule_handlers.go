@@ -11,19 +11,36 @@ import (
 
 	"github.com/bdobrica/Ruriko/common/trace"
 	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
 )
 
 func (h *Handlers) resolveAgentACPClient(ctx context.Context, agentID string) (*acp.Client, error) {
+	_, client, err := h.resolveAgentACP(ctx, agentID)
+	return client, err
+}
+
+// resolveAgentACP is resolveAgentACPClient for callers that also need the
+// agent's stored record.
+func (h *Handlers) resolveAgentACP(ctx context.Context, agentID string) (*store.Agent, *acp.Client, error) {
 	agentID = strings.TrimSpace(agentID)
 	if agentID == "" {
-		return nil, fmt.Errorf("agent ID must not be empty")
+		return nil, nil, fmt.Errorf("agent ID must not be empty")
 	}
 	agent, err := h.store.GetAgent(ctx, agentID)
 	if err != nil {
-		return nil, fmt.Errorf("agent not found: %s", agentID)
+		return nil, nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	client, err := agentACPClient(agent)
+	if err != nil {
+		return nil, nil, err
 	}
+	return agent, client, nil
+}
+
+// agentACPClient builds an ACP client from an already loaded agent record.
+func agentACPClient(agent *store.Agent) (*acp.Client, error) {
 	if !agent.ControlURL.Valid || strings.TrimSpace(agent.ControlURL.String) == "" {
-		return nil, fmt.Errorf("agent %s has no control URL; is it running?", agentID)
+		return nil, fmt.Errorf("agent %s has no control URL; is it running?", agent.ID)
 	}
 	token := ""
 	if agent.ACPToken.Valid {
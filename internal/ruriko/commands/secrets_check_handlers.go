@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// declaredSecret is a secret an agent's Gosuto refers to, with where it is
+// referenced and whether the agent needs it to start.
+type declaredSecret struct {
+	name     string
+	sources  []string
+	required bool
+}
+
+// gosutoSecretRefs collects the secret names cfg refers to: the secrets
+// list, the persona's LLM API key and webhook HMAC keys. The result is
+// sorted by name.
+func gosutoSecretRefs(cfg *gosuto.Config) []declaredSecret {
+	byName := map[string]*declaredSecret{}
+	add := func(name, source string, required bool) {
+		if name == "" {
+			return
+		}
+		d, ok := byName[name]
+		if !ok {
+			d = &declaredSecret{name: name}
+			byName[name] = d
+		}
+		d.sources = append(d.sources, source)
+		d.required = d.required || required
+	}
+	for _, s := range cfg.Secrets {
+		add(s.Name, "secrets", s.Required)
+	}
+	add(cfg.Persona.APIKeySecretRef, "persona.apiKeySecretRef", true)
+	for _, gw := range cfg.Gateways {
+		add(gw.Config["hmacSecretRef"], "gateways."+gw.Name+".hmacSecretRef", true)
+	}
+
+	out := make([]declaredSecret, 0, len(byName))
+	for _, d := range byName {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// missingRequiredSecrets returns the required secrets gv refers to that are
+// not both stored and bound to agentID.
+func (h *Handlers) missingRequiredSecrets(ctx context.Context, agentID string, gv *store.GosutoVersion) ([]string, error) {
+	var cfg gosuto.Config
+	if err := yaml.Unmarshal([]byte(gv.YAMLBlob), &cfg); err != nil {
+		return nil, fmt.Errorf("parse gosuto v%d for agent %q: %w", gv.Version, agentID, err)
+	}
+	bindings, err := h.secrets.ListBindings(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bindings for agent %q: %w", agentID, err)
+	}
+	bound := make(map[string]bool, len(bindings))
+	for _, b := range bindings {
+		bound[b.SecretName] = true
+	}
+	var missing []string
+	for _, d := range gosutoSecretRefs(&cfg) {
+		if !d.required {
+			continue
+		}
+		if !bound[d.name] {
+			missing = append(missing, d.name)
+			continue
+		}
+		if _, err := h.secrets.GetMetadata(ctx, d.name); err != nil {
+			missing = append(missing, d.name)
+		}
+	}
+	return missing, nil
+}
+
+// HandleAgentsSecretsCheck compares the secrets an agent's latest stored
+// Gosuto refers to against the agent's secret bindings, so drift shows up
+// before the agent trips over it at runtime. It reports secrets that are
+// declared but unbound (and whether they exist in the store at all), and
+// bindings the Gosuto no longer refers to.
+//
+// Usage: /ruriko agents secrets-check <agent>
+func (h *Handlers) HandleAgentsSecretsCheck(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents secrets-check <agent>")
+	}
+	if _, err := h.store.GetAgent(ctx, agentID); err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.secrets-check", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("agent not found: %s", agentID)
+	}
+	gv, err := h.store.GetLatestGosutoVersion(ctx, agentID)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.secrets-check", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("no gosuto config found for agent %q: %w", agentID, err)
+	}
+	var cfg gosuto.Config
+	if err := yaml.Unmarshal([]byte(gv.YAMLBlob), &cfg); err != nil {
+		return "", fmt.Errorf("parse gosuto v%d for agent %q: %w", gv.Version, agentID, err)
+	}
+	bindings, err := h.secrets.ListBindings(ctx, agentID)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.secrets-check", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to list bindings for agent %q: %w", agentID, err)
+	}
+	bound := make(map[string]bool, len(bindings))
+	for _, b := range bindings {
+		bound[b.SecretName] = true
+	}
+
+	declared := gosutoSecretRefs(&cfg)
+	var missing []string
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Secrets check for %s** (Gosuto v%d)\n\n", agentID, gv.Version)
+	for _, d := range declared {
+		refs := strings.Join(d.sources, ", ")
+		if bound[d.name] {
+			fmt.Fprintf(&sb, "- ✅ `%s` — bound (%s)\n", d.name, refs)
+			continue
+		}
+		missing = append(missing, d.name)
+		state := "stored but not bound"
+		hint := fmt.Sprintf("/ruriko secrets bind %s %s", agentID, d.name)
+		if _, err := h.secrets.GetMetadata(ctx, d.name); err != nil {
+			state = "not in the secret store"
+			hint = fmt.Sprintf("/ruriko secrets set %s, then /ruriko secrets bind %s %s", d.name, agentID, d.name)
+		}
+		icon := "⚠️"
+		if d.required {
+			icon = "❌"
+		}
+		fmt.Fprintf(&sb, "- %s `%s` — %s (%s); fix: `%s`\n", icon, d.name, state, refs, hint)
+	}
+
+	declaredSet := make(map[string]bool, len(declared))
+	for _, d := range declared {
+		declaredSet[d.name] = true
+	}
+	var extra []string
+	for _, b := range bindings {
+		if !declaredSet[b.SecretName] {
+			extra = append(extra, b.SecretName)
+			fmt.Fprintf(&sb, "- ➕ `%s` — bound but not referenced by the Gosuto\n", b.SecretName)
+		}
+	}
+	if len(declared) == 0 && len(extra) == 0 {
+		sb.WriteString("The Gosuto declares no secrets and none are bound.\n")
+	}
+
+	switch {
+	case len(missing) > 0:
+		fmt.Fprintf(&sb, "\n%d declared secret(s) are not bound.\n", len(missing))
+	case len(declared) > 0:
+		sb.WriteString("\nAll declared secrets are bound.\n")
+	}
+	if len(extra) > 0 {
+		fmt.Fprintf(&sb, "%d binding(s) are not referenced; unbind them if they are no longer needed.\n", len(extra))
+	}
+
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.secrets-check", agentID, "success",
+		store.AuditPayload{"version": gv.Version, "declared": len(declared), "missing": missing, "extra": extra}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.secrets-check", "err", err)
+	}
+
+	fmt.Fprintf(&sb, "\n(trace: %s)", traceID)
+	return sb.String(), nil
+}
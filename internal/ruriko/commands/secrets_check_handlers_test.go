@@ -0,0 +1,173 @@
+package commands_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/commands"
+	"github.com/bdobrica/Ruriko/internal/ruriko/secrets"
+)
+
+const secretsCheckGosuto = validGosutoWithPersonaAndInstructions + `secrets:
+  - name: kairo.finnhub-api-key
+    envVar: FINNHUB_API_KEY
+    required: true
+  - name: kairo.optional-token
+`
+
+func storeSecret(t *testing.T, sec *secrets.Store, name string) {
+	t.Helper()
+	if err := sec.Set(context.Background(), name, secrets.TypeAPIKey, []byte("value-"+name)); err != nil {
+		t.Fatalf("secrets.Set %q: %v", name, err)
+	}
+}
+
+func bindSecret(t *testing.T, sec *secrets.Store, agentID, name string) {
+	t.Helper()
+	storeSecret(t, sec, name)
+	if err := sec.Bind(context.Background(), agentID, name, "read"); err != nil {
+		t.Fatalf("secrets.Bind %q: %v", name, err)
+	}
+}
+
+func TestAgentsSecretsCheck_AllBound(t *testing.T) {
+	h, s, sec := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "kairo", secretsCheckGosuto)
+	bindSecret(t, sec, "kairo", "kairo.finnhub-api-key")
+	bindSecret(t, sec, "kairo", "kairo.optional-token")
+
+	resp, err := h.HandleAgentsSecretsCheck(context.Background(), parseCmd(t, "/ruriko agents secrets-check kairo"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsSecretsCheck: %v", err)
+	}
+	if !strings.Contains(resp, "All declared secrets are bound") {
+		t.Errorf("expected all-bound summary, got:\n%s", resp)
+	}
+	if strings.Contains(resp, "❌") || strings.Contains(resp, "➕") {
+		t.Errorf("unexpected drift reported:\n%s", resp)
+	}
+}
+
+func TestAgentsSecretsCheck_SomeMissing(t *testing.T) {
+	h, s, sec := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "kairo", secretsCheckGosuto)
+	// Stored but not bound.
+	storeSecret(t, sec, "kairo.finnhub-api-key")
+	// kairo.optional-token is neither stored nor bound.
+
+	resp, err := h.HandleAgentsSecretsCheck(context.Background(), parseCmd(t, "/ruriko agents secrets-check kairo"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsSecretsCheck: %v", err)
+	}
+	for _, want := range []string{
+		"❌ `kairo.finnhub-api-key` — stored but not bound",
+		"/ruriko secrets bind kairo kairo.finnhub-api-key",
+		"⚠️ `kairo.optional-token` — not in the secret store",
+		"2 declared secret(s) are not bound",
+	} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("response missing %q:\n%s", want, resp)
+		}
+	}
+}
+
+func TestAgentsSecretsCheck_ExtraBound(t *testing.T) {
+	h, s, sec := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "kairo", secretsCheckGosuto)
+	bindSecret(t, sec, "kairo", "kairo.finnhub-api-key")
+	bindSecret(t, sec, "kairo", "kairo.optional-token")
+	bindSecret(t, sec, "kairo", "kairo.legacy-key")
+
+	resp, err := h.HandleAgentsSecretsCheck(context.Background(), parseCmd(t, "/ruriko agents secrets-check kairo"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsSecretsCheck: %v", err)
+	}
+	if !strings.Contains(resp, "➕ `kairo.legacy-key` — bound but not referenced") {
+		t.Errorf("expected extra binding to be reported, got:\n%s", resp)
+	}
+	if !strings.Contains(resp, "All declared secrets are bound") {
+		t.Errorf("expected declared secrets to be reported bound, got:\n%s", resp)
+	}
+}
+
+func TestAgentsSecretsCheck_PersonaAndWebhookRefs(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "kairo", strings.Replace(validGosutoWithPersonaAndInstructions,
+		"  model: gpt-4o\n", "  model: gpt-4o\n  apiKeySecretRef: kairo.openai-key\n", 1)+`gateways:
+  - name: hooks
+    type: webhook
+    config:
+      authType: hmac-sha256
+      hmacSecretRef: kairo.webhook-hmac
+`)
+
+	resp, err := h.HandleAgentsSecretsCheck(context.Background(), parseCmd(t, "/ruriko agents secrets-check kairo"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsSecretsCheck: %v", err)
+	}
+	for _, want := range []string{"`kairo.openai-key`", "persona.apiKeySecretRef", "`kairo.webhook-hmac`", "gateways.hooks.hmacSecretRef"} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("response missing %q:\n%s", want, resp)
+		}
+	}
+}
+
+// seedPushTarget seeds kairo with secretsCheckGosuto and a running mock ACP
+// server that records applied config hashes.
+func seedPushTarget(t *testing.T) (*commands.Handlers, *secrets.Store, *mockACPServer) {
+	t.Helper()
+	h, s, sec := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "kairo", secretsCheckGosuto)
+	acp := newMockACPServer()
+	t.Cleanup(acp.srv.Close)
+	if err := s.UpdateAgentHandle(context.Background(), "kairo", "cid-kairo", acp.srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+	return h, sec, acp
+}
+
+func TestGosutoPush_BlockedWhenRequiredSecretMissing(t *testing.T) {
+	h, sec, acp := seedPushTarget(t)
+	// Only the optional secret is bound; the required one is stored but not bound.
+	bindSecret(t, sec, "kairo", "kairo.optional-token")
+	storeSecret(t, sec, "kairo.finnhub-api-key")
+
+	_, err := h.HandleGosutoPush(context.Background(), parseCmd(t, "/ruriko gosuto push kairo"), fakeEvent("@admin:example.com"))
+	if err == nil {
+		t.Fatal("expected push to be refused")
+	}
+	if !strings.Contains(err.Error(), "kairo.finnhub-api-key") || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("error should list the missing secret and mention --force, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "kairo.optional-token") {
+		t.Errorf("optional secrets must not block the push, got: %v", err)
+	}
+	if acp.applied() != "" {
+		t.Error("config was pushed despite the missing secret")
+	}
+}
+
+func TestGosutoPush_ForceOverridesMissingSecret(t *testing.T) {
+	h, _, acp := seedPushTarget(t)
+
+	resp, err := h.HandleGosutoPush(context.Background(), parseCmd(t, "/ruriko gosuto push kairo --force"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleGosutoPush --force: %v", err)
+	}
+	if !strings.Contains(resp, "pushed") || acp.applied() == "" {
+		t.Errorf("expected the config to be pushed, resp:\n%s", resp)
+	}
+}
+
+func TestGosutoPush_ProceedsWhenRequiredSecretsBound(t *testing.T) {
+	h, sec, acp := seedPushTarget(t)
+	bindSecret(t, sec, "kairo", "kairo.finnhub-api-key")
+
+	if _, err := h.HandleGosutoPush(context.Background(), parseCmd(t, "/ruriko gosuto push kairo"), fakeEvent("@admin:example.com")); err != nil {
+		t.Fatalf("HandleGosutoPush: %v", err)
+	}
+	if acp.applied() == "" {
+		t.Error("expected the config to be pushed")
+	}
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// maxTokenOverlap mirrors the agent-side cap on the rotation overlap window.
+const maxTokenOverlap = 24 * time.Hour
+
+// HandleAgentsRotateToken rolls an agent's ACP bearer token without
+// downtime. It pushes a freshly generated token to the agent (authenticated
+// with the current one), then stores it. The agent keeps accepting the old
+// token until the overlap window ends, so requests already in flight with
+// it still succeed; after that only the new token works.
+//
+// Usage: /ruriko agents rotate-token <agent> [--overlap 10m]
+func (h *Handlers) HandleAgentsRotateToken(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents rotate-token <agent> [--overlap 10m]")
+	}
+	var overlap time.Duration
+	if raw := cmd.GetFlag("overlap", ""); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < time.Second || d > maxTokenOverlap {
+			return "", fmt.Errorf("--overlap must be a duration between 1s and %s (e.g. 10m)", maxTokenOverlap)
+		}
+		overlap = d
+	}
+
+	agent, client, err := h.resolveAgentACP(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.rotate-token", agentID, "error", nil, err.Error())
+		return "", err
+	}
+	if !agent.ACPToken.Valid || agent.ACPToken.String == "" {
+		return "", fmt.Errorf("agent %q has no ACP token; authentication is disabled", agentID)
+	}
+
+	newToken, err := generateACPToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ACP token: %w", err)
+	}
+	resp, err := client.RotateToken(ctx, acp.TokenRotateRequest{
+		Token:          newToken,
+		OverlapSeconds: int(overlap / time.Second),
+	})
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.rotate-token", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to rotate token on agent %q: %w", agentID, err)
+	}
+	retiresAt := resp.RetiresAt.UTC().Format(time.RFC3339)
+
+	if err := h.store.SetAgentACPToken(ctx, agentID, newToken); err != nil {
+		// The agent already switched. Ruriko's stored token keeps working
+		// only until the overlap ends, so the operator must act before then.
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.rotate-token", agentID, "error",
+			store.AuditPayload{"retires_at": retiresAt}, err.Error())
+		return "", fmt.Errorf("agent %q switched tokens but storing the new one failed (old token retires at %s): %w", agentID, retiresAt, err)
+	}
+
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.rotate-token", agentID, "success",
+		store.AuditPayload{"retires_at": retiresAt}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.rotate-token", "err", err)
+	}
+
+	return fmt.Sprintf("🔑 Rotated the ACP token for **%s**. The previous token is accepted until %s.\n\n(trace: %s)",
+		agentID, retiresAt, traceID), nil
+}
@@ -0,0 +1,91 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+func TestAgentsRotateToken_PushesAndStoresNewToken(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "rotbot", validGosutoWithPersonaAndInstructions)
+	if err := s.SetAgentACPToken(ctx, "rotbot", "old-token-0123456789"); err != nil {
+		t.Fatalf("SetAgentACPToken: %v", err)
+	}
+
+	var gotAuth string
+	var gotReq acpspec.TokenRotateRequest
+	retires := time.Date(2026, 3, 1, 12, 10, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token/rotate", func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.TokenRotateResponse{RetiresAt: retires})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if err := s.UpdateAgentHandle(ctx, "rotbot", "cid-rotbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsRotateToken(ctx, parseCmd(t, "/ruriko agents rotate-token rotbot --overlap 10m"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsRotateToken: %v", err)
+	}
+	if gotAuth != "Bearer old-token-0123456789" {
+		t.Errorf("Authorization = %q, want the old token", gotAuth)
+	}
+	if gotReq.OverlapSeconds != 600 || gotReq.Token == "" || gotReq.Token == "old-token-0123456789" {
+		t.Errorf("unexpected rotate request: %+v", gotReq)
+	}
+	agent, err := s.GetAgent(ctx, "rotbot")
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if agent.ACPToken.String != gotReq.Token {
+		t.Errorf("stored token = %q, want the pushed token %q", agent.ACPToken.String, gotReq.Token)
+	}
+	if !strings.Contains(resp, "2026-03-01T12:10:00Z") {
+		t.Errorf("expected retirement time in response, got:\n%s", resp)
+	}
+	if strings.Contains(resp, gotReq.Token) {
+		t.Errorf("response must not echo the new token, got:\n%s", resp)
+	}
+}
+
+func TestAgentsRotateToken_AgentFailureKeepsStoredToken(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "rotbot", validGosutoWithPersonaAndInstructions)
+	if err := s.SetAgentACPToken(ctx, "rotbot", "old-token-0123456789"); err != nil {
+		t.Fatalf("SetAgentACPToken: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid bearer token"}`, http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+	if err := s.UpdateAgentHandle(ctx, "rotbot", "cid-rotbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	if _, err := h.HandleAgentsRotateToken(ctx, parseCmd(t, "/ruriko agents rotate-token rotbot"), fakeEvent("@admin:example.com")); err == nil {
+		t.Fatal("expected error when the agent rejects the rotation")
+	}
+	agent, err := s.GetAgent(ctx, "rotbot")
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if agent.ACPToken.String != "old-token-0123456789" {
+		t.Errorf("stored token changed to %q after a failed rotation", agent.ACPToken.String)
+	}
+}
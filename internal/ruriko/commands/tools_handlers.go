@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// HandleAgentsTools lists the tools an agent currently offers to its LLM,
+// grouped by source MCP server, so operators can see the exact (mcp, tool)
+// pairs their capability rules need to match. With --schemas each tool's
+// input schema is printed as well.
+//
+// Usage: /ruriko agents tools <agent> [--schemas]
+func (h *Handlers) HandleAgentsTools(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents tools <agent> [--schemas]")
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.tools", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	withSchemas := cmd.HasFlag("schemas")
+	resp, err := client.ListTools(ctx, withSchemas)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.tools", agentID, "error", nil, err.Error())
+		return "", fmt.Errorf("failed to fetch tools from agent %q: %w", agentID, err)
+	}
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.tools", agentID, "success",
+		store.AuditPayload{"tools": len(resp.Tools)}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.tools", "err", err)
+	}
+
+	if len(resp.Tools) == 0 {
+		return fmt.Sprintf("**%s** offers no tools.\n\n(trace: %s)", agentID, traceID), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Tools on %s** (%d)\n", agentID, len(resp.Tools))
+	lastMCP := ""
+	for _, t := range resp.Tools {
+		if t.MCP != lastMCP {
+			fmt.Fprintf(&sb, "\n**mcp: %s**\n", t.MCP)
+			lastMCP = t.MCP
+		}
+		fmt.Fprintf(&sb, "- `%s`", t.Tool)
+		if t.Description != "" {
+			fmt.Fprintf(&sb, " — %s", firstLine(t.Description))
+		}
+		sb.WriteString("\n")
+		if withSchemas && t.InputSchema != nil {
+			if raw, err := json.Marshal(t.InputSchema); err == nil {
+				fmt.Fprintf(&sb, "  `%s`\n", raw)
+			}
+		}
+	}
+	fmt.Fprintf(&sb, "\n(trace: %s)", traceID)
+	return sb.String(), nil
+}
+
+// firstLine returns s up to its first newline, trimmed.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,71 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+func TestAgentsTools_ListsToolsByMCP(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "toolbot", validGosutoWithPersonaAndInstructions)
+	var gotQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tools", func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.ToolListResponse{Tools: []acpspec.ToolInfo{
+			{Name: "docs__search", MCP: "docs", Tool: "search", Description: "Search the index.\nMore detail.",
+				InputSchema: map[string]interface{}{"type": "object"}},
+			{Name: "matrix.send_message", MCP: "builtin", Tool: "matrix.send_message", Description: "Send a message."},
+		}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if err := s.UpdateAgentHandle(ctx, "toolbot", "cid-toolbot", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsTools(ctx, parseCmd(t, "/ruriko agents tools toolbot"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsTools: %v", err)
+	}
+	for _, want := range []string{"(2)", "**mcp: docs**", "`search` — Search the index.", "**mcp: builtin**", "`matrix.send_message`"} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("expected %q in response, got:\n%s", want, resp)
+		}
+	}
+	if strings.Contains(resp, "More detail") || strings.Contains(resp, `"type"`) {
+		t.Errorf("expected first description line only and no schemas, got:\n%s", resp)
+	}
+	if gotQuery != "" {
+		t.Errorf("query = %q, want none without --schemas", gotQuery)
+	}
+
+	resp, err = h.HandleAgentsTools(ctx, parseCmd(t, "/ruriko agents tools toolbot --schemas"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsTools --schemas: %v", err)
+	}
+	if gotQuery != "schemas=true" {
+		t.Errorf("query = %q, want schemas=true", gotQuery)
+	}
+	if !strings.Contains(resp, `{"type":"object"}`) {
+		t.Errorf("expected input schema in response, got:\n%s", resp)
+	}
+}
+
+func TestAgentsTools_NoControlURL(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "idletools", validGosutoWithPersonaAndInstructions)
+
+	if _, err := h.HandleAgentsTools(context.Background(), parseCmd(t, "/ruriko agents tools idletools"), fakeEvent("@admin:example.com")); err == nil {
+		t.Fatal("expected error for agent without control URL")
+	}
+}
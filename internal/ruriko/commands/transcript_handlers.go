@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"maunium.net/go/mautrix/event"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// maxTranscriptMessageLen caps each message shown by agents transcript, so a
+// long system prompt or tool result cannot blow past Matrix message limits.
+const maxTranscriptMessageLen = 2000
+
+// HandleAgentsTranscript shows the message history an agent sent its LLM
+// during one turn: system prompt, user message, assistant replies and tool
+// results. Agents record transcripts only when FEATURE_TURN_TRANSCRIPTS is
+// set, and redact secret values before storing them.
+//
+// Usage: /ruriko agents transcript <agent> <trace>
+func (h *Handlers) HandleAgentsTranscript(ctx context.Context, cmd *Command, evt *event.Event) (string, error) {
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+
+	agentID, ok := cmd.GetArg(0)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents transcript <agent> <trace>")
+	}
+	turnTrace, ok := cmd.GetArg(1)
+	if !ok {
+		return "", fmt.Errorf("usage: /ruriko agents transcript <agent> <trace>")
+	}
+
+	client, err := h.resolveAgentACPClient(ctx, agentID)
+	if err != nil {
+		_ = h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.transcript", agentID, "error", nil, err.Error())
+		return "", err
+	}
+
+	resp, err := client.TurnTranscript(ctx, turnTrace)
+	if err != nil {
+		h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.transcript", agentID, "error",
+			store.AuditPayload{"turn_trace": turnTrace}, err.Error())
+		return "", fmt.Errorf("failed to fetch transcript from agent %q: %w", agentID, err)
+	}
+	if err := h.store.WriteAudit(ctx, traceID, evt.Sender.String(), "agents.transcript", agentID, "success",
+		store.AuditPayload{"turn_trace": turnTrace, "messages": len(resp.Messages)}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "agents.transcript", "err", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Transcript of %s on %s** (%d messages, %s)\n\n",
+		resp.TraceID, agentID, len(resp.Messages), resp.At.UTC().Format("2006-01-02 15:04:05"))
+	for i, m := range resp.Messages {
+		fmt.Fprintf(&sb, "%d. **%s**", i+1, m.Role)
+		if m.Name != "" {
+			fmt.Fprintf(&sb, " `%s`", m.Name)
+		}
+		if m.Content != "" {
+			fmt.Fprintf(&sb, ": %s", truncateTranscript(m.Content))
+		}
+		for _, tc := range m.ToolCalls {
+			fmt.Fprintf(&sb, "\n   → `%s` %s", tc.Name, truncateTranscript(tc.Arguments))
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n(trace: %s)", traceID)
+	return sb.String(), nil
+}
+
+// truncateTranscript shortens s to maxTranscriptMessageLen bytes without
+// splitting a UTF-8 sequence.
+func truncateTranscript(s string) string {
+	if len(s) <= maxTranscriptMessageLen {
+		return s
+	}
+	n := maxTranscriptMessageLen
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,77 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+func TestAgentsTranscript_ShowsTurnMessages(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	ctx := context.Background()
+
+	seedAgentWithGosuto(t, s, "scribe", validGosutoWithPersonaAndInstructions)
+	var gotPath string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/turns/{trace}/transcript", func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(acpspec.TurnTranscriptResponse{
+			TraceID: r.PathValue("trace"),
+			At:      time.Now(),
+			Messages: []acpspec.TranscriptMessage{
+				{Role: "system", Content: "You are a scribe."},
+				{Role: "user", Content: "token is [REDACTED]"},
+				{Role: "assistant", ToolCalls: []acpspec.TranscriptToolCall{{ID: "c1", Name: "docs__search", Arguments: `{"q":"x"}`}}},
+				{Role: "tool", Name: "docs__search", ToolCallID: "c1", Content: "no results"},
+				{Role: "assistant", Content: "Nothing found."},
+				{Role: "tool", Name: "docs__fetch", ToolCallID: "c2", Content: "x" + strings.Repeat("é", 2000)},
+			},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if err := s.UpdateAgentHandle(ctx, "scribe", "cid-scribe", srv.URL, "gitai:test"); err != nil {
+		t.Fatalf("UpdateAgentHandle: %v", err)
+	}
+
+	resp, err := h.HandleAgentsTranscript(ctx, parseCmd(t, "/ruriko agents transcript scribe t-42"), fakeEvent("@admin:example.com"))
+	if err != nil {
+		t.Fatalf("HandleAgentsTranscript: %v", err)
+	}
+	if gotPath != "/turns/t-42/transcript" {
+		t.Errorf("path = %q, want /turns/t-42/transcript", gotPath)
+	}
+	for _, want := range []string{
+		"**Transcript of t-42 on scribe** (6 messages",
+		"1. **system**: You are a scribe.",
+		"2. **user**: token is [REDACTED]",
+		"→ `docs__search` {\"q\":\"x\"}",
+		"4. **tool** `docs__search`: no results",
+		"5. **assistant**: Nothing found.",
+	} {
+		if !strings.Contains(resp, want) {
+			t.Errorf("expected %q in response, got:\n%s", want, resp)
+		}
+	}
+	// The long tool result is cut on a rune boundary.
+	if !utf8.ValidString(resp) || !strings.Contains(resp, "é…") {
+		t.Errorf("expected the long message truncated to valid UTF-8, got:\n%s", resp)
+	}
+}
+
+func TestAgentsTranscript_RequiresTrace(t *testing.T) {
+	h, s, _ := newHandlerFixture(t)
+	seedAgentWithGosuto(t, s, "scribe", validGosutoWithPersonaAndInstructions)
+
+	if _, err := h.HandleAgentsTranscript(context.Background(), parseCmd(t, "/ruriko agents transcript scribe"), fakeEvent("@admin:example.com")); err == nil {
+		t.Fatal("expected usage error without a trace ID")
+	}
+}
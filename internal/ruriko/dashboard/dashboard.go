@@ -0,0 +1,323 @@
+// Package dashboard serves a read-only HTML view of Ruriko's state for
+// operators without Matrix access.
+//
+// Routes (all GET, all behind the dashboard token):
+//
+//	/ui/           agents and their state
+//	/ui/audit      recent audit events
+//	/ui/approvals  pending approvals
+//	/ui/kuze       outstanding Kuze tokens
+//
+// The token is accepted as "Authorization: Bearer <token>" or as the
+// password of HTTP basic auth (any user name), so a browser can log in from
+// its own prompt. No page shows secret values, tokens or approval
+// parameters.
+package dashboard
+
+import (
+	"context"
+	"crypto/subtle"
+	"embed"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/approvals"
+	"github.com/bdobrica/Ruriko/internal/ruriko/kuze"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// auditLimit is the number of audit events shown on /ui/audit.
+const auditLimit = 100
+
+// timeLayout formats every timestamp on the dashboard.
+const timeLayout = "2006-01-02 15:04:05 UTC"
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var pages = map[string]*template.Template{
+	"agents":    mustParse("agents"),
+	"audit":     mustParse("audit"),
+	"approvals": mustParse("approvals"),
+	"kuze":      mustParse("kuze"),
+}
+
+func mustParse(page string) *template.Template {
+	t, err := template.ParseFS(templateFS, "templates/layout.html", "templates/"+page+".html")
+	if err != nil {
+		panic("dashboard: parse template " + page + ": " + err.Error())
+	}
+	return t
+}
+
+// Store is the subset of *store.Store the dashboard reads.
+type Store interface {
+	ListAgents(ctx context.Context) ([]*store.Agent, error)
+	GetAuditLog(ctx context.Context, limit int) ([]*store.AuditEntry, error)
+}
+
+// ApprovalLister lists approvals by status. *approvals.Store satisfies it.
+type ApprovalLister interface {
+	List(ctx context.Context, status string) ([]*approvals.Approval, error)
+}
+
+// TokenLister reports outstanding Kuze tokens. *kuze.Server satisfies it.
+type TokenLister interface {
+	TokenStatuses(ctx context.Context) ([]kuze.TokenStatus, error)
+}
+
+// Config holds options for creating a Server.
+type Config struct {
+	// Token is the credential operators present. The routes are not
+	// mounted when it is empty.
+	Token string
+
+	// Approvals, when non-nil, backs /ui/approvals.
+	Approvals ApprovalLister
+
+	// Kuze, when non-nil, backs /ui/kuze; without it the page reports that
+	// Kuze is not configured.
+	Kuze TokenLister
+}
+
+// Server renders the dashboard pages.
+type Server struct {
+	store     Store
+	token     string
+	approvals ApprovalLister
+	kuze      TokenLister
+	now       func() time.Time
+}
+
+// New creates a dashboard Server.
+func New(st Store, cfg Config) *Server {
+	return &Server{
+		store:     st,
+		token:     cfg.Token,
+		approvals: cfg.Approvals,
+		kuze:      cfg.Kuze,
+		now:       time.Now,
+	}
+}
+
+// RouteRegistrar is satisfied by *http.ServeMux and by app.HealthServer's
+// Handle method.
+type RouteRegistrar interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// RegisterRoutes mounts the dashboard pages on r. Nothing is mounted when
+// no token is configured.
+func (s *Server) RegisterRoutes(r RouteRegistrar) {
+	if s.token == "" {
+		return
+	}
+	r.Handle("/ui/", s.protect(s.handleAgents))
+	r.Handle("/ui/audit", s.protect(s.handleAudit))
+	r.Handle("/ui/approvals", s.protect(s.handleApprovals))
+	r.Handle("/ui/kuze", s.protect(s.handleKuze))
+}
+
+// protect rejects requests without the dashboard token and anything but GET.
+func (s *Server) protect(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Ruriko", charset="UTF-8"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h(w, r)
+	})
+}
+
+// authorized reports whether r carries the dashboard token as a bearer token
+// or a basic-auth password.
+func (s *Server) authorized(r *http.Request) bool {
+	presented := ""
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		presented = auth[len("Bearer "):]
+	} else if _, pass, ok := r.BasicAuth(); ok {
+		presented = pass
+	}
+	return presented != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(s.token)) == 1
+}
+
+// pageData is passed to every page template. Only the fields of the page
+// being rendered are set.
+type pageData struct {
+	Page  string
+	Title string
+
+	Agents      []agentRow
+	Audit       []auditRow
+	Approvals   []approvalRow
+	Tokens      []tokenRow
+	KuzeEnabled bool
+}
+
+type agentRow struct {
+	ID                string
+	DisplayName       string
+	Template          string
+	Status            string
+	ProvisioningState string
+	Enabled           bool
+	LastSeen          string
+	GosutoVersion     string
+	Drift             bool
+}
+
+type auditRow struct {
+	Time    string
+	Actor   string
+	Action  string
+	Target  string
+	Result  string
+	Error   string
+	TraceID string
+}
+
+type approvalRow struct {
+	ID        string
+	Action    string
+	Target    string
+	Requestor string
+	Created   string
+	Expires   string
+}
+
+type tokenRow struct {
+	SecretRef  string
+	SecretType string
+	AgentID    string
+	Purpose    string
+	Created    string
+	Expires    string
+	Expired    bool
+}
+
+func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/ui/" {
+		http.NotFound(w, r)
+		return
+	}
+	agents, err := s.store.ListAgents(r.Context())
+	if err != nil {
+		s.fail(w, "list agents", err)
+		return
+	}
+	data := pageData{Page: "agents", Title: "Agents"}
+	for _, a := range agents {
+		row := agentRow{
+			ID:                a.ID,
+			DisplayName:       a.DisplayName,
+			Template:          a.Template,
+			Status:            a.Status,
+			ProvisioningState: a.ProvisioningState,
+			Enabled:           a.Enabled,
+			LastSeen:          "never",
+			GosutoVersion:     "-",
+			Drift:             a.DesiredGosutoHash.Valid && a.DesiredGosutoHash.String != a.ActualGosutoHash.String,
+		}
+		if a.LastSeen.Valid {
+			row.LastSeen = formatTime(a.LastSeen.Time)
+		}
+		if a.GosutoVersion.Valid {
+			row.GosutoVersion = "v" + strconv.FormatInt(a.GosutoVersion.Int64, 10)
+		}
+		data.Agents = append(data.Agents, row)
+	}
+	s.render(w, data)
+}
+
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.store.GetAuditLog(r.Context(), auditLimit)
+	if err != nil {
+		s.fail(w, "read audit log", err)
+		return
+	}
+	data := pageData{Page: "audit", Title: "Recent audit events"}
+	for _, e := range entries {
+		data.Audit = append(data.Audit, auditRow{
+			Time:    formatTime(e.Timestamp),
+			Actor:   e.ActorMXID,
+			Action:  e.Action,
+			Target:  e.Target.String,
+			Result:  e.Result,
+			Error:   e.ErrorMessage.String,
+			TraceID: e.TraceID,
+		})
+	}
+	s.render(w, data)
+}
+
+func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
+	data := pageData{Page: "approvals", Title: "Pending approvals"}
+	if s.approvals != nil {
+		pending, err := s.approvals.List(r.Context(), string(approvals.StatusPending))
+		if err != nil {
+			s.fail(w, "list approvals", err)
+			return
+		}
+		for _, a := range pending {
+			data.Approvals = append(data.Approvals, approvalRow{
+				ID:        a.ID,
+				Action:    a.Action,
+				Target:    a.Target,
+				Requestor: a.RequestorMXID,
+				Created:   formatTime(a.CreatedAt),
+				Expires:   formatTime(a.ExpiresAt),
+			})
+		}
+	}
+	s.render(w, data)
+}
+
+func (s *Server) handleKuze(w http.ResponseWriter, r *http.Request) {
+	data := pageData{Page: "kuze", Title: "Kuze tokens", KuzeEnabled: s.kuze != nil}
+	if s.kuze != nil {
+		tokens, err := s.kuze.TokenStatuses(r.Context())
+		if err != nil {
+			s.fail(w, "list kuze tokens", err)
+			return
+		}
+		now := s.now()
+		for _, t := range tokens {
+			data.Tokens = append(data.Tokens, tokenRow{
+				SecretRef:  t.SecretRef,
+				SecretType: t.SecretType,
+				AgentID:    t.AgentID,
+				Purpose:    t.Purpose,
+				Created:    formatTime(t.CreatedAt),
+				Expires:    formatTime(t.ExpiresAt),
+				Expired:    t.Expired(now),
+			})
+		}
+	}
+	s.render(w, data)
+}
+
+func (s *Server) render(w http.ResponseWriter, data pageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := pages[data.Page].Execute(w, data); err != nil {
+		slog.Error("dashboard: render page", "page", data.Page, "err", err)
+	}
+}
+
+func (s *Server) fail(w http.ResponseWriter, op string, err error) {
+	slog.Error("dashboard: "+op, "err", err)
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
@@ -0,0 +1,139 @@
+package dashboard_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/approvals"
+	"github.com/bdobrica/Ruriko/internal/ruriko/dashboard"
+	"github.com/bdobrica/Ruriko/internal/ruriko/kuze"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+const dashToken = "dash-token-123"
+
+// newDashboard seeds a store with an agent, an audit event, a pending
+// approval and a Kuze token, and serves the dashboard over it. It returns
+// the server and the Kuze token value, which no page may show.
+func newDashboard(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	st, err := store.New(filepath.Join(t.TempDir(), "ruriko.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	ctx := context.Background()
+
+	if err := st.CreateAgent(ctx, &store.Agent{
+		ID: "saito", DisplayName: "Saito", Template: "saito-agent", Status: "running", Enabled: true,
+	}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	if err := st.WriteAudit(ctx, "trace-1", "@admin:example.com", "agents.create", "saito", "success", nil, ""); err != nil {
+		t.Fatalf("WriteAudit: %v", err)
+	}
+	approvalsStore := approvals.NewStore(st.DB())
+	if _, err := approvalsStore.Create(ctx, "agents.delete", "saito", `{"args":["saito"]}`, "@admin:example.com", time.Hour); err != nil {
+		t.Fatalf("approvals Create: %v", err)
+	}
+	kuzeServer := kuze.New(st.DB(), nil, kuze.Config{BaseURL: "https://ruriko.example.com"})
+	issued, err := kuzeServer.IssueHumanToken(ctx, "openai.api_key", "api_key")
+	if err != nil {
+		t.Fatalf("IssueHumanToken: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	dashboard.New(st, dashboard.Config{
+		Token:     dashToken,
+		Approvals: approvalsStore,
+		Kuze:      kuzeServer,
+	}).RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts, issued.Token
+}
+
+func get(t *testing.T, url string, setAuth func(*http.Request)) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if setAuth != nil {
+		setAuth(req)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func bearer(req *http.Request) { req.Header.Set("Authorization", "Bearer "+dashToken) }
+
+func TestDashboard_PagesRenderSeededData(t *testing.T) {
+	ts, kuzeToken := newDashboard(t)
+
+	cases := map[string][]string{
+		"/ui/":          {"saito", "saito-agent", "running"},
+		"/ui/audit":     {"agents.create", "@admin:example.com", "trace-1"},
+		"/ui/approvals": {"agents.delete", "saito", "@admin:example.com"},
+		"/ui/kuze":      {"openai.api_key", "human link", "pending"},
+	}
+	for path, wants := range cases {
+		code, body := get(t, ts.URL+path, bearer)
+		if code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", path, code)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: expected %q in page", path, want)
+			}
+		}
+		if strings.Contains(body, kuzeToken) {
+			t.Errorf("%s: page leaks the Kuze token value", path)
+		}
+	}
+
+	// Approval parameters are never rendered.
+	if _, body := get(t, ts.URL+"/ui/approvals", bearer); strings.Contains(body, `"args"`) {
+		t.Error("approvals page shows approval parameters")
+	}
+}
+
+func TestDashboard_RequiresToken(t *testing.T) {
+	ts, _ := newDashboard(t)
+
+	for _, path := range []string{"/ui/", "/ui/audit", "/ui/approvals", "/ui/kuze"} {
+		code, _ := get(t, ts.URL+path, nil)
+		if code != http.StatusUnauthorized {
+			t.Errorf("%s without token: status = %d, want 401", path, code)
+		}
+		code, _ = get(t, ts.URL+path, func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") })
+		if code != http.StatusUnauthorized {
+			t.Errorf("%s with wrong token: status = %d, want 401", path, code)
+		}
+	}
+
+	code, _ := get(t, ts.URL+"/ui/", func(r *http.Request) { r.SetBasicAuth("operator", dashToken) })
+	if code != http.StatusOK {
+		t.Errorf("basic auth with token as password: status = %d, want 200", code)
+	}
+}
+
+func TestDashboard_NotMountedWithoutToken(t *testing.T) {
+	mux := http.NewServeMux()
+	dashboard.New(nil, dashboard.Config{}).RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	if code, _ := get(t, ts.URL+"/ui/", bearer); code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 when no token is configured", code)
+	}
+}
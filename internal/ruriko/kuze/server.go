@@ -154,6 +154,12 @@ func (srv *Server) PruneExpiredWithNotify(ctx context.Context) error {
 	return srv.tokens.PruneExpired(ctx)
 }
 
+// TokenStatuses returns the outstanding (unredeemed) tokens, newest first,
+// without their values.
+func (srv *Server) TokenStatuses(ctx context.Context) ([]TokenStatus, error) {
+	return srv.tokens.ListUnused(ctx)
+}
+
 // New creates a new Kuze Server.
 //
 //   - db must be the same *sql.DB used by the Ruriko store (so that the
@@ -315,7 +321,6 @@ func (srv *Server) handleIssueAgent(w http.ResponseWriter, r *http.Request) {
 	slog.Info("kuze: issued agent redemption token",
 		"agent", result.AgentID,
 		"ref", result.SecretRef,
-		"token_prefix", safePrefix(result.Token, 8),
 		"expires_at", result.ExpiresAt,
 	)
 
@@ -379,7 +384,6 @@ func (srv *Server) handleRedeem(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, ErrAgentIDMismatch):
 			slog.Warn("kuze: agent identity mismatch on redeem",
 				"claimed_agent", claimedAgentID,
-				"token_prefix", safePrefix(token, 8),
 			)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusForbidden)
@@ -410,7 +414,6 @@ func (srv *Server) handleRedeem(w http.ResponseWriter, r *http.Request) {
 	slog.Info("kuze: secret redeemed by agent",
 		"agent", claimedAgentID,
 		"ref", pt.SecretRef,
-		"token_prefix", safePrefix(token, 8),
 	)
 
 	w.Header().Set("Content-Type", "application/json")
@@ -480,7 +483,7 @@ func (srv *Server) acceptSecret(w http.ResponseWriter, r *http.Request, token st
 		// Non-fatal: secret is already stored.  Log and continue so the user
 		// sees the success page rather than an error.
 		slog.Warn("kuze: burn token after successful store",
-			"token_prefix", safePrefix(token, 8), "err", err)
+			"ref", pt.SecretRef, "err", err)
 	}
 
 	slog.Info("kuze: secret stored via one-time form", "ref", pt.SecretRef)
@@ -507,10 +510,3 @@ func (srv *Server) handleTokenError(w http.ResponseWriter, err error, op string)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 	}
 }
-
-func safePrefix(s string, n int) string {
-	if len(s) <= n {
-		return s
-	}
-	return s[:n]
-}
@@ -1,11 +1,13 @@
 package kuze_test
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -698,3 +700,39 @@ func TestKuze_HumanTokenCanNotBeRedeemed(t *testing.T) {
 		t.Fatalf("human token redeemed via agent endpoint: expected 403, got %d", w.Code)
 	}
 }
+
+// TestKuze_RedeemLogsRefNotToken verifies that the redeem log lines name the
+// secret ref and never include the one-time token.
+func TestKuze_RedeemLogsRefNotToken(t *testing.T) {
+	var logs bytes.Buffer
+	orig := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(orig) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	srv, ss, _ := newTestServer(t, time.Minute)
+	ctx := context.Background()
+	_ = ss.Set(ctx, "finnhub_key", secrets.TypeAPIKey, []byte("sk-test-value"))
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux)
+
+	var tokens []string
+	for _, agent := range []string{"kairo", "intruder"} {
+		res, err := srv.IssueAgentToken(ctx, "kairo", "finnhub_key", "api_key", "")
+		if err != nil {
+			t.Fatalf("IssueAgentToken: %v", err)
+		}
+		tokens = append(tokens, res.Token)
+		req := httptest.NewRequest(http.MethodGet, "/kuze/redeem/"+res.Token, nil)
+		req.Header.Set("X-Agent-ID", agent)
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if !strings.Contains(logs.String(), "ref=finnhub_key") {
+		t.Errorf("logs do not name the ref:\n%s", logs.String())
+	}
+	for _, tok := range tokens {
+		if strings.Contains(logs.String(), tok[:8]) {
+			t.Errorf("logs contain token %q:\n%s", tok, logs.String())
+		}
+	}
+}
@@ -299,6 +299,56 @@ WHERE used = 0 AND expires_at < ?
 	return result, rows.Err()
 }
 
+// TokenStatus describes an unredeemed token without its value, for
+// read-only status views.
+type TokenStatus struct {
+	SecretRef  string
+	SecretType string
+	AgentID    string
+	Purpose    string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// Expired reports whether the token's TTL had elapsed at now.
+func (t TokenStatus) Expired(now time.Time) bool {
+	return now.After(t.ExpiresAt)
+}
+
+// ListUnused returns the status of every token not yet redeemed, newest
+// first. Token values are deliberately not loaded.
+func (s *TokenStore) ListUnused(ctx context.Context) ([]TokenStatus, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT secret_ref, secret_type, created_at, expires_at, agent_id, purpose
+FROM kuze_tokens
+WHERE used = 0
+ORDER BY created_at DESC
+`)
+	if err != nil {
+		return nil, fmt.Errorf("kuze: query unused tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var result []TokenStatus
+	for rows.Next() {
+		var ts TokenStatus
+		var createdStr, expiresStr string
+		var agentIDNull, purposeNull sql.NullString
+		if err := rows.Scan(
+			&ts.SecretRef, &ts.SecretType, &createdStr, &expiresStr,
+			&agentIDNull, &purposeNull,
+		); err != nil {
+			return nil, fmt.Errorf("kuze: scan unused token row: %w", err)
+		}
+		ts.CreatedAt, _ = time.Parse(time.RFC3339, createdStr)
+		ts.ExpiresAt, _ = time.Parse(time.RFC3339, expiresStr)
+		ts.AgentID = agentIDNull.String
+		ts.Purpose = purposeNull.String
+		result = append(result, ts)
+	}
+	return result, rows.Err()
+}
+
 // PruneExpired deletes tokens that have expired or have already been used.
 // Intended to be called periodically (e.g. from a background goroutine).
 func (s *TokenStore) PruneExpired(ctx context.Context) error {
@@ -81,55 +81,66 @@ func (p *openAIProvider) Classify(ctx context.Context, req ClassifyRequest) (*Cl
 	}
 	msgs = append(msgs, openaicore.Message{Role: "user", Content: req.Message})
 
+	content, usage, err := p.complete(ctx, msgs, 768)
+	if err != nil {
+		return nil, err
+	}
+	var classified ClassifyResponse
+	if err := json.Unmarshal([]byte(content), &classified); err != nil {
+		return nil, fmt.Errorf("nlp: decode classification JSON (%.200s): %w", content, ErrMalformedOutput)
+	}
+
+	// Attach token-usage metadata so callers can enforce budgets and write
+	// cost entries to the audit trail.
+	classified.Usage = usage
+
+	return &classified, nil
+}
+
+// complete runs one JSON-mode chat completion and returns the content of the
+// first choice together with its token usage. Upstream 429s are wrapped in
+// ErrRateLimit.
+func (p *openAIProvider) complete(ctx context.Context, msgs []openaicore.Message, maxTokens int) (string, *TokenUsage, error) {
 	body := openaicore.ChatCompletionRequest{
 		Model:          p.cfg.Model,
 		Messages:       msgs,
-		MaxTokens:      768,
+		MaxTokens:      maxTokens,
 		ResponseFormat: &openaicore.ResponseFormat{Type: "json_object"},
 	}
 
 	result, err := p.client.CreateChatCompletion(ctx, body)
 	if err != nil {
-		return nil, fmt.Errorf("nlp: %w", err)
+		return "", nil, fmt.Errorf("nlp: %w", err)
 	}
 	oaiResp := result.Response
 	latencyMS := result.LatencyMS
 
 	if oaiResp.Error != nil {
 		if result.StatusCode == 429 {
-			return nil, fmt.Errorf("nlp: API rate limit (HTTP 429): %s: %w",
+			return "", nil, fmt.Errorf("nlp: API rate limit (HTTP 429): %s: %w",
 				oaiResp.Error.Message, ErrRateLimit)
 		}
-		return nil, fmt.Errorf("nlp: API error (%s): %s", oaiResp.Error.Type, oaiResp.Error.Message)
+		return "", nil, fmt.Errorf("nlp: API error (%s): %s", oaiResp.Error.Type, oaiResp.Error.Message)
 	}
 
 	// A non-429 HTTP error without a structured error body (e.g. 503 upstream).
 	if result.StatusCode >= 400 {
 		if result.StatusCode == 429 {
-			return nil, fmt.Errorf("nlp: API rate limit (HTTP 429): %w", ErrRateLimit)
+			return "", nil, fmt.Errorf("nlp: API rate limit (HTTP 429): %w", ErrRateLimit)
 		}
-		return nil, fmt.Errorf("nlp: unexpected HTTP status %d", result.StatusCode)
+		return "", nil, fmt.Errorf("nlp: unexpected HTTP status %d", result.StatusCode)
 	}
 
 	if len(oaiResp.Choices) == 0 {
-		return nil, fmt.Errorf("nlp: no choices returned (HTTP %d)", result.StatusCode)
+		return "", nil, fmt.Errorf("nlp: no choices returned (HTTP %d)", result.StatusCode)
 	}
 
 	content, _ := oaiResp.Choices[0].Message.Content.(string)
-	var classified ClassifyResponse
-	if err := json.Unmarshal([]byte(content), &classified); err != nil {
-		return nil, fmt.Errorf("nlp: decode classification JSON (%.200s): %w", content, ErrMalformedOutput)
-	}
-
-	// Attach token-usage metadata so callers can enforce budgets and write
-	// cost entries to the audit trail.
-	classified.Usage = &TokenUsage{
+	return content, &TokenUsage{
 		PromptTokens:     oaiResp.Usage.PromptTokens,
 		CompletionTokens: oaiResp.Usage.CompletionTokens,
 		TotalTokens:      oaiResp.Usage.TotalTokens,
 		Model:            p.cfg.Model,
 		LatencyMS:        latencyMS,
-	}
-
-	return &classified, nil
+	}, nil
 }
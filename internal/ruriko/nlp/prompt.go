@@ -262,7 +262,7 @@ func DefaultCatalogue() Catalogue {
 		},
 		{
 			Action:      "gosuto.rollback",
-			Usage:       "/ruriko gosuto rollback <agent> --to <version>",
+			Usage:       "/ruriko gosuto rollback <agent> --to <version> | --hash <hash>",
 			Description: "Roll back an agent's Gosuto config to a previous version.",
 		},
 		{
@@ -270,6 +270,27 @@ func DefaultCatalogue() Catalogue {
 			Usage:       "/ruriko gosuto push <agent>",
 			Description: "Push the current Gosuto config to the running agent.",
 		},
+		{
+			Action:      "gosuto.suggest",
+			Usage:       "/ruriko gosuto suggest <agent> --goal \"<natural language>\"",
+			Description: "Draft capabilities/instructions edits for a goal and show them as a diff; nothing is stored or applied.",
+			ReadOnly:    true,
+		},
+		{
+			Action:      "gosuto.canary",
+			Usage:       "/ruriko gosuto canary <agent> --room <room-id> [--version <n>]",
+			Description: "Run a Gosuto config version in one room only, keeping the live config elsewhere.",
+		},
+		{
+			Action:      "gosuto.promote",
+			Usage:       "/ruriko gosuto promote <agent>",
+			Description: "Apply an agent's active canary Gosuto config to every room.",
+		},
+		{
+			Action:      "gosuto.abort",
+			Usage:       "/ruriko gosuto abort <agent>",
+			Description: "Drop an agent's active canary Gosuto config.",
+		},
 
 		// ----- approvals -----------------------------------------------------
 		{
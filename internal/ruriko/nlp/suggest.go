@@ -0,0 +1,76 @@
+package nlp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	openaicore "github.com/bdobrica/Ruriko/common/llm/openai"
+)
+
+// SuggestRequest asks the LLM to draft a Gosuto change for one agent.
+type SuggestRequest struct {
+	// AgentID is the agent whose config is being edited.
+	AgentID string
+	// Goal is the operator's natural-language description of the change.
+	Goal string
+	// CurrentYAML is the agent's current Gosuto config.
+	CurrentYAML string
+}
+
+// SuggestResponse is the LLM's proposed Gosuto change.
+type SuggestResponse struct {
+	// YAML is the full revised Gosuto config.
+	YAML string `json:"yaml"`
+	// Explanation is a short summary of what was changed and why.
+	Explanation string `json:"explanation"`
+	// Usage holds token-usage metadata for the call. It is not part of the
+	// LLM's JSON output.
+	Usage *TokenUsage `json:"-"`
+}
+
+// Suggester is implemented by providers that can draft Gosuto edits. It is
+// separate from Provider so classification-only providers (and test mocks)
+// do not have to support it; callers type-assert the resolved provider.
+//
+// A suggestion is only ever a proposal: callers must show it to the operator
+// and never store or apply it on their own.
+type Suggester interface {
+	SuggestGosuto(ctx context.Context, req SuggestRequest) (*SuggestResponse, error)
+}
+
+// suggestSystemPrompt instructs the LLM to return a revised Gosuto config.
+const suggestSystemPrompt = `You help operators edit Gosuto configs, the YAML files that define a Gitai agent's trust, capabilities, persona and instructions.
+
+You receive an agent's current Gosuto config and the operator's goal. Revise ONLY the capabilities and instructions sections to meet the goal; every other section is ignored. Keep capability rules least-privilege: prefer narrow mcp/tool matches, never add an allow-all rule, and keep existing deny rules. Keep instructions concise and consistent with the rest of the config.
+
+Respond with a JSON object with exactly these fields:
+  "yaml":        the complete revised Gosuto config as a YAML string
+  "explanation": one or two sentences describing the change
+
+If the goal cannot be met by editing capabilities or instructions, return the config unchanged and say why in the explanation.`
+
+// SuggestGosuto asks the LLM for a revised Gosuto config meeting req.Goal.
+func (p *openAIProvider) SuggestGosuto(ctx context.Context, req SuggestRequest) (*SuggestResponse, error) {
+	var user strings.Builder
+	fmt.Fprintf(&user, "Agent: %s\nGoal: %s\n\nCurrent Gosuto config:\n%s", req.AgentID, req.Goal, req.CurrentYAML)
+
+	msgs := []openaicore.Message{
+		{Role: "system", Content: suggestSystemPrompt},
+		{Role: "user", Content: user.String()},
+	}
+	content, usage, err := p.complete(ctx, msgs, 4096)
+	if err != nil {
+		return nil, err
+	}
+	var suggested SuggestResponse
+	if err := json.Unmarshal([]byte(content), &suggested); err != nil {
+		return nil, fmt.Errorf("nlp: decode suggestion JSON (%.200s): %w", content, ErrMalformedOutput)
+	}
+	if strings.TrimSpace(suggested.YAML) == "" {
+		return nil, fmt.Errorf("nlp: suggestion has no yaml: %w", ErrMalformedOutput)
+	}
+	suggested.Usage = usage
+	return &suggested, nil
+}
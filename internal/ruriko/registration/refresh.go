@@ -0,0 +1,84 @@
+package registration
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// Secret refresh rate limit: an agent may trigger at most refreshLimit
+// pushes per refreshWindow. Agents apply their own cooldown too; this guards
+// Ruriko and Kuze against a misbehaving one.
+const (
+	refreshLimit  = 3
+	refreshWindow = 10 * time.Minute
+)
+
+// handleSecretRefresh re-pushes an agent's bound secrets at the agent's
+// request. The caller authenticates with the ACP token Ruriko holds for it.
+func (s *Server) handleSecretRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req acpspec.SecretRefreshRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.AgentID == "" {
+		http.Error(w, "agent_id is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	agent, err := s.store.GetAgent(ctx, req.AgentID)
+	if err != nil || !agent.Enabled || !agentTokenValid(r, agent) {
+		// Unknown agents and bad tokens look the same to the caller.
+		slog.Warn("secret refresh: rejected request", "agent", req.AgentID, "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !s.refreshLimiter.Allow(refreshLimit, req.AgentID) {
+		slog.Warn("secret refresh: rate limited", "agent", req.AgentID)
+		http.Error(w, "secret refresh rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	traceID := trace.GenerateID()
+	ctx = trace.WithTraceID(ctx, traceID)
+	n, err := s.refresher.PushToAgent(ctx, req.AgentID)
+	if err != nil {
+		s.store.WriteAudit(ctx, traceID, req.AgentID, "secrets.refresh", req.AgentID, "error",
+			store.AuditPayload{"requested": req.Secrets}, err.Error())
+		slog.Error("secret refresh: push failed", "agent", req.AgentID, "err", err)
+		http.Error(w, "secret refresh failed", http.StatusBadGateway)
+		return
+	}
+	if err := s.store.WriteAudit(ctx, traceID, req.AgentID, "secrets.refresh", req.AgentID, "success",
+		store.AuditPayload{"requested": req.Secrets, "pushed": n}, ""); err != nil {
+		slog.Warn("audit write failed", "op", "secrets.refresh", "err", err)
+	}
+
+	slog.Info("secret refresh: pushed secrets", "agent", req.AgentID, "pushed", n, "requested", req.Secrets)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(acpspec.SecretRefreshResponse{Pushed: n})
+}
+
+// agentTokenValid reports whether r carries agent's ACP token as a bearer
+// token. Agents without a stored token cannot refresh.
+func agentTokenValid(r *http.Request, agent *store.Agent) bool {
+	want := agent.ACPToken.String
+	auth := r.Header.Get("Authorization")
+	return agent.ACPToken.Valid && want != "" && strings.HasPrefix(auth, "Bearer ") &&
+		subtle.ConstantTimeCompare([]byte(auth[len("Bearer "):]), []byte(want)) == 1
+}
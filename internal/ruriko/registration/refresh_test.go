@@ -0,0 +1,120 @@
+package registration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/registration"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+const agentACPToken = "saito-acp-token"
+
+// countingRefresher records PushToAgent calls.
+type countingRefresher struct {
+	calls atomic.Int32
+}
+
+func (r *countingRefresher) PushToAgent(_ context.Context, agentID string) (int, error) {
+	r.calls.Add(1)
+	return 2, nil
+}
+
+func newRefreshServer(t *testing.T) (*httptest.Server, *countingRefresher) {
+	t.Helper()
+	st, err := store.New(filepath.Join(t.TempDir(), "ruriko.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	ctx := context.Background()
+	if err := st.CreateAgent(ctx, &store.Agent{
+		ID: "saito", DisplayName: "Saito", Status: "running", Enabled: true,
+	}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	if err := st.SetAgentACPToken(ctx, "saito", agentACPToken); err != nil {
+		t.Fatalf("SetAgentACPToken: %v", err)
+	}
+
+	ref := &countingRefresher{}
+	mux := http.NewServeMux()
+	registration.New(st, registration.Config{Refresher: ref}).RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts, ref
+}
+
+func postRefresh(t *testing.T, ts *httptest.Server, token string, req acpspec.SecretRefreshRequest) *http.Response {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest(http.MethodPost, ts.URL+"/agents/secrets/refresh", bytes.NewReader(body))
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("POST /agents/secrets/refresh: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestSecretRefresh_PushesSecrets(t *testing.T) {
+	ts, ref := newRefreshServer(t)
+
+	resp := postRefresh(t, ts, agentACPToken, acpspec.SecretRefreshRequest{AgentID: "saito", Secrets: []string{"api_key"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var out acpspec.SecretRefreshResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Pushed != 2 {
+		t.Errorf("pushed = %d, want 2", out.Pushed)
+	}
+	if ref.calls.Load() != 1 {
+		t.Errorf("PushToAgent calls = %d, want 1", ref.calls.Load())
+	}
+}
+
+func TestSecretRefresh_RejectsWrongToken(t *testing.T) {
+	ts, ref := newRefreshServer(t)
+
+	for _, token := range []string{"", "not-the-token"} {
+		resp := postRefresh(t, ts, token, acpspec.SecretRefreshRequest{AgentID: "saito"})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, resp.StatusCode)
+		}
+	}
+	resp := postRefresh(t, ts, agentACPToken, acpspec.SecretRefreshRequest{AgentID: "unknown"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unknown agent: status = %d, want 401", resp.StatusCode)
+	}
+	if ref.calls.Load() != 0 {
+		t.Errorf("PushToAgent calls = %d, want 0", ref.calls.Load())
+	}
+}
+
+func TestSecretRefresh_RateLimited(t *testing.T) {
+	ts, ref := newRefreshServer(t)
+
+	var last int
+	for i := 0; i < 5; i++ {
+		last = postRefresh(t, ts, agentACPToken, acpspec.SecretRefreshRequest{AgentID: "saito"}).StatusCode
+	}
+	if last != http.StatusTooManyRequests {
+		t.Errorf("status after burst = %d, want 429", last)
+	}
+	if ref.calls.Load() != 3 {
+		t.Errorf("PushToAgent calls = %d, want 3", ref.calls.Load())
+	}
+}
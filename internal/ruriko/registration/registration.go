@@ -0,0 +1,197 @@
+// Package registration implements agent self-registration.
+//
+// A Gitai agent configured with RURIKO_REGISTER_URL POSTs its details to
+// Ruriko on startup:
+//
+//	POST /agents/register  (Authorization: Bearer <bootstrap token>)
+//
+// Ruriko validates the shared bootstrap token and records the agent's control
+// URL, ACP token, runtime version and applied Gosuto hash in the store. This
+// lets agents come up behind dynamic addresses without an operator re-running
+// provisioning.
+//
+// Agents behind NAT can additionally open a WebSocket control tunnel:
+//
+//	GET /agents/tunnel  (Authorization: Bearer <agent ACP token>, X-Agent-ID: <id>)
+//
+// Agents that have no ACP token on record yet authenticate the tunnel with
+// the bootstrap token instead.
+//
+// While the tunnel is connected, every ACP client targeting the agent's
+// control URL sends its requests over the tunnel instead of HTTP.
+//
+// Registration only updates agents that already exist in the store (created
+// via /ruriko agents create); unknown or disabled agent IDs are rejected so a
+// leaked bootstrap token cannot introduce new agents. Once an agent has an ACP
+// token on record, re-registration must present that same token, so a leaked
+// bootstrap token cannot redirect or take over a registered agent either.
+//
+// Agents whose secret leases lapse can ask for fresh ones:
+//
+//	POST /agents/secrets/refresh  (Authorization: Bearer <agent ACP token>)
+//
+// Ruriko re-issues Kuze tokens for the agent's bound secrets and pushes them
+// over ACP before answering, so the agent can retry immediately.
+package registration
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bdobrica/Ruriko/common/ratelimit"
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// maxBodyBytes caps the registration request body.
+const maxBodyBytes = 64 * 1024 // 64 KiB
+
+// agentStore is the minimal interface the Server needs from the Store.
+type agentStore interface {
+	GetAgent(ctx context.Context, id string) (*store.Agent, error)
+	UpdateAgentRegistration(ctx context.Context, id, controlURL, acpToken, runtimeVersion, gosutoHash string) error
+	WriteAudit(ctx context.Context, traceID, actor, action, target, result string, payload store.AuditPayload, errMsg string) error
+}
+
+// SecretRefresher pushes an agent's bound secrets to it.
+// *secrets.Distributor satisfies it.
+type SecretRefresher interface {
+	PushToAgent(ctx context.Context, agentID string) (int, error)
+}
+
+// Config holds options for creating a Server.
+type Config struct {
+	// BootstrapToken is the shared secret agents must present as a bearer
+	// token. It must be non-empty; callers should not mount the routes when
+	// self-registration is not configured.
+	BootstrapToken string
+
+	// Tunnels, when non-nil, enables GET /agents/tunnel and receives the
+	// tunnels agents open. Pass acp.Tunnels in production.
+	Tunnels *acp.TunnelRegistry
+
+	// Refresher, when non-nil, enables POST /agents/secrets/refresh.
+	Refresher SecretRefresher
+}
+
+// Server handles POST /agents/register.
+type Server struct {
+	store          agentStore
+	token          string
+	tunnels        *acp.TunnelRegistry
+	refresher      SecretRefresher
+	refreshLimiter *ratelimit.KeyedFixedWindow
+}
+
+// New creates a new registration Server.
+func New(st agentStore, cfg Config) *Server {
+	return &Server{
+		store:          st,
+		token:          cfg.BootstrapToken,
+		tunnels:        cfg.Tunnels,
+		refresher:      cfg.Refresher,
+		refreshLimiter: ratelimit.NewKeyedFixedWindow(refreshWindow),
+	}
+}
+
+// RouteRegistrar is satisfied by *http.ServeMux and by app.HealthServer's
+// Handle method.
+type RouteRegistrar interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// RegisterRoutes mounts the registration handler on the given registrar.
+// Registration and the tunnel need a bootstrap token; the secret refresh
+// route needs a Refresher.
+func (s *Server) RegisterRoutes(r RouteRegistrar) {
+	if s.token != "" {
+		r.Handle("/agents/register", http.HandlerFunc(s.handleRegister))
+		if s.tunnels != nil {
+			r.Handle("/agents/tunnel", http.HandlerFunc(s.handleTunnel))
+		}
+	}
+	if s.refresher != nil {
+		r.Handle("/agents/secrets/refresh", http.HandlerFunc(s.handleSecretRefresh))
+	}
+}
+
+// authorized reports whether r carries the bootstrap bearer token.
+func (s *Server) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	return s.token != "" && strings.HasPrefix(auth, "Bearer ") &&
+		subtle.ConstantTimeCompare([]byte(auth[len("Bearer "):]), []byte(s.token)) == 1
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.authorized(r) {
+		slog.Warn("registration: rejected request with invalid bootstrap token", "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req acpspec.RegisterRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.AgentID == "" {
+		http.Error(w, "agent_id is required", http.StatusBadRequest)
+		return
+	}
+	u, err := url.Parse(req.ControlURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, "control_url must be an absolute http(s) URL", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	agent, err := s.store.GetAgent(ctx, req.AgentID)
+	if err != nil {
+		slog.Info("registration: unknown agent", "agent", req.AgentID)
+		http.Error(w, "agent not found", http.StatusNotFound)
+		return
+	}
+	if !agent.Enabled {
+		slog.Info("registration: agent is disabled", "agent", req.AgentID)
+		http.Error(w, "agent not found", http.StatusNotFound)
+		return
+	}
+	if !acpTokenMatches(agent, req.ACPToken) {
+		slog.Warn("registration: rejected re-registration without the current ACP token",
+			"agent", req.AgentID, "remote", r.RemoteAddr)
+		http.Error(w, "acp_token does not match the registered agent", http.StatusForbidden)
+		return
+	}
+
+	if err := s.store.UpdateAgentRegistration(ctx, req.AgentID, req.ControlURL, req.ACPToken, req.Version, req.GosutoHash); err != nil {
+		slog.Error("registration: store update failed", "agent", req.AgentID, "err", err)
+		http.Error(w, "registration failed", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("registration: agent registered",
+		"agent", req.AgentID, "control_url", req.ControlURL, "version", req.Version)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// acpTokenMatches reports whether token may register on behalf of agent.
+// Agents without a stored ACP token accept any token; once one is stored it
+// can only be changed through ACP token rotation, not by re-registering.
+func acpTokenMatches(agent *store.Agent, token string) bool {
+	want := agent.ACPToken.String
+	if !agent.ACPToken.Valid || want == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
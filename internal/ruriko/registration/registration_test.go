@@ -0,0 +1,169 @@
+package registration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/registration"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+const bootstrapToken = "bootstrap-secret"
+
+func newTestServer(t *testing.T) (*httptest.Server, *store.Store) {
+	t.Helper()
+	st, err := store.New(filepath.Join(t.TempDir(), "ruriko.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	if err := st.CreateAgent(context.Background(), &store.Agent{
+		ID: "saito", DisplayName: "Saito", Status: "running", Enabled: true,
+	}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	registration.New(st, registration.Config{BootstrapToken: bootstrapToken}).RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts, st
+}
+
+func postRegister(t *testing.T, ts *httptest.Server, token string, req acpspec.RegisterRequest) *http.Response {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest(http.MethodPost, ts.URL+"/agents/register", bytes.NewReader(body))
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("POST /agents/register: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestRegister_UpdatesStore(t *testing.T) {
+	ts, st := newTestServer(t)
+
+	resp := postRegister(t, ts, bootstrapToken, acpspec.RegisterRequest{
+		AgentID:    "saito",
+		ControlURL: "http://10.1.2.3:8765",
+		ACPToken:   "acp-token-1",
+		Version:    "v1.2.3",
+		GosutoHash: "abc123",
+	})
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+
+	agent, err := st.GetAgent(context.Background(), "saito")
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if agent.ControlURL.String != "http://10.1.2.3:8765" {
+		t.Errorf("control URL = %q", agent.ControlURL.String)
+	}
+	if agent.ACPToken.String != "acp-token-1" {
+		t.Errorf("acp token = %q", agent.ACPToken.String)
+	}
+	if agent.RuntimeVersion.String != "v1.2.3" {
+		t.Errorf("runtime version = %q", agent.RuntimeVersion.String)
+	}
+	if agent.ActualGosutoHash.String != "abc123" {
+		t.Errorf("actual gosuto hash = %q", agent.ActualGosutoHash.String)
+	}
+	if !agent.LastSeen.Valid {
+		t.Error("expected last_seen to be set")
+	}
+}
+
+func TestRegister_RejectsBadToken(t *testing.T) {
+	ts, st := newTestServer(t)
+
+	for _, token := range []string{"", "wrong-token"} {
+		resp := postRegister(t, ts, token, acpspec.RegisterRequest{
+			AgentID:    "saito",
+			ControlURL: "http://evil.example.com:8765",
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, resp.StatusCode)
+		}
+	}
+
+	agent, err := st.GetAgent(context.Background(), "saito")
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if agent.ControlURL.Valid && agent.ControlURL.String != "" {
+		t.Errorf("control URL must not change on rejected registration, got %q", agent.ControlURL.String)
+	}
+}
+
+func TestRegister_RequiresStoredACPToken(t *testing.T) {
+	ts, st := newTestServer(t)
+	ctx := context.Background()
+	if err := st.SetAgentACPToken(ctx, "saito", "acp-token-1"); err != nil {
+		t.Fatalf("SetAgentACPToken: %v", err)
+	}
+
+	for _, token := range []string{"", "attacker-token"} {
+		resp := postRegister(t, ts, bootstrapToken, acpspec.RegisterRequest{
+			AgentID:    "saito",
+			ControlURL: "http://evil.example.com:8765",
+			ACPToken:   token,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("acp token %q: expected 403, got %d", token, resp.StatusCode)
+		}
+	}
+	agent, err := st.GetAgent(ctx, "saito")
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if agent.ACPToken.String != "acp-token-1" || agent.ControlURL.String == "http://evil.example.com:8765" {
+		t.Fatalf("rejected registration changed the agent: token=%q url=%q", agent.ACPToken.String, agent.ControlURL.String)
+	}
+
+	resp := postRegister(t, ts, bootstrapToken, acpspec.RegisterRequest{
+		AgentID:    "saito",
+		ControlURL: "http://10.1.2.4:8765",
+		ACPToken:   "acp-token-1",
+	})
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 with the current token, got %d", resp.StatusCode)
+	}
+}
+
+func TestRegister_UnknownAgent(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp := postRegister(t, ts, bootstrapToken, acpspec.RegisterRequest{
+		AgentID:    "ghost",
+		ControlURL: "http://10.1.2.3:8765",
+	})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestRegister_InvalidControlURL(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp := postRegister(t, ts, bootstrapToken, acpspec.RegisterRequest{
+		AgentID:    "saito",
+		ControlURL: "not a url",
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
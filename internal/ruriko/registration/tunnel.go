@@ -0,0 +1,72 @@
+package registration
+
+import (
+	"log/slog"
+	"net/http"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// tunnelURLPrefix is the control URL recorded for agents that only reach
+// Ruriko through the tunnel and never registered an HTTP address.
+const tunnelURLPrefix = "tunnel://"
+
+// handleTunnel upgrades an authenticated agent connection to a WebSocket and
+// registers it as the agent's ACP transport until it disconnects.
+//
+// Agents with an ACP token on record must present that token as the bearer
+// token; the bootstrap token is only accepted for agents that have none yet.
+func (s *Server) handleTunnel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	agentID := r.Header.Get("X-Agent-ID")
+	if agentID == "" {
+		http.Error(w, "X-Agent-ID header is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	agent, err := s.store.GetAgent(ctx, agentID)
+	if err != nil || !agent.Enabled || !s.tunnelAuthorized(r, agent) {
+		// Unknown agents and bad tokens look the same to the caller.
+		slog.Warn("tunnel: rejected connection", "agent", agentID, "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	controlURL := agent.ControlURL.String
+	if controlURL == "" {
+		controlURL = tunnelURLPrefix + agentID
+	}
+	if err := s.store.UpdateAgentRegistration(ctx, agentID, controlURL, "", "", ""); err != nil {
+		slog.Error("tunnel: store update failed", "agent", agentID, "err", err)
+		http.Error(w, "registration failed", http.StatusInternalServerError)
+		return
+	}
+
+	websocket.Server{Handler: func(conn *websocket.Conn) {
+		t := acp.NewTunnel(conn)
+		s.tunnels.Register(controlURL, t)
+		defer s.tunnels.Unregister(controlURL, t)
+		slog.Info("tunnel: agent connected", "agent", agentID, "control_url", controlURL)
+		if err := t.Serve(); err != nil {
+			slog.Info("tunnel: agent disconnected", "agent", agentID, "err", err)
+			return
+		}
+		slog.Info("tunnel: agent disconnected", "agent", agentID)
+	}}.ServeHTTP(w, r)
+}
+
+// tunnelAuthorized reports whether r may open a tunnel for agent: with the
+// agent's own ACP token once one is stored, otherwise with the bootstrap token.
+func (s *Server) tunnelAuthorized(r *http.Request, agent *store.Agent) bool {
+	if agent.ACPToken.Valid && agent.ACPToken.String != "" {
+		return agentTokenValid(r, agent)
+	}
+	return s.authorized(r)
+}
@@ -0,0 +1,191 @@
+package registration_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
+	"github.com/bdobrica/Ruriko/internal/ruriko/registration"
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// natControlURL is an address Ruriko cannot reach over HTTP; requests to it
+// only succeed when they travel over the tunnel.
+const natControlURL = "http://saito.nat.invalid:8765"
+
+// newTunnelServer starts Ruriko's registration routes (with the tunnel
+// enabled) for an agent whose recorded control URL is unreachable. A non-empty
+// acpToken is stored as the agent's ACP token.
+func newTunnelServer(t *testing.T, acpToken string) *httptest.Server {
+	t.Helper()
+	st, err := store.New(filepath.Join(t.TempDir(), "ruriko.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	agent := &store.Agent{ID: "saito", DisplayName: "Saito", Status: "running", Enabled: true}
+	agent.ControlURL.String, agent.ControlURL.Valid = natControlURL, true
+	agent.ACPToken.String, agent.ACPToken.Valid = acpToken, acpToken != ""
+	if err := st.CreateAgent(context.Background(), agent); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	registration.New(st, registration.Config{
+		BootstrapToken: bootstrapToken,
+		Tunnels:        acp.Tunnels,
+	}).RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// agentStub records what the agent-side handlers received.
+type agentStub struct {
+	mu      sync.Mutex
+	applied string
+}
+
+func (a *agentStub) appliedYAML() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.applied
+}
+
+// startTunnelAgent runs a Gitai ACP server that only reaches Ruriko through
+// the outbound tunnel. The returned cancel func disconnects it.
+func startTunnelAgent(t *testing.T, ruriko *httptest.Server, token string) (*agentStub, context.CancelFunc) {
+	t.Helper()
+	stub := &agentStub{}
+	srv := control.New(":0", control.Handlers{
+		AgentID:    "saito",
+		Version:    "v-test",
+		StartedAt:  time.Now(),
+		Token:      "acp-token",
+		GosutoHash: func() string { return "hash-1" },
+		MCPNames:   func() []string { return nil },
+		ApplyConfig: func(yaml, hash string) error {
+			stub.mu.Lock()
+			stub.applied = yaml
+			stub.mu.Unlock()
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go srv.RunTunnel(ctx, control.TunnelConfig{
+		URL:     "ws" + strings.TrimPrefix(ruriko.URL, "http") + "/agents/tunnel",
+		Token:   token,
+		AgentID: "saito",
+	})
+	return stub, cancel
+}
+
+func waitForTunnel(t *testing.T, connected bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if (acp.Tunnels.Lookup(natControlURL) != nil) == connected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for tunnel connected=%v", connected)
+}
+
+func TestTunnel_StatusAndConfigApply(t *testing.T) {
+	ts := newTunnelServer(t, "")
+	stub, _ := startTunnelAgent(t, ts, bootstrapToken)
+	waitForTunnel(t, true)
+
+	client := acp.New(natControlURL, acp.Options{Token: "acp-token"})
+	ctx := context.Background()
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status over tunnel: %v", err)
+	}
+	if status.AgentID != "saito" || status.GosutoHash != "hash-1" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	sum := sha256.Sum256([]byte("apiVersion: gosuto/v1\n"))
+	if err := client.ApplyConfig(ctx, acp.ConfigApplyRequest{YAML: "apiVersion: gosuto/v1\n", Hash: hex.EncodeToString(sum[:])}); err != nil {
+		t.Fatalf("ApplyConfig over tunnel: %v", err)
+	}
+	if got := stub.appliedYAML(); got != "apiVersion: gosuto/v1\n" {
+		t.Errorf("agent applied %q", got)
+	}
+
+	// The agent's own bearer-token check still applies inside the tunnel.
+	if _, err := acp.New(natControlURL, acp.Options{Token: "wrong"}).Status(ctx); err == nil {
+		t.Error("expected wrong ACP token to be rejected over tunnel")
+	}
+}
+
+func TestTunnel_FallsBackToHTTPAfterDisconnect(t *testing.T) {
+	ts := newTunnelServer(t, "")
+	_, disconnect := startTunnelAgent(t, ts, bootstrapToken)
+	waitForTunnel(t, true)
+
+	disconnect()
+	waitForTunnel(t, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := acp.New(natControlURL).Health(ctx); err == nil {
+		t.Fatal("expected HTTP fallback to an unreachable host to fail")
+	}
+}
+
+func TestTunnel_RejectsBadToken(t *testing.T) {
+	ts := newTunnelServer(t, "")
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/agents/tunnel", nil)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+	req.Header.Set("X-Agent-ID", "saito")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /agents/tunnel: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestTunnel_RegisteredAgentRequiresACPToken(t *testing.T) {
+	ts := newTunnelServer(t, "acp-token")
+
+	for token, want := range map[string]int{
+		bootstrapToken: http.StatusUnauthorized,
+		"wrong-token":  http.StatusUnauthorized,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/agents/tunnel", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Agent-ID", "saito")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /agents/tunnel: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("token %q: expected %d, got %d", token, want, resp.StatusCode)
+		}
+	}
+
+	// The agent's own ACP token opens the tunnel.
+	startTunnelAgent(t, ts, "acp-token")
+	waitForTunnel(t, true)
+}
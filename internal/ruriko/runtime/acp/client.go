@@ -9,15 +9,33 @@
 //   - Per-operation timeouts via context.WithTimeout (no shared client timeout).
 //   - X-Request-ID on every request; X-Idempotency-Key on mutating operations.
 //   - Response bodies are capped at 1 MiB to prevent memory exhaustion.
+//   - Large request bodies can be gzipped (Options.GzipMinBytes or
+//     ConfigureGzip); compression is off by default.
+//
+// Agents whose ACP server serves HTTPS are reached with the process-wide TLS
+// settings installed by ConfigureTLS (custom CA or, for development,
+// skipped verification), or a per-client Options.TLSConfig.
+//
+// Agents that cannot be reached over HTTP may instead dial Ruriko and keep a
+// WebSocket control tunnel open (see Tunnel). Clients transparently use a
+// registered tunnel for their base URL and fall back to HTTP otherwise.
 package acp
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
@@ -41,26 +59,130 @@ type Options struct {
 	// Token, when non-empty, is sent as a Bearer token in the Authorization
 	// header on every request.  When empty the header is omitted (dev/test).
 	Token string
+
+	// TLSConfig, when non-nil, is used for HTTPS connections to the agent
+	// instead of the process-wide settings from ConfigureTLS.
+	TLSConfig *tls.Config
+
+	// GzipMinBytes, when positive, gzips request bodies of at least this
+	// many bytes (sent with Content-Encoding: gzip). When zero the
+	// process-wide setting from ConfigureGzip applies.
+	GzipMinBytes int
+}
+
+// defaultGzipMinBytes is the request size from which clients without their
+// own Options.GzipMinBytes gzip bodies; 0 disables compression.
+var defaultGzipMinBytes atomic.Int64
+
+// ConfigureGzip sets the request body size, in bytes, from which clients
+// gzip their payloads (large Gosuto configs, tool calls). 0 disables
+// compression, which is the default; enable it only once every agent runs a
+// Gitai that accepts gzip-encoded bodies.
+func ConfigureGzip(minBytes int) {
+	defaultGzipMinBytes.Store(int64(max(minBytes, 0)))
+}
+
+// defaultTransport is the HTTP transport used by clients without their own
+// TLSConfig. ConfigureTLS replaces it; until then it is http.DefaultTransport.
+var defaultTransport atomic.Pointer[http.Transport]
+
+// tlsTransports caches one transport per Options.TLSConfig so clients that
+// share a TLS config also share its connection pool.
+var tlsTransports sync.Map // *tls.Config → *http.Transport
+
+// LoadTLSConfig builds a client TLS configuration that trusts the PEM
+// certificates in caFile in addition to the system roots. When
+// insecureSkipVerify is true, server certificates are not verified at all
+// (development only). It returns nil when neither option is set.
+func LoadTLSConfig(caFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	if caFile == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify, //nolint:gosec // explicit operator opt-in
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read acp ca file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("acp ca file %s: no PEM certificates found", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// ConfigureTLS installs the TLS settings used by every client created
+// afterwards without Options.TLSConfig. Call it once at startup.
+func ConfigureTLS(caFile string, insecureSkipVerify bool) error {
+	cfg, err := LoadTLSConfig(caFile, insecureSkipVerify)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		defaultTransport.Store(nil)
+		return nil
+	}
+	defaultTransport.Store(transportWithTLS(cfg))
+	return nil
+}
+
+// transportWithTLS clones http.DefaultTransport with the given TLS config.
+func transportWithTLS(cfg *tls.Config) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = cfg
+	return t
+}
+
+// transportFor returns the cached transport for cfg, creating it on first use.
+func transportFor(cfg *tls.Config) *http.Transport {
+	if t, ok := tlsTransports.Load(cfg); ok {
+		return t.(*http.Transport)
+	}
+	t, _ := tlsTransports.LoadOrStore(cfg, transportWithTLS(cfg))
+	return t.(*http.Transport)
 }
 
 // Client is an ACP HTTP client for a single agent control endpoint.
 type Client struct {
-	baseURL    string
-	token      string
-	httpClient *http.Client
+	baseURL      string
+	token        string
+	gzipMinBytes int
+	httpClient   *http.Client
 }
 
 // New creates a new ACP client targeting the given base URL
 // (e.g. "http://10.0.0.5:8765").  Zero or one Options value may be supplied.
 func New(baseURL string, opts ...Options) *Client {
 	var token string
+	gzipMinBytes := int(defaultGzipMinBytes.Load())
+	var fallback http.RoundTripper = http.DefaultTransport
+	if t := defaultTransport.Load(); t != nil {
+		fallback = t
+	}
 	if len(opts) > 0 {
 		token = opts[0].Token
+		if opts[0].GzipMinBytes > 0 {
+			gzipMinBytes = opts[0].GzipMinBytes
+		}
+		if opts[0].TLSConfig != nil {
+			fallback = transportFor(opts[0].TLSConfig)
+		}
 	}
 	return &Client{
-		baseURL:    baseURL,
-		token:      token,
-		httpClient: &http.Client{}, // no global timeout — per-op contexts are used
+		baseURL:      baseURL,
+		token:        token,
+		gzipMinBytes: gzipMinBytes,
+		// No global timeout — per-op contexts are used. Requests go over the
+		// agent's control tunnel when one is connected, HTTP(S) otherwise.
+		httpClient: &http.Client{Transport: tunnelTransport{registry: Tunnels, fallback: fallback}},
 	}
 }
 
@@ -71,11 +193,34 @@ func New(baseURL string, opts ...Options) *Client {
 type HealthResponse = acpspec.HealthResponse
 type StatusResponse = acpspec.StatusResponse
 type ConfigApplyRequest = acpspec.ConfigApplyRequest
+type ConfigResponse = acpspec.ConfigResponse
+type ConfigCanaryRequest = acpspec.ConfigCanaryRequest
+type CanaryStatus = acpspec.CanaryStatus
+type MonthlySpend = acpspec.MonthlySpend
+type MCPServerStatus = acpspec.MCPServerStatus
 type SecretsApplyRequest = acpspec.SecretsApplyRequest
 type SecretLease = acpspec.SecretLease
 type SecretsTokenRequest = acpspec.SecretsTokenRequest
+type SecretsTokenResponse = acpspec.SecretsTokenResponse
+type SecretLeaseFailure = acpspec.SecretLeaseFailure
 type ToolCallRequest = acpspec.ToolCallRequest
 type ToolCallResponse = acpspec.ToolCallResponse
+type DeadLetter = acpspec.DeadLetter
+type DeadLetterListResponse = acpspec.DeadLetterListResponse
+type DeadLetterRetryRequest = acpspec.DeadLetterRetryRequest
+type ToolInfo = acpspec.ToolInfo
+type ToolListResponse = acpspec.ToolListResponse
+type ToolCallRecord = acpspec.ToolCallRecord
+type ToolCallListResponse = acpspec.ToolCallListResponse
+type TurnSummary = acpspec.TurnSummary
+type TurnListResponse = acpspec.TurnListResponse
+type TranscriptMessage = acpspec.TranscriptMessage
+type TranscriptToolCall = acpspec.TranscriptToolCall
+type TurnTranscriptResponse = acpspec.TurnTranscriptResponse
+type TokenRotateRequest = acpspec.TokenRotateRequest
+type TokenRotateResponse = acpspec.TokenRotateResponse
+type BroadcastRequest = acpspec.BroadcastRequest
+type BroadcastResponse = acpspec.BroadcastResponse
 type ErrorResponse = acpspec.ErrorResponse
 
 // Health calls GET /health and returns the response.
@@ -100,6 +245,18 @@ func (c *Client) Status(ctx context.Context) (*StatusResponse, error) {
 	return &resp, nil
 }
 
+// Config calls GET /config and returns the Gosuto YAML currently applied on
+// the agent together with its hash.
+func (c *Client) Config(ctx context.Context) (*ConfigResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutStatus)
+	defer cancel()
+	var resp ConfigResponse
+	if err := c.get(ctx, "/config", &resp); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return &resp, nil
+}
+
 // ApplyConfig pushes a new Gosuto configuration to the agent.
 func (c *Client) ApplyConfig(ctx context.Context, req ConfigApplyRequest) error {
 	ctx, cancel := context.WithTimeout(ctx, timeoutMutate)
@@ -107,6 +264,21 @@ func (c *Client) ApplyConfig(ctx context.Context, req ConfigApplyRequest) error
 	return c.post(ctx, "/config/apply", req, nil, true)
 }
 
+// ApplyCanary pushes a Gosuto config that the agent uses only for turns in
+// req.Room, keeping its live config for every other room.
+func (c *Client) ApplyCanary(ctx context.Context, req ConfigCanaryRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, timeoutMutate)
+	defer cancel()
+	return c.post(ctx, "/config/canary", req, nil, true)
+}
+
+// AbortCanary drops the agent's canary config.
+func (c *Client) AbortCanary(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, timeoutMutate)
+	defer cancel()
+	return c.post(ctx, "/config/canary/abort", nil, nil, true)
+}
+
 // ApplySecrets pushes a secrets bundle to the agent.
 func (c *Client) ApplySecrets(ctx context.Context, req SecretsApplyRequest) error {
 	ctx, cancel := context.WithTimeout(ctx, timeoutSecrets)
@@ -116,11 +288,16 @@ func (c *Client) ApplySecrets(ctx context.Context, req SecretsApplyRequest) erro
 
 // ApplySecretsToken sends a token-based secret distribution request to the agent.
 // The agent redeems each lease from Kuze to obtain the plaintext value; secrets
-// never travel in the ACP payload.
-func (c *Client) ApplySecretsToken(ctx context.Context, req SecretsTokenRequest) error {
+// never travel in the ACP payload. The response lists the refs the agent
+// applied and those it failed to redeem; a partial outcome is not an error.
+func (c *Client) ApplySecretsToken(ctx context.Context, req SecretsTokenRequest) (*SecretsTokenResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeoutSecrets)
 	defer cancel()
-	return c.post(ctx, "/secrets/token", req, nil, true)
+	var resp SecretsTokenResponse
+	if err := c.post(ctx, "/secrets/token", req, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
 // Restart requests the agent to gracefully restart its process.
@@ -148,6 +325,115 @@ func (c *Client) CallTool(ctx context.Context, req ToolCallRequest) (*ToolCallRe
 	return &resp, nil
 }
 
+// ListTools calls GET /tools and returns the tools the agent currently
+// offers to its LLM. Input schemas are included when withSchemas is set.
+func (c *Client) ListTools(ctx context.Context, withSchemas bool) (*ToolListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutStatus)
+	defer cancel()
+	path := "/tools"
+	if withSchemas {
+		path += "?schemas=true"
+	}
+	var resp ToolListResponse
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, fmt.Errorf("tools: %w", err)
+	}
+	return &resp, nil
+}
+
+// ListToolCalls calls GET /calls and returns the agent's most recent
+// tool-call audit entries. A limit of 0 uses the agent's default.
+func (c *Client) ListToolCalls(ctx context.Context, limit int) (*ToolCallListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutStatus)
+	defer cancel()
+	path := "/calls"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var resp ToolCallListResponse
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, fmt.Errorf("calls: %w", err)
+	}
+	return &resp, nil
+}
+
+// ListTurns calls GET /turns and returns summaries of the agent's most
+// recent turns. A limit of 0 uses the agent's default.
+func (c *Client) ListTurns(ctx context.Context, limit int) (*TurnListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutStatus)
+	defer cancel()
+	path := "/turns"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var resp TurnListResponse
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, fmt.Errorf("turns: %w", err)
+	}
+	return &resp, nil
+}
+
+// TurnTranscript calls GET /turns/{trace}/transcript and returns the
+// secret-redacted message history of the agent's turn traceID. Agents only
+// record transcripts when FEATURE_TURN_TRANSCRIPTS is set.
+func (c *Client) TurnTranscript(ctx context.Context, traceID string) (*TurnTranscriptResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutStatus)
+	defer cancel()
+	var resp TurnTranscriptResponse
+	if err := c.get(ctx, "/turns/"+url.PathEscape(traceID)+"/transcript", &resp); err != nil {
+		return nil, fmt.Errorf("transcript: %w", err)
+	}
+	return &resp, nil
+}
+
+// RotateToken calls POST /token/rotate, installing req.Token as the agent's
+// ACP bearer token. The client's own token keeps working until the returned
+// retirement time.
+func (c *Client) RotateToken(ctx context.Context, req TokenRotateRequest) (*TokenRotateResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutMutate)
+	defer cancel()
+	var resp TokenRotateResponse
+	if err := c.post(ctx, "/token/rotate", req, &resp, false); err != nil {
+		return nil, fmt.Errorf("rotate token: %w", err)
+	}
+	return &resp, nil
+}
+
+// Broadcast calls POST /broadcast, asking the agent to post req.Message to
+// every room it is in. It is not retried: a retry after a partial failure
+// would post the notice twice.
+func (c *Client) Broadcast(ctx context.Context, req BroadcastRequest) (*BroadcastResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutMutate)
+	defer cancel()
+	var resp BroadcastResponse
+	if err := c.post(ctx, "/broadcast", req, &resp, false); err != nil {
+		return nil, fmt.Errorf("broadcast: %w", err)
+	}
+	return &resp, nil
+}
+
+// ListDeadLetters calls GET /dlq and returns the agent's failed-event queue.
+func (c *Client) ListDeadLetters(ctx context.Context) (*DeadLetterListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutStatus)
+	defer cancel()
+	var resp DeadLetterListResponse
+	if err := c.get(ctx, "/dlq", &resp); err != nil {
+		return nil, fmt.Errorf("dlq: %w", err)
+	}
+	return &resp, nil
+}
+
+// RetryDeadLetter asks the agent to re-dispatch a dead-lettered event. The
+// retry runs asynchronously on the agent; a nil error means it was started.
+func (c *Client) RetryDeadLetter(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, timeoutMutate)
+	defer cancel()
+	if err := c.post(ctx, "/dlq/retry", DeadLetterRetryRequest{ID: id}, nil, true); err != nil {
+		return fmt.Errorf("dlq retry: %w", err)
+	}
+	return nil
+}
+
 // --- internal helpers ---
 
 func (c *Client) get(ctx context.Context, path string, out interface{}) error {
@@ -163,11 +449,18 @@ func (c *Client) get(ctx context.Context, path string, out interface{}) error {
 // so the server can safely deduplicate retried calls within its TTL window.
 func (c *Client) post(ctx context.Context, path string, body interface{}, out interface{}, idempotent bool) error {
 	var bodyReader io.Reader
+	gzipped := false
 	if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("marshal request: %w", err)
 		}
+		if c.gzipMinBytes > 0 && len(b) >= c.gzipMinBytes {
+			if b, err = gzipBytes(b); err != nil {
+				return fmt.Errorf("compress request: %w", err)
+			}
+			gzipped = true
+		}
 		bodyReader = bytes.NewReader(b)
 	}
 
@@ -178,20 +471,38 @@ func (c *Client) post(ctx context.Context, path string, body interface{}, out in
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	c.setCommonHeaders(req, idempotent)
 	return c.do(req, out)
 }
 
+// gzipBytes returns b gzip-compressed.
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // setCommonHeaders attaches headers present on every outgoing ACP request:
-//   - X-Trace-ID  (propagated from ctx when available)
+//   - X-Trace-ID  (propagated from ctx; generated when ctx has none)
 //   - X-Request-ID (unique per call; aids server-side log correlation)
 //   - X-Idempotency-Key (on mutating ops; equals X-Request-ID)
 //   - Authorization: Bearer <token> (when a token is configured)
 func (c *Client) setCommonHeaders(req *http.Request, addIdempotencyKey bool) {
 	// Trace propagation.
-	if traceID := trace.FromContext(req.Context()); traceID != "" {
-		req.Header.Set("X-Trace-ID", traceID)
+	traceID := trace.FromContext(req.Context())
+	if traceID == "" {
+		traceID = trace.GenerateID()
 	}
+	req.Header.Set(trace.Header, traceID)
 
 	// Unique per-call request ID for server-side log correlation.
 	reqID := trace.GenerateID()
@@ -1,15 +1,18 @@
 package acp_test
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/bdobrica/Ruriko/common/trace"
 	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
 )
 
@@ -68,6 +71,30 @@ func TestClient_SendsRequestID(t *testing.T) {
 	}
 }
 
+func TestClient_PropagatesTraceID(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(trace.Header)
+		json.NewEncoder(w).Encode(acp.HealthResponse{Status: "ok"})
+	}))
+	defer ts.Close()
+
+	ctx := trace.WithTraceID(context.Background(), "t_from_ruriko")
+	if _, err := acp.New(ts.URL).Health(ctx); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if got != "t_from_ruriko" {
+		t.Errorf("%s = %q; want t_from_ruriko", trace.Header, got)
+	}
+
+	if _, err := acp.New(ts.URL).Health(context.Background()); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if !strings.HasPrefix(got, "t_") || got == "t_from_ruriko" {
+		t.Errorf("%s = %q; want a generated trace ID", trace.Header, got)
+	}
+}
+
 func TestClient_SendsIdempotencyKeyOnMutation(t *testing.T) {
 	var gotIdemKey string
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -155,6 +182,45 @@ func TestClient_ErrorResponseIncludesBody(t *testing.T) {
 	}
 }
 
+// --- Request compression --------------------------------------------------
+
+func TestClient_GzipsLargeBodies(t *testing.T) {
+	var gotEncoding []string
+	var gotYAML []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = append(gotEncoding, r.Header.Get("Content-Encoding"))
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("gzip reader: %v", err)
+				return
+			}
+			body = zr
+		}
+		var req acp.ConfigApplyRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		gotYAML = append(gotYAML, req.YAML)
+	}))
+	defer ts.Close()
+
+	client := acp.New(ts.URL, acp.Options{GzipMinBytes: 1024})
+	large := strings.Repeat("# padding\n", 200)
+	for _, yaml := range []string{"apiVersion: gosuto/v1\n", large} {
+		if err := client.ApplyConfig(context.Background(), acp.ConfigApplyRequest{YAML: yaml, Hash: "h"}); err != nil {
+			t.Fatalf("ApplyConfig: %v", err)
+		}
+	}
+	if len(gotEncoding) != 2 || gotEncoding[0] != "" || gotEncoding[1] != "gzip" {
+		t.Errorf("Content-Encoding = %q, want only the large body gzipped", gotEncoding)
+	}
+	if len(gotYAML) != 2 || gotYAML[1] != large {
+		t.Errorf("server did not decode the gzipped config intact")
+	}
+}
+
 // --- Cancel endpoint test (R2.5) ------------------------------------------
 
 func TestClient_Cancel(t *testing.T) {
@@ -197,7 +263,7 @@ func TestClient_ApplySecretsToken(t *testing.T) {
 	defer ts.Close()
 
 	client := acp.New(ts.URL)
-	err := client.ApplySecretsToken(context.Background(), acp.SecretsTokenRequest{
+	_, err := client.ApplySecretsToken(context.Background(), acp.SecretsTokenRequest{
 		Leases: []acp.SecretLease{
 			{
 				SecretRef:       "openai_api_key",
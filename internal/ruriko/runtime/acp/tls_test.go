@@ -0,0 +1,90 @@
+package acp_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+)
+
+// newTLSAgent starts an HTTPS agent stub with a self-signed certificate and
+// returns it together with a PEM file holding that certificate as the CA.
+func newTLSAgent(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(acp.HealthResponse{Status: "ok", AgentID: "tls-agent"})
+	}))
+	t.Cleanup(ts.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, pemBytes, 0o600); err != nil {
+		t.Fatalf("write ca: %v", err)
+	}
+	return ts, caFile
+}
+
+func TestClient_TLSWithConfiguredCA(t *testing.T) {
+	ts, caFile := newTLSAgent(t)
+
+	tlsCfg, err := acp.LoadTLSConfig(caFile, false)
+	if err != nil {
+		t.Fatalf("LoadTLSConfig: %v", err)
+	}
+	resp, err := acp.New(ts.URL, acp.Options{TLSConfig: tlsCfg}).Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health over TLS: %v", err)
+	}
+	if resp.AgentID != "tls-agent" {
+		t.Errorf("agent_id = %q, want tls-agent", resp.AgentID)
+	}
+}
+
+func TestClient_TLSUntrustedCertFails(t *testing.T) {
+	ts, _ := newTLSAgent(t)
+
+	if _, err := acp.New(ts.URL).Health(context.Background()); err == nil {
+		t.Fatal("expected certificate verification error without the CA")
+	}
+}
+
+func TestConfigureTLS_AppliesToNewClients(t *testing.T) {
+	ts, caFile := newTLSAgent(t)
+	t.Cleanup(func() { _ = acp.ConfigureTLS("", false) })
+
+	if err := acp.ConfigureTLS(caFile, false); err != nil {
+		t.Fatalf("ConfigureTLS: %v", err)
+	}
+	if _, err := acp.New(ts.URL).Health(context.Background()); err != nil {
+		t.Fatalf("Health with process-wide CA: %v", err)
+	}
+
+	if err := acp.ConfigureTLS("", true); err != nil {
+		t.Fatalf("ConfigureTLS skip-verify: %v", err)
+	}
+	if _, err := acp.New(ts.URL).Health(context.Background()); err != nil {
+		t.Fatalf("Health with skip-verify: %v", err)
+	}
+}
+
+func TestLoadTLSConfig_Errors(t *testing.T) {
+	if cfg, err := acp.LoadTLSConfig("", false); cfg != nil || err != nil {
+		t.Errorf("no options: got (%v, %v), want (nil, nil)", cfg, err)
+	}
+	if _, err := acp.LoadTLSConfig(filepath.Join(t.TempDir(), "missing.pem"), false); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+	bad := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(bad, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acp.LoadTLSConfig(bad, false); err == nil {
+		t.Error("expected error for CA file without certificates")
+	}
+}
@@ -0,0 +1,241 @@
+package acp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/websocket"
+
+	acpspec "github.com/bdobrica/Ruriko/common/spec/acp"
+)
+
+// ErrTunnelClosed is returned for requests in flight when the agent's
+// control tunnel disconnects.
+var ErrTunnelClosed = errors.New("acp tunnel closed")
+
+// Tunnel multiplexes ACP requests over an agent-initiated WebSocket.
+//
+// Agents behind NAT cannot be reached by Ruriko's HTTP pushes, so they dial
+// Ruriko instead and keep the connection open. Each ACP request is sent as an
+// acpspec.TunnelFrame and matched to the agent's response frame by ID.
+// Tunnel implements http.RoundTripper so the regular Client is used as-is.
+type Tunnel struct {
+	conn *websocket.Conn
+
+	sendMu sync.Mutex
+	nextID atomic.Uint64
+
+	mu      sync.Mutex
+	pending map[string]chan acpspec.TunnelFrame
+	closed  bool
+	done    chan struct{}
+}
+
+// NewTunnel wraps an accepted WebSocket connection. Call Serve to start
+// reading responses.
+func NewTunnel(conn *websocket.Conn) *Tunnel {
+	return &Tunnel{
+		conn:    conn,
+		pending: make(map[string]chan acpspec.TunnelFrame),
+		done:    make(chan struct{}),
+	}
+}
+
+// Serve reads response frames until the connection fails or is closed.
+// Pending requests are failed with ErrTunnelClosed on return.
+func (t *Tunnel) Serve() error {
+	defer t.Close()
+	for {
+		var f acpspec.TunnelFrame
+		if err := websocket.JSON.Receive(t.conn, &f); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("tunnel receive: %w", err)
+		}
+		t.mu.Lock()
+		ch, ok := t.pending[f.ID]
+		delete(t.pending, f.ID)
+		t.mu.Unlock()
+		if ok {
+			ch <- f
+		}
+	}
+}
+
+// Done is closed when the tunnel shuts down.
+func (t *Tunnel) Done() <-chan struct{} { return t.done }
+
+// Close closes the underlying connection and fails pending requests.
+func (t *Tunnel) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.pending = nil
+	close(t.done)
+	t.mu.Unlock()
+	return t.conn.Close()
+}
+
+// RoundTrip sends req to the agent over the tunnel and waits for the
+// response frame or for req's context to end.
+func (t *Tunnel) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		body = b
+	}
+
+	id := strconv.FormatUint(t.nextID.Add(1), 10)
+	frame := acpspec.TunnelFrame{
+		ID:     id,
+		Method: req.Method,
+		Path:   req.URL.RequestURI(),
+		Header: flattenHeader(req.Header),
+		Body:   body,
+	}
+
+	ch := make(chan acpspec.TunnelFrame, 1)
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, ErrTunnelClosed
+	}
+	t.pending[id] = ch
+	t.mu.Unlock()
+
+	t.sendMu.Lock()
+	err := websocket.JSON.Send(t.conn, frame)
+	t.sendMu.Unlock()
+	if err != nil {
+		t.forget(id)
+		return nil, fmt.Errorf("tunnel send: %w", err)
+	}
+
+	select {
+	case resp := <-ch:
+		h := make(http.Header, len(resp.Header))
+		for k, v := range resp.Header {
+			h.Set(k, v)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+			StatusCode:    resp.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        h,
+			Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+			ContentLength: int64(len(resp.Body)),
+			Request:       req,
+		}, nil
+	case <-t.done:
+		return nil, ErrTunnelClosed
+	case <-req.Context().Done():
+		t.forget(id)
+		return nil, req.Context().Err()
+	}
+}
+
+func (t *Tunnel) forget(id string) {
+	t.mu.Lock()
+	if t.pending != nil {
+		delete(t.pending, id)
+	}
+	t.mu.Unlock()
+}
+
+// flattenHeader keeps the first value of each header; ACP only relies on
+// single-valued headers (Authorization, Content-Type, X-Request-ID, …).
+func flattenHeader(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if len(v) > 0 {
+			out[k] = v[0]
+		}
+	}
+	return out
+}
+
+// TunnelRegistry maps agent control URLs to their connected tunnels.
+type TunnelRegistry struct {
+	mu      sync.RWMutex
+	tunnels map[string]*Tunnel
+}
+
+// NewTunnelRegistry returns an empty registry.
+func NewTunnelRegistry() *TunnelRegistry {
+	return &TunnelRegistry{tunnels: make(map[string]*Tunnel)}
+}
+
+// Tunnels is the registry consulted by every Client. When a tunnel is
+// registered for a client's base URL, requests go over the tunnel; otherwise
+// they fall back to plain HTTP.
+var Tunnels = NewTunnelRegistry()
+
+// Register routes requests for controlURL through t, replacing any previous
+// tunnel for the same URL.
+func (r *TunnelRegistry) Register(controlURL string, t *Tunnel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tunnels[tunnelKey(controlURL)] = t
+}
+
+// Unregister removes t for controlURL. It is a no-op when a newer tunnel has
+// since replaced t.
+func (r *TunnelRegistry) Unregister(controlURL string, t *Tunnel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := tunnelKey(controlURL)
+	if r.tunnels[key] == t {
+		delete(r.tunnels, key)
+	}
+}
+
+// Lookup returns the tunnel for controlURL, or nil.
+func (r *TunnelRegistry) Lookup(controlURL string) *Tunnel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tunnels[tunnelKey(controlURL)]
+}
+
+// tunnelKey normalises a control URL to "scheme://host".
+func tunnelKey(controlURL string) string {
+	u, err := url.Parse(controlURL)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimRight(controlURL, "/"))
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// tunnelTransport sends requests over a registered tunnel when one exists
+// for the target host and falls back to HTTP otherwise.
+type tunnelTransport struct {
+	registry *TunnelRegistry
+	fallback http.RoundTripper
+}
+
+func (tt tunnelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t := tt.registry.Lookup(req.URL.Scheme + "://" + req.URL.Host); t != nil {
+		return t.RoundTrip(req)
+	}
+	return tt.fallback.RoundTrip(req)
+}
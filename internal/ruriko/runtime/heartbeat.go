@@ -0,0 +1,256 @@
+package runtime
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// Liveness is the operator-facing reachability state of an agent, derived
+// from how long ago it was last seen.
+type Liveness string
+
+const (
+	// LivenessOnline means the agent answered a heartbeat recently.
+	LivenessOnline Liveness = "online"
+	// LivenessStale means heartbeats have lapsed for longer than the stale
+	// threshold but not yet the offline threshold.
+	LivenessStale Liveness = "stale"
+	// LivenessOffline means no heartbeat has succeeded within the offline
+	// threshold.
+	LivenessOffline Liveness = "offline"
+	// LivenessUnknown means the agent has never been seen.
+	LivenessUnknown Liveness = "unknown"
+)
+
+// LivenessThresholds controls how last_seen ages map to a Liveness.
+type LivenessThresholds struct {
+	// Stale is the age after which an agent is reported as stale.
+	Stale time.Duration
+	// Offline is the age after which an agent is reported as offline.
+	Offline time.Duration
+}
+
+// DefaultLivenessThresholds are used when no thresholds are configured.
+var DefaultLivenessThresholds = LivenessThresholds{
+	Stale:   2 * time.Minute,
+	Offline: 10 * time.Minute,
+}
+
+// withDefaults fills zero fields from DefaultLivenessThresholds.
+func (t LivenessThresholds) withDefaults() LivenessThresholds {
+	if t.Stale <= 0 {
+		t.Stale = DefaultLivenessThresholds.Stale
+	}
+	if t.Offline <= 0 {
+		t.Offline = DefaultLivenessThresholds.Offline
+	}
+	return t
+}
+
+// Classify returns the Liveness for an agent last seen at lastSeen, as
+// observed at now.
+func (t LivenessThresholds) Classify(lastSeen sql.NullTime, now time.Time) Liveness {
+	if !lastSeen.Valid {
+		return LivenessUnknown
+	}
+	t = t.withDefaults()
+	age := now.Sub(lastSeen.Time)
+	switch {
+	case age >= t.Offline:
+		return LivenessOffline
+	case age >= t.Stale:
+		return LivenessStale
+	default:
+		return LivenessOnline
+	}
+}
+
+// HeartbeatConfig configures the heartbeat monitor.
+type HeartbeatConfig struct {
+	// Interval is how often agents are polled. Defaults to 1m.
+	Interval time.Duration
+
+	// MaxBackoff caps the delay between polls of an agent whose heartbeats
+	// keep failing. Each consecutive failure doubles the delay, starting
+	// from Interval. Defaults to 10m.
+	MaxBackoff time.Duration
+
+	// ACPClientFactory builds the client used to call ACP GET /health.
+	// Pass NewACPChecker in production.
+	ACPClientFactory ACPClientFactory
+
+	// Thresholds classifies last_seen ages; transitions between states are
+	// reported through AlertFunc.
+	Thresholds LivenessThresholds
+
+	// AlertFunc is called when an agent's liveness changes between passes.
+	// If nil, transitions are only logged.
+	AlertFunc func(agentID, message string)
+}
+
+// agentBeat is the monitor's per-agent backoff and liveness bookkeeping.
+type agentBeat struct {
+	failures int
+	next     time.Time
+	liveness Liveness
+}
+
+// HeartbeatMonitor polls ACP GET /health on every enabled agent with a
+// known control URL and records last_seen and last_health_check on success.
+// Unlike the Reconciler it does not require a container runtime, so it also
+// covers agents running outside Ruriko's Docker host.
+type HeartbeatMonitor struct {
+	store *store.Store
+	cfg   HeartbeatConfig
+	now   func() time.Time
+
+	mu    sync.Mutex
+	beats map[string]*agentBeat
+}
+
+// NewHeartbeatMonitor creates a HeartbeatMonitor using the wall clock.
+func NewHeartbeatMonitor(s *store.Store, cfg HeartbeatConfig) *HeartbeatMonitor {
+	return NewHeartbeatMonitorWithClock(s, cfg, time.Now)
+}
+
+// NewHeartbeatMonitorWithClock creates a HeartbeatMonitor with an injectable
+// clock, for tests.
+func NewHeartbeatMonitorWithClock(s *store.Store, cfg HeartbeatConfig, now func() time.Time) *HeartbeatMonitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Minute
+	}
+	cfg.Thresholds = cfg.Thresholds.withDefaults()
+	return &HeartbeatMonitor{
+		store: s,
+		cfg:   cfg,
+		now:   now,
+		beats: make(map[string]*agentBeat),
+	}
+}
+
+// Run starts the heartbeat loop. Blocks until ctx is cancelled.
+func (m *HeartbeatMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	slog.Info("[heartbeat] starting", "interval", m.cfg.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("[heartbeat] stopping")
+			return
+		case <-ticker.C:
+			traceID := trace.GenerateID()
+			m.Poll(trace.WithTraceID(ctx, traceID))
+		}
+	}
+}
+
+// Poll runs a single heartbeat pass over all eligible agents. Agents in
+// backoff after failed heartbeats are skipped until their next attempt is due.
+func (m *HeartbeatMonitor) Poll(ctx context.Context) {
+	if m.cfg.ACPClientFactory == nil {
+		return
+	}
+	agents, err := m.store.ListAgents(ctx)
+	if err != nil {
+		slog.Error("[heartbeat] list agents", "err", err, "trace_id", trace.FromContext(ctx))
+		return
+	}
+
+	for _, agent := range agents {
+		if !agent.Enabled || !agent.ControlURL.Valid || agent.ControlURL.String == "" {
+			continue
+		}
+		beat := m.beat(agent.ID)
+		if now := m.now(); now.Before(beat.next) {
+			m.observe(agent.ID, beat, agent.LastSeen, now)
+			continue
+		}
+
+		checker := m.cfg.ACPClientFactory(agent.ControlURL.String, agent.ACPToken.String)
+		if _, err := checker.Health(ctx); err != nil {
+			beat.failures++
+			beat.next = m.now().Add(m.backoff(beat.failures))
+			slog.Debug("[heartbeat] health check failed",
+				"agent", agent.ID, "failures", beat.failures, "retry_at", beat.next,
+				"err", err, "trace_id", trace.FromContext(ctx))
+			m.observe(agent.ID, beat, agent.LastSeen, m.now())
+			continue
+		}
+
+		beat.failures = 0
+		beat.next = time.Time{}
+		if err := m.store.UpdateAgentLastSeen(ctx, agent.ID); err != nil {
+			slog.Warn("[heartbeat] failed to update last_seen", "agent", agent.ID, "err", err)
+		}
+		if err := m.store.UpdateAgentHealthCheck(ctx, agent.ID); err != nil {
+			slog.Warn("[heartbeat] failed to update last_health_check", "agent", agent.ID, "err", err)
+		}
+		now := m.now()
+		m.observe(agent.ID, beat, sql.NullTime{Time: now, Valid: true}, now)
+	}
+}
+
+// Liveness returns the liveness most recently observed for agentID, or
+// LivenessUnknown if the monitor has not polled it yet.
+func (m *HeartbeatMonitor) Liveness(agentID string) Liveness {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b, ok := m.beats[agentID]; ok && b.liveness != "" {
+		return b.liveness
+	}
+	return LivenessUnknown
+}
+
+func (m *HeartbeatMonitor) beat(agentID string) *agentBeat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.beats[agentID]
+	if !ok {
+		b = &agentBeat{}
+		m.beats[agentID] = b
+	}
+	return b
+}
+
+// observe classifies the agent and reports a transition when its liveness
+// differs from the previous pass.
+func (m *HeartbeatMonitor) observe(agentID string, beat *agentBeat, lastSeen sql.NullTime, now time.Time) {
+	state := m.cfg.Thresholds.Classify(lastSeen, now)
+	m.mu.Lock()
+	prev := beat.liveness
+	beat.liveness = state
+	m.mu.Unlock()
+
+	if prev == "" || prev == state {
+		return
+	}
+	slog.Info("[heartbeat] liveness change", "agent", agentID, "from", prev, "to", state)
+	msg := "liveness changed: " + string(prev) + " → " + string(state)
+	if m.cfg.AlertFunc != nil {
+		m.cfg.AlertFunc(agentID, msg)
+	}
+}
+
+// backoff returns the delay before the next poll after n consecutive failures.
+func (m *HeartbeatMonitor) backoff(n int) time.Duration {
+	d := m.cfg.Interval
+	for i := 1; i < n; i++ {
+		d *= 2
+		if d >= m.cfg.MaxBackoff {
+			return m.cfg.MaxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,174 @@
+package runtime_test
+
+// heartbeat_test.go — tests for the HeartbeatMonitor and liveness
+// classification (online / stale / offline).
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime"
+	appstore "github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// heartbeatClock is a manually advanced clock for the heartbeat monitor.
+type heartbeatClock struct{ t time.Time }
+
+func (c *heartbeatClock) now() time.Time          { return c.t }
+func (c *heartbeatClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestLivenessThresholds_Classify(t *testing.T) {
+	th := runtime.LivenessThresholds{Stale: time.Minute, Offline: 5 * time.Minute}
+	now := time.Now()
+	seen := func(ago time.Duration) sql.NullTime { return sql.NullTime{Time: now.Add(-ago), Valid: true} }
+
+	cases := []struct {
+		name     string
+		lastSeen sql.NullTime
+		want     runtime.Liveness
+	}{
+		{"never seen", sql.NullTime{}, runtime.LivenessUnknown},
+		{"just now", seen(0), runtime.LivenessOnline},
+		{"under stale", seen(59 * time.Second), runtime.LivenessOnline},
+		{"at stale", seen(time.Minute), runtime.LivenessStale},
+		{"under offline", seen(4 * time.Minute), runtime.LivenessStale},
+		{"at offline", seen(5 * time.Minute), runtime.LivenessOffline},
+	}
+	for _, tc := range cases {
+		if got := th.Classify(tc.lastSeen, now); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLivenessThresholds_ZeroUsesDefaults(t *testing.T) {
+	now := time.Now()
+	lastSeen := sql.NullTime{Time: now.Add(-runtime.DefaultLivenessThresholds.Stale), Valid: true}
+	if got := (runtime.LivenessThresholds{}).Classify(lastSeen, now); got != runtime.LivenessStale {
+		t.Errorf("got %s, want stale", got)
+	}
+}
+
+// TestHeartbeat_Transitions drives an agent through online → stale →
+// offline as heartbeats lapse, then back to online when they resume.
+func TestHeartbeat_Transitions(t *testing.T) {
+	s := newTestStore(t)
+	a := newHealthyAgent(t, s, "hb-agent")
+	mock := &mockACPChecker{}
+	clock := &heartbeatClock{t: time.Now()}
+
+	var alerts []string
+	mon := runtime.NewHeartbeatMonitorWithClock(s, runtime.HeartbeatConfig{
+		Interval:         time.Minute,
+		MaxBackoff:       time.Minute, // poll every pass so each step is observed
+		ACPClientFactory: makeACPFactory(mock),
+		Thresholds:       runtime.LivenessThresholds{Stale: 2 * time.Minute, Offline: 5 * time.Minute},
+		AlertFunc:        func(_, msg string) { alerts = append(alerts, msg) },
+	}, clock.now)
+	ctx := context.Background()
+
+	mon.Poll(ctx)
+	if got := mon.Liveness(a.ID); got != runtime.LivenessOnline {
+		t.Fatalf("after successful heartbeat: got %s, want online", got)
+	}
+	got, err := s.GetAgent(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if !got.LastSeen.Valid || !got.LastHealthCheck.Valid {
+		t.Fatal("expected last_seen and last_health_check to be recorded")
+	}
+
+	mock.healthErr = errors.New("connection refused")
+	clock.advance(3 * time.Minute)
+	mon.Poll(ctx)
+	if got := mon.Liveness(a.ID); got != runtime.LivenessStale {
+		t.Fatalf("after lapsed heartbeats: got %s, want stale", got)
+	}
+
+	clock.advance(3 * time.Minute)
+	mon.Poll(ctx)
+	if got := mon.Liveness(a.ID); got != runtime.LivenessOffline {
+		t.Fatalf("after long outage: got %s, want offline", got)
+	}
+
+	mock.healthErr = nil
+	clock.advance(time.Minute)
+	mon.Poll(ctx)
+	if got := mon.Liveness(a.ID); got != runtime.LivenessOnline {
+		t.Fatalf("after recovery: got %s, want online", got)
+	}
+
+	want := []string{
+		"liveness changed: online → stale",
+		"liveness changed: stale → offline",
+		"liveness changed: offline → online",
+	}
+	if len(alerts) != len(want) {
+		t.Fatalf("alerts = %v, want %v", alerts, want)
+	}
+	for i := range want {
+		if alerts[i] != want[i] {
+			t.Errorf("alert[%d] = %q, want %q", i, alerts[i], want[i])
+		}
+	}
+}
+
+// TestHeartbeat_BackoffSkipsFailingAgent verifies that consecutive failures
+// push the next poll further out instead of hammering an unreachable agent.
+func TestHeartbeat_BackoffSkipsFailingAgent(t *testing.T) {
+	s := newTestStore(t)
+	newHealthyAgent(t, s, "down-agent")
+	mock := &mockACPChecker{healthErr: errors.New("timeout")}
+	clock := &heartbeatClock{t: time.Now()}
+
+	mon := runtime.NewHeartbeatMonitorWithClock(s, runtime.HeartbeatConfig{
+		Interval:         time.Minute,
+		MaxBackoff:       10 * time.Minute,
+		ACPClientFactory: makeACPFactory(mock),
+	}, clock.now)
+	ctx := context.Background()
+
+	mon.Poll(ctx) // failure 1 → next attempt in 1m
+	clock.advance(time.Minute)
+	mon.Poll(ctx) // failure 2 → next attempt in 2m
+	if mock.healthCalls != 2 {
+		t.Fatalf("expected 2 health calls, got %d", mock.healthCalls)
+	}
+
+	clock.advance(time.Minute)
+	mon.Poll(ctx) // still backing off
+	if mock.healthCalls != 2 {
+		t.Fatalf("expected poll to be skipped during backoff, got %d calls", mock.healthCalls)
+	}
+
+	clock.advance(time.Minute)
+	mon.Poll(ctx) // backoff elapsed
+	if mock.healthCalls != 3 {
+		t.Fatalf("expected poll after backoff, got %d calls", mock.healthCalls)
+	}
+}
+
+// TestHeartbeat_SkipsAgentsWithoutControlURL verifies that agents Ruriko
+// cannot reach are not polled.
+func TestHeartbeat_SkipsAgentsWithoutControlURL(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.CreateAgent(context.Background(), &appstore.Agent{
+		ID: "no-url", DisplayName: "no-url", Template: "cron", Status: "running",
+	}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	mock := &mockACPChecker{}
+	mon := runtime.NewHeartbeatMonitor(s, runtime.HeartbeatConfig{ACPClientFactory: makeACPFactory(mock)})
+
+	mon.Poll(context.Background())
+	if mock.healthCalls != 0 {
+		t.Errorf("expected no health calls, got %d", mock.healthCalls)
+	}
+	if got := mon.Liveness("no-url"); got != runtime.LivenessUnknown {
+		t.Errorf("got %s, want unknown", got)
+	}
+}
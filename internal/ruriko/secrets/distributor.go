@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"log/slog"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/bdobrica/Ruriko/common/retry"
+	"github.com/bdobrica/Ruriko/common/spec/gosuto"
 	"github.com/bdobrica/Ruriko/common/trace"
 	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
 	"github.com/bdobrica/Ruriko/internal/ruriko/store"
@@ -74,10 +77,41 @@ func (d *Distributor) PushToAgent(ctx context.Context, agentID string) (int, err
 
 // --- token-based distribution (R4.2) ----------------------------------------
 
+// cacheTTLs returns the per-secret cache TTL overrides (in seconds) declared
+// in the agent's latest Gosuto config. It returns nil when the agent has no
+// config or it cannot be parsed; leases then carry no override.
+func (d *Distributor) cacheTTLs(ctx context.Context, agentID string) map[string]int {
+	gv, err := d.store.GetLatestGosutoVersion(ctx, agentID)
+	if err != nil {
+		return nil
+	}
+	var cfg gosuto.Config
+	if err := yaml.Unmarshal([]byte(gv.YAMLBlob), &cfg); err != nil {
+		slog.Warn("distributor: cannot parse gosuto for secret TTLs", "agent", agentID, "err", err)
+		return nil
+	}
+	ttls := make(map[string]int)
+	for _, ref := range cfg.Secrets {
+		if ref.CacheTTLSeconds > 0 {
+			ttls[ref.Name] = ref.CacheTTLSeconds
+		}
+	}
+	return ttls
+}
+
+// leaseRedeemRetries is how many times the distributor re-issues leases the
+// agent reported as failed to redeem before giving up on them. It is kept
+// small so a persistently failing secret cannot loop.
+const leaseRedeemRetries = 1
+
 // distributeViaTokens issues a Kuze token per bound secret and sends the
 // list of {secret_ref, redemption_token, kuze_url} leases to the agent via
 // POST /secrets/token. The agent redeems each token from Kuze to obtain
 // the plaintext value.
+//
+// When the agent reports that some leases failed to redeem, fresh tokens are
+// issued for just those refs and sent again, up to leaseRedeemRetries times,
+// before the failures are returned to the caller.
 func (d *Distributor) distributeViaTokens(ctx context.Context, agentID string) (int, error) {
 	traceID := trace.FromContext(ctx)
 
@@ -99,59 +133,82 @@ func (d *Distributor) distributeViaTokens(ctx context.Context, agentID string) (
 		return 0, nil
 	}
 
-	// Issue a Kuze token for each bound secret.
-	var leases []acp.SecretLease
-	var errs []error
-	issuedRefs := make(map[string]bool) // track which refs were issued
+	ttls := d.cacheTTLs(ctx, agentID)
+	client := acp.New(controlURL, acp.Options{Token: acpToken})
 
+	pending := make([]string, 0, len(bindings))
 	for _, b := range bindings {
-		// Resolve secret type for proper token scoping.
-		meta, err := d.secrets.GetMetadata(ctx, b.SecretName)
-		if err != nil {
-			slog.Warn("distributor: failed to get secret metadata",
-				"agent", agentID, "secret", b.SecretName, "err", err)
-			errs = append(errs, fmt.Errorf("metadata %q: %w", b.SecretName, err))
-			continue
-		}
+		pending = append(pending, b.SecretName)
+	}
+	var errs []error
+	applied := make(map[string]bool) // refs the agent redeemed and applied
+	var failed []acp.SecretLeaseFailure
 
-		result, err := d.kuze.IssueAgentToken(ctx, agentID, b.SecretName, string(meta.Type), "distribution")
-		if err != nil {
-			slog.Warn("distributor: failed to issue Kuze token",
-				"agent", agentID, "secret", b.SecretName, "err", err)
-			errs = append(errs, fmt.Errorf("issue token %q: %w", b.SecretName, err))
-			continue
+	for attempt := 0; ; attempt++ {
+		leases, issueErrs := d.issueLeases(ctx, agentID, pending, ttls)
+		errs = append(errs, issueErrs...)
+		if len(leases) == 0 {
+			if attempt == 0 && len(errs) > 0 {
+				return 0, fmt.Errorf("all secrets failed to issue tokens: %v", errs[0])
+			}
+			// The re-lease issue errors already name the failed refs.
+			failed = nil
+			break
 		}
 
-		leases = append(leases, acp.SecretLease{
-			SecretRef:       result.SecretRef,
-			RedemptionToken: result.Token,
-			KuzeURL:         result.RedeemURL,
+		slog.Info("distributing secrets via Kuze tokens",
+			"agent", agentID, "count", len(leases), "attempt", attempt+1, "trace", traceID)
+
+		var resp *acp.SecretsTokenResponse
+		sendErr := retry.Do(ctx, retry.DefaultConfig, func() error {
+			var err error
+			resp, err = client.ApplySecretsToken(ctx, acp.SecretsTokenRequest{Leases: leases})
+			return err
 		})
-		issuedRefs[b.SecretName] = true
-	}
+		if sendErr != nil {
+			if attempt == 0 {
+				return 0, fmt.Errorf("ACP /secrets/token: %w", sendErr)
+			}
+			errs = append(errs, fmt.Errorf("ACP /secrets/token (re-lease): %w", sendErr))
+			break
+		}
 
-	if len(leases) == 0 {
-		if len(errs) > 0 {
-			return 0, fmt.Errorf("all secrets failed to issue tokens: %v", errs[0])
+		failedRefs := make(map[string]bool, len(resp.Failed))
+		for _, f := range resp.Failed {
+			failedRefs[f.SecretRef] = true
 		}
-		return 0, nil
+		failed = resp.Failed
+		pending = pending[:0]
+		for _, l := range leases {
+			if failedRefs[l.SecretRef] {
+				pending = append(pending, l.SecretRef)
+			} else {
+				applied[l.SecretRef] = true
+			}
+		}
+		if len(pending) == 0 {
+			failed = nil
+			break
+		}
+		if attempt >= leaseRedeemRetries {
+			break
+		}
+		slog.Warn("distributor: re-issuing leases the agent failed to redeem",
+			"agent", agentID, "refs", pending, "trace", traceID)
 	}
 
-	slog.Info("distributing secrets via Kuze tokens",
-		"agent", agentID, "count", len(leases), "trace", traceID)
-
-	client := acp.New(controlURL, acp.Options{Token: acpToken})
-	sendErr := retry.Do(ctx, retry.DefaultConfig, func() error {
-		return client.ApplySecretsToken(ctx, acp.SecretsTokenRequest{Leases: leases})
-	})
-	if sendErr != nil {
-		return 0, fmt.Errorf("ACP /secrets/token: %w", sendErr)
+	// Leases the agent could not redeem are not marked pushed, so they stay
+	// stale and are sent again later.
+	for _, f := range failed {
+		slog.Warn("distributor: agent failed to redeem secret lease",
+			"agent", agentID, "secret", f.SecretRef, "err", f.Error)
+		errs = append(errs, fmt.Errorf("redeem %q: %s", f.SecretRef, f.Error))
 	}
 
-	// Mark pushed for each issued secret.
+	// Mark pushed for each applied secret.
 	pushed := 0
 	for _, b := range bindings {
-		if !issuedRefs[b.SecretName] {
+		if !applied[b.SecretName] {
 			continue
 		}
 		if err := d.secrets.MarkPushed(ctx, agentID, b.SecretName); err != nil {
@@ -163,11 +220,44 @@ func (d *Distributor) distributeViaTokens(ctx context.Context, agentID string) (
 	}
 
 	if len(errs) > 0 {
-		return pushed, fmt.Errorf("%d secret(s) failed to issue tokens: %v", len(errs), errs[0])
+		return pushed, fmt.Errorf("%d secret(s) failed to distribute: %v", len(errs), errs[0])
 	}
 	return pushed, nil
 }
 
+// issueLeases issues a Kuze token for each named secret. Secrets whose
+// metadata or token cannot be obtained are skipped and reported in errs.
+func (d *Distributor) issueLeases(ctx context.Context, agentID string, names []string, ttls map[string]int) ([]acp.SecretLease, []error) {
+	var leases []acp.SecretLease
+	var errs []error
+	for _, name := range names {
+		// Resolve secret type for proper token scoping.
+		meta, err := d.secrets.GetMetadata(ctx, name)
+		if err != nil {
+			slog.Warn("distributor: failed to get secret metadata",
+				"agent", agentID, "secret", name, "err", err)
+			errs = append(errs, fmt.Errorf("metadata %q: %w", name, err))
+			continue
+		}
+
+		result, err := d.kuze.IssueAgentToken(ctx, agentID, name, string(meta.Type), "distribution")
+		if err != nil {
+			slog.Warn("distributor: failed to issue Kuze token",
+				"agent", agentID, "secret", name, "err", err)
+			errs = append(errs, fmt.Errorf("issue token %q: %w", name, err))
+			continue
+		}
+
+		leases = append(leases, acp.SecretLease{
+			SecretRef:       result.SecretRef,
+			RedemptionToken: result.Token,
+			KuzeURL:         result.RedeemURL,
+			TTLSeconds:      ttls[name],
+		})
+	}
+	return leases, errs
+}
+
 // --- legacy direct push (pre-R4.2, will be gated/removed via R4.4) ----------
 
 // pushRaw is the legacy direct-push path: decrypts each secret and sends
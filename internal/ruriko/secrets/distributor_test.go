@@ -0,0 +1,160 @@
+package secrets_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bdobrica/Ruriko/internal/ruriko/runtime/acp"
+	"github.com/bdobrica/Ruriko/internal/ruriko/secrets"
+	appstore "github.com/bdobrica/Ruriko/internal/ruriko/store"
+)
+
+// countingIssuer issues numbered fake Kuze tokens and counts them per ref.
+type countingIssuer struct {
+	mu     sync.Mutex
+	issued map[string]int
+}
+
+func (i *countingIssuer) IssueAgentToken(_ context.Context, _, secretRef, _, _ string) (*secrets.TokenLeaseResult, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.issued == nil {
+		i.issued = make(map[string]int)
+	}
+	i.issued[secretRef]++
+	tok := fmt.Sprintf("tok-%s-%d", secretRef, i.issued[secretRef])
+	return &secrets.TokenLeaseResult{
+		RedeemURL: "http://kuze.local/kuze/redeem/" + tok,
+		SecretRef: secretRef,
+		Token:     tok,
+	}, nil
+}
+
+// partialAgent is a fake ACP /secrets/token endpoint that reports the refs
+// in failFor as failed for the first failTimes requests that carry them.
+type partialAgent struct {
+	mu        sync.Mutex
+	failFor   map[string]bool
+	failTimes int
+	requests  [][]string
+}
+
+func (a *partialAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req acp.SecretsTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.mu.Lock()
+	var refs []string
+	for _, l := range req.Leases {
+		refs = append(refs, l.SecretRef)
+	}
+	a.requests = append(a.requests, refs)
+	fail := len(a.requests) <= a.failTimes
+	a.mu.Unlock()
+
+	resp := acp.SecretsTokenResponse{Applied: []string{}}
+	for _, l := range req.Leases {
+		if fail && a.failFor[l.SecretRef] {
+			resp.Failed = append(resp.Failed, acp.SecretLeaseFailure{SecretRef: l.SecretRef, Error: "kuze redeem returned 410"})
+		} else {
+			resp.Applied = append(resp.Applied, l.SecretRef)
+		}
+	}
+	status := http.StatusOK
+	if len(resp.Failed) > 0 {
+		status = http.StatusMultiStatus
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// newDistributorFixture creates an agent reachable at agentURL with two
+// bound secrets, "key-a" and "key-b".
+func newDistributorFixture(t *testing.T, agentURL string) (*secrets.Store, *countingIssuer, *secrets.Distributor) {
+	t.Helper()
+	sec, s := newTestSecrets(t)
+	ctx := context.Background()
+	if err := s.CreateAgent(ctx, &appstore.Agent{
+		ID: "agent-dist", DisplayName: "Dist Agent", Template: "cron", Status: "running",
+		ControlURL: sql.NullString{String: agentURL, Valid: true},
+	}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	for _, name := range []string{"key-a", "key-b"} {
+		if err := sec.Set(ctx, name, secrets.TypeAPIKey, []byte("v-"+name)); err != nil {
+			t.Fatalf("Set %s: %v", name, err)
+		}
+		if err := sec.Bind(ctx, "agent-dist", name, "read"); err != nil {
+			t.Fatalf("Bind %s: %v", name, err)
+		}
+	}
+	issuer := &countingIssuer{}
+	return sec, issuer, secrets.NewDistributorWithKuze(sec, s, issuer)
+}
+
+func staleNames(t *testing.T, sec *secrets.Store) []string {
+	t.Helper()
+	stale, err := sec.StaleBindings(context.Background())
+	if err != nil {
+		t.Fatalf("StaleBindings: %v", err)
+	}
+	var names []string
+	for _, b := range stale {
+		names = append(names, b.SecretName)
+	}
+	return names
+}
+
+func TestDistributor_ReLeasesPartialFailure(t *testing.T) {
+	agent := &partialAgent{failFor: map[string]bool{"key-b": true}, failTimes: 1}
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+	sec, issuer, d := newDistributorFixture(t, ts.URL)
+
+	pushed, err := d.PushToAgent(context.Background(), "agent-dist")
+	if err != nil {
+		t.Fatalf("PushToAgent: %v", err)
+	}
+	if pushed != 2 {
+		t.Errorf("pushed = %d, want 2", pushed)
+	}
+	if len(agent.requests) != 2 || len(agent.requests[1]) != 1 || agent.requests[1][0] != "key-b" {
+		t.Errorf("agent requests = %v, want a re-push of key-b only", agent.requests)
+	}
+	if issuer.issued["key-a"] != 1 || issuer.issued["key-b"] != 2 {
+		t.Errorf("tokens issued = %v, want key-a once and key-b twice", issuer.issued)
+	}
+	if stale := staleNames(t, sec); len(stale) != 0 {
+		t.Errorf("stale bindings = %v, want none", stale)
+	}
+}
+
+func TestDistributor_ReLeaseIsBounded(t *testing.T) {
+	agent := &partialAgent{failFor: map[string]bool{"key-b": true}, failTimes: 100}
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+	sec, issuer, d := newDistributorFixture(t, ts.URL)
+
+	pushed, err := d.PushToAgent(context.Background(), "agent-dist")
+	if err == nil {
+		t.Fatal("expected an error for the persistently failing ref")
+	}
+	if pushed != 1 {
+		t.Errorf("pushed = %d, want 1", pushed)
+	}
+	if len(agent.requests) != 2 || issuer.issued["key-b"] != 2 {
+		t.Errorf("agent requests = %v, tokens = %v; want one initial push and one re-lease", agent.requests, issuer.issued)
+	}
+	if stale := staleNames(t, sec); len(stale) != 1 || stale[0] != "key-b" {
+		t.Errorf("stale bindings = %v, want [key-b]", stale)
+	}
+}
@@ -209,6 +209,36 @@ func (s *Store) UpdateAgentHandle(ctx context.Context, id, containerID, controlU
 	return nil
 }
 
+// UpdateAgentRegistration records the details an agent reported through
+// self-registration: its reachable control URL, ACP token, runtime version and
+// currently applied Gosuto hash. last_seen is bumped as well. An empty
+// acpToken, runtimeVersion or gosutoHash leaves the stored value unchanged.
+func (s *Store) UpdateAgentRegistration(ctx context.Context, id, controlURL, acpToken, runtimeVersion, gosutoHash string) error {
+	now := time.Now()
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE agents
+		SET control_url = ?,
+		    acp_token = COALESCE(NULLIF(?, ''), acp_token),
+		    runtime_version = COALESCE(NULLIF(?, ''), runtime_version),
+		    actual_gosuto_hash = COALESCE(NULLIF(?, ''), actual_gosuto_hash),
+		    last_seen = ?, updated_at = ?
+		WHERE id = ?
+	`, controlURL, acpToken, runtimeVersion, gosutoHash, now, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to update agent registration: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("agent not found: %s", id)
+	}
+
+	return nil
+}
+
 // UpdateAgentProvisioningState updates the fine-grained provisioning pipeline
 // state for an agent (R5.2).
 //
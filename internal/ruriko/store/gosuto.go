@@ -140,6 +140,38 @@ func (s *Store) ListGosutoVersions(ctx context.Context, agentID string) ([]*Gosu
 	return versions, nil
 }
 
+// FindGosutoVersionsByHash returns the versions of an agent whose hash
+// starts with prefix, newest first. Rollbacks re-save old content, so several
+// versions may share one hash. prefix must already be validated as hex.
+func (s *Store) FindGosutoVersionsByHash(ctx context.Context, agentID, prefix string) ([]*GosutoVersion, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, agent_id, version, hash, yaml_blob, created_at, created_by_mxid
+		FROM gosuto_versions
+		WHERE agent_id = ? AND hash LIKE ? || '%'
+		ORDER BY version DESC
+	`, agentID, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("query gosuto_versions by hash: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*GosutoVersion
+	for rows.Next() {
+		gv := &GosutoVersion{}
+		if err := rows.Scan(
+			&gv.ID, &gv.AgentID, &gv.Version, &gv.Hash, &gv.YAMLBlob,
+			&gv.CreatedAt, &gv.CreatedByMXID,
+		); err != nil {
+			return nil, fmt.Errorf("scan gosuto_version: %w", err)
+		}
+		versions = append(versions, gv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate gosuto_versions: %w", err)
+	}
+	return versions, nil
+}
+
 // PruneGosutoVersions deletes old versions for an agent, keeping at most
 // keepN most recent versions. If keepN <= 0, nothing is deleted.
 func (s *Store) PruneGosutoVersions(ctx context.Context, agentID string, keepN int) error {
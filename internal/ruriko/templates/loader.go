@@ -191,6 +191,10 @@ type TemplateInfo struct {
 	// Description is the human-readable description from metadata.description,
 	// trimmed of surrounding whitespace and newlines.
 	Description string
+
+	// Image is the default container image from metadata.image. Empty when
+	// the template does not declare one.
+	Image string
 }
 
 // Describe extracts the TemplateInfo for a single named template by rendering
@@ -218,6 +222,7 @@ func (r *Registry) Describe(name string) (*TemplateInfo, error) {
 		Name:          name,
 		CanonicalName: cfg.Metadata.CanonicalName,
 		Description:   strings.TrimSpace(cfg.Metadata.Description),
+		Image:         strings.TrimSpace(cfg.Metadata.Image),
 	}, nil
 }
 
@@ -431,6 +431,7 @@ metadata:
   name: "{{.AgentName}}"
   template: test-agent
   canonicalName: tester
+  image: registry.example.com/tester:1.2
   description: >
     A test agent used in unit tests.
 trust:
@@ -471,6 +472,9 @@ func TestRegistry_Describe_ExtractsMetadata(t *testing.T) {
 	if !strings.Contains(info.Description, "A test agent") {
 		t.Errorf("Description does not contain expected text: %q", info.Description)
 	}
+	if info.Image != "registry.example.com/tester:1.2" {
+		t.Errorf("Image: got %q, want %q", info.Image, "registry.example.com/tester:1.2")
+	}
 }
 
 func TestRegistry_Describe_EmptyCanonicalNameWhenAbsent(t *testing.T) {
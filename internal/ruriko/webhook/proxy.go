@@ -12,7 +12,9 @@
 //
 // The forwarded request carries the agent's own ACP bearer token so the Gitai
 // server can authenticate it. The agent's response status is propagated back
-// to the webhook sender.
+// to the webhook sender. An X-Trace-ID header from the sender is passed on to
+// the agent (one is generated when absent) and returned in the response, so
+// a delivery can be followed from Ruriko's logs into the agent's.
 //
 // Authentication modes (configured per gateway in Gosuto):
 //   - "bearer" (default): Authorization: Bearer header must match the agent's ACP token.
@@ -32,6 +34,7 @@ import (
 	"time"
 
 	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/common/trace"
 	"github.com/bdobrica/Ruriko/common/webhookauth"
 	"github.com/bdobrica/Ruriko/internal/ruriko/store"
 )
@@ -116,7 +119,12 @@ func (p *Proxy) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 	agentID, source := parts[0], parts[1]
 
-	ctx := r.Context()
+	traceID := r.Header.Get(trace.Header)
+	if !trace.ValidID(traceID) {
+		traceID = trace.GenerateID()
+	}
+	w.Header().Set(trace.Header, traceID)
+	ctx := trace.WithTraceID(r.Context(), traceID)
 
 	// Validate the agent exists, is enabled, and has an ACP control URL.
 	agent, err := p.store.GetAgent(ctx, agentID)
@@ -194,22 +202,30 @@ func (p *Proxy) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	if contentType == "" {
 		contentType = "application/json"
 	}
+	header := http.Header{"Content-Type": {contentType}}
+	// A typeFrom header (e.g. "header:X-GitHub-Event") is read by the agent,
+	// so it must survive the hop.
+	if tf, err := gosutospec.ParseWebhookTypeFrom(gw.Config["typeFrom"]); err == nil && tf.Header != "" {
+		if v := r.Header.Get(tf.Header); v != "" {
+			header.Set(tf.Header, v)
+		}
+	}
 
 	acpToken := ""
 	if agent.ACPToken.Valid {
 		acpToken = agent.ACPToken.String
 	}
 
-	status, err := p.forward(ctx, acpURL, acpToken, body, contentType)
+	status, err := p.forward(ctx, acpURL, acpToken, body, header)
 	if err != nil {
 		slog.Error("webhook: forward to agent failed",
-			"agent", agentID, "source", source, "acp_url", acpURL, "err", err)
+			"agent", agentID, "source", source, "acp_url", acpURL, "trace_id", traceID, "err", err)
 		http.Error(w, "failed to forward to agent", http.StatusBadGateway)
 		return
 	}
 
 	slog.Info("webhook: forwarded",
-		"agent", agentID, "source", source, "acp_url", acpURL, "acp_status", status)
+		"agent", agentID, "source", source, "acp_url", acpURL, "acp_status", status, "trace_id", traceID)
 
 	// Propagate the agent's response status to the webhook sender.
 	w.WriteHeader(status)
@@ -283,15 +299,18 @@ func (p *Proxy) validateHMAC(ctx context.Context, r *http.Request, body []byte,
 	return nil
 }
 
-// forward sends body to acpURL as a POST request carrying the agent's bearer
-// token, and returns the HTTP response status code. The response body is
-// drained and discarded.
-func (p *Proxy) forward(ctx context.Context, acpURL, token string, body []byte, contentType string) (int, error) {
+// forward sends body to acpURL as a POST request carrying header, the
+// agent's bearer token and the trace ID from ctx, and returns the HTTP
+// response status code. The response body is drained and discarded.
+func (p *Proxy) forward(ctx context.Context, acpURL, token string, body []byte, header http.Header) (int, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, acpURL, bytes.NewReader(body))
 	if err != nil {
 		return 0, fmt.Errorf("build forward request: %w", err)
 	}
-	req.Header.Set("Content-Type", contentType)
+	req.Header = header.Clone()
+	if traceID := trace.FromContext(ctx); traceID != "" {
+		req.Header.Set(trace.Header, traceID)
+	}
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
@@ -11,7 +11,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/bdobrica/Ruriko/common/spec/envelope"
+	gosutospec "github.com/bdobrica/Ruriko/common/spec/gosuto"
+	"github.com/bdobrica/Ruriko/common/trace"
+	"github.com/bdobrica/Ruriko/internal/gitai/control"
 	"github.com/bdobrica/Ruriko/internal/ruriko/store"
 	"github.com/bdobrica/Ruriko/internal/ruriko/webhook"
 )
@@ -165,6 +170,43 @@ func TestWebhookProxy_HappyPath_Bearer(t *testing.T) {
 	}
 }
 
+// TestWebhookProxy_ForwardsTypeFromHeader verifies that the header named by
+// a gateway's typeFrom reaches the agent, and other inbound headers do not.
+func TestWebhookProxy_ForwardsTypeFromHeader(t *testing.T) {
+	const token = "secret-acp-token"
+	got := make(chan http.Header, 1)
+	acpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Clone()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer acpSrv.Close()
+
+	agent := fakeAgent(acpSrv.URL, token)
+	yaml := gosutoWithWebhookGateway("github", "bearer", "") + "        typeFrom: \"header:X-GitHub-Event\"\n"
+	_, mux := newProxy(agent, yaml, nil, 100)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/agent-1/github", strings.NewReader(`{"action":"opened"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-Other", "dropped")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	h := <-got
+	if v := h.Get("X-GitHub-Event"); v != "pull_request" {
+		t.Errorf("X-GitHub-Event = %q, want pull_request", v)
+	}
+	if v := h.Get("X-Other"); v != "" {
+		t.Errorf("X-Other = %q, want it dropped", v)
+	}
+	if v := h.Get("Content-Type"); v != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", v)
+	}
+}
+
 // TestWebhookProxy_HappyPath_HMAC verifies that a valid HMAC-authed webhook
 // delivery is forwarded to the agent.
 func TestWebhookProxy_HappyPath_HMAC(t *testing.T) {
@@ -425,3 +467,69 @@ func TestWebhookProxy_MissingHMACHeader(t *testing.T) {
 		t.Errorf("expected 401, got %d", rr.Code)
 	}
 }
+
+// newTracingAgent starts a real agent ACP server with a bearer-authenticated
+// webhook gateway named source. Each accepted event sends the trace ID seen
+// by the agent's event handler on the returned channel.
+func newTracingAgent(t *testing.T, token, source string) (*httptest.Server, <-chan string) {
+	t.Helper()
+	got := make(chan string, 4)
+	cfg := &gosutospec.Config{
+		APIVersion: "gosuto/v1",
+		Metadata:   gosutospec.Metadata{Name: "test-agent"},
+		Trust:      gosutospec.Trust{AllowedRooms: []string{"*"}, AllowedSenders: []string{"*"}},
+		Gateways:   []gosutospec.Gateway{{Name: source, Type: "webhook"}},
+	}
+	srv := control.New(":0", control.Handlers{
+		AgentID:      "agent-1",
+		StartedAt:    time.Now(),
+		Token:        token,
+		ActiveConfig: func() *gosutospec.Config { return cfg },
+		HandleEvent: func(ctx context.Context, _ *envelope.Event) error {
+			got <- trace.FromContext(ctx)
+			return nil
+		},
+	})
+	ts := httptest.NewServer(srv.TestHandler())
+	t.Cleanup(ts.Close)
+	return ts, got
+}
+
+// TestWebhookProxy_PropagatesTraceID verifies that the sender's X-Trace-ID
+// reaches the agent's event handler through the proxy and is echoed back,
+// and that the proxy generates one when the sender does not.
+func TestWebhookProxy_PropagatesTraceID(t *testing.T) {
+	const token = "secret-acp-token"
+	agentSrv, got := newTracingAgent(t, token, "deploy-hook")
+	_, mux := newProxy(fakeAgent(agentSrv.URL, token), gosutoWithWebhookGateway("deploy-hook", "bearer", ""), nil, 100)
+
+	for _, sent := range []string{"t_from_sender", ""} {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/agent-1/deploy-hook", strings.NewReader(`{"event":"push"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if sent != "" {
+			req.Header.Set(trace.Header, sent)
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusAccepted {
+			t.Fatalf("sent %q: expected 202, got %d: %s", sent, rr.Code, rr.Body.String())
+		}
+		echoed := rr.Header().Get(trace.Header)
+		if sent != "" && echoed != sent {
+			t.Errorf("echoed trace ID = %q, want %q", echoed, sent)
+		}
+		if sent == "" && !strings.HasPrefix(echoed, "t_") {
+			t.Errorf("echoed trace ID = %q, want a generated one", echoed)
+		}
+		select {
+		case atAgent := <-got:
+			if atAgent != echoed {
+				t.Errorf("agent saw trace ID %q, sender got %q", atAgent, echoed)
+			}
+		default:
+			t.Fatalf("sent %q: agent event handler was not called", sent)
+		}
+	}
+}